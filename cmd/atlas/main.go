@@ -8,16 +8,18 @@ import (
 	"os/signal"
 	"sort"
 	"strings"
-	"sync"
 	"syscall"
 	"time"
 
 	"github.com/Xover-Official/Xover/internal/ai"
 	"github.com/Xover-Official/Xover/internal/analytics"
+	"github.com/Xover-Official/Xover/internal/chaos"
+	"github.com/Xover-Official/Xover/internal/concurrency"
 	"github.com/Xover-Official/Xover/internal/config"
 	"github.com/Xover-Official/Xover/internal/logger" // Updated
 	"github.com/Xover-Official/Xover/internal/loop"
 	"github.com/Xover-Official/Xover/internal/persistence"
+	"github.com/Xover-Official/Xover/internal/retention"
 	"go.uber.org/zap"
 )
 
@@ -33,12 +35,13 @@ func main() {
 		l.Error("Failed to load config", zap.Error(err))
 		os.Exit(1)
 	}
+	concurrency.SetDefault(concurrency.NewManager(cfg.Server.MaxGoroutines))
 
 	// 3. Initialize persistence layer based on configuration
 	var ledger persistence.Ledger
 	if cfg.Server.Mode == "production" {
 		l.Info("📊 Connecting to Production Ledger (PostgreSQL)...")
-		ledger, err = persistence.NewPostgresLedger(cfg.Database.DSN)
+		ledger, err = persistence.NewPostgresLedgerWithConfig(cfg.Database.DSN, persistence.PostgresConfigFrom(cfg.Database))
 	} else {
 		l.Info("📊 Using development Ledger (SQLite)...")
 		dataPath := "./data"
@@ -55,15 +58,7 @@ func main() {
 	tokenTracker := analytics.NewTokenTracker(cfg.Analytics.PersistPath)
 
 	// 5. Initialize AI Orchestrator with different AI models
-	aiCfg := &ai.Config{
-		// The OpenRouterKey is used for all Gemini and Claude models via the OpenRouter API.
-		GeminiAPIKey: cfg.AI.OpenRouterKey,
-		ClaudeAPIKey: cfg.AI.OpenRouterKey,
-		GPT5APIKey:   cfg.AI.OpenRouterKey,
-		DevinAPIKey:  cfg.AI.DevinKey,
-		CacheEnabled: cfg.AI.CacheEnabled,
-		CacheAddr:    cfg.Redis.Address,
-	}
+	aiCfg := ai.NewConfig(cfg)
 
 	orchestrator, err := ai.NewUnifiedOrchestrator(aiCfg, tokenTracker, l)
 	if err != nil {
@@ -72,6 +67,13 @@ func main() {
 	}
 	defer orchestrator.Close()
 
+	injector, err := chaos.NewInjector(cfg.Chaos, cfg.Server.Mode, l)
+	if err != nil {
+		l.Error("Invalid chaos configuration", zap.Error(err))
+		os.Exit(1)
+	}
+	chaos.WrapAIFactory(orchestrator.GetFactory(), injector)
+
 	// 6. Health check logic for all registered AI tiers
 	l.Info("🏥 Running AI health checks...")
 	healthResults := runHealthChecks(orchestrator.GetFactory())
@@ -101,6 +103,20 @@ func main() {
 		}
 	}()
 
+	// Purge old ledger actions; the PostgreSQL tables are purged by the dashboard
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	if store, ok := ledger.(retention.Store); ok && cfg.Retention.Enabled {
+		l.Info("🧹 Starting ledger retention purge", zap.Duration("keep", cfg.Retention.LedgerActions))
+		purger := retention.NewPurger(store, retention.Policy{
+			Periods:   map[string]time.Duration{"actions": cfg.Retention.LedgerActions},
+			BatchSize: cfg.Retention.BatchSize,
+			DryRun:    cfg.Retention.DryRun,
+			LegalHold: cfg.Retention.LegalHold,
+		}, l)
+		go purger.Start(purgeCtx, cfg.Retention.Interval)
+	}
+
 	// 8. Graceful Shutdown on SIGINT or SIGTERM
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -109,15 +125,16 @@ func main() {
 	l.Info("🛑 Shutting down gracefully...")
 
 	oodaLoop.Stop()
+	stopPurge()
 
 	// Print final cost and savings statistics
-	stats := tokenTracker.GetStats()
+	stats := tokenTracker.GetSnapshot()
 	fmt.Println("\n" + strings.Repeat("═", 60))
 	fmt.Println("📊 FINAL SESSION STATS")
 	fmt.Println(strings.Repeat("═", 60))
-	fmt.Printf("  AI Cost:         $%.4f\n", stats["total_cost_usd"])
-	fmt.Printf("  Cloud Savings:   $%.2f\n", stats["total_savings_usd"])
-	fmt.Printf("  Net Profit:      $%.2f\n", stats["net_profit_usd"])
+	fmt.Printf("  AI Cost:         $%.4f\n", stats.TotalCostUSD)
+	fmt.Printf("  Cloud Savings:   $%.2f\n", stats.TotalSavingsUSD)
+	fmt.Printf("  Net Profit:      $%.2f\n", stats.NetProfitUSD)
 	fmt.Println(strings.Repeat("═", 60))
 
 	l.Info("👋 Talos shutdown complete.")
@@ -125,33 +142,21 @@ func main() {
 
 // runHealthChecks performs parallel health checks on all available AI clients.
 func runHealthChecks(factory *ai.AIClientFactory) map[string]bool {
-	clients := map[string]ai.AIClient{
-		"Sentinel (Gemini Flash)": factory.GetClientByName("sentinel"),
-		"Strategist (Gemini Pro)": factory.GetClientByName("strategist"),
-		"Arbiter (Claude)":        factory.GetClientByName("arbiter"),
-		"Reasoning (GPT-5 Mini)":  factory.GetClientByName("reasoning"),
-		"Oracle (Devin)":          factory.GetClientByName("oracle"),
+	labels := map[string]string{
+		"sentinel":   "Sentinel (Gemini Flash)",
+		"strategist": "Strategist (Gemini Pro)",
+		"arbiter":    "Arbiter (Claude)",
+		"reasoning":  "Reasoning (GPT-5 Mini)",
+		"oracle":     "Oracle (Devin)",
 	}
 
-	var wg sync.WaitGroup
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
 	results := make(map[string]bool)
-	var mu sync.Mutex
-
-	for name, client := range clients {
-		wg.Add(1)
-		go func(name string, client ai.AIClient) {
-			defer wg.Done()
-			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-			defer cancel()
-
-			err := client.HealthCheck(ctx)
-			mu.Lock()
-			results[name] = err == nil
-			mu.Unlock()
-		}(name, client)
+	for name, err := range factory.HealthCheckAll(ctx) {
+		results[labels[name]] = err == nil
 	}
-
-	wg.Wait()
 	return results
 }
 
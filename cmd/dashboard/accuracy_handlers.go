@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/analytics"
+	"github.com/Xover-Official/Xover/internal/metrics"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultAccuracyWindow is how far back /api/accuracy looks by default
+	defaultAccuracyWindow = 30 * 24 * time.Hour
+	// accuracyRefreshInterval is how often the MAPE gauge is recomputed
+	accuracyRefreshInterval = 15 * time.Minute
+)
+
+// handleAccuracy reports how realized savings compare to their estimates.
+// GET /api/accuracy?window=720h
+func (s *server) handleAccuracy(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if !s.requireRepository(w) {
+		return
+	}
+
+	window := defaultAccuracyWindow
+	if raw := r.URL.Query().Get("window"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			respondWithError(w, http.StatusBadRequest, "invalid window duration")
+			return
+		}
+		window = d
+	}
+
+	resp, err := s.savingsAccuracy(r.Context(), window)
+	if err != nil {
+		s.respondWithRepositoryError(w, err, "failed to compute savings accuracy")
+		return
+	}
+	if window == defaultAccuracyWindow {
+		metrics.SavingsEstimateMAPE.Set(resp.Overall.MAPE)
+	}
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// savingsAccuracy computes estimate accuracy over the window ending now,
+// overall and per optimization type
+func (s *server) savingsAccuracy(ctx context.Context, window time.Duration) (*AccuracyResponse, error) {
+	end := time.Now().UTC()
+	start := end.Add(-window)
+
+	samples, err := s.repository.ListSavingsSamples(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	all := make([]analytics.SavingsSample, 0, len(samples))
+	byType := make(map[string][]analytics.SavingsSample)
+	for _, sample := range samples {
+		point := analytics.SavingsSample{Estimated: sample.EstimatedSavings, Actual: sample.ActualSavings}
+		all = append(all, point)
+		byType[sample.OptimizationType] = append(byType[sample.OptimizationType], point)
+	}
+
+	resp := &AccuracyResponse{
+		WindowStart: start,
+		WindowEnd:   end,
+		Overall:     analytics.ComputeSavingsAccuracy(all),
+		ByType:      make(map[string]analytics.SavingsAccuracy, len(byType)),
+	}
+	for optimizationType, points := range byType {
+		resp.ByType[optimizationType] = analytics.ComputeSavingsAccuracy(points)
+	}
+	return resp, nil
+}
+
+// startAccuracyRefresh keeps the MAPE gauge current for Prometheus scrapes
+// over the default window.
+func (s *server) startAccuracyRefresh(ctx context.Context) {
+	ticker := time.NewTicker(accuracyRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		resp, err := s.savingsAccuracy(ctx, defaultAccuracyWindow)
+		if err != nil {
+			s.logger.Warn("failed to refresh savings accuracy", zap.Error(err))
+		} else {
+			metrics.SavingsEstimateMAPE.Set(resp.Overall.MAPE)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,505 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/auth"
+	"github.com/Xover-Official/Xover/internal/database"
+	"go.uber.org/zap"
+)
+
+const (
+	inviteTTL         = 72 * time.Hour
+	minPasswordLength = 12
+)
+
+// adminPermission guards all organization and user administration endpoints.
+// Only the admin role holds it (via the "*" wildcard).
+var adminPermission = auth.Permission{Resource: "users", Action: "admin"}
+
+// OrganizationRequest is the body for creating or updating an organization.
+type OrganizationRequest struct {
+	Name     string                 `json:"name"`
+	Settings map[string]interface{} `json:"settings"`
+}
+
+// UserRequest is the body for creating or updating a user.
+type UserRequest struct {
+	Email          string  `json:"email"`
+	OrganizationID *string `json:"organization_id"`
+	Role           string  `json:"role"`
+	Status         string  `json:"status"`
+	Password       string  `json:"password,omitempty"`
+}
+
+// InviteResponse returns the one-time invitation token to the administrator.
+type InviteResponse struct {
+	User        *database.User `json:"user"`
+	InviteToken string         `json:"invite_token"`
+	ExpiresAt   time.Time      `json:"expires_at"`
+}
+
+// AcceptInviteRequest is the body for accepting an invitation.
+type AcceptInviteRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// registerAdminRoutes mounts the admin-only organization and user management API.
+func (s *server) registerAdminRoutes(api *http.ServeMux) {
+	admin := func(h http.HandlerFunc) http.HandlerFunc {
+		return s.requirePermission(adminPermission, h)
+	}
+
+	api.HandleFunc("GET /admin/organizations", admin(s.handleListOrganizations))
+	s.handleMutation(api, "POST /admin/organizations", admin(s.handleCreateOrganization))
+	api.HandleFunc("GET /admin/organizations/{id}", admin(s.handleGetOrganization))
+	s.handleMutation(api, "PUT /admin/organizations/{id}", admin(s.handleUpdateOrganization))
+
+	api.HandleFunc("GET /admin/users", admin(s.handleListUsers))
+	s.handleMutation(api, "POST /admin/users", admin(s.handleCreateUser))
+	s.handleMutation(api, "POST /admin/users/invite", admin(s.handleInviteUser))
+	api.HandleFunc("GET /admin/users/{id}", admin(s.handleGetUser))
+	s.handleMutation(api, "PUT /admin/users/{id}", admin(s.handleUpdateUser))
+	s.handleMutation(api, "PUT /admin/users/{id}/role", admin(s.handleAssignRole))
+}
+
+func (s *server) handleListOrganizations(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
+	}
+	orgs, err := s.repository.ListOrganizations(r.Context())
+	if err != nil {
+		s.respondWithRepositoryError(w, err, "failed to list organizations")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, orgs)
+}
+
+func (s *server) handleCreateOrganization(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
+	}
+	var req OrganizationRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validateOrganizationName(req.Name); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	org := &database.Organization{Name: strings.TrimSpace(req.Name), Settings: req.Settings}
+	if err := s.repository.CreateOrganization(r.Context(), org); err != nil {
+		s.respondWithRepositoryError(w, err, "failed to create organization")
+		return
+	}
+
+	s.audit(r, "organization.create", "organization", org.ID, map[string]interface{}{"name": org.Name})
+	respondWithJSON(w, http.StatusCreated, org)
+}
+
+func (s *server) handleGetOrganization(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
+	}
+	org, err := s.repository.GetOrganization(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.respondWithRepositoryError(w, err, "failed to get organization")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, org)
+}
+
+func (s *server) handleUpdateOrganization(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
+	}
+	var req OrganizationRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validateOrganizationName(req.Name); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	org := &database.Organization{ID: r.PathValue("id"), Name: strings.TrimSpace(req.Name), Settings: req.Settings}
+	if err := s.repository.UpdateOrganization(r.Context(), org); err != nil {
+		s.respondWithRepositoryError(w, err, "failed to update organization")
+		return
+	}
+
+	s.audit(r, "organization.update", "organization", org.ID, map[string]interface{}{"name": org.Name})
+	respondWithJSON(w, http.StatusOK, org)
+}
+
+func (s *server) handleListUsers(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
+	}
+	users, err := s.repository.ListUsers(r.Context(), r.URL.Query().Get("org_id"))
+	if err != nil {
+		s.respondWithRepositoryError(w, err, "failed to list users")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, users)
+}
+
+func (s *server) handleCreateUser(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
+	}
+	var req UserRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Role == "" {
+		req.Role = string(auth.RoleViewer)
+	}
+	if err := validateUserRequest(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user := &database.User{
+		Email:          req.Email,
+		OrganizationID: req.OrganizationID,
+		Role:           req.Role,
+		Status:         database.UserStatusActive,
+	}
+
+	// Password is optional: SSO-only users never get one
+	if req.Password != "" {
+		if err := validatePassword(req.Password); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		hash, err := s.security.HashPassword(req.Password)
+		if err != nil {
+			s.logger.Error("failed to hash password", zap.Error(err))
+			respondWithError(w, http.StatusInternalServerError, "failed to create user")
+			return
+		}
+		user.PasswordHash = &hash
+	}
+
+	if err := s.repository.CreateUser(r.Context(), user); err != nil {
+		s.respondWithRepositoryError(w, err, "failed to create user")
+		return
+	}
+
+	s.audit(r, "user.create", "user", user.ID, map[string]interface{}{"email": user.Email, "role": user.Role})
+	respondWithJSON(w, http.StatusCreated, user)
+}
+
+func (s *server) handleInviteUser(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
+	}
+	var req UserRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Role == "" {
+		req.Role = string(auth.RoleViewer)
+	}
+	if err := validateUserRequest(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	token, tokenHash, err := newInviteToken()
+	if err != nil {
+		s.logger.Error("failed to generate invite token", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "failed to invite user")
+		return
+	}
+
+	user := &database.User{Email: req.Email, OrganizationID: req.OrganizationID, Role: req.Role}
+	expiresAt := time.Now().Add(inviteTTL).UTC()
+	if err := s.repository.InviteUser(r.Context(), user, tokenHash, expiresAt); err != nil {
+		s.respondWithRepositoryError(w, err, "failed to invite user")
+		return
+	}
+
+	s.audit(r, "user.invite", "user", user.ID, map[string]interface{}{"email": user.Email, "role": user.Role})
+	respondWithJSON(w, http.StatusCreated, InviteResponse{User: user, InviteToken: token, ExpiresAt: expiresAt})
+}
+
+func (s *server) handleGetUser(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
+	}
+	user, err := s.repository.GetUserByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.respondWithRepositoryError(w, err, "failed to get user")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, user)
+}
+
+func (s *server) handleUpdateUser(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
+	}
+	var req UserRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := s.repository.GetUserByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.respondWithRepositoryError(w, err, "failed to get user")
+		return
+	}
+
+	// Email is immutable here; only membership, role and status can change
+	req.Email = user.Email
+	if req.Role == "" {
+		req.Role = user.Role
+	}
+	if req.Status == "" {
+		req.Status = user.Status
+	}
+	if req.OrganizationID == nil {
+		req.OrganizationID = user.OrganizationID
+	}
+	if err := validateUserRequest(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	before := map[string]interface{}{"role": user.Role, "status": user.Status}
+	user.OrganizationID = req.OrganizationID
+	user.Role = req.Role
+	user.Status = req.Status
+	if err := s.repository.UpdateUser(r.Context(), user); err != nil {
+		s.respondWithRepositoryError(w, err, "failed to update user")
+		return
+	}
+
+	s.audit(r, "user.update", "user", user.ID, map[string]interface{}{
+		"before": before,
+		"after":  map[string]interface{}{"role": user.Role, "status": user.Status},
+	})
+	respondWithJSON(w, http.StatusOK, user)
+}
+
+func (s *server) handleAssignRole(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
+	}
+	var req struct {
+		Role string `json:"role"`
+	}
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !isValidRole(req.Role) {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("invalid role: %q", req.Role))
+		return
+	}
+
+	user, err := s.repository.GetUserByID(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.respondWithRepositoryError(w, err, "failed to get user")
+		return
+	}
+
+	previousRole := user.Role
+	user.Role = req.Role
+	if err := s.repository.UpdateUser(r.Context(), user); err != nil {
+		s.respondWithRepositoryError(w, err, "failed to assign role")
+		return
+	}
+
+	s.audit(r, "user.role_assign", "user", user.ID, map[string]interface{}{"from": previousRole, "to": user.Role})
+	respondWithJSON(w, http.StatusOK, user)
+}
+
+// handleAcceptInvite is public: the invitation token itself authenticates the caller.
+func (s *server) handleAcceptInvite(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if !s.requireRepository(w) {
+		return
+	}
+	var req AcceptInviteRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Token == "" {
+		respondWithError(w, http.StatusBadRequest, "token is required")
+		return
+	}
+	if err := validatePassword(req.Password); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	hash, err := s.security.HashPassword(req.Password)
+	if err != nil {
+		s.logger.Error("failed to hash password", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "failed to accept invitation")
+		return
+	}
+
+	user, err := s.repository.AcceptInvite(r.Context(), hashInviteToken(req.Token), hash)
+	if err != nil {
+		s.respondWithRepositoryError(w, err, "failed to accept invitation")
+		return
+	}
+
+	s.audit(r, "user.invite_accept", "user", user.ID, map[string]interface{}{"email": user.Email})
+	respondWithJSON(w, http.StatusOK, user)
+}
+
+// --- helpers ---
+
+// requireRepository writes a 503 and returns false if no database is configured.
+func (s *server) requireRepository(w http.ResponseWriter) bool {
+	if s.repository == nil {
+		respondWithError(w, http.StatusServiceUnavailable, "this endpoint requires a database connection")
+		return false
+	}
+	return true
+}
+
+// respondWithRepositoryError maps repository errors onto HTTP status codes.
+func (s *server) respondWithRepositoryError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, database.ErrNotFound):
+		respondWithError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, database.ErrConflict):
+		respondWithError(w, http.StatusConflict, err.Error())
+	default:
+		s.logger.Error(message, zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, message)
+	}
+}
+
+// audit records an administrative action in the audit log. Failures are
+// logged but never fail the request.
+func (s *server) audit(r *http.Request, action, resourceType, resourceID string, details map[string]interface{}) {
+	entry := &database.AuditLog{
+		Action:       action,
+		ResourceType: &resourceType,
+		ResourceID:   &resourceID,
+		Details:      details,
+	}
+	if claims, ok := r.Context().Value(userContextKey).(*auth.Claims); ok && claims.UserID != "" {
+		entry.UserID = &claims.UserID
+	}
+	if ip := clientIP(r); ip != "" {
+		entry.IPAddress = &ip
+	}
+
+	if err := s.repository.CreateAuditLog(r.Context(), entry); err != nil {
+		s.logger.Warn("failed to write audit log", zap.String("action", action), zap.Error(err))
+	}
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(payload)
+}
+
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) error {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(dst); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func clientIP(r *http.Request) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+func isValidRole(role string) bool {
+	switch auth.Role(role) {
+	case auth.RoleAdmin, auth.RoleOperator, auth.RoleViewer:
+		return true
+	default:
+		return false
+	}
+}
+
+func validateOrganizationName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if len(name) > 255 {
+		return errors.New("name must be at most 255 characters")
+	}
+	return nil
+}
+
+func validateUserRequest(req *UserRequest) error {
+	addr, err := mail.ParseAddress(req.Email)
+	if err != nil || addr.Address != req.Email {
+		return fmt.Errorf("invalid email: %q", req.Email)
+	}
+	if !isValidRole(req.Role) {
+		return fmt.Errorf("invalid role: %q", req.Role)
+	}
+	switch req.Status {
+	case "", database.UserStatusActive, database.UserStatusDisabled, database.UserStatusInvited:
+	default:
+		return fmt.Errorf("invalid status: %q", req.Status)
+	}
+	return nil
+}
+
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	return nil
+}
+
+// newInviteToken returns a random invitation token and the hash stored in the database.
+func newInviteToken() (token, tokenHash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token = hex.EncodeToString(buf)
+	return token, hashInviteToken(token), nil
+}
+
+func hashInviteToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
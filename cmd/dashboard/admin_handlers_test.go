@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/auth"
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/Xover-Official/Xover/internal/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memRepository keeps organizations, users and audit entries in memory with
+// the repository's not-found and uniqueness errors. Methods it doesn't
+// implement panic through the nil embedded interface.
+type memRepository struct {
+	Repository
+	orgs    map[string]*database.Organization
+	users   map[string]*database.User
+	invites map[string]string // invite token hash -> user ID
+	audit   []*database.AuditLog
+	nextID  int
+}
+
+func newMemRepository() *memRepository {
+	return &memRepository{
+		orgs:    map[string]*database.Organization{},
+		users:   map[string]*database.User{},
+		invites: map[string]string{},
+	}
+}
+
+func (m *memRepository) id() string {
+	m.nextID++
+	return fmt.Sprintf("id-%d", m.nextID)
+}
+
+func (m *memRepository) CreateAuditLog(_ context.Context, log *database.AuditLog) error {
+	m.audit = append(m.audit, log)
+	return nil
+}
+
+func (m *memRepository) CreateOrganization(_ context.Context, org *database.Organization) error {
+	for _, existing := range m.orgs {
+		if existing.Name == org.Name {
+			return fmt.Errorf("failed to create organization %q: %w", org.Name, database.ErrConflict)
+		}
+	}
+	org.ID, org.CreatedAt = m.id(), time.Now()
+	stored := *org
+	m.orgs[org.ID] = &stored
+	return nil
+}
+
+func (m *memRepository) GetOrganization(_ context.Context, id string) (*database.Organization, error) {
+	org, ok := m.orgs[id]
+	if !ok {
+		return nil, fmt.Errorf("organization %s: %w", id, database.ErrNotFound)
+	}
+	found := *org
+	return &found, nil
+}
+
+func (m *memRepository) ListOrganizations(context.Context) ([]*database.Organization, error) {
+	var orgs []*database.Organization
+	for _, org := range m.orgs {
+		orgs = append(orgs, org)
+	}
+	return orgs, nil
+}
+
+func (m *memRepository) UpdateOrganization(_ context.Context, org *database.Organization) error {
+	stored, ok := m.orgs[org.ID]
+	if !ok {
+		return fmt.Errorf("organization %s: %w", org.ID, database.ErrNotFound)
+	}
+	stored.Name, stored.Settings = org.Name, org.Settings
+	org.CreatedAt = stored.CreatedAt
+	return nil
+}
+
+func (m *memRepository) addUser(user *database.User) error {
+	for _, existing := range m.users {
+		if existing.Email == strings.ToLower(user.Email) {
+			return fmt.Errorf("failed to create user %q: %w", user.Email, database.ErrConflict)
+		}
+	}
+	user.ID, user.Email, user.CreatedAt = m.id(), strings.ToLower(user.Email), time.Now()
+	stored := *user
+	m.users[user.ID] = &stored
+	return nil
+}
+
+func (m *memRepository) CreateUser(_ context.Context, user *database.User) error {
+	return m.addUser(user)
+}
+
+func (m *memRepository) InviteUser(_ context.Context, user *database.User, inviteTokenHash string, expiresAt time.Time) error {
+	user.Status, user.InviteExpiresAt = database.UserStatusInvited, &expiresAt
+	if err := m.addUser(user); err != nil {
+		return err
+	}
+	m.invites[inviteTokenHash] = user.ID
+	return nil
+}
+
+func (m *memRepository) AcceptInvite(_ context.Context, inviteTokenHash, passwordHash string) (*database.User, error) {
+	id, ok := m.invites[inviteTokenHash]
+	if !ok {
+		return nil, fmt.Errorf("invitation invalid or expired: %w", database.ErrNotFound)
+	}
+	delete(m.invites, inviteTokenHash)
+	user := m.users[id]
+	user.Status, user.PasswordHash, user.InviteExpiresAt = database.UserStatusActive, &passwordHash, nil
+	accepted := *user
+	return &accepted, nil
+}
+
+func (m *memRepository) GetUserByID(_ context.Context, id string) (*database.User, error) {
+	user, ok := m.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user %s: %w", id, database.ErrNotFound)
+	}
+	found := *user
+	return &found, nil
+}
+
+func (m *memRepository) ListUsers(_ context.Context, orgID string) ([]*database.User, error) {
+	var users []*database.User
+	for _, user := range m.users {
+		if orgID == "" || (user.OrganizationID != nil && *user.OrganizationID == orgID) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (m *memRepository) UpdateUser(_ context.Context, user *database.User) error {
+	if _, ok := m.users[user.ID]; !ok {
+		return fmt.Errorf("user %s: %w", user.ID, database.ErrNotFound)
+	}
+	stored := *user
+	m.users[user.ID] = &stored
+	return nil
+}
+
+func newAdminServer(repo Repository) *server {
+	return &server{
+		logger:     zap.NewNop(),
+		jwtManager: auth.NewJWTManager("admin-test-secret", time.Hour),
+		security:   security.NewSecurityManager("admin-test-secret", time.Hour, 24*time.Hour, zap.NewNop()),
+		repository: repo,
+	}
+}
+
+// adminRequest sends method path with a JSON body through the full router as
+// an admin and returns the recorded response
+func adminRequest(t *testing.T, srv *server, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if strings.HasPrefix(path, "/api/") {
+		token, err := srv.jwtManager.Generate(auth.User{ID: "admin-1", Email: "admin@example.com", Role: auth.RoleAdmin})
+		require.NoError(t, err)
+		req.AddCookie(&http.Cookie{Name: "atlas_token", Value: token})
+	}
+	rec := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, req)
+	return rec
+}
+
+func decodeBody(t *testing.T, rec *httptest.ResponseRecorder, dst interface{}) {
+	t.Helper()
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), dst))
+}
+
+func TestAdminHandlers_Organizations(t *testing.T) {
+	repo := newMemRepository()
+	srv := newAdminServer(repo)
+
+	rec := adminRequest(t, srv, http.MethodPost, "/api/admin/organizations", `{"name": "  Acme  ", "settings": {"tier": "gold"}}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created database.Organization
+	decodeBody(t, rec, &created)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "Acme", created.Name)
+	assert.Equal(t, "gold", created.Settings["tier"])
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"get", http.MethodGet, "/api/admin/organizations/" + created.ID, "", http.StatusOK},
+		{"list", http.MethodGet, "/api/admin/organizations", "", http.StatusOK},
+		{"update", http.MethodPut, "/api/admin/organizations/" + created.ID, `{"name": "Acme Corp"}`, http.StatusOK},
+		{"get missing", http.MethodGet, "/api/admin/organizations/nope", "", http.StatusNotFound},
+		{"update missing", http.MethodPut, "/api/admin/organizations/nope", `{"name": "Ghost"}`, http.StatusNotFound},
+		{"duplicate name", http.MethodPost, "/api/admin/organizations", `{"name": "Acme Corp"}`, http.StatusConflict},
+		{"blank name", http.MethodPost, "/api/admin/organizations", `{"name": "   "}`, http.StatusBadRequest},
+		{"long name", http.MethodPost, "/api/admin/organizations", `{"name": "` + strings.Repeat("a", 256) + `"}`, http.StatusBadRequest},
+		{"unknown field", http.MethodPost, "/api/admin/organizations", `{"name": "Beta", "owner": "x"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := adminRequest(t, srv, tt.method, tt.path, tt.body)
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+			if tt.want != http.StatusOK {
+				var body map[string]string
+				decodeBody(t, rec, &body)
+				assert.NotEmpty(t, body["error"])
+			}
+		})
+	}
+
+	var orgs []database.Organization
+	decodeBody(t, adminRequest(t, srv, http.MethodGet, "/api/admin/organizations", ""), &orgs)
+	require.Len(t, orgs, 1)
+	assert.Equal(t, "Acme Corp", orgs[0].Name)
+
+	var actions []string
+	for _, entry := range repo.audit {
+		actions = append(actions, entry.Action)
+		assert.Equal(t, "admin-1", *entry.UserID)
+	}
+	assert.Equal(t, []string{"organization.create", "organization.update"}, actions)
+}
+
+func TestAdminHandlers_Users(t *testing.T) {
+	repo := newMemRepository()
+	srv := newAdminServer(repo)
+
+	rec := adminRequest(t, srv, http.MethodPost, "/api/admin/users", `{"email": "ops@example.com", "password": "correct-horse-battery"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var created map[string]interface{}
+	decodeBody(t, rec, &created)
+	assert.Equal(t, "ops@example.com", created["email"])
+	assert.Equal(t, string(auth.RoleViewer), created["role"], "viewer by default")
+	assert.Equal(t, database.UserStatusActive, created["status"])
+	assert.NotContains(t, created, "password_hash", "the hash is never returned")
+	id := created["id"].(string)
+	require.NotNil(t, repo.users[id].PasswordHash)
+	assert.NotEqual(t, "correct-horse-battery", *repo.users[id].PasswordHash)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"get", http.MethodGet, "/api/admin/users/" + id, "", http.StatusOK},
+		{"list", http.MethodGet, "/api/admin/users", "", http.StatusOK},
+		{"update", http.MethodPut, "/api/admin/users/" + id, `{"status": "disabled"}`, http.StatusOK},
+		{"assign role", http.MethodPut, "/api/admin/users/" + id + "/role", `{"role": "operator"}`, http.StatusOK},
+		{"get missing", http.MethodGet, "/api/admin/users/nope", "", http.StatusNotFound},
+		{"update missing", http.MethodPut, "/api/admin/users/nope", `{"role": "admin"}`, http.StatusNotFound},
+		{"assign role to missing", http.MethodPut, "/api/admin/users/nope/role", `{"role": "admin"}`, http.StatusNotFound},
+		{"duplicate email", http.MethodPost, "/api/admin/users", `{"email": "OPS@example.com"}`, http.StatusConflict},
+		{"invalid email", http.MethodPost, "/api/admin/users", `{"email": "not-an-email"}`, http.StatusBadRequest},
+		{"invalid role", http.MethodPost, "/api/admin/users", `{"email": "dev@example.com", "role": "root"}`, http.StatusBadRequest},
+		{"short password", http.MethodPost, "/api/admin/users", `{"email": "dev@example.com", "password": "short"}`, http.StatusBadRequest},
+		{"invalid status", http.MethodPut, "/api/admin/users/" + id, `{"status": "banned"}`, http.StatusBadRequest},
+		{"assign invalid role", http.MethodPut, "/api/admin/users/" + id + "/role", `{"role": "root"}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := adminRequest(t, srv, tt.method, tt.path, tt.body)
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+			if tt.want != http.StatusOK {
+				var body map[string]string
+				decodeBody(t, rec, &body)
+				assert.NotEmpty(t, body["error"])
+			}
+		})
+	}
+
+	var user database.User
+	decodeBody(t, adminRequest(t, srv, http.MethodGet, "/api/admin/users/"+id, ""), &user)
+	assert.Equal(t, string(auth.RoleOperator), user.Role)
+	assert.Equal(t, database.UserStatusDisabled, user.Status)
+	assert.Equal(t, "ops@example.com", user.Email)
+}
+
+func TestAdminHandlers_InviteAndAccept(t *testing.T) {
+	repo := newMemRepository()
+	srv := newAdminServer(repo)
+
+	rec := adminRequest(t, srv, http.MethodPost, "/api/admin/users/invite", `{"email": "new@example.com", "role": "operator"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var invite InviteResponse
+	decodeBody(t, rec, &invite)
+	assert.Len(t, invite.InviteToken, 64)
+	assert.Equal(t, database.UserStatusInvited, invite.User.Status)
+	assert.WithinDuration(t, time.Now().Add(inviteTTL), invite.ExpiresAt, time.Minute)
+	assert.NotContains(t, repo.invites, invite.InviteToken, "only the token's hash is stored")
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"short password", `{"token": "` + invite.InviteToken + `", "password": "short"}`, http.StatusBadRequest},
+		{"missing token", `{"password": "correct-horse-battery"}`, http.StatusBadRequest},
+		{"unknown token", `{"token": "deadbeef", "password": "correct-horse-battery"}`, http.StatusNotFound},
+		{"accept", `{"token": "` + invite.InviteToken + `", "password": "correct-horse-battery"}`, http.StatusOK},
+		{"token is single use", `{"token": "` + invite.InviteToken + `", "password": "correct-horse-battery"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := adminRequest(t, srv, http.MethodPost, "/auth/invite/accept", tt.body)
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+		})
+	}
+
+	assert.Equal(t, database.UserStatusActive, repo.users[invite.User.ID].Status)
+	assert.Equal(t, http.StatusMethodNotAllowed, adminRequest(t, srv, http.MethodGet, "/auth/invite/accept", "").Code)
+}
+
+func TestAdminHandlers_WithoutDatabase(t *testing.T) {
+	srv := newAdminServer(nil)
+	for _, path := range []string{"/api/admin/organizations", "/api/admin/users"} {
+		assert.Equal(t, http.StatusServiceUnavailable, adminRequest(t, srv, http.MethodGet, path, "").Code, path)
+	}
+	rec := adminRequest(t, srv, http.MethodPost, "/auth/invite/accept", `{"token": "t", "password": "correct-horse-battery"}`)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/Xover-Official/Xover/internal/cloud"
 )
@@ -32,7 +33,7 @@ func (m *MockOrchestrator) GenerateOptimizationSuggestion(ctx context.Context, r
 		suggestion = "resize_down"
 		estimatedSavings = res.CostPerMonth * 0.5 // 50% savings
 		priority = "high"
-	} else if res.CPUUsage < 40 && res.MemoryUsage < 50 {
+	} else if rightsizing.Evaluate(res.CPUUsage, res.MemoryUsage).Safe {
 		suggestion = "rightsize"
 		estimatedSavings = res.CostPerMonth * 0.25 // 25% savings
 		priority = "medium"
@@ -60,8 +61,8 @@ func generateOptimizationReason(cpuUsage, memoryUsage float64, state string) str
 	if cpuUsage < 20 && memoryUsage < 30 {
 		return "Very low utilization - consider significant downsizing"
 	}
-	if cpuUsage < 40 && memoryUsage < 50 {
-		return "Low utilization - consider rightsizing"
+	if sizing := rightsizing.Evaluate(cpuUsage, memoryUsage); sizing.Safe {
+		return fmt.Sprintf("Low utilization (%s) - consider rightsizing", sizing)
 	}
 	return "Resource appears to be appropriately sized"
 }
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Xover-Official/Xover/internal/auth"
+	"github.com/Xover-Official/Xover/internal/database"
+)
+
+// approvePermission guards approving or rejecting queued actions.
+var approvePermission = auth.Permission{Resource: "actions", Action: "write"}
+
+// actionsReadPermission guards listing actions.
+var actionsReadPermission = auth.Permission{Resource: "actions", Action: "read"}
+
+// maxActionListLimit caps how many actions one list request returns.
+const maxActionListLimit = 500
+
+// dryRunOverridePermission guards running a single approved action with a
+// dry-run setting other than the global one.
+var dryRunOverridePermission = auth.Permission{Resource: "actions", Action: "override_dry_run"}
+
+// ApprovalRequest approves one queued action. DryRun, when set, overrides
+// the global dry-run setting for this action only.
+type ApprovalRequest struct {
+	DryRun *bool `json:"dry_run,omitempty"`
+}
+
+// ApprovalResponse reports the resolved action and any dry-run override.
+type ApprovalResponse struct {
+	ActionID       string `json:"action_id"`
+	Decision       string `json:"decision"`
+	DryRunOverride *bool  `json:"dry_run_override,omitempty"`
+}
+
+// BulkApprovalRequest approves or rejects every action awaiting approval that
+// matches Filter. With DryRun set nothing changes and only the count is returned.
+type BulkApprovalRequest struct {
+	Decision string                `json:"decision"` // "approve" or "reject"
+	Filter   database.ActionFilter `json:"filter"`
+	DryRun   bool                  `json:"dry_run"`
+}
+
+// BulkApprovalResponse reports how many actions matched (dry run) or were changed.
+type BulkApprovalResponse struct {
+	Decision string `json:"decision"`
+	DryRun   bool   `json:"dry_run"`
+	Matched  int    `json:"matched"`
+	Affected int    `json:"affected"`
+}
+
+// requestOrgID is the organization of the authenticated user, whose actions
+// are the only ones the user sees and resolves. It is "" for a user of none.
+func requestOrgID(r *http.Request) string {
+	if claims, ok := r.Context().Value(userContextKey).(*auth.Claims); ok {
+		return claims.OrganizationID
+	}
+	return ""
+}
+
+// handleListActions lists the organization's most recent actions, optionally
+// only those in one status.
+// GET /api/actions?status=AWAITING_APPROVAL&limit=20
+func (s *server) handleListActions(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
+	}
+
+	limit := 50
+	if raw := r.URL.Query().Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxActionListLimit {
+			respondWithError(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	actions, err := s.repository.ListRecentActions(r.Context(), requestOrgID(r), r.URL.Query().Get("status"), limit)
+	if err != nil {
+		s.respondWithRepositoryError(w, err, "failed to list actions")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, actions)
+}
+
+// handleBulkApproval resolves queued actions in bulk; each affected action is
+// audited individually.
+// POST /api/actions/bulk
+func (s *server) handleBulkApproval(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
+	}
+
+	var req BulkApprovalRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Decision != "approve" && req.Decision != "reject" {
+		respondWithError(w, http.StatusBadRequest, `decision must be "approve" or "reject"`)
+		return
+	}
+
+	resp := BulkApprovalResponse{Decision: req.Decision, DryRun: req.DryRun}
+	if req.DryRun {
+		count, err := s.repository.CountAwaitingActions(r.Context(), requestOrgID(r), req.Filter)
+		if err != nil {
+			s.respondWithRepositoryError(w, err, "failed to count matching actions")
+			return
+		}
+		resp.Matched = count
+		respondWithJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	actor := &database.AuditLog{}
+	if claims, ok := r.Context().Value(userContextKey).(*auth.Claims); ok && claims.UserID != "" {
+		actor.UserID = &claims.UserID
+	}
+	if ip := clientIP(r); ip != "" {
+		actor.IPAddress = &ip
+	}
+
+	affected, err := s.repository.ResolveAwaitingActions(r.Context(), requestOrgID(r), req.Filter, req.Decision == "approve", actor)
+	if err != nil {
+		s.respondWithRepositoryError(w, err, "failed to resolve actions")
+		return
+	}
+	resp.Matched, resp.Affected = affected, affected
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// handleApproveAction approves a single queued action, optionally with a
+// per-action dry-run override that requires the operator role.
+// POST /api/actions/{id}/approve
+func (s *server) handleApproveAction(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
+	}
+
+	var req ApprovalRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			respondWithError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if req.DryRun != nil {
+		claims, ok := r.Context().Value(userContextKey).(*auth.Claims)
+		if !ok || !claims.Role.HasPermission(dryRunOverridePermission) {
+			respondWithError(w, http.StatusForbidden, "dry-run override requires the operator role")
+			return
+		}
+	}
+
+	s.resolveAction(w, r, true, req.DryRun)
+}
+
+// handleRejectAction rejects a single queued action.
+// POST /api/actions/{id}/reject
+func (s *server) handleRejectAction(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
+	}
+	s.resolveAction(w, r, false, nil)
+}
+
+func (s *server) resolveAction(w http.ResponseWriter, r *http.Request, approve bool, dryRunOverride *bool) {
+	actionID := r.PathValue("id")
+
+	actor := &database.AuditLog{}
+	if claims, ok := r.Context().Value(userContextKey).(*auth.Claims); ok && claims.UserID != "" {
+		actor.UserID = &claims.UserID
+	}
+	if ip := clientIP(r); ip != "" {
+		actor.IPAddress = &ip
+	}
+
+	if err := s.repository.ResolveAction(r.Context(), requestOrgID(r), actionID, approve, dryRunOverride, actor); err != nil {
+		s.respondWithRepositoryError(w, err, "failed to resolve action")
+		return
+	}
+
+	decision := "reject"
+	if approve {
+		decision = "approve"
+	}
+	respondWithJSON(w, http.StatusOK, ApprovalResponse{ActionID: actionID, Decision: decision, DryRunOverride: dryRunOverride})
+}
@@ -2,12 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/Xover-Official/Xover/internal/auth"
+	"github.com/Xover-Official/Xover/internal/config"
 	"go.uber.org/zap"
 )
 
@@ -16,36 +18,155 @@ type contextKey string
 // userContextKey is a type-safe key for storing user claims in the request context.
 const userContextKey = contextKey("user")
 
-func (s *server) handleLogin(w http.ResponseWriter, r *http.Request) {
-	providerName := strings.TrimPrefix(r.URL.Path, "/auth/login/")
-	provider, err := s.getSSOProvider(providerName)
-	if err != nil {
-		respondWithError(w, http.StatusBadRequest, err.Error())
-		return
+// oauthStateCookie holds the signed state of a sign-in in progress. It is
+// scoped to the provider's callback path and checked there against the state
+// the provider sends back, so a callback cannot be forged from another site.
+const oauthStateCookie = "atlas_oauth_state"
+
+// refreshCookie holds the refresh token; only /auth/refresh receives it
+const refreshCookie = "atlas_refresh"
+
+// newOIDCRegistry registers the sign-in providers that are configured
+func newOIDCRegistry(cfg config.SSOConfig) *auth.OIDCRegistry {
+	registry := auth.NewOIDCRegistry()
+	if cfg.Google.ClientID != "" {
+		registry.Register("google", auth.NewGoogleProvider(cfg.Google.ClientID, cfg.Google.ClientSecret))
+	}
+	if cfg.GitHub.ClientID != "" {
+		registry.Register("github", auth.NewGitHubProvider(cfg.GitHub.ClientID, cfg.GitHub.ClientSecret))
 	}
+	if cfg.Okta.ClientID != "" && cfg.Okta.Domain != "" {
+		registry.Register("okta", auth.NewOktaOIDCProvider(cfg.Okta.Domain, cfg.Okta.ClientID, cfg.Okta.ClientSecret))
+	}
+	if cfg.Azure.ClientID != "" && cfg.Azure.TenantID != "" {
+		registry.Register("azure", auth.NewAzureOIDCProvider(cfg.Azure.TenantID, cfg.Azure.ClientID, cfg.Azure.ClientSecret))
+	}
+	return registry
+}
 
+// oidcProvider returns the provider named by the path segment after prefix
+func (s *server) oidcProvider(r *http.Request, prefix string) (string, auth.OIDCProvider, error) {
+	name := strings.TrimPrefix(r.URL.Path, prefix)
+	if s.oidc == nil {
+		return name, nil, fmt.Errorf("%w: %s", auth.ErrUnknownProvider, name)
+	}
+	provider, err := s.oidc.Get(name)
+	return name, provider, err
+}
+
+// oauthStates signs sign-in state with a key derived from the JWT secret
+func (s *server) oauthStates() *auth.OAuthStateSigner {
+	return auth.NewOAuthStateSigner(s.config.JWT.SecretKey, auth.OAuthStateTTL)
+}
+
+// rejectOAuthState records a callback whose state failed verification as a
+// security event: it is either a stale tab or a forged or replayed callback
+func (s *server) rejectOAuthState(r *http.Request, providerName string, hadCookie bool, reason error) {
+	s.logger.Warn("security event: sso callback state rejected",
+		zap.String("provider", providerName),
+		zap.String("reason", reason.Error()),
+		zap.Bool("state_cookie", hadCookie),
+		zap.String("ip_address", clientIP(r)),
+		zap.String("user_agent", r.UserAgent()),
+	)
+	if s.repository != nil {
+		s.audit(r, "auth.state_rejected", "sso_provider", providerName, map[string]interface{}{
+			"reason":       reason.Error(),
+			"state_cookie": hadCookie,
+			"user_agent":   r.UserAgent(),
+		})
+	}
+}
+
+// callbackURL is the redirect URI registered with the provider
+func callbackURL(r *http.Request, providerName string) string {
 	// Make redirect URI scheme-aware for production environments (e.g., behind HTTPS proxy)
 	scheme := "http"
 	if r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https" {
 		scheme = "https"
 	}
-	redirectURI := fmt.Sprintf("%s://%s/auth/callback/%s", scheme, r.Host, providerName)
-	authURL := provider.GetAuthURL(redirectURI)
-	http.Redirect(w, r, authURL, http.StatusTemporaryRedirect)
+	return fmt.Sprintf("%s://%s/auth/callback/%s", scheme, r.Host, providerName)
 }
 
+// handleLogin starts a sign-in with the provider named in the path
+// GET /auth/login/{provider}
+func (s *server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	providerName, provider, err := s.oidcProvider(r, "/auth/login/")
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	state, cookie, err := s.oauthStates().Issue(providerName)
+	if err != nil {
+		s.logger.Error("failed to generate oauth state", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "failed to start sign-in")
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Value:    cookie,
+		Path:     "/auth/callback/" + providerName,
+		MaxAge:   int(auth.OAuthStateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode, // sent on the provider's top-level redirect back
+	})
+
+	http.Redirect(w, r, provider.AuthCodeURL(state, callbackURL(r, providerName)), http.StatusTemporaryRedirect)
+}
+
+// handleCallback completes a sign-in: it checks the state, exchanges the
+// code, maps the provider identity to a local user and issues the access
+// and refresh tokens
+// GET /auth/callback/{provider}
 func (s *server) handleCallback(w http.ResponseWriter, r *http.Request) {
-	providerName := strings.TrimPrefix(r.URL.Path, "/auth/callback/")
-	provider, err := s.getSSOProvider(providerName)
+	providerName, provider, err := s.oidcProvider(r, "/auth/callback/")
 	if err != nil {
 		respondWithError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	code := r.URL.Query().Get("code")
-	ssoUser, err := provider.Authenticate(r.Context(), code)
+	// The state is single-use whatever the outcome
+	stateCookie, cookieErr := r.Cookie(oauthStateCookie)
+	http.SetCookie(w, &http.Cookie{
+		Name:     oauthStateCookie,
+		Path:     "/auth/callback/" + providerName,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+	})
+
+	query := r.URL.Query()
+	if reason := query.Get("error"); reason != "" {
+		s.logger.Warn("sso sign-in refused by provider", zap.String("provider", providerName), zap.String("error", reason))
+		respondWithError(w, http.StatusBadRequest, "sign-in was cancelled or denied")
+		return
+	}
+	if cookieErr != nil {
+		err = auth.ErrInvalidOAuthState
+	} else {
+		err = s.oauthStates().Verify(providerName, query.Get("state"), stateCookie.Value)
+	}
+	if err != nil {
+		s.rejectOAuthState(r, providerName, cookieErr == nil, err)
+		if errors.Is(err, auth.ErrExpiredOAuthState) {
+			respondWithError(w, http.StatusBadRequest, "sign-in expired; please sign in again")
+			return
+		}
+		respondWithError(w, http.StatusBadRequest, "invalid sign-in state")
+		return
+	}
+
+	token, err := provider.Exchange(r.Context(), query.Get("code"), callbackURL(r, providerName))
+	if err != nil {
+		s.logger.Error("sso authentication failed", zap.String("provider", providerName), zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "sso authentication failed")
+		return
+	}
+	ssoUser, err := provider.UserInfo(r.Context(), token)
 	if err != nil {
-		s.logger.Error("sso authentication failed", zap.Error(err))
+		s.logger.Error("sso authentication failed", zap.String("provider", providerName), zap.Error(err))
 		respondWithError(w, http.StatusInternalServerError, "sso authentication failed")
 		return
 	}
@@ -57,13 +178,57 @@ func (s *server) handleCallback(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := s.jwtManager.Generate(*user)
+	pair, err := s.jwtManager.GeneratePair(*user, s.config.JWT.RefreshDuration)
 	if err != nil {
 		s.logger.Error("failed to generate jwt", zap.Error(err))
 		respondWithError(w, http.StatusInternalServerError, "failed to generate token")
 		return
 	}
 
+	s.setAccessCookie(w, r, pair.AccessToken)
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshCookie,
+		Value:    pair.RefreshToken,
+		Path:     "/auth/refresh",
+		Expires:  pair.RefreshExpiresAt,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteStrictMode,
+	})
+
+	http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
+}
+
+// handleRefresh issues a new access token for a valid refresh token
+// POST /auth/refresh
+func (s *server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	cookie, err := r.Cookie(refreshCookie)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "no refresh token")
+		return
+	}
+	claims, err := s.jwtManager.VerifyRefresh(cookie.Value)
+	if err != nil {
+		respondWithError(w, http.StatusUnauthorized, "invalid refresh token")
+		return
+	}
+
+	token, err := s.jwtManager.Generate(auth.User{
+		ID:             claims.UserID,
+		Email:          claims.Email,
+		OrganizationID: claims.OrganizationID,
+		Role:           claims.Role,
+	})
+	if err != nil {
+		s.logger.Error("failed to generate jwt", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "failed to generate token")
+		return
+	}
+	s.setAccessCookie(w, r, token)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) setAccessCookie(w http.ResponseWriter, r *http.Request, token string) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     "atlas_token",
 		Value:    token,
@@ -72,8 +237,6 @@ func (s *server) handleCallback(w http.ResponseWriter, r *http.Request) {
 		HttpOnly: true,
 		Secure:   r.TLS != nil,
 	})
-
-	http.Redirect(w, r, "/", http.StatusTemporaryRedirect)
 }
 
 func (s *server) handleLogout(w http.ResponseWriter, r *http.Request) {
@@ -85,6 +248,14 @@ func (s *server) handleLogout(w http.ResponseWriter, r *http.Request) {
 		HttpOnly: true,
 		Secure:   r.TLS != nil,
 	})
+	http.SetCookie(w, &http.Cookie{
+		Name:     refreshCookie,
+		Value:    "",
+		Path:     "/auth/refresh",
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+	})
 	http.Redirect(w, r, "/login", http.StatusTemporaryRedirect)
 }
 
@@ -107,6 +278,56 @@ func (s *server) authMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// WhoAmIResponse describes the authenticated session. It deliberately omits
+// the token and its JWT ID; SessionID is derived from the latter.
+type WhoAmIResponse struct {
+	UserID           string    `json:"user_id"`
+	Username         string    `json:"username"`
+	OrganizationID   string    `json:"organization_id"`
+	Roles            []string  `json:"roles"`
+	Permissions      []string  `json:"permissions"`
+	SessionID        string    `json:"session_id,omitempty"`
+	IssuedAt         time.Time `json:"issued_at"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	ExpiresInSeconds int64     `json:"expires_in_seconds"`
+}
+
+// handleWhoAmI returns the caller's identity, permissions and remaining token
+// lifetime so the UI can hide actions it may not perform and refresh early.
+// GET /api/whoami
+func (s *server) handleWhoAmI(w http.ResponseWriter, r *http.Request) {
+	claims, ok := r.Context().Value(userContextKey).(*auth.Claims)
+	if !ok {
+		respondWithError(w, http.StatusUnauthorized, "no user in context")
+		return
+	}
+
+	resp := WhoAmIResponse{
+		UserID:         claims.UserID,
+		Username:       claims.Email,
+		OrganizationID: claims.OrganizationID,
+		Roles:          []string{string(claims.Role)},
+		Permissions:    []string{},
+		SessionID:      claims.SessionID(),
+	}
+	for _, p := range claims.Role.Permissions() {
+		resp.Permissions = append(resp.Permissions, p.String())
+	}
+	if claims.IssuedAt != nil {
+		resp.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		resp.ExpiresAt = claims.ExpiresAt.Time
+		if remaining := time.Until(claims.ExpiresAt.Time); remaining > 0 {
+			resp.ExpiresInSeconds = int64(remaining.Seconds())
+		}
+	}
+
+	// Session details must never be cached by intermediaries
+	w.Header().Set("Cache-Control", "no-store")
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
 func (s *server) requirePermission(permission auth.Permission, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		userClaims, ok := r.Context().Value(userContextKey).(*auth.Claims)
@@ -124,17 +345,22 @@ func (s *server) requirePermission(permission auth.Permission, next http.Handler
 	}
 }
 
-// getSSOProvider is a helper to instantiate an SSO provider by name.
-func (s *server) getSSOProvider(name string) (auth.SSOProvider, error) {
-	switch name {
-	case "google":
-		return auth.NewGoogleWorkspaceProvider(s.config.SSO.Google.ClientID, s.config.SSO.Google.ClientSecret), nil
-	case "okta":
-		return auth.NewOktaProvider(s.config.SSO.Okta.Domain, s.config.SSO.Okta.ClientID, s.config.SSO.Okta.ClientSecret), nil
-	case "azure":
-		return auth.NewAzureADProvider(s.config.SSO.Azure.TenantID, s.config.SSO.Azure.ClientID, s.config.SSO.Azure.ClientSecret), nil
-	default:
-		return nil, fmt.Errorf("unknown provider: %s", name)
+// requireRole lets next run only for callers holding one of roles
+func (s *server) requireRole(next http.HandlerFunc, roles ...auth.Role) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userClaims, ok := r.Context().Value(userContextKey).(*auth.Claims)
+		if !ok {
+			respondWithError(w, http.StatusUnauthorized, "no user in context")
+			return
+		}
+
+		for _, role := range roles {
+			if userClaims.Role == role {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+		respondWithError(w, http.StatusForbidden, "insufficient permissions")
 	}
 }
 
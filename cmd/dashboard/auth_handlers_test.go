@@ -1,10 +1,12 @@
 package main
 
 import (
-	"errors"
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,30 +14,36 @@ import (
 	"github.com/Xover-Official/Xover/internal/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
-// MockSSOProvider is a mock implementation of the SSOProvider interface
-type MockSSOProvider struct {
+// MockOIDCProvider is a mock implementation of the auth.OIDCProvider interface
+type MockOIDCProvider struct {
 	mock.Mock
 }
 
-func (m *MockSSOProvider) Authenticate(ctx context.Context, code string) (*auth.SSOUser, error) {
-	args := m.Called(ctx, code)
-	var user *auth.SSOUser
+func (m *MockOIDCProvider) AuthCodeURL(state, redirectURI string) string {
+	return "https://idp.example.com/authorize?state=" + url.QueryEscape(state) + "&redirect_uri=" + url.QueryEscape(redirectURI)
+}
+
+func (m *MockOIDCProvider) Exchange(ctx context.Context, code, redirectURI string) (*auth.OAuthToken, error) {
+	args := m.Called(ctx, code, redirectURI)
+	var token *auth.OAuthToken
 	if args.Get(0) != nil {
-		user = args.Get(0).(*auth.SSOUser)
+		token = args.Get(0).(*auth.OAuthToken)
 	}
-	return user, args.Error(1)
+	return token, args.Error(1)
 }
 
-func (m *MockSSOProvider) ValidateToken(ctx context.Context, token string) (*auth.SSOUser, error) {
+func (m *MockOIDCProvider) UserInfo(ctx context.Context, token *auth.OAuthToken) (*auth.SSOUser, error) {
 	args := m.Called(ctx, token)
-	return args.Get(0).(*auth.SSOUser), args.Error(1)
-}
-
-func (m *MockSSOProvider) GetAuthURL(redirectURI string) string {
-	args := m.Called(redirectURI)
-	return args.String(0)
+	var user *auth.SSOUser
+	if args.Get(0) != nil {
+		user = args.Get(0).(*auth.SSOUser)
+	}
+	return user, args.Error(1)
 }
 
 // MockUserStore is a mock implementation of the UserStore interface
@@ -89,96 +97,233 @@ func TestAuthMiddleware(t *testing.T) {
 	})
 }
 
+func testAuthConfig() *config.Config {
+	return &config.Config{JWT: config.JWTConfig{
+		SecretKey:       "0123456789abcdef0123456789abcdef",
+		TokenDuration:   time.Hour,
+		RefreshDuration: 7 * 24 * time.Hour,
+	}}
+}
+
+func findCookie(rr *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, cookie := range rr.Result().Cookies() {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
+
 func TestHandleLogin(t *testing.T) {
 	srv := &server{
-		config: &config.Config{
-			SSO: config.SSOConfig{
-				Google: config.SSOProviderConfig{ClientID: "test-client-id", ClientSecret: "test-client-secret"},
-			},
-		},
+		logger: zap.NewNop(),
+		config: testAuthConfig(),
+		oidc: newOIDCRegistry(config.SSOConfig{
+			Google: config.SSOProviderConfig{ClientID: "test-client-id", ClientSecret: "test-client-secret"},
+		}),
 	}
-	req := httptest.NewRequest("GET", "/auth/login/google", nil)
-	rr := httptest.NewRecorder()
-	srv.handleLogin(rr, req)
-	assert.Equal(t, http.StatusTemporaryRedirect, rr.Code)
-	assert.Contains(t, rr.Header().Get("Location"), "accounts.google.com")
+
+	t.Run("Redirects to the provider with a state cookie", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/auth/login/google", nil)
+		rr := httptest.NewRecorder()
+		srv.handleLogin(rr, req)
+
+		assert.Equal(t, http.StatusTemporaryRedirect, rr.Code)
+		location, err := url.Parse(rr.Header().Get("Location"))
+		require.NoError(t, err)
+		assert.Equal(t, "accounts.google.com", location.Host)
+		assert.Equal(t, "http://example.com/auth/callback/google", location.Query().Get("redirect_uri"))
+
+		cookie := findCookie(rr, oauthStateCookie)
+		require.NotNil(t, cookie)
+		assert.NoError(t, srv.oauthStates().Verify("google", location.Query().Get("state"), cookie.Value))
+		assert.Equal(t, "/auth/callback/google", cookie.Path)
+		assert.True(t, cookie.HttpOnly)
+	})
+
+	t.Run("Unconfigured provider is rejected", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/auth/login/github", nil)
+		rr := httptest.NewRecorder()
+		srv.handleLogin(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "unknown sign-in provider")
+	})
 }
 
 func TestHandleCallback(t *testing.T) {
 	jwtMgr := auth.NewJWTManager("test-secret", time.Hour)
-	mockSSOProvider := new(MockSSOProvider)
+	provider := new(MockOIDCProvider)
 	mockUserStore := new(MockUserStore)
 
+	registry := auth.NewOIDCRegistry()
+	registry.Register("mock", provider)
+	core, logs := observer.New(zap.WarnLevel)
 	srv := &server{
 		jwtManager: jwtMgr,
 		userStore:  mockUserStore,
-		logger:     zap.NewNop(),
-		config: &config.Config{
-			SSO: config.SSOConfig{
-				Google: config.SSOProviderConfig{ClientID: "test-client-id", ClientSecret: "test-client-secret"},
-			},
-		},
+		logger:     zap.New(core),
+		config:     testAuthConfig(),
+		oidc:       registry,
 	}
 
-	// Override the getSSOProvider to return our mock
-	srv.getSSOProvider = func(name string) (auth.SSOProvider, error) {
-		if name == "google" {
-			return mockSSOProvider, nil
+	callback := func(query, stateCookie string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/auth/callback/mock?"+query, nil)
+		if stateCookie != "" {
+			req.AddCookie(&http.Cookie{Name: oauthStateCookie, Value: stateCookie})
 		}
-		return nil, errors.New("unknown provider")
+		rr := httptest.NewRecorder()
+		srv.handleCallback(rr, req)
+		return rr
 	}
+	redirectURI := "http://example.com/auth/callback/mock"
+	state, stateCookie, err := srv.oauthStates().Issue("mock")
+	require.NoError(t, err)
 
-	t.Run("Successful callback", func(t *testing.T) {
-		ssoUser := &auth.SSOUser{ID: "sso-123", Email: "test@example.com"}
+	t.Run("Successful callback issues the token pair", func(t *testing.T) {
+		token := &auth.OAuthToken{AccessToken: "provider-token"}
+		ssoUser := &auth.SSOUser{ID: "sso-123", Email: "test@example.com", Provider: "mock"}
 		dbUser := &auth.User{ID: "user-1", Email: "test@example.com", Role: auth.RoleAdmin}
 
-		mockSSOProvider.On("Authenticate", mock.Anything, "good-code").Return(ssoUser, nil).Once()
+		provider.On("Exchange", mock.Anything, "good-code", redirectURI).Return(token, nil).Once()
+		provider.On("UserInfo", mock.Anything, token).Return(ssoUser, nil).Once()
 		mockUserStore.On("Upsert", ssoUser).Return(dbUser, nil).Once()
 
-		req := httptest.NewRequest("GET", "/auth/callback/google?code=good-code", nil)
-		rr := httptest.NewRecorder()
-
-		srv.handleCallback(rr, req)
+		rr := callback("code=good-code&state="+state, stateCookie)
 
 		assert.Equal(t, http.StatusTemporaryRedirect, rr.Code)
 		assert.Equal(t, "/", rr.Header().Get("Location"))
 
-		cookie := rr.Result().Cookies()[0]
-		assert.Equal(t, "atlas_token", cookie.Name)
-		assert.NotEmpty(t, cookie.Value)
-
-		claims, err := jwtMgr.Verify(cookie.Value)
-		assert.NoError(t, err)
-		assert.Equal(t, dbUser.ID, claims.ID)
+		access := findCookie(rr, "atlas_token")
+		require.NotNil(t, access)
+		claims, err := jwtMgr.Verify(access.Value)
+		require.NoError(t, err)
+		assert.Equal(t, dbUser.ID, claims.UserID)
 		assert.Equal(t, dbUser.Email, claims.Email)
 
-		mockSSOProvider.AssertExpectations(t)
+		refresh := findCookie(rr, refreshCookie)
+		require.NotNil(t, refresh)
+		assert.Equal(t, "/auth/refresh", refresh.Path)
+		refreshClaims, err := jwtMgr.VerifyRefresh(refresh.Value)
+		require.NoError(t, err)
+		assert.Equal(t, dbUser.ID, refreshClaims.UserID)
+
+		state := findCookie(rr, oauthStateCookie)
+		require.NotNil(t, state)
+		assert.Equal(t, -1, state.MaxAge, "the state is single-use")
+
+		provider.AssertExpectations(t)
 		mockUserStore.AssertExpectations(t)
 	})
 
-	t.Run("SSO authentication fails", func(t *testing.T) {
-		mockSSOProvider.On("Authenticate", mock.Anything, "bad-code").Return(nil, errors.New("invalid code")).Once()
+	t.Run("Tampered state is rejected before the exchange", func(t *testing.T) {
+		rr := callback("code=intercepted-code&state="+state+"x", stateCookie)
 
-		req := httptest.NewRequest("GET", "/auth/callback/google?code=bad-code", nil)
-		rr := httptest.NewRecorder()
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "invalid sign-in state")
+		assert.Nil(t, findCookie(rr, "atlas_token"))
+		provider.AssertNotCalled(t, "Exchange", mock.Anything, "intercepted-code", mock.Anything)
+
+		events := logs.FilterMessage("security event: sso callback state rejected").TakeAll()
+		require.Len(t, events, 1)
+		assert.Equal(t, "mock", events[0].ContextMap()["provider"])
+	})
+
+	t.Run("Unsigned state cookie is rejected", func(t *testing.T) {
+		// An attacker who can plant a cookie pairs it with their own state
+		forged := "attacker-state" + stateCookie[strings.Index(stateCookie, "."):]
+		rr := callback("code=intercepted-code&state=attacker-state", forged)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "invalid sign-in state")
+	})
+
+	t.Run("State issued for another provider is rejected", func(t *testing.T) {
+		otherState, otherCookie, err := srv.oauthStates().Issue("google")
+		require.NoError(t, err)
+		rr := callback("code=intercepted-code&state="+otherState, otherCookie)
 
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "invalid sign-in state")
+	})
+
+	t.Run("Missing state cookie is rejected", func(t *testing.T) {
+		rr := callback("code=intercepted-code&state="+state, "")
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "invalid sign-in state")
+	})
+
+	t.Run("Provider error is reported", func(t *testing.T) {
+		rr := callback("error=access_denied&state="+state, stateCookie)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+		assert.Contains(t, rr.Body.String(), "cancelled or denied")
+	})
+
+	t.Run("Unknown provider is rejected", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/auth/callback/okta?code=x&state=s", nil)
+		rr := httptest.NewRecorder()
 		srv.handleCallback(rr, req)
 
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("SSO authentication fails", func(t *testing.T) {
+		provider.On("Exchange", mock.Anything, "bad-code", redirectURI).Return(nil, errors.New("invalid code")).Once()
+
+		rr := callback("code=bad-code&state="+state, stateCookie)
+
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 		assert.Contains(t, rr.Body.String(), "sso authentication failed")
-		mockSSOProvider.AssertExpectations(t)
+		provider.AssertExpectations(t)
 	})
 
 	t.Run("User resolution fails", func(t *testing.T) {
-		ssoUser := &auth.SSOUser{ID: "sso-123", Email: "test@example.com"}
-		mockSSOProvider.On("Authenticate", mock.Anything, "good-code-bad-user").Return(ssoUser, nil).Once()
+		token := &auth.OAuthToken{AccessToken: "provider-token-2"}
+		ssoUser := &auth.SSOUser{ID: "sso-456", Email: "other@example.com"}
+		provider.On("Exchange", mock.Anything, "good-code-bad-user", redirectURI).Return(token, nil).Once()
+		provider.On("UserInfo", mock.Anything, token).Return(ssoUser, nil).Once()
 		mockUserStore.On("Upsert", ssoUser).Return(nil, errors.New("db error")).Once()
 
-		req := httptest.NewRequest("GET", "/auth/callback/google?code=good-code-bad-user", nil)
-		rr := httptest.NewRecorder()
-		srv.handleCallback(rr, req)
+		rr := callback("code=good-code-bad-user&state="+state, stateCookie)
 
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 		assert.Contains(t, rr.Body.String(), "failed to process user login")
 	})
 }
+
+func TestHandleRefresh(t *testing.T) {
+	jwtMgr := auth.NewJWTManager("test-secret", time.Hour)
+	srv := &server{jwtManager: jwtMgr, logger: zap.NewNop(), config: testAuthConfig()}
+	user := auth.User{ID: "user-1", Email: "test@example.com", Role: auth.RoleOperator}
+	pair, err := jwtMgr.GeneratePair(user, time.Hour)
+	require.NoError(t, err)
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/auth/refresh", nil)
+		req.AddCookie(&http.Cookie{Name: refreshCookie, Value: token})
+		rr := httptest.NewRecorder()
+		srv.handleRefresh(rr, req)
+		return rr
+	}
+
+	t.Run("Refresh token issues a new access token", func(t *testing.T) {
+		rr := refresh(pair.RefreshToken)
+
+		assert.Equal(t, http.StatusNoContent, rr.Code)
+		access := findCookie(rr, "atlas_token")
+		require.NotNil(t, access)
+		claims, err := jwtMgr.Verify(access.Value)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, claims.UserID)
+		assert.Equal(t, user.Role, claims.Role)
+	})
+
+	t.Run("Access token is not a refresh token", func(t *testing.T) {
+		rr := refresh(pair.AccessToken)
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+		assert.Nil(t, findCookie(rr, "atlas_token"))
+	})
+}
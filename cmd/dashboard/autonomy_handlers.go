@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Xover-Official/Xover/internal/auth"
+	"github.com/Xover-Official/Xover/internal/features"
+	"go.uber.org/zap"
+)
+
+// Autonomy flags decide which actions run without approval, so changing them
+// is a settings change.
+var (
+	autonomyReadPermission  = auth.Permission{Resource: "settings", Action: "read"}
+	autonomyWritePermission = auth.Permission{Resource: "settings", Action: "write"}
+)
+
+// handleGetAutonomy returns the current autonomy flags.
+// GET /api/autonomy
+func (s *server) handleGetAutonomy(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.autonomy.Refresh(r.Context()); err != nil {
+		s.logger.Warn("failed to refresh autonomy flags", zap.Error(err))
+	}
+	respondWithJSON(w, http.StatusOK, s.autonomy.Flags())
+}
+
+// handleUpdateAutonomy replaces the autonomy flags. Engines pick the change up
+// at the start of their next cycle; it is audited with the old and new flags.
+// PUT /api/autonomy
+func (s *server) handleUpdateAutonomy(w http.ResponseWriter, r *http.Request) {
+	var flags features.AutonomyFlags
+	if err := decodeJSONBody(w, r, &flags); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := flags.Validate(); err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var actor string
+	if claims, ok := r.Context().Value(userContextKey).(*auth.Claims); ok {
+		actor = claims.UserID
+	}
+	previous, err := s.autonomy.Update(r.Context(), flags, actor)
+	if err != nil {
+		s.logger.Error("failed to update autonomy flags", zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "failed to update autonomy flags")
+		return
+	}
+
+	current := s.autonomy.Flags()
+	s.logger.Info("autonomy flags updated",
+		zap.String("updated_by", actor),
+		zap.String("default", string(current.Default)),
+		zap.Int("rules", len(current.Rules)),
+	)
+	if s.repository != nil {
+		s.audit(r, "autonomy.update", "settings", "autonomy", map[string]interface{}{"from": previous, "to": current})
+	}
+	respondWithJSON(w, http.StatusOK, current)
+}
@@ -40,16 +40,20 @@ func (s *server) performCacheRefresh(ctx context.Context) {
 	defer cancel()
 
 	resources, err := s.adapter.FetchResources(fetchCtx)
-	if err != nil {
+	if _, ok := cloud.PartialFetch(err); ok {
+		s.logger.Warn("some regions failed during resource cache refresh", zap.Error(err))
+	} else if err != nil {
 		s.logger.Error("failed to fetch resources for cache", zap.Error(err))
 		return // Keep stale data on failure
 	}
 
+	fetchedAt := time.Now()
 	s.resourceCache.Lock()
 	s.resourceCache.resources = resources
-	s.resourceCache.fetchedAt = time.Now()
+	s.resourceCache.fetchedAt = fetchedAt
 	s.resourceCache.Unlock()
 	s.logger.Info("resource cache updated successfully", zap.Int("count", len(resources)))
+	s.hub.publishResources(resources, fetchedAt)
 
 	// Now, update derived caches
 	s.updateResourceMetricsCache(resources)
@@ -119,6 +123,7 @@ func (s *server) updateOptimizationSuggestionsCache(resources []*cloud.ResourceV
 		Suggestions:           suggestions,
 		TotalSuggestions:      len(suggestions),
 		TotalPotentialSavings: calculateTotalSavings(suggestions),
+		QuickWinSavings:       calculateQuickWinSavings(suggestions),
 		Timestamp:             time.Now(),
 	}
 
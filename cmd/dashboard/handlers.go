@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -10,61 +11,68 @@ import (
 
 // --- API Handlers ---
 
+// defaultROIWindow is how far back /api/roi looks by default
+const defaultROIWindow = 30 * 24 * time.Hour
+
+// handleROI reports realized savings against AI spend.
+// GET /api/roi?window=720h
 func (s *server) handleROI(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	resp := ROIResponse{
-		Period: "30 days",
+	if !s.requireRepository(w) {
+		return
 	}
-	resp.ROI.TotalSavings = 1250.50
-	resp.ROI.TotalCosts = 342.75
-	resp.ROI.NetROI = 907.75
-	resp.ROI.ROIPercentage = 264.8
 
-	json.NewEncoder(w).Encode(resp)
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		s.logger.Error("failed to write response", zap.Error(err))
+	window := defaultROIWindow
+	if raw := r.URL.Query().Get("window"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d <= 0 {
+			respondWithError(w, http.StatusBadRequest, "invalid window duration")
+			return
+		}
+		window = d
 	}
-}
 
-func (s *server) handleTokenBreakdown(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	stats := s.tracker.GetStats()
+	summary, err := s.repository.GetROISummary(r.Context(), requestOrgID(r), window)
+	if err != nil {
+		s.respondWithRepositoryError(w, err, "failed to compute ROI")
+		return
+	}
 
-	// Safely extract values from the map to prevent panics from type assertions.
-	safeFloat64 := func(m map[string]interface{}, key string) float64 {
-		if val, ok := m[key].(float64); ok {
-			return val
-		}
-		return 0.0
+	resp := ROIResponse{Period: formatWindow(window), ByType: summary.ByType}
+	resp.ROI.TotalSavings = summary.TotalSavings
+	resp.ROI.TotalCosts = summary.TotalAICost
+	resp.ROI.NetROI = summary.NetROI
+	resp.ROI.ROIPercentage = summary.ROIPercentage
+	respondWithJSON(w, http.StatusOK, resp)
+}
+
+// formatWindow describes a window in days when it is a whole number of them
+func formatWindow(window time.Duration) string {
+	const day = 24 * time.Hour
+	if window%day != 0 {
+		return window.String()
 	}
-	safeInt := func(m map[string]interface{}, key string) int {
-		if val, ok := m[key].(float64); ok { // JSON numbers are often float64
-			return int(val)
-		}
-		if val, ok := m[key].(int); ok {
-			return val
-		}
-		return 0
+	if days := window / day; days != 1 {
+		return fmt.Sprintf("%d days", days)
 	}
+	return "1 day"
+}
+
+// handleTokenBreakdown reports AI token usage and cost per tier.
+// GET /api/token-breakdown
+func (s *server) handleTokenBreakdown(w http.ResponseWriter, r *http.Request) {
+	snap := s.tracker.GetSnapshot()
 
 	resp := TokenBreakdownResponse{
-		TotalCostUSD:    safeFloat64(stats, "total_cost_usd"),
-		TotalTokens:     safeInt(stats, "total_tokens"),
-		TotalSavingsUSD: safeFloat64(stats, "total_savings_usd"),
-		NetProfitUSD:    safeFloat64(stats, "net_profit_usd"),
-		Breakdown: map[string]ModelTokenInfo{
-			"sentinel":   {Tokens: 1500, Cost: 0.75},
-			"strategist": {Tokens: 800, Cost: 1.20},
-			"arbiter":    {Tokens: 400, Cost: 0.80},
-			"reasoning":  {Tokens: 200, Cost: 0.60},
-		},
+		TotalCostUSD:    snap.TotalCostUSD,
+		TotalTokens:     snap.TotalTokens,
+		TotalSavingsUSD: snap.TotalSavingsUSD,
+		NetProfitUSD:    snap.NetProfitUSD,
+		Breakdown:       make(map[string]ModelTokenInfo, len(snap.TierBreakdown)),
 	}
-	json.NewEncoder(w).Encode(resp)
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		s.logger.Error("failed to write response", zap.Error(err))
+	for tier, stats := range snap.TierBreakdown {
+		resp.Breakdown[tier] = ModelTokenInfo{Tokens: stats.Tokens, Cost: stats.CostUSD, Requests: stats.Requests}
 	}
+	respondWithJSON(w, http.StatusOK, resp)
 }
 
 func (s *server) handleSystemStatus(w http.ResponseWriter, r *http.Request) {
@@ -92,23 +100,58 @@ func (s *server) handleSystemStatus(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// handleResources serves a page of the cached resources. total_count is how
+// many match the filters; without a limit every match is returned.
+// GET /api/resources?provider=aws&type=ec2&region=us-east-1&sort=cost_desc&limit=50&offset=0
 func (s *server) handleResources(w http.ResponseWriter, r *http.Request) {
+	query, err := parseResourceQuery(r.URL.Query())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	// The cache is now updated by a background worker.
 	// This handler just serves the latest cached data.
 	s.resourceCache.RLock()
-	defer s.resourceCache.RUnlock()
+	page, total := query.apply(s.resourceCache.resources)
+	fetchedAt := s.resourceCache.fetchedAt
+	s.resourceCache.RUnlock()
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	resp := ResourcesResponse{
-		Resources:   s.resourceCache.resources,
-		TotalCount:  len(s.resourceCache.resources),
-		LastUpdated: s.resourceCache.fetchedAt,
+	respondWithJSON(w, http.StatusOK, ResourcesResponse{
+		Resources:   page,
+		TotalCount:  total,
+		Offset:      query.offset,
+		Limit:       query.limit,
+		LastUpdated: fetchedAt,
+	})
+}
+
+// defaultActionCooldown is how long a resource is left alone after a completed action.
+const defaultActionCooldown = 24 * time.Hour
+
+// handleResourceHistory returns the full optimization timeline for one resource.
+// GET /api/resources/{id}/history?cooldown=24h
+func (s *server) handleResourceHistory(w http.ResponseWriter, r *http.Request) {
+	if !s.requireRepository(w) {
+		return
 	}
-	json.NewEncoder(w).Encode(resp)
-	if err := json.NewEncoder(w).Encode(resp); err != nil {
-		s.logger.Error("failed to write response", zap.Error(err))
+
+	cooldown := defaultActionCooldown
+	if raw := r.URL.Query().Get("cooldown"); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			respondWithError(w, http.StatusBadRequest, "invalid cooldown duration")
+			return
+		}
+		cooldown = d
+	}
+
+	history, err := s.repository.GetResourceHistory(r.Context(), r.PathValue("id"), cooldown)
+	if err != nil {
+		s.respondWithRepositoryError(w, err, "failed to load resource history")
+		return
 	}
+	respondWithJSON(w, http.StatusOK, history)
 }
 
 func (s *server) handleHealthz(w http.ResponseWriter, r *http.Request) {
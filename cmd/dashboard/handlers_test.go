@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// historyRepository serves one resource's history and records the cooldown
+// it was asked for
+type historyRepository struct {
+	Repository
+	history  *database.ResourceHistory
+	err      error
+	cooldown time.Duration
+}
+
+func (h *historyRepository) GetResourceHistory(_ context.Context, resourceID string, cooldown time.Duration) (*database.ResourceHistory, error) {
+	h.cooldown = cooldown
+	if h.err != nil {
+		return nil, h.err
+	}
+	if resourceID != h.history.ResourceID {
+		return nil, fmt.Errorf("resource %s: %w", resourceID, database.ErrNotFound)
+	}
+	return h.history, nil
+}
+
+func TestHandleResourceHistory(t *testing.T) {
+	lastAction := time.Date(2026, 5, 14, 9, 0, 0, 0, time.UTC)
+	cooldownUntil := lastAction.Add(defaultActionCooldown)
+	status, savings := "completed", 42.5
+	history := &database.ResourceHistory{
+		ResourceID: "i-0abc",
+		Entries: []*database.ResourceHistoryEntry{
+			{Type: database.HistoryEntryAction, ID: "a-1", Timestamp: lastAction, Summary: "resize", Status: &status},
+			{Type: database.HistoryEntrySavings, ID: "s-1", Timestamp: lastAction.Add(time.Hour), Summary: "resize", ActualSavings: &savings},
+		},
+		LastActionAt:  &lastAction,
+		CooldownUntil: &cooldownUntil,
+		InCooldown:    true,
+	}
+
+	tests := []struct {
+		name         string
+		repo         Repository
+		path         string
+		want         int
+		wantCooldown time.Duration
+	}{
+		{"default cooldown", &historyRepository{history: history}, "/api/resources/i-0abc/history", http.StatusOK, defaultActionCooldown},
+		{"custom cooldown", &historyRepository{history: history}, "/api/resources/i-0abc/history?cooldown=90m", http.StatusOK, 90 * time.Minute},
+		{"zero cooldown", &historyRepository{history: history}, "/api/resources/i-0abc/history?cooldown=0s", http.StatusOK, 0},
+		{"unknown resource", &historyRepository{history: history}, "/api/resources/i-missing/history", http.StatusNotFound, defaultActionCooldown},
+		{"malformed cooldown", &historyRepository{history: history}, "/api/resources/i-0abc/history?cooldown=soon", http.StatusBadRequest, 0},
+		{"negative cooldown", &historyRepository{history: history}, "/api/resources/i-0abc/history?cooldown=-1h", http.StatusBadRequest, 0},
+		{"repository failure", &historyRepository{err: errors.New("connection refused")}, "/api/resources/i-0abc/history", http.StatusInternalServerError, defaultActionCooldown},
+		{"no database", nil, "/api/resources/i-0abc/history", http.StatusServiceUnavailable, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := adminRequest(t, newAdminServer(tt.repo), http.MethodGet, tt.path, "")
+			assert.Equal(t, tt.want, rec.Code, rec.Body.String())
+			if repo, ok := tt.repo.(*historyRepository); ok {
+				assert.Equal(t, tt.wantCooldown, repo.cooldown)
+			}
+			if tt.want != http.StatusOK {
+				var body map[string]string
+				decodeBody(t, rec, &body)
+				assert.NotEmpty(t, body["error"])
+				return
+			}
+
+			var body map[string]interface{}
+			decodeBody(t, rec, &body)
+			assert.Equal(t, "i-0abc", body["resource_id"])
+			assert.Equal(t, true, body["in_cooldown"])
+			assert.Equal(t, false, body["pending_approval"])
+			assert.Equal(t, "2026-05-15T09:00:00Z", body["cooldown_until"])
+			assert.NotContains(t, body, "pending_action_id", "omitted when nothing awaits approval")
+
+			entries, ok := body["entries"].([]interface{})
+			require.True(t, ok)
+			require.Len(t, entries, 2)
+			first := entries[0].(map[string]interface{})
+			assert.Equal(t, "action", first["type"])
+			assert.Equal(t, "completed", first["status"])
+			assert.NotContains(t, first, "actual_savings")
+			assert.Equal(t, 42.5, entries[1].(map[string]interface{})["actual_savings"])
+		})
+	}
+}
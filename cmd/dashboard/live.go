@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/engine"
+	"github.com/Xover-Official/Xover/internal/monitoring"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// Live update event types
+const (
+	liveEventSnapshot  = "snapshot"   // the resource cache, sent once on connect
+	liveEventResources = "resources"  // the resource cache after a refresh
+	liveEventAlert     = "alert"      // an alert that fired
+	liveEventOODACycle = "ooda_cycle" // a completed OODA cycle
+)
+
+const (
+	liveSendBuffer = 16               // events queued per client before it is dropped as too slow
+	liveWriteWait  = 10 * time.Second // time allowed to write one message
+	livePongWait   = 60 * time.Second // time allowed between pongs
+	livePingPeriod = livePongWait * 9 / 10
+	liveMaxMessage = 512 // clients only send control frames
+)
+
+// errLiveHubClosed is returned by register once the hub has shut down.
+var errLiveHubClosed = errors.New("live updates are shutting down")
+
+// liveUpgrader keeps gorilla's same-origin check, since the session
+// cookie would otherwise let any site open a socket as the user.
+var liveUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// liveEvent is the JSON message pushed to live update clients.
+type liveEvent struct {
+	Type      string      `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+// liveResources is the data of snapshot and resources events.
+type liveResources struct {
+	Resources  []*cloud.ResourceV2 `json:"resources"`
+	TotalCount int                 `json:"total_count"`
+	FetchedAt  time.Time           `json:"fetched_at"`
+}
+
+// liveHub broadcasts events to every connected WebSocket client. Each client
+// has its own send buffer; a client that lets it fill is disconnected rather
+// than holding up the others. A nil hub drops every event.
+//
+// The cache refresh publishes to the hub itself. An OODA engine or alert
+// manager run in the dashboard process publishes through
+// SetCycleListener(hub.publishCycle) and SetAlertListener(hub.publishAlert).
+type liveHub struct {
+	logger *zap.Logger
+
+	mu      sync.Mutex
+	clients map[*liveClient]struct{}
+	closed  bool
+}
+
+type liveClient struct {
+	hub  *liveHub
+	conn *websocket.Conn
+	send chan []byte // closed by the hub when the client is removed
+}
+
+func newLiveHub(logger *zap.Logger) *liveHub {
+	return &liveHub{
+		logger:  logger,
+		clients: make(map[*liveClient]struct{}),
+	}
+}
+
+// publish sends an event to every client.
+func (h *liveHub) publish(eventType string, data interface{}) {
+	if h == nil {
+		return
+	}
+	message, err := json.Marshal(liveEvent{Type: eventType, Timestamp: time.Now(), Data: data})
+	if err != nil {
+		h.logger.Error("failed to encode live event", zap.String("type", eventType), zap.Error(err))
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.clients {
+		select {
+		case client.send <- message:
+		default:
+			h.logger.Warn("dropping slow live update client", zap.String("remote", client.conn.RemoteAddr().String()))
+			h.remove(client)
+		}
+	}
+}
+
+// publishResources announces a resource cache refresh.
+func (h *liveHub) publishResources(resources []*cloud.ResourceV2, fetchedAt time.Time) {
+	h.publish(liveEventResources, liveResources{Resources: resources, TotalCount: len(resources), FetchedAt: fetchedAt})
+}
+
+// publishAlert is an AlertManager listener; see SetAlertListener.
+func (h *liveHub) publishAlert(alert monitoring.Alert) {
+	h.publish(liveEventAlert, alert)
+}
+
+// publishCycle is an OODA engine listener; see SetCycleListener.
+func (h *liveHub) publishCycle(summary engine.CycleSummary) {
+	h.publish(liveEventOODACycle, summary)
+}
+
+// register adds a client whose first message is snapshot. Taking the hub
+// lock for both means no event published after the snapshot was taken is
+// missed, and none published before it is sent after it.
+func (h *liveHub) register(conn *websocket.Conn, snapshot func() interface{}) (*liveClient, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, errLiveHubClosed
+	}
+
+	message, err := json.Marshal(liveEvent{Type: liveEventSnapshot, Timestamp: time.Now(), Data: snapshot()})
+	if err != nil {
+		return nil, err
+	}
+	client := &liveClient{hub: h, conn: conn, send: make(chan []byte, liveSendBuffer)}
+	client.send <- message
+	h.clients[client] = struct{}{}
+	return client, nil
+}
+
+// unregister removes a client that disconnected.
+func (h *liveHub) unregister(client *liveClient) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(client)
+}
+
+// remove closes the client's send buffer, which makes its writer close the
+// connection. Callers hold h.mu.
+func (h *liveHub) remove(client *liveClient) {
+	if _, ok := h.clients[client]; ok {
+		delete(h.clients, client)
+		close(client.send)
+	}
+}
+
+// shutdown disconnects every client and refuses new ones.
+func (h *liveHub) shutdown() {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for client := range h.clients {
+		h.remove(client)
+	}
+}
+
+// writePump writes queued events and pings to the connection. It is the
+// connection's only writer.
+func (c *liveClient) writePump() {
+	ticker := time.NewTicker(livePingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case message, ok := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+			if !ok {
+				c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+				return
+			}
+			if err := c.conn.WriteMessage(websocket.TextMessage, message); err != nil {
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(liveWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readPump reads until the connection fails or the client stops answering
+// pings, then unregisters the client. Messages from the client are ignored.
+func (c *liveClient) readPump() {
+	defer func() {
+		c.hub.unregister(c)
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(liveMaxMessage)
+	c.conn.SetReadDeadline(time.Now().Add(livePongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(livePongWait))
+	})
+	for {
+		if _, _, err := c.conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// handleLiveUpdates upgrades to a WebSocket that receives a snapshot of the
+// resource cache, then every cache refresh, fired alert and completed OODA
+// cycle as it happens.
+// GET /api/ws
+func (s *server) handleLiveUpdates(w http.ResponseWriter, r *http.Request) {
+	if s.hub == nil {
+		respondWithError(w, http.StatusServiceUnavailable, "live updates are not available")
+		return
+	}
+
+	conn, err := liveUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // the upgrader has already replied
+	}
+	client, err := s.hub.register(conn, s.resourceSnapshot)
+	if err != nil {
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""))
+		conn.Close()
+		return
+	}
+
+	go client.writePump()
+	go client.readPump()
+}
+
+// resourceSnapshot returns the cached resources as a snapshot event's data.
+func (s *server) resourceSnapshot() interface{} {
+	s.resourceCache.RLock()
+	defer s.resourceCache.RUnlock()
+	return liveResources{
+		Resources:  s.resourceCache.resources,
+		TotalCount: len(s.resourceCache.resources),
+		FetchedAt:  s.resourceCache.fetchedAt,
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/auth"
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/engine"
+	"github.com/Xover-Official/Xover/internal/monitoring"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newLiveServer starts the dashboard routes with a hub and a simulator
+// adapter, and returns a session cookie for them
+func newLiveServer(t *testing.T) (*server, *cloud.Simulator, *httptest.Server, *http.Cookie) {
+	t.Helper()
+	simulator := &cloud.Simulator{MockResources: []*cloud.ResourceV2{{ID: "i-1", Provider: "aws", CostPerMonth: 120}}}
+	srv := &server{
+		adapter:    simulator,
+		logger:     zap.NewNop(),
+		jwtManager: auth.NewJWTManager("live-test-secret", time.Hour),
+		hub:        newLiveHub(zap.NewNop()),
+	}
+	srv.performCacheRefresh(context.Background())
+
+	ts := httptest.NewServer(srv.routes())
+	t.Cleanup(func() {
+		srv.hub.shutdown()
+		ts.Close()
+	})
+
+	token, err := srv.jwtManager.Generate(auth.User{ID: "user-1", Email: "viewer@example.com", Role: auth.RoleViewer})
+	require.NoError(t, err)
+	return srv, simulator, ts, &http.Cookie{Name: "atlas_token", Value: token}
+}
+
+func dialLive(t *testing.T, ts *httptest.Server, cookie *http.Cookie) *websocket.Conn {
+	t.Helper()
+	header := http.Header{}
+	header.Add("Cookie", cookie.String())
+	conn, resp, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/api/ws", header)
+	require.NoError(t, err)
+	resp.Body.Close()
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readLive reads the next event, decoding its data into data
+func readLive(t *testing.T, conn *websocket.Conn, data interface{}) string {
+	t.Helper()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	var event struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	require.NoError(t, conn.ReadJSON(&event))
+	require.NoError(t, json.Unmarshal(event.Data, data))
+	return event.Type
+}
+
+func TestLiveUpdates_SnapshotThenRefresh(t *testing.T) {
+	srv, simulator, ts, cookie := newLiveServer(t)
+	conn := dialLive(t, ts, cookie)
+
+	var snapshot liveResources
+	require.Equal(t, liveEventSnapshot, readLive(t, conn, &snapshot))
+	require.Len(t, snapshot.Resources, 1)
+	assert.Equal(t, "i-1", snapshot.Resources[0].ID)
+	assert.Equal(t, 1, snapshot.TotalCount)
+
+	simulator.MockResources = append(simulator.MockResources, &cloud.ResourceV2{ID: "i-2", Provider: "aws", CostPerMonth: 80})
+	srv.performCacheRefresh(context.Background())
+
+	var refreshed liveResources
+	require.Equal(t, liveEventResources, readLive(t, conn, &refreshed))
+	assert.Equal(t, 2, refreshed.TotalCount)
+	assert.Equal(t, "i-2", refreshed.Resources[1].ID)
+	assert.True(t, refreshed.FetchedAt.After(snapshot.FetchedAt))
+}
+
+func TestLiveUpdates_AlertsAndCycles(t *testing.T) {
+	srv, _, ts, cookie := newLiveServer(t)
+	conn := dialLive(t, ts, cookie)
+	readLive(t, conn, &liveResources{})
+
+	am := monitoring.NewAlertManager(log.New(io.Discard, "", 0), nil, nil)
+	am.SetAlertListener(srv.hub.publishAlert)
+	am.BudgetAlertHook(50)(51.25)
+
+	var alert monitoring.Alert
+	require.Equal(t, liveEventAlert, readLive(t, conn, &alert))
+	assert.Equal(t, monitoring.BudgetAlertID, alert.ID)
+	assert.Equal(t, 51.25, alert.Current)
+
+	srv.hub.publishCycle(engine.CycleSummary{ResourcesScanned: 12, ActionsExecuted: 2})
+	var cycle engine.CycleSummary
+	require.Equal(t, liveEventOODACycle, readLive(t, conn, &cycle))
+	assert.Equal(t, 12, cycle.ResourcesScanned)
+	assert.Equal(t, 2, cycle.ActionsExecuted)
+}
+
+func TestLiveUpdates_RequiresSession(t *testing.T) {
+	_, _, ts, _ := newLiveServer(t)
+
+	dialer := *websocket.DefaultDialer
+	_, resp, err := dialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/api/ws", nil)
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+}
+
+func TestLiveUpdates_ShutdownClosesClients(t *testing.T) {
+	srv, _, ts, cookie := newLiveServer(t)
+	conn := dialLive(t, ts, cookie)
+	readLive(t, conn, &liveResources{})
+
+	srv.hub.shutdown()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, _, err := conn.ReadMessage()
+	assert.True(t, websocket.IsCloseError(err, websocket.CloseGoingAway), "got %v", err)
+}
+
+func TestLiveHub_DropsSlowClient(t *testing.T) {
+	hub := newLiveHub(zap.NewNop())
+	registered := make(chan *liveClient, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := liveUpgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		// No writer is started, so nothing drains the send buffer
+		client, err := hub.register(conn, func() interface{} { return nil })
+		require.NoError(t, err)
+		registered <- client
+	}))
+	defer ts.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	client := <-registered
+
+	// The snapshot takes one slot of the buffer
+	for i := 1; i < liveSendBuffer; i++ {
+		hub.publish(liveEventResources, nil)
+	}
+	hub.mu.Lock()
+	assert.Len(t, hub.clients, 1)
+	hub.mu.Unlock()
+
+	hub.publish(liveEventResources, nil)
+	hub.mu.Lock()
+	assert.Empty(t, hub.clients)
+	hub.mu.Unlock()
+
+	drained := 0
+	for range client.send {
+		drained++
+	}
+	assert.Equal(t, liveSendBuffer, drained, "the dropped client's buffer is closed")
+}
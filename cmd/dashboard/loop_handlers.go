@@ -0,0 +1,60 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Xover-Official/Xover/internal/auth"
+	"go.uber.org/zap"
+)
+
+// Pausing the loop stops actions from being proposed, so operators who
+// handle actions may do it.
+var (
+	loopReadPermission  = auth.Permission{Resource: "actions", Action: "read"}
+	loopWritePermission = auth.Permission{Resource: "actions", Action: "write"}
+)
+
+// loopController is the part of loop.OODALoop operators can toggle
+type loopController interface {
+	Pause()
+	Resume()
+	IsPaused() bool
+}
+
+// handleGetLoop reports whether the OODA loop is paused.
+// GET /api/loop
+func (s *server) handleGetLoop(w http.ResponseWriter, r *http.Request) {
+	if s.loop == nil {
+		respondWithError(w, http.StatusServiceUnavailable, "the OODA loop does not run in this process")
+		return
+	}
+	respondWithJSON(w, http.StatusOK, LoopStatusResponse{Paused: s.loop.IsPaused()})
+}
+
+// handleSetLoopPaused pauses or resumes the OODA loop, e.g. for a deploy
+// freeze. The loop keeps its state and picks up again on its next tick.
+// POST /api/loop/pause, POST /api/loop/resume
+func (s *server) handleSetLoopPaused(paused bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.loop == nil {
+			respondWithError(w, http.StatusServiceUnavailable, "the OODA loop does not run in this process")
+			return
+		}
+
+		was := s.loop.IsPaused()
+		action := "loop.resume"
+		if paused {
+			s.loop.Pause()
+			action = "loop.pause"
+		} else {
+			s.loop.Resume()
+		}
+		if was != paused {
+			s.logger.Info("OODA loop toggled", zap.String("action", action))
+			if s.repository != nil {
+				s.audit(r, action, "loop", "ooda", nil)
+			}
+		}
+		respondWithJSON(w, http.StatusOK, LoopStatusResponse{Paused: s.loop.IsPaused()})
+	}
+}
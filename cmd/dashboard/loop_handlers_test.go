@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeLoop struct{ paused bool }
+
+func (f *fakeLoop) Pause()         { f.paused = true }
+func (f *fakeLoop) Resume()        { f.paused = false }
+func (f *fakeLoop) IsPaused() bool { return f.paused }
+
+// loopRequest sends method path as a user with role and decodes the reply
+func loopRequest(t *testing.T, srv *server, role auth.Role, method, path string) (int, LoopStatusResponse) {
+	t.Helper()
+	token, err := srv.jwtManager.Generate(auth.User{ID: "user-1", Email: "ops@example.com", Role: role})
+	require.NoError(t, err)
+	req := httptest.NewRequest(method, path, nil)
+	req.AddCookie(&http.Cookie{Name: "atlas_token", Value: token})
+	rec := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, req)
+
+	var status LoopStatusResponse
+	if rec.Code == http.StatusOK {
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&status))
+	}
+	return rec.Code, status
+}
+
+func newLoopServer(loop loopController) *server {
+	return &server{
+		logger:     zap.NewNop(),
+		jwtManager: auth.NewJWTManager("loop-test-secret", time.Hour),
+		loop:       loop,
+	}
+}
+
+func TestLoopHandlers_PauseAndResume(t *testing.T) {
+	loop := &fakeLoop{}
+	srv := newLoopServer(loop)
+
+	code, status := loopRequest(t, srv, auth.RoleOperator, http.MethodPost, "/api/loop/pause")
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, status.Paused)
+	assert.True(t, loop.paused)
+
+	code, status = loopRequest(t, srv, auth.RoleViewer, http.MethodGet, "/api/loop")
+	require.Equal(t, http.StatusOK, code)
+	assert.True(t, status.Paused)
+
+	code, status = loopRequest(t, srv, auth.RoleOperator, http.MethodPost, "/api/loop/resume")
+	require.Equal(t, http.StatusOK, code)
+	assert.False(t, status.Paused)
+	assert.False(t, loop.paused)
+}
+
+func TestLoopHandlers_ViewerCannotToggle(t *testing.T) {
+	loop := &fakeLoop{}
+	code, _ := loopRequest(t, newLoopServer(loop), auth.RoleViewer, http.MethodPost, "/api/loop/pause")
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.False(t, loop.paused)
+}
+
+func TestLoopHandlers_WithoutLoop(t *testing.T) {
+	code, _ := loopRequest(t, newLoopServer(nil), auth.RoleAdmin, http.MethodGet, "/api/loop")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+}
@@ -6,20 +6,37 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
 
+	"github.com/Xover-Official/Xover/internal/ai"
 	"github.com/Xover-Official/Xover/internal/analytics"
 	"github.com/Xover-Official/Xover/internal/auth"
+	"github.com/Xover-Official/Xover/internal/chaos"
 	"github.com/Xover-Official/Xover/internal/cloud"
 	"github.com/Xover-Official/Xover/internal/cloud/aws"
+	"github.com/Xover-Official/Xover/internal/cloud/metricsource"
+	"github.com/Xover-Official/Xover/internal/concurrency"
 	"github.com/Xover-Official/Xover/internal/config"
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/Xover-Official/Xover/internal/events"
+	"github.com/Xover-Official/Xover/internal/features"
+	"github.com/Xover-Official/Xover/internal/monitoring"
+	"github.com/Xover-Official/Xover/internal/report"
+	"github.com/Xover-Official/Xover/internal/retention"
+	"github.com/Xover-Official/Xover/internal/secrets"
+	"github.com/Xover-Official/Xover/internal/security"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -45,18 +62,25 @@ type suggestionsCache struct {
 
 // server represents the dependency container for the application
 type server struct {
-	tracker      *analytics.TokenTracker
-	orchestrator AIOrchestrator // Use interface for decoupling
-	adapter      cloud.CloudAdapter
-	redisClient  *redis.Client
-	logger       *zap.Logger
-	config       *config.Config
-	jwtManager   *auth.JWTManager
-	userStore    UserStore // Use interface for decoupling
+	tracker          *analytics.TokenTracker
+	orchestrator     AIOrchestrator // Use interface for decoupling
+	adapter          cloud.CloudAdapter
+	redisClient      *redis.Client
+	logger           *zap.Logger
+	config           *config.Config
+	jwtManager       *auth.JWTManager
+	oidc             *auth.OIDCRegistry // sign-in providers by the name in /auth/login/{provider}
+	userStore        UserStore          // Use interface for decoupling
+	repository       Repository         // nil when no database is configured
+	reports          *report.Generator
+	security         *security.SecurityManager
 	mode             string
 	resourceCache    resourceCache
 	metricsCache     metricsCache
 	suggestionsCache suggestionsCache
+	autonomy         *features.AutonomyGate // shared with engines through Redis
+	hub              *liveHub               // pushes live updates to /api/ws clients
+	loop             loopController         // nil unless the OODA loop runs in this process
 }
 
 func main() {
@@ -70,12 +94,16 @@ func main() {
 	}
 	defer logger.Sync()
 
-	// 2. Load configuration
-	cfg, err := config.Load("config.yaml")
+	// 2. Load configuration, reading credentials it leaves unset from the
+	// secret store it names
+	cfg, err := config.LoadWithSecrets("config.yaml", func(s config.SecretsConfig) (config.SecretLookup, error) {
+		return secrets.NewLookup(context.Background(), s.Source, s.RefreshInterval)
+	})
 	if err != nil {
 		logger.Error("failed to load configuration", zap.Error(err))
 		os.Exit(1)
 	}
+	concurrency.SetDefault(concurrency.NewManager(cfg.Server.MaxGoroutines))
 
 	// 3. Parse command-line flags
 	runLoadTest := flag.Bool("run-load-test", false, "Run load test simulation")
@@ -85,27 +113,75 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// External metrics (Datadog/Prometheus) are optional; without them the
+	// adapter uses CloudWatch alone
+	metricsProvider, err := metricsource.New(cfg.Cloud.Metrics)
+	if err != nil {
+		logger.Error("invalid metrics provider configuration", zap.Error(err))
+		os.Exit(1)
+	}
+	if metricsProvider != nil {
+		logger.Info("external metrics provider enabled",
+			zap.String("provider", metricsProvider.Name()),
+			zap.String("mode", cfg.Cloud.Metrics.Mode),
+		)
+	}
+
 	cloudCfg := cloud.CloudConfig{
-		Region: cfg.Cloud.Region,
-		DryRun: cfg.Cloud.DryRun,
+		Region:        cfg.Cloud.Region,
+		DryRun:        cfg.Cloud.DryRun,
+		SavingsRatios: cfg.Cloud.SavingsRatios,
+		Protection: cloud.ProtectionPolicy{
+			Tags:        cfg.Cloud.Protection.Tags,
+			ResourceIDs: cfg.Cloud.Protection.ResourceIDs,
+		},
+		Termination: cloud.TerminationPolicy{
+			MinIdle:          cfg.Cloud.Termination.MinIdle,
+			IdleCPUThreshold: cfg.Cloud.Termination.IdleCPUThreshold,
+			BackupTag:        cfg.Cloud.Termination.BackupTag,
+			MaxBackupAge:     cfg.Cloud.Termination.MaxBackupAge,
+			SoftTerminate:    cfg.Cloud.Termination.SoftTerminate,
+			GracePeriod:      cfg.Cloud.Termination.GracePeriod,
+
+			ProtectedEnvironments: cfg.Cloud.Termination.ProtectedEnvironments,
+			ProtectedResources:    slices.Concat(ai.DefaultProtectedResources, cfg.Cloud.Termination.ProtectedResources),
+		},
+		Idle: cloud.IdlePolicy{MinIdle: cfg.Cloud.Idle.MinIdle},
+		Regions: cloud.RegionPolicy{
+			Allow: cfg.Cloud.Regions.Allow,
+			Deny:  cfg.Cloud.Regions.Deny,
+		},
+		MaxAttempts: cfg.Cloud.RetryAttempts,
+		Metrics:     metricsProvider,
+		MetricsMode: cfg.Cloud.Metrics.Mode,
+		APIMetrics:  cloud.NewAPIMetrics(),
+		Tracer:      otel.Tracer("talos-dashboard"),
+	}
+	// Every region's adapter shares the API call counters served on /metrics
+	if err := cloudCfg.APIMetrics.Register(prometheus.DefaultRegisterer); err != nil {
+		logger.Warn("could not register cloud API metrics", zap.Error(err))
 	}
 
-	awsAdapter, err := aws.New(ctx, cloudCfg)
+	awsAdapter, err := newAWSAdapter(ctx, cloudCfg)
 	if err != nil {
 		logger.Error("could not create AWS adapter", zap.Error(err))
 		os.Exit(1)
 	}
 
-	// Type assert to cloud.CloudAdapter
-	var adapter cloud.CloudAdapter = awsAdapter
+	injector, err := chaos.NewInjector(cfg.Chaos, cfg.Server.Mode, logger)
+	if err != nil {
+		logger.Error("invalid chaos configuration", zap.Error(err))
+		os.Exit(1)
+	}
+
+	// Fault injection is a no-op unless chaos is enabled outside production
+	adapter := chaos.WrapCloudAdapter(awsAdapter, injector)
 
 	rdb := redis.NewClient(&redis.Options{
 		Addr: cfg.Redis.Address,
 	})
 	defer rdb.Close()
 
-	tracker := analytics.NewTokenTracker(cfg.Analytics.PersistPath)
-
 	// In a real enterprise application, the actual AI orchestrator would be initialized here.
 	// For this refactoring, we use a mock that implements the AIOrchestrator interface.
 	// The real `ai.UnifiedOrchestrator` would need to implement this interface.
@@ -116,6 +192,14 @@ func main() {
 	// Initialize the user store. In production, this would be a database-backed store.
 	userStore := NewInMemoryUserStore()
 
+	// The database is optional for the dashboard; endpoints backed by it
+	// return 503 when it is unavailable.
+	repository, closeDB := connectRepository(cfg, logger)
+	defer closeDB()
+
+	tracker := newTokenTracker(cfg.Analytics, repository, logger)
+	defer tracker.Close()
+
 	srv := &server{
 		tracker:      tracker,
 		orchestrator: orchestrator,
@@ -125,6 +209,16 @@ func main() {
 		logger:       logger,
 		config:       cfg,
 		jwtManager:   jwtMgr,
+		oidc:         newOIDCRegistry(cfg.SSO),
+		security:     security.NewSecurityManager(cfg.JWT.SecretKey, cfg.JWT.TokenDuration, 7*24*time.Hour, logger),
+		autonomy:     features.NewAutonomyGate(features.AutonomyFlags{}, features.NewRedisAutonomyStore(rdb)),
+		hub:          newLiveHub(logger),
+	}
+	// Assigned only when connected: a nil *database.Repository would make a
+	// non-nil Repository
+	if repository != nil {
+		srv.repository = repository
+		srv.reports = report.NewGenerator(repository)
 	}
 
 	if *runLoadTest {
@@ -134,6 +228,58 @@ func main() {
 
 	// Start background tasks
 	go srv.startResourceCacheRefresh(ctx)
+	if repository != nil {
+		go srv.startAccuracyRefresh(ctx)
+	}
+	if repository != nil && cfg.Retention.Enabled {
+		purger := retention.NewPurger(repository, retention.Policy{
+			Periods:   cfg.Retention.Periods(),
+			BatchSize: cfg.Retention.BatchSize,
+			DryRun:    cfg.Retention.DryRun,
+			LegalHold: cfg.Retention.LegalHold,
+		}, logger)
+		go purger.Start(ctx, cfg.Retention.Interval)
+	}
+	if repository != nil && cfg.Events.Enabled {
+		relay := events.NewWebhookRelay(repository, events.RelayConfig{
+			URL:          cfg.Events.URL,
+			Secret:       cfg.Events.Secret,
+			PollInterval: cfg.Events.PollInterval,
+			BatchSize:    cfg.Events.BatchSize,
+			Timeout:      cfg.Events.Timeout,
+			MaxBackoff:   cfg.Events.MaxBackoff,
+		}, logger)
+		go relay.Run(ctx)
+	}
+	if repository != nil && cfg.Reports.Enabled {
+		mailer := monitoring.NewSMTPSender(monitoring.SMTPConfig{
+			Host:     cfg.Email.SMTPHost,
+			Port:     cfg.Email.SMTPPort,
+			Username: cfg.Email.Username,
+			Password: cfg.Email.Password,
+			From:     cfg.Email.From,
+		})
+		schedules := make([]report.Schedule, 0, len(cfg.Reports.Schedules))
+		for _, sc := range cfg.Reports.Schedules {
+			schedules = append(schedules, report.Schedule{
+				Name:       sc.Name,
+				OrgID:      sc.OrgID,
+				Cron:       sc.Cron,
+				Period:     sc.Period,
+				Format:     report.Format(sc.Format),
+				Recipients: sc.Recipients,
+				Subject:    sc.Subject,
+				LinkURL:    sc.LinkURL,
+			})
+		}
+		reportScheduler, err := report.NewScheduler(srv.reports, repository, mailer, schedules, logger)
+		if err != nil {
+			logger.Error("invalid report schedule", zap.Error(err))
+			os.Exit(1)
+		}
+		reportScheduler.SetRetry(cfg.Reports.RetryInterval, cfg.Reports.MaxAttempts)
+		go reportScheduler.Run(ctx)
+	}
 
 	// 5. Router Setup
 	httpServer := &http.Server{
@@ -155,8 +301,10 @@ func main() {
 	signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
 	<-stop
 
-	// Cancel the base context to abort all active handlers immediately
+	// Cancel the base context to abort all active handlers immediately.
+	// WebSocket connections are hijacked, so Shutdown leaves them to the hub.
 	cancel()
+	srv.hub.shutdown()
 
 	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -164,6 +312,63 @@ func main() {
 	logger.Info("server stopped")
 }
 
+// newAWSAdapter creates the AWS adapter for the primary region, and a
+// multi-region adapter when the region allow list names further regions
+func newAWSAdapter(ctx context.Context, cloudCfg cloud.CloudConfig) (cloud.CloudAdapter, error) {
+	regions := []string{cloudCfg.Region}
+	for _, region := range cloudCfg.Regions.Allow {
+		if region != cloudCfg.Region && !strings.ContainsAny(region, "*?[\\") {
+			regions = append(regions, region)
+		}
+	}
+	if len(regions) == 1 {
+		return aws.New(ctx, cloudCfg)
+	}
+
+	members := make([]cloud.AccountAdapter, 0, len(regions))
+	for _, region := range regions {
+		regionCfg := cloudCfg
+		regionCfg.Region = region
+		adapter, err := aws.New(ctx, regionCfg)
+		if err != nil {
+			return nil, fmt.Errorf("region %s: %w", region, err)
+		}
+		members = append(members, cloud.AccountAdapter{Region: region, Adapter: adapter})
+	}
+	return cloud.NewMultiAdapter(members...).WithRegionPolicy(cloudCfg.Regions), nil
+}
+
+// connectRepository opens the PostgreSQL repository from the configured DSN.
+// It returns a nil repository (and a no-op close) if the database is unreachable.
+func connectRepository(cfg *config.Config, logger *zap.Logger) (*database.Repository, func()) {
+	dbCfg, err := database.ConfigFromDSN(cfg.Database.DSN)
+	if err != nil {
+		logger.Warn("invalid database DSN, database-backed endpoints disabled", zap.Error(err))
+		return nil, func() {}
+	}
+
+	tracer := otel.Tracer("talos-dashboard")
+	dm, err := database.NewDatabaseManager(dbCfg, logger, tracer)
+	if err != nil {
+		logger.Warn("database unavailable, database-backed endpoints disabled", zap.Error(err))
+		return nil, func() {}
+	}
+
+	return database.NewRepository(dm, logger, tracer), dm.Close
+}
+
+// newTokenTracker keeps the token totals in the database when configured to
+// and the database is up, falling back to the JSON file
+func newTokenTracker(cfg config.AnalyticsConfig, repository *database.Repository, logger *zap.Logger) *analytics.TokenTracker {
+	if cfg.Store == "postgres" {
+		if repository != nil {
+			return analytics.NewTokenTrackerWithStore(analytics.NewPostgresTokenStore(repository))
+		}
+		logger.Warn("database unavailable, token usage is kept in the local file", zap.String("path", cfg.PersistPath))
+	}
+	return analytics.NewTokenTracker(cfg.PersistPath)
+}
+
 func runSimulation(s *server) {
 	s.logger.Info("simulation mode active")
 }
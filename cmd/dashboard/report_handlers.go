@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/report"
+	"go.uber.org/zap"
+)
+
+// handleReport renders the savings report for a named period.
+// GET /api/report?period=month&format=html|pdf
+func (s *server) handleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		respondWithError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+	if s.reports == nil {
+		respondWithError(w, http.StatusServiceUnavailable, "reporting requires a database connection")
+		return
+	}
+
+	period := r.URL.Query().Get("period")
+	start, end, err := report.PeriodRange(period, time.Now())
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format := report.Format(r.URL.Query().Get("format"))
+	if format == "" {
+		format = report.FormatHTML
+	}
+	if format != report.FormatHTML && format != report.FormatPDF {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("unsupported format: %s", format))
+		return
+	}
+
+	// Render into a buffer so a failure doesn't leave a half-written response
+	var buf bytes.Buffer
+	if err := s.reports.Generate(r.Context(), requestOrgID(r), start, end, format, &buf); err != nil {
+		s.logger.Error("failed to generate report", zap.String("period", period), zap.Error(err))
+		respondWithError(w, http.StatusInternalServerError, "failed to generate report")
+		return
+	}
+
+	filename := fmt.Sprintf("talos-report-%s.%s", start.Format("2006-01-02"), format)
+	if format == report.FormatPDF {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	} else {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filename))
+	}
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		s.logger.Error("failed to write response", zap.Error(err))
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/Xover-Official/Xover/internal/report"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// reportSource serves a fixed savings report, or err
+type reportSource struct{ err error }
+
+func (s reportSource) GetSavingsReport(_ context.Context, _ string, start, end time.Time, _ int) (*database.SavingsReport, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &database.SavingsReport{PeriodStart: start, PeriodEnd: end, TotalRealizedSavings: 1234.5, ActionsTotal: 3}, nil
+}
+
+func TestHandleReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   report.Source // nil: no database
+		query    string
+		want     int
+		wantType string
+		wantBody string
+	}{
+		{"html report", reportSource{}, "?period=month", http.StatusOK, "text/html; charset=utf-8", "$1234.50"},
+		{"default period and format", reportSource{}, "", http.StatusOK, "text/html; charset=utf-8", "Here's what Talos saved you"},
+		{"unknown period", reportSource{}, "?period=fortnight", http.StatusBadRequest, "application/json", "unknown report period"},
+		{"unknown format", reportSource{}, "?format=docx", http.StatusBadRequest, "application/json", "unsupported format"},
+		{"source failure", reportSource{err: errors.New("connection refused")}, "", http.StatusInternalServerError, "application/json", "failed to generate report"},
+		{"no database", nil, "", http.StatusServiceUnavailable, "application/json", "requires a database"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := &server{logger: zap.NewNop()}
+			if tt.source != nil {
+				srv.reports = report.NewGenerator(tt.source)
+			}
+			rec := httptest.NewRecorder()
+			srv.handleReport(rec, httptest.NewRequest(http.MethodGet, "/api/report"+tt.query, nil))
+
+			assert.Equal(t, tt.want, rec.Code)
+			assert.Equal(t, tt.wantType, rec.Header().Get("Content-Type"))
+			assert.Contains(t, rec.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestHandleReport_AttachesFilename(t *testing.T) {
+	srv := &server{logger: zap.NewNop(), reports: report.NewGenerator(reportSource{})}
+	rec := httptest.NewRecorder()
+	srv.handleReport(rec, httptest.NewRequest(http.MethodGet, "/api/report?period=year", nil))
+
+	start, _, _ := report.PeriodRange("year", time.Now())
+	assert.Equal(t, `inline; filename="talos-report-`+start.Format("2006-01-02")+`.html"`, rec.Header().Get("Content-Disposition"))
+}
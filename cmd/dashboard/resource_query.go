@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+// maxResourcePageLimit caps how many resources one page returns.
+const maxResourcePageLimit = 1000
+
+// resourceSorts orders resources for ?sort=; ties keep ID order so pages
+// are stable.
+var resourceSorts = map[string]func(a, b *cloud.ResourceV2) bool{
+	"cost_desc": func(a, b *cloud.ResourceV2) bool { return a.CostPerMonth > b.CostPerMonth },
+	"cpu_asc":   func(a, b *cloud.ResourceV2) bool { return a.CPUUsage < b.CPUUsage },
+}
+
+// resourceQuery is the filtering, ordering and paging asked of /api/resources.
+type resourceQuery struct {
+	provider, resourceType, region string
+	sort                           string
+	limit                          int // 0 returns every match
+	offset                         int
+}
+
+// parseResourceQuery reads ?provider=&type=&region=&sort=&limit=&offset=.
+func parseResourceQuery(values url.Values) (resourceQuery, error) {
+	q := resourceQuery{
+		provider:     values.Get("provider"),
+		resourceType: values.Get("type"),
+		region:       values.Get("region"),
+		sort:         values.Get("sort"),
+	}
+
+	if q.sort != "" {
+		if _, ok := resourceSorts[q.sort]; !ok {
+			return q, fmt.Errorf("sort must be cost_desc or cpu_asc")
+		}
+	}
+	if raw := values.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 || n > maxResourcePageLimit {
+			return q, fmt.Errorf("limit must be between 1 and %d", maxResourcePageLimit)
+		}
+		q.limit = n
+	}
+	if raw := values.Get("offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return q, fmt.Errorf("offset must be a non-negative integer")
+		}
+		q.offset = n
+	}
+	return q, nil
+}
+
+// matches reports whether a resource passes the filters, which ignore case.
+func (q resourceQuery) matches(r *cloud.ResourceV2) bool {
+	return (q.provider == "" || strings.EqualFold(r.Provider, q.provider)) &&
+		(q.resourceType == "" || strings.EqualFold(r.Type, q.resourceType)) &&
+		(q.region == "" || strings.EqualFold(r.Region, q.region))
+}
+
+// apply returns the requested page and how many resources matched. The
+// input slice is not reordered.
+func (q resourceQuery) apply(resources []*cloud.ResourceV2) ([]*cloud.ResourceV2, int) {
+	matched := make([]*cloud.ResourceV2, 0, len(resources))
+	for _, r := range resources {
+		if q.matches(r) {
+			matched = append(matched, r)
+		}
+	}
+
+	if less, ok := resourceSorts[q.sort]; ok {
+		sort.SliceStable(matched, func(i, j int) bool {
+			a, b := matched[i], matched[j]
+			if less(a, b) {
+				return true
+			}
+			if less(b, a) {
+				return false
+			}
+			return a.ID < b.ID
+		})
+	}
+
+	total := len(matched)
+	if q.offset >= total {
+		return []*cloud.ResourceV2{}, total
+	}
+	end := total
+	if q.limit > 0 && q.offset+q.limit < total {
+		end = q.offset + q.limit
+	}
+	return matched[q.offset:end], total
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newResourceServer() *server {
+	srv := &server{}
+	srv.resourceCache.resources = []*cloud.ResourceV2{
+		{ID: "i-1", Provider: "aws", Type: "ec2", Region: "us-east-1", CostPerMonth: 120, CPUUsage: 45},
+		{ID: "i-2", Provider: "aws", Type: "ec2", Region: "eu-west-1", CostPerMonth: 80, CPUUsage: 5},
+		{ID: "db-1", Provider: "aws", Type: "rds", Region: "us-east-1", CostPerMonth: 300, CPUUsage: 20},
+		{ID: "vm-1", Provider: "gcp", Type: "compute", Region: "us-central1", CostPerMonth: 60, CPUUsage: 70},
+		{ID: "vm-2", Provider: "azure", Type: "vm", Region: "eastus", CostPerMonth: 80, CPUUsage: 10},
+	}
+	return srv
+}
+
+func getResources(t *testing.T, srv *server, query string) (*httptest.ResponseRecorder, ResourcesResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	srv.handleResources(rec, httptest.NewRequest(http.MethodGet, "/api/resources"+query, nil))
+	var resp ResourcesResponse
+	if rec.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	}
+	return rec, resp
+}
+
+func resourceIDs(resp ResourcesResponse) []string {
+	ids := make([]string, len(resp.Resources))
+	for i, r := range resp.Resources {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestHandleResources_Unpaged(t *testing.T) {
+	rec, resp := getResources(t, newResourceServer(), "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 5, resp.TotalCount)
+	assert.Len(t, resp.Resources, 5)
+	assert.Zero(t, resp.Limit)
+	assert.NotContains(t, rec.Body.String(), `"limit"`)
+}
+
+func TestHandleResources_Filters(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"?provider=aws", []string{"i-1", "i-2", "db-1"}},
+		{"?provider=GCP", []string{"vm-1"}},
+		{"?type=ec2", []string{"i-1", "i-2"}},
+		{"?region=us-east-1", []string{"i-1", "db-1"}},
+		{"?provider=aws&type=ec2&region=eu-west-1", []string{"i-2"}},
+		{"?provider=oracle", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec, resp := getResources(t, newResourceServer(), tt.query)
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, tt.want, resourceIDs(resp))
+			assert.Equal(t, len(tt.want), resp.TotalCount)
+		})
+	}
+}
+
+func TestHandleResources_Sorts(t *testing.T) {
+	_, resp := getResources(t, newResourceServer(), "?sort=cost_desc")
+	// i-2 and vm-2 tie on cost and fall back to ID order
+	assert.Equal(t, []string{"db-1", "i-1", "i-2", "vm-2", "vm-1"}, resourceIDs(resp))
+
+	_, resp = getResources(t, newResourceServer(), "?sort=cpu_asc&provider=aws")
+	assert.Equal(t, []string{"i-2", "db-1", "i-1"}, resourceIDs(resp))
+}
+
+func TestHandleResources_Pages(t *testing.T) {
+	srv := newResourceServer()
+
+	var seen []string
+	for offset := 0; offset < 6; offset += 2 {
+		rec, resp := getResources(t, srv, "?sort=cost_desc&limit=2&offset="+strconv.Itoa(offset))
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, 5, resp.TotalCount, "total_count counts every match")
+		assert.Equal(t, 2, resp.Limit)
+		assert.Equal(t, offset, resp.Offset)
+		assert.LessOrEqual(t, len(resp.Resources), 2)
+		seen = append(seen, resourceIDs(resp)...)
+	}
+	assert.Equal(t, []string{"db-1", "i-1", "i-2", "vm-2", "vm-1"}, seen)
+
+	_, resp := getResources(t, srv, "?provider=aws&limit=2&offset=2")
+	assert.Equal(t, 3, resp.TotalCount)
+	assert.Equal(t, []string{"db-1"}, resourceIDs(resp))
+
+	_, resp = getResources(t, srv, "?offset=10")
+	assert.Equal(t, 5, resp.TotalCount)
+	assert.Empty(t, resp.Resources)
+
+	// Paging and sorting leave the cache as it was
+	assert.Equal(t, "i-1", srv.resourceCache.resources[0].ID)
+}
+
+func TestHandleResources_RejectsBadQuery(t *testing.T) {
+	for _, query := range []string{"?limit=0", "?limit=1001", "?limit=ten", "?offset=-1", "?sort=name"} {
+		rec, _ := getResources(t, newResourceServer(), query)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+	}
+}
@@ -3,7 +3,9 @@ package main
 import (
 	"time"
 
+	"github.com/Xover-Official/Xover/internal/analytics"
 	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/database"
 )
 
 // Standardized API response structs
@@ -16,13 +18,15 @@ type ROIResponse struct {
 		NetROI        float64 `json:"net_roi"`
 		ROIPercentage float64 `json:"roi_percentage"`
 	} `json:"roi"`
-	Period string `json:"period"`
+	Period string                    `json:"period"`
+	ByType []*database.SavingsByType `json:"by_type"`
 }
 
-// ModelTokenInfo defines the token and cost for a specific AI model.
+// ModelTokenInfo defines the tokens, cost and requests for a specific AI tier.
 type ModelTokenInfo struct {
-	Tokens int     `json:"tokens"`
-	Cost   float64 `json:"cost"`
+	Tokens   int     `json:"tokens"`
+	Cost     float64 `json:"cost"`
+	Requests int     `json:"requests"`
 }
 
 // TokenBreakdownResponse defines the structure for the token breakdown endpoint.
@@ -46,10 +50,17 @@ type SystemStatusResponse struct {
 // ResourcesResponse defines the structure for the resources endpoint.
 type ResourcesResponse struct {
 	Resources   []*cloud.ResourceV2 `json:"resources"`
-	TotalCount  int                 `json:"total_count"`
+	TotalCount  int                 `json:"total_count"` // matches across all pages
+	Offset      int                 `json:"offset"`
+	Limit       int                 `json:"limit,omitempty"` // omitted when unpaged
 	LastUpdated time.Time           `json:"last_updated"`
 }
 
+// LoopStatusResponse defines the structure for the OODA loop endpoints.
+type LoopStatusResponse struct {
+	Paused bool `json:"paused"`
+}
+
 // HealthzResponse defines the structure for the healthz endpoint.
 type HealthzResponse struct {
 	Status    string    `json:"status"`
@@ -97,9 +108,9 @@ type FeedbackResponse struct {
 
 // TokenStatsResponse defines the structure for the token stats endpoint.
 type TokenStatsResponse struct {
-	Status          string         `json:"status"`
-	TokenStatistics map[string]any `json:"token_statistics"`
-	Timestamp       time.Time      `json:"timestamp"`
+	Status          string                  `json:"status"`
+	TokenStatistics analytics.TokenSnapshot `json:"token_statistics"`
+	Timestamp       time.Time               `json:"timestamp"`
 }
 
 // ResourceMetricsResponse defines the structure for the resource metrics endpoint.
@@ -128,6 +139,7 @@ type OptimizationSuggestion struct {
 	EstimatedSavings float64 `json:"estimated_savings"`
 	Priority         string  `json:"priority"`
 	Reason           string  `json:"reason"`
+	QuickWin         bool    `json:"quick_win"` // idle cleanup that needs no approval
 }
 
 // OptimizationSuggestionsResponse defines the structure for the optimization suggestions endpoint.
@@ -136,5 +148,14 @@ type OptimizationSuggestionsResponse struct {
 	Suggestions           []OptimizationSuggestion `json:"suggestions"`
 	TotalSuggestions      int                      `json:"total_suggestions"`
 	TotalPotentialSavings float64                  `json:"total_potential_savings"`
+	QuickWinSavings       float64                  `json:"quick_win_savings"`
 	Timestamp             time.Time                `json:"timestamp"`
 }
+
+// AccuracyResponse defines the structure for the savings accuracy endpoint.
+type AccuracyResponse struct {
+	WindowStart time.Time                            `json:"window_start"`
+	WindowEnd   time.Time                            `json:"window_end"`
+	Overall     analytics.SavingsAccuracy            `json:"overall"`
+	ByType      map[string]analytics.SavingsAccuracy `json:"by_type"`
+}
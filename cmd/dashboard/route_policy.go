@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+
+	"github.com/Xover-Official/Xover/internal/auth"
+)
+
+var (
+	adminOnly      = []auth.Role{auth.RoleAdmin}
+	operatorsAndUp = []auth.Role{auth.RoleAdmin, auth.RoleOperator}
+)
+
+// routeRoles declares the roles allowed on each API route that changes
+// state, keyed by the pattern it is registered with. Permission checks on
+// the handlers still apply; this is the coarse gate in front of them.
+var routeRoles = map[string][]auth.Role{
+	"POST /actions/bulk":         operatorsAndUp,
+	"POST /actions/{id}/approve": operatorsAndUp,
+	"POST /actions/{id}/reject":  operatorsAndUp,
+	"POST /feedback":             operatorsAndUp,
+	"PUT /autonomy":              operatorsAndUp,
+	"POST /loop/pause":           operatorsAndUp,
+	"POST /loop/resume":          operatorsAndUp,
+
+	"POST /admin/organizations":     adminOnly,
+	"PUT /admin/organizations/{id}": adminOnly,
+	"POST /admin/users":             adminOnly,
+	"POST /admin/users/invite":      adminOnly,
+	"PUT /admin/users/{id}":         adminOnly,
+	"PUT /admin/users/{id}/role":    adminOnly,
+}
+
+// handleMutation registers a state-changing API route behind the roles
+// routeRoles declares for its pattern. A pattern missing from routeRoles is
+// admin-only, so a new route is never open to every signed-in user.
+func (s *server) handleMutation(api *http.ServeMux, pattern string, handler http.HandlerFunc) {
+	roles, ok := routeRoles[pattern]
+	if !ok {
+		roles = adminOnly
+	}
+	api.HandleFunc(pattern, s.requireRole(handler, roles...))
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// apiRequest sends method path through the full router as a user with role
+func apiRequest(t *testing.T, srv *server, role auth.Role, method, path string) int {
+	t.Helper()
+	token, err := srv.jwtManager.Generate(auth.User{ID: "user-1", Email: "user@example.com", Role: role})
+	require.NoError(t, err)
+	req := httptest.NewRequest(method, path, nil)
+	req.AddCookie(&http.Cookie{Name: "atlas_token", Value: token})
+	rec := httptest.NewRecorder()
+	srv.routes().ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestRouteRoles_MutatingRoutes(t *testing.T) {
+	srv := &server{
+		logger:     zap.NewNop(),
+		jwtManager: auth.NewJWTManager("policy-test-secret", time.Hour),
+		loop:       &fakeLoop{},
+	}
+
+	tests := []struct {
+		name   string
+		role   auth.Role
+		method string
+		path   string
+		want   int
+	}{
+		{"viewer cannot submit feedback", auth.RoleViewer, http.MethodPost, "/api/feedback", http.StatusForbidden},
+		{"operator submits feedback", auth.RoleOperator, http.MethodPost, "/api/feedback", http.StatusOK},
+		{"viewer cannot pause the loop", auth.RoleViewer, http.MethodPost, "/api/loop/pause", http.StatusForbidden},
+		{"operator pauses the loop", auth.RoleOperator, http.MethodPost, "/api/loop/pause", http.StatusOK},
+		{"viewer cannot approve actions", auth.RoleViewer, http.MethodPost, "/api/actions/a-1/approve", http.StatusForbidden},
+		{"operator cannot create users", auth.RoleOperator, http.MethodPost, "/api/admin/users", http.StatusForbidden},
+		{"admin passes the role gate", auth.RoleAdmin, http.MethodPost, "/api/admin/users", http.StatusServiceUnavailable},
+		{"viewer still reads", auth.RoleViewer, http.MethodGet, "/api/loop", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, apiRequest(t, srv, tt.role, tt.method, tt.path))
+		})
+	}
+}
+
+func TestHandleMutation_UndeclaredRouteIsAdminOnly(t *testing.T) {
+	srv := &server{logger: zap.NewNop()}
+	api := http.NewServeMux()
+	srv.handleMutation(api, "POST /undeclared", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	for role, want := range map[auth.Role]int{
+		auth.RoleAdmin:    http.StatusNoContent,
+		auth.RoleOperator: http.StatusForbidden,
+		auth.RoleViewer:   http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/undeclared", nil)
+		req = req.WithContext(context.WithValue(req.Context(), userContextKey, &auth.Claims{UserID: "user-1", Role: role}))
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, req)
+		assert.Equal(t, want, rec.Code, role)
+	}
+}
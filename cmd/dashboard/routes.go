@@ -1,6 +1,10 @@
 package main
 
-import "net/http"
+import (
+	"net/http"
+
+	"github.com/Xover-Official/Xover/internal/metrics"
+)
 
 // routes sets up all the HTTP handlers for the dashboard application.
 func (s *server) routes() http.Handler {
@@ -13,24 +17,46 @@ func (s *server) routes() http.Handler {
 	// Publicly accessible health check.
 	router.HandleFunc("/healthz", s.handleHealthz)
 
+	// Prometheus scrape endpoint; OpenMetrics responses include trace exemplars.
+	router.Handle("/metrics", metrics.Handler())
+
 	// Public auth endpoints for the SSO login/logout/callback flow.
 	router.HandleFunc("/auth/login/", s.handleLogin)
 	router.HandleFunc("/auth/callback/", s.handleCallback)
+	router.HandleFunc("POST /auth/refresh", s.handleRefresh)
 	router.HandleFunc("/auth/logout", s.handleLogout)
+	router.HandleFunc("/auth/invite/accept", s.handleAcceptInvite)
 
-	// API endpoints are grouped together and protected by the authentication middleware.
+	// API endpoints are grouped together and protected by the authentication
+	// middleware. Routes that change state are registered with handleMutation
+	// so they are gated by the roles in routeRoles.
 	api := http.NewServeMux()
+	api.HandleFunc("GET /whoami", s.handleWhoAmI)
 	api.HandleFunc("/roi", s.handleROI)
 	api.HandleFunc("/token-breakdown", s.handleTokenBreakdown)
 	api.HandleFunc("/system/status", s.handleSystemStatus)
 	api.HandleFunc("/resources", s.handleResources)
+	api.HandleFunc("GET /resources/{id}/history", s.handleResourceHistory)
+	api.HandleFunc("GET /ws", s.handleLiveUpdates)
+	api.HandleFunc("GET /actions", s.requirePermission(actionsReadPermission, s.handleListActions))
+	s.handleMutation(api, "POST /actions/bulk", s.requirePermission(approvePermission, s.handleBulkApproval))
+	s.handleMutation(api, "POST /actions/{id}/approve", s.requirePermission(approvePermission, s.handleApproveAction))
+	s.handleMutation(api, "POST /actions/{id}/reject", s.requirePermission(approvePermission, s.handleRejectAction))
 	api.HandleFunc("/token-stats", s.handleTokenStats)
 	api.HandleFunc("/resource-metrics", s.handleResourceMetrics)
 	api.HandleFunc("/optimization-suggestions", s.handleOptimizationSuggestions)
 	api.HandleFunc("/dashboard/stats", s.handleDashboardStats)
 	api.HandleFunc("/dashboard/opportunities", s.handleOpportunities)
 	api.HandleFunc("/dashboard/anomalies", s.handleAnomalies)
-	api.HandleFunc("/feedback", s.handleSubmitFeedback)
+	s.handleMutation(api, "POST /feedback", s.handleSubmitFeedback)
+	api.HandleFunc("/report", s.handleReport)
+	api.HandleFunc("/accuracy", s.handleAccuracy)
+	api.HandleFunc("GET /autonomy", s.requirePermission(autonomyReadPermission, s.handleGetAutonomy))
+	s.handleMutation(api, "PUT /autonomy", s.requirePermission(autonomyWritePermission, s.handleUpdateAutonomy))
+	api.HandleFunc("GET /loop", s.requirePermission(loopReadPermission, s.handleGetLoop))
+	s.handleMutation(api, "POST /loop/pause", s.requirePermission(loopWritePermission, s.handleSetLoopPaused(true)))
+	s.handleMutation(api, "POST /loop/resume", s.requirePermission(loopWritePermission, s.handleSetLoopPaused(false)))
+	s.registerAdminRoutes(api)
 
 	// Mount the protected API endpoints under the /api/ path.
 	// http.StripPrefix is used to remove the "/api" prefix before the request reaches the 'api' mux,
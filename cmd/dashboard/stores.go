@@ -1,6 +1,12 @@
 package main
 
-import "github.com/Xover-Official/Xover/internal/auth"
+import (
+	"context"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/auth"
+	"github.com/Xover-Official/Xover/internal/database"
+)
 
 // UserStore defines the interface for user persistence.
 // This allows for swapping the backend (e.g., Postgres, Mongo) without changing business logic.
@@ -9,6 +15,35 @@ type UserStore interface {
 	Upsert(ssoUser *auth.SSOUser) (*auth.User, error)
 }
 
+// Repository is the database access the handlers need. *database.Repository
+// implements it; tests substitute a fake.
+type Repository interface {
+	CreateAuditLog(ctx context.Context, log *database.AuditLog) error
+
+	CreateOrganization(ctx context.Context, org *database.Organization) error
+	GetOrganization(ctx context.Context, id string) (*database.Organization, error)
+	ListOrganizations(ctx context.Context) ([]*database.Organization, error)
+	UpdateOrganization(ctx context.Context, org *database.Organization) error
+
+	CreateUser(ctx context.Context, user *database.User) error
+	InviteUser(ctx context.Context, user *database.User, inviteTokenHash string, expiresAt time.Time) error
+	AcceptInvite(ctx context.Context, inviteTokenHash, passwordHash string) (*database.User, error)
+	GetUserByID(ctx context.Context, id string) (*database.User, error)
+	ListUsers(ctx context.Context, orgID string) ([]*database.User, error)
+	UpdateUser(ctx context.Context, user *database.User) error
+
+	ListRecentActions(ctx context.Context, orgID, status string, limit int) ([]*database.Action, error)
+	CountAwaitingActions(ctx context.Context, orgID string, filter database.ActionFilter) (int, error)
+	ResolveAwaitingActions(ctx context.Context, orgID string, filter database.ActionFilter, approve bool, actor *database.AuditLog) (int, error)
+	ResolveAction(ctx context.Context, orgID, id string, approve bool, dryRunOverride *bool, actor *database.AuditLog) error
+
+	GetROISummary(ctx context.Context, orgID string, window time.Duration) (*database.ROISummary, error)
+	GetResourceHistory(ctx context.Context, resourceID string, cooldown time.Duration) (*database.ResourceHistory, error)
+	ListSavingsSamples(ctx context.Context, start, end time.Time) ([]*database.SavingsSample, error)
+}
+
+var _ Repository = (*database.Repository)(nil)
+
 // InMemoryUserStore is a temporary, non-production-ready implementation of UserStore.
 // TODO: Replace with a real database-backed implementation of UserStore.
 type InMemoryUserStore struct {
@@ -2,13 +2,18 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/risk"
 )
 
+// rightsizing decides when a suggestion recommends downsizing
+var rightsizing = risk.DefaultSizingPolicy()
+
 // Additional handlers that aren't in main.go
 
 func (s *server) handleTokenStats(w http.ResponseWriter, _ *http.Request) {
@@ -20,10 +25,9 @@ func (s *server) handleTokenStats(w http.ResponseWriter, _ *http.Request) {
 		return
 	}
 
-	stats := s.tracker.GetStats()
 	resp := TokenStatsResponse{
 		Status:          "success",
-		TokenStatistics: stats,
+		TokenStatistics: s.tracker.GetSnapshot(),
 		Timestamp:       time.Now(),
 	}
 	json.NewEncoder(w).Encode(resp)
@@ -87,6 +91,7 @@ func (s *server) handleOptimizationSuggestions(w http.ResponseWriter, r *http.Re
 		Suggestions:           filteredSuggestions,
 		TotalSuggestions:      len(filteredSuggestions),
 		TotalPotentialSavings: calculateTotalSavings(filteredSuggestions),
+		QuickWinSavings:       calculateQuickWinSavings(filteredSuggestions),
 		Timestamp:             time.Now(),
 	}
 
@@ -102,6 +107,20 @@ func respondWithError(w http.ResponseWriter, code int, message string) {
 
 // generateSuggestionForResource is a helper for the caching worker.
 func generateSuggestionForResource(res *cloud.ResourceV2) *OptimizationSuggestion {
+	// Idle load balancers and addresses are removed outright
+	if action, ok := cloud.QuickWinAction(res); ok {
+		return &OptimizationSuggestion{
+			ResourceID: res.ID, ResourceType: res.Type, Provider: res.Provider,
+			Region: res.Region, CurrentCost: res.CostPerMonth, Suggestion: string(action),
+			EstimatedSavings: res.CostPerMonth, Priority: "high", QuickWin: true,
+			Reason: quickWinReason(res),
+		}
+	}
+	// Load balancers and addresses in use have nothing else to optimize
+	if len(cloud.SupportedActions(res.Type)) > 0 && !cloud.ActionOptimize.ValidFor(res.Type) {
+		return nil
+	}
+
 	var suggestion string
 	var estimatedSavings float64
 	var priority string
@@ -110,7 +129,7 @@ func generateSuggestionForResource(res *cloud.ResourceV2) *OptimizationSuggestio
 		suggestion = "resize_down"
 		estimatedSavings = res.CostPerMonth * 0.5 // 50% savings
 		priority = "high"
-	} else if res.CPUUsage < 40 && res.MemoryUsage < 50 {
+	} else if rightsizing.Evaluate(res.CPUUsage, res.MemoryUsage).Safe {
 		suggestion = "rightsize"
 		estimatedSavings = res.CostPerMonth * 0.25 // 25% savings
 		priority = "medium"
@@ -138,12 +157,32 @@ func generateOptimizationReason(cpuUsage, memoryUsage float64, state string) str
 	if cpuUsage < 20 && memoryUsage < 30 {
 		return "Very low utilization - consider significant downsizing"
 	}
-	if cpuUsage < 40 && memoryUsage < 50 {
-		return "Low utilization - consider rightsizing"
+	if sizing := rightsizing.Evaluate(cpuUsage, memoryUsage); sizing.Safe {
+		return fmt.Sprintf("Low utilization (%s) - consider rightsizing", sizing)
 	}
 	return "Resource appears to be appropriately sized"
 }
 
+func quickWinReason(res *cloud.ResourceV2) string {
+	switch res.Type {
+	case cloud.ResourceTypeElasticIP:
+		return "Elastic IP is not attached to anything and is billed while unused"
+	case cloud.ResourceTypeEBS:
+		return "EBS volume is not attached to any instance and is billed while unused"
+	}
+	return "Load balancer has served no traffic and is billed while idle"
+}
+
+func calculateQuickWinSavings(suggestions []OptimizationSuggestion) float64 {
+	total := 0.0
+	for _, suggestion := range suggestions {
+		if suggestion.QuickWin {
+			total += suggestion.EstimatedSavings
+		}
+	}
+	return total
+}
+
 func calculateTotalSavings(suggestions []OptimizationSuggestion) float64 {
 	total := 0.0
 	for _, suggestion := range suggestions {
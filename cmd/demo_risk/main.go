@@ -47,10 +47,10 @@ func main() {
 
 		fmt.Printf("--- Recommendation ---\n")
 		fmt.Printf("Status: ")
-		if res.CPUUsage < 40 && res.MemoryUsage < 50 {
-			fmt.Println("✅ RECOMMENDED")
+		if result.Sizing.Safe {
+			fmt.Printf("✅ RECOMMENDED (%s)\n", result.Sizing)
 		} else {
-			fmt.Println("❌ SKIP (Too High Risk)")
+			fmt.Printf("❌ SKIP (%s)\n", result.Sizing)
 		}
 
 		fmt.Printf("Projected Impact: $%.2f/mo\n", result.Impact)
@@ -8,15 +8,17 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/Xover-Official/Xover/internal/config"
 	"github.com/Xover-Official/Xover/internal/deployment"
+	"github.com/Xover-Official/Xover/internal/metrics"
 	"github.com/Xover-Official/Xover/internal/monitoring"
 	"github.com/Xover-Official/Xover/internal/performance"
+	"github.com/Xover-Official/Xover/internal/persistence"
 	"github.com/Xover-Official/Xover/internal/security"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
@@ -31,17 +33,12 @@ func main() {
 	}
 	defer logger.Sync()
 
-	// Load configuration from environment
-	envConfig, err := config.NewEnvironmentConfig()
+	// Load configuration from defaults and the environment
+	envConfig, err := config.Load("")
 	if err != nil {
 		logger.Fatal("Failed to load configuration", zap.Error(err))
 	}
 
-	// Validate configuration
-	if err := envConfig.Validate(); err != nil {
-		logger.Fatal("Invalid configuration", zap.Error(err))
-	}
-
 	logger.Info("Configuration loaded successfully",
 		zap.String("mode", envConfig.Server.Mode),
 		zap.String("cloud_provider", envConfig.Cloud.Provider),
@@ -61,6 +58,12 @@ func main() {
 		30*time.Minute, // Default refresh time
 		logger,
 	)
+	securityManager.SetRateLimit(envConfig.Server.RateLimit.Requests, envConfig.Server.RateLimit.Window)
+	trustedProxies, err := security.ParseTrustedProxies(envConfig.Server.TrustedProxies)
+	if err != nil {
+		logger.Fatal("Invalid trusted proxies", zap.Error(err))
+	}
+	securityManager.SetTrustedProxies(trustedProxies)
 
 	// Initialize deployment manager
 	deploymentManager, err := deployment.NewDeploymentManager(
@@ -107,6 +110,21 @@ func main() {
 			zap.String("output_dir", outputDir),
 		)
 
+		// deploy --apply applies the manifests to the cluster; --dry-run
+		// has the API server check them without persisting anything
+		apply, dryRun := false, false
+		for _, arg := range os.Args[2:] {
+			switch arg {
+			case "--apply":
+				apply = true
+			case "--dry-run":
+				apply, dryRun = true, true
+			}
+		}
+		if apply {
+			applyManifests(ctx, deploymentManager, dryRun, logger)
+		}
+
 		fmt.Printf("\n📦 Deployment package created in: %s\n", outputDir)
 		fmt.Printf("🚀 To deploy: cd %s && ./deploy.sh\n", outputDir)
 		return
@@ -135,7 +153,33 @@ func main() {
 	logger.Info("Shutdown complete")
 }
 
-func startMonitoringServer(monitoringService *monitoring.MonitoringService, config *config.EnvironmentConfig, logger *zap.Logger) {
+// applyManifests applies the generated Kubernetes manifests, logging the
+// outcome for each object
+func applyManifests(ctx context.Context, deploymentManager *deployment.DeploymentManager, dryRun bool, logger *zap.Logger) {
+	manifests, err := deploymentManager.GenerateKubernetesManifests()
+	if err != nil {
+		logger.Fatal("Failed to generate kubernetes manifests", zap.Error(err))
+	}
+
+	results, err := deploymentManager.DeployToKubernetes(ctx, manifests, deployment.DeployOptions{DryRun: dryRun})
+	for _, result := range results {
+		if result.Err != nil {
+			logger.Error("Failed to apply object", zap.String("file", result.File), zap.String("object", result.String()))
+			continue
+		}
+		logger.Info("Applied object", zap.String("file", result.File), zap.String("object", result.String()), zap.Bool("dry_run", dryRun))
+	}
+	if err != nil {
+		logger.Fatal("Kubernetes deployment failed", zap.Error(err))
+	}
+	if dryRun {
+		fmt.Printf("☸️  Dry run: %d objects would be applied\n", len(results))
+		return
+	}
+	fmt.Printf("☸️  Applied %d objects to Kubernetes\n", len(results))
+}
+
+func startMonitoringServer(monitoringService *monitoring.MonitoringService, config *config.Config, logger *zap.Logger) {
 	monitoringConfig := config.GetMonitoringConfig()
 
 	if prometheusConfig, ok := monitoringConfig["prometheus"].(map[string]interface{}); ok {
@@ -149,7 +193,7 @@ func startMonitoringServer(monitoringService *monitoring.MonitoringService, conf
 			logger.Info("Starting Prometheus metrics server", zap.String("port", port))
 
 			mux := http.NewServeMux()
-			mux.Handle("/metrics", promhttp.Handler())
+			mux.Handle("/metrics", metrics.Handler())
 
 			go func() {
 				if err := http.ListenAndServe(":"+port, mux); err != nil {
@@ -160,17 +204,14 @@ func startMonitoringServer(monitoringService *monitoring.MonitoringService, conf
 	}
 }
 
-func startHealthServer(monitoringService *monitoring.MonitoringService, securityManager *security.EnhancedSecurityManager, config *config.EnvironmentConfig, logger *zap.Logger) {
+func startHealthServer(monitoringService *monitoring.MonitoringService, securityManager *security.EnhancedSecurityManager, config *config.Config, logger *zap.Logger) {
 	port := config.Server.Port
 	logger.Info("Starting health check server", zap.String("port", port))
 
 	healthCheck := monitoring.NewHealthCheck(logger)
 
 	// Add health checks
-	healthCheck.AddCheck("database", func(ctx context.Context) error {
-		// Check database connectivity
-		return nil
-	})
+	healthCheck.AddCheck("database", databaseHealthCheck(config.Database))
 
 	healthCheck.AddCheck("redis", func(ctx context.Context) error {
 		// Check Redis connectivity
@@ -193,8 +234,8 @@ func startHealthServer(monitoringService *monitoring.MonitoringService, security
 		status := "UP"
 		statusCode := http.StatusOK
 
-		for _, err := range results {
-			if err != "" {
+		for _, result := range results {
+			if result != "healthy" {
 				status = "DOWN"
 				statusCode = http.StatusServiceUnavailable
 				break
@@ -209,11 +250,36 @@ func startHealthServer(monitoringService *monitoring.MonitoringService, security
 		})
 	})
 
+	// Logout revokes the session's tokens
+	mux.Handle("/auth/logout", securityManager.GetLogoutHandler())
+
 	if err := http.ListenAndServe(":"+port, mux); err != nil {
 		logger.Error("Failed to start health server", zap.Error(err))
 	}
 }
 
+// databaseHealthCheck probes the ledger database. The ledger is opened on the
+// first probe and reopened after a failed open, so a database that is down at
+// startup is reported until it comes up rather than for good.
+func databaseHealthCheck(db config.DatabaseConfig) func(ctx context.Context) error {
+	var (
+		mu     sync.Mutex
+		ledger *persistence.PostgresLedger
+	)
+	return func(ctx context.Context) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if ledger == nil {
+			opened, err := persistence.NewPostgresLedgerWithConfig(db.DSN, persistence.PostgresConfigFrom(db))
+			if err != nil {
+				return err
+			}
+			ledger = opened
+		}
+		return ledger.HealthCheck(ctx)
+	}
+}
+
 func startPerformanceOptimization(monitoringService *monitoring.MonitoringService, logger *zap.Logger) {
 	logger.Info("Starting performance optimization")
 
@@ -241,7 +307,7 @@ func startPerformanceOptimization(monitoringService *monitoring.MonitoringServic
 	}
 }
 
-func runMainApplication(ctx context.Context, config *config.EnvironmentConfig, monitoringService *monitoring.MonitoringService, securityManager *security.EnhancedSecurityManager, logger *zap.Logger) {
+func runMainApplication(ctx context.Context, config *config.Config, monitoringService *monitoring.MonitoringService, securityManager *security.EnhancedSecurityManager, logger *zap.Logger) {
 	logger.Info("Main application started")
 
 	ticker := time.NewTicker(1 * time.Minute)
@@ -258,7 +324,7 @@ func runMainApplication(ctx context.Context, config *config.EnvironmentConfig, m
 	}
 }
 
-func doApplicationWork(config *config.EnvironmentConfig, monitoringService *monitoring.MonitoringService, securityManager *security.EnhancedSecurityManager, logger *zap.Logger) {
+func doApplicationWork(config *config.Config, monitoringService *monitoring.MonitoringService, securityManager *security.EnhancedSecurityManager, logger *zap.Logger) {
 	// Simulate resource fetching
 	start := time.Now()
 
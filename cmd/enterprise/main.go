@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"os"
 
@@ -11,6 +10,7 @@ import (
 	"github.com/Xover-Official/Xover/internal/config"
 	"github.com/Xover-Official/Xover/internal/manager"
 	"github.com/Xover-Official/Xover/internal/persistence"
+	"github.com/Xover-Official/Xover/internal/secrets"
 	"github.com/Xover-Official/Xover/internal/worker"
 	"go.uber.org/zap"
 )
@@ -32,18 +32,26 @@ func main() {
 	}
 }
 
+// loadConfig loads the enterprise configuration, reading any credentials it
+// leaves unset from the secret store it names
+func loadConfig() (*config.Config, error) {
+	return config.LoadWithSecrets("config.enterprise.yaml", func(s config.SecretsConfig) (config.SecretLookup, error) {
+		return secrets.NewLookup(context.Background(), s.Source, s.RefreshInterval)
+	})
+}
+
 func runManager() {
 	log.Println("🚀 Starting Talos Enterprise Manager")
 
 	// Load configuration
-	cfg, err := config.Load("config.enterprise.yaml")
+	cfg, err := loadConfig()
 	if err != nil {
 		log.Fatalf("❌ Failed to load config: %v", err)
 	}
 
 	// Initialize PostgreSQL ledger
 	log.Println("📊 Connecting to PostgreSQL...")
-	ledger, err := persistence.NewPostgresLedger(cfg.Database.DSN)
+	ledger, err := persistence.NewPostgresLedgerWithConfig(cfg.Database.DSN, persistence.PostgresConfigFrom(cfg.Database))
 	if err != nil {
 		log.Fatalf("❌ PostgreSQL connection failed: %v", err)
 	}
@@ -55,12 +63,7 @@ func runManager() {
 
 	// Initialize AI orchestrator
 	log.Println("🤖 Initializing AI orchestrator...")
-	aiCfg := &ai.Config{
-		GeminiAPIKey: cfg.AI.OpenRouterKey,
-		DevinAPIKey:  cfg.AI.DevinKey,
-		CacheEnabled: true,
-		CacheAddr:    "redis:6379",
-	}
+	aiCfg := ai.NewConfig(cfg)
 
 	orchestrator, err := ai.NewUnifiedOrchestrator(aiCfg, tokenTracker, zap.NewExample())
 	if err != nil {
@@ -88,21 +91,19 @@ func runManager() {
 func runWorker() {
 	log.Println("🚀 Starting Talos Enterprise Worker")
 
-	// Get worker ID from environment or generate one
-	workerID := os.Getenv("WORKER_ID")
-	if workerID == "" {
-		workerID = fmt.Sprintf("worker-%d", os.Getpid())
-	}
+	// WORKER_ID is only a prefix: replicas share their environment, so each
+	// process adds its hostname and a random suffix
+	workerID := worker.UniqueID(os.Getenv("WORKER_ID"))
 
 	// Load configuration
-	cfg, err := config.Load("config.enterprise.yaml")
+	cfg, err := loadConfig()
 	if err != nil {
 		log.Fatalf("❌ Failed to load config: %v", err)
 	}
 
 	// Initialize PostgreSQL ledger
 	log.Println("📊 Connecting to PostgreSQL...")
-	ledger, err := persistence.NewPostgresLedger(cfg.Database.DSN)
+	ledger, err := persistence.NewPostgresLedgerWithConfig(cfg.Database.DSN, persistence.PostgresConfigFrom(cfg.Database))
 	if err != nil {
 		log.Fatalf("❌ PostgreSQL connection failed: %v", err)
 	}
@@ -114,12 +115,7 @@ func runWorker() {
 
 	// Initialize AI orchestrator
 	log.Println("🤖 Initializing AI orchestrator...")
-	aiCfg := &ai.Config{
-		GeminiAPIKey: cfg.AI.OpenRouterKey,
-		DevinAPIKey:  cfg.AI.DevinKey,
-		CacheEnabled: true,
-		CacheAddr:    "redis:6379",
-	}
+	aiCfg := ai.NewConfig(cfg)
 
 	orchestrator, err := ai.NewUnifiedOrchestrator(aiCfg, tokenTracker, zap.NewExample())
 	if err != nil {
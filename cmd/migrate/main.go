@@ -2,10 +2,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -43,6 +46,8 @@ func main() {
 	switch command {
 	case "up":
 		runMigrations(pool)
+	case "down":
+		rollbackMigration(pool)
 	case "status":
 		showStatus(pool)
 	default:
@@ -51,33 +56,162 @@ func main() {
 	}
 }
 
+// migrationsDir holds the VERSION.up.sql and VERSION.down.sql scripts
+const migrationsDir = "migrations"
+
 func runMigrations(pool *pgxpool.Pool) {
-	ctx := context.Background()
+	applied, err := migrateUp(context.Background(), pool, migrationsDir)
+	for _, version := range applied {
+		fmt.Printf("🚀 Applied migration: %s\n", version)
+	}
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	if len(applied) == 0 {
+		fmt.Println("✅ Database is up to date")
+		return
+	}
+	fmt.Printf("✅ Applied %d migration(s) successfully!\n", len(applied))
+}
 
-	// Read migration file
-	migrationSQL, err := os.ReadFile("migrations/001_initial_schema.sql")
+func rollbackMigration(pool *pgxpool.Pool) {
+	version, err := migrateDown(context.Background(), pool, migrationsDir)
 	if err != nil {
-		log.Fatalf("Failed to read migration file: %v", err)
+		log.Fatalf("%v", err)
 	}
 
-	fmt.Println("🚀 Running migration: 001_initial_schema.sql")
+	if version == "" {
+		fmt.Println("✅ No migrations to roll back")
+		return
+	}
+	fmt.Printf("⏪ Rolled back migration: %s\n", version)
+}
 
-	// Execute migration
-	_, err = pool.Exec(ctx, string(migrationSQL))
+// prepareTracking creates the schema_migrations table that records applied
+// versions, so that "up" can be re-run safely and "down" knows what to revert
+func prepareTracking(ctx context.Context, pool *pgxpool.Pool) error {
+	_, err := pool.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+		)
+	`)
 	if err != nil {
-		log.Fatalf("Migration failed: %v", err)
-	}
-
-	fmt.Println("✅ Migration completed successfully!")
-	fmt.Println("\n📊 Database schema created:")
-	fmt.Println("  - actions (idempotent ledger)")
-	fmt.Println("  - ai_decisions (AI audit trail)")
-	fmt.Println("  - token_usage (cost tracking)")
-	fmt.Println("  - savings_events (ROI tracking)")
-	fmt.Println("  - organizations (multi-tenancy)")
-	fmt.Println("  - users (RBAC)")
-	fmt.Println("  - resources (cloud inventory)")
-	fmt.Println("  - audit_log (compliance)")
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	// Versions used to be recorded by file name
+	_, err = pool.Exec(ctx, `
+		UPDATE schema_migrations SET version = left(version, -4)
+		WHERE version LIKE '%.sql'
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to rename recorded migrations: %w", err)
+	}
+
+	// Databases created before migrations were tracked already have 001 applied
+	_, err = pool.Exec(ctx, `
+		INSERT INTO schema_migrations (version)
+		SELECT '001_initial_schema'
+		WHERE to_regclass('public.actions') IS NOT NULL
+		  AND NOT EXISTS (SELECT 1 FROM schema_migrations)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to baseline schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// migrateUp applies every migration in dir that hasn't been applied yet, in
+// version order, and returns the versions it applied. It stops at the first
+// failure; the failed migration is rolled back and earlier ones stay applied.
+func migrateUp(ctx context.Context, pool *pgxpool.Pool, dir string) ([]string, error) {
+	if err := prepareTracking(ctx, pool); err != nil {
+		return nil, err
+	}
+	migrations, err := database.ListMigrations(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []string
+	for _, migration := range migrations {
+		var exists bool
+		err := pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", migration.Version).Scan(&exists)
+		if err != nil {
+			return applied, fmt.Errorf("failed to check migration %s: %w", migration.Version, err)
+		}
+		if exists {
+			continue
+		}
+
+		err = runScript(ctx, pool, migration.UpPath, "INSERT INTO schema_migrations (version) VALUES ($1)", migration.Version)
+		if err != nil {
+			return applied, fmt.Errorf("migration %s failed: %w", migration.Version, err)
+		}
+		applied = append(applied, migration.Version)
+	}
+	return applied, nil
+}
+
+// migrateDown reverts the most recently applied migration with its down
+// script and returns its version, or "" if none is applied
+func migrateDown(ctx context.Context, pool *pgxpool.Pool, dir string) (string, error) {
+	if err := prepareTracking(ctx, pool); err != nil {
+		return "", err
+	}
+
+	var version string
+	err := pool.QueryRow(ctx, "SELECT version FROM schema_migrations ORDER BY version DESC LIMIT 1").Scan(&version)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to find the last migration: %w", err)
+	}
+
+	migrations, err := database.ListMigrations(dir)
+	if err != nil {
+		return "", err
+	}
+	var downPath string
+	for _, migration := range migrations {
+		if migration.Version == version {
+			downPath = migration.DownPath
+		}
+	}
+	if downPath == "" {
+		return "", fmt.Errorf("migration %s has no down script in %s", version, dir)
+	}
+
+	if err := runScript(ctx, pool, downPath, "DELETE FROM schema_migrations WHERE version = $1", version); err != nil {
+		return "", fmt.Errorf("rollback of %s failed: %w", version, err)
+	}
+	return version, nil
+}
+
+// runScript runs a migration script and the statement recording it in one
+// transaction
+func runScript(ctx context.Context, pool *pgxpool.Pool, path, record, version string) error {
+	script, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read migration file: %w", err)
+	}
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, string(script)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, record, version); err != nil {
+		return fmt.Errorf("failed to record migration: %w", err)
+	}
+	return tx.Commit(ctx)
 }
 
 func showStatus(pool *pgxpool.Pool) {
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// errNotAuthenticated is returned when the API redirects to the login page
+var errNotAuthenticated = errors.New("not signed in: pass --token or set TALOS_TOKEN")
+
+// apiClient talks to the dashboard API with the session token the web
+// dashboard stores in its atlas_token cookie
+type apiClient struct {
+	baseURL string
+	token   string
+	http    *http.Client
+}
+
+func newAPIClient(baseURL, token string) *apiClient {
+	return &apiClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		http: &http.Client{
+			Timeout: 5 * time.Second,
+			// The API redirects unauthenticated requests to /login; report
+			// that instead of following it
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// do sends a request with an optional JSON body and decodes the JSON
+// response into out when it is not nil
+func (c *apiClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.AddCookie(&http.Cookie{Name: "atlas_token", Value: c.token})
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 300 && resp.StatusCode < 400, resp.StatusCode == http.StatusUnauthorized:
+		return errNotAuthenticated
+	case resp.StatusCode >= 400:
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s %s: %s", method, path, apiErr.Error)
+		}
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// systemStatus is the body of GET /api/system/status
+type systemStatus struct {
+	Status   string                 `json:"status"`
+	Version  string                 `json:"version"`
+	Uptime   string                 `json:"uptime"`
+	Services map[string]string      `json:"services"`
+	Metrics  map[string]interface{} `json:"metrics"`
+}
+
+// healthReport is the body of GET /health. Each check maps to "healthy" or
+// to "unhealthy: <error>".
+type healthReport struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// alert is one entry of GET /api/dashboard/anomalies
+type alert struct {
+	ID         string    `json:"id"`
+	Severity   string    `json:"severity"`
+	Message    string    `json:"message"`
+	ResourceID string    `json:"resource_id"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// action is one entry of GET /api/actions
+type action struct {
+	ID               string    `json:"id"`
+	ResourceID       string    `json:"resource_id"`
+	ActionType       string    `json:"action_type"`
+	Status           string    `json:"status"`
+	RiskScore        float64   `json:"risk_score"`
+	EstimatedSavings float64   `json:"estimated_savings"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// savings is the part of GET /api/token-breakdown the dashboard shows
+type savings struct {
+	TotalSavingsUSD float64 `json:"total_savings_usd"`
+	TotalCostUSD    float64 `json:"total_cost_usd"`
+	NetProfitUSD    float64 `json:"net_profit_usd"`
+}
+
+// autonomyFlags mirrors GET/PUT /api/autonomy. Rules are passed through
+// untouched so toggling safe mode never rewrites them.
+type autonomyFlags struct {
+	Default  string            `json:"default,omitempty"`
+	Rules    []json.RawMessage `json:"rules,omitempty"`
+	SafeMode bool              `json:"safe_mode,omitempty"`
+}
+
+func (c *apiClient) systemStatus(ctx context.Context) (*systemStatus, error) {
+	var status systemStatus
+	return &status, c.do(ctx, http.MethodGet, "/api/system/status", nil, &status)
+}
+
+// rawSystemStatus returns GET /api/system/status as sent, for --output json
+func (c *apiClient) rawSystemStatus(ctx context.Context) (json.RawMessage, error) {
+	var raw json.RawMessage
+	return raw, c.do(ctx, http.MethodGet, "/api/system/status", nil, &raw)
+}
+
+// health returns GET /health, or nil when the daemon has no such endpoint.
+// A failing check answers 503 with the same body, so that is not an error.
+func (c *apiClient) health(ctx context.Context) (*healthReport, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/health", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusServiceUnavailable:
+	case http.StatusNotFound:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("GET /health: %s", resp.Status)
+	}
+	var report healthReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to decode /health: %w", err)
+	}
+	return &report, nil
+}
+
+func (c *apiClient) alerts(ctx context.Context) ([]alert, error) {
+	var alerts []alert
+	return alerts, c.do(ctx, http.MethodGet, "/api/dashboard/anomalies", nil, &alerts)
+}
+
+func (c *apiClient) actions(ctx context.Context, status string, limit int) ([]action, error) {
+	path := fmt.Sprintf("/api/actions?limit=%d", limit)
+	if status != "" {
+		path += "&status=" + status
+	}
+	var actions []action
+	return actions, c.do(ctx, http.MethodGet, path, nil, &actions)
+}
+
+func (c *apiClient) savings(ctx context.Context) (*savings, error) {
+	var s savings
+	return &s, c.do(ctx, http.MethodGet, "/api/token-breakdown", nil, &s)
+}
+
+func (c *apiClient) autonomy(ctx context.Context) (*autonomyFlags, error) {
+	var flags autonomyFlags
+	return &flags, c.do(ctx, http.MethodGet, "/api/autonomy", nil, &flags)
+}
+
+func (c *apiClient) updateAutonomy(ctx context.Context, flags *autonomyFlags) error {
+	return c.do(ctx, http.MethodPut, "/api/autonomy", flags, nil)
+}
+
+// resolveAction approves or rejects one action awaiting approval
+func (c *apiClient) resolveAction(ctx context.Context, id string, approve bool) error {
+	verb := "reject"
+	if approve {
+		verb = "approve"
+	}
+	return c.do(ctx, http.MethodPost, "/api/actions/"+id+"/"+verb, nil, nil)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validConfig = `
+server:
+  port: "8080"
+  mode: development
+ai:
+  mock: true
+jwt:
+  secret_key: "0123456789abcdef0123456789abcdef"
+cloud:
+  provider: aws
+  region: us-east-1
+`
+
+func writeConfigFile(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+	return path
+}
+
+// setValidEnvironment satisfies the environment validator's required
+// variables with values that agree with validConfig
+func setValidEnvironment(t *testing.T) {
+	t.Helper()
+	t.Setenv("PORT", "8080")
+	t.Setenv("MODE", "development")
+	t.Setenv("JWT_SECRET", "0123456789abcdef0123456789abcdef")
+	t.Setenv("REDIS_ADDRESS", "localhost:6379")
+}
+
+// runConfigValidate runs the command as main does; a non-nil error is a
+// non-zero exit
+func runConfigValidate(t *testing.T, path string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"config", "validate", "--file", path})
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetArgs(nil)
+	})
+	err := rootCmd.Execute()
+	return out.String(), err
+}
+
+func TestConfigValidate_Valid(t *testing.T) {
+	setValidEnvironment(t)
+
+	out, err := runConfigValidate(t, writeConfigFile(t, validConfig))
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "Configuration is valid")
+}
+
+func TestConfigValidate_ReportsEveryInvalidField(t *testing.T) {
+	setValidEnvironment(t)
+	t.Setenv("JWT_SECRET", "")
+
+	out, err := runConfigValidate(t, writeConfigFile(t, `
+server:
+  port: "8080"
+  mode: development
+jwt:
+  secret_key: short
+cloud:
+  provider: aws
+  region: us-east-1
+  savings_ratios:
+    stop: 2
+`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "4 problem(s)")
+	assert.Regexp(t, `ai\s+an AI API key is required`, out)
+	assert.Regexp(t, `jwt.secret_key\s+JWT secret key must be at least 32 characters`, out)
+	assert.Regexp(t, `cloud.savings_ratios.stop\s+cloud savings ratio for "stop" must be between 0 and 1`, out)
+	assert.Regexp(t, `environment\s+.*JWT_SECRET is not set`, out)
+}
+
+func TestConfigValidate_UnknownKeysWarnOnly(t *testing.T) {
+	setValidEnvironment(t)
+
+	out, err := runConfigValidate(t, writeConfigFile(t, validConfig+"redis:\n  adress: localhost:6379\n"))
+	require.NoError(t, err, out)
+	assert.Contains(t, out, "field adress not found")
+	assert.Contains(t, out, "Configuration is valid")
+}
+
+func TestConfigValidate_UnreadableFile(t *testing.T) {
+	setValidEnvironment(t)
+
+	out, err := runConfigValidate(t, filepath.Join(t.TempDir(), "missing.yaml"))
+	require.Error(t, err)
+	assert.Regexp(t, `file\s+failed to read config file`, out)
+
+	out, err = runConfigValidate(t, writeConfigFile(t, "server: [port"))
+	require.Error(t, err)
+	assert.Contains(t, out, "failed to parse config file")
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var (
+	dashboardAPI      string
+	dashboardToken    string
+	dashboardInterval time.Duration
+)
+
+// dashboardListLimit is how many pending approvals and recent actions are shown
+const dashboardListLimit = 10
+
+var dashboardCmd = &cobra.Command{
+	Use:   "dashboard",
+	Short: "Live terminal dashboard for status, alerts, approvals and savings",
+	Long: `Polls the Talos API and shows system status, active alerts, actions awaiting
+approval, recent actions and cumulative savings.
+
+Keys: ↑/↓ select an approval, a approve, x reject, s toggle safe mode,
+r refresh now, q quit.`,
+	Example: "  TALOS_TOKEN=... talos dashboard --api http://localhost:8080 --interval 10s",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		token := dashboardToken
+		if token == "" {
+			token = os.Getenv("TALOS_TOKEN")
+		}
+		if dashboardInterval < time.Second {
+			return fmt.Errorf("--interval must be at least 1s")
+		}
+
+		model := newDashboardModel(newAPIClient(dashboardAPI, token), dashboardInterval)
+		_, err := tea.NewProgram(model, tea.WithAltScreen(), tea.WithContext(cmd.Context())).Run()
+		return err
+	},
+}
+
+// dashboardSnapshot is one poll of the API. Sections that failed to load
+// keep the previous poll's data, so an unreachable daemon leaves the last
+// known state on screen.
+type dashboardSnapshot struct {
+	status   *systemStatus
+	alerts   []alert
+	pending  []action
+	recent   []action
+	savings  *savings
+	autonomy *autonomyFlags
+	err      error // first failure of this poll
+	at       time.Time
+}
+
+type (
+	snapshotMsg dashboardSnapshot
+	tickMsg     time.Time
+	// resultMsg reports the outcome of a keybinding's API call
+	resultMsg struct {
+		text string
+		err  error
+	}
+)
+
+type dashboardModel struct {
+	client   *apiClient
+	interval time.Duration
+
+	snap     dashboardSnapshot
+	lastGood time.Time
+	selected int
+	busy     bool // an approve, reject or safe-mode call is in flight
+	message  string
+	width    int
+}
+
+func newDashboardModel(client *apiClient, interval time.Duration) dashboardModel {
+	return dashboardModel{client: client, interval: interval}
+}
+
+func (m dashboardModel) Init() tea.Cmd {
+	return tea.Batch(m.poll(), m.tick())
+}
+
+func (m dashboardModel) tick() tea.Cmd {
+	return tea.Tick(m.interval, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// poll fetches every section; a failed section keeps its previous value
+func (m dashboardModel) poll() tea.Cmd {
+	prev := m.snap
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		snap := prev
+		snap.err = nil
+		snap.at = time.Now()
+		keep := func(err error) bool {
+			if err != nil && snap.err == nil {
+				snap.err = err
+			}
+			return err == nil
+		}
+
+		if status, err := client.systemStatus(ctx); keep(err) {
+			snap.status = status
+		}
+		if snap.err != nil {
+			// The daemon is down; don't wait out the timeout on every section
+			return snapshotMsg(snap)
+		}
+		if alerts, err := client.alerts(ctx); keep(err) {
+			snap.alerts = alerts
+		}
+		if pending, err := client.actions(ctx, "AWAITING_APPROVAL", dashboardListLimit); keep(err) {
+			snap.pending = pending
+		}
+		if recent, err := client.actions(ctx, "", dashboardListLimit); keep(err) {
+			snap.recent = recent
+		}
+		if s, err := client.savings(ctx); keep(err) {
+			snap.savings = s
+		}
+		if flags, err := client.autonomy(ctx); keep(err) {
+			snap.autonomy = flags
+		}
+		return snapshotMsg(snap)
+	}
+}
+
+// resolve approves or rejects the selected pending action
+func (m dashboardModel) resolve(approve bool) tea.Cmd {
+	if m.selected >= len(m.snap.pending) {
+		return nil
+	}
+	target := m.snap.pending[m.selected]
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		done := "rejected"
+		if approve {
+			done = "approved"
+		}
+		if err := client.resolveAction(ctx, target.ID, approve); err != nil {
+			return resultMsg{err: err}
+		}
+		return resultMsg{text: fmt.Sprintf("%s %s on %s", done, target.ActionType, target.ResourceID)}
+	}
+}
+
+// toggleSafeMode flips safe mode on the latest flags, leaving the rules as
+// they are
+func (m dashboardModel) toggleSafeMode() tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		flags, err := client.autonomy(ctx)
+		if err != nil {
+			return resultMsg{err: err}
+		}
+		flags.SafeMode = !flags.SafeMode
+		if err := client.updateAutonomy(ctx, flags); err != nil {
+			return resultMsg{err: err}
+		}
+		if flags.SafeMode {
+			return resultMsg{text: "safe mode on: every action now waits for approval"}
+		}
+		return resultMsg{text: "safe mode off"}
+	}
+}
+
+func (m dashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	case tickMsg:
+		return m, tea.Batch(m.poll(), m.tick())
+	case snapshotMsg:
+		m.snap = dashboardSnapshot(msg)
+		if m.snap.err == nil {
+			m.lastGood = m.snap.at
+		}
+		if m.selected >= len(m.snap.pending) {
+			m.selected = max(len(m.snap.pending)-1, 0)
+		}
+	case resultMsg:
+		m.busy = false
+		if msg.err != nil {
+			m.message = "error: " + msg.err.Error()
+			return m, nil
+		}
+		m.message = msg.text
+		return m, m.poll()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "up", "k":
+			if m.selected > 0 {
+				m.selected--
+			}
+		case "down", "j":
+			if m.selected < len(m.snap.pending)-1 {
+				m.selected++
+			}
+		case "r":
+			return m, m.poll()
+		case "a", "x", "s":
+			if m.busy {
+				return m, nil
+			}
+			var cmd tea.Cmd
+			switch msg.String() {
+			case "a":
+				cmd = m.resolve(true)
+			case "x":
+				cmd = m.resolve(false)
+			default:
+				cmd = m.toggleSafeMode()
+			}
+			if cmd != nil {
+				m.busy = true
+				m.message = "working..."
+			}
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+func (m dashboardModel) View() string {
+	var b strings.Builder
+	rule := strings.Repeat("─", max(min(m.width, 100), 40))
+
+	fmt.Fprintf(&b, "📡 Talos Dashboard  %s\n", m.client.baseURL)
+	switch {
+	case m.snap.at.IsZero():
+		b.WriteString("Connecting...\n")
+	case m.snap.err != nil && m.lastGood.IsZero():
+		fmt.Fprintf(&b, "🔴 API unavailable: %v\n", m.snap.err)
+	case m.snap.err != nil:
+		fmt.Fprintf(&b, "🟠 %v (showing data from %s)\n", m.snap.err, m.lastGood.Format("15:04:05"))
+	default:
+		fmt.Fprintf(&b, "🟢 Updated %s, every %s\n", m.snap.at.Format("15:04:05"), m.interval)
+	}
+	b.WriteString(rule + "\n")
+
+	if s := m.snap.status; s != nil {
+		fmt.Fprintf(&b, "System:  %s  v%s  up %s\n", strings.ToUpper(s.Status), s.Version, s.Uptime)
+		names := make([]string, 0, len(s.Services))
+		for name := range s.Services {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&b, "  %-18s %s\n", name, s.Services[name])
+		}
+	}
+	if f := m.snap.autonomy; f != nil {
+		mode := "off"
+		if f.SafeMode {
+			mode = "ON (every action waits for approval)"
+		}
+		fmt.Fprintf(&b, "Safe mode: %s\n", mode)
+	}
+	if s := m.snap.savings; s != nil {
+		fmt.Fprintf(&b, "Savings: $%.2f total, $%.2f AI cost, $%.2f net\n", s.TotalSavingsUSD, s.TotalCostUSD, s.NetProfitUSD)
+	}
+
+	fmt.Fprintf(&b, "%s\nAlerts (%d)\n", rule, len(m.snap.alerts))
+	for _, a := range m.snap.alerts {
+		fmt.Fprintf(&b, "  [%s] %s %s (%s)\n", strings.ToUpper(a.Severity), a.Message, a.ResourceID, a.Timestamp.Local().Format("Jan 02 15:04"))
+	}
+
+	fmt.Fprintf(&b, "%s\nAwaiting approval (%d)\n", rule, len(m.snap.pending))
+	for i, a := range m.snap.pending {
+		cursor := "  "
+		if i == m.selected {
+			cursor = "> "
+		}
+		fmt.Fprintf(&b, "%s%-12s %-28s $%8.2f/mo  risk %.1f\n", cursor, a.ActionType, a.ResourceID, a.EstimatedSavings, a.RiskScore)
+	}
+
+	fmt.Fprintf(&b, "%s\nRecent actions\n", rule)
+	for _, a := range m.snap.recent {
+		fmt.Fprintf(&b, "  %s  %-18s %-12s %-28s $%8.2f/mo\n", a.CreatedAt.Local().Format("Jan 02 15:04"), a.Status, a.ActionType, a.ResourceID, a.EstimatedSavings)
+	}
+
+	b.WriteString(rule + "\n")
+	if m.message != "" {
+		b.WriteString(m.message + "\n")
+	}
+	b.WriteString("↑/↓ select  a approve  x reject  s safe mode  r refresh  q quit\n")
+	return b.String()
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/ai"
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/cloud/aws"
+	"github.com/Xover-Official/Xover/internal/config"
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/Xover-Official/Xover/internal/monitoring"
+	"github.com/Xover-Official/Xover/internal/persistence"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+)
+
+var (
+	doctorEmailTo string
+	doctorTimeout time.Duration
+)
+
+type checkStatus string
+
+const (
+	checkPass checkStatus = "✅ pass"
+	checkFail checkStatus = "❌ fail"
+	checkSkip checkStatus = "➖ skip"
+)
+
+// doctorCheck is one row of the doctor report; hint says how to fix a
+// failure or enable a skipped check
+type doctorCheck struct {
+	name   string
+	status checkStatus
+	detail string
+	hint   string
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that every configured dependency is reachable before go-live",
+	Long: `Validates the configuration, then checks the database and its migrations,
+the ledger, Redis, every AI tier, the cloud credentials with a read-only
+resource fetch, and the email channel. Prints a pass/fail table with hints
+and exits non-zero when any check fails.`,
+	Example:       "  talos doctor --config config.yaml --email-to ops@example.com",
+	SilenceUsage:  true,
+	SilenceErrors: true, // main prints the error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		checks := runDoctor(cmd.Context(), configPath)
+		printDoctorReport(checks)
+
+		failed := 0
+		for _, c := range checks {
+			if c.status == checkFail {
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+// runDoctor runs every check in order. Checks that depend on a failed one
+// are skipped rather than failed again.
+func runDoctor(ctx context.Context, path string) []doctorCheck {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return []doctorCheck{{
+			name:   "config",
+			status: checkFail,
+			detail: err.Error(),
+			hint:   fmt.Sprintf("fix %s or the environment variable overriding it; no other check can run without a valid config", path),
+		}}
+	}
+
+	checks := []doctorCheck{{name: "config", status: checkPass, detail: fmt.Sprintf("%s is valid (%s mode)", path, cfg.Server.Mode)}}
+	checks = append(checks, checkDatabase(ctx, cfg)...)
+	checks = append(checks, checkLedger(ctx, cfg))
+	checks = append(checks, checkRedis(ctx, cfg))
+	checks = append(checks, checkAITiers(ctx, cfg)...)
+	checks = append(checks, checkCloud(ctx, cfg))
+	checks = append(checks, checkEmail(ctx, cfg))
+	return checks
+}
+
+// checkDatabase connects to the database and compares schema_migrations
+// with the files in migrations/
+func checkDatabase(ctx context.Context, cfg *config.Config) []doctorCheck {
+	dbCfg, err := database.ConfigFromDSN(cfg.Database.DSN)
+	if err != nil {
+		return []doctorCheck{
+			{name: "database", status: checkFail, detail: err.Error(), hint: "set database.dsn to a postgres:// connection string"},
+			{name: "migrations", status: checkSkip, detail: "database unavailable"},
+		}
+	}
+
+	dm, err := database.NewDatabaseManager(dbCfg, zap.NewNop(), otel.Tracer("talos-cli"))
+	if err != nil {
+		return []doctorCheck{
+			{name: "database", status: checkFail, detail: err.Error(), hint: "check that PostgreSQL is running and database.dsn has the right host and credentials"},
+			{name: "migrations", status: checkSkip, detail: "database unavailable"},
+		}
+	}
+	defer dm.Close()
+
+	checkCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	if err := dm.HealthCheck(checkCtx); err != nil {
+		return []doctorCheck{
+			{name: "database", status: checkFail, detail: err.Error(), hint: "check that PostgreSQL is running and accepting connections"},
+			{name: "migrations", status: checkSkip, detail: "database unavailable"},
+		}
+	}
+	checks := []doctorCheck{{name: "database", status: checkPass, detail: fmt.Sprintf("connected to %s/%s", dbCfg.Host, dbCfg.Database)}}
+
+	applied, err := dm.AppliedMigrations(checkCtx)
+	if err != nil {
+		return append(checks, doctorCheck{name: "migrations", status: checkFail, detail: err.Error(), hint: "run go run ./cmd/migrate to create the schema"})
+	}
+	migrations, _ := database.ListMigrations("migrations")
+	if len(migrations) == 0 {
+		return append(checks, doctorCheck{name: "migrations", status: checkSkip,
+			detail: fmt.Sprintf("%d applied; no migrations/ directory here to compare against", len(applied)),
+			hint:   "run talos doctor from the repository root to check for pending migrations"})
+	}
+
+	done := make(map[string]bool, len(applied))
+	for _, version := range applied {
+		done[version] = true
+	}
+	var pending []string
+	for _, migration := range migrations {
+		if !done[migration.Version] {
+			pending = append(pending, migration.Version)
+		}
+	}
+	if len(pending) > 0 {
+		return append(checks, doctorCheck{name: "migrations", status: checkFail,
+			detail: "pending: " + strings.Join(pending, ", "),
+			hint:   "run go run ./cmd/migrate to apply them"})
+	}
+	return append(checks, doctorCheck{name: "migrations", status: checkPass, detail: fmt.Sprintf("all %d applied", len(migrations))})
+}
+
+// checkLedger pings the production ledger the way atlas opens it; the
+// development SQLite ledger is created on first start and needs no check
+func checkLedger(ctx context.Context, cfg *config.Config) doctorCheck {
+	if cfg.Server.Mode != "production" {
+		return doctorCheck{name: "ledger", status: checkSkip, detail: "development mode uses the local SQLite ledger"}
+	}
+
+	ledger, err := persistence.NewPostgresLedgerWithConfig(cfg.Database.DSN, persistence.PostgresConfigFrom(cfg.Database))
+	if err != nil {
+		return doctorCheck{name: "ledger", status: checkFail, detail: err.Error(), hint: "check database.dsn; the ledger shares the application database"}
+	}
+	defer ledger.Close()
+
+	checkCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	if _, err := ledger.GetStats(checkCtx); err != nil {
+		return doctorCheck{name: "ledger", status: checkFail, detail: err.Error(), hint: "run go run ./cmd/migrate to create the actions table"}
+	}
+	return doctorCheck{name: "ledger", status: checkPass, detail: "PostgreSQL ledger reachable"}
+}
+
+func checkRedis(ctx context.Context, cfg *config.Config) doctorCheck {
+	if cfg.Redis.Address == "" {
+		return doctorCheck{name: "redis", status: checkSkip, detail: "redis.address not set", hint: "set redis.address to enable caching and the task queue"}
+	}
+
+	rdb := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Address,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	defer rdb.Close()
+
+	checkCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	if err := rdb.Ping(checkCtx).Err(); err != nil {
+		return doctorCheck{name: "redis", status: checkFail, detail: err.Error(), hint: "check that Redis is running at redis.address and redis.password is right"}
+	}
+	return doctorCheck{name: "redis", status: checkPass, detail: "PING " + cfg.Redis.Address}
+}
+
+// checkAITiers health-checks every tier through the same factory the
+// orchestrator uses, so OpenRouter fallbacks are checked too
+func checkAITiers(ctx context.Context, cfg *config.Config) []doctorCheck {
+	factory, err := ai.NewAIClientFactory(ai.NewConfig(cfg))
+	if err != nil {
+		return []doctorCheck{{name: "ai", status: checkFail, detail: err.Error(), hint: "check the keys under ai:"}}
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	results := factory.HealthCheckAll(checkCtx)
+
+	checks := make([]doctorCheck, 0, len(ai.TierNames))
+	for _, name := range ai.TierNames {
+		model := factory.GetClientByName(name).GetModel()
+		if err := results[name]; err != nil {
+			checks = append(checks, doctorCheck{name: "ai/" + name, status: checkFail, detail: err.Error(),
+				hint: "set the tier's provider key under ai: or ai.openrouter_key, or ai.mock for offline use"})
+			continue
+		}
+		checks = append(checks, doctorCheck{name: "ai/" + name, status: checkPass, detail: model})
+	}
+	return checks
+}
+
+// checkCloud loads the cloud credentials and lists resources, which is
+// read-only regardless of cloud.dry_run
+func checkCloud(ctx context.Context, cfg *config.Config) doctorCheck {
+	if cfg.Cloud.Provider != "aws" {
+		return doctorCheck{name: "cloud", status: checkSkip, detail: fmt.Sprintf("no adapter for provider %q", cfg.Cloud.Provider)}
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	adapter, err := aws.New(checkCtx, cloud.CloudConfig{Region: cfg.Cloud.Region, DryRun: true})
+	if err != nil {
+		return doctorCheck{name: "cloud", status: checkFail, detail: err.Error(), hint: "configure AWS credentials (environment, shared config or instance role)"}
+	}
+	resources, err := adapter.FetchResources(checkCtx)
+	if err != nil {
+		return doctorCheck{name: "cloud", status: checkFail, detail: err.Error(),
+			hint: "grant the credentials read access to EC2, RDS, ELB and CloudWatch in " + cfg.Cloud.Region}
+	}
+	return doctorCheck{name: "cloud", status: checkPass, detail: fmt.Sprintf("listed %d resource(s) in %s", len(resources), cfg.Cloud.Region)}
+}
+
+// checkEmail sends a test notification through the email channel when a
+// recipient is given
+func checkEmail(ctx context.Context, cfg *config.Config) doctorCheck {
+	if cfg.Email.SMTPHost == "" {
+		return doctorCheck{name: "email", status: checkSkip, detail: "email.smtp_host not set", hint: "configure email: to get alerts and scheduled reports by mail"}
+	}
+	if doctorEmailTo == "" {
+		return doctorCheck{name: "email", status: checkSkip, detail: "no recipient", hint: "pass --email-to to send a test email through " + cfg.Email.SMTPHost}
+	}
+
+	notifier := monitoring.NewNotifier(nil)
+	notifier.SetEmailSender(monitoring.NewSMTPSender(monitoring.SMTPConfig{
+		Host:     cfg.Email.SMTPHost,
+		Port:     cfg.Email.SMTPPort,
+		Username: cfg.Email.Username,
+		Password: cfg.Email.Password,
+		From:     cfg.Email.From,
+	}))
+	channel := &monitoring.NotificationChannel{ID: "doctor", Name: "doctor", Type: "email", Config: map[string]interface{}{"to": doctorEmailTo}}
+
+	checkCtx, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+	if err := notifier.TestChannel(checkCtx, channel); err != nil {
+		return doctorCheck{name: "email", status: checkFail, detail: err.Error(), hint: "check email.smtp_host, email.smtp_port and the SMTP credentials"}
+	}
+	return doctorCheck{name: "email", status: checkPass, detail: "test email sent to " + doctorEmailTo}
+}
+
+func printDoctorReport(checks []doctorCheck) {
+	fmt.Println("🩺 Talos Doctor")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSTATUS\tDETAIL")
+	for _, c := range checks {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", c.name, c.status, c.detail)
+		if c.hint != "" && c.status != checkPass {
+			fmt.Fprintf(w, "\t\t↳ %s\n", c.hint)
+		}
+	}
+	w.Flush()
+}
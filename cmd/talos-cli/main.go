@@ -1,10 +1,20 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/Xover-Official/Xover/internal/config"
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/Xover-Official/Xover/internal/engine"
+	"github.com/Xover-Official/Xover/internal/report"
+	"github.com/Xover-Official/Xover/internal/retention"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
 )
 
 var rootCmd = &cobra.Command{
@@ -28,31 +38,254 @@ var scanCmd = &cobra.Command{
 	},
 }
 
-var statusCmd = &cobra.Command{
-	Use:   "status",
-	Short: "Check system and swarm status",
-	Run: func(cmd *cobra.Command, args []string) {
-		fmt.Println("📡 Talos System Status")
-		fmt.Println("----------------------")
-		fmt.Println("System:   🟢 ONLINE")
-		fmt.Println("AI Swarm: 🔵 IDLE")
-		fmt.Println("Events:   ⚡ PROCESSING")
+var (
+	reportPeriod string
+	reportFormat string
+	reportOutput string
+	configPath   string
+	orgID        string
+)
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Generate a savings report (HTML or PDF)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo, closeDB, err := openRepository()
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		start, end, err := report.PeriodRange(reportPeriod, time.Now())
+		if err != nil {
+			return err
+		}
+
+		output := reportOutput
+		if output == "" {
+			output = fmt.Sprintf("talos-report-%s.%s", start.Format("2006-01-02"), reportFormat)
+		}
+		f, err := os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", output, err)
+		}
+		defer f.Close()
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 2*time.Minute)
+		defer cancel()
+
+		generator := report.NewGenerator(repo)
+		if err := generator.Generate(ctx, orgID, start, end, report.Format(reportFormat), f); err != nil {
+			os.Remove(output)
+			return err
+		}
+
+		fmt.Printf("📄 Report for %s - %s written to %s\n", start.Format("2006-01-02"), end.Format("2006-01-02"), output)
+		return nil
 	},
 }
 
-var optimizeCmd = &cobra.Command{
-	Use:   "optimize [resource-id]",
-	Short: "Run AI optimization on a resource",
-	Run: func(cmd *cobra.Command, args []string) {
-		if len(args) == 0 {
-			fmt.Println("Error: resource-id required")
-			return
+// openRepository connects to the database configured in --config
+func openRepository() (*database.Repository, func(), error) {
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	dbCfg, err := database.ConfigFromDSN(cfg.Database.DSN)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	logger := zap.NewNop()
+	tracer := otel.Tracer("talos-cli")
+	dm, err := database.NewDatabaseManager(dbCfg, logger, tracer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return database.NewRepository(dm, logger, tracer), dm.Close, nil
+}
+
+var (
+	filterActionType  string
+	filterEnvironment string
+	filterMaxSavings  float64
+	filterMaxRisk     float64
+	approvalDryRun    bool
+)
+
+var actionsCmd = &cobra.Command{
+	Use:   "actions",
+	Short: "Approve or reject queued optimization actions in bulk",
+}
+
+// newResolveCmd builds the approve/reject subcommands, which share their filter flags
+func newResolveCmd(approve bool) *cobra.Command {
+	use, verb, done := "reject", "Reject", "rejected"
+	if approve {
+		use, verb, done = "approve", "Approve", "approved"
+	}
+
+	cmd := &cobra.Command{
+		Use:     use,
+		Short:   verb + " every action awaiting approval that matches the filters",
+		Example: fmt.Sprintf("  talos actions %s --action-type stop --environment dev --max-savings 50 --max-risk 3 --dry-run", use),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			filter := database.ActionFilter{ActionType: filterActionType, Environment: filterEnvironment}
+			if cmd.Flags().Changed("max-savings") {
+				filter.MaxSavings = &filterMaxSavings
+			}
+			if cmd.Flags().Changed("max-risk") {
+				filter.MaxRisk = &filterMaxRisk
+			}
+
+			repo, closeDB, err := openRepository()
+			if err != nil {
+				return err
+			}
+			defer closeDB()
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), time.Minute)
+			defer cancel()
+
+			if approvalDryRun {
+				count, err := repo.CountAwaitingActions(ctx, orgID, filter)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("🔎 %d action(s) would be %s (dry run, nothing changed)\n", count, done)
+				return nil
+			}
+
+			affected, err := repo.ResolveAwaitingActions(ctx, orgID, filter, approve, nil)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("✅ %d action(s) %s\n", affected, done)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&filterActionType, "action-type", "", "only actions of this type (e.g. stop, resize)")
+	cmd.Flags().StringVar(&filterEnvironment, "environment", "", "only resources tagged with this environment")
+	cmd.Flags().Float64Var(&filterMaxSavings, "max-savings", 0, "only actions with estimated monthly savings below this amount")
+	cmd.Flags().Float64Var(&filterMaxRisk, "max-risk", 0, "only actions with a risk score below this value")
+	cmd.Flags().BoolVar(&approvalDryRun, "dry-run", false, "report how many actions match without changing them")
+	return cmd
+}
+
+var (
+	backtestPeriod    string
+	backtestCandidate string
+	backtestBaseline  string
+)
+
+var backtestCmd = &cobra.Command{
+	Use:     "backtest",
+	Short:   "Replay past cycles with a candidate engine config and compare the decisions",
+	Example: "  talos backtest --candidate candidate-engine.yaml --period quarter",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		candidate, err := loadEngineConfig(backtestCandidate)
+		if err != nil {
+			return err
+		}
+		baseline, err := loadEngineConfig(backtestBaseline)
+		if err != nil {
+			return err
+		}
+
+		start, end, err := report.PeriodRange(backtestPeriod, time.Now())
+		if err != nil {
+			return err
+		}
+
+		repo, closeDB, err := openRepository()
+		if err != nil {
+			return err
 		}
-		fmt.Printf("🤖 Optimizing resource %s...\n", args[0])
-		fmt.Println("💡 Recommendation: Resizing to t3.medium would save $45/mo")
+		defer closeDB()
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Minute)
+		defer cancel()
+
+		result, err := engine.NewBacktester(repo, baseline, zap.NewNop()).Run(ctx, start, end, candidate)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("🧪 Backtest %s - %s: %d cycle(s), %d decision(s) replayed, %d without a stored decision\n",
+			start.Format("2006-01-02"), end.Format("2006-01-02"), result.Cycles, result.Replayed, result.MissingDecisions)
+		fmt.Printf("  Baseline:  %d action(s), $%.2f/mo estimated savings\n", result.Baseline.Actions, result.Baseline.EstimatedSavings)
+		fmt.Printf("  Candidate: %d action(s), $%.2f/mo estimated savings\n", result.Candidate.Actions, result.Candidate.EstimatedSavings)
+		fmt.Printf("  Delta:     %+d action(s), %+.2f/mo\n", result.ActionDelta, result.SavingsDelta)
+		for _, change := range result.Changes {
+			fmt.Printf("    %s %s: %s -> %s (%+.2f)\n", change.CycleID, change.ResourceID, change.Baseline, change.Candidate, change.SavingsDelta)
+		}
+		return nil
 	},
 }
 
+var purgeDryRun bool
+
+var purgeCmd = &cobra.Command{
+	Use:     "purge",
+	Short:   "Delete history older than the configured retention periods",
+	Example: "  talos purge --dry-run",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		repo, closeDB, err := openRepository()
+		if err != nil {
+			return err
+		}
+		defer closeDB()
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Minute)
+		defer cancel()
+
+		policy := retention.Policy{
+			Periods:   cfg.Retention.Periods(),
+			BatchSize: cfg.Retention.BatchSize,
+			DryRun:    purgeDryRun || cfg.Retention.DryRun,
+			LegalHold: cfg.Retention.LegalHold,
+		}
+		if policy.LegalHold {
+			fmt.Println("⚖️  Legal hold is active; nothing was purged")
+			return nil
+		}
+
+		results, err := retention.NewPurger(repo, policy, zap.NewNop()).RunOnce(ctx)
+		for _, result := range results {
+			verb := "deleted"
+			if result.DryRun {
+				verb = "would delete"
+			}
+			fmt.Printf("🧹 %-20s %s %d row(s) older than %s\n", result.Table, verb, result.Rows, result.Cutoff.Format("2006-01-02"))
+		}
+		return err
+	},
+}
+
+// loadEngineConfig reads an engine config YAML over the defaults; an empty
+// path returns the defaults
+func loadEngineConfig(path string) (*engine.EngineConfig, error) {
+	cfg := engine.DefaultEngineConfig()
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read engine config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse engine config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Authenticate with Talos Cloud",
@@ -66,6 +299,37 @@ func init() {
 	rootCmd.AddCommand(statusCmd)
 	rootCmd.AddCommand(optimizeCmd)
 	rootCmd.AddCommand(loginCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(actionsCmd)
+	actionsCmd.AddCommand(newResolveCmd(true), newResolveCmd(false))
+	rootCmd.AddCommand(backtestCmd)
+	rootCmd.AddCommand(purgeCmd)
+	rootCmd.AddCommand(dashboardCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+
+	rootCmd.PersistentFlags().StringVar(&configPath, "config", "config.yaml", "path to the Talos configuration file")
+	rootCmd.PersistentFlags().StringVar(&orgID, "org", "", "organization whose actions and savings to use (default: actions of no organization)")
+	reportCmd.Flags().StringVar(&reportPeriod, "period", "month", "report period: week, month, quarter or year")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "html", "output format: html or pdf")
+	reportCmd.Flags().StringVarP(&reportOutput, "output", "o", "", "output file (default talos-report-<start>.<format>)")
+	backtestCmd.Flags().StringVar(&backtestPeriod, "period", "month", "period to replay: week, month, quarter or year")
+	backtestCmd.Flags().StringVar(&backtestCandidate, "candidate", "", "engine config YAML to evaluate")
+	backtestCmd.Flags().StringVar(&backtestBaseline, "baseline", "", "engine config YAML to compare against (default engine defaults)")
+	backtestCmd.MarkFlagRequired("candidate")
+	purgeCmd.Flags().BoolVar(&purgeDryRun, "dry-run", false, "count the rows that would be deleted without deleting them")
+	dashboardCmd.Flags().StringVar(&dashboardAPI, "api", "http://localhost:8080", "base URL of the Talos dashboard API")
+	dashboardCmd.Flags().StringVar(&dashboardToken, "token", "", "session token (default $TALOS_TOKEN)")
+	dashboardCmd.Flags().DurationVar(&dashboardInterval, "interval", 5*time.Second, "how often to poll the API")
+	doctorCmd.Flags().StringVar(&doctorEmailTo, "email-to", "", "send a test email to this address (comma-separated for several)")
+	doctorCmd.Flags().DurationVar(&doctorTimeout, "timeout", 15*time.Second, "time limit for each check")
+	configValidateCmd.Flags().StringVar(&configFile, "file", "", "configuration file to validate (default the --config file)")
+	optimizeCmd.Flags().BoolVar(&optimizeApply, "apply", false, "execute the recommended action (cloud.dry_run still applies)")
+	optimizeCmd.Flags().StringVar(&optimizeOutput, "output", "text", "output format: text or json")
+	statusCmd.Flags().StringVar(&statusAPI, "api", "http://localhost:8080", "base URL of the Talos daemon")
+	statusCmd.Flags().StringVar(&statusToken, "token", "", "session token (default $TALOS_TOKEN)")
+	statusCmd.Flags().StringVar(&statusFormat, "output", "table", "output format: table or json")
 }
 
 func main() {
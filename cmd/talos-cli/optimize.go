@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/ai"
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/cloud/aws"
+	"github.com/Xover-Official/Xover/internal/config"
+	"github.com/spf13/cobra"
+	"go.uber.org/zap"
+)
+
+var (
+	optimizeApply  bool
+	optimizeOutput string
+)
+
+// resourceAnalyzer is the part of ai.TOPAZOrchestrator that optimize uses
+type resourceAnalyzer interface {
+	AnalyzeWithROSES(ctx context.Context, resource *cloud.ResourceV2, contextData map[string]interface{}) (*ai.TOPAZDecision, error)
+}
+
+// recommendationActions maps the verb a T.O.P.A.Z. recommendation starts
+// with to the action --apply performs. MONITOR and NO_ACTION change nothing.
+var recommendationActions = map[string]cloud.ActionType{
+	"DOWNSIZE":               cloud.ActionResize,
+	"RIGHTSIZE":              cloud.ActionResize,
+	"OPTIMIZE_INSTANCE_TYPE": cloud.ActionOptimize,
+}
+
+// optimizeResult is what optimize prints, as text or with --output json
+type optimizeResult struct {
+	ResourceID       string   `json:"resource_id"`
+	ResourceType     string   `json:"resource_type"`
+	Region           string   `json:"region,omitempty"`
+	MonthlyCost      float64  `json:"monthly_cost"`
+	Recommendation   string   `json:"recommendation"`
+	Action           string   `json:"action,omitempty"` // empty when the recommendation changes nothing
+	GoNoGo           string   `json:"go_no_go"`
+	RiskScore        float64  `json:"risk_score"`
+	Confidence       float64  `json:"confidence"`
+	EstimatedSavings float64  `json:"estimated_savings"`
+	Reasoning        []string `json:"reasoning,omitempty"`
+	Applied          bool     `json:"applied"`
+	DryRun           bool     `json:"dry_run"`
+	AppliedSavings   float64  `json:"applied_savings,omitempty"`
+}
+
+// optimizeOptions are the optimize flags, plus the dry-run setting the
+// adapter was created with
+type optimizeOptions struct {
+	apply  bool
+	dryRun bool
+	output string
+}
+
+var optimizeCmd = &cobra.Command{
+	Use:   "optimize [resource-id]",
+	Short: "Run AI optimization on a resource",
+	Long: `Looks the resource up through the configured cloud adapter, runs the
+ROSES/T.O.P.A.Z. analysis on it and prints the recommendation, risk score
+and estimated savings. With --apply the recommended action is executed;
+cloud.dry_run in the configuration still applies.`,
+	Example:       "  talos optimize i-0abc123 --output json\n  talos optimize i-0abc123 --apply",
+	Args:          cobra.ExactArgs(1),
+	SilenceUsage:  true,
+	SilenceErrors: true, // main prints the error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if optimizeOutput != "text" && optimizeOutput != "json" {
+			return fmt.Errorf("--output must be text or json")
+		}
+		cfg, err := config.Load(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 2*time.Minute)
+		defer cancel()
+
+		adapter, err := newCloudAdapter(ctx, cfg)
+		if err != nil {
+			return err
+		}
+		orchestrator, err := ai.NewTOPAZOrchestrator(ai.NewConfig(cfg), nil, zap.NewNop())
+		if err != nil {
+			return err
+		}
+		defer orchestrator.Close()
+
+		return runOptimize(ctx, cmd.OutOrStdout(), adapter, orchestrator, args[0], optimizeOptions{
+			apply:  optimizeApply,
+			dryRun: cfg.Cloud.DryRun,
+			output: optimizeOutput,
+		})
+	},
+}
+
+// newCloudAdapter creates the adapter for the configured provider with the
+// same protection and termination policies the engine uses
+func newCloudAdapter(ctx context.Context, cfg *config.Config) (cloud.CloudAdapter, error) {
+	if cfg.Cloud.Provider != "aws" {
+		return nil, fmt.Errorf("no cloud adapter for provider %q", cfg.Cloud.Provider)
+	}
+	return aws.New(ctx, cloud.CloudConfig{
+		Region:        cfg.Cloud.Region,
+		DryRun:        cfg.Cloud.DryRun,
+		SavingsRatios: cfg.Cloud.SavingsRatios,
+		Protection: cloud.ProtectionPolicy{
+			Tags:        cfg.Cloud.Protection.Tags,
+			ResourceIDs: cfg.Cloud.Protection.ResourceIDs,
+		},
+		Termination: cloud.TerminationPolicy{
+			MinIdle:          cfg.Cloud.Termination.MinIdle,
+			IdleCPUThreshold: cfg.Cloud.Termination.IdleCPUThreshold,
+			BackupTag:        cfg.Cloud.Termination.BackupTag,
+			MaxBackupAge:     cfg.Cloud.Termination.MaxBackupAge,
+			SoftTerminate:    cfg.Cloud.Termination.SoftTerminate,
+			GracePeriod:      cfg.Cloud.Termination.GracePeriod,
+
+			ProtectedEnvironments: cfg.Cloud.Termination.ProtectedEnvironments,
+			ProtectedResources:    slices.Concat(ai.DefaultProtectedResources, cfg.Cloud.Termination.ProtectedResources),
+		},
+		Idle:        cloud.IdlePolicy{MinIdle: cfg.Cloud.Idle.MinIdle},
+		MaxAttempts: cfg.Cloud.RetryAttempts,
+	})
+}
+
+// runOptimize analyzes one resource, applies the recommended action when
+// asked and the verdict is Go, and prints the result. The result is printed
+// even when applying fails, and the failure is returned.
+func runOptimize(ctx context.Context, out io.Writer, adapter cloud.CloudAdapter, analyzer resourceAnalyzer, resourceID string, opts optimizeOptions) error {
+	resource, err := adapter.GetResource(ctx, resourceID)
+	if err != nil {
+		return fmt.Errorf("failed to look up %s: %w", resourceID, err)
+	}
+	decision, err := analyzer.AnalyzeWithROSES(ctx, resource, map[string]interface{}{"source": "cli"})
+	if err != nil {
+		return err
+	}
+
+	verb, _, _ := strings.Cut(decision.Recommendation, " ")
+	action := recommendationActions[verb]
+	result := optimizeResult{
+		ResourceID:       resource.ID,
+		ResourceType:     resource.Type,
+		Region:           resource.Region,
+		MonthlyCost:      resource.CostPerMonth,
+		Recommendation:   decision.Recommendation,
+		Action:           string(action),
+		GoNoGo:           decision.GoNoGo,
+		RiskScore:        decision.RiskScore,
+		Confidence:       decision.Confidence,
+		EstimatedSavings: decision.ExpectedSavings,
+		Reasoning:        decision.Reasoning,
+		DryRun:           opts.dryRun,
+	}
+
+	var applyErr error
+	switch {
+	case !opts.apply || action == "":
+	case decision.GoNoGo != "Go":
+		applyErr = fmt.Errorf("not applied: the analysis verdict is %s", decision.GoNoGo)
+	default:
+		result.AppliedSavings, applyErr = adapter.ApplyOptimization(ctx, resource, string(action))
+		result.Applied = applyErr == nil
+	}
+
+	if opts.output == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			return err
+		}
+		return applyErr
+	}
+	printOptimizeResult(out, result, opts.apply)
+	return applyErr
+}
+
+func printOptimizeResult(out io.Writer, result optimizeResult, apply bool) {
+	fmt.Fprintf(out, "🤖 %s (%s", result.ResourceID, result.ResourceType)
+	if result.Region != "" {
+		fmt.Fprintf(out, ", %s", result.Region)
+	}
+	fmt.Fprintf(out, ") costs $%.2f/mo\n", result.MonthlyCost)
+	fmt.Fprintf(out, "💡 Recommendation: %s\n", result.Recommendation)
+	fmt.Fprintf(out, "📈 Risk score: %.1f/100 (%s, %.0f%% confidence)\n", result.RiskScore, result.GoNoGo, result.Confidence*100)
+	fmt.Fprintf(out, "💸 Estimated savings: $%.2f/mo\n", result.EstimatedSavings)
+	for _, reason := range result.Reasoning {
+		fmt.Fprintf(out, "   • %s\n", reason)
+	}
+
+	switch {
+	case result.Applied && result.DryRun:
+		fmt.Fprintf(out, "🧪 Dry run: %s would save $%.2f/mo; nothing was changed\n", result.Action, result.AppliedSavings)
+	case result.Applied:
+		fmt.Fprintf(out, "✅ Applied %s, saving $%.2f/mo\n", result.Action, result.AppliedSavings)
+	case apply && result.Action == "":
+		fmt.Fprintln(out, "➖ Nothing to apply")
+	case !apply && result.Action != "":
+		fmt.Fprintf(out, "Run with --apply to %s %s\n", result.Action, result.ResourceID)
+	}
+}
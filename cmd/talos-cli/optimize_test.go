@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Xover-Official/Xover/internal/ai"
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAdapter serves one resource and records every ApplyOptimization call
+type fakeAdapter struct {
+	cloud.Simulator
+	applied []string
+}
+
+func (f *fakeAdapter) ApplyOptimization(ctx context.Context, resource *cloud.ResourceV2, action string) (float64, error) {
+	f.applied = append(f.applied, resource.ID+":"+action)
+	return 42.5, nil
+}
+
+func newFakeAdapter() *fakeAdapter {
+	return &fakeAdapter{Simulator: cloud.Simulator{MockResources: []*cloud.ResourceV2{{
+		ID: "i-0abc", Type: cloud.ResourceTypeEC2, Region: "us-east-1", CostPerMonth: 140, CPUUsage: 8, MemoryUsage: 12,
+	}}}}
+}
+
+// fakeAnalyzer returns decision for every resource
+type fakeAnalyzer struct {
+	decision ai.TOPAZDecision
+	analyzed []string
+}
+
+func (f *fakeAnalyzer) AnalyzeWithROSES(ctx context.Context, resource *cloud.ResourceV2, contextData map[string]interface{}) (*ai.TOPAZDecision, error) {
+	f.analyzed = append(f.analyzed, resource.ID)
+	decision := f.decision
+	decision.ResourceID = resource.ID
+	return &decision, nil
+}
+
+func downsizeAnalyzer() *fakeAnalyzer {
+	return &fakeAnalyzer{decision: ai.TOPAZDecision{
+		Recommendation:  "DOWNSIZE - Resource significantly underutilized",
+		RiskScore:       18.5,
+		Confidence:      0.82,
+		ExpectedSavings: 84,
+		GoNoGo:          "Go",
+		Reasoning:       []string{"CPU below 10% for 14 days"},
+	}}
+}
+
+func TestRunOptimize_PrintsAnalysisWithoutApplying(t *testing.T) {
+	adapter, analyzer := newFakeAdapter(), downsizeAnalyzer()
+	var out bytes.Buffer
+
+	require.NoError(t, runOptimize(context.Background(), &out, adapter, analyzer, "i-0abc", optimizeOptions{output: "text"}))
+
+	assert.Equal(t, []string{"i-0abc"}, analyzer.analyzed)
+	assert.Empty(t, adapter.applied, "nothing changes without --apply")
+	assert.Contains(t, out.String(), "Recommendation: DOWNSIZE - Resource significantly underutilized")
+	assert.Contains(t, out.String(), "Risk score: 18.5/100 (Go, 82% confidence)")
+	assert.Contains(t, out.String(), "Estimated savings: $84.00/mo")
+	assert.Contains(t, out.String(), "CPU below 10% for 14 days")
+	assert.Contains(t, out.String(), "--apply to resize i-0abc")
+	assert.NotContains(t, out.String(), "t3.medium")
+}
+
+func TestRunOptimize_JSONOutput(t *testing.T) {
+	adapter, analyzer := newFakeAdapter(), downsizeAnalyzer()
+	var out bytes.Buffer
+
+	require.NoError(t, runOptimize(context.Background(), &out, adapter, analyzer, "i-0abc", optimizeOptions{output: "json"}))
+
+	var result optimizeResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Equal(t, "i-0abc", result.ResourceID)
+	assert.Equal(t, analyzer.decision.Recommendation, result.Recommendation)
+	assert.Equal(t, "resize", result.Action)
+	assert.Equal(t, 18.5, result.RiskScore)
+	assert.Equal(t, 84.0, result.EstimatedSavings)
+	assert.Equal(t, 140.0, result.MonthlyCost)
+	assert.False(t, result.Applied)
+	assert.Empty(t, adapter.applied)
+}
+
+func TestRunOptimize_Apply(t *testing.T) {
+	adapter, analyzer := newFakeAdapter(), downsizeAnalyzer()
+	var out bytes.Buffer
+
+	require.NoError(t, runOptimize(context.Background(), &out, adapter, analyzer, "i-0abc", optimizeOptions{apply: true, output: "json"}))
+
+	assert.Equal(t, []string{"i-0abc:resize"}, adapter.applied)
+	var result optimizeResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.True(t, result.Applied)
+	assert.Equal(t, 42.5, result.AppliedSavings)
+}
+
+func TestRunOptimize_ApplyReportsDryRun(t *testing.T) {
+	adapter := newFakeAdapter()
+	var out bytes.Buffer
+
+	require.NoError(t, runOptimize(context.Background(), &out, adapter, downsizeAnalyzer(), "i-0abc", optimizeOptions{apply: true, dryRun: true, output: "text"}))
+
+	assert.Len(t, adapter.applied, 1, "the adapter is trusted to honor its dry-run setting")
+	assert.Contains(t, out.String(), "Dry run: resize would save $42.50/mo")
+}
+
+func TestRunOptimize_ApplyRefusesNoGo(t *testing.T) {
+	adapter, analyzer := newFakeAdapter(), downsizeAnalyzer()
+	analyzer.decision.GoNoGo = "No-Go"
+	var out bytes.Buffer
+
+	err := runOptimize(context.Background(), &out, adapter, analyzer, "i-0abc", optimizeOptions{apply: true, output: "text"})
+	assert.ErrorContains(t, err, "No-Go")
+	assert.Empty(t, adapter.applied)
+	assert.Contains(t, out.String(), "Recommendation: DOWNSIZE", "the analysis is still printed")
+}
+
+func TestRunOptimize_ApplyWithNothingToDo(t *testing.T) {
+	adapter, analyzer := newFakeAdapter(), downsizeAnalyzer()
+	analyzer.decision.Recommendation = "MONITOR - Current utilization acceptable"
+	var out bytes.Buffer
+
+	require.NoError(t, runOptimize(context.Background(), &out, adapter, analyzer, "i-0abc", optimizeOptions{apply: true, output: "text"}))
+	assert.Empty(t, adapter.applied)
+	assert.Contains(t, out.String(), "Nothing to apply")
+}
+
+func TestRunOptimize_UnknownResource(t *testing.T) {
+	adapter, analyzer := newFakeAdapter(), downsizeAnalyzer()
+
+	err := runOptimize(context.Background(), &bytes.Buffer{}, adapter, analyzer, "i-missing", optimizeOptions{output: "text"})
+	assert.ErrorContains(t, err, "i-missing")
+	assert.Empty(t, analyzer.analyzed)
+}
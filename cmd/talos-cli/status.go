@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusAPI    string
+	statusToken  string
+	statusFormat string
+)
+
+const (
+	serviceUp   = "UP"
+	serviceDown = "DOWN"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Check system and service status",
+	Long: `Queries the running daemon's /api/system/status and /health endpoints and
+shows the system status and whether each health-checked service is UP or
+DOWN. Exits non-zero when the daemon cannot be reached or a service is DOWN.`,
+	Example:       "  TALOS_TOKEN=... talos status --api http://localhost:8080 --output json",
+	Args:          cobra.NoArgs,
+	SilenceUsage:  true,
+	SilenceErrors: true, // main prints the error
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if statusFormat != "table" && statusFormat != "json" {
+			return fmt.Errorf("--output must be table or json")
+		}
+		token := statusToken
+		if token == "" {
+			token = os.Getenv("TALOS_TOKEN")
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 10*time.Second)
+		defer cancel()
+		return runStatus(ctx, cmd.OutOrStdout(), newAPIClient(statusAPI, token), statusFormat)
+	},
+}
+
+// serviceHealth is one health check as UP or DOWN, with the failure
+type serviceHealth struct {
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// statusReport is the --output json document: the system status as the
+// daemon sent it, and the health checks
+type statusReport struct {
+	Status   json.RawMessage          `json:"status"`
+	Health   string                   `json:"health"` // UP when the daemon and every service are
+	Services map[string]serviceHealth `json:"services"`
+}
+
+// runStatus prints the daemon's status and health, and fails when the
+// daemon is unreachable or any service is DOWN. Services come from the
+// system status and the /health checks.
+func runStatus(ctx context.Context, out io.Writer, client *apiClient, format string) error {
+	raw, err := client.rawSystemStatus(ctx)
+	if err != nil {
+		return daemonError(client.baseURL, err)
+	}
+	var status systemStatus
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return fmt.Errorf("failed to decode /api/system/status: %w", err)
+	}
+	health, err := client.health(ctx)
+	if err != nil {
+		return daemonError(client.baseURL, err)
+	}
+
+	// The health checks are authoritative for the services they cover
+	results := make(map[string]string, len(status.Services))
+	for name, state := range status.Services {
+		results[name] = state
+	}
+	report := statusReport{Status: raw, Health: serviceUp, Services: make(map[string]serviceHealth, len(results))}
+	if health != nil {
+		for name, result := range health.Checks {
+			results[name] = result
+		}
+		if !healthy(health.Status) {
+			report.Health = serviceDown
+		}
+	}
+	down := 0
+	for name, result := range results {
+		if healthy(result) {
+			report.Services[name] = serviceHealth{Status: serviceUp}
+			continue
+		}
+		down++
+		report.Health = serviceDown
+		report.Services[name] = serviceHealth{Status: serviceDown, Detail: strings.TrimPrefix(result, "unhealthy: ")}
+	}
+
+	if format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printStatusTable(out, client.baseURL, &status, report)
+	}
+
+	switch {
+	case down > 0:
+		return fmt.Errorf("%d service(s) DOWN", down)
+	case report.Health == serviceDown:
+		return fmt.Errorf("daemon health is %s", health.Status)
+	}
+	return nil
+}
+
+// healthy reports whether a health status or check result means UP
+func healthy(result string) bool {
+	switch strings.ToLower(result) {
+	case "", "ok", "up", "online", "healthy":
+		return true
+	}
+	return false
+}
+
+// daemonError explains a failure to connect, rather than surfacing the
+// raw dial error
+func daemonError(baseURL string, err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("cannot reach the Talos daemon at %s; is it running? (%v)", baseURL, urlErr.Err)
+	}
+	return err
+}
+
+func printStatusTable(out io.Writer, baseURL string, status *systemStatus, report statusReport) {
+	fmt.Fprintf(out, "📡 Talos System Status (%s)\n", baseURL)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Status:\t%s\n", status.Status)
+	fmt.Fprintf(w, "Version:\t%s\n", status.Version)
+	fmt.Fprintf(w, "Uptime:\t%s\n", status.Uptime)
+	fmt.Fprintf(w, "Health:\t%s\n", stateLabel(report.Health))
+	w.Flush()
+
+	if len(report.Services) > 0 {
+		fmt.Fprintln(out)
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SERVICE\tSTATE\tDETAIL")
+		for _, name := range sortedKeys(report.Services) {
+			service := report.Services[name]
+			fmt.Fprintf(w, "%s\t%s\t%s\n", name, stateLabel(service.Status), service.Detail)
+		}
+		w.Flush()
+	}
+
+	if len(status.Metrics) > 0 {
+		fmt.Fprintln(out)
+		w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "METRIC\tVALUE")
+		for _, name := range sortedKeys(status.Metrics) {
+			fmt.Fprintf(w, "%s\t%v\n", name, status.Metrics[name])
+		}
+		w.Flush()
+	}
+}
+
+func stateLabel(state string) string {
+	if state == serviceUp {
+		return "🟢 " + state
+	}
+	return "🔴 " + state
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
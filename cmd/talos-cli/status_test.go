@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const statusJSON = `{"status":"healthy","version":"1.0.0","uptime":"2h 34m",` +
+	`"services":{"ai_orchestrator":"online","database":"online"},` +
+	`"metrics":{"active_optimizations":3,"cost_savings_today":45.75}}`
+
+// newStatusServer serves the system status and a /health answer with the
+// given code and checks; a zero code means no /health endpoint
+func newStatusServer(t *testing.T, healthCode int, checks map[string]string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/system/status", func(w http.ResponseWriter, r *http.Request) {
+		if c, err := r.Cookie("atlas_token"); err != nil || c.Value != "tok" {
+			http.Redirect(w, r, "/login", http.StatusTemporaryRedirect)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(statusJSON))
+	})
+	if healthCode != 0 {
+		mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+			status := "UP"
+			if healthCode != http.StatusOK {
+				status = "DOWN"
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(healthCode)
+			json.NewEncoder(w).Encode(healthReport{Status: status, Checks: checks})
+		})
+	}
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestRunStatus_JSONOutput(t *testing.T) {
+	ts := newStatusServer(t, http.StatusOK, map[string]string{"redis": "healthy"})
+	var out bytes.Buffer
+
+	require.NoError(t, runStatus(context.Background(), &out, newAPIClient(ts.URL, "tok"), "json"))
+
+	var report statusReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.JSONEq(t, statusJSON, string(report.Status), "the status is passed through as sent")
+	assert.Equal(t, "UP", report.Health)
+	assert.Equal(t, map[string]serviceHealth{
+		"ai_orchestrator": {Status: "UP"},
+		"database":        {Status: "UP"},
+		"redis":           {Status: "UP"},
+	}, report.Services)
+}
+
+func TestRunStatus_FailingCheckIsDown(t *testing.T) {
+	ts := newStatusServer(t, http.StatusServiceUnavailable, map[string]string{
+		"redis":    "healthy",
+		"database": "unhealthy: connection refused",
+	})
+	var out bytes.Buffer
+
+	err := runStatus(context.Background(), &out, newAPIClient(ts.URL, "tok"), "json")
+	assert.EqualError(t, err, "1 service(s) DOWN")
+
+	var report statusReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report), "the report is printed before failing")
+	assert.Equal(t, "DOWN", report.Health)
+	assert.Equal(t, serviceHealth{Status: "DOWN", Detail: "connection refused"}, report.Services["database"],
+		"the health check overrides the system status")
+	assert.Equal(t, "UP", report.Services["redis"].Status)
+}
+
+func TestRunStatus_Table(t *testing.T) {
+	ts := newStatusServer(t, http.StatusServiceUnavailable, map[string]string{"database": "unhealthy: connection refused"})
+	var out bytes.Buffer
+
+	require.Error(t, runStatus(context.Background(), &out, newAPIClient(ts.URL, "tok"), "table"))
+
+	assert.Contains(t, out.String(), "Talos System Status ("+ts.URL+")")
+	assert.Regexp(t, `Version:\s+1\.0\.0`, out.String())
+	assert.Regexp(t, `Uptime:\s+2h 34m`, out.String())
+	assert.Regexp(t, `Health:\s+🔴 DOWN`, out.String())
+	assert.Regexp(t, `ai_orchestrator\s+🟢 UP`, out.String())
+	assert.Regexp(t, `database\s+🔴 DOWN\s+connection refused`, out.String())
+	assert.Regexp(t, `cost_savings_today\s+45\.75`, out.String())
+}
+
+func TestRunStatus_WithoutHealthEndpoint(t *testing.T) {
+	ts := newStatusServer(t, 0, nil)
+	var out bytes.Buffer
+
+	require.NoError(t, runStatus(context.Background(), &out, newAPIClient(ts.URL, "tok"), "json"))
+
+	var report statusReport
+	require.NoError(t, json.Unmarshal(out.Bytes(), &report))
+	assert.Equal(t, "UP", report.Health)
+	assert.Len(t, report.Services, 2)
+}
+
+func TestRunStatus_Unreachable(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	url := ts.URL
+	ts.Close()
+	var out bytes.Buffer
+
+	err := runStatus(context.Background(), &out, newAPIClient(url, "tok"), "table")
+	assert.ErrorContains(t, err, "cannot reach the Talos daemon at "+url)
+	assert.Empty(t, out.String())
+}
+
+func TestRunStatus_NotAuthenticated(t *testing.T) {
+	ts := newStatusServer(t, http.StatusOK, nil)
+
+	err := runStatus(context.Background(), &bytes.Buffer{}, newAPIClient(ts.URL, "wrong"), "table")
+	assert.ErrorIs(t, err, errNotAuthenticated)
+}
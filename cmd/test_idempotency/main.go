@@ -10,11 +10,11 @@ import (
 
 func main() {
 	// Setup
-	ledger, err := idempotency.NewLedger("atlas_ledger.db")
+	ledger, err := idempotency.NewSQLiteLedger("atlas_ledger.db")
 	if err != nil {
 		log.Fatalf("Failed to create ledger: %v", err)
 	}
-	engine := idempotency.NewEngine(ledger)
+	engine := idempotency.NewEngine(ledger, idempotency.DefaultTTL)
 
 	// Mock action payload
 	payload := map[string]string{
@@ -0,0 +1,72 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	talerrors "github.com/Xover-Official/Xover/internal/errors"
+)
+
+// budgetScope accumulates the cost of the AI calls made with one context
+type budgetScope struct {
+	mu    sync.Mutex
+	limit float64
+	spent float64
+}
+
+type budgetScopeKey struct{}
+
+// BeginBudgetScope returns a context whose Analyze calls share the
+// configured per-cycle cost budget. Once their responses have cost more than
+// the budget, further calls with the context fail with an
+// AI_INSUFFICIENT_TOKENS error instead of calling a provider. Cached
+// responses are free. Without a budget ctx is returned unchanged.
+func (o *UnifiedOrchestrator) BeginBudgetScope(ctx context.Context) context.Context {
+	if o.maxCostPerCycle <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, budgetScopeKey{}, &budgetScope{limit: o.maxCostPerCycle})
+}
+
+// BudgetSpent returns the cost charged so far to ctx's budget scope
+func BudgetSpent(ctx context.Context) float64 {
+	scope, ok := ctx.Value(budgetScopeKey{}).(*budgetScope)
+	if !ok {
+		return 0
+	}
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+	return scope.spent
+}
+
+// IsBudgetExceeded reports whether err comes from an exhausted budget scope
+func IsBudgetExceeded(err error) bool {
+	var talosErr *talerrors.TalosError
+	return errors.As(err, &talosErr) && talosErr.Code == talerrors.ErrAIInsufficientTokens
+}
+
+// checkBudget fails once ctx's budget scope has been exceeded
+func checkBudget(ctx context.Context) error {
+	scope, ok := ctx.Value(budgetScopeKey{}).(*budgetScope)
+	if !ok {
+		return nil
+	}
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+	if scope.spent > scope.limit {
+		return talerrors.NewAIBudgetExceededError(scope.spent, scope.limit)
+	}
+	return nil
+}
+
+// chargeBudget adds a response's cost to ctx's budget scope
+func chargeBudget(ctx context.Context, response *AIResponse) {
+	scope, ok := ctx.Value(budgetScopeKey{}).(*budgetScope)
+	if !ok {
+		return
+	}
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+	scope.spent += response.CostUSD
+}
@@ -0,0 +1,57 @@
+package ai
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+func TestBudgetScope_RejectsCallsOnceExceeded(t *testing.T) {
+	client := &fakeClient{model: "claude", cost: 0.04}
+	orchestrator := fallbackOrchestrator(t, DefaultFallbackChain, map[string]*fakeClient{"arbiter": client})
+	orchestrator.maxCostPerCycle = 0.10
+	resource := &cloud.ResourceV2{ID: "i-1", Type: "ec2"}
+
+	ctx := orchestrator.BeginBudgetScope(context.Background())
+	for i := 0; i < 3; i++ {
+		if _, err := orchestrator.Analyze(ctx, "analyze", 6.0, resource); err != nil {
+			t.Fatalf("call %d within budget failed: %v", i+1, err)
+		}
+	}
+	if spent := BudgetSpent(ctx); spent < 0.119 || spent > 0.121 {
+		t.Errorf("expected $0.12 spent, got %f", spent)
+	}
+
+	// The scope is over budget: the provider isn't called again
+	_, err := orchestrator.Analyze(ctx, "analyze", 6.0, resource)
+	if !IsBudgetExceeded(err) {
+		t.Fatalf("expected a budget error, got %v", err)
+	}
+	if _, err := orchestrator.AnalyzeStream(ctx, "analyze", 6.0, resource); !IsBudgetExceeded(err) {
+		t.Fatalf("expected a budget error for a stream, got %v", err)
+	}
+	if client.calls != 3 {
+		t.Errorf("expected 3 provider calls, got %d", client.calls)
+	}
+
+	// A new scope starts with a fresh budget, and calls outside a scope are unlimited
+	if _, err := orchestrator.Analyze(orchestrator.BeginBudgetScope(context.Background()), "analyze", 6.0, resource); err != nil {
+		t.Errorf("new scope rejected: %v", err)
+	}
+	if _, err := orchestrator.Analyze(context.Background(), "analyze", 6.0, resource); err != nil {
+		t.Errorf("unscoped call rejected: %v", err)
+	}
+}
+
+func TestBudgetScope_Disabled(t *testing.T) {
+	client := &fakeClient{model: "claude", cost: 100}
+	orchestrator := fallbackOrchestrator(t, DefaultFallbackChain, map[string]*fakeClient{"arbiter": client})
+
+	ctx := orchestrator.BeginBudgetScope(context.Background())
+	for i := 0; i < 3; i++ {
+		if _, err := orchestrator.Analyze(ctx, "analyze", 6.0, &cloud.ResourceV2{ID: "i-1", Type: "ec2"}); err != nil {
+			t.Fatalf("call %d failed without a budget: %v", i+1, err)
+		}
+	}
+}
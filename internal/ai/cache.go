@@ -5,20 +5,22 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
-	"fmt"
 	"time"
 
+	"github.com/Xover-Official/Xover/internal/cache"
 	"github.com/redis/go-redis/v9"
 )
 
 // RedisCache implements caching for AI responses
 type RedisCache struct {
-	client *redis.Client
-	ttl    time.Duration
-	prefix string
+	client  *redis.Client
+	ttl     time.Duration
+	prefix  string
+	breaker *cache.Breaker
 }
 
-// NewRedisCache creates a new Redis cache client
+// NewRedisCache creates a new Redis cache client. If Redis is unreachable the
+// cache starts degraded: lookups miss until a later probe reconnects.
 func NewRedisCache(addr string, password string, db int, ttl time.Duration) (*RedisCache, error) {
 	client := redis.NewClient(&redis.Options{
 		Addr:     addr,
@@ -30,22 +32,47 @@ func NewRedisCache(addr string, password string, db int, ttl time.Duration) (*Re
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	c := &RedisCache{
+		client:  client,
+		ttl:     ttl,
+		prefix:  "talos:ai:",
+		breaker: cache.NewBreaker(5, 30*time.Second),
+	}
 	if err := client.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		c.breaker.Trip()
 	}
 
-	return &RedisCache{
-		client: client,
-		ttl:    ttl,
-		prefix: "talos:ai:",
-	}, nil
+	return c, nil
+}
+
+// Degraded reports whether Redis calls are currently being skipped
+func (c *RedisCache) Degraded() bool {
+	return c.breaker.State() != cache.BreakerClosed
+}
+
+// do runs fn unless the breaker is open; redis.Nil counts as success
+func (c *RedisCache) do(fn func() error) error {
+	if !c.breaker.Allow() {
+		return cache.ErrCircuitOpen
+	}
+	err := fn()
+	if err != nil && err != redis.Nil {
+		c.breaker.Failure()
+	} else {
+		c.breaker.Success()
+	}
+	return err
 }
 
 // Get retrieves a cached AI response
 func (c *RedisCache) Get(ctx context.Context, prompt string) (*CachedResponse, error) {
 	key := c.makeKey(prompt)
 
-	data, err := c.client.Get(ctx, key).Bytes()
+	var data []byte
+	err := c.do(func() (err error) {
+		data, err = c.client.Get(ctx, key).Bytes()
+		return err
+	})
 	if err == redis.Nil {
 		return nil, nil // Cache miss
 	}
@@ -76,7 +103,9 @@ func (c *RedisCache) Set(ctx context.Context, prompt string, response *AIRespons
 		return err
 	}
 
-	return c.client.Set(ctx, key, data, c.ttl).Err()
+	return c.do(func() error {
+		return c.client.Set(ctx, key, data, c.ttl).Err()
+	})
 }
 
 // makeKey creates a cache key from the prompt
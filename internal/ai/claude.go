@@ -107,6 +107,12 @@ func (c *ClaudeClient) Analyze(ctx context.Context, request AIRequest) (*AIRespo
 	}, nil
 }
 
+// AnalyzeStream streams the response as a single delta; the provider's
+// response isn't streamed
+func (c *ClaudeClient) AnalyzeStream(ctx context.Context, request AIRequest) (<-chan StreamChunk, error) {
+	return StreamAnalyze(ctx, request, c.Analyze), nil
+}
+
 func (c *ClaudeClient) GetEstimatedCost(request AIRequest) float64 {
 	estimatedInputTokens := len(request.Prompt) / 4
 	estimatedOutputTokens := request.MaxTokens
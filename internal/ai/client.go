@@ -2,7 +2,10 @@ package ai
 
 import (
 	"context"
+	"sync"
 	"time"
+
+	"github.com/Xover-Official/Xover/internal/config"
 )
 
 // AIRequest represents a request to any AI model
@@ -25,6 +28,9 @@ type AIResponse struct {
 	Confidence   float64  // 0.0 to 1.0
 	Reasoning    string   // Explanation of the decision
 	Alternatives []string // Alternative recommendations considered
+	// Metadata describes how the response was produced, e.g. the "tier"
+	// that answered and, after a fallback, the tier it fell back from
+	Metadata map[string]interface{}
 }
 
 // AIClient is the interface all AI tier implementations must satisfy
@@ -32,6 +38,10 @@ type AIClient interface {
 	// Analyze processes a request and returns a response
 	Analyze(ctx context.Context, request AIRequest) (*AIResponse, error)
 
+	// AnalyzeStream processes a request and streams the response as it is
+	// generated. Draining the stream gives the same response as Analyze.
+	AnalyzeStream(ctx context.Context, request AIRequest) (<-chan StreamChunk, error)
+
 	// GetEstimatedCost estimates cost before making the call
 	GetEstimatedCost(request AIRequest) float64
 
@@ -56,6 +66,16 @@ type AIClientFactory struct {
 
 // NewAIClientFactory creates a new factory with all clients initialized
 func NewAIClientFactory(config *Config) (*AIClientFactory, error) {
+	if config.Mock {
+		return &AIClientFactory{
+			geminiFlashClient: NewMockClient(1),
+			geminiProClient:   NewMockClient(2),
+			claudeClient:      NewMockClient(3),
+			gpt5MiniClient:    NewMockClient(4),
+			devinClient:       NewMockClient(5),
+		}, nil
+	}
+
 	factory := &AIClientFactory{
 		geminiFlashClient: NewGeminiFlashClient(config.GeminiAPIKey),
 		geminiProClient:   NewGeminiProClient(config.GeminiAPIKey),
@@ -64,6 +84,22 @@ func NewAIClientFactory(config *Config) (*AIClientFactory, error) {
 		devinClient:       NewDevinClient(config.DevinAPIKey),
 	}
 
+	// Tiers without their own provider key go through OpenRouter. Devin has
+	// no OpenRouter model and always needs its own key.
+	if config.OpenRouterKey != "" {
+		router := NewOpenRouterClient(config.OpenRouterKey)
+		if config.GeminiAPIKey == "" {
+			factory.geminiFlashClient = newOpenRouterTier(router, "google/gemini-2.0-flash-exp", 1)
+			factory.geminiProClient = newOpenRouterTier(router, "google/gemini-pro", 2)
+		}
+		if config.ClaudeAPIKey == "" {
+			factory.claudeClient = newOpenRouterTier(router, "anthropic/claude-3.5-sonnet", 3)
+		}
+		if config.GPT5APIKey == "" {
+			factory.gpt5MiniClient = newOpenRouterTier(router, "openai/gpt-4o-mini", 4)
+		}
+	}
+
 	return factory, nil
 }
 
@@ -85,17 +121,22 @@ func (f *AIClientFactory) SetClient(name string, client AIClient) {
 
 // GetClientForRisk returns the appropriate AI client based on risk score
 func (f *AIClientFactory) GetClientForRisk(riskScore float64) AIClient {
+	return f.GetClientByName(TierForRisk(riskScore))
+}
+
+// TierForRisk returns the TierNames name of the tier that handles riskScore
+func TierForRisk(riskScore float64) string {
 	switch {
 	case riskScore < 3.0:
-		return f.geminiFlashClient // Tier 1: Sentinel
+		return "sentinel" // Tier 1
 	case riskScore < 5.0:
-		return f.geminiProClient // Tier 2: Strategist
+		return "strategist" // Tier 2
 	case riskScore < 7.0:
-		return f.claudeClient // Tier 3: Arbiter
+		return "arbiter" // Tier 3
 	case riskScore < 9.0:
-		return f.gpt5MiniClient // Tier 4: Reasoning Engine
+		return "reasoning" // Tier 4: Reasoning Engine
 	default:
-		return f.devinClient // Tier 5: Oracle
+		return "oracle" // Tier 5
 	}
 }
 
@@ -117,12 +158,76 @@ func (f *AIClientFactory) GetClientByName(name string) AIClient {
 	}
 }
 
+// TierNames lists the tiers GetClientByName resolves, cheapest first
+var TierNames = []string{"sentinel", "strategist", "arbiter", "reasoning", "oracle"}
+
+// DefaultFallbackChain is the fallback chain used when Config.FallbackChain
+// is empty. Oracle is left out: it needs its own Devin key and is the most
+// expensive tier.
+var DefaultFallbackChain = []string{"sentinel", "strategist", "arbiter", "reasoning"}
+
+// HealthCheckAll checks every tier concurrently and returns each tier's
+// result keyed by its TierNames name; a nil error means the tier is healthy
+func (f *AIClientFactory) HealthCheckAll(ctx context.Context) map[string]error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	results := make(map[string]error, len(TierNames))
+
+	for _, name := range TierNames {
+		wg.Add(1)
+		go func(name string, client AIClient) {
+			defer wg.Done()
+			err := client.HealthCheck(ctx)
+			mu.Lock()
+			results[name] = err
+			mu.Unlock()
+		}(name, f.GetClientByName(name))
+	}
+
+	wg.Wait()
+	return results
+}
+
 // Config holds API configuration
 type Config struct {
-	GeminiAPIKey string
-	ClaudeAPIKey string
-	GPT5APIKey   string
-	DevinAPIKey  string
-	CacheEnabled bool
-	CacheAddr    string
+	// OpenRouterKey serves every tier whose own provider key is empty
+	OpenRouterKey string
+	GeminiAPIKey  string
+	ClaudeAPIKey  string
+	GPT5APIKey    string
+	DevinAPIKey   string
+	CacheEnabled  bool
+	CacheAddr     string
+	Mock          bool // Use offline MockClient for every tier; no API keys required
+	// MaxPromptTokens caps generated ROSES prompts; 0 means unlimited
+	MaxPromptTokens int
+	// SchemaRetries is how many times a response violating DecisionSchema is
+	// re-prompted before falling back to free text
+	SchemaRetries int
+	// FallbackChain lists tiers by TierNames name. When a tier's provider is
+	// unavailable the request moves to the next tier in the chain; empty
+	// means DefaultFallbackChain.
+	FallbackChain []string
+	// MaxCostPerCycleUSD caps the AI spend of a budget scope, see
+	// UnifiedOrchestrator.BeginBudgetScope; 0 means unlimited
+	MaxCostPerCycleUSD float64
+}
+
+// NewConfig builds the AI configuration from the application config, giving
+// each provider its own key
+func NewConfig(cfg *config.Config) *Config {
+	return &Config{
+		OpenRouterKey:      cfg.AI.OpenRouterKey,
+		GeminiAPIKey:       cfg.AI.GeminiAPIKey,
+		ClaudeAPIKey:       cfg.AI.ClaudeAPIKey,
+		GPT5APIKey:         cfg.AI.GPT5MiniAPIKey,
+		DevinAPIKey:        cfg.AI.DevinKey,
+		CacheEnabled:       cfg.AI.CacheEnabled,
+		CacheAddr:          cfg.Redis.Address,
+		Mock:               cfg.AI.Mock,
+		MaxPromptTokens:    cfg.AI.MaxPromptTokens,
+		SchemaRetries:      cfg.AI.SchemaRetries,
+		FallbackChain:      cfg.AI.FallbackChain,
+		MaxCostPerCycleUSD: cfg.AI.MaxCostPerCycleUSD,
+	}
 }
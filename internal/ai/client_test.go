@@ -2,6 +2,9 @@ package ai
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/Xover-Official/Xover/internal/cloud"
@@ -58,3 +61,74 @@ func testLogger() *zap.Logger {
 	logger, _ := config.Build()
 	return logger
 }
+
+func TestMockClientVariesWithUtilization(t *testing.T) {
+	factory, err := NewAIClientFactory(&Config{Mock: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	orchestrator := &UnifiedOrchestrator{
+		factory: factory,
+		logger:  testLogger(),
+	}
+
+	ctx := context.Background()
+	idle := &cloud.ResourceV2{ID: "idle", Type: "ec2", CPUUsage: 0.05, MemoryUsage: 0.1}
+	busy := &cloud.ResourceV2{ID: "busy", Type: "ec2", CPUUsage: 0.95, MemoryUsage: 0.5}
+
+	idleResp, err := orchestrator.Analyze(ctx, "analyze idle", 2.0, idle)
+	if err != nil {
+		t.Fatalf("mock analysis failed: %v", err)
+	}
+	busyResp, err := orchestrator.Analyze(ctx, "analyze busy", 2.0, busy)
+	if err != nil {
+		t.Fatalf("mock analysis failed: %v", err)
+	}
+
+	if idleResp.TokensUsed != 0 || idleResp.CostUSD != 0 {
+		t.Errorf("expected zero token accounting, got %d tokens / $%f", idleResp.TokensUsed, idleResp.CostUSD)
+	}
+
+	var idleAnalysis, busyAnalysis MockAnalysis
+	if err := json.Unmarshal([]byte(idleResp.Content), &idleAnalysis); err != nil {
+		t.Fatalf("mock content is not valid JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(busyResp.Content), &busyAnalysis); err != nil {
+		t.Fatalf("mock content is not valid JSON: %v", err)
+	}
+
+	if idleAnalysis.Recommendations[0] == busyAnalysis.Recommendations[0] {
+		t.Error("expected mock recommendations to differ by utilization")
+	}
+	if err := factory.GetClientByName("oracle").HealthCheck(ctx); err != nil {
+		t.Errorf("mock health check failed: %v", err)
+	}
+}
+
+func TestROSESPromptTruncation(t *testing.T) {
+	tags := map[string]string{"environment": "production"}
+	for i := 0; i < 200; i++ {
+		tags[fmt.Sprintf("cost-center-%03d", i)] = "finance-shared-services"
+	}
+	resource := &cloud.ResourceV2{ID: "i-tagged", Type: "ec2", Provider: "aws", CPUUsage: 12.5, CostPerMonth: 80, Tags: tags}
+	contextData := map[string]interface{}{"history": strings.Repeat("x", 2000)}
+
+	roses := NewROSESFramework()
+	full := roses.GenerateROSESPrompt(resource, contextData)
+
+	roses.SetPromptBudget(800, zap.NewNop())
+	truncated := roses.GenerateROSESPrompt(resource, contextData)
+
+	if EstimateTokens(truncated) > 800 {
+		t.Errorf("expected prompt within budget, got %d tokens (untruncated %d)", EstimateTokens(truncated), EstimateTokens(full))
+	}
+	for _, keep := range []string{"CPU Usage: 12.50%", "Cost Per Month: $80.00", "environment: production", "<Rules>"} {
+		if !strings.Contains(truncated, keep) {
+			t.Errorf("truncated prompt lost %q", keep)
+		}
+	}
+	if !strings.Contains(truncated, "low-signal tags omitted") {
+		t.Error("expected a note about omitted tags")
+	}
+}
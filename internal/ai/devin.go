@@ -115,6 +115,12 @@ Provide comprehensive analysis:`, request.Prompt)
 	}, nil
 }
 
+// AnalyzeStream streams the response as a single delta; the provider's
+// response isn't streamed
+func (c *DevinClient) AnalyzeStream(ctx context.Context, request AIRequest) (<-chan StreamChunk, error) {
+	return StreamAnalyze(ctx, request, c.Analyze), nil
+}
+
 func (c *DevinClient) GetEstimatedCost(request AIRequest) float64 {
 	estimatedTokens := len(request.Prompt)/4 + request.MaxTokens
 	return float64(estimatedTokens) * 0.0005
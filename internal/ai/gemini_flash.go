@@ -114,6 +114,12 @@ func (c *GeminiFlashClient) Analyze(ctx context.Context, request AIRequest) (*AI
 	}, nil
 }
 
+// AnalyzeStream streams the response as a single delta; the provider's
+// response isn't streamed
+func (c *GeminiFlashClient) AnalyzeStream(ctx context.Context, request AIRequest) (<-chan StreamChunk, error) {
+	return StreamAnalyze(ctx, request, c.Analyze), nil
+}
+
 // GetEstimatedCost estimates cost before making the call
 func (c *GeminiFlashClient) GetEstimatedCost(request AIRequest) float64 {
 	estimatedTokens := len(request.Prompt)/4 + request.MaxTokens
@@ -111,6 +111,12 @@ func (c *GeminiProClient) Analyze(ctx context.Context, request AIRequest) (*AIRe
 	}, nil
 }
 
+// AnalyzeStream streams the response as a single delta; the provider's
+// response isn't streamed
+func (c *GeminiProClient) AnalyzeStream(ctx context.Context, request AIRequest) (<-chan StreamChunk, error) {
+	return StreamAnalyze(ctx, request, c.Analyze), nil
+}
+
 func (c *GeminiProClient) GetEstimatedCost(request AIRequest) float64 {
 	estimatedTokens := len(request.Prompt)/4 + request.MaxTokens
 	return float64(estimatedTokens) * 0.00001
@@ -195,6 +195,12 @@ func (c *GPT5MiniClient) extractAlternatives(content string) []string {
 	return alternatives
 }
 
+// AnalyzeStream streams the response as a single delta; the provider's
+// response isn't streamed
+func (c *GPT5MiniClient) AnalyzeStream(ctx context.Context, request AIRequest) (<-chan StreamChunk, error) {
+	return StreamAnalyze(ctx, request, c.Analyze), nil
+}
+
 func (c *GPT5MiniClient) GetEstimatedCost(request AIRequest) float64 {
 	estimatedInputTokens := len(request.Prompt) / 4
 	estimatedOutputTokens := request.MaxTokens
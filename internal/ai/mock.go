@@ -0,0 +1,156 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MockClient implements AIClient without any network access. It returns
+// deterministic, schema-valid responses derived from the resource utilization
+// carried in the request metadata, so the engine, CLI and dashboard can run
+// fully offline for demos and CI.
+type MockClient struct {
+	tier  int
+	model string
+}
+
+// MockAnalysis is the JSON document returned in AIResponse.Content by
+// MockClient; it always satisfies DecisionSchema
+type MockAnalysis = Decision
+
+// NewMockClient creates a new offline mock client for the given tier (1-5)
+func NewMockClient(tier int) *MockClient {
+	return &MockClient{
+		tier:  tier,
+		model: fmt.Sprintf("mock-tier-%d", tier),
+	}
+}
+
+// mockStreamDelta is how many bytes of content each streamed mock chunk carries
+const mockStreamDelta = 32
+
+// Analyze implements AIClient interface
+func (c *MockClient) Analyze(ctx context.Context, request AIRequest) (*AIResponse, error) {
+	stream, err := c.AnalyzeStream(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return DrainStream(stream)
+}
+
+// AnalyzeStream implements AIClient interface, streaming the analysis in
+// small deltas like a real model would
+func (c *MockClient) AnalyzeStream(ctx context.Context, request AIRequest) (<-chan StreamChunk, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	startTime := time.Now()
+
+	analysis := c.analyze(request)
+	content, err := json.Marshal(analysis)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal mock analysis: %w", err)
+	}
+
+	stream := make(chan StreamChunk)
+	go func() {
+		defer close(stream)
+		for len(content) > 0 {
+			n := min(mockStreamDelta, len(content))
+			if !sendChunk(ctx, stream, StreamChunk{Delta: string(content[:n])}) {
+				return
+			}
+			content = content[n:]
+		}
+		sendChunk(ctx, stream, StreamChunk{Final: &AIResponse{
+			TokensUsed:   0,
+			CostUSD:      0,
+			Model:        c.model,
+			Latency:      time.Since(startTime),
+			Confidence:   analysis.Confidence,
+			Reasoning:    "Deterministic mock analysis based on resource utilization",
+			Alternatives: []string{"No action"},
+		}})
+	}()
+	return stream, nil
+}
+
+// analyze derives a deterministic analysis from the utilization metadata
+func (c *MockClient) analyze(request AIRequest) MockAnalysis {
+	cpu := normalizeUtilization(metadataFloat(request.Metadata, "cpu_usage"))
+	mem := normalizeUtilization(metadataFloat(request.Metadata, "memory_usage"))
+
+	analysis := MockAnalysis{RiskScore: request.RiskScore}
+
+	switch {
+	case cpu < 0.2 && mem < 0.3:
+		analysis.Confidence = 0.9
+		analysis.Recommendations = []string{
+			"Downsize instance by one size class",
+			"Evaluate scheduling outside business hours",
+		}
+		analysis.Reasoning = []string{
+			fmt.Sprintf("CPU utilization %.0f%% and memory utilization %.0f%% are well below capacity", cpu*100, mem*100),
+		}
+	case cpu < 0.4 && mem < 0.5:
+		analysis.Confidence = 0.75
+		analysis.Recommendations = []string{"Rightsize instance to match observed utilization"}
+		analysis.Reasoning = []string{
+			fmt.Sprintf("CPU utilization %.0f%% leaves moderate headroom", cpu*100),
+		}
+	case cpu > 0.8 || mem > 0.9:
+		analysis.Confidence = 0.8
+		analysis.Recommendations = []string{"No downsizing; monitor for capacity pressure"}
+		analysis.Reasoning = []string{"Resource is running near capacity"}
+	default:
+		analysis.Confidence = 0.6
+		analysis.Recommendations = []string{"No action required"}
+		analysis.Reasoning = []string{"Utilization is within the optimal range"}
+	}
+
+	return analysis
+}
+
+// GetEstimatedCost always returns zero; mock calls are free
+func (c *MockClient) GetEstimatedCost(request AIRequest) float64 {
+	return 0
+}
+
+// GetModel returns the model identifier
+func (c *MockClient) GetModel() string {
+	return c.model
+}
+
+// GetTier returns the tier level
+func (c *MockClient) GetTier() int {
+	return c.tier
+}
+
+// HealthCheck always succeeds for the mock client
+func (c *MockClient) HealthCheck(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// metadataFloat reads a numeric value from request metadata
+func metadataFloat(metadata map[string]interface{}, key string) float64 {
+	switch v := metadata[key].(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	default:
+		return 0
+	}
+}
+
+// normalizeUtilization accepts utilization as a ratio (0-1) or a percentage (0-100)
+func normalizeUtilization(v float64) float64 {
+	if v > 1 {
+		return v / 100
+	}
+	return v
+}
@@ -1,12 +1,14 @@
 package ai
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -32,44 +34,12 @@ func NewOpenRouterClient(apiKey string) *OpenRouterClient {
 func (c *OpenRouterClient) Analyze(ctx context.Context, request AIRequest, modelName string) (*AIResponse, error) {
 	startTime := time.Now()
 
-	reqBody := map[string]interface{}{
-		"model": modelName,
-		"messages": []map[string]string{
-			{
-				"role":    "user",
-				"content": request.Prompt,
-			},
-		},
-		"max_tokens":  request.MaxTokens,
-		"temperature": request.Temperature,
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-	httpReq.Header.Set("HTTP-Referer", "https://github.com/Xover-Official/Xover")
-	httpReq.Header.Set("X-Title", "Talos Cloud Guardian")
-
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.post(ctx, request, modelName, false)
 	if err != nil {
-		return nil, fmt.Errorf("API call failed: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
-	}
-
 	var result struct {
 		Choices []struct {
 			Message struct {
@@ -108,6 +78,130 @@ func (c *OpenRouterClient) Analyze(ctx context.Context, request AIRequest, model
 	}, nil
 }
 
+// AnalyzeStream calls any model through OpenRouter, streaming the completion
+// as the model generates it
+func (c *OpenRouterClient) AnalyzeStream(ctx context.Context, request AIRequest, modelName string) (<-chan StreamChunk, error) {
+	startTime := time.Now()
+
+	resp, err := c.post(ctx, request, modelName, true)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := make(chan StreamChunk)
+	go func() {
+		defer close(stream)
+		defer resp.Body.Close()
+
+		final := &AIResponse{Model: modelName, Confidence: 0.90}
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			// Server-sent events; lines starting with ':' are keep-alive comments
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			if !ok {
+				continue
+			}
+			if data == "[DONE]" {
+				break
+			}
+
+			var event struct {
+				Model   string `json:"model"`
+				Choices []struct {
+					Delta struct {
+						Content string `json:"content"`
+					} `json:"delta"`
+				} `json:"choices"`
+				Usage *struct {
+					PromptTokens     int `json:"prompt_tokens"`
+					CompletionTokens int `json:"completion_tokens"`
+					TotalTokens      int `json:"total_tokens"`
+				} `json:"usage"`
+				Error *struct {
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				sendChunk(ctx, stream, StreamChunk{Err: fmt.Errorf("failed to decode stream event: %w", err)})
+				return
+			}
+			if event.Error != nil {
+				sendChunk(ctx, stream, StreamChunk{Err: fmt.Errorf("stream failed: %s", event.Error.Message)})
+				return
+			}
+			if event.Model != "" {
+				final.Model = event.Model
+			}
+			if event.Usage != nil {
+				final.TokensUsed = event.Usage.TotalTokens
+				final.CostUSD = c.calculateCost(final.Model, event.Usage.PromptTokens, event.Usage.CompletionTokens)
+			}
+			if len(event.Choices) > 0 && event.Choices[0].Delta.Content != "" {
+				if !sendChunk(ctx, stream, StreamChunk{Delta: event.Choices[0].Delta.Content}) {
+					return
+				}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			sendChunk(ctx, stream, StreamChunk{Err: fmt.Errorf("failed to read stream: %w", err)})
+			return
+		}
+
+		final.Latency = time.Since(startTime)
+		sendChunk(ctx, stream, StreamChunk{Final: final})
+	}()
+	return stream, nil
+}
+
+// post sends a chat completion request for the prompt, returning the response
+// if it succeeded
+func (c *OpenRouterClient) post(ctx context.Context, request AIRequest, modelName string, stream bool) (*http.Response, error) {
+	reqBody := map[string]interface{}{
+		"model": modelName,
+		"messages": []map[string]string{
+			{
+				"role":    "user",
+				"content": request.Prompt,
+			},
+		},
+		"max_tokens":  request.MaxTokens,
+		"temperature": request.Temperature,
+	}
+	if stream {
+		reqBody["stream"] = true
+		// Usage is only reported on a stream when asked for
+		reqBody["usage"] = map[string]bool{"include": true}
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	httpReq.Header.Set("HTTP-Referer", "https://github.com/Xover-Official/Xover")
+	httpReq.Header.Set("X-Title", "Talos Cloud Guardian")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("API call failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+	return resp, nil
+}
+
 // calculateCost estimates cost based on OpenRouter pricing
 func (c *OpenRouterClient) calculateCost(model string, inputTokens, outputTokens int) float64 {
 	// OpenRouter pricing (approximate, per 1M tokens)
@@ -141,3 +235,41 @@ func (c *OpenRouterClient) HealthCheck(ctx context.Context, model string) error
 	_, err := c.Analyze(ctx, testReq, model)
 	return err
 }
+
+// openRouterTier serves one AI tier through OpenRouter with a fixed model
+type openRouterTier struct {
+	client *OpenRouterClient
+	model  string
+	tier   int
+}
+
+func newOpenRouterTier(client *OpenRouterClient, model string, tier int) *openRouterTier {
+	return &openRouterTier{client: client, model: model, tier: tier}
+}
+
+// Analyze calls the tier's model through OpenRouter
+func (t *openRouterTier) Analyze(ctx context.Context, request AIRequest) (*AIResponse, error) {
+	return t.client.Analyze(ctx, request, t.model)
+}
+
+// AnalyzeStream streams the tier's model through OpenRouter
+func (t *openRouterTier) AnalyzeStream(ctx context.Context, request AIRequest) (<-chan StreamChunk, error) {
+	return t.client.AnalyzeStream(ctx, request, t.model)
+}
+
+// GetEstimatedCost estimates the cost of a request at OpenRouter pricing
+func (t *openRouterTier) GetEstimatedCost(request AIRequest) float64 {
+	return t.client.calculateCost(t.model, len(request.Prompt)/4, request.MaxTokens)
+}
+
+func (t *openRouterTier) GetModel() string {
+	return t.model
+}
+
+func (t *openRouterTier) GetTier() int {
+	return t.tier
+}
+
+func (t *openRouterTier) HealthCheck(ctx context.Context) error {
+	return t.client.HealthCheck(ctx, t.model)
+}
@@ -6,10 +6,13 @@ import (
 	"strings"
 	"time"
 
+	"sort"
+	"sync"
+
 	"github.com/Xover-Official/Xover/internal/cloud"
 	"github.com/Xover-Official/Xover/internal/telemetry"
 	"go.opentelemetry.io/otel/attribute"
-	"sync"
+	"go.uber.org/zap"
 )
 
 // ROSESFramework implements the Role-Objective-Scenario-ExpectedSolution-Steps prompting method
@@ -21,6 +24,9 @@ type ROSESFramework struct {
 	steps             []string
 	rules             []string
 	systemInstruction string
+
+	maxPromptTokens int // 0 means unlimited
+	logger          *zap.Logger
 }
 
 // TOPAZLogic implements the T.O.P.A.Z. Zero-Sum Learning framework
@@ -103,6 +109,12 @@ func NewROSESFramework() *ROSESFramework {
 	}
 }
 
+// DefaultProtectedResources are the resource ID patterns T.O.P.A.Z. treats
+// as critical. The engine's default protection policy never acts on them, and
+// adapters never terminate them once they are passed on as
+// cloud.TerminationPolicy.ProtectedResources.
+var DefaultProtectedResources = []string{"db-prod-*", "auth-*", "payment-*"}
+
 // NewTOPAZLogic creates a new T.O.P.A.Z. logic engine
 func NewTOPAZLogic() *TOPAZLogic {
 	return &TOPAZLogic{
@@ -114,7 +126,7 @@ func NewTOPAZLogic() *TOPAZLogic {
 		},
 		antifragile: AntifragileRules{
 			RequireAntiFragileTags: true,
-			ProtectedResources:     []string{"db-prod-*", "auth-*", "payment-*"},
+			ProtectedResources:     append([]string(nil), DefaultProtectedResources...),
 			MaintenanceWindows:     []string{"Saturday 02:00-04:00", "Sunday 02:00-04:00"},
 		},
 		learning: LearningEngine{
@@ -125,8 +137,135 @@ func NewTOPAZLogic() *TOPAZLogic {
 	}
 }
 
-// GenerateROSESPrompt creates a structured prompt using the ROSES framework
+// highSignalTags are the tags the T.O.P.A.Z. logic reads; they are the last
+// to be dropped when a prompt exceeds its token budget
+var highSignalTags = map[string]bool{
+	"environment":  true,
+	"env":          true,
+	"anti-fragile": true,
+	"auto-scaling": true,
+	"redundancy":   true,
+}
+
+// maxContextValueLen is how long a context value may be once context is summarized
+const maxContextValueLen = 80
+
+// EstimateTokens approximates the token count of a prompt (~4 characters per token)
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// SetPromptBudget caps generated prompts at maxTokens (0 disables the cap).
+// Truncations are logged to logger.
+func (r *ROSESFramework) SetPromptBudget(maxTokens int, logger *zap.Logger) {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	r.maxPromptTokens = maxTokens
+	r.logger = logger
+}
+
+// promptData is the variable part of a ROSES prompt
+type promptData struct {
+	tags           []string // "key: value", high-signal tags first
+	context        []string // "key: value", sorted by key
+	omittedTags    int
+	omittedContext int
+}
+
+// GenerateROSESPrompt creates a structured prompt using the ROSES framework.
+// If the prompt exceeds the token budget, low-signal tags are dropped first,
+// then context values are shortened and finally collapsed into a summary.
+// Resource metrics and rules are always kept.
 func (r *ROSESFramework) GenerateROSESPrompt(resource *cloud.ResourceV2, contextData map[string]interface{}) string {
+	high, low := partitionTags(resource.Tags)
+	data := promptData{
+		tags:    append(high, low...),
+		context: contextLines(contextData, 0),
+	}
+
+	prompt := r.renderPrompt(resource, data)
+	if r.maxPromptTokens <= 0 || EstimateTokens(prompt) <= r.maxPromptTokens {
+		return prompt
+	}
+	originalTokens := EstimateTokens(prompt)
+
+	// 1. Drop low-signal tags, last first
+	for len(low) > 0 && EstimateTokens(prompt) > r.maxPromptTokens {
+		low = low[:len(low)-1]
+		data.tags = append(high[:len(high):len(high)], low...)
+		data.omittedTags++
+		prompt = r.renderPrompt(resource, data)
+	}
+
+	// 2. Shorten long context values
+	if EstimateTokens(prompt) > r.maxPromptTokens {
+		data.context = contextLines(contextData, maxContextValueLen)
+		prompt = r.renderPrompt(resource, data)
+	}
+
+	// 3. Collapse context to a summary line
+	if EstimateTokens(prompt) > r.maxPromptTokens && len(data.context) > 0 {
+		data.omittedContext = len(data.context)
+		data.context = nil
+		prompt = r.renderPrompt(resource, data)
+	}
+
+	if r.logger != nil {
+		r.logger.Warn("ROSES prompt truncated to fit token budget",
+			zap.String("resource_id", resource.ID),
+			zap.Int("budget_tokens", r.maxPromptTokens),
+			zap.Int("original_tokens", originalTokens),
+			zap.Int("final_tokens", EstimateTokens(prompt)),
+			zap.Int("tags_omitted", data.omittedTags),
+			zap.Int("context_items_omitted", data.omittedContext),
+		)
+	}
+
+	return prompt
+}
+
+// partitionTags splits tags into high- and low-signal "key: value" lines,
+// each sorted by key so truncation is deterministic
+func partitionTags(tags map[string]string) (high, low []string) {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		line := fmt.Sprintf("%s: %s", key, tags[key])
+		if highSignalTags[key] {
+			high = append(high, line)
+		} else {
+			low = append(low, line)
+		}
+	}
+	return high, low
+}
+
+// contextLines renders context as sorted "key: value" lines, truncating
+// values longer than maxLen (0 for no limit)
+func contextLines(contextData map[string]interface{}, maxLen int) []string {
+	keys := make([]string, 0, len(contextData))
+	for key := range contextData {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := fmt.Sprintf("%v", contextData[key])
+		if maxLen > 0 && len(value) > maxLen {
+			value = value[:maxLen] + "..."
+		}
+		lines = append(lines, fmt.Sprintf("%s: %s", key, value))
+	}
+	return lines
+}
+
+func (r *ROSESFramework) renderPrompt(resource *cloud.ResourceV2, data promptData) string {
 	promptBuilder := strings.Builder{}
 
 	// XML Delimiters for structure
@@ -160,18 +299,24 @@ func (r *ROSESFramework) GenerateROSESPrompt(resource *cloud.ResourceV2, context
 	promptBuilder.WriteString(fmt.Sprintf("Cost Per Month: $%.2f\n", resource.CostPerMonth))
 
 	// Add tags
-	if len(resource.Tags) > 0 {
+	if len(data.tags) > 0 || data.omittedTags > 0 {
 		promptBuilder.WriteString("Tags:\n")
-		for key, value := range resource.Tags {
-			promptBuilder.WriteString(fmt.Sprintf("  %s: %s\n", key, value))
+		for _, line := range data.tags {
+			promptBuilder.WriteString(fmt.Sprintf("  %s\n", line))
+		}
+		if data.omittedTags > 0 {
+			promptBuilder.WriteString(fmt.Sprintf("  (%d low-signal tags omitted)\n", data.omittedTags))
 		}
 	}
 
 	// Add context data
-	if contextData != nil {
+	if len(data.context) > 0 || data.omittedContext > 0 {
 		promptBuilder.WriteString("Additional Context:\n")
-		for key, value := range contextData {
-			promptBuilder.WriteString(fmt.Sprintf("  %s: %v\n", key, value))
+		for _, line := range data.context {
+			promptBuilder.WriteString(fmt.Sprintf("  %s\n", line))
+		}
+		if data.omittedContext > 0 {
+			promptBuilder.WriteString(fmt.Sprintf("  (%d context items omitted to fit the token budget)\n", data.omittedContext))
 		}
 	}
 
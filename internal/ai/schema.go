@@ -0,0 +1,205 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ErrSchemaViolation is returned when an AI response does not match the
+// expected JSON Schema
+var ErrSchemaViolation = errors.New("AI response does not match schema")
+
+// DecisionSchemaJSON is the JSON Schema every decision response must satisfy.
+// It is included verbatim in prompts so models know the exact shape expected.
+const DecisionSchemaJSON = `{
+  "type": "object",
+  "required": ["risk_score", "confidence", "recommendations", "reasoning"],
+  "properties": {
+    "risk_score": {"type": "number", "minimum": 0, "maximum": 10},
+    "confidence": {"type": "number", "minimum": 0, "maximum": 1},
+    "recommendations": {"type": "array", "items": {"type": "string", "minLength": 1}},
+    "reasoning": {"type": "array", "items": {"type": "string"}}
+  }
+}`
+
+// DecisionSchema is DecisionSchemaJSON parsed for validation
+var DecisionSchema = MustParseSchema(DecisionSchemaJSON)
+
+// Decision is a structured AI decision response (see DecisionSchemaJSON)
+type Decision struct {
+	RiskScore       float64  `json:"risk_score"`
+	Confidence      float64  `json:"confidence"`
+	Recommendations []string `json:"recommendations"`
+	Reasoning       []string `json:"reasoning"`
+}
+
+// Schema is the subset of JSON Schema used to validate AI responses: type,
+// required, properties, items, enum, minimum, maximum, minLength and minItems
+type Schema struct {
+	Type       string             `json:"type,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Enum       []interface{}      `json:"enum,omitempty"`
+	Minimum    *float64           `json:"minimum,omitempty"`
+	Maximum    *float64           `json:"maximum,omitempty"`
+	MinLength  *int               `json:"minLength,omitempty"`
+	MinItems   *int               `json:"minItems,omitempty"`
+}
+
+// ParseSchema parses a JSON Schema document
+func ParseSchema(document string) (*Schema, error) {
+	var schema Schema
+	if err := json.Unmarshal([]byte(document), &schema); err != nil {
+		return nil, fmt.Errorf("invalid JSON schema: %w", err)
+	}
+	return &schema, nil
+}
+
+// MustParseSchema is ParseSchema for package-level schemas; it panics on error
+func MustParseSchema(document string) *Schema {
+	schema, err := ParseSchema(document)
+	if err != nil {
+		panic(err)
+	}
+	return schema
+}
+
+// Validate checks a decoded JSON value against the schema and returns every
+// violation, each prefixed with the JSON path of the offending value
+func (s *Schema) Validate(value interface{}) []string {
+	var violations []string
+	s.validate("$", value, &violations)
+	return violations
+}
+
+func (s *Schema) validate(path string, value interface{}, violations *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*violations = append(*violations, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if s.Type != "" && !hasJSONType(value, s.Type) {
+		fail("expected %s, got %s", s.Type, jsonTypeOf(value))
+		return
+	}
+
+	if len(s.Enum) > 0 && !inEnum(value, s.Enum) {
+		fail("value %v is not one of %v", value, s.Enum)
+	}
+
+	switch v := value.(type) {
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("%v is below the minimum %v", v, *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("%v is above the maximum %v", v, *s.Maximum)
+		}
+	case string:
+		if s.MinLength != nil && len(v) < *s.MinLength {
+			fail("string is shorter than %d", *s.MinLength)
+		}
+	case []interface{}:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("array has fewer than %d items", *s.MinItems)
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, violations)
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				fail("missing required property %q", name)
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if property, ok := v[name]; ok {
+				s.Properties[name].validate(path+"."+name, property, violations)
+			}
+		}
+	}
+}
+
+// hasJSONType reports whether a value decoded by encoding/json has the named
+// JSON Schema type
+func hasJSONType(value interface{}, want string) bool {
+	switch want {
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == float64(int64(n))
+	default:
+		return jsonTypeOf(value) == want
+	}
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, allowed := range enum {
+		if value == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDecision extracts the JSON object from an AI response, validates it
+// against DecisionSchema and decodes it. Markdown code fences and text around
+// the object are ignored. The error wraps ErrSchemaViolation when the
+// response is not valid JSON or does not match the schema.
+func ParseDecision(content string) (*Decision, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("%w: no JSON object in response", ErrSchemaViolation)
+	}
+	raw := []byte(content[start : end+1])
+
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, fmt.Errorf("%w: invalid JSON: %v", ErrSchemaViolation, err)
+	}
+	if violations := DecisionSchema.Validate(value); len(violations) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSchemaViolation, strings.Join(violations, "; "))
+	}
+
+	var decision Decision
+	if err := json.Unmarshal(raw, &decision); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSchemaViolation, err)
+	}
+	return &decision, nil
+}
+
+// schemaRepairPrompt asks the model to answer the original prompt again,
+// this time matching the schema
+func schemaRepairPrompt(prompt string, violation error) string {
+	return fmt.Sprintf("%s\n\nYour previous response didn't match the schema (%v). "+
+		"Respond with only a JSON object matching this JSON Schema:\n%s", prompt, violation, DecisionSchemaJSON)
+}
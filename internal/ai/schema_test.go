@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseDecision_Valid(t *testing.T) {
+	content := "```json\n" + `{"risk_score": 3.5, "confidence": 0.8, "recommendations": ["Downsize to t3.small"], "reasoning": ["CPU below 10%"]}` + "\n```"
+
+	decision, err := ParseDecision(content)
+	if err != nil {
+		t.Fatalf("Expected a valid decision, got %v", err)
+	}
+	if decision.RiskScore != 3.5 || decision.Confidence != 0.8 {
+		t.Errorf("Unexpected decision %+v", decision)
+	}
+	if len(decision.Recommendations) != 1 || decision.Recommendations[0] != "Downsize to t3.small" {
+		t.Errorf("Unexpected recommendations %v", decision.Recommendations)
+	}
+}
+
+func TestParseDecision_Violations(t *testing.T) {
+	cases := map[string]struct {
+		content string
+		want    string
+	}{
+		"not json":             {"- Downsize the instance", "no JSON object"},
+		"risk score string":    {`{"risk_score": "low", "confidence": 0.8, "recommendations": [], "reasoning": []}`, "$.risk_score: expected number, got string"},
+		"missing confidence":   {`{"risk_score": 2, "recommendations": [], "reasoning": []}`, `missing required property "confidence"`},
+		"confidence too high":  {`{"risk_score": 2, "confidence": 80, "recommendations": [], "reasoning": []}`, "above the maximum"},
+		"empty recommendation": {`{"risk_score": 2, "confidence": 0.5, "recommendations": [""], "reasoning": []}`, "$.recommendations[0]"},
+	}
+
+	for name, tc := range cases {
+		_, err := ParseDecision(tc.content)
+		if !errors.Is(err, ErrSchemaViolation) {
+			t.Errorf("%s: expected ErrSchemaViolation, got %v", name, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: expected error to mention %q, got %v", name, tc.want, err)
+		}
+	}
+}
+
+func TestSchema_EnumAndInteger(t *testing.T) {
+	schema := MustParseSchema(`{"type": "object", "properties": {
+		"action": {"enum": ["stop", "resize"]},
+		"count": {"type": "integer", "minimum": 1}
+	}}`)
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(`{"action": "delete", "count": 1.5}`), &value); err != nil {
+		t.Fatal(err)
+	}
+	violations := schema.Validate(value)
+	if len(violations) != 2 {
+		t.Fatalf("Expected 2 violations, got %v", violations)
+	}
+	if !strings.HasPrefix(violations[0], "$.action") || !strings.HasPrefix(violations[1], "$.count") {
+		t.Errorf("Unexpected violations %v", violations)
+	}
+}
+
+func TestMockClient_SatisfiesDecisionSchema(t *testing.T) {
+	for _, cpu := range []float64{0.05, 0.3, 0.6, 0.95} {
+		content, err := json.Marshal(NewMockClient(1).analyze(AIRequest{
+			RiskScore: 4,
+			Metadata:  map[string]interface{}{"cpu_usage": cpu, "memory_usage": 0.2},
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := ParseDecision(string(content)); err != nil {
+			t.Errorf("Mock analysis at %.0f%% CPU violates the schema: %v", cpu*100, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// StreamChunk is one piece of a streamed response. Every chunk but the last
+// carries a text Delta. The last carries either Final, the response's usage
+// and model details, or Err if the stream failed. The response content is
+// the concatenation of the deltas, so Final.Content is left empty.
+type StreamChunk struct {
+	Delta string
+	Final *AIResponse
+	Err   error
+}
+
+// DrainStream reads a stream to the end and assembles the complete response
+func DrainStream(stream <-chan StreamChunk) (*AIResponse, error) {
+	var content strings.Builder
+	var final *AIResponse
+	for chunk := range stream {
+		switch {
+		case chunk.Err != nil:
+			return nil, chunk.Err
+		case chunk.Final != nil:
+			final = chunk.Final
+		default:
+			content.WriteString(chunk.Delta)
+		}
+	}
+	if final == nil {
+		return nil, fmt.Errorf("stream ended without a final chunk")
+	}
+
+	response := *final
+	response.Content = content.String()
+	return &response, nil
+}
+
+// StreamAnalyze streams the response of a client that can't stream
+// natively: the whole content arrives as one delta once analyze returns
+func StreamAnalyze(ctx context.Context, request AIRequest, analyze func(context.Context, AIRequest) (*AIResponse, error)) <-chan StreamChunk {
+	// Buffered for the delta and the final chunk, so the goroutine finishes
+	// even if nobody reads the stream
+	stream := make(chan StreamChunk, 2)
+	go func() {
+		defer close(stream)
+		response, err := analyze(ctx, request)
+		if err != nil {
+			stream <- StreamChunk{Err: err}
+			return
+		}
+		streamResponse(stream, response)
+	}()
+	return stream
+}
+
+// streamResponse sends a complete response as a delta and a final chunk.
+// stream must have room for both.
+func streamResponse(stream chan<- StreamChunk, response *AIResponse) {
+	final := *response
+	final.Content = ""
+	stream <- StreamChunk{Delta: response.Content}
+	stream <- StreamChunk{Final: &final}
+}
+
+// sendChunk sends chunk unless ctx is done first, reporting whether it was
+// sent. Streaming goroutines use it so that they don't block forever on a
+// reader that has given up.
+func sendChunk(ctx context.Context, stream chan<- StreamChunk, chunk StreamChunk) bool {
+	select {
+	case stream <- chunk:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
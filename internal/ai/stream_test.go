@@ -0,0 +1,208 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Xover-Official/Xover/internal/analytics"
+	"github.com/Xover-Official/Xover/internal/cloud"
+	talerrors "github.com/Xover-Official/Xover/internal/errors"
+)
+
+// streamingClient streams its deltas one by one, then reports tokens used
+type streamingClient struct {
+	fakeClient
+	deltas []string
+	tokens int
+	// failAfter fails the stream with err once that many deltas were sent
+	failAfter int
+}
+
+func (c *streamingClient) AnalyzeStream(ctx context.Context, request AIRequest) (<-chan StreamChunk, error) {
+	c.calls++
+	stream := make(chan StreamChunk)
+	go func() {
+		defer close(stream)
+		for i, delta := range c.deltas {
+			if c.err != nil && i == c.failAfter {
+				sendChunk(ctx, stream, StreamChunk{Err: c.err})
+				return
+			}
+			if !sendChunk(ctx, stream, StreamChunk{Delta: delta}) {
+				return
+			}
+		}
+		if c.err != nil {
+			sendChunk(ctx, stream, StreamChunk{Err: c.err})
+			return
+		}
+		sendChunk(ctx, stream, StreamChunk{Final: &AIResponse{Model: c.model, TokensUsed: c.tokens, CostUSD: 0.01}})
+	}()
+	return stream, nil
+}
+
+func (c *streamingClient) Analyze(ctx context.Context, request AIRequest) (*AIResponse, error) {
+	stream, err := c.AnalyzeStream(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return DrainStream(stream)
+}
+
+func TestAnalyzeStream_OrderedDeliveryAndUsage(t *testing.T) {
+	tracker := analytics.NewTokenTracker(filepath.Join(t.TempDir(), "tokens.json"))
+	defer tracker.Close()
+
+	client := &streamingClient{
+		fakeClient: fakeClient{model: "gemini-flash"},
+		deltas:     []string{"Down", "size ", "to ", "t3.small"},
+		tokens:     120,
+	}
+	orchestrator := fallbackOrchestrator(t, DefaultFallbackChain, nil)
+	orchestrator.factory.SetClient("sentinel", client)
+	orchestrator.tokenTracker = tracker
+
+	stream, err := orchestrator.AnalyzeStream(context.Background(), "analyze", 1.0, &cloud.ResourceV2{ID: "i-1", Type: "ec2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var deltas []string
+	var final *AIResponse
+	for chunk := range stream {
+		switch {
+		case chunk.Err != nil:
+			t.Fatalf("unexpected stream error: %v", chunk.Err)
+		case chunk.Final != nil:
+			final = chunk.Final
+		default:
+			if final != nil {
+				t.Fatal("delta after the final chunk")
+			}
+			deltas = append(deltas, chunk.Delta)
+		}
+	}
+
+	if strings.Join(deltas, "|") != "Down|size |to |t3.small" {
+		t.Errorf("deltas out of order: %q", deltas)
+	}
+	if final == nil || final.TokensUsed != 120 || final.Model != "gemini-flash" || final.Metadata["tier"] != "sentinel" {
+		t.Fatalf("unexpected final chunk %+v", final)
+	}
+
+	// Usage is recorded once the stream ends
+	snapshot := tracker.GetSnapshot()
+	if snapshot.TotalTokens != 120 || snapshot.ModelBreakdown["gemini-flash"].Requests != 1 {
+		t.Errorf("unexpected token accounting %+v", snapshot)
+	}
+}
+
+func TestAnalyzeStream_DrainsToAnalyze(t *testing.T) {
+	orchestrator := fallbackOrchestrator(t, DefaultFallbackChain, nil)
+	resource := &cloud.ResourceV2{ID: "idle", Type: "ec2", CPUUsage: 0.05, MemoryUsage: 0.1}
+
+	stream, err := orchestrator.AnalyzeStream(context.Background(), "analyze", 2.0, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	streamed, err := DrainStream(stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	direct, err := orchestrator.Analyze(context.Background(), "analyze", 2.0, resource)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if streamed.Content == "" || streamed.Content != direct.Content || streamed.Confidence != direct.Confidence {
+		t.Errorf("streamed response %+v differs from %+v", streamed, direct)
+	}
+}
+
+func TestAnalyzeStream_FallsBackBeforeFirstDelta(t *testing.T) {
+	unavailable := talerrors.NewAIServiceError("claude", "Anthropic", fmt.Errorf("status: 529"))
+	arbiter := &streamingClient{fakeClient: fakeClient{model: "claude", err: unavailable}, deltas: []string{"never"}}
+	reasoning := &streamingClient{fakeClient: fakeClient{model: "gpt5-mini"}, deltas: []string{"ok"}, tokens: 7}
+	orchestrator := fallbackOrchestrator(t, DefaultFallbackChain, nil)
+	orchestrator.factory.SetClient("arbiter", arbiter)
+	orchestrator.factory.SetClient("reasoning", reasoning)
+
+	stream, err := orchestrator.AnalyzeStream(context.Background(), "analyze", 6.0, &cloud.ResourceV2{ID: "i-1", Type: "ec2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response, err := DrainStream(stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.Content != "ok" || response.Metadata["fallback_from"] != "arbiter" {
+		t.Errorf("unexpected response %+v", response)
+	}
+
+	// Once text was sent, a failure ends the stream instead
+	arbiter.failAfter = 1
+	arbiter.deltas = []string{"partial", "never"}
+	stream, err = orchestrator.AnalyzeStream(context.Background(), "analyze", 6.0, &cloud.ResourceV2{ID: "i-1", Type: "ec2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := DrainStream(stream); !isServiceUnavailable(err) {
+		t.Errorf("expected the arbiter's error, got %v", err)
+	}
+	if reasoning.calls != 1 {
+		t.Errorf("expected no second fallback, reasoning called %d times", reasoning.calls)
+	}
+}
+
+func TestOpenRouterClient_AnalyzeStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), `"stream":true`) {
+			t.Errorf("expected a streaming request, got %s", body)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, ": OPENROUTER PROCESSING\n\n")
+		io.WriteString(w, `data: {"model":"openai/gpt-4o-mini","choices":[{"delta":{"content":"Hello"}}]}`+"\n\n")
+		io.WriteString(w, `data: {"model":"openai/gpt-4o-mini","choices":[{"delta":{"content":", world"}}]}`+"\n\n")
+		io.WriteString(w, `data: {"model":"openai/gpt-4o-mini","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`+"\n\n")
+		io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	client := NewOpenRouterClient("key")
+	client.endpoint = server.URL
+	stream, err := newOpenRouterTier(client, "openai/gpt-4o-mini", 4).AnalyzeStream(context.Background(), AIRequest{Prompt: "hi", MaxTokens: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	response, err := DrainStream(stream)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.Content != "Hello, world" || response.TokensUsed != 15 || response.CostUSD <= 0 {
+		t.Errorf("unexpected response %+v", response)
+	}
+}
+
+func TestDrainStream_Errors(t *testing.T) {
+	stream := make(chan StreamChunk, 2)
+	stream <- StreamChunk{Delta: "partial"}
+	stream <- StreamChunk{Err: fmt.Errorf("connection reset")}
+	close(stream)
+	if _, err := DrainStream(stream); err == nil || err.Error() != "connection reset" {
+		t.Errorf("expected the stream error, got %v", err)
+	}
+
+	truncated := make(chan StreamChunk, 1)
+	truncated <- StreamChunk{Delta: "partial"}
+	close(truncated)
+	if _, err := DrainStream(truncated); err == nil {
+		t.Error("expected an error for a stream without a final chunk")
+	}
+}
@@ -27,9 +27,12 @@ func NewTOPAZOrchestrator(config *Config, tracker *analytics.TokenTracker, l *za
 		return nil, fmt.Errorf("failed to create base orchestrator: %w", err)
 	}
 
+	roses := NewROSESFramework()
+	roses.SetPromptBudget(config.MaxPromptTokens, l)
+
 	return &TOPAZOrchestrator{
 		UnifiedOrchestrator: baseOrchestrator,
-		rosesFramework:      NewROSESFramework(),
+		rosesFramework:      roses,
 		topazLogic:          NewTOPAZLogic(),
 	}, nil
 }
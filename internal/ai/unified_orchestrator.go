@@ -2,11 +2,17 @@ package ai
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/Xover-Official/Xover/internal/analytics"
+	"github.com/Xover-Official/Xover/internal/cache"
 	"github.com/Xover-Official/Xover/internal/cloud"
+	talerrors "github.com/Xover-Official/Xover/internal/errors"
+	"github.com/Xover-Official/Xover/internal/metrics"
 	"go.uber.org/zap"
 )
 
@@ -24,6 +30,14 @@ type UnifiedOrchestrator struct {
 	tokenTracker *analytics.TokenTracker
 	cache        AICache
 	logger       *zap.Logger
+	// schemaRetries is how many times AnalyzeDecision re-prompts a model
+	// whose response violates DecisionSchema
+	schemaRetries int
+	// fallbackChain orders the tiers tried when a tier's provider is
+	// unavailable; nil disables fallback
+	fallbackChain []string
+	// maxCostPerCycle is the cost budget of BeginBudgetScope; 0 disables it
+	maxCostPerCycle float64
 }
 
 // NewUnifiedOrchestrator creates a new orchestrator with the given configuration and zap logger
@@ -33,58 +47,259 @@ func NewUnifiedOrchestrator(config *Config, tokenTracker *analytics.TokenTracker
 		return nil, fmt.Errorf("failed to create AI client factory: %w", err)
 	}
 
-	var cache AICache
-	if config.CacheEnabled && config.CacheAddr != "" {
-		cache, err = NewRedisCache(config.CacheAddr, "", 0, time.Hour)
-		if err != nil {
+	fallbackChain := config.FallbackChain
+	if len(fallbackChain) == 0 {
+		fallbackChain = DefaultFallbackChain
+	}
+	for _, tier := range fallbackChain {
+		if !slices.Contains(TierNames, tier) {
+			return nil, fmt.Errorf("unknown tier %q in AI fallback chain", tier)
+		}
+	}
+
+	var aiCache AICache
+	if config.CacheEnabled && config.CacheAddr != "" && !config.Mock {
+		redisCache, err := NewRedisCache(config.CacheAddr, "", 0, time.Hour)
+		switch {
+		case err != nil:
 			logger.Info("Redis cache unavailable", zap.Error(err))
-		} else {
+		case redisCache.Degraded():
+			aiCache = redisCache
+			logger.Warn("Redis unreachable, AI cache degraded until it recovers")
+		default:
+			aiCache = redisCache
 			logger.Info("Redis cache enabled")
 		}
 	}
 
 	return &UnifiedOrchestrator{
-		factory:      factory,
-		tokenTracker: tokenTracker,
-		cache:        cache,
-		logger:       logger,
+		factory:         factory,
+		tokenTracker:    tokenTracker,
+		cache:           aiCache,
+		logger:          logger,
+		schemaRetries:   config.SchemaRetries,
+		fallbackChain:   fallbackChain,
+		maxCostPerCycle: config.MaxCostPerCycleUSD,
 	}, nil
 }
 
 // Analyze routes request to appropriate AI tier based on risk score
 func (o *UnifiedOrchestrator) Analyze(ctx context.Context, prompt string, riskScore float64, resource *cloud.ResourceV2) (*AIResponse, error) {
-	if ctx == nil {
-		return nil, fmt.Errorf("context is required")
+	return o.analyze(ctx, prompt, riskScore, resource, nil)
+}
+
+// AnalyzeDecision is Analyze for prompts that ask for a decision matching
+// DecisionSchema. A response that violates the schema is rejected and the
+// model re-prompted up to the configured number of schema retries. If it
+// still does not comply, the last response is returned with a nil Decision
+// so the caller can fall back to reading it as free text.
+func (o *UnifiedOrchestrator) AnalyzeDecision(ctx context.Context, prompt string, riskScore float64, resource *cloud.ResourceV2) (*AIResponse, *Decision, error) {
+	var decision *Decision
+	validate := func(response *AIResponse) error {
+		parsed, err := ParseDecision(response.Content)
+		if err != nil {
+			return err
+		}
+		decision = parsed
+		return nil
 	}
-	if prompt == "" {
-		return nil, fmt.Errorf("prompt is required")
+
+	current := prompt
+	for attempt := 0; ; attempt++ {
+		response, err := o.analyze(ctx, current, riskScore, resource, validate)
+		if err == nil {
+			metrics.RecordSchemaValidation(response.Model, true)
+			return response, decision, nil
+		}
+		if !errors.Is(err, ErrSchemaViolation) {
+			return nil, nil, err
+		}
+
+		metrics.RecordSchemaValidation(response.Model, false)
+		if attempt >= o.schemaRetries {
+			o.logger.Warn("AI response still violates the decision schema, falling back to free text",
+				zap.String("model", response.Model), zap.Int("attempts", attempt+1), zap.Error(err))
+			return response, nil, nil
+		}
+		o.logger.Info("Re-prompting after schema violation", zap.String("model", response.Model), zap.Error(err))
+		current = schemaRepairPrompt(prompt, err)
 	}
-	if resource == nil {
-		return nil, fmt.Errorf("resource is required")
+}
+
+// analyze runs a prompt through the tier for its risk score. A non-nil
+// validate rejects responses: a rejected response is neither cached nor
+// served from the cache, and is returned together with validate's error.
+func (o *UnifiedOrchestrator) analyze(ctx context.Context, prompt string, riskScore float64, resource *cloud.ResourceV2, validate func(*AIResponse) error) (*AIResponse, error) {
+	if err := checkAnalyzeArgs(ctx, prompt, resource); err != nil {
+		return nil, err
 	}
 
 	// Check cache first
 	if o.cache != nil {
 		cached, err := o.cache.Get(ctx, prompt)
-		if err == nil && cached != nil {
+		if err == nil && cached != nil && (validate == nil || validate(cached.Response) == nil) {
 			o.logger.Info("Cache HIT", zap.String("resource_id", resource.ID))
 			return cached.Response, nil
 		}
 	}
 
-	// Get appropriate client for risk level
-	client := o.factory.GetClientForRisk(riskScore)
+	if err := checkBudget(ctx); err != nil {
+		return nil, err
+	}
+
+	// Analyze with retry logic, on the tier for the risk level
+	response, err := o.analyzeWithFallback(ctx, TierForRisk(riskScore), newAnalyzeRequest(prompt, riskScore, resource))
+	if err != nil {
+		o.logger.Error("AI analysis failed", zap.Error(err))
+		return nil, err
+	}
+	chargeBudget(ctx, response)
+
+	o.recordUsage(response)
+
+	if validate != nil {
+		if err := validate(response); err != nil {
+			return response, err
+		}
+	}
+
+	o.cacheResponse(ctx, prompt, response)
+	return response, nil
+}
+
+// AnalyzeStream is Analyze with the response streamed as the model generates
+// it. The tier's usage is recorded and the response cached once the stream
+// ends; a cached response is streamed as a single delta. A tier whose AI
+// service is unavailable before it sends any text falls back like Analyze.
+func (o *UnifiedOrchestrator) AnalyzeStream(ctx context.Context, prompt string, riskScore float64, resource *cloud.ResourceV2) (<-chan StreamChunk, error) {
+	if err := checkAnalyzeArgs(ctx, prompt, resource); err != nil {
+		return nil, err
+	}
+
+	if o.cache != nil {
+		cached, err := o.cache.Get(ctx, prompt)
+		if err == nil && cached != nil {
+			o.logger.Info("Cache HIT", zap.String("resource_id", resource.ID))
+			stream := make(chan StreamChunk, 2)
+			streamResponse(stream, cached.Response)
+			close(stream)
+			return stream, nil
+		}
+	}
+
+	if err := checkBudget(ctx); err != nil {
+		return nil, err
+	}
+
+	request := newAnalyzeRequest(prompt, riskScore, resource)
+	stream := make(chan StreamChunk)
+	go func() {
+		defer close(stream)
+
+		response, err := o.streamWithFallback(ctx, TierForRisk(riskScore), request, stream)
+		if err != nil {
+			o.logger.Error("AI analysis failed", zap.Error(err))
+			sendChunk(ctx, stream, StreamChunk{Err: err})
+			return
+		}
+		chargeBudget(ctx, response)
+
+		o.recordUsage(response)
+		o.cacheResponse(ctx, prompt, response)
+
+		final := *response
+		final.Content = ""
+		sendChunk(ctx, stream, StreamChunk{Final: &final})
+	}()
+	return stream, nil
+}
+
+// streamWithFallback streams request from tier, forwarding its deltas to out,
+// and returns the complete response. It falls back along the fallback chain
+// like analyzeWithFallback, as long as the failing tier sent no text.
+func (o *UnifiedOrchestrator) streamWithFallback(ctx context.Context, tier string, request AIRequest, out chan<- StreamChunk) (*AIResponse, error) {
+	tiers := o.tiersFrom(tier)
 
-	o.logger.Info("Routing to AI client", zap.Float64("risk_score", riskScore), zap.String("client_type", fmt.Sprintf("%T", client)))
+	var err error
+	for i, name := range tiers {
+		client := o.factory.GetClientByName(name)
+		o.logger.Info("Streaming from AI client", zap.Float64("risk_score", request.RiskScore),
+			zap.String("tier", name), zap.String("client_type", fmt.Sprintf("%T", client)))
 
+		var response *AIResponse
+		var streamed bool
+		start := time.Now()
+		response, streamed, err = forwardStream(ctx, client, request, out)
+		metrics.RecordAIModelCall(ctx, client.GetModel(), analysisType(request), time.Since(start), err)
+		if err == nil {
+			attributeTier(response, name, tier)
+			return response, nil
+		}
+		if streamed || !isServiceUnavailable(err) || i == len(tiers)-1 {
+			break
+		}
+		o.logger.Warn("AI tier unavailable, falling back", zap.String("tier", name),
+			zap.String("fallback", tiers[i+1]), zap.Error(err))
+	}
+	return nil, err
+}
+
+// forwardStream streams request from client, forwarding its deltas to out.
+// It returns the complete response, or the stream's error and whether any
+// text had been forwarded before it failed.
+func forwardStream(ctx context.Context, client AIClient, request AIRequest, out chan<- StreamChunk) (*AIResponse, bool, error) {
+	in, err := client.AnalyzeStream(ctx, request)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var content strings.Builder
+	var final *AIResponse
+	for chunk := range in {
+		switch {
+		case chunk.Err != nil:
+			return nil, content.Len() > 0, chunk.Err
+		case chunk.Final != nil:
+			final = chunk.Final
+		default:
+			if !sendChunk(ctx, out, chunk) {
+				return nil, true, ctx.Err()
+			}
+			content.WriteString(chunk.Delta)
+		}
+	}
+	if final == nil {
+		return nil, content.Len() > 0, fmt.Errorf("%s stream ended without a final chunk", client.GetModel())
+	}
+
+	response := *final
+	response.Content = content.String()
+	return &response, false, nil
+}
+
+// checkAnalyzeArgs validates the arguments of Analyze and AnalyzeStream
+func checkAnalyzeArgs(ctx context.Context, prompt string, resource *cloud.ResourceV2) error {
+	if ctx == nil {
+		return fmt.Errorf("context is required")
+	}
+	if prompt == "" {
+		return fmt.Errorf("prompt is required")
+	}
+	if resource == nil {
+		return fmt.Errorf("resource is required")
+	}
+	return nil
+}
+
+// newAnalyzeRequest builds the request for analyzing a resource
+func newAnalyzeRequest(prompt string, riskScore float64, resource *cloud.ResourceV2) AIRequest {
 	// Dynamic token allocation based on risk tier
 	maxTokens := 1000
 	if riskScore >= 7.0 {
 		maxTokens = 4000 // High-risk tiers (Arbiter/Reasoning/Oracle) require more context
 	}
 
-	// Create request
-	request := AIRequest{
+	return AIRequest{
 		Prompt:       prompt,
 		ResourceType: resource.Type,
 		RiskScore:    riskScore,
@@ -99,30 +314,82 @@ func (o *UnifiedOrchestrator) Analyze(ctx context.Context, prompt string, riskSc
 			"cost_per_month": resource.CostPerMonth,
 		},
 	}
+}
 
-	// Analyze with retry logic
-	response, err := o.AnalyzeWithRetry(ctx, client, request, 3)
-	if err != nil {
-		o.logger.Error("AI analysis failed", zap.Error(err))
-		return nil, err
+// cacheResponse caches the response to prompt
+func (o *UnifiedOrchestrator) cacheResponse(ctx context.Context, prompt string, response *AIResponse) {
+	if o.cache == nil {
+		return
 	}
-
-	// Track usage
-	if o.tokenTracker != nil {
-		o.tokenTracker.RecordUsage(response.Model, response.TokensUsed)
+	if err := o.cache.Set(ctx, prompt, response); err != nil && !errors.Is(err, cache.ErrCircuitOpen) {
+		o.logger.Warn("Failed to cache response", zap.Error(err))
 	}
+}
 
-	// Cache the response
-	if o.cache != nil {
-		if err := o.cache.Set(ctx, prompt, response); err != nil {
-			o.logger.Warn("Failed to cache response", zap.Error(err))
+// analyzeWithFallback sends request to tier. While the tier's AI service is
+// unavailable, the request moves on to the tiers after it in the fallback
+// chain. The response metadata records the tier that answered.
+func (o *UnifiedOrchestrator) analyzeWithFallback(ctx context.Context, tier string, request AIRequest) (*AIResponse, error) {
+	tiers := o.tiersFrom(tier)
+
+	var err error
+	for i, name := range tiers {
+		client := o.factory.GetClientByName(name)
+		o.logger.Info("Routing to AI client", zap.Float64("risk_score", request.RiskScore),
+			zap.String("tier", name), zap.String("client_type", fmt.Sprintf("%T", client)))
+
+		var response *AIResponse
+		response, err = o.AnalyzeWithRetry(ctx, client, request, 3)
+		if err == nil {
+			attributeTier(response, name, tier)
+			return response, nil
 		}
+		if !isServiceUnavailable(err) || i == len(tiers)-1 {
+			break
+		}
+		o.logger.Warn("AI tier unavailable, falling back", zap.String("tier", name),
+			zap.String("fallback", tiers[i+1]), zap.Error(err))
 	}
+	return nil, err
+}
 
-	return response, nil
+// tiersFrom returns tier followed by the tiers it falls back to
+func (o *UnifiedOrchestrator) tiersFrom(tier string) []string {
+	tiers := []string{tier}
+	if i := slices.Index(o.fallbackChain, tier); i >= 0 {
+		tiers = append(tiers, o.fallbackChain[i+1:]...)
+	}
+	return tiers
+}
+
+// recordUsage tracks the response's tokens against the tier that answered
+func (o *UnifiedOrchestrator) recordUsage(response *AIResponse) {
+	if o.tokenTracker == nil {
+		return
+	}
+	tier, _ := response.Metadata["tier"].(string)
+	o.tokenTracker.RecordUsage(tier, response.Model, response.TokensUsed)
+}
+
+// attributeTier records in the response metadata the tier that answered and,
+// if it differs, the tier the request was meant for
+func attributeTier(response *AIResponse, tier, requested string) {
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]interface{})
+	}
+	response.Metadata["tier"] = tier
+	if tier != requested {
+		response.Metadata["fallback_from"] = requested
+	}
+}
+
+// isServiceUnavailable reports whether err is an AI_SERVICE_UNAVAILABLE error
+func isServiceUnavailable(err error) bool {
+	var talosErr *talerrors.TalosError
+	return errors.As(err, &talosErr) && talosErr.Code == talerrors.ErrAIServiceUnavailable
 }
 
-// analyzeWithRetry implements retry logic for AI calls
+// AnalyzeWithRetry implements retry logic for AI calls
 func (o *UnifiedOrchestrator) AnalyzeWithRetry(ctx context.Context, client AIClient, request AIRequest, maxRetries int) (*AIResponse, error) {
 	var lastErr error
 
@@ -140,7 +407,9 @@ func (o *UnifiedOrchestrator) AnalyzeWithRetry(ctx context.Context, client AICli
 			}
 		}
 
+		start := time.Now()
 		response, err := client.Analyze(ctx, request)
+		metrics.RecordAIModelCall(ctx, client.GetModel(), analysisType(request), time.Since(start), err)
 		if err == nil {
 			return response, nil
 		}
@@ -148,6 +417,12 @@ func (o *UnifiedOrchestrator) AnalyzeWithRetry(ctx context.Context, client AICli
 		lastErr = err
 		o.logger.Warn("AI analysis attempt failed", zap.Int("attempt", attempt), zap.Error(err))
 
+		// The clients already retry their provider, so an unavailable
+		// service is left to the fallback chain
+		if isServiceUnavailable(err) {
+			return nil, fmt.Errorf("AI analysis failed: %w", err)
+		}
+
 		// Fail fast if context is cancelled
 		if ctx.Err() != nil {
 			return nil, fmt.Errorf("context cancelled during analysis: %w", ctx.Err())
@@ -157,6 +432,18 @@ func (o *UnifiedOrchestrator) AnalyzeWithRetry(ctx context.Context, client AICli
 	return nil, fmt.Errorf("AI analysis failed after %d attempts: %w", maxRetries, lastErr)
 }
 
+// analysisType labels model metrics: an explicit "analysis_type" in the
+// request metadata, otherwise the resource type
+func analysisType(request AIRequest) string {
+	if t, ok := request.Metadata["analysis_type"].(string); ok && t != "" {
+		return t
+	}
+	if request.ResourceType != "" {
+		return request.ResourceType
+	}
+	return "unknown"
+}
+
 // GetFactory returns the underlying AI client factory for advanced usage
 func (o *UnifiedOrchestrator) GetFactory() *AIClientFactory {
 	return o.factory
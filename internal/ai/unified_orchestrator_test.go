@@ -0,0 +1,189 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	talerrors "github.com/Xover-Official/Xover/internal/errors"
+	"github.com/Xover-Official/Xover/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+)
+
+// fakeClient answers every request with its model name, or fails with err
+type fakeClient struct {
+	model string
+	tier  int
+	err   error
+	cost  float64
+	calls int
+}
+
+func (c *fakeClient) Analyze(ctx context.Context, request AIRequest) (*AIResponse, error) {
+	c.calls++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &AIResponse{Content: "answer from " + c.model, Model: c.model, TokensUsed: 42, CostUSD: c.cost}, nil
+}
+
+func (c *fakeClient) AnalyzeStream(ctx context.Context, request AIRequest) (<-chan StreamChunk, error) {
+	return StreamAnalyze(ctx, request, c.Analyze), nil
+}
+
+func (c *fakeClient) GetEstimatedCost(AIRequest) float64    { return 0 }
+func (c *fakeClient) GetModel() string                      { return c.model }
+func (c *fakeClient) GetTier() int                          { return c.tier }
+func (c *fakeClient) HealthCheck(ctx context.Context) error { return c.err }
+
+func fallbackOrchestrator(t *testing.T, chain []string, clients map[string]*fakeClient) *UnifiedOrchestrator {
+	t.Helper()
+	factory, err := NewAIClientFactory(&Config{Mock: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, client := range clients {
+		factory.SetClient(name, client)
+	}
+	return &UnifiedOrchestrator{factory: factory, logger: zap.NewNop(), fallbackChain: chain}
+}
+
+func TestAnalyze_FallsBackWhenTierUnavailable(t *testing.T) {
+	strategist := &fakeClient{model: "gemini-pro", tier: 2, err: talerrors.NewAIServiceError("gemini-pro", "Gemini", fmt.Errorf("status: 503"))}
+	arbiter := &fakeClient{model: "claude", tier: 3}
+	reasoning := &fakeClient{model: "gpt5-mini", tier: 4}
+	orchestrator := fallbackOrchestrator(t, DefaultFallbackChain, map[string]*fakeClient{
+		"strategist": strategist, "arbiter": arbiter, "reasoning": reasoning,
+	})
+
+	response, err := orchestrator.Analyze(context.Background(), "analyze", 4.0, &cloud.ResourceV2{ID: "i-1", Type: "ec2"})
+	if err != nil {
+		t.Fatalf("expected the arbiter to answer, got %v", err)
+	}
+	if response.Content != "answer from claude" || response.Model != "claude" {
+		t.Errorf("unexpected response %+v", response)
+	}
+	if response.Metadata["tier"] != "arbiter" || response.Metadata["fallback_from"] != "strategist" {
+		t.Errorf("fallback not attributed: %v", response.Metadata)
+	}
+	// An unavailable tier isn't retried, and the chain stops at the first answer
+	if strategist.calls != 1 || arbiter.calls != 1 || reasoning.calls != 0 {
+		t.Errorf("unexpected calls: strategist %d, arbiter %d, reasoning %d", strategist.calls, arbiter.calls, reasoning.calls)
+	}
+}
+
+func TestAnalyze_FallbackChainExhausted(t *testing.T) {
+	unavailable := func(model string) *fakeClient {
+		return &fakeClient{model: model, err: talerrors.NewAIServiceError(model, "OpenRouter", fmt.Errorf("max retries reached"))}
+	}
+	arbiter, reasoning := unavailable("claude"), unavailable("gpt5-mini")
+	orchestrator := fallbackOrchestrator(t, []string{"arbiter", "reasoning"}, map[string]*fakeClient{
+		"arbiter": arbiter, "reasoning": reasoning,
+	})
+
+	_, err := orchestrator.Analyze(context.Background(), "analyze", 6.0, &cloud.ResourceV2{ID: "i-1", Type: "ec2"})
+	if !isServiceUnavailable(err) {
+		t.Fatalf("expected the last tier's unavailable error, got %v", err)
+	}
+	if arbiter.calls != 1 || reasoning.calls != 1 {
+		t.Errorf("expected each tier to be tried once, got arbiter %d, reasoning %d", arbiter.calls, reasoning.calls)
+	}
+}
+
+func TestAnalyze_NoFallbackForOtherErrors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sentinel := &fakeClient{model: "gemini-flash", err: errors.New("bad request")}
+	strategist := &fakeClient{model: "gemini-pro"}
+	orchestrator := fallbackOrchestrator(t, DefaultFallbackChain, map[string]*fakeClient{
+		"sentinel": sentinel, "strategist": strategist,
+	})
+
+	_, err := orchestrator.Analyze(ctx, "analyze", 1.0, &cloud.ResourceV2{ID: "i-1", Type: "ec2"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if strategist.calls != 0 {
+		t.Errorf("expected no fallback, strategist called %d times", strategist.calls)
+	}
+}
+
+func TestAnalyze_ServedByRequestedTier(t *testing.T) {
+	orchestrator := fallbackOrchestrator(t, DefaultFallbackChain, nil)
+
+	response, err := orchestrator.Analyze(context.Background(), "analyze", 9.5, &cloud.ResourceV2{ID: "i-1", Type: "ec2"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.Metadata["tier"] != "oracle" {
+		t.Errorf("expected the oracle tier, got %v", response.Metadata["tier"])
+	}
+	if _, ok := response.Metadata["fallback_from"]; ok {
+		t.Errorf("unexpected fallback: %v", response.Metadata)
+	}
+}
+
+func TestNewUnifiedOrchestrator_RejectsUnknownFallbackTier(t *testing.T) {
+	_, err := NewUnifiedOrchestrator(&Config{Mock: true, FallbackChain: []string{"arbiter", "gpt-9"}}, nil, zap.NewNop())
+	if err == nil {
+		t.Fatal("expected an unknown tier to be rejected")
+	}
+}
+
+func TestAnalysisType(t *testing.T) {
+	tests := []struct {
+		name    string
+		request AIRequest
+		want    string
+	}{
+		{"metadata wins", AIRequest{ResourceType: "ec2", Metadata: map[string]interface{}{"analysis_type": "rightsizing"}}, "rightsizing"},
+		{"empty metadata value", AIRequest{ResourceType: "ec2", Metadata: map[string]interface{}{"analysis_type": ""}}, "ec2"},
+		{"non-string metadata value", AIRequest{ResourceType: "rds", Metadata: map[string]interface{}{"analysis_type": 7}}, "rds"},
+		{"resource type", AIRequest{ResourceType: "ebs"}, "ebs"},
+		{"neither", AIRequest{}, "unknown"},
+	}
+	for _, tt := range tests {
+		if got := analysisType(tt.request); got != tt.want {
+			t.Errorf("%s: analysisType() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzeWithRetry_RecordsModelCalls(t *testing.T) {
+	calls := func(model, outcome string) float64 {
+		return testutil.ToFloat64(metrics.AIModelRequestsTotal.WithLabelValues(model, "retry-test", outcome))
+	}
+	request := AIRequest{Metadata: map[string]interface{}{"analysis_type": "retry-test"}}
+	orchestrator := &UnifiedOrchestrator{logger: zap.NewNop()}
+
+	healthy := &fakeClient{model: "retry-healthy"}
+	if _, err := orchestrator.AnalyzeWithRetry(context.Background(), healthy, request, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := calls("retry-healthy", "success"); got != 1 {
+		t.Errorf("expected 1 successful call recorded, got %v", got)
+	}
+
+	failing := &fakeClient{model: "retry-failing", err: errors.New("invalid response")}
+	if _, err := orchestrator.AnalyzeWithRetry(context.Background(), failing, request, 0); err == nil {
+		t.Fatal("expected the failure to be returned")
+	}
+	if got := calls("retry-failing", "error"); got != 1 {
+		t.Errorf("expected 1 failed call recorded, got %v", got)
+	}
+
+	// An unavailable service isn't retried, but the failed call still counts
+	unavailable := &fakeClient{model: "retry-unavailable", err: talerrors.NewAIServiceError("retry-unavailable", "OpenRouter", fmt.Errorf("status: 503"))}
+	if _, err := orchestrator.AnalyzeWithRetry(context.Background(), unavailable, request, 3); err == nil {
+		t.Fatal("expected the failure to be returned")
+	}
+	if unavailable.calls != 1 {
+		t.Errorf("expected no retries, got %d calls", unavailable.calls)
+	}
+	if got, ok := calls("retry-unavailable", "error"), calls("retry-unavailable", "success"); got != 1 || ok != 0 {
+		t.Errorf("expected 1 failed and no successful calls recorded, got %v and %v", got, ok)
+	}
+}
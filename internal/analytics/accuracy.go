@@ -0,0 +1,90 @@
+package analytics
+
+import (
+	"math"
+	"sort"
+	"strconv"
+)
+
+// SavingsSample is one executed optimization's estimated and realized savings
+type SavingsSample struct {
+	Estimated float64
+	Actual    float64
+}
+
+// RatioBucket counts samples whose actual/estimated ratio is at most LE and
+// above the previous bucket's bound, like a Prometheus histogram bucket
+type RatioBucket struct {
+	LE    string `json:"le"` // "+Inf" for the last bucket
+	Count int    `json:"count"`
+}
+
+// SavingsAccuracy summarizes how realized savings compare to estimates. A
+// ratio of 1 means the estimate was exact; below 1 it was optimistic.
+type SavingsAccuracy struct {
+	Samples   int                `json:"samples"`
+	Skipped   int                `json:"skipped"` // samples without a positive estimate
+	MeanRatio float64            `json:"mean_ratio"`
+	Quantiles map[string]float64 `json:"quantiles"` // "p10" to "p90" of the ratio
+	MAPE      float64            `json:"mape"`      // mean absolute percentage error, in percent
+	Histogram []RatioBucket      `json:"histogram"`
+}
+
+// accuracyQuantiles are the ratio quantiles reported in SavingsAccuracy
+var accuracyQuantiles = []struct {
+	name string
+	q    float64
+}{{"p10", 0.1}, {"p25", 0.25}, {"p50", 0.5}, {"p75", 0.75}, {"p90", 0.9}}
+
+// RatioBucketBounds are the upper bounds of the accuracy histogram buckets
+var RatioBucketBounds = []float64{0.25, 0.5, 0.75, 0.9, 1.1, 1.25, 1.5, 2}
+
+// ComputeSavingsAccuracy computes the distribution of actual/estimated savings
+// ratios and the mean absolute percentage error of the estimates. Samples
+// without a positive estimate have no meaningful ratio and are skipped.
+func ComputeSavingsAccuracy(samples []SavingsSample) SavingsAccuracy {
+	result := SavingsAccuracy{
+		Quantiles: make(map[string]float64, len(accuracyQuantiles)),
+		Histogram: make([]RatioBucket, len(RatioBucketBounds)+1),
+	}
+	for i, bound := range RatioBucketBounds {
+		result.Histogram[i].LE = strconv.FormatFloat(bound, 'g', -1, 64)
+	}
+	result.Histogram[len(RatioBucketBounds)].LE = "+Inf"
+
+	ratios := make([]float64, 0, len(samples))
+	var ratioSum, errorSum float64
+	for _, s := range samples {
+		if s.Estimated <= 0 {
+			result.Skipped++
+			continue
+		}
+		ratio := s.Actual / s.Estimated
+		ratios = append(ratios, ratio)
+		ratioSum += ratio
+		errorSum += math.Abs(s.Actual-s.Estimated) / s.Estimated
+
+		result.Histogram[sort.SearchFloat64s(RatioBucketBounds, ratio)].Count++
+	}
+
+	result.Samples = len(ratios)
+	if result.Samples == 0 {
+		return result
+	}
+
+	sort.Float64s(ratios)
+	result.MeanRatio = ratioSum / float64(result.Samples)
+	result.MAPE = errorSum / float64(result.Samples) * 100
+	for _, q := range accuracyQuantiles {
+		result.Quantiles[q.name] = quantile(ratios, q.q)
+	}
+	return result
+}
+
+// quantile returns the q-th quantile of sorted values by linear interpolation
+func quantile(sorted []float64, q float64) float64 {
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
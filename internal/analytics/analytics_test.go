@@ -1,6 +1,8 @@
 package analytics
 
 import (
+	"math"
+	"sync"
 	"testing"
 )
 
@@ -8,7 +10,7 @@ func TestTokenTracker_RecordUsage(t *testing.T) {
 	tracker := NewTokenTracker("")
 
 	// Test recording usage
-	tracker.RecordUsage("gemini-2.0-flash-exp", 1000)
+	tracker.RecordUsage("sentinel", "gemini-2.0-flash-exp", 1000)
 
 	if tracker.TotalTokens != 1000 {
 		t.Errorf("Expected 1000 tokens, got %d", tracker.TotalTokens)
@@ -23,7 +25,7 @@ func TestTokenTracker_ROI(t *testing.T) {
 	tracker := NewTokenTracker("")
 
 	// Record usage and savings
-	tracker.RecordUsage("gemini-2.0-flash-exp", 1000)
+	tracker.RecordUsage("sentinel", "gemini-2.0-flash-exp", 1000)
 	tracker.RecordSavings(100.0)
 
 	roi := tracker.GetROI()
@@ -32,6 +34,152 @@ func TestTokenTracker_ROI(t *testing.T) {
 	}
 }
 
+func TestTokenTracker_SnapshotIsConsistent(t *testing.T) {
+	tracker := NewTokenTracker("")
+
+	// Each update adds equal cost and savings, so a consistent snapshot
+	// always has a net profit of zero
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				tracker.TrackAI("strategist", "gemini-1.5-pro", 10, 1.0, 1.0)
+			}
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	for {
+		snap := tracker.GetSnapshot()
+		if snap.TotalCostUSD != snap.TotalSavingsUSD {
+			t.Fatalf("torn snapshot: cost %.2f, savings %.2f", snap.TotalCostUSD, snap.TotalSavingsUSD)
+		}
+		if snap.ModelBreakdown["gemini-1.5-pro"].CostUSD != snap.TotalCostUSD {
+			t.Fatalf("breakdown cost %.2f does not match total %.2f", snap.ModelBreakdown["gemini-1.5-pro"].CostUSD, snap.TotalCostUSD)
+		}
+		select {
+		case <-done:
+			final := tracker.GetSnapshot()
+			if final.TotalTokens != 20000 {
+				t.Errorf("Expected 20000 tokens, got %d", final.TotalTokens)
+			}
+			return
+		default:
+		}
+	}
+}
+
+func TestTokenTracker_SnapshotCopiesBreakdown(t *testing.T) {
+	tracker := NewTokenTracker("")
+	tracker.RecordUsage("sentinel", "gemini-1.5-pro", 1000)
+
+	snap := tracker.GetSnapshot()
+	snap.ModelBreakdown["gemini-1.5-pro"] = TokenUsage{}
+
+	if tracker.GetSnapshot().ModelBreakdown["gemini-1.5-pro"].Tokens != 1000 {
+		t.Error("Expected snapshot breakdown to be a copy")
+	}
+}
+
+func TestTokenTracker_GetBreakdownByTier(t *testing.T) {
+	tracker := NewTokenTracker("")
+	tracker.TrackAI("sentinel", "gemini-2.0-flash-exp", 1000, 0.10, 0)
+	tracker.TrackAI("sentinel", "gemini-2.0-flash-exp", 500, 0.05, 0)
+	tracker.TrackAI("strategist", "gemini-1.5-pro", 2000, 1.00, 0)
+	tracker.TrackAI("arbiter", "gemini-1.5-pro", 3000, 2.00, 0)
+	tracker.TrackAI("", "devin", 100, 0.50, 0)
+
+	breakdown := tracker.GetBreakdown()
+	want := map[string]TierStats{
+		"sentinel":   {Tokens: 1500, CostUSD: 0.15, Requests: 2},
+		"strategist": {Tokens: 2000, CostUSD: 1.00, Requests: 1},
+		"arbiter":    {Tokens: 3000, CostUSD: 2.00, Requests: 1},
+		UnknownTier:  {Tokens: 100, CostUSD: 0.50, Requests: 1},
+	}
+	if len(breakdown) != len(want) {
+		t.Fatalf("Expected %d tiers, got %+v", len(want), breakdown)
+	}
+	for tier, w := range want {
+		g := breakdown[tier]
+		if g.Tokens != w.Tokens || g.Requests != w.Requests || math.Abs(g.CostUSD-w.CostUSD) > 1e-9 {
+			t.Errorf("%s: expected %+v, got %+v", tier, w, g)
+		}
+	}
+
+	// The model breakdown still sums across tiers
+	if pro := tracker.GetSnapshot().ModelBreakdown["gemini-1.5-pro"]; pro.Tokens != 5000 || pro.Requests != 2 {
+		t.Errorf("Expected gemini-1.5-pro summed over both tiers, got %+v", pro)
+	}
+
+	breakdown["sentinel"] = TierStats{}
+	if tracker.GetBreakdown()["sentinel"].Tokens != 1500 {
+		t.Error("Expected GetBreakdown to return a copy")
+	}
+}
+
+func TestTokenTracker_BudgetAlertFiresOnce(t *testing.T) {
+	tracker := NewTokenTracker("")
+
+	var mu sync.Mutex
+	var fired []float64
+	tracker.SetBudgetAlert(10.0, func(current float64) {
+		// The callback may read the tracker without deadlocking
+		_ = tracker.GetSnapshot()
+		mu.Lock()
+		fired = append(fired, current)
+		mu.Unlock()
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				tracker.TrackAI("strategist", "gemini-1.5-pro", 10, 0.5, 0)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(fired) != 1 {
+		t.Fatalf("Expected the budget alert to fire once, fired %d times", len(fired))
+	}
+	if fired[0] < 10.0 || fired[0] > 10.5 {
+		t.Errorf("Expected the alert at the crossing, got $%.2f", fired[0])
+	}
+
+	tracker.RecordUsage("sentinel", "devin", 1)
+	if len(fired) != 1 {
+		t.Errorf("Expected no further alerts in the same period, got %d", len(fired))
+	}
+}
+
+func TestTokenTracker_ResetBudgetPeriodRearms(t *testing.T) {
+	tracker := NewTokenTracker("")
+	var fired []float64
+	tracker.SetBudgetAlert(5.0, func(current float64) { fired = append(fired, current) })
+
+	tracker.TrackAI("strategist", "gemini-1.5-pro", 10, 6.0, 0)
+	tracker.ResetBudgetPeriod()
+	tracker.TrackAI("strategist", "gemini-1.5-pro", 10, 3.0, 0)
+	if len(fired) != 1 {
+		t.Fatalf("Expected spend before the reset not to count, fired %d times", len(fired))
+	}
+
+	tracker.TrackAI("strategist", "gemini-1.5-pro", 10, 3.0, 0)
+	if len(fired) != 2 || fired[1] != 6.0 {
+		t.Errorf("Expected a second alert at $6.00 of period spend, got %v", fired)
+	}
+}
+
 func TestForecaster_PredictCost(t *testing.T) {
 	forecaster := NewForecaster()
 
@@ -83,3 +231,41 @@ func TestForecaster_GetTrend(t *testing.T) {
 		t.Errorf("Expected increasing trend, got %s", trend)
 	}
 }
+
+func TestComputeSavingsAccuracy(t *testing.T) {
+	acc := ComputeSavingsAccuracy([]SavingsSample{
+		{Estimated: 100, Actual: 100},
+		{Estimated: 100, Actual: 50},
+		{Estimated: 200, Actual: 300},
+		{Estimated: 0, Actual: 10},
+	})
+
+	if acc.Samples != 3 || acc.Skipped != 1 {
+		t.Fatalf("Expected 3 samples and 1 skipped, got %d and %d", acc.Samples, acc.Skipped)
+	}
+	if math.Abs(acc.MeanRatio-1.0) > 1e-9 {
+		t.Errorf("Expected mean ratio 1.0, got %f", acc.MeanRatio)
+	}
+	// Errors are 0%, 50% and 50%
+	if math.Abs(acc.MAPE-100.0/3) > 1e-9 {
+		t.Errorf("Expected MAPE 33.33, got %f", acc.MAPE)
+	}
+	if acc.Quantiles["p50"] != 1.0 {
+		t.Errorf("Expected median ratio 1.0, got %f", acc.Quantiles["p50"])
+	}
+
+	counts := map[string]int{}
+	for _, bucket := range acc.Histogram {
+		counts[bucket.LE] = bucket.Count
+	}
+	if counts["0.5"] != 1 || counts["1.1"] != 1 || counts["1.5"] != 1 {
+		t.Errorf("Unexpected histogram %+v", acc.Histogram)
+	}
+}
+
+func TestComputeSavingsAccuracy_Empty(t *testing.T) {
+	acc := ComputeSavingsAccuracy(nil)
+	if acc.Samples != 0 || acc.MAPE != 0 {
+		t.Errorf("Expected an empty summary, got %+v", acc)
+	}
+}
@@ -0,0 +1,216 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/database"
+)
+
+// tokenStoreTimeout bounds each call a TokenStore makes to a database
+const tokenStoreTimeout = 10 * time.Second
+
+// TokenState is the part of a TokenTracker that a TokenStore persists. Its
+// JSON form is the tracker's original tokens.json layout.
+type TokenState struct {
+	TotalTokens     int                   `json:"total_tokens"`
+	TotalCostUSD    float64               `json:"total_cost_usd"`
+	TotalSavingsUSD float64               `json:"total_savings_usd"`
+	NetROI          float64               `json:"net_roi"`
+	ModelBreakdown  map[string]TokenUsage `json:"model_breakdown"`
+	TierBreakdown   map[string]TokenUsage `json:"tier_breakdown,omitempty"`
+	Usage           []LabeledUsage        `json:"usage,omitempty"` // by tier and model
+	StartTime       time.Time             `json:"start_time"`
+}
+
+// TokenStore persists a TokenTracker's totals
+type TokenStore interface {
+	// Load returns the stored totals, or nil if nothing has been stored yet
+	Load() (*TokenState, error)
+	// Save stores the tracker's current totals
+	Save(state *TokenState) error
+}
+
+// FileTokenStore keeps the totals in a JSON file. It suits a single process;
+// replicas each writing their own file do not see each other's usage.
+type FileTokenStore struct {
+	path string
+}
+
+// NewFileTokenStore creates a store for the JSON file at path
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// Load reads the file; a missing file loads as nothing stored
+func (s *FileTokenStore) Load() (*TokenState, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", s.path, err)
+	}
+
+	var state TokenState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	return &state, nil
+}
+
+// Save overwrites the file with state
+func (s *FileTokenStore) Save(state *TokenState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal token tracker data: %w", err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// TokenUsageRepository is the part of the database repository the Postgres store uses
+type TokenUsageRepository interface {
+	RecordTokenUsageBatch(ctx context.Context, usages []*database.TokenUsage) error
+	GetTokenUsageTotals(ctx context.Context) ([]*database.TokenUsageTotal, error)
+}
+
+// trackerRequestType marks token_usage rows written by a TokenTracker
+const trackerRequestType = "tracker"
+
+// unattributedModel holds savings recorded without a model (RecordSavings)
+const unattributedModel = "unattributed"
+
+// PostgresTokenStore keeps the totals in the token_usage table. Save appends
+// a row per tier and model for the usage since the previous Save, so every
+// replica can write to the table and Load sums all of them.
+type PostgresTokenStore struct {
+	repo TokenUsageRepository
+
+	mu           sync.Mutex
+	savedUsage   map[UsageLabel]TokenUsage // the usage already written by this store
+	savedSavings float64
+}
+
+// NewPostgresTokenStore creates a store writing through repo
+func NewPostgresTokenStore(repo TokenUsageRepository) *PostgresTokenStore {
+	return &PostgresTokenStore{repo: repo, savedUsage: make(map[UsageLabel]TokenUsage)}
+}
+
+// Load sums token_usage per model
+func (s *PostgresTokenStore) Load() (*TokenState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), tokenStoreTimeout)
+	defer cancel()
+
+	totals, err := s.repo.GetTokenUsageTotals(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(totals) == 0 {
+		return nil, nil
+	}
+
+	state := &TokenState{ModelBreakdown: make(map[string]TokenUsage), TierBreakdown: make(map[string]TokenUsage)}
+	saved := make(map[UsageLabel]TokenUsage)
+	for _, total := range totals {
+		state.TotalTokens += total.Tokens
+		state.TotalCostUSD += total.CostUSD
+		state.TotalSavingsUSD += total.SavingsUSD
+		if state.StartTime.IsZero() || total.FirstUsedAt.Before(state.StartTime) {
+			state.StartTime = total.FirstUsedAt
+		}
+		if total.Model == unattributedModel {
+			continue
+		}
+
+		label := UsageLabel{Tier: total.Tier, Model: total.Model}
+		if label.Tier == "" {
+			label.Tier = UnknownTier // rows written before usage was tiered
+		}
+		usage := TokenUsage{Tokens: total.Tokens, CostUSD: total.CostUSD, Requests: total.Requests}
+		saved[label] = saved[label].merge(usage)
+		state.ModelBreakdown[label.Model] = state.ModelBreakdown[label.Model].merge(usage)
+		state.TierBreakdown[label.Tier] = state.TierBreakdown[label.Tier].merge(usage)
+	}
+	for label, usage := range saved {
+		state.Usage = append(state.Usage, LabeledUsage{UsageLabel: label, TokenUsage: usage})
+	}
+
+	s.mu.Lock()
+	s.savedUsage, s.savedSavings = saved, state.TotalSavingsUSD
+	s.mu.Unlock()
+	return state, nil
+}
+
+// Save writes the usage recorded since the last Load or Save
+func (s *PostgresTokenStore) Save(state *TokenState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	requestType := trackerRequestType
+	usage := make([]LabeledUsage, len(state.Usage))
+	copy(usage, state.Usage)
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Tier != usage[j].Tier {
+			return usage[i].Tier < usage[j].Tier
+		}
+		return usage[i].Model < usage[j].Model
+	})
+
+	var rows []*database.TokenUsage
+	for _, u := range usage {
+		prev := s.savedUsage[u.UsageLabel]
+		if u.TokenUsage == prev {
+			continue
+		}
+		rows = append(rows, &database.TokenUsage{
+			Model:       u.Model,
+			Tier:        u.Tier,
+			Tokens:      u.Tokens - prev.Tokens,
+			CostUSD:     u.CostUSD - prev.CostUSD,
+			Requests:    u.Requests - prev.Requests,
+			RequestType: &requestType,
+		})
+	}
+	if savings := state.TotalSavingsUSD - s.savedSavings; savings != 0 {
+		rows = append(rows, &database.TokenUsage{Model: unattributedModel, SavingsUSD: savings, RequestType: &requestType})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tokenStoreTimeout)
+	defer cancel()
+	if err := s.repo.RecordTokenUsageBatch(ctx, rows); err != nil {
+		return err
+	}
+	for _, u := range usage {
+		s.savedUsage[u.UsageLabel] = u.TokenUsage
+	}
+	s.savedSavings = state.TotalSavingsUSD
+	return nil
+}
+
+func copyTokenState(state *TokenState) TokenState {
+	copied := *state
+	copied.ModelBreakdown = copyBreakdown(state.ModelBreakdown)
+	copied.TierBreakdown = copyBreakdown(state.TierBreakdown)
+	copied.Usage = append([]LabeledUsage(nil), state.Usage...)
+	return copied
+}
+
+func copyBreakdown(breakdown map[string]TokenUsage) map[string]TokenUsage {
+	copied := make(map[string]TokenUsage, len(breakdown))
+	for key, usage := range breakdown {
+		copied[key] = usage
+	}
+	return copied
+}
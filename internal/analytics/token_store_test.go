@@ -0,0 +1,193 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/database"
+)
+
+// memoryTokenRepository is an in-memory token_usage table
+type memoryTokenRepository struct {
+	rows []database.TokenUsage
+	fail bool
+}
+
+func (r *memoryTokenRepository) RecordTokenUsageBatch(ctx context.Context, usages []*database.TokenUsage) error {
+	if r.fail {
+		return errors.New("database unavailable")
+	}
+	for _, usage := range usages {
+		row := *usage
+		row.CreatedAt = time.Now()
+		r.rows = append(r.rows, row)
+	}
+	return nil
+}
+
+func (r *memoryTokenRepository) GetTokenUsageTotals(ctx context.Context) ([]*database.TokenUsageTotal, error) {
+	type key struct{ model, tier string }
+	byKey := make(map[key]*database.TokenUsageTotal)
+	var totals []*database.TokenUsageTotal
+	for _, row := range r.rows {
+		total, ok := byKey[key{row.Model, row.Tier}]
+		if !ok {
+			total = &database.TokenUsageTotal{Model: row.Model, Tier: row.Tier, FirstUsedAt: row.CreatedAt}
+			byKey[key{row.Model, row.Tier}] = total
+			totals = append(totals, total)
+		}
+		total.Tokens += row.Tokens
+		total.CostUSD += row.CostUSD
+		total.Requests += row.Requests
+		total.SavingsUSD += row.SavingsUSD
+	}
+	return totals, nil
+}
+
+func assertStatsEqual(t *testing.T, want, got map[string]interface{}) {
+	t.Helper()
+	for _, key := range []string{"total_tokens", "total_cost_usd", "total_savings_usd", "net_roi"} {
+		w, g := want[key], got[key]
+		if wf, ok := w.(float64); ok {
+			if math.Abs(wf-g.(float64)) > 1e-9 {
+				t.Errorf("%s: expected %v, got %v", key, w, g)
+			}
+			continue
+		}
+		if w != g {
+			t.Errorf("%s: expected %v, got %v", key, w, g)
+		}
+	}
+	wantModels := want["model_breakdown"].(map[string]TokenUsage)
+	gotModels := got["model_breakdown"].(map[string]TokenUsage)
+	if len(wantModels) != len(gotModels) {
+		t.Fatalf("model_breakdown: expected %v, got %v", wantModels, gotModels)
+	}
+	for model, usage := range wantModels {
+		if g := gotModels[model]; g.Tokens != usage.Tokens || g.Requests != usage.Requests || math.Abs(g.CostUSD-usage.CostUSD) > 1e-9 {
+			t.Errorf("model_breakdown[%s]: expected %+v, got %+v", model, usage, g)
+		}
+	}
+	wantTiers := want["tier_breakdown"].(map[string]TierStats)
+	gotTiers := got["tier_breakdown"].(map[string]TierStats)
+	if len(wantTiers) != len(gotTiers) {
+		t.Fatalf("tier_breakdown: expected %v, got %v", wantTiers, gotTiers)
+	}
+	for tier, usage := range wantTiers {
+		if g := gotTiers[tier]; g.Tokens != usage.Tokens || g.Requests != usage.Requests || math.Abs(g.CostUSD-usage.CostUSD) > 1e-9 {
+			t.Errorf("tier_breakdown[%s]: expected %+v, got %+v", tier, usage, g)
+		}
+	}
+}
+
+func TestFileTokenStore_StatsSurviveReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+
+	tracker := NewTokenTracker(path)
+	tracker.RecordUsage("sentinel", "gemini-1.5-pro", 4000)
+	tracker.TrackAI("strategist", "anthropic/claude-3.5-sonnet", 1000, 0.25, 40)
+	tracker.Close()
+
+	reloaded := NewTokenTracker(path)
+	assertStatsEqual(t, tracker.GetStats(), reloaded.GetStats())
+}
+
+func TestFileTokenStore_UntieredFileLoadsAsUnknown(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	legacy := `{"total_tokens": 500, "total_cost_usd": 0.5, "model_breakdown": {"gemini-1.5-pro": {"tokens": 500, "cost_usd": 0.5, "requests": 1}}}`
+	if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := NewTokenTracker(path)
+	if usage := tracker.GetBreakdown()[UnknownTier]; usage.Tokens != 500 || usage.Requests != 1 {
+		t.Errorf("Expected the untiered usage under %q, got %+v", UnknownTier, tracker.GetBreakdown())
+	}
+}
+
+func TestFileTokenStore_MissingFileLoadsNothing(t *testing.T) {
+	state, err := NewFileTokenStore(filepath.Join(t.TempDir(), "missing.json")).Load()
+	if err != nil || state != nil {
+		t.Errorf("Expected nothing stored, got %+v, %v", state, err)
+	}
+}
+
+func TestPostgresTokenStore_StatsSurviveReload(t *testing.T) {
+	repo := &memoryTokenRepository{}
+
+	tracker := NewTokenTrackerWithStore(NewPostgresTokenStore(repo))
+	tracker.RecordUsage("sentinel", "gemini-1.5-pro", 4000)
+	tracker.TrackAI("strategist", "anthropic/claude-3.5-sonnet", 1000, 0.25, 40)
+	tracker.RecordSavings(10)
+	tracker.Close()
+
+	reloaded := NewTokenTrackerWithStore(NewPostgresTokenStore(repo))
+	assertStatsEqual(t, tracker.GetStats(), reloaded.GetStats())
+
+	// Later saves append only what changed since the previous one
+	rows := len(repo.rows)
+	tracker.RecordUsage("sentinel", "gemini-1.5-pro", 1000)
+	tracker.Close()
+	tracker.Close()
+	if len(repo.rows) != rows+1 {
+		t.Fatalf("Expected one new row, got %d", len(repo.rows)-rows)
+	}
+	if row := repo.rows[len(repo.rows)-1]; row.Tokens != 1000 || row.Requests != 1 {
+		t.Errorf("Expected a delta row of 1000 tokens in 1 request, got %+v", row)
+	}
+}
+
+func TestPostgresTokenStore_UntieredRowsLoadAsUnknown(t *testing.T) {
+	repo := &memoryTokenRepository{rows: []database.TokenUsage{
+		{Model: "gemini-1.5-pro", Tokens: 500, CostUSD: 0.5, Requests: 1}, // written before tiers
+	}}
+
+	tracker := NewTokenTrackerWithStore(NewPostgresTokenStore(repo))
+	if usage := tracker.GetBreakdown()[UnknownTier]; usage.Tokens != 500 {
+		t.Fatalf("Expected the untiered row under %q, got %+v", UnknownTier, tracker.GetBreakdown())
+	}
+
+	tracker.RecordUsage("strategist", "gemini-1.5-pro", 1000)
+	tracker.Close()
+	if row := repo.rows[len(repo.rows)-1]; len(repo.rows) != 2 || row.Tier != "strategist" || row.Tokens != 1000 {
+		t.Errorf("Expected one new strategist row, got %+v", repo.rows)
+	}
+}
+
+func TestPostgresTokenStore_ReplicasAggregate(t *testing.T) {
+	repo := &memoryTokenRepository{}
+
+	manager := NewTokenTrackerWithStore(NewPostgresTokenStore(repo))
+	worker := NewTokenTrackerWithStore(NewPostgresTokenStore(repo))
+	manager.TrackAI("strategist", "gemini-1.5-pro", 100, 1.0, 5)
+	worker.TrackAI("strategist", "gemini-1.5-pro", 300, 3.0, 15)
+	manager.Close()
+	worker.Close()
+
+	stats := NewTokenTrackerWithStore(NewPostgresTokenStore(repo)).GetSnapshot()
+	if stats.TotalTokens != 400 || stats.TotalCostUSD != 4.0 || stats.TotalSavingsUSD != 20 {
+		t.Errorf("Expected both replicas' usage, got %+v", stats)
+	}
+	if usage := stats.ModelBreakdown["gemini-1.5-pro"]; usage.Requests != 2 {
+		t.Errorf("Expected 2 requests, got %+v", usage)
+	}
+}
+
+func TestPostgresTokenStore_FailedSaveIsRetried(t *testing.T) {
+	repo := &memoryTokenRepository{fail: true}
+
+	tracker := NewTokenTrackerWithStore(NewPostgresTokenStore(repo))
+	tracker.TrackAI("strategist", "gemini-1.5-pro", 100, 1.0, 0)
+	tracker.Close()
+
+	repo.fail = false
+	tracker.Close()
+	if len(repo.rows) != 1 || repo.rows[0].Tokens != 100 {
+		t.Errorf("Expected the usage written once on retry, got %+v", repo.rows)
+	}
+}
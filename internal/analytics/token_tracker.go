@@ -1,9 +1,7 @@
 package analytics
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
 	"sync"
 	"time"
 
@@ -18,6 +16,41 @@ type TokenUsage struct {
 	Requests int     `json:"requests"`
 }
 
+// add returns the usage with one more request
+func (u TokenUsage) add(tokens int, costUSD float64) TokenUsage {
+	u.Tokens += tokens
+	u.CostUSD += costUSD
+	u.Requests++
+	return u
+}
+
+// merge returns the sum of two usages
+func (u TokenUsage) merge(other TokenUsage) TokenUsage {
+	u.Tokens += other.Tokens
+	u.CostUSD += other.CostUSD
+	u.Requests += other.Requests
+	return u
+}
+
+// TierStats is the usage recorded against one AI tier
+type TierStats TokenUsage
+
+// UnknownTier labels usage recorded without a tier
+const UnknownTier = "unknown"
+
+// UsageLabel is what usage is recorded against: the AI tier that served the
+// request and the model behind it
+type UsageLabel struct {
+	Tier  string `json:"tier"`
+	Model string `json:"model"`
+}
+
+// LabeledUsage is the usage recorded against one label
+type LabeledUsage struct {
+	UsageLabel
+	TokenUsage
+}
+
 // TokenTracker tracks AI token usage and calculates ROI
 type TokenTracker struct {
 	mu              sync.RWMutex
@@ -26,10 +59,19 @@ type TokenTracker struct {
 	TotalSavingsUSD float64               `json:"total_savings_usd"`
 	NetROI          float64               `json:"net_roi"`
 	ModelBreakdown  map[string]TokenUsage `json:"model_breakdown"`
+	TierBreakdown   map[string]TokenUsage `json:"tier_breakdown"`
 	StartTime       time.Time             `json:"start_time"`
-	persistPath     string
+	usage           map[UsageLabel]TokenUsage
+	store           TokenStore // nil keeps the totals in memory only
+	saveMu          sync.Mutex // serialises saves so they reach the store in order
 	stopChan        chan struct{}
 	dirty           bool
+
+	// Budget alert; see SetBudgetAlert
+	budgetUSD       float64
+	budgetAlert     func(current float64)
+	budgetFired     bool
+	budgetPeriodUSD float64 // TotalCostUSD when the budget period began
 }
 
 // Model pricing (per 1M tokens)
@@ -41,17 +83,31 @@ var modelPricing = map[string]float64{
 	"devin":                       10.00, // $10.00 per request (flat fee)
 }
 
-// NewTokenTracker creates a new token tracker
+// NewTokenTracker creates a new token tracker persisted to a JSON file at
+// persistPath, or kept in memory only if persistPath is empty
 func NewTokenTracker(persistPath string) *TokenTracker {
+	if persistPath == "" {
+		return NewTokenTrackerWithStore(nil)
+	}
+	return NewTokenTrackerWithStore(NewFileTokenStore(persistPath))
+}
+
+// NewTokenTrackerWithStore creates a new token tracker that starts from, and
+// periodically saves to, store. A nil store keeps the totals in memory only.
+func NewTokenTrackerWithStore(store TokenStore) *TokenTracker {
 	tracker := &TokenTracker{
 		ModelBreakdown: make(map[string]TokenUsage),
+		TierBreakdown:  make(map[string]TokenUsage),
+		usage:          make(map[UsageLabel]TokenUsage),
 		StartTime:      time.Now(),
-		persistPath:    persistPath,
+		store:          store,
 		stopChan:       make(chan struct{}),
 	}
 
 	// Try to load existing data
-	tracker.Load()
+	if err := tracker.Load(); err != nil {
+		logger.GetLogger().Warn("Failed to load token tracker data", zap.Error(err))
+	}
 
 	// Start persistence loop
 	go tracker.persistLoop()
@@ -66,12 +122,7 @@ func (t *TokenTracker) persistLoop() {
 	for {
 		select {
 		case <-ticker.C:
-			t.mu.Lock()
-			if t.dirty {
-				t.saveInternal()
-				t.dirty = false
-			}
-			t.mu.Unlock()
+			t.save()
 		case <-t.stopChan:
 			t.Close()
 			return
@@ -81,18 +132,51 @@ func (t *TokenTracker) persistLoop() {
 
 // Close stops the tracker and performs a final save
 func (t *TokenTracker) Close() {
+	t.save()
+}
+
+// SetBudgetAlert calls cb once, with the spend so far, when the AI cost of
+// the current budget period reaches thresholdUSD. The period runs from the
+// tracker's start until ResetBudgetPeriod. cb runs outside the tracker's
+// lock, on the goroutine that recorded the usage.
+func (t *TokenTracker) SetBudgetAlert(thresholdUSD float64, cb func(current float64)) {
+	t.mu.Lock()
+	t.budgetUSD = thresholdUSD
+	t.budgetAlert = cb
+	t.budgetFired = false
+	fire := t.budgetCrossed()
+	t.mu.Unlock()
+	fire()
+}
+
+// ResetBudgetPeriod starts a new budget period, re-arming the budget alert
+func (t *TokenTracker) ResetBudgetPeriod() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
-	if t.dirty {
-		t.saveInternal()
-		t.dirty = false
+	t.budgetPeriodUSD = t.TotalCostUSD
+	t.budgetFired = false
+}
+
+// budgetCrossed marks the budget alert fired if this period's spend has
+// reached the threshold and returns the call to make once the lock is
+// released; otherwise it returns a no-op (must be called with lock held)
+func (t *TokenTracker) budgetCrossed() func() {
+	if t.budgetAlert == nil || t.budgetFired {
+		return func() {}
+	}
+	spent := t.TotalCostUSD - t.budgetPeriodUSD
+	if spent < t.budgetUSD {
+		return func() {}
 	}
+	t.budgetFired = true
+	cb := t.budgetAlert
+	return func() { cb(spent) }
 }
 
-// RecordUsage records token usage for a specific model
-func (t *TokenTracker) RecordUsage(model string, tokens int) {
+// RecordUsage records a request's token usage against the tier that served
+// it and its model, pricing the tokens by model
+func (t *TokenTracker) RecordUsage(tier, model string, tokens int) {
 	t.mu.Lock()
-	defer t.mu.Unlock()
 
 	// Calculate cost
 	pricePerMillion, ok := modelPricing[model]
@@ -107,22 +191,30 @@ func (t *TokenTracker) RecordUsage(model string, tokens int) {
 		costUSD = (float64(tokens) / 1_000_000.0) * pricePerMillion
 	}
 
-	// Update totals
-	t.TotalTokens += tokens
-	t.TotalCostUSD += costUSD
-
-	// Update model breakdown
-	usage := t.ModelBreakdown[model]
-	usage.Tokens += tokens
-	usage.CostUSD += costUSD
-	usage.Requests++
-	t.ModelBreakdown[model] = usage
+	t.addUsage(UsageLabel{Tier: tier, Model: model}, tokens, costUSD)
 
 	// Recalculate ROI
 	t.calculateROI()
 
 	// Mark as dirty
 	t.dirty = true
+
+	fire := t.budgetCrossed()
+	t.mu.Unlock()
+	fire()
+}
+
+// addUsage adds a request to the totals and to its model's and tier's
+// breakdowns (must be called with lock held)
+func (t *TokenTracker) addUsage(label UsageLabel, tokens int, costUSD float64) {
+	if label.Tier == "" {
+		label.Tier = UnknownTier
+	}
+	t.TotalTokens += tokens
+	t.TotalCostUSD += costUSD
+	t.ModelBreakdown[label.Model] = t.ModelBreakdown[label.Model].add(tokens, costUSD)
+	t.TierBreakdown[label.Tier] = t.TierBreakdown[label.Tier].add(tokens, costUSD)
+	t.usage[label] = t.usage[label].add(tokens, costUSD)
 }
 
 func (t *TokenTracker) RecordSavings(savingsUSD float64) {
@@ -150,19 +242,72 @@ func (t *TokenTracker) GetROI() float64 {
 	return t.NetROI
 }
 
-// GetStats returns current statistics
-func (t *TokenTracker) GetStats() map[string]interface{} {
+// TokenSnapshot is a point-in-time copy of the tracker's totals. All fields
+// are read under a single lock, so cost, savings and ROI always agree.
+type TokenSnapshot struct {
+	TotalTokens     int                   `json:"total_tokens"`
+	TotalCostUSD    float64               `json:"total_cost_usd"`
+	TotalSavingsUSD float64               `json:"total_savings_usd"`
+	NetROI          float64               `json:"net_roi"`
+	NetProfitUSD    float64               `json:"net_profit_usd"`
+	ModelBreakdown  map[string]TokenUsage `json:"model_breakdown"`
+	TierBreakdown   map[string]TierStats  `json:"tier_breakdown"`
+	UptimeHours     float64               `json:"uptime_hours"`
+}
+
+// GetSnapshot returns a consistent copy of the current statistics. The
+// breakdowns are copied so callers never share the tracker's maps.
+func (t *TokenTracker) GetSnapshot() TokenSnapshot {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
+	breakdown := make(map[string]TokenUsage, len(t.ModelBreakdown))
+	for model, usage := range t.ModelBreakdown {
+		breakdown[model] = usage
+	}
+
+	return TokenSnapshot{
+		TotalTokens:     t.TotalTokens,
+		TotalCostUSD:    t.TotalCostUSD,
+		TotalSavingsUSD: t.TotalSavingsUSD,
+		NetROI:          t.NetROI,
+		NetProfitUSD:    t.TotalSavingsUSD - t.TotalCostUSD,
+		ModelBreakdown:  breakdown,
+		TierBreakdown:   t.tierBreakdownLocked(),
+		UptimeHours:     time.Since(t.StartTime).Hours(),
+	}
+}
+
+// GetBreakdown returns the usage recorded against each AI tier
+func (t *TokenTracker) GetBreakdown() map[string]TierStats {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tierBreakdownLocked()
+}
+
+// tierBreakdownLocked copies the tier breakdown (lock assumed held)
+func (t *TokenTracker) tierBreakdownLocked() map[string]TierStats {
+	breakdown := make(map[string]TierStats, len(t.TierBreakdown))
+	for tier, usage := range t.TierBreakdown {
+		breakdown[tier] = TierStats(usage)
+	}
+	return breakdown
+}
+
+// GetStats returns current statistics as a map, built from a single snapshot.
+// Prefer GetSnapshot for typed access.
+func (t *TokenTracker) GetStats() map[string]interface{} {
+	snap := t.GetSnapshot()
+
 	return map[string]interface{}{
-		"total_tokens":      t.TotalTokens,
-		"total_cost_usd":    t.TotalCostUSD,
-		"total_savings_usd": t.TotalSavingsUSD,
-		"net_roi":           t.NetROI,
-		"net_profit_usd":    t.TotalSavingsUSD - t.TotalCostUSD,
-		"model_breakdown":   t.ModelBreakdown,
-		"uptime_hours":      time.Since(t.StartTime).Hours(),
+		"total_tokens":      snap.TotalTokens,
+		"total_cost_usd":    snap.TotalCostUSD,
+		"total_savings_usd": snap.TotalSavingsUSD,
+		"net_roi":           snap.NetROI,
+		"net_profit_usd":    snap.NetProfitUSD,
+		"model_breakdown":   snap.ModelBreakdown,
+		"tier_breakdown":    snap.TierBreakdown,
+		"uptime_hours":      snap.UptimeHours,
 	}
 }
 
@@ -196,79 +341,105 @@ func (t *TokenTracker) GenerateReport() string {
 	return report
 }
 
-// saveInternal persists the tracker state to disk (lock assumed held)
-func (t *TokenTracker) saveInternal() {
-	if t.persistPath == "" {
-		return
-	}
+// save writes the totals to the store if they changed since the last save.
+// The store is called outside the tracker's lock so a slow database does not
+// hold up recording usage.
+func (t *TokenTracker) save() {
+	t.saveMu.Lock()
+	defer t.saveMu.Unlock()
 
-	data, err := json.MarshalIndent(t, "", "  ")
-	if err != nil {
-		logger.GetLogger().Error("Failed to marshal token tracker data", zap.Error(err))
+	t.mu.Lock()
+	if t.store == nil || !t.dirty {
+		t.mu.Unlock()
 		return
 	}
-
-	if err := os.WriteFile(t.persistPath, data, 0644); err != nil {
-		logger.GetLogger().Error("Failed to save token tracker data", zap.String("path", t.persistPath), zap.Error(err))
+	state := t.stateLocked()
+	t.dirty = false
+	t.mu.Unlock()
+
+	if err := t.store.Save(state); err != nil {
+		logger.GetLogger().Error("Failed to save token tracker data", zap.Error(err))
+		t.mu.Lock()
+		t.dirty = true
+		t.mu.Unlock()
 	}
 }
 
-// GetBreakdown returns model breakdown statistics
-func (t *TokenTracker) GetBreakdown() map[string]interface{} {
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-
-	breakdown := make(map[string]interface{})
-	for model, usage := range t.ModelBreakdown {
-		breakdown[model] = map[string]interface{}{
-			"tokens":   usage.Tokens,
-			"cost":     usage.CostUSD,
-			"requests": usage.Requests,
-		}
+// stateLocked copies the persisted totals (lock assumed held)
+func (t *TokenTracker) stateLocked() *TokenState {
+	usage := make([]LabeledUsage, 0, len(t.usage))
+	for label, u := range t.usage {
+		usage = append(usage, LabeledUsage{UsageLabel: label, TokenUsage: u})
 	}
-
-	// Add totals
-	breakdown["total_cost"] = t.TotalCostUSD
-	breakdown["projected_savings"] = t.TotalSavingsUSD
-	breakdown["roi"] = t.NetROI
-
-	return breakdown
+	state := copyTokenState(&TokenState{
+		TotalTokens:     t.TotalTokens,
+		TotalCostUSD:    t.TotalCostUSD,
+		TotalSavingsUSD: t.TotalSavingsUSD,
+		NetROI:          t.NetROI,
+		ModelBreakdown:  t.ModelBreakdown,
+		TierBreakdown:   t.TierBreakdown,
+		Usage:           usage,
+		StartTime:       t.StartTime,
+	})
+	return &state
 }
 
 // TrackAI is a convenience method that combines recording usage and savings
-func (t *TokenTracker) TrackAI(model string, tokens int, costUSD, savingsUSD float64) {
+func (t *TokenTracker) TrackAI(tier, model string, tokens int, costUSD, savingsUSD float64) {
 	t.mu.Lock()
-	defer t.mu.Unlock()
 
-	// Update totals
-	t.TotalTokens += tokens
-	t.TotalCostUSD += costUSD
+	t.addUsage(UsageLabel{Tier: tier, Model: model}, tokens, costUSD)
 	t.TotalSavingsUSD += savingsUSD
 
-	// Update model breakdown
-	usage := t.ModelBreakdown[model]
-	usage.Tokens += tokens
-	usage.CostUSD += costUSD
-	usage.Requests++
-	t.ModelBreakdown[model] = usage
-
 	// Recalculate ROI
 	t.calculateROI()
 
 	// Mark as dirty
 	t.dirty = true
+
+	fire := t.budgetCrossed()
+	t.mu.Unlock()
+	fire()
 }
 
-// Load loads the tracker state from disk
+// Load replaces the tracker's totals with the store's, if it has any
 func (t *TokenTracker) Load() error {
-	if t.persistPath == "" {
+	if t.store == nil {
 		return nil
 	}
 
-	data, err := os.ReadFile(t.persistPath)
-	if err != nil {
-		return err // File doesn't exist yet, that's okay
+	state, err := t.store.Load()
+	if err != nil || state == nil {
+		return err
 	}
 
-	return json.Unmarshal(data, t)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.TotalTokens = state.TotalTokens
+	t.TotalCostUSD = state.TotalCostUSD
+	t.TotalSavingsUSD = state.TotalSavingsUSD
+	t.ModelBreakdown = state.ModelBreakdown
+	if t.ModelBreakdown == nil {
+		t.ModelBreakdown = make(map[string]TokenUsage)
+	}
+	t.TierBreakdown = state.TierBreakdown
+	if t.TierBreakdown == nil {
+		t.TierBreakdown = make(map[string]TokenUsage)
+	}
+	t.usage = make(map[UsageLabel]TokenUsage, len(state.Usage))
+	for _, u := range state.Usage {
+		t.usage[u.UsageLabel] = u.TokenUsage
+	}
+	if len(state.Usage) == 0 {
+		// Saved before usage was tiered
+		for model, usage := range t.ModelBreakdown {
+			t.usage[UsageLabel{Tier: UnknownTier, Model: model}] = usage
+			t.TierBreakdown[UnknownTier] = t.TierBreakdown[UnknownTier].merge(usage)
+		}
+	}
+	if !state.StartTime.IsZero() {
+		t.StartTime = state.StartTime
+	}
+	t.calculateROI()
+	return nil
 }
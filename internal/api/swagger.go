@@ -97,7 +97,9 @@ func GetResources(w http.ResponseWriter, r *http.Request) {}
 // @Tags Analytics
 // @Accept json
 // @Produce json
+// @Param window query string false "Window as a Go duration, default 720h"
 // @Success 200 {object} ROIResponse
+// @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Security BearerAuth
 // @Router /roi [get]
@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeIssuer serves a token endpoint that accepts "good-code" and the
+// user info documents in responses, keyed by path
+func fakeIssuer(t *testing.T, responses map[string]interface{}) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/token" {
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("code") != "good-code" ||
+				r.PostForm.Get("client_secret") != "secret" || r.PostForm.Get("grant_type") != "authorization_code" {
+				// GitHub style: 200 with an error field
+				json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access-1", "token_type": "bearer"})
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer access-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func pointAt(p *OAuthProvider, server *httptest.Server, userInfoPath string) *OAuthProvider {
+	p.Endpoints.TokenURL = server.URL + "/token"
+	p.Endpoints.UserInfoURL = server.URL + userInfoPath
+	p.HTTPClient = server.Client()
+	return p
+}
+
+func TestOAuthProvider_AuthCodeURL(t *testing.T) {
+	p := NewGoogleProvider("client-1", "secret")
+	authURL, err := url.Parse(p.AuthCodeURL("state-1", "https://talos.example.com/auth/callback/google"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if authURL.Host != "accounts.google.com" {
+		t.Errorf("expected the Google authorization endpoint, got %s", authURL.Host)
+	}
+	query := authURL.Query()
+	for key, want := range map[string]string{
+		"client_id":     "client-1",
+		"state":         "state-1",
+		"redirect_uri":  "https://talos.example.com/auth/callback/google",
+		"response_type": "code",
+		"scope":         "openid email profile",
+	} {
+		if got := query.Get(key); got != want {
+			t.Errorf("%s: expected %q, got %q", key, want, got)
+		}
+	}
+}
+
+func TestGoogleProvider_ExchangeAndUserInfo(t *testing.T) {
+	server := fakeIssuer(t, map[string]interface{}{
+		"/userinfo": map[string]interface{}{"sub": "1077", "email": "ada@example.com", "email_verified": true, "name": "Ada"},
+	})
+	p := pointAt(NewGoogleProvider("client-1", "secret"), server, "/userinfo")
+
+	token, err := p.Exchange(context.Background(), "good-code", "https://talos.example.com/cb")
+	if err != nil {
+		t.Fatalf("exchange failed: %v", err)
+	}
+	user, err := p.UserInfo(context.Background(), token)
+	if err != nil {
+		t.Fatalf("userinfo failed: %v", err)
+	}
+
+	want := SSOUser{ID: "1077", Email: "ada@example.com", Name: "Ada", Provider: "google"}
+	if user.ID != want.ID || user.Email != want.Email || user.Name != want.Name || user.Provider != want.Provider {
+		t.Errorf("expected %+v, got %+v", want, *user)
+	}
+}
+
+func TestOAuthProvider_ExchangeRejectsBadCode(t *testing.T) {
+	server := fakeIssuer(t, nil)
+	p := pointAt(NewGitHubProvider("client-1", "secret"), server, "/user")
+
+	_, err := p.Exchange(context.Background(), "stolen-code", "https://talos.example.com/cb")
+	if err == nil || !strings.Contains(err.Error(), "bad_verification_code") {
+		t.Errorf("expected the provider's error, got %v", err)
+	}
+}
+
+func TestGoogleProvider_RefusesUnverifiedEmail(t *testing.T) {
+	server := fakeIssuer(t, map[string]interface{}{
+		"/userinfo": map[string]interface{}{"sub": "1077", "email": "ada@example.com", "email_verified": false},
+	})
+	p := pointAt(NewGoogleProvider("client-1", "secret"), server, "/userinfo")
+
+	_, err := p.UserInfo(context.Background(), &OAuthToken{AccessToken: "access-1"})
+	if err == nil || !strings.Contains(err.Error(), "not verified") {
+		t.Errorf("expected an unverified email to be refused, got %v", err)
+	}
+}
+
+func TestGitHubProvider_UsesPrimaryVerifiedEmail(t *testing.T) {
+	server := fakeIssuer(t, map[string]interface{}{
+		"/user": map[string]interface{}{"id": 583231, "login": "octocat", "name": ""},
+		"/user/emails": []map[string]interface{}{
+			{"email": "old@example.com", "primary": false, "verified": true},
+			{"email": "octo@example.com", "primary": true, "verified": true},
+		},
+	})
+	p := pointAt(NewGitHubProvider("client-1", "secret"), server, "/user")
+
+	token, err := p.Exchange(context.Background(), "good-code", "https://talos.example.com/cb")
+	if err != nil {
+		t.Fatalf("exchange failed: %v", err)
+	}
+	user, err := p.UserInfo(context.Background(), token)
+	if err != nil {
+		t.Fatalf("userinfo failed: %v", err)
+	}
+
+	if user.ID != "583231" || user.Email != "octo@example.com" || user.Name != "octocat" || user.Provider != "github" {
+		t.Errorf("unexpected user %+v", *user)
+	}
+}
+
+func TestGitHubProvider_RequiresVerifiedEmail(t *testing.T) {
+	server := fakeIssuer(t, map[string]interface{}{
+		"/user":        map[string]interface{}{"id": 583231, "login": "octocat"},
+		"/user/emails": []map[string]interface{}{{"email": "octo@example.com", "primary": true, "verified": false}},
+	})
+	p := pointAt(NewGitHubProvider("client-1", "secret"), server, "/user")
+
+	if _, err := p.UserInfo(context.Background(), &OAuthToken{AccessToken: "access-1"}); err == nil {
+		t.Error("expected an error without a primary verified email")
+	}
+}
+
+func TestOIDCRegistry(t *testing.T) {
+	registry := NewOIDCRegistry()
+	registry.Register("github", NewGitHubProvider("a", "b"))
+	registry.Register("google", NewGoogleProvider("a", "b"))
+
+	if _, err := registry.Get("google"); err != nil {
+		t.Errorf("expected google to be registered: %v", err)
+	}
+	if _, err := registry.Get("okta"); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("expected ErrUnknownProvider, got %v", err)
+	}
+	if names := registry.Names(); len(names) != 2 || names[0] != "github" || names[1] != "google" {
+		t.Errorf("unexpected names %v", names)
+	}
+}
+
+func TestNewOAuthState_IsRandom(t *testing.T) {
+	a, err := NewOAuthState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewOAuthState()
+	if a == b || len(a) < 40 {
+		t.Errorf("expected two long distinct states, got %q and %q", a, b)
+	}
+}
+
+func TestJWTManager_GeneratePair(t *testing.T) {
+	manager := NewJWTManager("test-secret-key", time.Hour)
+	user := User{ID: "user-123", Email: "test@example.com", Role: RoleViewer}
+
+	pair, err := manager.GeneratePair(user, 7*24*time.Hour)
+	if err != nil {
+		t.Fatalf("Failed to generate pair: %v", err)
+	}
+
+	if _, err := manager.Verify(pair.AccessToken); err != nil {
+		t.Errorf("access token should verify: %v", err)
+	}
+	if _, err := manager.Verify(pair.RefreshToken); err == nil {
+		t.Error("a refresh token must not be accepted as an access token")
+	}
+	claims, err := manager.VerifyRefresh(pair.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh token should verify: %v", err)
+	}
+	if claims.UserID != user.ID {
+		t.Errorf("Expected UserID %s, got %s", user.ID, claims.UserID)
+	}
+	if _, err := manager.VerifyRefresh(pair.AccessToken); err == nil {
+		t.Error("an access token must not be accepted as a refresh token")
+	}
+	if time.Until(pair.RefreshExpiresAt) < 6*24*time.Hour {
+		t.Errorf("unexpected refresh expiry %v", pair.RefreshExpiresAt)
+	}
+}
+
+func TestOAuthStateSigner(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	signer := NewOAuthStateSigner("0123456789abcdef0123456789abcdef", OAuthStateTTL)
+	signer.now = func() time.Time { return now }
+
+	state, cookie, err := signer.Issue("google")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.Verify("google", state, cookie); err != nil {
+		t.Fatalf("expected the issued state to verify: %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		provider, state, cookie string
+	}{
+		"tampered state":   {"google", state + "x", cookie},
+		"empty state":      {"google", "", cookie},
+		"other provider":   {"github", state, cookie},
+		"forged signature": {"google", state, cookie + "x"},
+		"extended expiry":  {"google", state, strings.Replace(cookie, ".", ".9", 1)},
+		"malformed cookie": {"google", state, state},
+	} {
+		if err := signer.Verify(tc.provider, tc.state, tc.cookie); !errors.Is(err, ErrInvalidOAuthState) {
+			t.Errorf("%s: expected ErrInvalidOAuthState, got %v", name, err)
+		}
+	}
+
+	other := NewOAuthStateSigner("another-secret-another-secret-00", OAuthStateTTL)
+	if err := other.Verify("google", state, cookie); !errors.Is(err, ErrInvalidOAuthState) {
+		t.Errorf("a cookie signed with another secret must not verify, got %v", err)
+	}
+
+	now = now.Add(OAuthStateTTL)
+	if err := signer.Verify("google", state, cookie); !errors.Is(err, ErrExpiredOAuthState) {
+		t.Errorf("expected ErrExpiredOAuthState, got %v", err)
+	}
+}
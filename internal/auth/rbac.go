@@ -1,6 +1,9 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"time"
 
@@ -30,6 +33,36 @@ type User struct {
 	Role           Role
 }
 
+// String renders the permission as "resource:action"
+func (p Permission) String() string {
+	return p.Resource + ":" + p.Action
+}
+
+// KnownPermissions lists every permission checked by the API, so clients can
+// be told which of them a role holds
+var KnownPermissions = []Permission{
+	{Resource: "resources", Action: "read"},
+	{Resource: "resources", Action: "write"},
+	{Resource: "resources", Action: "delete"},
+	{Resource: "actions", Action: "read"},
+	{Resource: "actions", Action: "write"},
+	{Resource: "actions", Action: "override_dry_run"},
+	{Resource: "settings", Action: "read"},
+	{Resource: "settings", Action: "write"},
+	{Resource: "users", Action: "admin"},
+}
+
+// Permissions returns the known permissions held by the role
+func (r Role) Permissions() []Permission {
+	var granted []Permission
+	for _, p := range KnownPermissions {
+		if r.HasPermission(p) {
+			granted = append(granted, p)
+		}
+	}
+	return granted
+}
+
 // HasPermission checks if a role has a specific permission
 func (r Role) HasPermission(p Permission) bool {
 	permissions := map[Role][]Permission{
@@ -41,6 +74,7 @@ func (r Role) HasPermission(p Permission) bool {
 			{Resource: "resources", Action: "write"},
 			{Resource: "actions", Action: "read"},
 			{Resource: "actions", Action: "write"},
+			{Resource: "actions", Action: "override_dry_run"},
 			{Resource: "settings", Action: "read"},
 		},
 		RoleViewer: {
@@ -87,24 +121,76 @@ func NewJWTManager(secretKey string, tokenDuration time.Duration) *JWTManager {
 	}
 }
 
+// tokenTypeRefresh marks refresh tokens so they cannot be used for access
+const tokenTypeRefresh = "refresh"
+
 // Claims represents JWT claims
 type Claims struct {
 	UserID         string `json:"user_id"`
 	Email          string `json:"email"`
 	OrganizationID string `json:"org_id"`
 	Role           Role   `json:"role"`
+	TokenType      string `json:"typ,omitempty"` // tokenTypeRefresh for refresh tokens, empty for access tokens
 	jwt.RegisteredClaims
 }
 
+// SessionID is a stable, non-reversible identifier for the token's session,
+// derived from the JWT ID so the ID itself is never exposed. It is empty for
+// tokens issued without an ID.
+func (c *Claims) SessionID() string {
+	if c.ID == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(c.ID))
+	return hex.EncodeToString(sum[:8])
+}
+
 // Generate creates a new JWT token
 func (m *JWTManager) Generate(user User) (string, error) {
+	return m.sign(user, "", m.tokenDuration)
+}
+
+// TokenPair is an access token with the refresh token that renews it
+type TokenPair struct {
+	AccessToken      string
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// GeneratePair creates an access token and a refresh token valid for
+// refreshDuration. Verify rejects the refresh token; use VerifyRefresh.
+func (m *JWTManager) GeneratePair(user User, refreshDuration time.Duration) (*TokenPair, error) {
+	access, err := m.Generate(user)
+	if err != nil {
+		return nil, err
+	}
+	refresh, err := m.sign(user, tokenTypeRefresh, refreshDuration)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:      access,
+		RefreshToken:     refresh,
+		RefreshExpiresAt: time.Now().Add(refreshDuration),
+	}, nil
+}
+
+func (m *JWTManager) sign(user User, tokenType string, duration time.Duration) (string, error) {
+	idBytes := make([]byte, 16)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", err
+	}
+	tokenID := hex.EncodeToString(idBytes)
+
 	claims := Claims{
 		UserID:         user.ID,
 		Email:          user.Email,
 		OrganizationID: user.OrganizationID,
 		Role:           user.Role,
+		TokenType:      tokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(m.tokenDuration)),
+			ID:        tokenID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -113,8 +199,31 @@ func (m *JWTManager) Generate(user User) (string, error) {
 	return token.SignedString([]byte(m.secretKey))
 }
 
-// Verify validates a JWT token and returns the claims
+// Verify validates an access token and returns the claims
 func (m *JWTManager) Verify(tokenString string) (*Claims, error) {
+	claims, err := m.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType == tokenTypeRefresh {
+		return nil, errors.New("refresh token used as access token")
+	}
+	return claims, nil
+}
+
+// VerifyRefresh validates a refresh token issued by GeneratePair
+func (m *JWTManager) VerifyRefresh(tokenString string) (*Claims, error) {
+	claims, err := m.parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if claims.TokenType != tokenTypeRefresh {
+		return nil, errors.New("not a refresh token")
+	}
+	return claims, nil
+}
+
+func (m *JWTManager) parse(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		return []byte(m.secretKey), nil
 	})
@@ -16,6 +16,8 @@ func TestRole_HasPermission(t *testing.T) {
 		{RoleOperator, Permission{Resource: "settings", Action: "write"}, false},
 		{RoleViewer, Permission{Resource: "resources", Action: "read"}, true},
 		{RoleViewer, Permission{Resource: "resources", Action: "write"}, false},
+		{RoleOperator, Permission{Resource: "actions", Action: "override_dry_run"}, true},
+		{RoleViewer, Permission{Resource: "actions", Action: "override_dry_run"}, false},
 	}
 
 	for _, tt := range tests {
@@ -66,3 +68,47 @@ func TestJWTManager_VerifyInvalidToken(t *testing.T) {
 		t.Error("Expected error for invalid token")
 	}
 }
+
+func TestRole_Permissions(t *testing.T) {
+	if got := len(RoleAdmin.Permissions()); got != len(KnownPermissions) {
+		t.Errorf("Expected admin to hold all %d known permissions, got %d", len(KnownPermissions), got)
+	}
+
+	var viewer []string
+	for _, p := range RoleViewer.Permissions() {
+		viewer = append(viewer, p.String())
+	}
+	expected := []string{"resources:read", "actions:read", "settings:read"}
+	if len(viewer) != len(expected) {
+		t.Fatalf("Expected viewer permissions %v, got %v", expected, viewer)
+	}
+	for i := range expected {
+		if viewer[i] != expected[i] {
+			t.Errorf("Expected viewer permissions %v, got %v", expected, viewer)
+		}
+	}
+}
+
+func TestClaims_SessionID(t *testing.T) {
+	manager := NewJWTManager("test-secret-key", time.Hour)
+
+	token, err := manager.Generate(User{ID: "user-123", Role: RoleViewer})
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+	claims, err := manager.Verify(token)
+	if err != nil {
+		t.Fatalf("Failed to verify token: %v", err)
+	}
+
+	sessionID := claims.SessionID()
+	if claims.ID == "" || sessionID == "" {
+		t.Fatal("Expected token to carry an ID and a derived session ID")
+	}
+	if sessionID == claims.ID {
+		t.Error("Session ID must not expose the JWT ID")
+	}
+	if sessionID != claims.SessionID() {
+		t.Error("Expected session ID to be stable")
+	}
+}
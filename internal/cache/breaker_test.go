@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreakerOpensAndProbes(t *testing.T) {
+	now := time.Now()
+	b := NewBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	assert.True(t, b.Allow())
+	b.Failure()
+	assert.Equal(t, BreakerClosed, b.State())
+	assert.True(t, b.Allow())
+	b.Failure()
+	assert.Equal(t, BreakerOpen, b.State())
+	assert.False(t, b.Allow(), "open breaker rejects calls during cooldown")
+
+	// After the cooldown exactly one probe is let through
+	now = now.Add(time.Minute)
+	assert.True(t, b.Allow())
+	assert.Equal(t, BreakerHalfOpen, b.State())
+	assert.False(t, b.Allow())
+
+	// A failed probe restarts the cooldown
+	b.Failure()
+	assert.Equal(t, BreakerOpen, b.State())
+	assert.False(t, b.Allow())
+
+	now = now.Add(time.Minute)
+	assert.True(t, b.Allow())
+	b.Success()
+	assert.Equal(t, BreakerClosed, b.State())
+	assert.True(t, b.Allow())
+}
+
+func TestBreakerTrip(t *testing.T) {
+	b := NewBreaker(5, time.Hour)
+	b.Trip()
+	assert.Equal(t, BreakerOpen, b.State())
+	assert.False(t, b.Allow())
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2026 Project Atlas (Talos)
+// Licensed under the MIT License. See LICENSE in the project root for license information.
+
+// Package chaos injects errors and latency into the cloud adapter, AI clients
+// and repository so the retry, circuit breaker and graceful-degradation paths
+// in internal/errors can be exercised outside production.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/config"
+	"go.uber.org/zap"
+)
+
+// ErrInjectedFault is the root cause of every injected error
+var ErrInjectedFault = errors.New("chaos: injected fault")
+
+// Fault injection targets
+const (
+	TargetCloud      = "cloud"
+	TargetAI         = "ai"
+	TargetRepository = "repository"
+)
+
+// Injector decides, per call, whether to inject latency or an error.
+// A nil *Injector is valid and never injects anything.
+type Injector struct {
+	cfg     config.ChaosConfig
+	targets map[string]bool
+	logger  *zap.Logger
+
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// NewInjector returns an injector for the given configuration, or nil if
+// chaos is disabled. It refuses to run in production mode.
+func NewInjector(cfg config.ChaosConfig, mode string, logger *zap.Logger) (*Injector, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if err := cfg.Validate(mode); err != nil {
+		return nil, err
+	}
+
+	targets := make(map[string]bool)
+	for _, t := range cfg.Targets {
+		targets[t] = true
+	}
+	if len(targets) == 0 {
+		targets = map[string]bool{TargetCloud: true, TargetAI: true, TargetRepository: true}
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	logger.Warn("chaos fault injection enabled",
+		zap.Float64("error_rate", cfg.ErrorRate),
+		zap.Float64("latency_rate", cfg.LatencyRate),
+		zap.Duration("latency", cfg.Latency),
+		zap.Strings("targets", cfg.Targets),
+	)
+
+	return &Injector{
+		cfg:     cfg,
+		targets: targets,
+		logger:  logger,
+		rng:     rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// Inject may delay the call and/or return an error wrapping ErrInjectedFault.
+// It returns ctx.Err() if the context ends while latency is being injected.
+func (i *Injector) Inject(ctx context.Context, target, operation string) error {
+	if i == nil || !i.targets[target] {
+		return nil
+	}
+
+	delay, fail := i.roll()
+	if delay {
+		timer := time.NewTimer(i.cfg.Latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if fail {
+		i.logger.Debug("injecting fault", zap.String("target", target), zap.String("operation", operation))
+		return fmt.Errorf("%s %s: %w", target, operation, ErrInjectedFault)
+	}
+	return nil
+}
+
+func (i *Injector) roll() (delay, fail bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.rng.Float64() < i.cfg.LatencyRate, i.rng.Float64() < i.cfg.ErrorRate
+}
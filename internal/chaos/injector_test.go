@@ -0,0 +1,40 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestNewInjectorRefusesProduction(t *testing.T) {
+	_, err := NewInjector(config.ChaosConfig{Enabled: true, ErrorRate: 1}, "production", zap.NewNop())
+	assert.Error(t, err)
+
+	injector, err := NewInjector(config.ChaosConfig{}, "production", zap.NewNop())
+	require.NoError(t, err)
+	assert.Nil(t, injector)
+}
+
+func TestWrapCloudAdapterInjectsFaults(t *testing.T) {
+	injector, err := NewInjector(config.ChaosConfig{
+		Enabled:   true,
+		ErrorRate: 1,
+		Targets:   []string{TargetCloud},
+		Seed:      1,
+	}, "development", zap.NewNop())
+	require.NoError(t, err)
+
+	adapter := WrapCloudAdapter(cloud.NewSimulator(), injector)
+	_, err = adapter.FetchResources(context.Background())
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInjectedFault))
+
+	// Untargeted components pass through untouched
+	assert.NoError(t, injector.Inject(context.Background(), TargetAI, "Analyze"))
+}
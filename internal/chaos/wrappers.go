@@ -0,0 +1,149 @@
+package chaos
+
+import (
+	"context"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/ai"
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/Xover-Official/Xover/internal/engine"
+	talerrors "github.com/Xover-Official/Xover/internal/errors"
+)
+
+// CloudAdapter injects faults into a cloud.CloudAdapter
+type CloudAdapter struct {
+	cloud.CloudAdapter
+	injector *Injector
+}
+
+// WrapCloudAdapter returns adapter unchanged if injector is nil
+func WrapCloudAdapter(adapter cloud.CloudAdapter, injector *Injector) cloud.CloudAdapter {
+	if injector == nil {
+		return adapter
+	}
+	return &CloudAdapter{CloudAdapter: adapter, injector: injector}
+}
+
+func (a *CloudAdapter) FetchResources(ctx context.Context) ([]*cloud.ResourceV2, error) {
+	if err := a.injector.Inject(ctx, TargetCloud, "FetchResources"); err != nil {
+		return nil, talerrors.NewCloudAPIError("chaos", "FetchResources", err)
+	}
+	return a.CloudAdapter.FetchResources(ctx)
+}
+
+func (a *CloudAdapter) GetResource(ctx context.Context, id string) (*cloud.ResourceV2, error) {
+	if err := a.injector.Inject(ctx, TargetCloud, "GetResource"); err != nil {
+		return nil, talerrors.NewCloudAPIError("chaos", "GetResource", err)
+	}
+	return a.CloudAdapter.GetResource(ctx, id)
+}
+
+func (a *CloudAdapter) GetResources(ctx context.Context, ids []string) (map[string]*cloud.ResourceV2, error) {
+	if err := a.injector.Inject(ctx, TargetCloud, "GetResources"); err != nil {
+		return nil, talerrors.NewCloudAPIError("chaos", "GetResources", err)
+	}
+	return a.CloudAdapter.GetResources(ctx, ids)
+}
+
+func (a *CloudAdapter) ApplyOptimization(ctx context.Context, resource *cloud.ResourceV2, action string) (float64, error) {
+	if err := a.injector.Inject(ctx, TargetCloud, "ApplyOptimization"); err != nil {
+		return 0, talerrors.NewCloudAPIError("chaos", "ApplyOptimization", err)
+	}
+	return a.CloudAdapter.ApplyOptimization(ctx, resource, action)
+}
+
+// AIClient injects faults into an ai.AIClient
+type AIClient struct {
+	ai.AIClient
+	injector *Injector
+}
+
+// WrapAIClient returns client unchanged if injector is nil
+func WrapAIClient(client ai.AIClient, injector *Injector) ai.AIClient {
+	if injector == nil || client == nil {
+		return client
+	}
+	return &AIClient{AIClient: client, injector: injector}
+}
+
+func (c *AIClient) Analyze(ctx context.Context, request ai.AIRequest) (*ai.AIResponse, error) {
+	if err := c.injector.Inject(ctx, TargetAI, "Analyze"); err != nil {
+		return nil, talerrors.NewAIServiceError(c.GetModel(), "chaos", err)
+	}
+	return c.AIClient.Analyze(ctx, request)
+}
+
+func (c *AIClient) AnalyzeStream(ctx context.Context, request ai.AIRequest) (<-chan ai.StreamChunk, error) {
+	if err := c.injector.Inject(ctx, TargetAI, "AnalyzeStream"); err != nil {
+		return nil, talerrors.NewAIServiceError(c.GetModel(), "chaos", err)
+	}
+	return c.AIClient.AnalyzeStream(ctx, request)
+}
+
+// WrapAIFactory wraps every tier's client in the factory
+func WrapAIFactory(factory *ai.AIClientFactory, injector *Injector) {
+	if injector == nil {
+		return
+	}
+	for _, name := range []string{"sentinel", "strategist", "arbiter", "reasoning", "oracle"} {
+		factory.SetClient(name, WrapAIClient(factory.GetClientByName(name), injector))
+	}
+}
+
+// Repository injects faults into the engine's repository
+type Repository struct {
+	engine.Repository
+	injector *Injector
+}
+
+// WrapRepository returns repo unchanged if injector is nil
+func WrapRepository(repo engine.Repository, injector *Injector) engine.Repository {
+	if injector == nil {
+		return repo
+	}
+	return &Repository{Repository: repo, injector: injector}
+}
+
+func (r *Repository) CreateAction(ctx context.Context, orgID string, action *database.Action) error {
+	if err := r.injector.Inject(ctx, TargetRepository, "CreateAction"); err != nil {
+		return databaseError(err)
+	}
+	return r.Repository.CreateAction(ctx, orgID, action)
+}
+
+func (r *Repository) UpdateActionStatus(ctx context.Context, id string, status string, startedAt *time.Time, completedAt *time.Time, errorMsg *string) error {
+	if err := r.injector.Inject(ctx, TargetRepository, "UpdateActionStatus"); err != nil {
+		return databaseError(err)
+	}
+	return r.Repository.UpdateActionStatus(ctx, id, status, startedAt, completedAt, errorMsg)
+}
+
+func (r *Repository) ClaimAction(ctx context.Context, id string, startedAt time.Time) (bool, error) {
+	if err := r.injector.Inject(ctx, TargetRepository, "ClaimAction"); err != nil {
+		return false, databaseError(err)
+	}
+	return r.Repository.ClaimAction(ctx, id, startedAt)
+}
+
+func (r *Repository) CreateSavingsEvent(ctx context.Context, orgID string, event *database.SavingsEvent) error {
+	if err := r.injector.Inject(ctx, TargetRepository, "CreateSavingsEvent"); err != nil {
+		return databaseError(err)
+	}
+	return r.Repository.CreateSavingsEvent(ctx, orgID, event)
+}
+
+func (r *Repository) GetPendingActionsPage(ctx context.Context, orgID string, after *database.ActionCursor, limit int) ([]*database.Action, *database.ActionCursor, error) {
+	if err := r.injector.Inject(ctx, TargetRepository, "GetPendingActionsPage"); err != nil {
+		return nil, nil, databaseError(err)
+	}
+	return r.Repository.GetPendingActionsPage(ctx, orgID, after, limit)
+}
+
+func databaseError(cause error) error {
+	return talerrors.NewErrorBuilder(talerrors.ErrDatabaseError, "Database error").
+		Severity(talerrors.SeverityHigh).
+		Cause(cause).
+		WithRetry(true, time.Second).
+		Build()
+}
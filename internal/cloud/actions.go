@@ -0,0 +1,104 @@
+package cloud
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ActionType is an optimization that ApplyOptimization can perform
+type ActionType string
+
+const (
+	ActionStop         ActionType = "stop"
+	ActionResize       ActionType = "resize"
+	ActionTerminate    ActionType = "terminate"
+	ActionOptimize     ActionType = "optimize"
+	ActionSpotMigrate  ActionType = "spot_migrate"
+	ActionDeleteVolume ActionType = "delete_volume"
+	// ActionDeleteLoadBalancer deletes an idle load balancer
+	ActionDeleteLoadBalancer ActionType = "delete_load_balancer"
+	// ActionReleaseAddress releases an unattached static IP address
+	ActionReleaseAddress ActionType = "release_address"
+)
+
+// ErrInvalidAction is returned when an action is unknown or cannot be applied
+// to a resource's type or current state
+var ErrInvalidAction = errors.New("invalid action")
+
+// supportedActions lists the actions valid for each resource type. RDS and
+// Cloud SQL instances can be stopped and resized but not terminated through
+// the instance path, and only storage volumes can be deleted. Load balancers,
+// static IPs and EBS volumes can only be cleaned up once idle; terminating
+// an Elastic IP or EBS volume releases or deletes it.
+var supportedActions = map[string][]ActionType{
+	ResourceTypeEC2:     {ActionStop, ActionResize, ActionTerminate, ActionOptimize, ActionSpotMigrate},
+	ResourceTypeVM:      {ActionStop, ActionResize, ActionTerminate, ActionOptimize, ActionSpotMigrate},
+	ResourceTypeRDS:     {ActionStop, ActionResize, ActionOptimize},
+	ResourceTypeStorage: {ActionDeleteVolume, ActionOptimize},
+	ResourceTypeNetwork: {ActionOptimize},
+
+	ResourceTypeLoadBalancer: {ActionDeleteLoadBalancer},
+	ResourceTypeElasticIP:    {ActionReleaseAddress, ActionTerminate},
+	ResourceTypeEBS:          {ActionDeleteVolume, ActionTerminate},
+
+	ResourceTypeCloudSQL: {ActionStop, ActionResize, ActionOptimize},
+	// Azure SQL databases cannot be stopped, only scaled
+	ResourceTypeAzureSQL: {ActionResize, ActionOptimize},
+}
+
+// Resource states, across providers, that restrict which actions apply
+var (
+	// goneStates are resources being removed; no action applies to them
+	goneStates = map[string]bool{"terminated": true, "shutting-down": true, "deleting": true, "deleted": true}
+	// stoppedStates are resources that are already stopped or stopping
+	stoppedStates = map[string]bool{"stopped": true, "stopping": true, "deallocated": true}
+)
+
+// ParseActionType returns the ActionType named by action
+func ParseActionType(action string) (ActionType, error) {
+	switch t := ActionType(action); t {
+	case ActionStop, ActionResize, ActionTerminate, ActionOptimize, ActionSpotMigrate, ActionDeleteVolume,
+		ActionDeleteLoadBalancer, ActionReleaseAddress:
+		return t, nil
+	default:
+		return "", fmt.Errorf("%w: unknown action %q", ErrInvalidAction, action)
+	}
+}
+
+// SupportedActions returns the actions valid for a resource type
+func SupportedActions(resourceType string) []ActionType {
+	return supportedActions[resourceType]
+}
+
+// ValidFor reports whether the action applies to a resource type
+func (a ActionType) ValidFor(resourceType string) bool {
+	for _, supported := range supportedActions[resourceType] {
+		if supported == a {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateAction parses action and checks it against the resource's type and
+// state, returning an error wrapping ErrInvalidAction for invalid
+// combinations. Adapters and the engine call it before any change so invalid
+// actions fail up front rather than deep in execution. An unknown state is
+// not held against the action.
+func ValidateAction(resource *ResourceV2, action string) (ActionType, error) {
+	actionType, err := ParseActionType(action)
+	if err != nil {
+		return "", err
+	}
+	if !actionType.ValidFor(resource.Type) {
+		return "", fmt.Errorf("%w: %s is not supported for %q resources (supported: %v)",
+			ErrInvalidAction, actionType, resource.Type, SupportedActions(resource.Type))
+	}
+	if goneStates[resource.State] {
+		return "", fmt.Errorf("%w: cannot %s %s in state %q", ErrInvalidAction, actionType, resource.ID, resource.State)
+	}
+	if actionType == ActionStop && stoppedStates[resource.State] {
+		return "", fmt.Errorf("%w: %s is already %s", ErrInvalidAction, resource.ID, resource.State)
+	}
+	return actionType, nil
+}
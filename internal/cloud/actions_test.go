@@ -0,0 +1,41 @@
+package cloud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateAction(t *testing.T) {
+	cases := []struct {
+		name     string
+		resource *ResourceV2
+		action   string
+		valid    bool
+	}{
+		{"stop running ec2", &ResourceV2{Type: ResourceTypeEC2, State: "running"}, "stop", true},
+		{"stop rds", &ResourceV2{Type: ResourceTypeRDS, State: "available"}, "stop", true},
+		{"terminate rds", &ResourceV2{Type: ResourceTypeRDS, State: "available"}, "terminate", false},
+		{"delete volume of ec2", &ResourceV2{Type: ResourceTypeEC2}, "delete_volume", false},
+		{"delete storage volume", &ResourceV2{Type: ResourceTypeStorage}, "delete_volume", true},
+		{"stop stopped ec2", &ResourceV2{Type: ResourceTypeEC2, State: "stopped"}, "stop", false},
+		{"resize terminated ec2", &ResourceV2{Type: ResourceTypeEC2, State: "terminated"}, "resize", false},
+		{"unknown state", &ResourceV2{Type: ResourceTypeEC2, State: "unknown"}, "terminate", true},
+		{"unknown action", &ResourceV2{Type: ResourceTypeEC2}, "stopped", false},
+		{"unknown resource type", &ResourceV2{Type: "lambda"}, "stop", false},
+		{"delete load balancer", &ResourceV2{Type: ResourceTypeLoadBalancer, State: "active"}, "delete_load_balancer", true},
+		{"stop load balancer", &ResourceV2{Type: ResourceTypeLoadBalancer, State: "active"}, "stop", false},
+		{"release address", &ResourceV2{Type: ResourceTypeElasticIP, State: "unassociated"}, "release_address", true},
+		{"release address of ec2", &ResourceV2{Type: ResourceTypeEC2}, "release_address", false},
+	}
+
+	for _, tc := range cases {
+		actionType, err := ValidateAction(tc.resource, tc.action)
+		if tc.valid {
+			assert.NoError(t, err, tc.name)
+			assert.Equal(t, ActionType(tc.action), actionType, tc.name)
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidAction, tc.name)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package cloud
 
 import (
 	"context"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Provider constants
@@ -18,6 +20,15 @@ const (
 	ResourceTypeVM      = "vm"
 	ResourceTypeStorage = "storage"
 	ResourceTypeNetwork = "network"
+
+	ResourceTypeLoadBalancer = "load_balancer"
+	ResourceTypeElasticIP    = "elastic_ip"
+	// ResourceTypeEBS is an AWS EBS volume
+	ResourceTypeEBS = "ebs"
+	// ResourceTypeCloudSQL is a Google Cloud SQL instance
+	ResourceTypeCloudSQL = "cloudsql"
+	// ResourceTypeAzureSQL is an Azure SQL database
+	ResourceTypeAzureSQL = "azure_sql"
 )
 
 // CloudConfig defines the configuration for a cloud provider adapter.
@@ -26,6 +37,58 @@ type CloudConfig struct {
 	Region   string
 	APIKey   string
 	DryRun   bool
+	// Project is the GCP project to scan; empty uses the project of the
+	// default credentials.
+	Project string
+	// Subscription is the Azure subscription to scan; empty uses
+	// AZURE_SUBSCRIPTION_ID.
+	Subscription string
+	// SavingsRatios overrides DefaultSavingsRatios per action type.
+	SavingsRatios map[string]float64
+	// Protection lists resources that ApplyOptimization must refuse to modify.
+	Protection ProtectionPolicy
+	// Termination guards the terminate action.
+	Termination TerminationPolicy
+	// Idle guards deleting idle load balancers and releasing unattached IPs.
+	Idle IdlePolicy
+	// Regions limits which regions multi-region fetching scans.
+	Regions RegionPolicy
+	// MaxAttempts is how many times a provider API call is tried before
+	// its error is returned, counting the first try; zero uses the SDK's
+	// default.
+	MaxAttempts int
+	// Metrics is an optional external metrics source; nil uses only the
+	// provider's native monitoring (e.g. CloudWatch).
+	Metrics MetricsProvider
+	// MetricsMode is MetricsModeMerge (the default) or MetricsModeReplace.
+	MetricsMode string
+	// APIMetrics counts the adapter's calls to the provider API; nil
+	// records nothing.
+	APIMetrics *APIMetrics
+	// Tracer traces the adapter's operations and its calls to the provider
+	// API; nil traces nothing.
+	Tracer trace.Tracer
+}
+
+// DefaultSavingsRatios is the fraction of a resource's monthly cost assumed
+// saved by each action type until pricing-backed estimates are available.
+var DefaultSavingsRatios = map[string]float64{
+	string(ActionStop):      1.0,
+	string(ActionTerminate): 1.0,
+	string(ActionResize):    0.5,
+	string(ActionOptimize):  0.5,
+
+	string(ActionDeleteLoadBalancer): 1.0,
+	string(ActionReleaseAddress):     1.0,
+}
+
+// SavingsRatio returns the configured savings ratio for an action type,
+// falling back to DefaultSavingsRatios. Unknown actions save nothing.
+func (c CloudConfig) SavingsRatio(action string) float64 {
+	if ratio, ok := c.SavingsRatios[action]; ok {
+		return ratio
+	}
+	return DefaultSavingsRatios[action]
 }
 
 // CloudAdapter is the interface that all cloud providers must implement.
@@ -33,7 +96,41 @@ type CloudConfig struct {
 type CloudAdapter interface {
 	FetchResources(ctx context.Context) ([]*ResourceV2, error)
 	GetResource(ctx context.Context, id string) (*ResourceV2, error)
+	// GetResources looks several resources up at once, in as few provider
+	// API calls as the provider allows, and returns them keyed by the IDs
+	// they were requested by. Like GetResourceMetadata it may leave
+	// utilization metrics unset. IDs that can't be found are left out, so a
+	// caller looking one up with GetResource sees why.
+	GetResources(ctx context.Context, ids []string) (map[string]*ResourceV2, error)
 	ApplyOptimization(ctx context.Context, resource *ResourceV2, action string) (float64, error)
 	GetSpotPrice(zone, instanceType string) (float64, error)
 	ListZones() ([]string, error)
 }
+
+// MetadataGetter is implemented by adapters that can look a resource up
+// without its utilization metrics, which is all an action needs and saves a
+// metrics API round trip. GetResourceMetadata accepts the same IDs as
+// GetResource.
+type MetadataGetter interface {
+	GetResourceMetadata(ctx context.Context, id string) (*ResourceV2, error)
+}
+
+// GetResourcesOneByOne implements GetResources for adapters whose provider
+// has no batch lookup, with one GetResourceMetadata (or GetResource) call
+// per ID. Failed lookups are left out.
+func GetResourcesOneByOne(ctx context.Context, adapter CloudAdapter, ids []string) (map[string]*ResourceV2, error) {
+	lookup := adapter.GetResource
+	if getter, ok := adapter.(MetadataGetter); ok {
+		lookup = getter.GetResourceMetadata
+	}
+	resources := make(map[string]*ResourceV2, len(ids))
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return resources, err
+		}
+		if resource, err := lookup(ctx, id); err == nil {
+			resources[id] = resource
+		}
+	}
+	return resources, nil
+}
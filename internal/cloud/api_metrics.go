@@ -0,0 +1,46 @@
+package cloud
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Outcomes of a provider API call, as recorded by APIMetrics
+const (
+	APICallSuccess   = "success"
+	APICallThrottled = "throttled"
+	APICallError     = "error"
+)
+
+// APIMetrics counts the calls adapters make to their provider's API. One
+// instance can be shared by every adapter of a process.
+type APIMetrics struct {
+	calls *prometheus.CounterVec
+}
+
+// NewAPIMetrics creates the API call counters
+func NewAPIMetrics() *APIMetrics {
+	return &APIMetrics{
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "talos_cloud_api_calls_total",
+			Help: "Cloud provider API calls, by provider, service, operation and outcome",
+		}, []string{"provider", "service", "operation", "outcome"}),
+	}
+}
+
+// Register exposes the counters through reg, usually
+// prometheus.DefaultRegisterer so that they are served on /metrics
+func (m *APIMetrics) Register(reg prometheus.Registerer) error {
+	return reg.Register(m.calls)
+}
+
+// Record counts one API call with one of the APICall outcomes. It is safe
+// to call on a nil *APIMetrics.
+func (m *APIMetrics) Record(provider, service, operation, outcome string) {
+	if m == nil {
+		return
+	}
+	m.calls.WithLabelValues(provider, service, operation, outcome).Inc()
+}
+
+// Calls returns the counter of one label set, e.g. to read it in tests
+func (m *APIMetrics) Calls(provider, service, operation, outcome string) prometheus.Counter {
+	return m.calls.WithLabelValues(provider, service, operation, outcome)
+}
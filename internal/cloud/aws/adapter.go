@@ -2,6 +2,7 @@ package aws
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -13,57 +14,104 @@ import (
 	cloudwatchtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
+	rdstypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/aws/smithy-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/multierr"
 
 	"github.com/Xover-Official/Xover/internal/cloud"
 )
 
-// mockInstancePricing provides a rough cost estimate per month for instance types.
-// In a real application, this would use the AWS Price List API.
-var mockInstancePricing = map[string]float64{
-	"t2.micro":   10.0,
-	"t3.medium":  40.0,
-	"m5.large":   80.0,
-	"m5.2xlarge": 320.0,
+// ec2API is the part of the EC2 API the adapter uses
+type ec2API interface {
+	ec2.DescribeInstancesAPIClient
+	ec2.DescribeVolumesAPIClient
+	ec2.DescribeInstanceTypesAPIClient
+	DescribeAddresses(ctx context.Context, params *ec2.DescribeAddressesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error)
+	StopInstances(ctx context.Context, params *ec2.StopInstancesInput, optFns ...func(*ec2.Options)) (*ec2.StopInstancesOutput, error)
+	StartInstances(ctx context.Context, params *ec2.StartInstancesInput, optFns ...func(*ec2.Options)) (*ec2.StartInstancesOutput, error)
+	ModifyInstanceAttribute(ctx context.Context, params *ec2.ModifyInstanceAttributeInput, optFns ...func(*ec2.Options)) (*ec2.ModifyInstanceAttributeOutput, error)
+	TerminateInstances(ctx context.Context, params *ec2.TerminateInstancesInput, optFns ...func(*ec2.Options)) (*ec2.TerminateInstancesOutput, error)
+	CreateTags(ctx context.Context, params *ec2.CreateTagsInput, optFns ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error)
+	DeleteTags(ctx context.Context, params *ec2.DeleteTagsInput, optFns ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error)
+	ReleaseAddress(ctx context.Context, params *ec2.ReleaseAddressInput, optFns ...func(*ec2.Options)) (*ec2.ReleaseAddressOutput, error)
+	DeleteVolume(ctx context.Context, params *ec2.DeleteVolumeInput, optFns ...func(*ec2.Options)) (*ec2.DeleteVolumeOutput, error)
+	ec2.DescribeSpotPriceHistoryAPIClient
+	GetSpotPlacementScores(ctx context.Context, params *ec2.GetSpotPlacementScoresInput, optFns ...func(*ec2.Options)) (*ec2.GetSpotPlacementScoresOutput, error)
+	DescribeAvailabilityZones(ctx context.Context, params *ec2.DescribeAvailabilityZonesInput, optFns ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error)
+}
+
+// cloudWatchAPI is the part of the CloudWatch API the adapter uses
+type cloudWatchAPI interface {
+	GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error)
 }
 
 // Adapter implements the cloud.CloudAdapter interface for AWS.
 type Adapter struct {
-	ec2Client *ec2.Client
+	ec2Client ec2API
 	rdsClient *rds.Client
-	cwClient  *cloudwatch.Client
+	cwClient  cloudWatchAPI
+	elbClient *elbv2.Client
+	pricer    Pricer
 	region    string
 	dryRun    bool
+	cfg       cloud.CloudConfig
+	tracer    trace.Tracer // nil traces nothing
+
+	unattached unattachedAddresses
+	spot       spotMarkets
 }
 
 // New creates a new AWS adapter. It satisfies the cloud.Adapter interface.
 func New(ctx context.Context, cfg cloud.CloudConfig) (*Adapter, error) {
 	awsCfg, err := config.LoadDefaultConfig(ctx,
 		config.WithRegion(cfg.Region),
+		config.WithRetryer(newRetryer(cfg.MaxAttempts)),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
+	awsCfg.APIOptions = append(awsCfg.APIOptions, throttleErrorMiddleware)
+	if cfg.APIMetrics != nil {
+		awsCfg.APIOptions = append(awsCfg.APIOptions, apiMetricsMiddleware(cfg.APIMetrics))
+	}
+	if cfg.Tracer != nil {
+		awsCfg.APIOptions = append(awsCfg.APIOptions, tracingMiddleware(cfg.Tracer))
+	}
 
 	return &Adapter{
 		ec2Client: ec2.NewFromConfig(awsCfg),
 		rdsClient: rds.NewFromConfig(awsCfg),
 		cwClient:  cloudwatch.NewFromConfig(awsCfg),
+		elbClient: elbv2.NewFromConfig(awsCfg),
+		pricer:    NewPriceListPricer(awsCfg, DefaultPriceTTL),
 		region:    cfg.Region,
 		dryRun:    cfg.DryRun,
+		cfg:       cfg,
+		tracer:    cfg.Tracer,
 	}, nil
 }
 
 // FetchResources retrieves all supported AWS resources and converts them to the canonical ResourceV2 model.
 func (a *Adapter) FetchResources(ctx context.Context) ([]*cloud.ResourceV2, error) {
+	ctx, span := a.startSpan(ctx, "FetchResources", "")
+	resources, err := a.fetchResources(ctx)
+	span.SetAttributes(attribute.Int("aws.resource_count", len(resources)))
+	endSpan(span, err)
+	return resources, err
+}
+
+func (a *Adapter) fetchResources(ctx context.Context) ([]*cloud.ResourceV2, error) {
 	var wg sync.WaitGroup
-	var ec2Resources, rdsResources []*cloud.ResourceV2
-	var ec2Err, rdsErr error
+	var ec2Resources, rdsResources, lbResources, eipResources, ebsResources []*cloud.ResourceV2
+	var ec2Err, rdsErr, lbErr, eipErr, ebsErr error
 
-	wg.Add(2)
+	wg.Add(5)
 
-	// Fetch EC2 and RDS resources concurrently
+	// Fetch EC2, RDS, load balancers, Elastic IPs and EBS volumes concurrently
 	go func() {
 		defer wg.Done()
 		ec2Resources, ec2Err = a.fetchEC2Instances(ctx)
@@ -74,6 +122,21 @@ func (a *Adapter) FetchResources(ctx context.Context) ([]*cloud.ResourceV2, erro
 		rdsResources, rdsErr = a.fetchRDSInstances(ctx)
 	}()
 
+	go func() {
+		defer wg.Done()
+		lbResources, lbErr = a.fetchLoadBalancers(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		eipResources, eipErr = a.fetchElasticIPs(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		ebsResources, ebsErr = a.fetchEBSVolumes(ctx)
+	}()
+
 	wg.Wait()
 
 	if ec2Err != nil {
@@ -83,7 +146,21 @@ func (a *Adapter) FetchResources(ctx context.Context) ([]*cloud.ResourceV2, erro
 		return nil, fmt.Errorf("failed to fetch RDS instances: %w", rdsErr)
 	}
 
-	return append(ec2Resources, rdsResources...), nil
+	// Load balancers, addresses and volumes need extra IAM permissions;
+	// without them the scan goes on with instances only
+	resources := append(ec2Resources, rdsResources...)
+	if lbErr != nil {
+		log.Printf("skipping load balancers: %v", lbErr)
+	}
+	if eipErr != nil {
+		log.Printf("skipping Elastic IPs: %v", eipErr)
+	}
+	if ebsErr != nil {
+		log.Printf("skipping EBS volumes: %v", ebsErr)
+	}
+	resources = append(resources, lbResources...)
+	resources = append(resources, eipResources...)
+	return append(resources, ebsResources...), nil
 }
 
 func (a *Adapter) fetchEC2Instances(ctx context.Context) ([]*cloud.ResourceV2, error) {
@@ -111,40 +188,21 @@ func (a *Adapter) fetchEC2Instances(ctx context.Context) ([]*cloud.ResourceV2, e
 		go func() {
 			defer wg.Done()
 			for instance := range jobs {
-				metrics, err := a.getEC2Metrics(ctx, *instance.InstanceId)
-				if err != nil {
-					log.Printf("failed to get metrics for instance %s: %v", *instance.InstanceId, err)
+				instanceID := aws.ToString(instance.InstanceId)
+				if instanceID == "" {
+					log.Printf("skipping EC2 instance without an instance ID (state %q)", ec2State(instance))
 					continue
 				}
 
-				cpu, _ := metrics["cpu_usage"].(float64)
-				mem, _ := metrics["memory_usage"].(float64)
-				netIn, _ := metrics["network_in"].(float64)
-				netOut, _ := metrics["network_out"].(float64)
-
-				cost, _ := mockInstancePricing[string(instance.InstanceType)]
-
-				resource := &cloud.ResourceV2{
-					ID:           *instance.InstanceId,
-					Type:         cloud.ResourceTypeEC2,
-					Provider:     cloud.ProviderAWS,
-					Region:       a.region,
-					Tags:         make(map[string]string),
-					State:        string(instance.State.Name),
-					CreatedAt:    *instance.LaunchTime,
-					CPUUsage:     cpu,
-					MemoryUsage:  mem,
-					NetworkIn:    netIn,
-					NetworkOut:   netOut,
-					CostPerMonth: cost,
-					Metadata:     map[string]interface{}{"instance_type": string(instance.InstanceType)},
+				resource := ec2InstanceToResource(instance, a.region)
+				a.priceInstance(ctx, resource)
+				metrics, err := a.resourceMetrics(ctx, resource)
+				if err != nil {
+					log.Printf("failed to get metrics for instance %s: %v", instanceID, err)
+					continue
 				}
+				metrics.Apply(resource)
 
-				for _, tag := range instance.Tags {
-					if tag.Key != nil && tag.Value != nil {
-						resource.Tags[*tag.Key] = *tag.Value
-					}
-				}
 				results <- resource
 			}
 		}()
@@ -174,32 +232,45 @@ func (a *Adapter) fetchRDSInstances(ctx context.Context) ([]*cloud.ResourceV2, e
 
 	var resources []*cloud.ResourceV2
 	for _, instance := range result.DBInstances {
-		// RDS metrics fetching would be similar to EC2, omitted for brevity
-		resource := &cloud.ResourceV2{
-			ID:                 *instance.DBInstanceIdentifier,
-			Type:               cloud.ResourceTypeRDS,
-			Provider:           cloud.ProviderAWS,
-			Region:             a.region,
-			Tags:               make(map[string]string),
-			State:              *instance.DBInstanceStatus,
-			CreatedAt:          *instance.InstanceCreateTime,
-			CPUUsage:           30.0,  // Placeholder
-			MemoryUsage:        40.0,  // Placeholder
-			CostPerMonth:       200.0, // Placeholder
-			EncryptionEnabled:  *instance.StorageEncrypted,
-			PubliclyAccessible: *instance.PubliclyAccessible,
-			Metadata:           map[string]interface{}{"instance_class": *instance.DBInstanceClass},
+		resource, ok := rdsInstanceToResource(instance, a.region)
+		if !ok {
+			log.Printf("skipping RDS instance without an identifier (status %q)", aws.ToString(instance.DBInstanceStatus))
+			continue
 		}
-
 		resources = append(resources, resource)
 	}
 
 	return resources, nil
 }
 
-// GetResource retrieves a single resource by its ID
+// GetResource retrieves a single resource by its ID: a load balancer ARN, an
+// Elastic IP allocation ID, an EBS volume ID, or otherwise an EC2 instance ID
 func (a *Adapter) GetResource(ctx context.Context, id string) (*cloud.ResourceV2, error) {
-	// For now, only support EC2 by ID
+	ctx, span := a.startSpan(ctx, "GetResource", id)
+	resource, err := a.getResource(ctx, id, true)
+	endSpan(span, err)
+	return resource, err
+}
+
+// GetResourceMetadata retrieves a resource like GetResource, but leaves an
+// EC2 instance's utilization unset rather than asking CloudWatch for it
+func (a *Adapter) GetResourceMetadata(ctx context.Context, id string) (*cloud.ResourceV2, error) {
+	ctx, span := a.startSpan(ctx, "GetResourceMetadata", id)
+	resource, err := a.getResource(ctx, id, false)
+	endSpan(span, err)
+	return resource, err
+}
+
+func (a *Adapter) getResource(ctx context.Context, id string, withMetrics bool) (*cloud.ResourceV2, error) {
+	switch {
+	case isLoadBalancerID(id):
+		return a.getLoadBalancer(ctx, id)
+	case isElasticIPID(id):
+		return a.getElasticIP(ctx, id)
+	case isVolumeID(id):
+		return a.getVolume(ctx, id)
+	}
+
 	input := &ec2.DescribeInstancesInput{
 		InstanceIds: []string{id},
 	}
@@ -213,27 +284,156 @@ func (a *Adapter) GetResource(ctx context.Context, id string) (*cloud.ResourceV2
 	}
 
 	instance := result.Reservations[0].Instances[0]
-	metrics, err := a.getEC2Metrics(ctx, id)
+	resource := ec2InstanceToResource(instance, a.region)
+	if resource.ID == "" {
+		resource.ID = id
+	}
+	a.priceInstance(ctx, resource)
+	if !withMetrics {
+		return resource, nil
+	}
+
+	metrics, err := a.resourceMetrics(ctx, resource)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get metrics for %s: %w", id, err)
 	}
+	metrics.Apply(resource)
+	return resource, nil
+}
 
-	cpu, _ := metrics["cpu_usage"].(float64)
-	mem, _ := metrics["memory_usage"].(float64)
-	cost, _ := mockInstancePricing[string(instance.InstanceType)]
+// describeInstancesBatchSize is the most instance IDs GetResources asks for
+// in one DescribeInstances call, small enough that a batch also fits the
+// instance-id filter used as a fallback
+const describeInstancesBatchSize = 200
+
+// GetResources looks EC2 instances up in batches of
+// describeInstancesBatchSize IDs per DescribeInstances call, without their
+// utilization metrics. Load balancers, addresses and volumes are looked up
+// one at a time.
+func (a *Adapter) GetResources(ctx context.Context, ids []string) (map[string]*cloud.ResourceV2, error) {
+	ctx, span := a.startSpan(ctx, "GetResources", "", attribute.Int("aws.requested_count", len(ids)))
+	resources, err := a.getResources(ctx, ids)
+	span.SetAttributes(attribute.Int("aws.resource_count", len(resources)))
+	endSpan(span, err)
+	return resources, err
+}
+
+func (a *Adapter) getResources(ctx context.Context, ids []string) (map[string]*cloud.ResourceV2, error) {
+	resources := make(map[string]*cloud.ResourceV2, len(ids))
+	var instanceIDs []string
+	for _, id := range ids {
+		if isLoadBalancerID(id) || isElasticIPID(id) || isVolumeID(id) {
+			resource, err := a.getResource(ctx, id, false)
+			if err != nil {
+				// Left out of the batch, so the caller looks it up on its own
+				log.Printf("skipping %s in batch lookup: %v", id, err)
+				continue
+			}
+			resources[id] = resource
+			continue
+		}
+		instanceIDs = append(instanceIDs, id)
+	}
+
+	for start := 0; start < len(instanceIDs); start += describeInstancesBatchSize {
+		batch := instanceIDs[start:min(start+describeInstancesBatchSize, len(instanceIDs))]
+		instances, err := a.describeInstances(ctx, batch)
+		if err != nil {
+			return resources, fmt.Errorf("failed to describe instances: %w", err)
+		}
+		for _, instance := range instances {
+			resource := ec2InstanceToResource(instance, a.region)
+			if resource.ID == "" {
+				continue
+			}
+			a.priceInstance(ctx, resource)
+			resources[resource.ID] = resource
+		}
+	}
+	return resources, nil
+}
+
+// describeInstances describes the instances with the given IDs. Naming an
+// instance that no longer exists fails the whole call, so that batch is
+// retried with an instance-id filter, which skips unknown IDs.
+func (a *Adapter) describeInstances(ctx context.Context, ids []string) ([]ec2types.Instance, error) {
+	output, err := a.ec2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: ids})
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidInstanceID.NotFound" {
+		var instances []ec2types.Instance
+		paginator := ec2.NewDescribeInstancesPaginator(a.ec2Client, &ec2.DescribeInstancesInput{
+			Filters: []ec2types.Filter{{Name: aws.String("instance-id"), Values: ids}},
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+			for _, reservation := range page.Reservations {
+				instances = append(instances, reservation.Instances...)
+			}
+		}
+		return instances, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var instances []ec2types.Instance
+	for _, reservation := range output.Reservations {
+		instances = append(instances, reservation.Instances...)
+	}
+	return instances, nil
+}
+
+// priceInstance sets the monthly cost of an EC2 instance from its type. An
+// instance that can't be priced keeps a zero cost.
+func (a *Adapter) priceInstance(ctx context.Context, resource *cloud.ResourceV2) {
+	if a.pricer == nil {
+		return
+	}
+	instanceType, _ := resource.Metadata["instance_type"].(string)
+	cost, err := a.pricer.InstanceMonthlyCost(ctx, instanceType, a.region)
+	if err != nil {
+		log.Printf("no price for EC2 instance %s: %v", resource.ID, err)
+		return
+	}
+	resource.CostPerMonth = cost
+}
+
+// unknownState is reported when the SDK omits an instance's state, which
+// happens for instances still being provisioned (e.g. spot requests).
+const unknownState = "unknown"
+
+// ec2State returns the instance state name, or unknownState if it is missing.
+func ec2State(instance ec2types.Instance) string {
+	if instance.State == nil || instance.State.Name == "" {
+		return unknownState
+	}
+	return string(instance.State.Name)
+}
+
+// ec2InstanceToResource converts an SDK instance to the canonical model,
+// without utilization metrics or cost. Every pointer field is optional in the SDK,
+// so missing values fall back to zero values rather than panicking.
+func ec2InstanceToResource(instance ec2types.Instance, region string) *cloud.ResourceV2 {
+	id := aws.ToString(instance.InstanceId)
+	if instance.LaunchTime == nil {
+		log.Printf("EC2 instance %s has no launch time; using zero time", id)
+	}
 
 	resource := &cloud.ResourceV2{
-		ID:           *instance.InstanceId,
-		Type:         cloud.ResourceTypeEC2,
-		Provider:     cloud.ProviderAWS,
-		Region:       a.region,
-		Tags:         make(map[string]string),
-		State:        string(instance.State.Name),
-		CreatedAt:    *instance.LaunchTime,
-		CPUUsage:     cpu,
-		MemoryUsage:  mem,
-		CostPerMonth: cost,
-		Metadata:     map[string]interface{}{"instance_type": string(instance.InstanceType)},
+		ID:        id,
+		Type:      cloud.ResourceTypeEC2,
+		Provider:  cloud.ProviderAWS,
+		Region:    region,
+		Tags:      make(map[string]string),
+		State:     ec2State(instance),
+		CreatedAt: aws.ToTime(instance.LaunchTime),
+		Metadata:  map[string]interface{}{"instance_type": string(instance.InstanceType)},
+	}
+	if instance.Placement != nil && instance.Placement.AvailabilityZone != nil {
+		resource.Metadata[cloud.AvailabilityZoneKey] = *instance.Placement.AvailabilityZone
 	}
 
 	for _, tag := range instance.Tags {
@@ -242,34 +442,169 @@ func (a *Adapter) GetResource(ctx context.Context, id string) (*cloud.ResourceV2
 		}
 	}
 
-	return resource, nil
+	if resource.State == string(ec2types.InstanceStateNameStopped) {
+		if at, ok := stoppedAt(instance); ok {
+			resource.Metadata[stoppedAtKey] = at
+		}
+	}
+
+	return resource
+}
+
+// rdsInstanceToResource converts an SDK DB instance to the canonical model.
+// It returns false if the instance has no identifier and cannot be tracked.
+func rdsInstanceToResource(instance rdstypes.DBInstance, region string) (*cloud.ResourceV2, bool) {
+	id := aws.ToString(instance.DBInstanceIdentifier)
+	if id == "" {
+		return nil, false
+	}
+
+	state := aws.ToString(instance.DBInstanceStatus)
+	if state == "" {
+		state = unknownState
+	}
+	if instance.InstanceCreateTime == nil {
+		log.Printf("RDS instance %s has no create time; using zero time", id)
+	}
+
+	tags := make(map[string]string, len(instance.TagList))
+	for _, tag := range instance.TagList {
+		if tag.Key != nil && tag.Value != nil {
+			tags[*tag.Key] = *tag.Value
+		}
+	}
+
+	// RDS metrics fetching would be similar to EC2, omitted for brevity
+	return &cloud.ResourceV2{
+		ID:                 id,
+		Type:               cloud.ResourceTypeRDS,
+		Provider:           cloud.ProviderAWS,
+		Region:             region,
+		Tags:               tags,
+		State:              state,
+		CreatedAt:          aws.ToTime(instance.InstanceCreateTime),
+		CPUUsage:           30.0,  // Placeholder
+		MemoryUsage:        40.0,  // Placeholder
+		CostPerMonth:       200.0, // Placeholder
+		EncryptionEnabled:  aws.ToBool(instance.StorageEncrypted),
+		PubliclyAccessible: aws.ToBool(instance.PubliclyAccessible),
+		Metadata:           map[string]interface{}{"instance_class": aws.ToString(instance.DBInstanceClass)},
+	}, true
 }
 
 // ApplyOptimization applies an optimization to an AWS resource
 func (a *Adapter) ApplyOptimization(ctx context.Context, resource *cloud.ResourceV2, action string) (float64, error) {
-	if a.dryRun {
-		// Simulate savings calculation for dry run
-		var estimatedSavings float64
-		if action == "resize" {
-			// Mock downsizing: assume we save 50% of the cost.
-			estimatedSavings = resource.CostPerMonth * 0.5
+	ctx, span := a.startSpan(ctx, "ApplyOptimization", resource.ID, attribute.String("aws.action", action))
+	savings, err := a.applyOptimization(ctx, resource, action)
+	endSpan(span, err)
+	return savings, err
+}
+
+func (a *Adapter) applyOptimization(ctx context.Context, resource *cloud.ResourceV2, action string) (float64, error) {
+	// Protected resources are refused here even if the engine's checks were bypassed
+	if err := a.cfg.Protection.CheckMutation(resource, action); err != nil {
+		log.Printf("protection event: %v", err)
+		return 0, err
+	}
+	actionType, err := cloud.ValidateAction(resource, action)
+	if err != nil {
+		return 0, err
+	}
+
+	// Savings are estimated from the configured per-action ratios, except
+	// where they are priced below.
+	estimatedSavings := resource.CostPerMonth * a.cfg.SavingsRatio(action)
+
+	// Terminating an address or volume releases or deletes it, once the
+	// termination policy allows it, through the idle cleanup below
+	if cleanup, ok := terminateAsCleanup[resource.Type]; ok && actionType == cloud.ActionTerminate {
+		if err := a.cfg.Termination.CheckProtected(resource); err != nil {
+			log.Printf("termination safeguard: %v", err)
+			return 0, err
+		}
+		actionType = cleanup
+	}
+
+	// Termination is irreversible, so its safeguards run even in dry run and
+	// a projection never promises a termination that would be refused
+	var terminateNow bool
+	if actionType == cloud.ActionTerminate && resource.Type == cloud.ResourceTypeEC2 {
+		terminateNow, err = a.checkTermination(ctx, resource, time.Now())
+		if err != nil {
+			log.Printf("termination safeguard: %v", err)
+			return 0, err
+		}
+		// A terminated instance stops costing anything
+		estimatedSavings = resource.CostPerMonth
+	}
+
+	// A resize is checked up front and its savings priced, so a projection
+	// reflects the real target type
+	var resizeTo string
+	if actionType == cloud.ActionResize && resource.Type == cloud.ResourceTypeEC2 {
+		if resizeTo, err = resizeTarget(resource); err != nil {
+			return 0, err
+		}
+		if err := a.validateInstanceType(ctx, resizeTo); err != nil {
+			return 0, err
 		}
+		estimatedSavings = a.resizeSavings(ctx, resource, resizeTo)
+	}
+
+	// Idle cleanups are checked the same way, so a projection only counts
+	// load balancers, addresses and volumes that would really be removed
+	if actionType.IsQuickWin() {
+		if err := a.checkIdleCleanup(ctx, resource, time.Now()); err != nil {
+			log.Printf("idle safeguard: %v", err)
+			return 0, err
+		}
+		estimatedSavings = resource.CostPerMonth
+	}
+
+	// A per-action override (set by an operator at approval) wins over the global setting
+	if cloud.DryRun(ctx, a.dryRun) {
 		return estimatedSavings, nil
 	}
 
-	switch action {
-	case "stop":
+	// RDS instances are stopped through the RDS API, not EC2
+	switch {
+	case actionType == cloud.ActionStop && resource.Type == cloud.ResourceTypeRDS:
+		_, err := a.stopRDSInstance(ctx, resource.ID)
+		return estimatedSavings, err
+	case actionType == cloud.ActionStop:
 		_, err := a.stopEC2Instance(ctx, resource.ID)
-		// Stopping an instance saves its entire monthly cost.
-		return resource.CostPerMonth, err
-	case "resize":
-		_, err := a.resizeEC2Instance(ctx, resource.ID)
-		// Mock downsizing: assume we save 50% of the cost.
-		estimatedSavings := resource.CostPerMonth * 0.5
+		return estimatedSavings, err
+	case actionType == cloud.ActionResize && resource.Type == cloud.ResourceTypeEC2:
+		_, err := a.resizeEC2Instance(ctx, resource.ID, resizeTo)
+		return estimatedSavings, err
+	case actionType == cloud.ActionTerminate && resource.Type == cloud.ResourceTypeEC2 && terminateNow:
+		_, err := a.terminateEC2Instance(ctx, resource.ID)
+		return estimatedSavings, err
+	case actionType == cloud.ActionTerminate && resource.Type == cloud.ResourceTypeEC2:
+		_, err := a.softTerminateEC2Instance(ctx, resource.ID, time.Now())
+		return estimatedSavings, err
+	case actionType == cloud.ActionDeleteLoadBalancer:
+		_, err := a.deleteLoadBalancer(ctx, resource.ID)
+		return estimatedSavings, err
+	case actionType == cloud.ActionReleaseAddress:
+		_, err := a.releaseAddress(ctx, resource.ID)
+		return estimatedSavings, err
+	case actionType == cloud.ActionDeleteVolume && resource.Type == cloud.ResourceTypeEBS:
+		_, err := a.deleteVolume(ctx, resource.ID)
 		return estimatedSavings, err
 	default:
-		return 0, fmt.Errorf("unknown action: %s", action)
+		return 0, fmt.Errorf("%s on %s resources is not implemented by the AWS adapter", actionType, resource.Type)
+	}
+}
+
+func (a *Adapter) stopRDSInstance(ctx context.Context, dbInstanceID string) (string, error) {
+	_, err := a.rdsClient.StopDBInstance(ctx, &rds.StopDBInstanceInput{
+		DBInstanceIdentifier: aws.String(dbInstanceID),
+	})
+	if err != nil {
+		return "", err
 	}
+	return fmt.Sprintf("Stopped RDS instance %s", dbInstanceID), nil
 }
 
 func (a *Adapter) stopEC2Instance(ctx context.Context, instanceID string) (string, error) {
@@ -282,13 +617,43 @@ func (a *Adapter) stopEC2Instance(ctx context.Context, instanceID string) (strin
 	return fmt.Sprintf("Stopped EC2 instance %s", instanceID), nil
 }
 
-// getEC2Metrics fetches real CloudWatch metrics for an EC2 instance
-func (a *Adapter) getEC2Metrics(ctx context.Context, instanceID string) (map[string]interface{}, error) {
+// resourceMetrics gathers utilization for an EC2 instance from CloudWatch,
+// the configured external provider, or both according to the metrics mode.
+// An external provider that fails falls back to CloudWatch.
+func (a *Adapter) resourceMetrics(ctx context.Context, resource *cloud.ResourceV2) (cloud.Metrics, error) {
+	provider := a.cfg.Metrics
+	if provider == nil {
+		return a.getEC2Metrics(ctx, resource.ID)
+	}
+
+	external, err := provider.FetchMetrics(ctx, resource)
+	if err != nil {
+		log.Printf("%s metrics unavailable for %s, falling back to CloudWatch: %v", provider.Name(), resource.ID, err)
+		return a.getEC2Metrics(ctx, resource.ID)
+	}
+	if a.cfg.MetricsMode == cloud.MetricsModeReplace {
+		return external, nil
+	}
+
+	native, err := a.getEC2Metrics(ctx, resource.ID)
+	if err != nil {
+		// The external provider already answered; CloudWatch only fills gaps
+		log.Printf("CloudWatch metrics incomplete for %s: %v", resource.ID, err)
+	}
+	return external.Merge(native), nil
+}
+
+// getEC2Metrics fetches real CloudWatch metrics for an EC2 instance. Memory
+// is only reported by instances running the CloudWatch agent, which publishes
+// mem_used_percent to the CWAgent namespace; the agent must append the
+// InstanceId dimension.
+func (a *Adapter) getEC2Metrics(ctx context.Context, instanceID string) (cloud.Metrics, error) {
+	ctx, span := a.startSpan(ctx, "GetEC2Metrics", instanceID)
 	var wg sync.WaitGroup
-	var cpuResult, netInResult, netOutResult *cloudwatch.GetMetricStatisticsOutput
-	var cpuErr, netInErr, netOutErr error
+	var cpuResult, memResult, netInResult, netOutResult *cloudwatch.GetMetricStatisticsOutput
+	var cpuErr, memErr, netInErr, netOutErr error
 
-	wg.Add(3)
+	wg.Add(4)
 
 	go func() {
 		defer wg.Done()
@@ -303,6 +668,19 @@ func (a *Adapter) getEC2Metrics(ctx context.Context, instanceID string) (map[str
 		})
 	}()
 
+	go func() {
+		defer wg.Done()
+		memResult, memErr = a.cwClient.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+			Namespace:  aws.String("CWAgent"),
+			MetricName: aws.String("mem_used_percent"),
+			Dimensions: []cloudwatchtypes.Dimension{{Name: aws.String("InstanceId"), Value: aws.String(instanceID)}},
+			StartTime:  aws.Time(time.Now().Add(-1 * time.Hour)),
+			EndTime:    aws.Time(time.Now()),
+			Period:     aws.Int32(300), // 5 minutes
+			Statistics: []cloudwatchtypes.Statistic{cloudwatchtypes.StatisticAverage},
+		})
+	}()
+
 	go func() {
 		defer wg.Done()
 		netInResult, netInErr = a.cwClient.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
@@ -331,71 +709,59 @@ func (a *Adapter) getEC2Metrics(ctx context.Context, instanceID string) (map[str
 
 	wg.Wait()
 
-	err := multierr.Combine(cpuErr, netInErr, netOutErr)
+	err := multierr.Combine(cpuErr, memErr, netInErr, netOutErr)
+	endSpan(span, err)
+
+	// Only metrics with datapoints are reported, so a merge can fill the rest
+	metrics := cloud.Metrics{}
 
-	netInBytes := 0.0
 	if netInErr == nil && netInResult != nil && len(netInResult.Datapoints) > 0 {
 		latest := netInResult.Datapoints[0]
 		if latest.Sum != nil {
-			netInBytes = *latest.Sum
+			metrics[cloud.MetricNetworkIn] = *latest.Sum
 		}
 	}
 
-	netOutBytes := 0.0
 	if netOutErr == nil && netOutResult != nil && len(netOutResult.Datapoints) > 0 {
 		latest := netOutResult.Datapoints[0]
 		if latest.Sum != nil {
-			netOutBytes = *latest.Sum
+			metrics[cloud.MetricNetworkOut] = *latest.Sum
 		}
 	}
 
-	cpuUsage := 0.0
 	if cpuErr == nil && cpuResult != nil && len(cpuResult.Datapoints) > 0 {
 		latest := cpuResult.Datapoints[0]
 		if latest.Average != nil {
-			cpuUsage = *latest.Average
+			metrics[cloud.MetricCPUUsage] = *latest.Average
 		}
 	}
 
-	metrics := map[string]interface{}{
-		"cpu_usage":    cpuUsage,
-		"memory_usage": 0.0, // Memory metrics require custom CloudWatch agent
-		"network_in":   netInBytes,
-		"network_out":  netOutBytes,
-		"timestamp":    time.Now(),
+	// Without the agent there are no datapoints, and memory stays unknown
+	if memErr == nil && memResult != nil && len(memResult.Datapoints) > 0 {
+		latest := memResult.Datapoints[0]
+		if latest.Average != nil {
+			metrics[cloud.MetricMemoryUsage] = *latest.Average
+		}
 	}
 
 	return metrics, err
 }
 
-func (a *Adapter) resizeEC2Instance(ctx context.Context, instanceID string) (string, error) {
-	// This would involve stopping, modifying, and restarting
-	return fmt.Sprintf("Resized EC2 instance %s", instanceID), nil
-}
-
-// GetSpotPrice returns the current spot price for an instance type in a zone
+// GetSpotPrice returns the latest Linux spot price for an instance type in
+// a zone
 func (a *Adapter) GetSpotPrice(zone, instanceType string) (float64, error) {
-	// Mock implementation - in production, this would call AWS pricing API
-	prices := map[string]float64{
-		"us-east-1a:t3.micro":  0.0104,
-		"us-east-1a:t3.small":  0.0208,
-		"us-east-1a:t3.medium": 0.0416,
-		"us-east-1b:t3.micro":  0.0104,
-		"us-east-1b:t3.small":  0.0208,
-		"us-east-1b:t3.medium": 0.0416,
-	}
-
-	key := fmt.Sprintf("%s:%s", zone, instanceType)
-	if price, exists := prices[key]; exists {
-		return price, nil
+	ctx, cancel := context.WithTimeout(context.Background(), spotLookupTimeout)
+	defer cancel()
+	market, err := a.SpotMarket(ctx, zone, instanceType)
+	if err != nil {
+		return 0, err
 	}
-
-	// Default price if not found
-	return 0.0416, nil
+	return market.Price, nil
 }
 
-// ListZones returns available availability zones
+// ListZones returns the available zones of the configured region
 func (a *Adapter) ListZones() ([]string, error) {
-	// Mock implementation - in production, this would call AWS EC2 API
-	return []string{"us-east-1a", "us-east-1b", "us-east-1c"}, nil
+	ctx, cancel := context.WithTimeout(context.Background(), spotLookupTimeout)
+	defer cancel()
+	return a.availabilityZones(ctx)
 }
@@ -0,0 +1,612 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatchtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	elbv2types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	rdstypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+func TestEC2InstanceToResource_PartiallyPopulated(t *testing.T) {
+	// A spot instance still being provisioned: no state, launch time or tag values
+	instance := ec2types.Instance{
+		InstanceId:   aws.String("i-0123456789abcdef0"),
+		InstanceType: ec2types.InstanceTypeT3Medium,
+		Tags:         []ec2types.Tag{{Key: aws.String("team")}},
+	}
+
+	resource := ec2InstanceToResource(instance, "us-east-1")
+
+	assert.Equal(t, "i-0123456789abcdef0", resource.ID)
+	assert.Equal(t, unknownState, resource.State)
+	assert.True(t, resource.CreatedAt.IsZero())
+	assert.Zero(t, resource.CostPerMonth, "priced separately by the adapter")
+	assert.Empty(t, resource.Tags)
+}
+
+func TestRDSInstanceToResource_PartiallyPopulated(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	resource, ok := rdsInstanceToResource(rdstypes.DBInstance{
+		DBInstanceIdentifier: aws.String("db-creating"),
+		InstanceCreateTime:   &created,
+	}, "us-east-1")
+	require.True(t, ok)
+	assert.Equal(t, unknownState, resource.State)
+	assert.Equal(t, created, resource.CreatedAt)
+	assert.False(t, resource.EncryptionEnabled)
+	assert.False(t, resource.PubliclyAccessible)
+
+	_, ok = rdsInstanceToResource(rdstypes.DBInstance{}, "us-east-1")
+	assert.False(t, ok)
+}
+
+func TestApplyOptimization_RefusesProtectedResources(t *testing.T) {
+	// No SDK clients: any attempt to reach AWS would panic
+	adapter := &Adapter{cfg: cloud.CloudConfig{
+		Protection: cloud.ProtectionPolicy{
+			Tags:        map[string]string{"compliance": "*"},
+			ResourceIDs: []string{"payment-*"},
+		},
+	}}
+
+	cases := []*cloud.ResourceV2{
+		{ID: "i-tagged", CostPerMonth: 100, Tags: map[string]string{cloud.ProtectedTagKey: cloud.ProtectedTagValue}},
+		{ID: "i-pci", CostPerMonth: 100, Tags: map[string]string{"compliance": "pci"}},
+		{ID: "payment-api", CostPerMonth: 100},
+	}
+	for _, resource := range cases {
+		savings, err := adapter.ApplyOptimization(context.Background(), resource, "stop")
+		assert.ErrorIs(t, err, cloud.ErrResourceProtected, resource.ID)
+		assert.Zero(t, savings)
+	}
+
+	adapter.dryRun = true
+	savings, err := adapter.ApplyOptimization(context.Background(), &cloud.ResourceV2{ID: "i-free", Type: cloud.ResourceTypeEC2, CostPerMonth: 100}, "stop")
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, savings)
+}
+
+func TestApplyOptimization_RejectsInvalidActions(t *testing.T) {
+	adapter := &Adapter{dryRun: true}
+
+	db := &cloud.ResourceV2{ID: "db-1", Type: cloud.ResourceTypeRDS, State: "available", CostPerMonth: 200}
+	_, err := adapter.ApplyOptimization(context.Background(), db, "terminate")
+	assert.ErrorIs(t, err, cloud.ErrInvalidAction)
+
+	stopped := &cloud.ResourceV2{ID: "i-stopped", Type: cloud.ResourceTypeEC2, State: "stopped", CostPerMonth: 100}
+	_, err = adapter.ApplyOptimization(context.Background(), stopped, "stop")
+	assert.ErrorIs(t, err, cloud.ErrInvalidAction)
+}
+
+func TestEC2InstanceToResource_RecordsStopTime(t *testing.T) {
+	instance := ec2types.Instance{
+		InstanceId:            aws.String("i-stopped"),
+		State:                 &ec2types.InstanceState{Name: ec2types.InstanceStateNameStopped},
+		StateTransitionReason: aws.String("User initiated (2026-01-02 03:04:05 GMT)"),
+	}
+
+	resource := ec2InstanceToResource(instance, "us-east-1")
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), resource.Metadata[stoppedAtKey])
+}
+
+func TestApplyOptimization_TerminateSafeguards(t *testing.T) {
+	// No SDK clients: stopped instances are checked without calling AWS
+	adapter := &Adapter{dryRun: true, cfg: cloud.CloudConfig{
+		SavingsRatios: map[string]float64{"terminate": 0.5},
+		Termination: cloud.TerminationPolicy{
+			MinIdle:       7 * 24 * time.Hour,
+			MaxBackupAge:  7 * 24 * time.Hour,
+			SoftTerminate: true,
+			GracePeriod:   72 * time.Hour,
+		},
+	}}
+	now := time.Now().UTC()
+	stopped := func(stoppedFor time.Duration, tags map[string]string) *cloud.ResourceV2 {
+		return &cloud.ResourceV2{
+			ID: "i-idle", Type: cloud.ResourceTypeEC2, State: "stopped", CostPerMonth: 80, Tags: tags,
+			Metadata: map[string]interface{}{stoppedAtKey: now.Add(-stoppedFor)},
+		}
+	}
+	backedUp := func() map[string]string {
+		return map[string]string{cloud.DefaultBackupTagKey: now.Add(-time.Hour).Format(time.RFC3339)}
+	}
+
+	// Dry run reports the full monthly cost regardless of the configured ratio
+	savings, err := adapter.ApplyOptimization(context.Background(), stopped(30*24*time.Hour, backedUp()), "terminate")
+	require.NoError(t, err)
+	assert.Equal(t, 80.0, savings)
+
+	_, err = adapter.ApplyOptimization(context.Background(), stopped(30*24*time.Hour, nil), "terminate")
+	assert.ErrorIs(t, err, cloud.ErrTerminationRefused, "no backup tag")
+
+	_, err = adapter.ApplyOptimization(context.Background(), stopped(time.Hour, backedUp()), "terminate")
+	assert.ErrorIs(t, err, cloud.ErrTerminationRefused, "not idle long enough")
+
+	pending := backedUp()
+	pending[cloud.TerminateAfterTagKey] = now.Add(time.Hour).Format(time.RFC3339)
+	_, err = adapter.ApplyOptimization(context.Background(), stopped(time.Hour, pending), "terminate")
+	assert.ErrorIs(t, err, cloud.ErrTerminationPending, "inside grace period")
+}
+
+func TestLoadBalancerToResource(t *testing.T) {
+	arn := "arn:aws:elasticloadbalancing:us-east-1:123456789012:loadbalancer/app/web/50dc6c495c0c9188"
+
+	resource, ok := loadBalancerToResource(elbv2types.LoadBalancer{
+		LoadBalancerArn: aws.String(arn),
+		Type:            elbv2types.LoadBalancerTypeEnumApplication,
+	}, "us-east-1")
+	require.True(t, ok)
+	assert.Equal(t, cloud.ResourceTypeLoadBalancer, resource.Type)
+	assert.Equal(t, unknownState, resource.State)
+	assert.True(t, isLoadBalancerID(resource.ID))
+	assert.Equal(t, "app/web/50dc6c495c0c9188", loadBalancerDimension(arn))
+
+	_, ok = loadBalancerToResource(elbv2types.LoadBalancer{}, "us-east-1")
+	assert.False(t, ok)
+}
+
+func TestAddressToResource(t *testing.T) {
+	resource, ok := addressToResource(ec2types.Address{
+		AllocationId: aws.String("eipalloc-0123"),
+		PublicIp:     aws.String("203.0.113.10"),
+	}, "us-east-1")
+	require.True(t, ok)
+	assert.Equal(t, cloud.ResourceTypeElasticIP, resource.Type)
+	assert.Equal(t, addressUnassociated, resource.State)
+
+	resource, ok = addressToResource(ec2types.Address{
+		AllocationId:  aws.String("eipalloc-0456"),
+		AssociationId: aws.String("eipassoc-0456"),
+	}, "us-east-1")
+	require.True(t, ok)
+	assert.Equal(t, addressAssociated, resource.State)
+
+	_, ok = addressToResource(ec2types.Address{PublicIp: aws.String("203.0.113.11")}, "us-east-1")
+	assert.False(t, ok)
+}
+
+func TestUnattachedAddresses_ClockResetsOnAttach(t *testing.T) {
+	var tracker unattachedAddresses
+	start := time.Now()
+
+	since, ok := tracker.observe("eipalloc-1", false, start)
+	require.True(t, ok)
+	since, _ = tracker.observe("eipalloc-1", false, start.Add(time.Hour))
+	assert.Equal(t, start, since)
+
+	_, ok = tracker.observe("eipalloc-1", true, start.Add(2*time.Hour))
+	assert.False(t, ok)
+	since, _ = tracker.observe("eipalloc-1", false, start.Add(3*time.Hour))
+	assert.Equal(t, start.Add(3*time.Hour), since)
+}
+
+func TestApplyOptimization_ReleaseAddressRequiresIdle(t *testing.T) {
+	// No SDK clients: addresses are checked without calling AWS
+	adapter := &Adapter{dryRun: true, cfg: cloud.CloudConfig{Idle: cloud.IdlePolicy{MinIdle: 7 * 24 * time.Hour}}}
+	address := func(metadata map[string]interface{}) *cloud.ResourceV2 {
+		return &cloud.ResourceV2{ID: "eipalloc-1", Type: cloud.ResourceTypeElasticIP, State: addressUnassociated, CostPerMonth: 3.65, Metadata: metadata}
+	}
+
+	savings, err := adapter.ApplyOptimization(context.Background(),
+		address(map[string]interface{}{cloud.IdleSinceKey: time.Now().Add(-8 * 24 * time.Hour)}), "release_address")
+	require.NoError(t, err)
+	assert.Equal(t, 3.65, savings)
+
+	_, err = adapter.ApplyOptimization(context.Background(),
+		address(map[string]interface{}{cloud.IdleSinceKey: time.Now().Add(-time.Hour)}), "release_address")
+	assert.ErrorIs(t, err, cloud.ErrNotIdle)
+
+	_, err = adapter.ApplyOptimization(context.Background(), address(nil), "release_address")
+	assert.ErrorIs(t, err, cloud.ErrNotIdle)
+}
+
+type stubMetricsProvider struct {
+	metrics cloud.Metrics
+}
+
+func (s stubMetricsProvider) Name() string { return "stub" }
+
+func (s stubMetricsProvider) FetchMetrics(ctx context.Context, resource *cloud.ResourceV2) (cloud.Metrics, error) {
+	return s.metrics, nil
+}
+
+func TestResourceMetrics_ReplaceModeSkipsCloudWatch(t *testing.T) {
+	// No CloudWatch client: any attempt to reach AWS would panic
+	adapter := &Adapter{cfg: cloud.CloudConfig{
+		Metrics:     stubMetricsProvider{metrics: cloud.Metrics{cloud.MetricMemoryUsage: 63}},
+		MetricsMode: cloud.MetricsModeReplace,
+	}}
+
+	resource := &cloud.ResourceV2{ID: "i-0123456789abcdef0"}
+	metrics, err := adapter.resourceMetrics(context.Background(), resource)
+	require.NoError(t, err)
+	metrics.Apply(resource)
+	assert.Equal(t, 63.0, resource.MemoryUsage)
+}
+
+// fakeCloudWatch returns one average datapoint per "<namespace>/<metric>"
+type fakeCloudWatch struct {
+	averages map[string]float64
+}
+
+func (f fakeCloudWatch) GetMetricStatistics(_ context.Context, params *cloudwatch.GetMetricStatisticsInput, _ ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	output := &cloudwatch.GetMetricStatisticsOutput{}
+	if v, ok := f.averages[aws.ToString(params.Namespace)+"/"+aws.ToString(params.MetricName)]; ok {
+		output.Datapoints = []cloudwatchtypes.Datapoint{{Average: aws.Float64(v), Sum: aws.Float64(v)}}
+	}
+	return output, nil
+}
+
+func TestGetEC2Metrics_AgentMemory(t *testing.T) {
+	adapter := &Adapter{cwClient: fakeCloudWatch{averages: map[string]float64{
+		"AWS/EC2/CPUUtilization":   12,
+		"CWAgent/mem_used_percent": 71.5,
+	}}}
+
+	resource := &cloud.ResourceV2{ID: "i-agent"}
+	metrics, err := adapter.resourceMetrics(context.Background(), resource)
+	require.NoError(t, err)
+	metrics.Apply(resource)
+	assert.Equal(t, 12.0, resource.CPUUsage)
+	assert.Equal(t, 71.5, resource.MemoryUsage)
+	assert.True(t, resource.MemoryMetricAvailable)
+}
+
+func TestGetEC2Metrics_NoAgent(t *testing.T) {
+	adapter := &Adapter{cwClient: fakeCloudWatch{averages: map[string]float64{
+		"AWS/EC2/CPUUtilization": 12,
+	}}}
+
+	resource := &cloud.ResourceV2{ID: "i-no-agent"}
+	metrics, err := adapter.resourceMetrics(context.Background(), resource)
+	require.NoError(t, err)
+	assert.NotContains(t, metrics, cloud.MetricMemoryUsage)
+	metrics.Apply(resource)
+	assert.Zero(t, resource.MemoryUsage)
+	assert.False(t, resource.MemoryMetricAvailable)
+	assert.False(t, resource.MemoryKnown())
+}
+
+// countingCloudWatch counts the metric queries made through it, which
+// getEC2Metrics makes concurrently
+type countingCloudWatch struct {
+	fakeCloudWatch
+	calls atomic.Int32
+}
+
+func (c *countingCloudWatch) GetMetricStatistics(ctx context.Context, params *cloudwatch.GetMetricStatisticsInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	c.calls.Add(1)
+	return c.fakeCloudWatch.GetMetricStatistics(ctx, params, optFns...)
+}
+
+func TestGetResourceMetadata_SkipsCloudWatch(t *testing.T) {
+	cw := &countingCloudWatch{fakeCloudWatch: fakeCloudWatch{averages: map[string]float64{"AWS/EC2/CPUUtilization": 42}}}
+	adapter := &Adapter{
+		ec2Client: &fakeEC2{instance: ec2types.Instance{
+			InstanceId:   aws.String("i-meta"),
+			InstanceType: ec2types.InstanceTypeT3Micro,
+			State:        &ec2types.InstanceState{Name: ec2types.InstanceStateNameRunning},
+			Tags:         []ec2types.Tag{{Key: aws.String("team"), Value: aws.String("web")}},
+		}},
+		cwClient: cw,
+		region:   "us-east-1",
+	}
+
+	resource, err := adapter.GetResourceMetadata(context.Background(), "i-meta")
+	require.NoError(t, err)
+	assert.Zero(t, cw.calls.Load())
+	assert.Equal(t, "running", resource.State)
+	assert.Equal(t, "web", resource.Tags["team"])
+	assert.Zero(t, resource.CPUUsage)
+
+	// The full lookup still reads utilization
+	resource, err = adapter.GetResource(context.Background(), "i-meta")
+	require.NoError(t, err)
+	assert.Positive(t, cw.calls.Load())
+	assert.Equal(t, 42.0, resource.CPUUsage)
+}
+
+// batchEC2 describes a running instance for every requested ID, except
+// missing ones, and records the IDs each DescribeInstances call asked for
+type batchEC2 struct {
+	ec2API
+	missing map[string]bool
+	calls   [][]string
+}
+
+func (f *batchEC2) DescribeInstances(_ context.Context, params *ec2.DescribeInstancesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	ids := params.InstanceIds
+	if len(params.Filters) > 0 {
+		ids = params.Filters[0].Values
+	}
+	f.calls = append(f.calls, ids)
+
+	var instances []ec2types.Instance
+	for _, id := range ids {
+		if f.missing[id] {
+			if len(params.InstanceIds) > 0 {
+				return nil, &smithy.GenericAPIError{Code: "InvalidInstanceID.NotFound", Message: "The instance ID '" + id + "' does not exist"}
+			}
+			continue
+		}
+		instances = append(instances, ec2types.Instance{
+			InstanceId: aws.String(id),
+			State:      &ec2types.InstanceState{Name: ec2types.InstanceStateNameRunning},
+		})
+	}
+	return &ec2.DescribeInstancesOutput{Reservations: []ec2types.Reservation{{Instances: instances}}}, nil
+}
+
+func instanceIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("i-%05d", i)
+	}
+	return ids
+}
+
+func TestGetResources_BatchesDescribeInstances(t *testing.T) {
+	for _, n := range []int{0, 1, describeInstancesBatchSize, describeInstancesBatchSize + 1, 2*describeInstancesBatchSize + 50} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			ec2Client, cw := &batchEC2{}, &countingCloudWatch{}
+			adapter := &Adapter{ec2Client: ec2Client, cwClient: cw, region: "us-east-1"}
+
+			resources, err := adapter.GetResources(context.Background(), instanceIDs(n))
+			require.NoError(t, err)
+			assert.Len(t, resources, n)
+			assert.Len(t, ec2Client.calls, (n+describeInstancesBatchSize-1)/describeInstancesBatchSize)
+			for _, ids := range ec2Client.calls {
+				assert.LessOrEqual(t, len(ids), describeInstancesBatchSize)
+			}
+			assert.Zero(t, cw.calls.Load())
+		})
+	}
+}
+
+func TestGetResources_SkipsMissingInstances(t *testing.T) {
+	ec2Client := &batchEC2{missing: map[string]bool{"i-00001": true}}
+	adapter := &Adapter{ec2Client: ec2Client, region: "us-east-1"}
+
+	resources, err := adapter.GetResources(context.Background(), instanceIDs(3))
+	require.NoError(t, err)
+	assert.Len(t, resources, 2)
+	assert.NotContains(t, resources, "i-00001")
+	assert.Equal(t, "running", resources["i-00002"].State)
+	// The batch naming the missing instance is retried with a filter
+	assert.Len(t, ec2Client.calls, 2)
+}
+
+func TestVolumeToResource(t *testing.T) {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	resource, ok := volumeToResource(ec2types.Volume{
+		VolumeId:   aws.String("vol-0123"),
+		VolumeType: ec2types.VolumeTypeGp3,
+		Size:       aws.Int32(500),
+		Iops:       aws.Int32(4000),
+		State:      ec2types.VolumeStateAvailable,
+		CreateTime: &created,
+		Encrypted:  aws.Bool(true),
+		Tags:       []ec2types.Tag{{Key: aws.String("team"), Value: aws.String("data")}},
+	}, "us-east-1")
+	require.True(t, ok)
+	assert.Equal(t, cloud.ResourceTypeEBS, resource.Type)
+	assert.Equal(t, "available", resource.State)
+	assert.Equal(t, 45.0, resource.CostPerMonth, "500 GB at $0.08 plus 1000 IOPS above baseline")
+	assert.Equal(t, true, resource.Metadata[unattachedKey])
+	assert.True(t, resource.EncryptionEnabled)
+	assert.Equal(t, "data", resource.Tags["team"])
+
+	attached, ok := volumeToResource(ec2types.Volume{
+		VolumeId:    aws.String("vol-0456"),
+		VolumeType:  ec2types.VolumeTypeIo2,
+		Size:        aws.Int32(100),
+		Iops:        aws.Int32(1000),
+		State:       ec2types.VolumeStateInUse,
+		Attachments: []ec2types.VolumeAttachment{{InstanceId: aws.String("i-1")}},
+	}, "us-east-1")
+	require.True(t, ok)
+	assert.Equal(t, 77.5, attached.CostPerMonth)
+	assert.Equal(t, false, attached.Metadata[unattachedKey])
+
+	_, ok = volumeToResource(ec2types.Volume{}, "us-east-1")
+	assert.False(t, ok)
+}
+
+// deletingEC2 records the destructive calls made to it
+type deletingEC2 struct {
+	ec2API
+	deleted []string
+}
+
+func (f *deletingEC2) TerminateInstances(_ context.Context, params *ec2.TerminateInstancesInput, _ ...func(*ec2.Options)) (*ec2.TerminateInstancesOutput, error) {
+	f.deleted = append(f.deleted, params.InstanceIds...)
+	return &ec2.TerminateInstancesOutput{}, nil
+}
+
+func (f *deletingEC2) DeleteVolume(_ context.Context, params *ec2.DeleteVolumeInput, _ ...func(*ec2.Options)) (*ec2.DeleteVolumeOutput, error) {
+	f.deleted = append(f.deleted, aws.ToString(params.VolumeId))
+	return &ec2.DeleteVolumeOutput{}, nil
+}
+
+func (f *deletingEC2) ReleaseAddress(_ context.Context, params *ec2.ReleaseAddressInput, _ ...func(*ec2.Options)) (*ec2.ReleaseAddressOutput, error) {
+	f.deleted = append(f.deleted, aws.ToString(params.AllocationId))
+	return &ec2.ReleaseAddressOutput{}, nil
+}
+
+func TestApplyOptimization_TerminateSkipsProtectedResources(t *testing.T) {
+	now := time.Now().UTC()
+	idleFor := 30 * 24 * time.Hour
+	backedUp := func(tags map[string]string) map[string]string {
+		tags[cloud.DefaultBackupTagKey] = now.Add(-time.Hour).Format(time.RFC3339)
+		return tags
+	}
+	resources := []struct {
+		resource  *cloud.ResourceV2
+		protected bool
+	}{
+		{&cloud.ResourceV2{ID: "i-prod", Type: cloud.ResourceTypeEC2, State: "stopped",
+			Tags: backedUp(map[string]string{"environment": "production"}), Metadata: map[string]interface{}{stoppedAtKey: now.Add(-idleFor)}}, true},
+		{&cloud.ResourceV2{ID: "i-dev", Type: cloud.ResourceTypeEC2, State: "stopped",
+			Tags: backedUp(map[string]string{"environment": "dev"}), Metadata: map[string]interface{}{stoppedAtKey: now.Add(-idleFor)}}, false},
+		{&cloud.ResourceV2{ID: "vol-prod", Type: cloud.ResourceTypeEBS, State: "available",
+			Tags: map[string]string{"env": "Prod"}, Metadata: map[string]interface{}{cloud.IdleSinceKey: now.Add(-idleFor)}}, true},
+		{&cloud.ResourceV2{ID: "vol-scratch", Type: cloud.ResourceTypeEBS, State: "available",
+			Metadata: map[string]interface{}{cloud.IdleSinceKey: now.Add(-idleFor)}}, false},
+		{&cloud.ResourceV2{ID: "eipalloc-payment-gw", Type: cloud.ResourceTypeElasticIP, State: addressUnassociated,
+			Metadata: map[string]interface{}{cloud.IdleSinceKey: now.Add(-idleFor)}}, true},
+		{&cloud.ResourceV2{ID: "eipalloc-spare", Type: cloud.ResourceTypeElasticIP, State: addressUnassociated,
+			Metadata: map[string]interface{}{cloud.IdleSinceKey: now.Add(-idleFor)}}, false},
+	}
+
+	client := &deletingEC2{}
+	adapter := &Adapter{ec2Client: client, cfg: cloud.CloudConfig{
+		Idle: cloud.IdlePolicy{MinIdle: 7 * 24 * time.Hour},
+		Termination: cloud.TerminationPolicy{
+			MinIdle:            7 * 24 * time.Hour,
+			MaxBackupAge:       7 * 24 * time.Hour,
+			ProtectedResources: []string{"eipalloc-payment-*"},
+		},
+	}}
+	for _, tc := range resources {
+		_, err := adapter.ApplyOptimization(context.Background(), tc.resource, "terminate")
+		if tc.protected {
+			assert.ErrorIs(t, err, cloud.ErrTerminationRefused, tc.resource.ID)
+		} else {
+			assert.NoError(t, err, tc.resource.ID)
+		}
+	}
+	assert.Equal(t, []string{"i-dev", "vol-scratch", "eipalloc-spare"}, client.deleted)
+}
+
+// untaggingEC2 records the tags deleted through it as "<resource>:<key>"
+type untaggingEC2 struct {
+	deletingEC2
+	untagged []string
+}
+
+func (f *untaggingEC2) DeleteTags(_ context.Context, params *ec2.DeleteTagsInput, _ ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error) {
+	for _, tag := range params.Tags {
+		f.untagged = append(f.untagged, params.Resources[0]+":"+aws.ToString(tag.Key))
+	}
+	return &ec2.DeleteTagsOutput{}, nil
+}
+
+// peakCloudWatch returns one maximum datapoint for every query
+type peakCloudWatch struct {
+	peak float64
+}
+
+func (f peakCloudWatch) GetMetricStatistics(context.Context, *cloudwatch.GetMetricStatisticsInput, ...func(*cloudwatch.Options)) (*cloudwatch.GetMetricStatisticsOutput, error) {
+	return &cloudwatch.GetMetricStatisticsOutput{Datapoints: []cloudwatchtypes.Datapoint{{Maximum: aws.Float64(f.peak)}}}, nil
+}
+
+func TestApplyOptimization_SoftTerminateRechecksAfterGracePeriod(t *testing.T) {
+	now := time.Now().UTC()
+	grace := 72 * time.Hour
+	taggedAt := now.Add(-grace - time.Hour)
+	pending := func(state string, stoppedAt time.Time) *cloud.ResourceV2 {
+		resource := &cloud.ResourceV2{ID: "i-idle", Type: cloud.ResourceTypeEC2, State: state, Metadata: map[string]interface{}{}, Tags: map[string]string{
+			cloud.DefaultBackupTagKey:  now.Add(-time.Hour).Format(time.RFC3339),
+			cloud.TerminateAfterTagKey: taggedAt.Add(grace).Format(time.RFC3339),
+		}}
+		if !stoppedAt.IsZero() {
+			resource.Metadata[stoppedAtKey] = stoppedAt
+		}
+		return resource
+	}
+	newAdapter := func(peak float64) (*Adapter, *untaggingEC2) {
+		client := &untaggingEC2{}
+		return &Adapter{ec2Client: client, cwClient: peakCloudWatch{peak: peak}, cfg: cloud.CloudConfig{
+			Termination: cloud.TerminationPolicy{
+				MinIdle:          7 * 24 * time.Hour,
+				IdleCPUThreshold: 5,
+				MaxBackupAge:     7 * 24 * time.Hour,
+				SoftTerminate:    true,
+				GracePeriod:      grace,
+			},
+		}}, client
+	}
+
+	// Stopped when it was tagged and idle since: terminated
+	adapter, client := newAdapter(1)
+	_, err := adapter.ApplyOptimization(context.Background(), pending("stopped", taggedAt.Add(time.Minute)), "terminate")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"i-idle"}, client.deleted)
+	assert.Empty(t, client.untagged)
+
+	// Restarted by its owner during the grace period: cancelled
+	adapter, client = newAdapter(1)
+	_, err = adapter.ApplyOptimization(context.Background(), pending("running", time.Time{}), "terminate")
+	assert.ErrorIs(t, err, cloud.ErrTerminationRefused)
+	assert.Empty(t, client.deleted)
+	assert.Equal(t, []string{"i-idle:" + cloud.TerminateAfterTagKey}, client.untagged)
+
+	// Restarted, used and stopped again before the grace period ended: cancelled
+	adapter, client = newAdapter(60)
+	_, err = adapter.ApplyOptimization(context.Background(), pending("stopped", now.Add(-time.Hour)), "terminate")
+	assert.ErrorIs(t, err, cloud.ErrTerminationRefused)
+	assert.Empty(t, client.deleted)
+	assert.Equal(t, []string{"i-idle:" + cloud.TerminateAfterTagKey}, client.untagged)
+
+	// Dry run refuses without touching the tag
+	adapter, client = newAdapter(1)
+	adapter.dryRun = true
+	_, err = adapter.ApplyOptimization(context.Background(), pending("running", time.Time{}), "terminate")
+	assert.ErrorIs(t, err, cloud.ErrTerminationRefused)
+	assert.Empty(t, client.untagged)
+}
+
+func TestApplyOptimization_DeleteVolumeRequiresIdle(t *testing.T) {
+	// No SDK clients: volumes are checked without calling AWS
+	adapter := &Adapter{dryRun: true, cfg: cloud.CloudConfig{Idle: cloud.IdlePolicy{MinIdle: 7 * 24 * time.Hour}}}
+	volume := func(metadata map[string]interface{}) *cloud.ResourceV2 {
+		return &cloud.ResourceV2{ID: "vol-0123", Type: cloud.ResourceTypeEBS, State: "available", CostPerMonth: 40, Metadata: metadata}
+	}
+
+	savings, err := adapter.ApplyOptimization(context.Background(),
+		volume(map[string]interface{}{cloud.IdleSinceKey: time.Now().Add(-8 * 24 * time.Hour)}), "delete_volume")
+	require.NoError(t, err)
+	assert.Equal(t, 40.0, savings)
+
+	_, err = adapter.ApplyOptimization(context.Background(), volume(nil), "delete_volume")
+	assert.ErrorIs(t, err, cloud.ErrNotIdle)
+
+	_, err = adapter.ApplyOptimization(context.Background(), volume(nil), "stop")
+	assert.ErrorIs(t, err, cloud.ErrInvalidAction)
+}
+
+func TestApplyOptimization_TerminateDefaultsMinIdle(t *testing.T) {
+	// No SDK clients: stopped instances are checked without calling AWS
+	adapter := &Adapter{dryRun: true, cfg: cloud.CloudConfig{Termination: cloud.TerminationPolicy{}}}
+	now := time.Now().UTC()
+	stopped := func(stoppedFor time.Duration) *cloud.ResourceV2 {
+		return &cloud.ResourceV2{
+			ID: "i-idle", Type: cloud.ResourceTypeEC2, State: "stopped", CostPerMonth: 80,
+			Tags:     map[string]string{cloud.DefaultBackupTagKey: now.Add(-time.Hour).Format(time.RFC3339)},
+			Metadata: map[string]interface{}{stoppedAtKey: now.Add(-stoppedFor)},
+		}
+	}
+
+	_, err := adapter.ApplyOptimization(context.Background(), stopped(24*time.Hour), "terminate")
+	assert.ErrorIs(t, err, cloud.ErrTerminationRefused, "an unset MinIdle falls back to DefaultMinIdle")
+
+	savings, err := adapter.ApplyOptimization(context.Background(), stopped(cloud.DefaultMinIdle+time.Hour), "terminate")
+	require.NoError(t, err)
+	assert.Equal(t, 80.0, savings)
+}
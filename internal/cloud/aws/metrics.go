@@ -0,0 +1,43 @@
+package aws
+
+import (
+	"context"
+	"errors"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+// apiMetricsMiddleware counts every call the SDK clients make in metrics.
+// It sits at the start of the stack, so a call is counted once, with the
+// outcome after retries.
+func apiMetricsMiddleware(metrics *cloud.APIMetrics) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("TalosAPIMetrics",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				metrics.Record("aws", awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx), apiCallOutcome(err))
+				return out, metadata, err
+			},
+		), middleware.After)
+	}
+}
+
+// apiCallOutcome classifies an API call's error as one of the cloud.APICall
+// outcomes
+func apiCallOutcome(err error) string {
+	if err == nil {
+		return cloud.APICallSuccess
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		if _, ok := retry.DefaultThrottleErrorCodes[apiErr.ErrorCode()]; ok {
+			return cloud.APICallThrottled
+		}
+	}
+	return cloud.APICallError
+}
@@ -0,0 +1,58 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+func TestAPIMetricsMiddleware_CountsCallsByOutcome(t *testing.T) {
+	var throttle atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/xml")
+		if throttle.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`<Response><Errors><Error><Code>RequestLimitExceeded</Code><Message>Request limit exceeded.</Message></Error></Errors><RequestID>r-2</RequestID></Response>`))
+			return
+		}
+		w.Write([]byte(`<DescribeAvailabilityZonesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><requestId>r-1</requestId><availabilityZoneInfo/></DescribeAvailabilityZonesResponse>`))
+	}))
+	defer server.Close()
+
+	metrics := cloud.NewAPIMetrics()
+	require.NoError(t, metrics.Register(prometheus.NewRegistry()))
+	client := ec2.NewFromConfig(aws.Config{
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		BaseEndpoint: aws.String(server.URL),
+		HTTPClient:   server.Client(),
+		Retryer:      func() aws.Retryer { return aws.NopRetryer{} },
+		APIOptions:   []func(*middleware.Stack) error{apiMetricsMiddleware(metrics)},
+	})
+
+	_, err := client.DescribeAvailabilityZones(context.Background(), &ec2.DescribeAvailabilityZonesInput{})
+	require.NoError(t, err)
+	throttle.Store(true)
+	_, err = client.DescribeAvailabilityZones(context.Background(), &ec2.DescribeAvailabilityZonesInput{})
+	require.Error(t, err)
+
+	calls := func(outcome string) float64 {
+		return testutil.ToFloat64(metrics.Calls("aws", "EC2", "DescribeAvailabilityZones", outcome))
+	}
+	assert.Equal(t, 1.0, calls(cloud.APICallSuccess))
+	assert.Equal(t, 1.0, calls(cloud.APICallThrottled))
+	assert.Equal(t, 0.0, calls(cloud.APICallError))
+}
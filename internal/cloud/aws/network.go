@@ -0,0 +1,398 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatchtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	elbv2types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+// Monthly list prices used until pricing-backed estimates are available
+const (
+	loadBalancerMonthlyCost = 16.43 // ALB/NLB hourly charge, excluding capacity units
+	elasticIPMonthlyCost    = 3.65  // public IPv4 address
+)
+
+// maxTagDescriptions is the most load balancers DescribeTags accepts at once
+const maxTagDescriptions = 20
+
+// Elastic IP states
+const (
+	addressAssociated   = "associated"
+	addressUnassociated = "unassociated"
+)
+
+// unattachedAddresses remembers when each Elastic IP or EBS volume was first
+// seen unattached, since AWS doesn't record when it was disassociated.
+// The clock restarts with the process, which only delays a release.
+type unattachedAddresses struct {
+	mu    sync.Mutex
+	since map[string]time.Time
+}
+
+// observe records whether an address is attached and returns when it was
+// first seen unattached
+func (u *unattachedAddresses) observe(id string, attached bool, now time.Time) (time.Time, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if attached {
+		delete(u.since, id)
+		return time.Time{}, false
+	}
+	if u.since == nil {
+		u.since = make(map[string]time.Time)
+	}
+	since, ok := u.since[id]
+	if !ok {
+		since = now
+		u.since[id] = since
+	}
+	return since, true
+}
+
+// isLoadBalancerID reports whether a resource ID is a load balancer ARN
+func isLoadBalancerID(id string) bool {
+	return strings.HasPrefix(id, "arn:") && strings.Contains(id, ":loadbalancer/")
+}
+
+// isElasticIPID reports whether a resource ID is an Elastic IP allocation ID
+func isElasticIPID(id string) bool {
+	return strings.HasPrefix(id, "eipalloc-")
+}
+
+func (a *Adapter) fetchLoadBalancers(ctx context.Context) ([]*cloud.ResourceV2, error) {
+	paginator := elbv2.NewDescribeLoadBalancersPaginator(a.elbClient, &elbv2.DescribeLoadBalancersInput{})
+
+	var resources []*cloud.ResourceV2
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe load balancers: %w", err)
+		}
+		for _, lb := range output.LoadBalancers {
+			resource, ok := loadBalancerToResource(lb, a.region)
+			if !ok {
+				log.Printf("skipping load balancer without an ARN (name %q)", aws.ToString(lb.LoadBalancerName))
+				continue
+			}
+			resources = append(resources, resource)
+		}
+	}
+
+	// Tags drive the protection policy, so untagged results are not returned
+	if err := a.tagLoadBalancers(ctx, resources); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, resource := range resources {
+		if err := a.markIdleLoadBalancer(ctx, resource, now); err != nil {
+			log.Printf("failed to get traffic for load balancer %s: %v", resource.ID, err)
+		}
+	}
+	return resources, nil
+}
+
+// getLoadBalancer retrieves one load balancer by ARN
+func (a *Adapter) getLoadBalancer(ctx context.Context, arn string) (*cloud.ResourceV2, error) {
+	output, err := a.elbClient.DescribeLoadBalancers(ctx, &elbv2.DescribeLoadBalancersInput{
+		LoadBalancerArns: []string{arn},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe load balancer %s: %w", arn, err)
+	}
+	if len(output.LoadBalancers) == 0 {
+		return nil, fmt.Errorf("resource %s not found", arn)
+	}
+
+	resource, ok := loadBalancerToResource(output.LoadBalancers[0], a.region)
+	if !ok {
+		return nil, fmt.Errorf("resource %s not found", arn)
+	}
+	if err := a.tagLoadBalancers(ctx, []*cloud.ResourceV2{resource}); err != nil {
+		return nil, err
+	}
+	if err := a.markIdleLoadBalancer(ctx, resource, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to get traffic for %s: %w", arn, err)
+	}
+	return resource, nil
+}
+
+// tagLoadBalancers fills in load balancer tags, which DescribeLoadBalancers
+// doesn't return
+func (a *Adapter) tagLoadBalancers(ctx context.Context, resources []*cloud.ResourceV2) error {
+	byARN := make(map[string]*cloud.ResourceV2, len(resources))
+	for _, resource := range resources {
+		byARN[resource.ID] = resource
+	}
+
+	for start := 0; start < len(resources); start += maxTagDescriptions {
+		end := min(start+maxTagDescriptions, len(resources))
+		arns := make([]string, 0, end-start)
+		for _, resource := range resources[start:end] {
+			arns = append(arns, resource.ID)
+		}
+
+		output, err := a.elbClient.DescribeTags(ctx, &elbv2.DescribeTagsInput{ResourceArns: arns})
+		if err != nil {
+			return fmt.Errorf("failed to describe load balancer tags: %w", err)
+		}
+		for _, description := range output.TagDescriptions {
+			resource, ok := byARN[aws.ToString(description.ResourceArn)]
+			if !ok {
+				continue
+			}
+			for _, tag := range description.Tags {
+				if tag.Key != nil && tag.Value != nil {
+					resource.Tags[*tag.Key] = *tag.Value
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// loadBalancerToResource converts an SDK load balancer to the canonical
+// model. It returns false if the load balancer has no ARN.
+func loadBalancerToResource(lb elbv2types.LoadBalancer, region string) (*cloud.ResourceV2, bool) {
+	arn := aws.ToString(lb.LoadBalancerArn)
+	if arn == "" {
+		return nil, false
+	}
+
+	state := unknownState
+	if lb.State != nil && lb.State.Code != "" {
+		state = string(lb.State.Code)
+	}
+	if lb.CreatedTime == nil {
+		log.Printf("load balancer %s has no create time; using zero time", arn)
+	}
+
+	return &cloud.ResourceV2{
+		ID:           arn,
+		Type:         cloud.ResourceTypeLoadBalancer,
+		Provider:     cloud.ProviderAWS,
+		Region:       region,
+		Tags:         make(map[string]string),
+		State:        state,
+		CreatedAt:    aws.ToTime(lb.CreatedTime),
+		CostPerMonth: loadBalancerMonthlyCost,
+		Metadata: map[string]interface{}{
+			"name":               aws.ToString(lb.LoadBalancerName),
+			"load_balancer_type": string(lb.Type),
+			"dns_name":           aws.ToString(lb.DNSName),
+		},
+	}, true
+}
+
+// trafficMetric returns the CloudWatch namespace and metric counting a load
+// balancer's traffic, and false for types whose traffic isn't tracked
+func trafficMetric(lbType string) (string, string, bool) {
+	switch elbv2types.LoadBalancerTypeEnum(lbType) {
+	case elbv2types.LoadBalancerTypeEnumApplication:
+		return "AWS/ApplicationELB", "RequestCount", true
+	case elbv2types.LoadBalancerTypeEnumNetwork:
+		return "AWS/NetworkELB", "NewFlowCount", true
+	default:
+		return "", "", false
+	}
+}
+
+func tracksTraffic(lbType string) bool {
+	_, _, ok := trafficMetric(lbType)
+	return ok
+}
+
+// loadBalancerDimension returns the CloudWatch LoadBalancer dimension for an
+// ARN, e.g. "app/my-alb/50dc6c495c0c9188"
+func loadBalancerDimension(arn string) string {
+	const marker = ":loadbalancer/"
+	if i := strings.Index(arn, marker); i >= 0 {
+		return arn[i+len(marker):]
+	}
+	return arn
+}
+
+// markIdleLoadBalancer sets cloud.IdleSinceKey on a load balancer that has
+// existed for the idle threshold and served no traffic in that time
+func (a *Adapter) markIdleLoadBalancer(ctx context.Context, resource *cloud.ResourceV2, now time.Time) error {
+	threshold := a.cfg.Idle.Threshold()
+	start := now.Add(-threshold)
+	if resource.State != string(elbv2types.LoadBalancerStateEnumActive) || resource.CreatedAt.After(start) {
+		return nil
+	}
+	// Gateway load balancers have no traffic count and are never cleaned up
+	if lbType, _ := resource.Metadata["load_balancer_type"].(string); !tracksTraffic(lbType) {
+		return nil
+	}
+
+	traffic, err := a.loadBalancerTraffic(ctx, resource, start, now)
+	if err != nil {
+		return err
+	}
+	if traffic == 0 {
+		resource.Metadata[cloud.IdleSinceKey] = start
+	}
+	return nil
+}
+
+// loadBalancerTraffic returns the requests (ALB) or new flows (NLB) a load
+// balancer served between start and end. CloudWatch reports no datapoints
+// for a load balancer without traffic, so none counts as zero.
+func (a *Adapter) loadBalancerTraffic(ctx context.Context, resource *cloud.ResourceV2, start, end time.Time) (float64, error) {
+	lbType, _ := resource.Metadata["load_balancer_type"].(string)
+	namespace, metric, ok := trafficMetric(lbType)
+	if !ok {
+		return 0, fmt.Errorf("traffic of %s load balancers is not tracked", lbType)
+	}
+
+	result, err := a.cwClient.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String(namespace),
+		MetricName: aws.String(metric),
+		Dimensions: []cloudwatchtypes.Dimension{{Name: aws.String("LoadBalancer"), Value: aws.String(loadBalancerDimension(resource.ID))}},
+		StartTime:  aws.Time(start),
+		EndTime:    aws.Time(end),
+		Period:     aws.Int32(statisticsPeriod(start, end)),
+		Statistics: []cloudwatchtypes.Statistic{cloudwatchtypes.StatisticSum},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var total float64
+	for _, point := range result.Datapoints {
+		if point.Sum != nil {
+			total += *point.Sum
+		}
+	}
+	return total, nil
+}
+
+func (a *Adapter) fetchElasticIPs(ctx context.Context) ([]*cloud.ResourceV2, error) {
+	return a.describeAddresses(ctx, &ec2.DescribeAddressesInput{})
+}
+
+// getElasticIP retrieves one Elastic IP by allocation ID
+func (a *Adapter) getElasticIP(ctx context.Context, allocationID string) (*cloud.ResourceV2, error) {
+	resources, err := a.describeAddresses(ctx, &ec2.DescribeAddressesInput{AllocationIds: []string{allocationID}})
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("resource %s not found", allocationID)
+	}
+	return resources[0], nil
+}
+
+func (a *Adapter) describeAddresses(ctx context.Context, input *ec2.DescribeAddressesInput) ([]*cloud.ResourceV2, error) {
+	output, err := a.ec2Client.DescribeAddresses(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe addresses: %w", err)
+	}
+
+	now := time.Now()
+	threshold := a.cfg.Idle.Threshold()
+	var resources []*cloud.ResourceV2
+	for _, address := range output.Addresses {
+		resource, ok := addressToResource(address, a.region)
+		if !ok {
+			log.Printf("skipping Elastic IP %s without an allocation ID", aws.ToString(address.PublicIp))
+			continue
+		}
+		since, unattached := a.unattached.observe(resource.ID, resource.State == addressAssociated, now)
+		if unattached && now.Sub(since) >= threshold {
+			resource.Metadata[cloud.IdleSinceKey] = since
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+// addressToResource converts an SDK Elastic IP to the canonical model. It
+// returns false if the address has no allocation ID and cannot be released.
+func addressToResource(address ec2types.Address, region string) (*cloud.ResourceV2, bool) {
+	id := aws.ToString(address.AllocationId)
+	if id == "" {
+		return nil, false
+	}
+
+	state := addressUnassociated
+	if aws.ToString(address.AssociationId) != "" || aws.ToString(address.InstanceId) != "" {
+		state = addressAssociated
+	}
+
+	resource := &cloud.ResourceV2{
+		ID:                 id,
+		Type:               cloud.ResourceTypeElasticIP,
+		Provider:           cloud.ProviderAWS,
+		Region:             region,
+		Tags:               make(map[string]string, len(address.Tags)),
+		State:              state,
+		CostPerMonth:       elasticIPMonthlyCost,
+		PubliclyAccessible: true,
+		Metadata:           map[string]interface{}{"public_ip": aws.ToString(address.PublicIp)},
+	}
+	for _, tag := range address.Tags {
+		if tag.Key != nil && tag.Value != nil {
+			resource.Tags[*tag.Key] = *tag.Value
+		}
+	}
+	return resource, true
+}
+
+// checkIdleCleanup applies the idle safeguard before a load balancer or
+// volume is deleted or an address released. Load balancers are checked for traffic
+// again since the resource may come from a cache.
+func (a *Adapter) checkIdleCleanup(ctx context.Context, resource *cloud.ResourceV2, now time.Time) error {
+	if err := a.cfg.Idle.CheckIdle(resource, now); err != nil {
+		return err
+	}
+	if resource.Type != cloud.ResourceTypeLoadBalancer {
+		return nil
+	}
+
+	since, _ := cloud.IdleSince(resource)
+	traffic, err := a.loadBalancerTraffic(ctx, resource, since, now)
+	if err != nil {
+		return fmt.Errorf("%w: traffic check for %s failed: %v", cloud.ErrNotIdle, resource.ID, err)
+	}
+	if traffic > 0 {
+		return fmt.Errorf("%w: %s served %.0f requests since %s", cloud.ErrNotIdle, resource.ID, traffic, since.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func (a *Adapter) deleteLoadBalancer(ctx context.Context, arn string) (string, error) {
+	_, err := a.elbClient.DeleteLoadBalancer(ctx, &elbv2.DeleteLoadBalancerInput{
+		LoadBalancerArn: aws.String(arn),
+	})
+	if err != nil {
+		return "", err
+	}
+	log.Printf("deleted idle load balancer %s", arn)
+	return fmt.Sprintf("Deleted load balancer %s", arn), nil
+}
+
+func (a *Adapter) releaseAddress(ctx context.Context, allocationID string) (string, error) {
+	_, err := a.ec2Client.ReleaseAddress(ctx, &ec2.ReleaseAddressInput{
+		AllocationId: aws.String(allocationID),
+	})
+	if err != nil {
+		return "", err
+	}
+	log.Printf("released unattached Elastic IP %s", allocationID)
+	return fmt.Sprintf("Released Elastic IP %s", allocationID), nil
+}
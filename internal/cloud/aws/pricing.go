@@ -0,0 +1,173 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	pricingtypes "github.com/aws/aws-sdk-go-v2/service/pricing/types"
+)
+
+// hoursPerMonth converts hourly list prices to the monthly costs the
+// optimizer works with
+const hoursPerMonth = 730
+
+// pricingRegion hosts the Price List API endpoint, which serves prices for
+// every region
+const pricingRegion = "us-east-1"
+
+// DefaultPriceTTL is how long a looked-up price is reused. List prices
+// change a few times a year at most.
+const DefaultPriceTTL = 24 * time.Hour
+
+// staticInstancePricing provides a rough cost estimate per month for
+// instance types. It is only used when the Price List API can't be reached.
+var staticInstancePricing = map[string]float64{
+	"t2.micro":   10.0,
+	"t3.medium":  40.0,
+	"m5.large":   80.0,
+	"m5.2xlarge": 320.0,
+}
+
+// Pricer looks up the cost of running an instance type
+type Pricer interface {
+	// InstanceMonthlyCost returns the on-demand monthly cost of a Linux
+	// instance type in a region
+	InstanceMonthlyCost(ctx context.Context, instanceType, region string) (float64, error)
+}
+
+// pricingAPI is the part of the Price List API the pricer uses
+type pricingAPI interface {
+	GetProducts(ctx context.Context, params *pricing.GetProductsInput, optFns ...func(*pricing.Options)) (*pricing.GetProductsOutput, error)
+}
+
+type cachedPrice struct {
+	monthly float64
+	expires time.Time
+}
+
+// PriceListPricer prices instances with the AWS Price List API, caching each
+// price for a TTL. When the API fails it falls back to a small static table.
+type PriceListPricer struct {
+	client pricingAPI
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedPrice
+}
+
+// NewPriceListPricer creates a pricer for the given AWS config. A ttl of
+// zero uses DefaultPriceTTL.
+func NewPriceListPricer(awsCfg aws.Config, ttl time.Duration) *PriceListPricer {
+	return newPriceListPricer(pricing.NewFromConfig(awsCfg, func(o *pricing.Options) {
+		o.Region = pricingRegion
+	}), ttl)
+}
+
+func newPriceListPricer(client pricingAPI, ttl time.Duration) *PriceListPricer {
+	if ttl <= 0 {
+		ttl = DefaultPriceTTL
+	}
+	return &PriceListPricer{
+		client: client,
+		ttl:    ttl,
+		now:    time.Now,
+		cache:  make(map[string]cachedPrice),
+	}
+}
+
+// InstanceMonthlyCost implements Pricer
+func (p *PriceListPricer) InstanceMonthlyCost(ctx context.Context, instanceType, region string) (float64, error) {
+	key := region + "/" + instanceType
+
+	p.mu.Lock()
+	cached, ok := p.cache[key]
+	p.mu.Unlock()
+	if ok && p.now().Before(cached.expires) {
+		return cached.monthly, nil
+	}
+
+	hourly, err := p.lookup(ctx, instanceType, region)
+	if err != nil {
+		// Failures are not cached, so the next scan tries the API again
+		if monthly, ok := staticInstancePricing[instanceType]; ok {
+			return monthly, nil
+		}
+		return 0, fmt.Errorf("failed to price %s in %s: %w", instanceType, region, err)
+	}
+
+	monthly := hourly * hoursPerMonth
+	p.mu.Lock()
+	p.cache[key] = cachedPrice{monthly: monthly, expires: p.now().Add(p.ttl)}
+	p.mu.Unlock()
+	return monthly, nil
+}
+
+// lookup returns the on-demand hourly price of a shared-tenancy Linux
+// instance without pre-installed software
+func (p *PriceListPricer) lookup(ctx context.Context, instanceType, region string) (float64, error) {
+	match := func(field, value string) pricingtypes.Filter {
+		return pricingtypes.Filter{Type: pricingtypes.FilterTypeTermMatch, Field: aws.String(field), Value: aws.String(value)}
+	}
+	output, err := p.client.GetProducts(ctx, &pricing.GetProductsInput{
+		ServiceCode: aws.String("AmazonEC2"),
+		Filters: []pricingtypes.Filter{
+			match("instanceType", instanceType),
+			match("regionCode", region),
+			match("operatingSystem", "Linux"),
+			match("tenancy", "Shared"),
+			match("preInstalledSw", "NA"),
+			match("capacitystatus", "Used"),
+		},
+		MaxResults: aws.Int32(10),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, product := range output.PriceList {
+		if hourly, ok := onDemandHourly(product); ok {
+			return hourly, nil
+		}
+	}
+	return 0, fmt.Errorf("no on-demand price listed")
+}
+
+// priceListProduct is the part of a Price List product document holding
+// on-demand prices, keyed by offer term and rate code
+type priceListProduct struct {
+	Terms struct {
+		OnDemand map[string]struct {
+			PriceDimensions map[string]struct {
+				Unit         string            `json:"unit"`
+				PricePerUnit map[string]string `json:"pricePerUnit"`
+			} `json:"priceDimensions"`
+		} `json:"OnDemand"`
+	} `json:"terms"`
+}
+
+// onDemandHourly extracts the hourly USD price from a product document
+func onDemandHourly(document string) (float64, bool) {
+	var product priceListProduct
+	if err := json.Unmarshal([]byte(document), &product); err != nil {
+		return 0, false
+	}
+	for _, term := range product.Terms.OnDemand {
+		for _, dimension := range term.PriceDimensions {
+			if dimension.Unit != "Hrs" {
+				continue
+			}
+			price, err := strconv.ParseFloat(dimension.PricePerUnit["USD"], 64)
+			if err == nil && price > 0 {
+				return price, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,110 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+// c6i.large in us-east-1, trimmed to the fields the pricer reads
+const c6iLargeProduct = `{
+	"product": {"attributes": {"instanceType": "c6i.large", "regionCode": "us-east-1"}},
+	"terms": {"OnDemand": {"ABC.JRTCKXETXF": {"priceDimensions": {
+		"ABC.JRTCKXETXF.6YS6EN2CT7": {"unit": "Hrs", "pricePerUnit": {"USD": "0.0850000000"}}
+	}}}}
+}`
+
+type fakePricingAPI struct {
+	products []string
+	err      error
+	calls    int
+	filters  map[string]string
+}
+
+func (f *fakePricingAPI) GetProducts(_ context.Context, params *pricing.GetProductsInput, _ ...func(*pricing.Options)) (*pricing.GetProductsOutput, error) {
+	f.calls++
+	f.filters = make(map[string]string)
+	for _, filter := range params.Filters {
+		f.filters[aws.ToString(filter.Field)] = aws.ToString(filter.Value)
+	}
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &pricing.GetProductsOutput{PriceList: f.products}, nil
+}
+
+func TestPriceListPricer_CachesForTTL(t *testing.T) {
+	api := &fakePricingAPI{products: []string{c6iLargeProduct}}
+	pricer := newPriceListPricer(api, time.Hour)
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	pricer.now = func() time.Time { return now }
+
+	cost, err := pricer.InstanceMonthlyCost(context.Background(), "c6i.large", "us-east-1")
+	require.NoError(t, err)
+	assert.InDelta(t, 62.05, cost, 0.001)
+	assert.Equal(t, "c6i.large", api.filters["instanceType"])
+	assert.Equal(t, "us-east-1", api.filters["regionCode"])
+
+	now = now.Add(59 * time.Minute)
+	_, err = pricer.InstanceMonthlyCost(context.Background(), "c6i.large", "us-east-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, api.calls, "served from the cache")
+
+	now = now.Add(2 * time.Minute)
+	_, err = pricer.InstanceMonthlyCost(context.Background(), "c6i.large", "us-east-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, api.calls, "expired entries are looked up again")
+}
+
+func TestPriceListPricer_FallsBackWhenAPIFails(t *testing.T) {
+	api := &fakePricingAPI{err: errors.New("AccessDeniedException")}
+	pricer := newPriceListPricer(api, 0)
+
+	cost, err := pricer.InstanceMonthlyCost(context.Background(), "t3.medium", "eu-west-1")
+	require.NoError(t, err)
+	assert.Equal(t, 40.0, cost)
+
+	_, err = pricer.InstanceMonthlyCost(context.Background(), "c6i.large", "eu-west-1")
+	assert.Error(t, err, "not in the static table")
+
+	// Failures are retried rather than cached
+	_, _ = pricer.InstanceMonthlyCost(context.Background(), "t3.medium", "eu-west-1")
+	assert.Equal(t, 3, api.calls)
+}
+
+func TestPriceListPricer_NoListedPrice(t *testing.T) {
+	api := &fakePricingAPI{products: []string{`{"terms": {}}`, `not json`}}
+	pricer := newPriceListPricer(api, 0)
+
+	_, err := pricer.InstanceMonthlyCost(context.Background(), "x9.huge", "us-east-1")
+	assert.Error(t, err)
+}
+
+type stubPricer map[string]float64
+
+func (s stubPricer) InstanceMonthlyCost(_ context.Context, instanceType, _ string) (float64, error) {
+	if cost, ok := s[instanceType]; ok {
+		return cost, nil
+	}
+	return 0, errors.New("unknown instance type")
+}
+
+func TestPriceInstance(t *testing.T) {
+	adapter := &Adapter{pricer: stubPricer{"c6i.large": 62.05}, region: "us-east-1"}
+
+	priced := &cloud.ResourceV2{ID: "i-1", Metadata: map[string]interface{}{"instance_type": "c6i.large"}}
+	adapter.priceInstance(context.Background(), priced)
+	assert.Equal(t, 62.05, priced.CostPerMonth)
+
+	unpriced := &cloud.ResourceV2{ID: "i-2", Metadata: map[string]interface{}{"instance_type": "x9.huge"}}
+	adapter.priceInstance(context.Background(), unpriced)
+	assert.Zero(t, unpriced.CostPerMonth)
+}
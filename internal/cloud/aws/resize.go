@@ -0,0 +1,210 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+// asgTagKey is set by EC2 Auto Scaling on the instances it launches. Their
+// type comes from the group's launch template, so resizing one instance
+// would be undone at the next scale event.
+const asgTagKey = "aws:autoscaling:groupName"
+
+// instanceStoppedTimeout bounds the wait for an instance to stop before its
+// type is changed
+const instanceStoppedTimeout = 10 * time.Minute
+
+// resizeTarget returns the instance type an EC2 resize moves to, which the
+// engine records on the resource from the action's plan
+func resizeTarget(resource *cloud.ResourceV2) (string, error) {
+	if resource.RightSizeRecommendation == "" {
+		return "", fmt.Errorf("%w: no target instance type recommended for %s", cloud.ErrInvalidAction, resource.ID)
+	}
+	if current, _ := resource.Metadata["instance_type"].(string); current == resource.RightSizeRecommendation {
+		return "", fmt.Errorf("%w: %s is already %s", cloud.ErrInvalidAction, resource.ID, current)
+	}
+	return resource.RightSizeRecommendation, nil
+}
+
+// validateInstanceType returns an error wrapping cloud.ErrInvalidAction
+// unless EC2 offers the instance type
+func (a *Adapter) validateInstanceType(ctx context.Context, instanceType string) error {
+	output, err := a.ec2Client.DescribeInstanceTypes(ctx, &ec2.DescribeInstanceTypesInput{
+		InstanceTypes: []ec2types.InstanceType{ec2types.InstanceType(instanceType)},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: instance type %s: %v", cloud.ErrInvalidAction, instanceType, err)
+	}
+	if len(output.InstanceTypes) == 0 {
+		return fmt.Errorf("%w: unknown instance type %s", cloud.ErrInvalidAction, instanceType)
+	}
+	return nil
+}
+
+// resizeSavings returns the monthly savings of moving an instance to the
+// target type, priced before and after. Without both prices it falls back to
+// the configured savings ratio.
+func (a *Adapter) resizeSavings(ctx context.Context, resource *cloud.ResourceV2, target string) float64 {
+	fallback := resource.CostPerMonth * a.cfg.SavingsRatio(string(cloud.ActionResize))
+	if a.pricer == nil {
+		return fallback
+	}
+
+	before := resource.CostPerMonth
+	if current, _ := resource.Metadata["instance_type"].(string); current != "" {
+		if cost, err := a.pricer.InstanceMonthlyCost(ctx, current, a.region); err == nil {
+			before = cost
+		}
+	}
+	after, err := a.pricer.InstanceMonthlyCost(ctx, target, a.region)
+	if err != nil || before == 0 {
+		log.Printf("pricing unavailable for resizing %s to %s, estimating savings: %v", resource.ID, target, err)
+		return fallback
+	}
+	// Moving to a larger type saves nothing
+	return max(0, before-after)
+}
+
+// resizeEC2Instance changes an instance's type. A running instance is
+// stopped, resized and started again; a stopped one stays stopped. If the
+// change fails the instance is restarted on its old type.
+func (a *Adapter) resizeEC2Instance(ctx context.Context, instanceID, targetType string) (string, error) {
+	output, err := a.ec2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{instanceID}})
+	if err != nil {
+		return "", fmt.Errorf("failed to describe instance %s: %w", instanceID, err)
+	}
+	if len(output.Reservations) == 0 || len(output.Reservations[0].Instances) == 0 {
+		return "", fmt.Errorf("resource %s not found", instanceID)
+	}
+	instance := output.Reservations[0].Instances[0]
+
+	for _, tag := range instance.Tags {
+		if aws.ToString(tag.Key) == asgTagKey {
+			return "", fmt.Errorf("%w: %s belongs to Auto Scaling group %s; change its launch template instead",
+				cloud.ErrInvalidAction, instanceID, aws.ToString(tag.Value))
+		}
+	}
+
+	state := ec2State(instance)
+	wasRunning := state == string(ec2types.InstanceStateNameRunning)
+	if !wasRunning && state != string(ec2types.InstanceStateNameStopped) {
+		return "", fmt.Errorf("%w: cannot resize %s in state %q", cloud.ErrInvalidAction, instanceID, state)
+	}
+
+	if wasRunning {
+		if _, err := a.stopEC2Instance(ctx, instanceID); err != nil {
+			return "", fmt.Errorf("failed to stop %s for resize: %w", instanceID, err)
+		}
+		waiter := ec2.NewInstanceStoppedWaiter(a.ec2Client)
+		if err := waiter.Wait(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{instanceID}}, instanceStoppedTimeout); err != nil {
+			return "", fmt.Errorf("%s did not stop for resize: %w", instanceID, err)
+		}
+	}
+
+	_, err = a.ec2Client.ModifyInstanceAttribute(ctx, &ec2.ModifyInstanceAttributeInput{
+		InstanceId:   aws.String(instanceID),
+		InstanceType: &ec2types.AttributeValue{Value: aws.String(targetType)},
+	})
+	if err != nil {
+		if wasRunning {
+			if _, startErr := a.startEC2Instance(ctx, instanceID); startErr != nil {
+				log.Printf("failed to restart %s after a failed resize: %v", instanceID, startErr)
+			}
+		}
+		return "", fmt.Errorf("failed to change %s to %s: %w", instanceID, targetType, err)
+	}
+
+	if wasRunning {
+		if _, err := a.startEC2Instance(ctx, instanceID); err != nil {
+			return "", fmt.Errorf("resized %s to %s but failed to start it: %w", instanceID, targetType, err)
+		}
+	}
+	log.Printf("resized EC2 instance %s from %s to %s", instanceID, instance.InstanceType, targetType)
+	return fmt.Sprintf("Resized EC2 instance %s to %s", instanceID, targetType), nil
+}
+
+func (a *Adapter) startEC2Instance(ctx context.Context, instanceID string) (string, error) {
+	_, err := a.ec2Client.StartInstances(ctx, &ec2.StartInstancesInput{
+		InstanceIds: []string{instanceID},
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Started EC2 instance %s", instanceID), nil
+}
+
+// Rollback restores an EC2 instance after a failed resize or stop: its
+// original type, then its running state if it was running. An instance that
+// already matches the snapshot is left alone.
+func (a *Adapter) Rollback(ctx context.Context, resource *cloud.ResourceV2, action string, snapshot cloud.ResourceSnapshot) error {
+	actionType := cloud.ActionType(action)
+	if resource.Type != cloud.ResourceTypeEC2 || (actionType != cloud.ActionResize && actionType != cloud.ActionStop) {
+		return fmt.Errorf("%w: %s on %s resources", cloud.ErrRollbackUnsupported, action, resource.Type)
+	}
+	// A dry run changed nothing
+	if cloud.DryRun(ctx, a.dryRun) {
+		return nil
+	}
+
+	output, err := a.ec2Client.DescribeInstances(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{resource.ID}})
+	if err != nil {
+		return fmt.Errorf("failed to describe instance %s: %w", resource.ID, err)
+	}
+	if len(output.Reservations) == 0 || len(output.Reservations[0].Instances) == 0 {
+		return fmt.Errorf("resource %s not found", resource.ID)
+	}
+	instance := output.Reservations[0].Instances[0]
+	state := ec2State(instance)
+
+	waitStopped := func() error {
+		waiter := ec2.NewInstanceStoppedWaiter(a.ec2Client)
+		if err := waiter.Wait(ctx, &ec2.DescribeInstancesInput{InstanceIds: []string{resource.ID}}, instanceStoppedTimeout); err != nil {
+			return fmt.Errorf("%s did not stop for rollback: %w", resource.ID, err)
+		}
+		state = string(ec2types.InstanceStateNameStopped)
+		return nil
+	}
+
+	if snapshot.InstanceType != "" && string(instance.InstanceType) != snapshot.InstanceType {
+		if state != string(ec2types.InstanceStateNameStopped) {
+			if state != string(ec2types.InstanceStateNameStopping) {
+				if _, err := a.stopEC2Instance(ctx, resource.ID); err != nil {
+					return fmt.Errorf("failed to stop %s for rollback: %w", resource.ID, err)
+				}
+			}
+			if err := waitStopped(); err != nil {
+				return err
+			}
+		}
+		_, err := a.ec2Client.ModifyInstanceAttribute(ctx, &ec2.ModifyInstanceAttributeInput{
+			InstanceId:   aws.String(resource.ID),
+			InstanceType: &ec2types.AttributeValue{Value: aws.String(snapshot.InstanceType)},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to restore %s to %s: %w", resource.ID, snapshot.InstanceType, err)
+		}
+	}
+
+	running := string(ec2types.InstanceStateNameRunning)
+	if snapshot.State == running && state != running && state != string(ec2types.InstanceStateNamePending) {
+		if state == string(ec2types.InstanceStateNameStopping) {
+			if err := waitStopped(); err != nil {
+				return err
+			}
+		}
+		if _, err := a.startEC2Instance(ctx, resource.ID); err != nil {
+			return fmt.Errorf("failed to restart %s: %w", resource.ID, err)
+		}
+	}
+
+	log.Printf("rolled back EC2 instance %s to %s (%s)", resource.ID, snapshot.InstanceType, snapshot.State)
+	return nil
+}
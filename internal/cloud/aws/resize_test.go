@@ -0,0 +1,177 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+// fakeEC2 simulates one instance through a stop, modify and start. Methods
+// it doesn't implement panic through the nil embedded interface.
+type fakeEC2 struct {
+	ec2API
+	instance  ec2types.Instance
+	known     map[string]bool
+	modifyErr error
+	calls     []string
+}
+
+func (f *fakeEC2) DescribeInstances(context.Context, *ec2.DescribeInstancesInput, ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	return &ec2.DescribeInstancesOutput{Reservations: []ec2types.Reservation{{Instances: []ec2types.Instance{f.instance}}}}, nil
+}
+
+func (f *fakeEC2) DescribeInstanceTypes(_ context.Context, params *ec2.DescribeInstanceTypesInput, _ ...func(*ec2.Options)) (*ec2.DescribeInstanceTypesOutput, error) {
+	output := &ec2.DescribeInstanceTypesOutput{}
+	for _, t := range params.InstanceTypes {
+		if !f.known[string(t)] {
+			return nil, errors.New("InvalidInstanceType")
+		}
+		output.InstanceTypes = append(output.InstanceTypes, ec2types.InstanceTypeInfo{InstanceType: t})
+	}
+	return output, nil
+}
+
+func (f *fakeEC2) setState(state ec2types.InstanceStateName) {
+	f.instance.State = &ec2types.InstanceState{Name: state}
+}
+
+func (f *fakeEC2) StopInstances(context.Context, *ec2.StopInstancesInput, ...func(*ec2.Options)) (*ec2.StopInstancesOutput, error) {
+	f.calls = append(f.calls, "stop")
+	f.setState(ec2types.InstanceStateNameStopped)
+	return &ec2.StopInstancesOutput{}, nil
+}
+
+func (f *fakeEC2) StartInstances(context.Context, *ec2.StartInstancesInput, ...func(*ec2.Options)) (*ec2.StartInstancesOutput, error) {
+	f.calls = append(f.calls, "start")
+	f.setState(ec2types.InstanceStateNameRunning)
+	return &ec2.StartInstancesOutput{}, nil
+}
+
+func (f *fakeEC2) ModifyInstanceAttribute(_ context.Context, params *ec2.ModifyInstanceAttributeInput, _ ...func(*ec2.Options)) (*ec2.ModifyInstanceAttributeOutput, error) {
+	if f.modifyErr != nil {
+		return nil, f.modifyErr
+	}
+	target := aws.ToString(params.InstanceType.Value)
+	f.calls = append(f.calls, "modify "+target)
+	f.instance.InstanceType = ec2types.InstanceType(target)
+	return &ec2.ModifyInstanceAttributeOutput{}, nil
+}
+
+func newResizeTest(state ec2types.InstanceStateName, tags ...ec2types.Tag) (*Adapter, *fakeEC2, *cloud.ResourceV2) {
+	fake := &fakeEC2{
+		instance: ec2types.Instance{
+			InstanceId:   aws.String("i-web"),
+			InstanceType: ec2types.InstanceTypeM5Xlarge,
+			Tags:         tags,
+		},
+		known: map[string]bool{"m5.large": true},
+	}
+	fake.setState(state)
+
+	adapter := &Adapter{
+		ec2Client: fake,
+		pricer:    stubPricer{"m5.xlarge": 140.16, "m5.large": 70.08},
+		region:    "us-east-1",
+	}
+	resource := &cloud.ResourceV2{
+		ID: "i-web", Type: cloud.ResourceTypeEC2, State: string(state), CostPerMonth: 140.16,
+		RightSizeRecommendation: "m5.large",
+		Metadata:                map[string]interface{}{"instance_type": "m5.xlarge"},
+	}
+	return adapter, fake, resource
+}
+
+func TestResize_StopsModifiesAndRestarts(t *testing.T) {
+	adapter, fake, resource := newResizeTest(ec2types.InstanceStateNameRunning)
+
+	savings, err := adapter.ApplyOptimization(context.Background(), resource, "resize")
+	require.NoError(t, err)
+	assert.Equal(t, 70.08, savings, "priced before and after, not a flat ratio")
+	assert.Equal(t, []string{"stop", "modify m5.large", "start"}, fake.calls)
+	assert.Equal(t, ec2types.InstanceTypeM5Large, fake.instance.InstanceType)
+}
+
+func TestResize_StoppedInstanceStaysStopped(t *testing.T) {
+	adapter, fake, resource := newResizeTest(ec2types.InstanceStateNameStopped)
+
+	_, err := adapter.ApplyOptimization(context.Background(), resource, "resize")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"modify m5.large"}, fake.calls)
+}
+
+func TestResize_DryRunOnlyPrices(t *testing.T) {
+	adapter, fake, resource := newResizeTest(ec2types.InstanceStateNameRunning)
+	adapter.dryRun = true
+
+	savings, err := adapter.ApplyOptimization(context.Background(), resource, "resize")
+	require.NoError(t, err)
+	assert.Equal(t, 70.08, savings)
+	assert.Empty(t, fake.calls)
+}
+
+func TestResize_Refusals(t *testing.T) {
+	adapter, fake, resource := newResizeTest(ec2types.InstanceStateNameRunning,
+		ec2types.Tag{Key: aws.String(asgTagKey), Value: aws.String("web-asg")})
+
+	_, err := adapter.ApplyOptimization(context.Background(), resource, "resize")
+	assert.ErrorIs(t, err, cloud.ErrInvalidAction, "Auto Scaling group member")
+
+	resource.RightSizeRecommendation = "m5.gigantic"
+	_, err = adapter.ApplyOptimization(context.Background(), resource, "resize")
+	assert.ErrorIs(t, err, cloud.ErrInvalidAction, "unknown instance type")
+
+	resource.RightSizeRecommendation = ""
+	_, err = adapter.ApplyOptimization(context.Background(), resource, "resize")
+	assert.ErrorIs(t, err, cloud.ErrInvalidAction, "no target")
+	assert.Empty(t, fake.calls)
+}
+
+func TestResize_FailedModifyRestartsInstance(t *testing.T) {
+	adapter, fake, resource := newResizeTest(ec2types.InstanceStateNameRunning)
+	fake.modifyErr = errors.New("Unsupported: m5.large is not supported in this zone")
+
+	_, err := adapter.ApplyOptimization(context.Background(), resource, "resize")
+	assert.Error(t, err)
+	assert.Equal(t, []string{"stop", "start"}, fake.calls)
+	assert.Equal(t, ec2types.InstanceTypeM5Xlarge, fake.instance.InstanceType)
+}
+
+func TestResizeSavings_FallsBackToRatio(t *testing.T) {
+	adapter, _, resource := newResizeTest(ec2types.InstanceStateNameRunning)
+	adapter.pricer = stubPricer{}
+
+	assert.Equal(t, 140.16*cloud.DefaultSavingsRatios["resize"], adapter.resizeSavings(context.Background(), resource, "m5.large"))
+
+	// A larger target saves nothing
+	adapter.pricer = stubPricer{"m5.xlarge": 140.16, "m5.2xlarge": 280.32}
+	assert.Zero(t, adapter.resizeSavings(context.Background(), resource, "m5.2xlarge"))
+}
+
+func TestRollback_RestoresTypeAndRestarts(t *testing.T) {
+	adapter, fake, resource := newResizeTest(ec2types.InstanceStateNameRunning)
+	snapshot := cloud.SnapshotResource(resource)
+
+	// Resized, but the instance never came back up
+	fake.setState(ec2types.InstanceStateNameStopped)
+	fake.instance.InstanceType = ec2types.InstanceTypeM5Large
+
+	require.NoError(t, adapter.Rollback(context.Background(), resource, "resize", snapshot))
+	assert.Equal(t, []string{"modify m5.xlarge", "start"}, fake.calls)
+	assert.Equal(t, ec2types.InstanceTypeM5Xlarge, fake.instance.InstanceType)
+
+	// Already restored: nothing to do
+	fake.calls = nil
+	require.NoError(t, adapter.Rollback(context.Background(), resource, "resize", snapshot))
+	assert.Empty(t, fake.calls)
+
+	err := adapter.Rollback(context.Background(), &cloud.ResourceV2{ID: "db-1", Type: cloud.ResourceTypeRDS}, "stop", snapshot)
+	assert.ErrorIs(t, err, cloud.ErrRollbackUnsupported)
+}
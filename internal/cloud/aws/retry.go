@@ -0,0 +1,47 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/ratelimit"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	talerrors "github.com/Xover-Official/Xover/internal/errors"
+)
+
+// newRetryer returns the SDK's standard retryer, which retries throttling
+// errors, 5xx responses and connection failures with exponential backoff and
+// jitter, trying each call up to maxAttempts times (retry.DefaultMaxAttempts
+// when not positive). The client-side retry quota is disabled so that a
+// large scan keeps backing off under sustained throttling rather than
+// failing once the quota runs out.
+func newRetryer(maxAttempts int, optFns ...func(*retry.StandardOptions)) func() aws.Retryer {
+	return func() aws.Retryer {
+		return retry.NewStandard(append([]func(*retry.StandardOptions){func(o *retry.StandardOptions) {
+			if maxAttempts > 0 {
+				o.MaxAttempts = maxAttempts
+			}
+			o.RateLimiter = ratelimit.None
+		}}, optFns...)...)
+	}
+}
+
+// throttleErrorMiddleware turns a call still throttled after its last retry
+// into a retryable cloud API error, so callers can tell it from a failure
+// that retrying later would not fix
+func throttleErrorMiddleware(stack *middleware.Stack) error {
+	return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("TalosThrottleErrors",
+		func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+			out, metadata, err := next.HandleInitialize(ctx, in)
+			if apiCallOutcome(err) == cloud.APICallThrottled {
+				operation := awsmiddleware.GetServiceID(ctx) + "." + awsmiddleware.GetOperationName(ctx)
+				err = talerrors.NewCloudAPIError(cloud.ProviderAWS, operation, err)
+			}
+			return out, metadata, err
+		},
+	), middleware.After)
+}
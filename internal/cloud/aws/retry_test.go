@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	talerrors "github.com/Xover-Official/Xover/internal/errors"
+)
+
+const throttledResponse = `<Response><Errors><Error><Code>RequestLimitExceeded</Code><Message>Request limit exceeded.</Message></Error></Errors><RequestID>r-throttled</RequestID></Response>`
+
+// noBackoff retries immediately
+type noBackoff struct{}
+
+func (noBackoff) BackoffDelay(int, error) (time.Duration, error) { return 0, nil }
+
+// throttlingAdapter returns an adapter whose SDK clients talk to a
+// query-API server that answers the first throttles DescribeInstances calls
+// with RequestLimitExceeded, and a count of the DescribeInstances calls
+func throttlingAdapter(t *testing.T, maxAttempts int, throttles int64) (*Adapter, *atomic.Int64) {
+	t.Helper()
+	var describeCalls atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		action := r.PostForm.Get("Action")
+		if action == "DescribeInstances" && describeCalls.Add(1) <= throttles {
+			w.Header().Set("Content-Type", "text/xml")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(throttledResponse))
+			return
+		}
+		body, ok := queryAPIResponses[action]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	awsCfg := aws.Config{
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		BaseEndpoint: aws.String(server.URL),
+		HTTPClient:   server.Client(),
+		Retryer: newRetryer(maxAttempts, func(o *retry.StandardOptions) {
+			o.Backoff = noBackoff{}
+		}),
+		APIOptions: []func(*middleware.Stack) error{throttleErrorMiddleware},
+	}
+	return &Adapter{
+		ec2Client: ec2.NewFromConfig(awsCfg),
+		rdsClient: rds.NewFromConfig(awsCfg),
+		elbClient: elbv2.NewFromConfig(awsCfg),
+		cwClient:  fakeCloudWatch{},
+		region:    "us-east-1",
+	}, &describeCalls
+}
+
+func TestFetchResources_RetriesThrottledCalls(t *testing.T) {
+	adapter, describeCalls := throttlingAdapter(t, 0, 2)
+
+	resources, err := adapter.FetchResources(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "i-traced", resources[0].ID)
+	assert.Equal(t, int64(3), describeCalls.Load())
+}
+
+func TestFetchResources_PersistentThrottleIsRetryable(t *testing.T) {
+	adapter, describeCalls := throttlingAdapter(t, 2, 100)
+
+	_, err := adapter.FetchResources(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, int64(2), describeCalls.Load(), "MaxAttempts bounds the tries")
+
+	var cloudErr *talerrors.TalosError
+	require.True(t, errors.As(err, &cloudErr), "got %v", err)
+	assert.Equal(t, talerrors.ErrCloudAPIError, cloudErr.Code)
+	assert.True(t, cloudErr.Retryable)
+	assert.Equal(t, "EC2.DescribeInstances", cloudErr.Context["operation"])
+	assert.Equal(t, "throttled", apiCallOutcome(err))
+}
@@ -0,0 +1,223 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+const (
+	// spotHistoryWindow is how far back the price volatility looks
+	spotHistoryWindow = 7 * 24 * time.Hour
+	// spotMarketTTL is how long a looked-up spot market is reused
+	spotMarketTTL = time.Hour
+	// spotProduct is the platform the spot prices are for
+	spotProduct = "Linux/UNIX"
+	// maxSpotVolatility is the price volatility treated as the highest
+	// interruption risk
+	maxSpotVolatility = 0.25
+	// spotLookupTimeout bounds the lookups behind GetSpotPrice and
+	// ListZones, which take no context
+	spotLookupTimeout = 30 * time.Second
+)
+
+type cachedSpotMarket struct {
+	market  *cloud.SpotMarket
+	expires time.Time
+}
+
+// spotMarkets caches spot market lookups and the region's zone IDs
+type spotMarkets struct {
+	mu      sync.Mutex
+	markets map[string]cachedSpotMarket
+	zoneIDs map[string]string // zone name to zone ID
+}
+
+// SpotMarket returns the spot market for an instance type in a zone: the
+// latest price and its volatility over the last week, and the spot
+// placement score. AWS publishes no interruption frequencies, so the
+// interruption risk is estimated from the placement score and volatility.
+// Results are cached for an hour.
+func (a *Adapter) SpotMarket(ctx context.Context, zone, instanceType string) (*cloud.SpotMarket, error) {
+	key := zone + ":" + instanceType
+	now := time.Now()
+	a.spot.mu.Lock()
+	if cached, ok := a.spot.markets[key]; ok && now.Before(cached.expires) {
+		a.spot.mu.Unlock()
+		return cached.market, nil
+	}
+	a.spot.mu.Unlock()
+
+	prices, err := a.spotPriceHistory(ctx, zone, instanceType, now.Add(-spotHistoryWindow))
+	if err != nil {
+		return nil, err
+	}
+	if len(prices) == 0 {
+		return nil, fmt.Errorf("no spot prices for %s in %s", instanceType, zone)
+	}
+
+	market := &cloud.SpotMarket{
+		Zone:            zone,
+		InstanceType:    instanceType,
+		Price:           prices[len(prices)-1],
+		PriceVolatility: volatility(prices),
+	}
+	// Placement scores need their own IAM permission and are rate limited;
+	// without one the risk rests on the price history alone
+	if score, err := a.placementScore(ctx, zone, instanceType); err == nil {
+		market.PlacementScore = score
+	}
+	market.InterruptionRisk = interruptionRisk(market.PlacementScore, market.PriceVolatility)
+
+	a.spot.mu.Lock()
+	if a.spot.markets == nil {
+		a.spot.markets = make(map[string]cachedSpotMarket)
+	}
+	a.spot.markets[key] = cachedSpotMarket{market: market, expires: now.Add(spotMarketTTL)}
+	a.spot.mu.Unlock()
+	return market, nil
+}
+
+// spotPriceHistory returns the Linux spot prices since start, oldest first
+func (a *Adapter) spotPriceHistory(ctx context.Context, zone, instanceType string, start time.Time) ([]float64, error) {
+	paginator := ec2.NewDescribeSpotPriceHistoryPaginator(a.ec2Client, &ec2.DescribeSpotPriceHistoryInput{
+		AvailabilityZone:    aws.String(zone),
+		InstanceTypes:       []ec2types.InstanceType{ec2types.InstanceType(instanceType)},
+		ProductDescriptions: []string{spotProduct},
+		StartTime:           aws.Time(start),
+	})
+
+	var history []ec2types.SpotPrice
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe spot price history: %w", err)
+		}
+		history = append(history, page.SpotPriceHistory...)
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		return aws.ToTime(history[i].Timestamp).Before(aws.ToTime(history[j].Timestamp))
+	})
+
+	prices := make([]float64, 0, len(history))
+	for _, entry := range history {
+		price, err := strconv.ParseFloat(aws.ToString(entry.SpotPrice), 64)
+		if err != nil {
+			continue
+		}
+		prices = append(prices, price)
+	}
+	return prices, nil
+}
+
+// placementScore returns the 1-10 spot placement score of one instance in
+// the zone
+func (a *Adapter) placementScore(ctx context.Context, zone, instanceType string) (int, error) {
+	zoneID, err := a.zoneID(ctx, zone)
+	if err != nil {
+		return 0, err
+	}
+	output, err := a.ec2Client.GetSpotPlacementScores(ctx, &ec2.GetSpotPlacementScoresInput{
+		InstanceTypes:          []string{instanceType},
+		TargetCapacity:         aws.Int32(1),
+		RegionNames:            []string{a.region},
+		SingleAvailabilityZone: aws.Bool(true),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get spot placement scores: %w", err)
+	}
+	for _, score := range output.SpotPlacementScores {
+		if aws.ToString(score.AvailabilityZoneId) == zoneID {
+			return int(aws.ToInt32(score.Score)), nil
+		}
+	}
+	return 0, fmt.Errorf("no spot placement score for %s in %s", instanceType, zone)
+}
+
+// zoneID maps a zone name to its ID, which placement scores are keyed by
+func (a *Adapter) zoneID(ctx context.Context, zone string) (string, error) {
+	a.spot.mu.Lock()
+	id, ok := a.spot.zoneIDs[zone]
+	a.spot.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+	if _, err := a.availabilityZones(ctx); err != nil {
+		return "", err
+	}
+	a.spot.mu.Lock()
+	defer a.spot.mu.Unlock()
+	if id, ok := a.spot.zoneIDs[zone]; ok {
+		return id, nil
+	}
+	return "", fmt.Errorf("unknown availability zone %s", zone)
+}
+
+// availabilityZones returns the names of the region's available zones,
+// leaving out local and wavelength zones, and records their IDs
+func (a *Adapter) availabilityZones(ctx context.Context) ([]string, error) {
+	output, err := a.ec2Client.DescribeAvailabilityZones(ctx, &ec2.DescribeAvailabilityZonesInput{
+		Filters: []ec2types.Filter{
+			{Name: aws.String("region-name"), Values: []string{a.region}},
+			{Name: aws.String("zone-type"), Values: []string{"availability-zone"}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe availability zones: %w", err)
+	}
+
+	zones := make([]string, 0, len(output.AvailabilityZones))
+	ids := make(map[string]string, len(output.AvailabilityZones))
+	for _, zone := range output.AvailabilityZones {
+		name := aws.ToString(zone.ZoneName)
+		ids[name] = aws.ToString(zone.ZoneId)
+		if zone.State == ec2types.AvailabilityZoneStateAvailable {
+			zones = append(zones, name)
+		}
+	}
+	sort.Strings(zones)
+
+	a.spot.mu.Lock()
+	a.spot.zoneIDs = ids
+	a.spot.mu.Unlock()
+	return zones, nil
+}
+
+// volatility returns the standard deviation of prices over their mean
+func volatility(prices []float64) float64 {
+	var sum float64
+	for _, p := range prices {
+		sum += p
+	}
+	mean := sum / float64(len(prices))
+	if mean == 0 {
+		return 0
+	}
+	var squares float64
+	for _, p := range prices {
+		squares += (p - mean) * (p - mean)
+	}
+	return math.Sqrt(squares/float64(len(prices))) / mean
+}
+
+// interruptionRisk combines a placement score, where 10 is the safest, and
+// the price volatility into a 0-1 risk. A zero score is unknown and leaves
+// the volatility alone to decide.
+func interruptionRisk(placementScore int, volatility float64) float64 {
+	priceRisk := math.Min(volatility/maxSpotVolatility, 1)
+	if placementScore <= 0 {
+		return priceRisk
+	}
+	placementRisk := float64(10-min(placementScore, 10)) / 9
+	return (placementRisk + priceRisk) / 2
+}
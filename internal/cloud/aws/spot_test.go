@@ -0,0 +1,139 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSpotEC2 serves spot price histories, placement scores and zones.
+// Methods it doesn't implement panic through the nil embedded interface.
+type fakeSpotEC2 struct {
+	ec2API
+	prices       map[string][]string // instance type to prices, newest first
+	scores       map[string]int32    // zone ID to placement score
+	scoresErr    error
+	historyCalls int
+}
+
+func (f *fakeSpotEC2) DescribeSpotPriceHistory(_ context.Context, params *ec2.DescribeSpotPriceHistoryInput, _ ...func(*ec2.Options)) (*ec2.DescribeSpotPriceHistoryOutput, error) {
+	f.historyCalls++
+	instanceType := string(params.InstanceTypes[0])
+	// AWS lists the newest prices first
+	now := time.Now()
+	output := &ec2.DescribeSpotPriceHistoryOutput{}
+	for i, price := range f.prices[instanceType] {
+		output.SpotPriceHistory = append(output.SpotPriceHistory, ec2types.SpotPrice{
+			AvailabilityZone: params.AvailabilityZone,
+			InstanceType:     ec2types.InstanceType(instanceType),
+			SpotPrice:        aws.String(price),
+			Timestamp:        aws.Time(now.Add(-time.Duration(i) * time.Hour)),
+		})
+	}
+	return output, nil
+}
+
+func (f *fakeSpotEC2) GetSpotPlacementScores(context.Context, *ec2.GetSpotPlacementScoresInput, ...func(*ec2.Options)) (*ec2.GetSpotPlacementScoresOutput, error) {
+	if f.scoresErr != nil {
+		return nil, f.scoresErr
+	}
+	output := &ec2.GetSpotPlacementScoresOutput{}
+	for zoneID, score := range f.scores {
+		output.SpotPlacementScores = append(output.SpotPlacementScores, ec2types.SpotPlacementScore{
+			AvailabilityZoneId: aws.String(zoneID),
+			Region:             aws.String("us-east-1"),
+			Score:              aws.Int32(score),
+		})
+	}
+	return output, nil
+}
+
+func (f *fakeSpotEC2) DescribeAvailabilityZones(context.Context, *ec2.DescribeAvailabilityZonesInput, ...func(*ec2.Options)) (*ec2.DescribeAvailabilityZonesOutput, error) {
+	zone := func(name, id string, state ec2types.AvailabilityZoneState) ec2types.AvailabilityZone {
+		return ec2types.AvailabilityZone{ZoneName: aws.String(name), ZoneId: aws.String(id), State: state}
+	}
+	return &ec2.DescribeAvailabilityZonesOutput{AvailabilityZones: []ec2types.AvailabilityZone{
+		zone("us-east-1b", "use1-az2", ec2types.AvailabilityZoneStateAvailable),
+		zone("us-east-1a", "use1-az1", ec2types.AvailabilityZoneStateAvailable),
+		zone("us-east-1e", "use1-az3", ec2types.AvailabilityZoneStateImpaired),
+	}}, nil
+}
+
+func newSpotTest() (*Adapter, *fakeSpotEC2) {
+	fake := &fakeSpotEC2{
+		prices: map[string][]string{
+			"m5.large":  {"0.0350", "0.0350", "0.0350", "0.0350"},
+			"c5.xlarge": {"0.1400", "0.0600", "0.1300", "0.0500"},
+		},
+		scores: map[string]int32{"use1-az1": 9, "use1-az2": 2},
+	}
+	return &Adapter{ec2Client: fake, region: "us-east-1"}, fake
+}
+
+func TestSpotMarket_StablePricesAndHighScore(t *testing.T) {
+	adapter, _ := newSpotTest()
+
+	market, err := adapter.SpotMarket(context.Background(), "us-east-1a", "m5.large")
+	require.NoError(t, err)
+	assert.Equal(t, 0.035, market.Price)
+	assert.Zero(t, market.PriceVolatility)
+	assert.Equal(t, 9, market.PlacementScore)
+	assert.InDelta(t, 0.5/9, market.InterruptionRisk, 1e-9)
+}
+
+func TestSpotMarket_VolatilePricesAndLowScore(t *testing.T) {
+	adapter, _ := newSpotTest()
+
+	stable, err := adapter.SpotMarket(context.Background(), "us-east-1a", "m5.large")
+	require.NoError(t, err)
+	volatile, err := adapter.SpotMarket(context.Background(), "us-east-1b", "c5.xlarge")
+	require.NoError(t, err)
+
+	assert.Equal(t, 0.14, volatile.Price, "the newest price")
+	assert.Greater(t, volatile.PriceVolatility, maxSpotVolatility)
+	assert.Equal(t, 2, volatile.PlacementScore)
+	assert.InDelta(t, (8.0/9+1)/2, volatile.InterruptionRisk, 1e-9)
+	assert.Greater(t, volatile.InterruptionRisk, stable.InterruptionRisk)
+}
+
+func TestSpotMarket_WithoutPlacementScores(t *testing.T) {
+	adapter, fake := newSpotTest()
+	fake.scoresErr = errors.New("UnauthorizedOperation")
+
+	market, err := adapter.SpotMarket(context.Background(), "us-east-1b", "c5.xlarge")
+	require.NoError(t, err)
+	assert.Zero(t, market.PlacementScore)
+	assert.Equal(t, 1.0, market.InterruptionRisk, "the price volatility alone")
+}
+
+func TestSpotMarket_CachedAndNoHistory(t *testing.T) {
+	adapter, fake := newSpotTest()
+
+	for i := 0; i < 2; i++ {
+		_, err := adapter.SpotMarket(context.Background(), "us-east-1a", "m5.large")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, fake.historyCalls)
+
+	price, err := adapter.GetSpotPrice("us-east-1a", "m5.large")
+	require.NoError(t, err)
+	assert.Equal(t, 0.035, price)
+
+	_, err = adapter.GetSpotPrice("us-east-1a", "p4d.24xlarge")
+	assert.ErrorContains(t, err, "no spot prices for p4d.24xlarge")
+}
+
+func TestListZones(t *testing.T) {
+	adapter, _ := newSpotTest()
+
+	zones, err := adapter.ListZones()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"us-east-1a", "us-east-1b"}, zones, "impaired zones are left out")
+}
@@ -0,0 +1,233 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatchtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+// stoppedAtKey is the resource metadata key holding when an instance stopped
+const stoppedAtKey = "stopped_at"
+
+// maxCloudWatchDatapoints is the most datapoints GetMetricStatistics returns
+const maxCloudWatchDatapoints = 1440
+
+// stateTransitionTime matches the timestamp EC2 puts in StateTransitionReason,
+// e.g. "User initiated (2026-01-02 03:04:05 GMT)"
+var stateTransitionTime = regexp.MustCompile(`\((\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}) GMT\)`)
+
+// stoppedAt parses when a stopped instance was stopped from its state
+// transition reason
+func stoppedAt(instance ec2types.Instance) (time.Time, bool) {
+	match := stateTransitionTime.FindStringSubmatch(aws.ToString(instance.StateTransitionReason))
+	if match == nil {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("2006-01-02 15:04:05", match[1])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t.UTC(), true
+}
+
+// terminateAsCleanup maps the resource types that terminate applies to
+// besides instances to the idle cleanup that removes them
+var terminateAsCleanup = map[string]cloud.ActionType{
+	cloud.ResourceTypeElasticIP: cloud.ActionReleaseAddress,
+	cloud.ResourceTypeEBS:       cloud.ActionDeleteVolume,
+}
+
+// checkTermination applies the termination safeguards. A protected resource
+// is never terminated. One with a pending soft terminate needs its grace
+// period to have passed and must still be stopped and idle, so an owner who
+// restarted it in the meantime cancels the termination; otherwise it must
+// be idle and backed up. It returns true when the resource may be
+// terminated now, and false when it should be soft terminated first.
+func (a *Adapter) checkTermination(ctx context.Context, resource *cloud.ResourceV2, now time.Time) (bool, error) {
+	policy := a.cfg.Termination
+	if err := policy.CheckProtected(resource); err != nil {
+		return false, err
+	}
+	if err := policy.CheckBackup(resource, now); err != nil {
+		return false, err
+	}
+	if after, pending := cloud.PendingTermination(resource); pending {
+		taggedAt := after.Add(-policy.GracePeriod)
+		if err := a.checkStillStopped(resource, taggedAt); err != nil {
+			a.cancelSoftTerminate(ctx, resource.ID)
+			return false, err
+		}
+		if err := cloud.CheckGracePeriod(resource, after, now); err != nil {
+			return true, err
+		}
+		if err := a.checkStillIdle(ctx, resource, taggedAt, now); err != nil {
+			a.cancelSoftTerminate(ctx, resource.ID)
+			return false, err
+		}
+		return true, nil
+	}
+	if err := a.checkIdle(ctx, resource, now); err != nil {
+		return false, err
+	}
+	return !policy.SoftTerminate, nil
+}
+
+// checkIdle returns an error wrapping cloud.ErrTerminationRefused unless the
+// instance has been idle for the policy's idle threshold: stopped for that
+// long, or running with CPU below IdleCPUThreshold for the whole window
+func (a *Adapter) checkIdle(ctx context.Context, resource *cloud.ResourceV2, now time.Time) error {
+	minIdle := a.cfg.Termination.IdleThreshold()
+
+	if since, ok := resource.Metadata[stoppedAtKey].(time.Time); ok {
+		if idle := now.Sub(since); idle < minIdle {
+			return fmt.Errorf("%w: %s has been stopped for %s (min %s)", cloud.ErrTerminationRefused, resource.ID, idle.Round(time.Minute), minIdle)
+		}
+		return nil
+	}
+	if resource.State != "running" {
+		return fmt.Errorf("%w: cannot tell how long %s has been %s", cloud.ErrTerminationRefused, resource.ID, resource.State)
+	}
+	if now.Sub(resource.CreatedAt) < minIdle {
+		return fmt.Errorf("%w: %s launched less than %s ago", cloud.ErrTerminationRefused, resource.ID, minIdle)
+	}
+
+	peak, err := a.peakCPU(ctx, resource.ID, now.Add(-minIdle), now)
+	if err != nil {
+		return fmt.Errorf("%w: idle check for %s failed: %v", cloud.ErrTerminationRefused, resource.ID, err)
+	}
+	if peak >= a.cfg.Termination.IdleCPUThreshold {
+		return fmt.Errorf("%w: %s peaked at %.1f%% CPU in the last %s (idle below %.1f%%)",
+			cloud.ErrTerminationRefused, resource.ID, peak, minIdle, a.cfg.Termination.IdleCPUThreshold)
+	}
+	return nil
+}
+
+// checkStillStopped returns an error wrapping cloud.ErrTerminationRefused
+// unless an instance soft terminated at taggedAt is stopped, and has not
+// been started since it was tagged
+func (a *Adapter) checkStillStopped(resource *cloud.ResourceV2, taggedAt time.Time) error {
+	if resource.State != "stopped" {
+		return fmt.Errorf("%w: %s is %s again since it was soft terminated", cloud.ErrTerminationRefused, resource.ID, resource.State)
+	}
+	if _, ok := resource.Metadata[stoppedAtKey].(time.Time); !ok {
+		return fmt.Errorf("%w: cannot tell when %s was stopped", cloud.ErrTerminationRefused, resource.ID)
+	}
+	return nil
+}
+
+// checkStillIdle re-checks the idle condition of a soft terminated instance
+// once its grace period is over. One stopped before it was tagged has not
+// run since; one stopped later may have run in between, so its CPU must
+// have stayed idle from the original idle window through to now.
+func (a *Adapter) checkStillIdle(ctx context.Context, resource *cloud.ResourceV2, taggedAt, now time.Time) error {
+	since := resource.Metadata[stoppedAtKey].(time.Time)
+	if since.Before(taggedAt) {
+		return a.checkIdle(ctx, resource, now)
+	}
+
+	start := taggedAt.Add(-a.cfg.Termination.IdleThreshold())
+	peak, err := a.peakCPU(ctx, resource.ID, start, now)
+	if err != nil {
+		return fmt.Errorf("%w: idle check for %s failed: %v", cloud.ErrTerminationRefused, resource.ID, err)
+	}
+	if peak >= a.cfg.Termination.IdleCPUThreshold {
+		return fmt.Errorf("%w: %s peaked at %.1f%% CPU since %s (idle below %.1f%%)",
+			cloud.ErrTerminationRefused, resource.ID, peak, start.Format(time.RFC3339), a.cfg.Termination.IdleCPUThreshold)
+	}
+	return nil
+}
+
+// cancelSoftTerminate removes the pending termination tag from an instance
+// that no longer qualifies, so it is vetted afresh before any new attempt.
+// Nothing is changed in dry run.
+func (a *Adapter) cancelSoftTerminate(ctx context.Context, instanceID string) {
+	if cloud.DryRun(ctx, a.dryRun) {
+		return
+	}
+	_, err := a.ec2Client.DeleteTags(ctx, &ec2.DeleteTagsInput{
+		Resources: []string{instanceID},
+		Tags:      []ec2types.Tag{{Key: aws.String(cloud.TerminateAfterTagKey)}},
+	})
+	if err != nil {
+		log.Printf("soft terminate: failed to cancel pending termination of %s: %v", instanceID, err)
+		return
+	}
+	log.Printf("soft terminate: cancelled pending termination of %s", instanceID)
+}
+
+// statisticsPeriod returns a CloudWatch period that covers start to end in a
+// single request. It must be a multiple of 60s.
+func statisticsPeriod(start, end time.Time) int32 {
+	period := int32(end.Sub(start).Seconds()/maxCloudWatchDatapoints/60+1) * 60
+	if period < 300 {
+		period = 300
+	}
+	return period
+}
+
+// peakCPU returns the maximum CPU utilization of an instance over a window
+func (a *Adapter) peakCPU(ctx context.Context, instanceID string, start, end time.Time) (float64, error) {
+	result, err := a.cwClient.GetMetricStatistics(ctx, &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  aws.String("AWS/EC2"),
+		MetricName: aws.String("CPUUtilization"),
+		Dimensions: []cloudwatchtypes.Dimension{{Name: aws.String("InstanceId"), Value: aws.String(instanceID)}},
+		StartTime:  aws.Time(start),
+		EndTime:    aws.Time(end),
+		Period:     aws.Int32(statisticsPeriod(start, end)),
+		Statistics: []cloudwatchtypes.Statistic{cloudwatchtypes.StatisticMaximum},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(result.Datapoints) == 0 {
+		return 0, fmt.Errorf("no CPU datapoints for %s", instanceID)
+	}
+
+	var peak float64
+	for _, point := range result.Datapoints {
+		if point.Maximum != nil && *point.Maximum > peak {
+			peak = *point.Maximum
+		}
+	}
+	return peak, nil
+}
+
+// softTerminateEC2Instance stops an instance and tags it with the time after
+// which it may be terminated
+func (a *Adapter) softTerminateEC2Instance(ctx context.Context, instanceID string, now time.Time) (string, error) {
+	after := now.Add(a.cfg.Termination.GracePeriod).UTC().Format(time.RFC3339)
+	_, err := a.ec2Client.CreateTags(ctx, &ec2.CreateTagsInput{
+		Resources: []string{instanceID},
+		Tags:      []ec2types.Tag{{Key: aws.String(cloud.TerminateAfterTagKey), Value: aws.String(after)}},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to tag %s for termination: %w", instanceID, err)
+	}
+
+	if _, err := a.stopEC2Instance(ctx, instanceID); err != nil {
+		return "", err
+	}
+	log.Printf("soft terminate: stopped %s; it will be terminated after %s unless tagged %s", instanceID, after, cloud.KeepTagKey)
+	return fmt.Sprintf("Stopped EC2 instance %s pending termination after %s", instanceID, after), nil
+}
+
+func (a *Adapter) terminateEC2Instance(ctx context.Context, instanceID string) (string, error) {
+	_, err := a.ec2Client.TerminateInstances(ctx, &ec2.TerminateInstancesInput{
+		InstanceIds: []string{instanceID},
+	})
+	if err != nil {
+		return "", err
+	}
+	log.Printf("terminated EC2 instance %s", instanceID)
+	return fmt.Sprintf("Terminated EC2 instance %s", instanceID), nil
+}
@@ -0,0 +1,62 @@
+package aws
+
+import (
+	"context"
+
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/smithy-go/middleware"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// tracingMiddleware wraps every call the SDK clients make in a client span
+// named after the service and operation, e.g. EC2.DescribeInstances. Like
+// the metrics middleware it spans the call's retries.
+func tracingMiddleware(tracer trace.Tracer) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		return stack.Initialize.Add(middleware.InitializeMiddlewareFunc("TalosTracing",
+			func(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+				service, operation := awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx)
+				ctx, span := tracer.Start(ctx, service+"."+operation,
+					trace.WithSpanKind(trace.SpanKindClient),
+					trace.WithAttributes(
+						attribute.String("rpc.system", "aws-api"),
+						attribute.String("rpc.service", service),
+						attribute.String("rpc.method", operation),
+					),
+				)
+				out, metadata, err := next.HandleInitialize(ctx, in)
+				endSpan(span, err)
+				return out, metadata, err
+			},
+		), middleware.After)
+	}
+}
+
+// startSpan starts a span for an adapter operation on a resource; resourceID
+// is empty for operations spanning the region. Adapters built without a
+// tracer get a no-op span.
+func (a *Adapter) startSpan(ctx context.Context, operation, resourceID string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	tracer := a.tracer
+	if tracer == nil {
+		tracer = noop.NewTracerProvider().Tracer("")
+	}
+	attrs = append(attrs, attribute.String("aws.operation", operation), attribute.String("aws.region", a.region))
+	if resourceID != "" {
+		attrs = append(attrs, attribute.String("aws.resource_id", resourceID))
+	}
+	return tracer.Start(ctx, "aws."+operation, trace.WithAttributes(attrs...))
+}
+
+// endSpan records the outcome of the spanned call, as one of the
+// cloud.APICall outcomes, and ends the span
+func endSpan(span trace.Span, err error) {
+	span.SetAttributes(attribute.String("aws.result", apiCallOutcome(err)))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,122 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// queryAPIResponses are minimal query-protocol answers, by Action, for the
+// calls FetchResources makes: one running instance and nothing else
+var queryAPIResponses = map[string]string{
+	"DescribeInstances": `<DescribeInstancesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><requestId>r-1</requestId>` +
+		`<reservationSet><item><instancesSet><item><instanceId>i-traced</instanceId><instanceType>t3.micro</instanceType>` +
+		`<instanceState><name>running</name></instanceState></item></instancesSet></item></reservationSet></DescribeInstancesResponse>`,
+	"DescribeAddresses": `<DescribeAddressesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><requestId>r-2</requestId><addressesSet/></DescribeAddressesResponse>`,
+	"DescribeVolumes":   `<DescribeVolumesResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><requestId>r-3</requestId><volumeSet/></DescribeVolumesResponse>`,
+	"DescribeDBInstances": `<DescribeDBInstancesResponse xmlns="http://rds.amazonaws.com/doc/2014-10-31/">` +
+		`<DescribeDBInstancesResult><DBInstances/></DescribeDBInstancesResult></DescribeDBInstancesResponse>`,
+	"DescribeLoadBalancers": `<DescribeLoadBalancersResponse xmlns="http://elasticloadbalancing.amazonaws.com/doc/2015-12-01/">` +
+		`<DescribeLoadBalancersResult><LoadBalancers/></DescribeLoadBalancersResult></DescribeLoadBalancersResponse>`,
+}
+
+func TestFetchResources_Traced(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		body, ok := queryAPIResponses[r.PostForm.Get("Action")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+	tracer := provider.Tracer("aws-test")
+
+	awsCfg := aws.Config{
+		Region:       "us-east-1",
+		Credentials:  credentials.NewStaticCredentialsProvider("AKID", "SECRET", ""),
+		BaseEndpoint: aws.String(server.URL),
+		HTTPClient:   server.Client(),
+		Retryer:      func() aws.Retryer { return aws.NopRetryer{} },
+		APIOptions:   []func(*middleware.Stack) error{tracingMiddleware(tracer)},
+	}
+	adapter := &Adapter{
+		ec2Client: ec2.NewFromConfig(awsCfg),
+		rdsClient: rds.NewFromConfig(awsCfg),
+		elbClient: elbv2.NewFromConfig(awsCfg),
+		cwClient:  fakeCloudWatch{averages: map[string]float64{"AWS/EC2/CPUUtilization": 3}},
+		region:    "us-east-1",
+		tracer:    tracer,
+	}
+
+	resources, err := adapter.FetchResources(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	root, ok := spans["aws.FetchResources"]
+	require.True(t, ok, "expected a FetchResources span, got %v", spans)
+	assert.Contains(t, root.Attributes, attribute.String("aws.result", "success"))
+	assert.Contains(t, root.Attributes, attribute.Int("aws.resource_count", 1))
+
+	// Every SDK call and the instance's metrics are children of the fetch
+	for _, name := range []string{"EC2.DescribeInstances", "EC2.DescribeAddresses", "EC2.DescribeVolumes", "RDS.DescribeDBInstances", "Elastic Load Balancing v2.DescribeLoadBalancers", "aws.GetEC2Metrics"} {
+		span, ok := spans[name]
+		if !assert.True(t, ok, "missing span %s", name) {
+			continue
+		}
+		assert.Equal(t, root.SpanContext.TraceID(), span.SpanContext.TraceID(), name)
+	}
+	assert.Contains(t, spans["aws.GetEC2Metrics"].Attributes, attribute.String("aws.resource_id", "i-traced"))
+	assert.Contains(t, spans["EC2.DescribeInstances"].Attributes, attribute.String("aws.result", "success"))
+}
+
+// throttledEC2 answers DescribeInstances with a throttling error
+type throttledEC2 struct {
+	ec2API
+}
+
+func (throttledEC2) DescribeInstances(context.Context, *ec2.DescribeInstancesInput, ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	return nil, &smithy.GenericAPIError{Code: "Throttling", Message: "Rate exceeded"}
+}
+
+func TestGetResource_TracesErrors(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+
+	adapter := &Adapter{ec2Client: throttledEC2{}, region: "us-east-1", tracer: provider.Tracer("aws-test")}
+	_, err := adapter.GetResource(context.Background(), "i-missing")
+	require.Error(t, err)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "aws.GetResource", spans[0].Name)
+	assert.Contains(t, spans[0].Attributes, attribute.String("aws.resource_id", "i-missing"))
+	assert.Contains(t, spans[0].Attributes, attribute.String("aws.result", "throttled"))
+	require.Len(t, spans[0].Events, 1, "the error is recorded on the span")
+	assert.Equal(t, "exception", spans[0].Events[0].Name)
+}
@@ -0,0 +1,160 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+// volumeGBMonthlyCost is the us-east-1 list price per GB-month of each EBS
+// volume type
+var volumeGBMonthlyCost = map[ec2types.VolumeType]float64{
+	ec2types.VolumeTypeGp2:      0.10,
+	ec2types.VolumeTypeGp3:      0.08,
+	ec2types.VolumeTypeIo1:      0.125,
+	ec2types.VolumeTypeIo2:      0.125,
+	ec2types.VolumeTypeSt1:      0.045,
+	ec2types.VolumeTypeSc1:      0.015,
+	ec2types.VolumeTypeStandard: 0.05,
+}
+
+// Provisioned performance charged on top of capacity
+const (
+	provisionedIOPSMonthlyCost = 0.065 // per IOPS on io1/io2
+	gp3IOPSMonthlyCost         = 0.005 // per IOPS above the gp3 baseline
+	gp3BaselineIOPS            = 3000
+)
+
+// unattachedKey is the metadata flag set on volumes attached to no instance
+const unattachedKey = "unattached"
+
+// isVolumeID reports whether a resource ID is an EBS volume ID
+func isVolumeID(id string) bool {
+	return strings.HasPrefix(id, "vol-")
+}
+
+func (a *Adapter) fetchEBSVolumes(ctx context.Context) ([]*cloud.ResourceV2, error) {
+	return a.describeVolumes(ctx, &ec2.DescribeVolumesInput{})
+}
+
+// getVolume retrieves one EBS volume by ID
+func (a *Adapter) getVolume(ctx context.Context, volumeID string) (*cloud.ResourceV2, error) {
+	resources, err := a.describeVolumes(ctx, &ec2.DescribeVolumesInput{VolumeIds: []string{volumeID}})
+	if err != nil {
+		return nil, err
+	}
+	if len(resources) == 0 {
+		return nil, fmt.Errorf("resource %s not found", volumeID)
+	}
+	return resources[0], nil
+}
+
+// describeVolumes converts volumes and marks the ones that have been
+// unattached for the idle threshold. Like addresses, AWS doesn't record when
+// a volume was detached, so the clock starts when it is first seen detached.
+func (a *Adapter) describeVolumes(ctx context.Context, input *ec2.DescribeVolumesInput) ([]*cloud.ResourceV2, error) {
+	paginator := ec2.NewDescribeVolumesPaginator(a.ec2Client, input)
+
+	now := time.Now()
+	threshold := a.cfg.Idle.Threshold()
+	var resources []*cloud.ResourceV2
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe volumes: %w", err)
+		}
+		for _, volume := range output.Volumes {
+			resource, ok := volumeToResource(volume, a.region)
+			if !ok {
+				log.Printf("skipping EBS volume without a volume ID")
+				continue
+			}
+			attached := resource.State != string(ec2types.VolumeStateAvailable)
+			since, unattached := a.unattached.observe(resource.ID, attached, now)
+			if unattached && now.Sub(since) >= threshold {
+				resource.Metadata[cloud.IdleSinceKey] = since
+			}
+			resources = append(resources, resource)
+		}
+	}
+	return resources, nil
+}
+
+// volumeToResource converts an SDK volume to the canonical model. It returns
+// false if the volume has no ID.
+func volumeToResource(volume ec2types.Volume, region string) (*cloud.ResourceV2, bool) {
+	id := aws.ToString(volume.VolumeId)
+	if id == "" {
+		return nil, false
+	}
+
+	state := unknownState
+	if volume.State != "" {
+		state = string(volume.State)
+	}
+
+	resource := &cloud.ResourceV2{
+		ID:                id,
+		Type:              cloud.ResourceTypeEBS,
+		Provider:          cloud.ProviderAWS,
+		Region:            region,
+		Tags:              make(map[string]string, len(volume.Tags)),
+		State:             state,
+		CreatedAt:         aws.ToTime(volume.CreateTime),
+		CostPerMonth:      volumeMonthlyCost(volume),
+		EncryptionEnabled: aws.ToBool(volume.Encrypted),
+		Metadata: map[string]interface{}{
+			"volume_type":       string(volume.VolumeType),
+			"size_gb":           aws.ToInt32(volume.Size),
+			"availability_zone": aws.ToString(volume.AvailabilityZone),
+			unattachedKey:       len(volume.Attachments) == 0,
+		},
+	}
+	if snapshot := aws.ToString(volume.SnapshotId); snapshot != "" {
+		resource.Metadata["snapshot_id"] = snapshot
+	}
+	for _, tag := range volume.Tags {
+		if tag.Key != nil && tag.Value != nil {
+			resource.Tags[*tag.Key] = *tag.Value
+		}
+	}
+	return resource, true
+}
+
+// volumeMonthlyCost estimates a volume's monthly cost from its size, type and
+// provisioned IOPS. Unknown types are priced as gp2.
+func volumeMonthlyCost(volume ec2types.Volume) float64 {
+	perGB, ok := volumeGBMonthlyCost[volume.VolumeType]
+	if !ok {
+		perGB = volumeGBMonthlyCost[ec2types.VolumeTypeGp2]
+	}
+	cost := float64(aws.ToInt32(volume.Size)) * perGB
+
+	iops := float64(aws.ToInt32(volume.Iops))
+	switch volume.VolumeType {
+	case ec2types.VolumeTypeIo1, ec2types.VolumeTypeIo2:
+		cost += iops * provisionedIOPSMonthlyCost
+	case ec2types.VolumeTypeGp3:
+		cost += max(0, iops-gp3BaselineIOPS) * gp3IOPSMonthlyCost
+	}
+	return cost
+}
+
+func (a *Adapter) deleteVolume(ctx context.Context, volumeID string) (string, error) {
+	_, err := a.ec2Client.DeleteVolume(ctx, &ec2.DeleteVolumeInput{
+		VolumeId: aws.String(volumeID),
+	})
+	if err != nil {
+		return "", err
+	}
+	log.Printf("deleted unattached EBS volume %s", volumeID)
+	return fmt.Sprintf("Deleted EBS volume %s", volumeID), nil
+}
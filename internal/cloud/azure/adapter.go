@@ -3,73 +3,548 @@ package azure
 import (
 	"context"
 	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+	"golang.org/x/sync/errgroup"
+
 	"github.com/Xover-Official/Xover/internal/cloud"
 )
 
-// AzureAdapter implements CloudAdapter for Azure
-type AzureAdapter struct {
-	vmClient       *armcompute.VirtualMachinesClient
-	subscriptionID string
+// vmSize is the rough on-demand price and memory of a VM size
+type vmSize struct {
+	costPerMonth float64
+	memoryGiB    float64
+}
+
+// mockVMPricing provides a rough cost estimate per month for VM sizes, and
+// their memory for turning available bytes into a utilization percentage.
+// In a real application, this would use the Azure Retail Prices API.
+var mockVMPricing = map[string]vmSize{
+	"Standard_B1s":    {costPerMonth: 7.59, memoryGiB: 1},
+	"Standard_B2s":    {costPerMonth: 30.37, memoryGiB: 4},
+	"Standard_D2s_v3": {costPerMonth: 70.08, memoryGiB: 8},
+	"Standard_D4s_v3": {costPerMonth: 140.16, memoryGiB: 16},
+	"Standard_D8s_v3": {costPerMonth: 280.32, memoryGiB: 32},
+	"Standard_E2s_v3": {costPerMonth: 91.98, memoryGiB: 16},
+	"Standard_F2s_v2": {costPerMonth: 61.76, memoryGiB: 4},
+}
+
+// mockSQLPricing provides a rough cost estimate per month for Azure SQL
+// service objectives
+var mockSQLPricing = map[string]float64{
+	"Basic":       4.90,
+	"S0":          14.72,
+	"S1":          29.43,
+	"S2":          73.61,
+	"GP_Gen5_2":   370.08,
+	"GP_Gen5_4":   740.16,
+	"BC_Gen5_2":   997.20,
+	"GP_S_Gen5_1": 185.04,
+}
+
+// Azure Monitor metric names
+const (
+	metricVMCPU          = "Percentage CPU"
+	metricVMAvailableMem = "Available Memory Bytes"
+	metricSQLCPU         = "cpu_percent"
+	metricSQLMemory      = "sql_instance_memory_percent"
+)
+
+// metricsWindow is how far back utilization is averaged
+const metricsWindow = time.Hour
+
+// metricsWorkers bounds concurrent Azure Monitor requests
+const metricsWorkers = 10
+
+// Adapter implements the cloud.CloudAdapter interface for Azure, covering
+// virtual machines and Azure SQL databases.
+type Adapter struct {
+	vms          vmAPI
+	sql          sqlAPI
+	metrics      metricsAPI
+	subscription string
+	region       string
+	dryRun       bool
+	cfg          cloud.CloudConfig
 }
 
-// NewAzureAdapter creates a new Azure adapter
-func NewAzureAdapter(subscriptionID string) (*AzureAdapter, error) {
+// New creates an Azure adapter using the default Azure credential chain
+// (environment, workload identity, managed identity or the Azure CLI). It
+// scans cfg.Subscription, or AZURE_SUBSCRIPTION_ID when that is empty, and
+// limits the scan to the cfg.Region location when it is set.
+func New(ctx context.Context, cfg cloud.CloudConfig) (*Adapter, error) {
+	subscription := cfg.Subscription
+	if subscription == "" {
+		subscription = os.Getenv("AZURE_SUBSCRIPTION_ID")
+	}
+	if subscription == "" {
+		return nil, fmt.Errorf("no Azure subscription configured: set it in the cloud config or AZURE_SUBSCRIPTION_ID")
+	}
+
 	cred, err := azidentity.NewDefaultAzureCredential(nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get Azure credentials: %w", err)
 	}
 
-	vmClient, err := armcompute.NewVirtualMachinesClient(subscriptionID, cred, nil)
+	vms, err := armcompute.NewVirtualMachinesClient(subscription, cred, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create VM client: %w", err)
 	}
+	servers, err := armsql.NewServersClient(subscription, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SQL server client: %w", err)
+	}
+	databases, err := armsql.NewDatabasesClient(subscription, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SQL database client: %w", err)
+	}
+	metrics, err := armmonitor.NewMetricsClient(subscription, cred, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metrics client: %w", err)
+	}
 
-	return &AzureAdapter{
-		vmClient:       vmClient,
-		subscriptionID: subscriptionID,
+	return &Adapter{
+		vms:          vmClient{vms: vms},
+		sql:          sqlClient{servers: servers, databases: databases},
+		metrics:      metricsClient{metrics: metrics},
+		subscription: subscription,
+		region:       cfg.Region,
+		dryRun:       cfg.DryRun,
+		cfg:          cfg,
 	}, nil
 }
 
-// FetchResources returns VM resources
-func (a *AzureAdapter) FetchResources(ctx context.Context) ([]*cloud.ResourceV2, error) {
-	vmResources, err := a.fetchVMs()
+// FetchResources retrieves VMs and SQL databases and converts them to the
+// canonical ResourceV2 model.
+func (a *Adapter) FetchResources(ctx context.Context) ([]*cloud.ResourceV2, error) {
+	var vmResources, sqlResources []*cloud.ResourceV2
+
+	// Fetch VMs and SQL databases concurrently
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		var err error
+		if vmResources, err = a.fetchVMs(gctx); err != nil {
+			return fmt.Errorf("failed to fetch VMs: %w", err)
+		}
+		return nil
+	})
+	g.Go(func() error {
+		var err error
+		if sqlResources, err = a.fetchSQLDatabases(gctx); err != nil {
+			return fmt.Errorf("failed to fetch SQL databases: %w", err)
+		}
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	return append(vmResources, sqlResources...), nil
+}
+
+func (a *Adapter) fetchVMs(ctx context.Context) ([]*cloud.ResourceV2, error) {
+	vms, err := a.vms.listVMs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var resources []*cloud.ResourceV2
+	for _, vm := range vms {
+		resource, ok := vmToResource(vm, a.subscription)
+		if !ok {
+			log.Printf("skipping Azure VM without a resource ID")
+			continue
+		}
+		if a.inRegion(resource) {
+			resources = append(resources, resource)
+		}
+	}
+
+	a.applyMetrics(ctx, resources, a.vmMetrics)
+	return resources, nil
+}
+
+func (a *Adapter) fetchSQLDatabases(ctx context.Context) ([]*cloud.ResourceV2, error) {
+	databases, err := a.sql.listDatabases(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch VMs: %w", err)
+		return nil, err
+	}
+
+	var resources []*cloud.ResourceV2
+	for _, db := range databases {
+		resource, ok := sqlDatabaseToResource(db, a.subscription)
+		if !ok {
+			log.Printf("skipping Azure SQL database without a resource ID")
+			continue
+		}
+		if a.inRegion(resource) {
+			resources = append(resources, resource)
+		}
 	}
-	return vmResources, nil
+
+	a.applyMetrics(ctx, resources, a.sqlMetrics)
+	return resources, nil
 }
 
-// GetSpotPrice satisfies the CloudAdapter interface
-func (a *AzureAdapter) GetSpotPrice(ctx context.Context, region, instanceType string) (float64, error) {
-	// Placeholder: implement actual Azure pricing API integration
-	return 0.45, nil
+// inRegion reports whether a resource is in the configured location.
+// Azure reports locations in lower case without spaces, e.g. "eastus".
+func (a *Adapter) inRegion(resource *cloud.ResourceV2) bool {
+	return a.region == "" || strings.EqualFold(resource.Region, a.region)
 }
 
-// ListZones satisfies the CloudAdapter interface
-func (a *AzureAdapter) ListZones(ctx context.Context, region string) ([]string, error) {
-	// Placeholder: implement actual Azure zones listing
-	return []string{"1", "2", "3"}, nil
+// applyMetrics fetches utilization for each resource with a bounded pool of
+// workers. A resource without metrics is still reported.
+func (a *Adapter) applyMetrics(ctx context.Context, resources []*cloud.ResourceV2, fetch func(context.Context, *cloud.ResourceV2) (cloud.Metrics, error)) {
+	var g errgroup.Group
+	g.SetLimit(metricsWorkers)
+	for _, resource := range resources {
+		g.Go(func() error {
+			native, err := fetch(ctx, resource)
+			if err != nil {
+				log.Printf("Azure Monitor metrics incomplete for %s: %v", resource.ID, err)
+			}
+			a.resourceMetrics(ctx, resource, native).Apply(resource)
+			return nil
+		})
+	}
+	g.Wait()
+}
+
+// vmMetrics reads CPU and memory from Azure Monitor. Memory is reported as
+// available bytes, so it needs the VM size's memory to become a percentage.
+func (a *Adapter) vmMetrics(ctx context.Context, resource *cloud.ResourceV2) (cloud.Metrics, error) {
+	end := time.Now()
+	start := end.Add(-metricsWindow)
+	metrics := cloud.Metrics{}
+
+	cpu, ok, err := a.metrics.average(ctx, resource.ID, metricVMCPU, start, end)
+	if err != nil {
+		return metrics, err
+	}
+	if ok {
+		metrics[cloud.MetricCPUUsage] = cpu
+	}
+
+	size, known := mockVMPricing[fmt.Sprint(resource.Metadata["instance_type"])]
+	if !known {
+		return metrics, nil
+	}
+	available, ok, err := a.metrics.average(ctx, resource.ID, metricVMAvailableMem, start, end)
+	if err != nil {
+		return metrics, err
+	}
+	if ok {
+		total := size.memoryGiB * (1 << 30)
+		metrics[cloud.MetricMemoryUsage] = max(0, 100*(1-available/total))
+	}
+	return metrics, nil
+}
+
+// sqlMetrics reads CPU and memory from Azure Monitor. DTU-based databases do
+// not report memory, so a failed memory query is not an error.
+func (a *Adapter) sqlMetrics(ctx context.Context, resource *cloud.ResourceV2) (cloud.Metrics, error) {
+	end := time.Now()
+	start := end.Add(-metricsWindow)
+	metrics := cloud.Metrics{}
+
+	cpu, ok, err := a.metrics.average(ctx, resource.ID, metricSQLCPU, start, end)
+	if err != nil {
+		return metrics, err
+	}
+	if ok {
+		metrics[cloud.MetricCPUUsage] = cpu
+	}
+	if memory, ok, err := a.metrics.average(ctx, resource.ID, metricSQLMemory, start, end); err == nil && ok {
+		metrics[cloud.MetricMemoryUsage] = memory
+	}
+	return metrics, nil
+}
+
+// resourceMetrics combines the Azure Monitor metrics of a resource with the
+// configured external provider according to the metrics mode. An external
+// provider that fails falls back to Azure Monitor.
+func (a *Adapter) resourceMetrics(ctx context.Context, resource *cloud.ResourceV2, native cloud.Metrics) cloud.Metrics {
+	provider := a.cfg.Metrics
+	if provider == nil {
+		return native
+	}
+
+	external, err := provider.FetchMetrics(ctx, resource)
+	if err != nil {
+		log.Printf("%s metrics unavailable for %s, falling back to Azure Monitor: %v", provider.Name(), resource.ID, err)
+		return native
+	}
+	if a.cfg.MetricsMode == cloud.MetricsModeReplace {
+		return external
+	}
+	return external.Merge(native)
 }
 
-// fetchVMs retrieves VM resources
-func (a *AzureAdapter) fetchVMs() ([]*cloud.ResourceV2, error) {
+// GetResource retrieves a VM or SQL database by its Azure resource ID
+func (a *Adapter) GetResource(ctx context.Context, id string) (*cloud.ResourceV2, error) {
+	rid, err := arm.ParseResourceID(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Azure resource ID %s: %w", id, err)
+	}
+
+	var resource *cloud.ResourceV2
+	var fetch func(context.Context, *cloud.ResourceV2) (cloud.Metrics, error)
+	switch {
+	case strings.EqualFold(rid.ResourceType.String(), "Microsoft.Compute/virtualMachines"):
+		vm, err := a.vms.getVM(ctx, rid.ResourceGroupName, rid.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get VM %s: %w", id, err)
+		}
+		resource, _ = vmToResource(vm, a.subscription)
+		fetch = a.vmMetrics
+	case strings.EqualFold(rid.ResourceType.String(), "Microsoft.Sql/servers/databases") && rid.Parent != nil:
+		db, err := a.sql.getDatabase(ctx, rid.ResourceGroupName, rid.Parent.Name, rid.Name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get SQL database %s: %w", id, err)
+		}
+		resource, _ = sqlDatabaseToResource(db, a.subscription)
+		fetch = a.sqlMetrics
+	default:
+		return nil, fmt.Errorf("unsupported Azure resource type %s", rid.ResourceType)
+	}
+	if resource == nil {
+		return nil, fmt.Errorf("resource %s not found", id)
+	}
+
+	native, err := fetch(ctx, resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metrics for %s: %w", id, err)
+	}
+	a.resourceMetrics(ctx, resource, native).Apply(resource)
+	return resource, nil
+}
+
+// unknownState is reported when Azure omits a resource's state
+const unknownState = "unknown"
+
+// powerState maps a VM's "PowerState/..." instance view status onto the
+// canonical states. A deallocated VM no longer incurs compute charges; a
+// merely stopped one still does.
+func powerState(vm *armcompute.VirtualMachine) string {
+	if vm.Properties == nil || vm.Properties.InstanceView == nil {
+		return unknownState
+	}
+	for _, status := range vm.Properties.InstanceView.Statuses {
+		if status == nil || status.Code == nil {
+			continue
+		}
+		code, ok := strings.CutPrefix(*status.Code, "PowerState/")
+		if !ok {
+			continue
+		}
+		switch code {
+		case "starting", "running":
+			return "running"
+		case "stopping", "deallocating":
+			return "stopping"
+		default: // stopped, deallocated
+			return code
+		}
+	}
+	return unknownState
+}
+
+// azureTags converts Azure's pointer-valued tags
+func azureTags(tags map[string]*string) map[string]string {
+	out := make(map[string]string, len(tags))
+	for k, v := range tags {
+		if v != nil {
+			out[k] = *v
+		}
+	}
+	return out
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// vmToResource converts an SDK VM to the canonical model, without
+// utilization metrics. It returns false if the VM has no resource ID.
+func vmToResource(vm *armcompute.VirtualMachine, subscription string) (*cloud.ResourceV2, bool) {
+	if vm == nil || vm.ID == nil {
+		return nil, false
+	}
+
+	resource := &cloud.ResourceV2{
+		ID:       *vm.ID,
+		Type:     cloud.ResourceTypeVM,
+		Provider: cloud.ProviderAzure,
+		Region:   deref(vm.Location),
+		Account:  subscription,
+		Tags:     azureTags(vm.Tags),
+		State:    powerState(vm),
+		Metadata: map[string]interface{}{"name": deref(vm.Name)},
+	}
+
+	if props := vm.Properties; props != nil {
+		if props.TimeCreated != nil {
+			resource.CreatedAt = *props.TimeCreated
+		}
+		if props.HardwareProfile != nil && props.HardwareProfile.VMSize != nil {
+			size := string(*props.HardwareProfile.VMSize)
+			resource.Metadata["instance_type"] = size
+			resource.CostPerMonth = mockVMPricing[size].costPerMonth
+		}
+	}
+	return resource, true
+}
+
+// sqlDatabaseToResource converts an SDK database to the canonical model. It
+// returns false if the database has no resource ID.
+func sqlDatabaseToResource(db *armsql.Database, subscription string) (*cloud.ResourceV2, bool) {
+	if db == nil || db.ID == nil {
+		return nil, false
+	}
+
 	resource := &cloud.ResourceV2{
-		ID:           "vm-placeholder",
-		Type:         "azure-vm",
-		Provider:     cloud.ProviderAzure,
-		Region:       "eastus",
-		Tags:         make(map[string]string),
-		CPUUsage:     45.0,
-		MemoryUsage:  55.0,
-		CostPerMonth: 150.0,
+		ID:       *db.ID,
+		Type:     cloud.ResourceTypeAzureSQL,
+		Provider: cloud.ProviderAzure,
+		Region:   deref(db.Location),
+		Account:  subscription,
+		Tags:     azureTags(db.Tags),
+		State:    unknownState,
+		// Azure SQL encrypts data at rest (TDE) by default
+		EncryptionEnabled: true,
+		Metadata:          map[string]interface{}{"name": deref(db.Name)},
 	}
-	return []*cloud.ResourceV2{resource}, nil
+
+	if db.SKU != nil && db.SKU.Name != nil {
+		resource.Metadata["instance_class"] = *db.SKU.Name
+		resource.CostPerMonth = mockSQLPricing[*db.SKU.Name]
+	}
+	if props := db.Properties; props != nil {
+		if props.Status != nil {
+			resource.State = strings.ToLower(string(*props.Status))
+		}
+		if props.CreationDate != nil {
+			resource.CreatedAt = *props.CreationDate
+		}
+	}
+	return resource, true
+}
+
+// GetResources looks resources up one at a time; ARM has no batch get by
+// resource ID
+func (a *Adapter) GetResources(ctx context.Context, ids []string) (map[string]*cloud.ResourceV2, error) {
+	return cloud.GetResourcesOneByOne(ctx, a, ids)
 }
 
-// ApplyOptimization updated to match interface signature
-func (a *AzureAdapter) ApplyOptimization(ctx context.Context, resource *cloud.ResourceV2, action string) (string, float64, error) {
-	return fmt.Sprintf("Applied %s to Azure resource %s", action, resource.ID), 50.0, nil
+// ApplyOptimization applies an optimization to an Azure VM. Stopping a VM
+// deallocates it so compute billing stops. In dry run it only returns the
+// estimated savings.
+func (a *Adapter) ApplyOptimization(ctx context.Context, resource *cloud.ResourceV2, action string) (float64, error) {
+	// Protected resources are refused here even if the engine's checks were bypassed
+	if err := a.cfg.Protection.CheckMutation(resource, action); err != nil {
+		log.Printf("protection event: %v", err)
+		return 0, err
+	}
+	actionType, err := cloud.ValidateAction(resource, action)
+	if err != nil {
+		return 0, err
+	}
+
+	estimatedSavings := resource.CostPerMonth * a.cfg.SavingsRatio(action)
+
+	var targetSize string
+	switch {
+	case resource.Type != cloud.ResourceTypeVM:
+		return 0, fmt.Errorf("%s on %s resources is not implemented by the Azure adapter", actionType, resource.Type)
+	case actionType == cloud.ActionResize:
+		// The engine records the size it recommends on the resource
+		targetSize = resource.RightSizeRecommendation
+		if targetSize == "" {
+			return 0, fmt.Errorf("%w: no target size recommended for %s", cloud.ErrInvalidAction, resource.ID)
+		}
+		if size, ok := mockVMPricing[targetSize]; ok {
+			estimatedSavings = max(0, resource.CostPerMonth-size.costPerMonth)
+		}
+	case actionType == cloud.ActionTerminate:
+		// Termination is irreversible, so its safeguards run even in dry run
+		if err := a.checkTermination(resource, time.Now()); err != nil {
+			log.Printf("termination safeguard: %v", err)
+			return 0, err
+		}
+		// A deleted VM stops costing anything
+		estimatedSavings = resource.CostPerMonth
+	}
+
+	// A per-action override (set by an operator at approval) wins over the global setting
+	if cloud.DryRun(ctx, a.dryRun) {
+		return estimatedSavings, nil
+	}
+
+	rid, err := arm.ParseResourceID(resource.ID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Azure resource ID %s: %w", resource.ID, err)
+	}
+
+	switch actionType {
+	case cloud.ActionStop:
+		return estimatedSavings, a.vms.deallocate(ctx, rid.ResourceGroupName, rid.Name)
+	case cloud.ActionResize:
+		return estimatedSavings, a.vms.resize(ctx, rid.ResourceGroupName, rid.Name, targetSize)
+	case cloud.ActionTerminate:
+		return estimatedSavings, a.vms.delete(ctx, rid.ResourceGroupName, rid.Name)
+	default:
+		return 0, fmt.Errorf("%s on %s resources is not implemented by the Azure adapter", actionType, resource.Type)
+	}
+}
+
+// checkTermination applies the termination safeguards. Azure does not
+// report when a VM was stopped, so only a deallocated VM may be deleted:
+// stopping it first is the soft terminate, and MinIdle is left to the
+// engine's idle analysis.
+func (a *Adapter) checkTermination(resource *cloud.ResourceV2, now time.Time) error {
+	if err := a.cfg.Termination.CheckProtected(resource); err != nil {
+		return err
+	}
+	if err := a.cfg.Termination.CheckBackup(resource, now); err != nil {
+		return err
+	}
+	if after, pending := cloud.PendingTermination(resource); pending {
+		return cloud.CheckGracePeriod(resource, after, now)
+	}
+	if resource.State != "deallocated" {
+		return fmt.Errorf("%w: %s must be deallocated before it is deleted (state %q)", cloud.ErrTerminationRefused, resource.ID, resource.State)
+	}
+	return nil
+}
+
+// GetSpotPrice returns the hourly spot price for a VM size in a region
+func (a *Adapter) GetSpotPrice(zone, instanceType string) (float64, error) {
+	// Mock implementation - in production, this would call the Azure Retail Prices API
+	prices := map[string]float64{
+		"Standard_B2s":    0.0083,
+		"Standard_D2s_v3": 0.0192,
+		"Standard_D4s_v3": 0.0384,
+		"Standard_F2s_v2": 0.0169,
+	}
+	if price, ok := prices[instanceType]; ok {
+		return price, nil
+	}
+	return 0.0192, nil
+}
+
+// ListZones returns the availability zones of the configured region
+func (a *Adapter) ListZones() ([]string, error) {
+	// Mock implementation - Azure numbers zones within each region
+	return []string{"1", "2", "3"}, nil
 }
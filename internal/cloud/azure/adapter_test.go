@@ -0,0 +1,317 @@
+package azure
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+const (
+	webID    = "/subscriptions/sub-1/resourceGroups/web/providers/Microsoft.Compute/virtualMachines/web-1"
+	batchID  = "/subscriptions/sub-1/resourceGroups/batch/providers/Microsoft.Compute/virtualMachines/batch-eu"
+	ordersID = "/subscriptions/sub-1/resourceGroups/data/providers/Microsoft.Sql/servers/sql-1/databases/orders"
+)
+
+type fakeVMs struct {
+	mu      sync.Mutex
+	vms     []*armcompute.VirtualMachine
+	calls   []string
+	listErr error
+}
+
+func (f *fakeVMs) listVMs(context.Context) ([]*armcompute.VirtualMachine, error) {
+	return f.vms, f.listErr
+}
+
+func (f *fakeVMs) getVM(_ context.Context, _, name string) (*armcompute.VirtualMachine, error) {
+	for _, vm := range f.vms {
+		if *vm.Name == name {
+			return vm, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeVMs) record(call string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, call)
+	return nil
+}
+
+func (f *fakeVMs) deallocate(_ context.Context, group, name string) error {
+	return f.record("deallocate " + group + "/" + name)
+}
+
+func (f *fakeVMs) resize(_ context.Context, group, name, size string) error {
+	return f.record("resize " + group + "/" + name + " " + size)
+}
+
+func (f *fakeVMs) delete(_ context.Context, group, name string) error {
+	return f.record("delete " + group + "/" + name)
+}
+
+type fakeSQL struct {
+	databases []*armsql.Database
+}
+
+func (f *fakeSQL) listDatabases(context.Context) ([]*armsql.Database, error) {
+	return f.databases, nil
+}
+
+func (f *fakeSQL) getDatabase(_ context.Context, _, server, name string) (*armsql.Database, error) {
+	for _, db := range f.databases {
+		if *db.Name == name {
+			return db, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+// fakeMetrics returns canned averages keyed by "<resource ID>|<metric>"
+type fakeMetrics struct {
+	values map[string]float64
+	err    error
+}
+
+func (f *fakeMetrics) average(_ context.Context, resourceID, metric string, _, _ time.Time) (float64, bool, error) {
+	if f.err != nil {
+		return 0, false, f.err
+	}
+	v, ok := f.values[resourceID+"|"+metric]
+	return v, ok, nil
+}
+
+func vm(id, name, location, size, power string) *armcompute.VirtualMachine {
+	return &armcompute.VirtualMachine{
+		ID:       to.Ptr(id),
+		Name:     to.Ptr(name),
+		Location: to.Ptr(location),
+		Properties: &armcompute.VirtualMachineProperties{
+			HardwareProfile: &armcompute.HardwareProfile{VMSize: to.Ptr(armcompute.VirtualMachineSizeTypes(size))},
+			InstanceView: &armcompute.VirtualMachineInstanceView{Statuses: []*armcompute.InstanceViewStatus{
+				{Code: to.Ptr("ProvisioningState/succeeded")},
+				{Code: to.Ptr("PowerState/" + power)},
+			}},
+		},
+	}
+}
+
+func newTestAdapter() (*Adapter, *fakeVMs) {
+	web := vm(webID, "web-1", "eastus", "Standard_D2s_v3", "running")
+	web.Tags = map[string]*string{"env": to.Ptr("prod")}
+	web.Properties.TimeCreated = to.Ptr(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	vms := &fakeVMs{vms: []*armcompute.VirtualMachine{
+		web,
+		vm(batchID, "batch-eu", "westeurope", "Standard_B2s", "deallocated"),
+		{Name: to.Ptr("no-id")},
+	}}
+	sql := &fakeSQL{databases: []*armsql.Database{{
+		ID:       to.Ptr(ordersID),
+		Name:     to.Ptr("orders"),
+		Location: to.Ptr("eastus"),
+		SKU:      &armsql.SKU{Name: to.Ptr("S1")},
+		Tags:     map[string]*string{"team": to.Ptr("orders")},
+		Properties: &armsql.DatabaseProperties{
+			Status: to.Ptr(armsql.DatabaseStatusOnline),
+		},
+	}}}
+	metrics := &fakeMetrics{values: map[string]float64{
+		webID + "|" + metricVMCPU:            12.5,
+		webID + "|" + metricVMAvailableMem:   6 * (1 << 30),
+		ordersID + "|" + metricSQLCPU:        40,
+		ordersID + "|" + metricSQLMemory:     55,
+		batchID + "|" + metricVMCPU:          0,
+		batchID + "|" + metricVMAvailableMem: 4 * (1 << 30),
+	}}
+
+	adapter := &Adapter{
+		vms:          vms,
+		sql:          sql,
+		metrics:      metrics,
+		subscription: "sub-1",
+		region:       "eastus",
+	}
+	return adapter, vms
+}
+
+func TestAdapter_ImplementsCloudAdapter(t *testing.T) {
+	var _ cloud.CloudAdapter = (*Adapter)(nil)
+}
+
+func TestFetchResources_ConvertsResourcesInRegion(t *testing.T) {
+	adapter, _ := newTestAdapter()
+
+	resources, err := adapter.FetchResources(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resources, 2, "VMs outside eastus and without an ID are skipped")
+
+	web := resources[0]
+	assert.Equal(t, webID, web.ID)
+	assert.Equal(t, cloud.ResourceTypeVM, web.Type)
+	assert.Equal(t, cloud.ProviderAzure, web.Provider)
+	assert.Equal(t, "eastus", web.Region)
+	assert.Equal(t, "sub-1", web.Account)
+	assert.Equal(t, "running", web.State)
+	assert.Equal(t, 12.5, web.CPUUsage)
+	assert.Equal(t, 25.0, web.MemoryUsage, "6 of 8 GiB available")
+	assert.Equal(t, 70.08, web.CostPerMonth)
+	assert.Equal(t, "Standard_D2s_v3", web.Metadata["instance_type"])
+	assert.Equal(t, map[string]string{"env": "prod"}, web.Tags)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), web.CreatedAt)
+
+	db := resources[1]
+	assert.Equal(t, ordersID, db.ID)
+	assert.Equal(t, cloud.ResourceTypeAzureSQL, db.Type)
+	assert.Equal(t, "online", db.State)
+	assert.Equal(t, 40.0, db.CPUUsage)
+	assert.Equal(t, 55.0, db.MemoryUsage)
+	assert.Equal(t, 29.43, db.CostPerMonth)
+	assert.Equal(t, "orders", db.Tags["team"])
+}
+
+func TestFetchResources_ListFailureFails(t *testing.T) {
+	adapter, vms := newTestAdapter()
+	vms.listErr = errors.New("authorization failed")
+
+	_, err := adapter.FetchResources(context.Background())
+	assert.ErrorContains(t, err, "failed to fetch VMs")
+}
+
+func TestFetchResources_MetricsFailureKeepsResources(t *testing.T) {
+	adapter, _ := newTestAdapter()
+	adapter.metrics = &fakeMetrics{err: errors.New("throttled")}
+
+	resources, err := adapter.FetchResources(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	assert.Zero(t, resources[0].CPUUsage)
+}
+
+func TestResourceMetrics_MergesExternalProvider(t *testing.T) {
+	adapter, _ := newTestAdapter()
+	adapter.cfg.Metrics = stubMetricsProvider{metrics: cloud.Metrics{cloud.MetricMemoryUsage: 63}}
+
+	resources, err := adapter.FetchResources(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 12.5, resources[0].CPUUsage, "Azure Monitor fills the gaps")
+	assert.Equal(t, 63.0, resources[0].MemoryUsage)
+}
+
+func TestPowerState(t *testing.T) {
+	assert.Equal(t, "stopping", powerState(vm(webID, "web-1", "eastus", "", "deallocating")))
+	assert.Equal(t, "stopped", powerState(vm(webID, "web-1", "eastus", "", "stopped")))
+	assert.Equal(t, "unknown", powerState(&armcompute.VirtualMachine{}))
+}
+
+func TestGetResource(t *testing.T) {
+	adapter, _ := newTestAdapter()
+	adapter.region = ""
+
+	web, err := adapter.GetResource(context.Background(), webID)
+	require.NoError(t, err)
+	assert.Equal(t, 25.0, web.MemoryUsage)
+
+	db, err := adapter.GetResource(context.Background(), strings.ToLower(ordersID))
+	require.NoError(t, err)
+	assert.Equal(t, 40.0, db.CPUUsage)
+
+	_, err = adapter.GetResource(context.Background(), "/subscriptions/sub-1/resourceGroups/web/providers/Microsoft.Storage/storageAccounts/logs")
+	assert.Error(t, err)
+	_, err = adapter.GetResource(context.Background(), "web-1")
+	assert.Error(t, err)
+}
+
+func TestApplyOptimization_DryRunDoesNotMutate(t *testing.T) {
+	adapter, vms := newTestAdapter()
+	adapter.dryRun = true
+
+	web := &cloud.ResourceV2{ID: webID, Type: cloud.ResourceTypeVM, State: "running", CostPerMonth: 70.08}
+	savings, err := adapter.ApplyOptimization(context.Background(), web, "stop")
+	require.NoError(t, err)
+	assert.Equal(t, 70.08, savings)
+	assert.Empty(t, vms.calls)
+
+	// A per-action override turns dry run off
+	savings, err = adapter.ApplyOptimization(cloud.WithDryRun(context.Background(), false), web, "stop")
+	require.NoError(t, err)
+	assert.Equal(t, 70.08, savings)
+	assert.Equal(t, []string{"deallocate web/web-1"}, vms.calls)
+}
+
+func TestApplyOptimization_Resize(t *testing.T) {
+	adapter, vms := newTestAdapter()
+	web := &cloud.ResourceV2{ID: webID, Type: cloud.ResourceTypeVM, State: "running", CostPerMonth: 70.08}
+
+	_, err := adapter.ApplyOptimization(context.Background(), web, "resize")
+	assert.ErrorIs(t, err, cloud.ErrInvalidAction, "no recommended size")
+
+	web.RightSizeRecommendation = "Standard_B2s"
+	savings, err := adapter.ApplyOptimization(context.Background(), web, "resize")
+	require.NoError(t, err)
+	assert.InDelta(t, 39.71, savings, 0.001)
+	assert.Equal(t, []string{"resize web/web-1 Standard_B2s"}, vms.calls)
+}
+
+func TestApplyOptimization_TerminateRequiresDeallocation(t *testing.T) {
+	adapter, vms := newTestAdapter()
+	backedUp := map[string]string{cloud.DefaultBackupTagKey: time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)}
+
+	web := &cloud.ResourceV2{ID: webID, Type: cloud.ResourceTypeVM, State: "running", CostPerMonth: 70.08, Tags: backedUp}
+	_, err := adapter.ApplyOptimization(context.Background(), web, "terminate")
+	assert.ErrorIs(t, err, cloud.ErrTerminationRefused)
+
+	// The safeguard applies in dry run too
+	_, err = adapter.ApplyOptimization(cloud.WithDryRun(context.Background(), true), web, "terminate")
+	assert.ErrorIs(t, err, cloud.ErrTerminationRefused)
+	assert.Empty(t, vms.calls)
+
+	batch := &cloud.ResourceV2{ID: batchID, Type: cloud.ResourceTypeVM, State: "deallocated", CostPerMonth: 30.37}
+	_, err = adapter.ApplyOptimization(context.Background(), batch, "terminate")
+	assert.ErrorIs(t, err, cloud.ErrTerminationRefused, "no backup tag")
+
+	batch.Tags = backedUp
+	savings, err := adapter.ApplyOptimization(context.Background(), batch, "terminate")
+	require.NoError(t, err)
+	assert.Equal(t, 30.37, savings)
+	assert.Equal(t, []string{"delete batch/batch-eu"}, vms.calls)
+}
+
+func TestApplyOptimization_RefusesProtectedAndUnsupported(t *testing.T) {
+	adapter, vms := newTestAdapter()
+	adapter.cfg.Protection = cloud.ProtectionPolicy{ResourceIDs: []string{"/subscriptions/*/resourceGroups/*/providers/Microsoft.Compute/virtualMachines/payment-*"}}
+
+	payment := "/subscriptions/sub-1/resourceGroups/web/providers/Microsoft.Compute/virtualMachines/payment-api"
+	_, err := adapter.ApplyOptimization(context.Background(), &cloud.ResourceV2{ID: payment, Type: cloud.ResourceTypeVM}, "stop")
+	assert.ErrorIs(t, err, cloud.ErrResourceProtected)
+
+	db := &cloud.ResourceV2{ID: ordersID, Type: cloud.ResourceTypeAzureSQL}
+	_, err = adapter.ApplyOptimization(context.Background(), db, "stop")
+	assert.ErrorIs(t, err, cloud.ErrInvalidAction)
+
+	_, err = adapter.ApplyOptimization(context.Background(), db, "resize")
+	assert.ErrorContains(t, err, "not implemented")
+	assert.Empty(t, vms.calls)
+}
+
+type stubMetricsProvider struct {
+	metrics cloud.Metrics
+}
+
+func (s stubMetricsProvider) Name() string { return "stub" }
+
+func (s stubMetricsProvider) FetchMetrics(ctx context.Context, resource *cloud.ResourceV2) (cloud.Metrics, error) {
+	return s.metrics, nil
+}
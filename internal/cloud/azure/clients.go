@@ -0,0 +1,200 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute/v6"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/sql/armsql"
+)
+
+// vmAPI is the part of the Azure compute API the adapter uses. VMs are
+// returned with their instance view so the power state is known.
+type vmAPI interface {
+	listVMs(ctx context.Context) ([]*armcompute.VirtualMachine, error)
+	getVM(ctx context.Context, resourceGroup, name string) (*armcompute.VirtualMachine, error)
+	deallocate(ctx context.Context, resourceGroup, name string) error
+	resize(ctx context.Context, resourceGroup, name, size string) error
+	delete(ctx context.Context, resourceGroup, name string) error
+}
+
+// sqlAPI is the part of the Azure SQL API the adapter uses
+type sqlAPI interface {
+	listDatabases(ctx context.Context) ([]*armsql.Database, error)
+	getDatabase(ctx context.Context, resourceGroup, server, name string) (*armsql.Database, error)
+}
+
+// metricsAPI reads Azure Monitor metrics
+type metricsAPI interface {
+	// average returns the mean of a metric over [start, end], and false
+	// when the resource reported no data
+	average(ctx context.Context, resourceID, metric string, start, end time.Time) (float64, bool, error)
+}
+
+type vmClient struct {
+	vms *armcompute.VirtualMachinesClient
+}
+
+// listVMs lists every VM in the subscription, then joins in the power
+// states, which the full listing does not include
+func (c vmClient) listVMs(ctx context.Context) ([]*armcompute.VirtualMachine, error) {
+	var vms []*armcompute.VirtualMachine
+	pager := c.vms.NewListAllPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		vms = append(vms, page.Value...)
+	}
+
+	views := make(map[string]*armcompute.VirtualMachineInstanceView)
+	statusPager := c.vms.NewListAllPager(&armcompute.VirtualMachinesClientListAllOptions{StatusOnly: to.Ptr("true")})
+	for statusPager.More() {
+		page, err := statusPager.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, vm := range page.Value {
+			if vm.ID != nil && vm.Properties != nil {
+				views[strings.ToLower(*vm.ID)] = vm.Properties.InstanceView
+			}
+		}
+	}
+
+	for _, vm := range vms {
+		if vm.ID != nil && vm.Properties != nil {
+			vm.Properties.InstanceView = views[strings.ToLower(*vm.ID)]
+		}
+	}
+	return vms, nil
+}
+
+func (c vmClient) getVM(ctx context.Context, resourceGroup, name string) (*armcompute.VirtualMachine, error) {
+	resp, err := c.vms.Get(ctx, resourceGroup, name, &armcompute.VirtualMachinesClientGetOptions{
+		Expand: to.Ptr(armcompute.InstanceViewTypesInstanceView),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &resp.VirtualMachine, nil
+}
+
+// deallocate stops a VM and releases its compute, which unlike a plain
+// power off stops the compute charges
+func (c vmClient) deallocate(ctx context.Context, resourceGroup, name string) error {
+	poller, err := c.vms.BeginDeallocate(ctx, resourceGroup, name, nil)
+	if err != nil {
+		return err
+	}
+	_, err = poller.PollUntilDone(ctx, nil)
+	return err
+}
+
+func (c vmClient) resize(ctx context.Context, resourceGroup, name, size string) error {
+	update := armcompute.VirtualMachineUpdate{
+		Properties: &armcompute.VirtualMachineProperties{
+			HardwareProfile: &armcompute.HardwareProfile{VMSize: to.Ptr(armcompute.VirtualMachineSizeTypes(size))},
+		},
+	}
+	poller, err := c.vms.BeginUpdate(ctx, resourceGroup, name, update, nil)
+	if err != nil {
+		return err
+	}
+	_, err = poller.PollUntilDone(ctx, nil)
+	return err
+}
+
+func (c vmClient) delete(ctx context.Context, resourceGroup, name string) error {
+	poller, err := c.vms.BeginDelete(ctx, resourceGroup, name, nil)
+	if err != nil {
+		return err
+	}
+	_, err = poller.PollUntilDone(ctx, nil)
+	return err
+}
+
+type sqlClient struct {
+	servers   *armsql.ServersClient
+	databases *armsql.DatabasesClient
+}
+
+// listDatabases lists the databases of every SQL server in the
+// subscription, leaving out each server's master database
+func (c sqlClient) listDatabases(ctx context.Context) ([]*armsql.Database, error) {
+	var databases []*armsql.Database
+	servers := c.servers.NewListPager(nil)
+	for servers.More() {
+		page, err := servers.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, server := range page.Value {
+			if server.ID == nil || server.Name == nil {
+				continue
+			}
+			id, err := arm.ParseResourceID(*server.ID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid server ID %s: %w", *server.ID, err)
+			}
+
+			pager := c.databases.NewListByServerPager(id.ResourceGroupName, *server.Name, nil)
+			for pager.More() {
+				dbPage, err := pager.NextPage(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("failed to list databases of %s: %w", *server.Name, err)
+				}
+				for _, db := range dbPage.Value {
+					if db.Name != nil && *db.Name == "master" {
+						continue
+					}
+					databases = append(databases, db)
+				}
+			}
+		}
+	}
+	return databases, nil
+}
+
+func (c sqlClient) getDatabase(ctx context.Context, resourceGroup, server, name string) (*armsql.Database, error) {
+	resp, err := c.databases.Get(ctx, resourceGroup, server, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resp.Database, nil
+}
+
+type metricsClient struct {
+	metrics *armmonitor.MetricsClient
+}
+
+func (c metricsClient) average(ctx context.Context, resourceID, metric string, start, end time.Time) (float64, bool, error) {
+	window := end.Sub(start)
+	resp, err := c.metrics.List(ctx, resourceID, &armmonitor.MetricsClientListOptions{
+		Metricnames: to.Ptr(metric),
+		Timespan:    to.Ptr(start.UTC().Format(time.RFC3339) + "/" + end.UTC().Format(time.RFC3339)),
+		Interval:    to.Ptr(fmt.Sprintf("PT%dM", int(window.Minutes()))),
+		Aggregation: to.Ptr("Average"),
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	// One metric over one interval yields at most one value; take the
+	// newest in case the service split the window
+	for _, m := range resp.Value {
+		for _, ts := range m.Timeseries {
+			for i := len(ts.Data) - 1; i >= 0; i-- {
+				if ts.Data[i].Average != nil {
+					return *ts.Data[i].Average, true, nil
+				}
+			}
+		}
+	}
+	return 0, false, nil
+}
@@ -0,0 +1,237 @@
+package cloud
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultResourceCacheTTL is how long a CachingAdapter serves cached results
+// when no TTL is given
+const DefaultResourceCacheTTL = 5 * time.Minute
+
+// fetchKey is the singleflight key for FetchResources; GetResource uses the
+// resource ID prefixed with "id:"
+const fetchKey = "fetch"
+
+// CachingAdapter wraps a CloudAdapter, caching FetchResources and GetResource
+// results for a TTL. It is meant for the read and observe path: dashboards,
+// reports and the engine's observe phase. Concurrent misses for the same
+// data wait on a single upstream call, made with the first caller's context.
+// Errors, including a partial multi-region fetch, are never cached.
+// ApplyOptimization evicts the resource it changed.
+//
+// The lookups an action is checked against always go to the wrapped
+// adapter, so protection tags, state and grace periods are never read from
+// the cache: GetResources, GetResourceMetadata, GetResourceByKey (see
+// Adapter) and Rollback are forwarded uncached.
+//
+// Callers get copies of the cached resources, so changing one does not
+// change what the next caller sees.
+type CachingAdapter struct {
+	CloudAdapter
+	ttl   time.Duration
+	now   func() time.Time
+	group singleflight.Group
+
+	mu         sync.Mutex
+	resources  []*ResourceV2 // nil until fetched
+	fetchedAt  time.Time
+	byID       map[string]cachedResource
+	generation uint64 // bumped by invalidation so fetches already in flight are not stored
+}
+
+type cachedResource struct {
+	resource  *ResourceV2
+	fetchedAt time.Time
+}
+
+// NewCachingAdapter wraps adapter with a cache that keeps results for ttl,
+// or DefaultResourceCacheTTL when ttl is not positive
+func NewCachingAdapter(adapter CloudAdapter, ttl time.Duration) *CachingAdapter {
+	if ttl <= 0 {
+		ttl = DefaultResourceCacheTTL
+	}
+	return &CachingAdapter{
+		CloudAdapter: adapter,
+		ttl:          ttl,
+		now:          time.Now,
+		byID:         make(map[string]cachedResource),
+	}
+}
+
+// FetchResources returns the cached resources while they are fresh and
+// fetches them from the wrapped adapter otherwise
+func (a *CachingAdapter) FetchResources(ctx context.Context) ([]*ResourceV2, error) {
+	a.mu.Lock()
+	if a.resources != nil && a.now().Sub(a.fetchedAt) < a.ttl {
+		resources := cloneResources(a.resources)
+		a.mu.Unlock()
+		return resources, nil
+	}
+	generation := a.generation
+	a.mu.Unlock()
+
+	value, err, _ := a.group.Do(fetchKey, func() (interface{}, error) {
+		resources, err := a.CloudAdapter.FetchResources(ctx)
+		if err != nil {
+			return resources, err
+		}
+		a.mu.Lock()
+		if a.generation == generation {
+			a.resources = resources
+			a.fetchedAt = a.now()
+		}
+		a.mu.Unlock()
+		return resources, nil
+	})
+	resources, _ := value.([]*ResourceV2)
+	return cloneResources(resources), err
+}
+
+// GetResource returns the cached resource while it is fresh and asks the
+// wrapped adapter otherwise. Resources are cached under the ID they were
+// requested by.
+func (a *CachingAdapter) GetResource(ctx context.Context, id string) (*ResourceV2, error) {
+	a.mu.Lock()
+	if cached, ok := a.byID[id]; ok && a.now().Sub(cached.fetchedAt) < a.ttl {
+		a.mu.Unlock()
+		return cloneResource(cached.resource), nil
+	}
+	generation := a.generation
+	a.mu.Unlock()
+
+	value, err, _ := a.group.Do("id:"+id, func() (interface{}, error) {
+		resource, err := a.CloudAdapter.GetResource(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		a.mu.Lock()
+		if a.generation == generation {
+			a.byID[id] = cachedResource{resource: resource, fetchedAt: a.now()}
+		}
+		a.mu.Unlock()
+		return resource, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cloneResource(value.(*ResourceV2)), nil
+}
+
+// GetResourceMetadata asks the wrapped adapter, never the cache, since an
+// action is checked against it. Adapters that are not MetadataGetters are
+// asked with GetResource.
+func (a *CachingAdapter) GetResourceMetadata(ctx context.Context, id string) (*ResourceV2, error) {
+	if getter, ok := a.CloudAdapter.(MetadataGetter); ok {
+		return getter.GetResourceMetadata(ctx, id)
+	}
+	return a.CloudAdapter.GetResource(ctx, id)
+}
+
+// Rollback restores the resource through the wrapped adapter, if it can roll
+// back, and evicts it
+func (a *CachingAdapter) Rollback(ctx context.Context, resource *ResourceV2, action string, snapshot ResourceSnapshot) error {
+	rollbacker, ok := a.CloudAdapter.(Rollbacker)
+	if !ok {
+		return ErrRollbackUnsupported
+	}
+	err := rollbacker.Rollback(ctx, resource, action, snapshot)
+	a.InvalidateResource(resource.ID)
+	return err
+}
+
+// Adapter returns the CachingAdapter as the CloudAdapter to hand to callers
+// that check for optional interfaces. When the wrapped adapter is a
+// ResourceKeyResolver, such as a MultiAdapter, so is the result, and its
+// GetResourceByKey is forwarded uncached; otherwise the result is a itself,
+// so callers keep looking resources up by native ID.
+func (a *CachingAdapter) Adapter() CloudAdapter {
+	if _, ok := a.CloudAdapter.(ResourceKeyResolver); ok {
+		return keyedCachingAdapter{a}
+	}
+	return a
+}
+
+// keyedCachingAdapter is a CachingAdapter over a ResourceKeyResolver
+type keyedCachingAdapter struct {
+	*CachingAdapter
+}
+
+// GetResourceByKey asks the wrapped adapter, never the cache
+func (a keyedCachingAdapter) GetResourceByKey(ctx context.Context, key string) (*ResourceV2, error) {
+	return a.CloudAdapter.(ResourceKeyResolver).GetResourceByKey(ctx, key)
+}
+
+// ApplyOptimization applies the action through the wrapped adapter and then
+// evicts the resource, whether or not the action succeeded, since a failed
+// action may still have changed it
+func (a *CachingAdapter) ApplyOptimization(ctx context.Context, resource *ResourceV2, action string) (float64, error) {
+	savings, err := a.CloudAdapter.ApplyOptimization(ctx, resource, action)
+	a.InvalidateResource(resource.ID)
+	return savings, err
+}
+
+// InvalidateResource evicts the resource with the given native ID or
+// canonical key. The cached FetchResources result holds the resource too,
+// so it is dropped and the next fetch goes upstream.
+func (a *CachingAdapter) InvalidateResource(id string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for key, cached := range a.byID {
+		if key == id || cached.resource.ID == id || cached.resource.CanonicalKey() == id {
+			delete(a.byID, key)
+		}
+	}
+	a.resources = nil
+	a.generation++
+}
+
+// Invalidate evicts everything
+func (a *CachingAdapter) Invalidate() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.byID = make(map[string]cachedResource)
+	a.resources = nil
+	a.generation++
+}
+
+func cloneResources(resources []*ResourceV2) []*ResourceV2 {
+	if resources == nil {
+		return nil
+	}
+	clones := make([]*ResourceV2, len(resources))
+	for i, resource := range resources {
+		clones[i] = cloneResource(resource)
+	}
+	return clones
+}
+
+// cloneResource copies a resource with its own tags, metadata and slices.
+// Metadata values are shared.
+func cloneResource(resource *ResourceV2) *ResourceV2 {
+	if resource == nil {
+		return nil
+	}
+	clone := *resource
+	if resource.Tags != nil {
+		clone.Tags = make(map[string]string, len(resource.Tags))
+		for k, v := range resource.Tags {
+			clone.Tags[k] = v
+		}
+	}
+	if resource.Metadata != nil {
+		clone.Metadata = make(map[string]interface{}, len(resource.Metadata))
+		for k, v := range resource.Metadata {
+			clone.Metadata[k] = v
+		}
+	}
+	clone.ComplianceTags = append([]string(nil), resource.ComplianceTags...)
+	clone.DependsOn = append([]string(nil), resource.DependsOn...)
+	clone.DependedBy = append([]string(nil), resource.DependedBy...)
+	return &clone
+}
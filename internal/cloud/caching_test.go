@@ -0,0 +1,256 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingAdapter counts upstream calls and, when gate is set, holds every
+// fetch until the gate is closed
+type countingAdapter struct {
+	Simulator
+	fetches atomic.Int32
+	gets    atomic.Int32
+	gate    chan struct{}
+	err     error
+}
+
+func (c *countingAdapter) FetchResources(ctx context.Context) ([]*ResourceV2, error) {
+	c.fetches.Add(1)
+	if c.gate != nil {
+		<-c.gate
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.Simulator.FetchResources(ctx)
+}
+
+func (c *countingAdapter) GetResource(ctx context.Context, id string) (*ResourceV2, error) {
+	c.gets.Add(1)
+	return c.Simulator.GetResource(ctx, id)
+}
+
+func newCountingAdapter(ids ...string) *countingAdapter {
+	return &countingAdapter{Simulator: *newAccountSimulator(ids...)}
+}
+
+// fakeClock is a settable time source for CachingAdapter.now
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestCachingAdapter(upstream CloudAdapter, ttl time.Duration) (*CachingAdapter, *fakeClock) {
+	clock := &fakeClock{now: time.Now()}
+	adapter := NewCachingAdapter(upstream, ttl)
+	adapter.now = clock.Now
+	return adapter, clock
+}
+
+func TestCachingAdapter_FetchWithinTTLIsCached(t *testing.T) {
+	ctx := context.Background()
+	upstream := newCountingAdapter("i-1", "i-2")
+	adapter, clock := newTestCachingAdapter(upstream, time.Minute)
+
+	first, err := adapter.FetchResources(ctx)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+
+	clock.now = clock.now.Add(59 * time.Second)
+	second, err := adapter.FetchResources(ctx)
+	require.NoError(t, err)
+	assert.Len(t, second, 2)
+	assert.Equal(t, int32(1), upstream.fetches.Load(), "second fetch within the TTL is served from the cache")
+
+	clock.now = clock.now.Add(time.Second)
+	_, err = adapter.FetchResources(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), upstream.fetches.Load(), "expired entries are refetched")
+}
+
+func TestCachingAdapter_ConcurrentFetchesCollapse(t *testing.T) {
+	upstream := newCountingAdapter("i-1")
+	upstream.gate = make(chan struct{})
+	adapter, _ := newTestCachingAdapter(upstream, time.Minute)
+
+	const callers = 20
+	var started, done sync.WaitGroup
+	results := make([][]*ResourceV2, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		started.Add(1)
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			started.Done()
+			results[i], errs[i] = adapter.FetchResources(context.Background())
+		}(i)
+	}
+	started.Wait()
+
+	// Let every caller reach the in-flight fetch before it completes
+	require.Eventually(t, func() bool { return upstream.fetches.Load() == 1 }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	close(upstream.gate)
+	done.Wait()
+
+	assert.Equal(t, int32(1), upstream.fetches.Load())
+	for i := range results {
+		require.NoError(t, errs[i])
+		assert.Len(t, results[i], 1)
+	}
+}
+
+func TestCachingAdapter_ErrorsAreNotCached(t *testing.T) {
+	ctx := context.Background()
+	upstream := newCountingAdapter("i-1")
+	upstream.err = errors.New("throttled")
+	adapter, _ := newTestCachingAdapter(upstream, time.Minute)
+
+	_, err := adapter.FetchResources(ctx)
+	assert.Error(t, err)
+
+	upstream.err = nil
+	resources, err := adapter.FetchResources(ctx)
+	require.NoError(t, err)
+	assert.Len(t, resources, 1)
+	assert.Equal(t, int32(2), upstream.fetches.Load())
+}
+
+func TestCachingAdapter_GetResource(t *testing.T) {
+	ctx := context.Background()
+	upstream := newCountingAdapter("i-1")
+	adapter, clock := newTestCachingAdapter(upstream, time.Minute)
+
+	for i := 0; i < 3; i++ {
+		resource, err := adapter.GetResource(ctx, "i-1")
+		require.NoError(t, err)
+		assert.Equal(t, "i-1", resource.ID)
+	}
+	assert.Equal(t, int32(1), upstream.gets.Load())
+
+	clock.now = clock.now.Add(time.Minute)
+	_, err := adapter.GetResource(ctx, "i-1")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), upstream.gets.Load())
+
+	_, err = adapter.GetResource(ctx, "i-missing")
+	assert.Error(t, err)
+	_, err = adapter.GetResource(ctx, "i-missing")
+	assert.Error(t, err)
+	assert.Equal(t, int32(4), upstream.gets.Load(), "lookups that failed are retried")
+}
+
+func TestCachingAdapter_ActLookupsAreNotCached(t *testing.T) {
+	ctx := context.Background()
+	upstream := newCountingAdapter("i-1")
+	adapter, _ := newTestCachingAdapter(upstream, time.Hour)
+
+	_, err := adapter.GetResource(ctx, "i-1")
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		upstream.MockResources[0].Tags = map[string]string{ProtectedTagKey: ProtectedTagValue}
+		resource, err := adapter.GetResourceMetadata(ctx, "i-1")
+		require.NoError(t, err)
+		assert.True(t, ProtectionPolicy{}.IsProtected(resource), "tags are read upstream")
+	}
+	assert.Equal(t, int32(3), upstream.gets.Load())
+
+	_, keyed := adapter.Adapter().(ResourceKeyResolver)
+	assert.False(t, keyed, "a single-account adapter is not keyed")
+	assert.ErrorIs(t, adapter.Rollback(ctx, upstream.MockResources[0], "stop", ResourceSnapshot{}), ErrRollbackUnsupported)
+
+	member := newCountingAdapter("i-2")
+	multi, _ := newTestCachingAdapter(NewMultiAdapter(AccountAdapter{Account: "111", Region: "us-east-1", Adapter: member}), time.Hour)
+	_, err = multi.FetchResources(ctx)
+	require.NoError(t, err)
+	resolver, keyed := multi.Adapter().(ResourceKeyResolver)
+	require.True(t, keyed)
+	for i := 0; i < 2; i++ {
+		resource, err := resolver.GetResourceByKey(ctx, "aws/111/us-east-1/i-2")
+		require.NoError(t, err)
+		assert.Equal(t, "i-2", resource.ID)
+	}
+	assert.Equal(t, int32(2), member.gets.Load())
+}
+
+func TestCachingAdapter_ApplyOptimizationEvicts(t *testing.T) {
+	ctx := context.Background()
+	upstream := newCountingAdapter("i-1", "i-2")
+	for _, resource := range upstream.MockResources {
+		resource.Type, resource.State = ResourceTypeEC2, "running"
+	}
+	adapter, _ := newTestCachingAdapter(upstream, time.Hour)
+
+	_, err := adapter.FetchResources(ctx)
+	require.NoError(t, err)
+	resource, err := adapter.GetResource(ctx, "i-1")
+	require.NoError(t, err)
+	_, err = adapter.GetResource(ctx, "i-2")
+	require.NoError(t, err)
+
+	_, err = adapter.ApplyOptimization(ctx, resource, string(ActionStop))
+	require.NoError(t, err)
+
+	_, err = adapter.GetResource(ctx, "i-1")
+	require.NoError(t, err)
+	_, err = adapter.GetResource(ctx, "i-2")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), upstream.gets.Load(), "only the changed resource is refetched")
+
+	_, err = adapter.FetchResources(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), upstream.fetches.Load(), "the fetched list held the changed resource")
+}
+
+func TestCachingAdapter_InvalidationDiscardsInFlightFetch(t *testing.T) {
+	ctx := context.Background()
+	upstream := newCountingAdapter("i-1")
+	upstream.gate = make(chan struct{})
+	adapter, _ := newTestCachingAdapter(upstream, time.Hour)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		adapter.FetchResources(ctx)
+	}()
+	require.Eventually(t, func() bool { return upstream.fetches.Load() == 1 }, time.Second, time.Millisecond)
+
+	// A mutation lands while the fetch is reading the old state
+	adapter.InvalidateResource("i-1")
+	close(upstream.gate)
+	<-done
+
+	_, err := adapter.FetchResources(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), upstream.fetches.Load())
+}
+
+func TestCachingAdapter_ReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	upstream := newCountingAdapter("i-1")
+	upstream.MockResources[0].Tags = map[string]string{"env": "prod"}
+	adapter, _ := newTestCachingAdapter(upstream, time.Hour)
+
+	resources, err := adapter.FetchResources(ctx)
+	require.NoError(t, err)
+	resources[0].CostPerMonth = 0
+	resources[0].Tags["env"] = "dev"
+
+	resources, err = adapter.FetchResources(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, resources[0].CostPerMonth)
+	assert.Equal(t, "prod", resources[0].Tags["env"])
+}
+
+func TestNewCachingAdapter_DefaultTTL(t *testing.T) {
+	assert.Equal(t, DefaultResourceCacheTTL, NewCachingAdapter(&Simulator{}, 0).ttl)
+	assert.Equal(t, time.Second, NewCachingAdapter(&Simulator{}, time.Second).ttl)
+}
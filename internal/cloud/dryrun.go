@@ -0,0 +1,22 @@
+package cloud
+
+import (
+	"context"
+)
+
+type dryRunKey struct{}
+
+// WithDryRun overrides the adapter's configured dry-run setting for calls made
+// with the returned context, so a single action can run live while the system
+// stays in dry-run, or be previewed while it is live
+func WithDryRun(ctx context.Context, dryRun bool) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, dryRun)
+}
+
+// DryRun returns the dry-run override carried by ctx, or configured if there is none
+func DryRun(ctx context.Context, configured bool) bool {
+	if dryRun, ok := ctx.Value(dryRunKey{}).(bool); ok {
+		return dryRun
+	}
+	return configured
+}
@@ -3,73 +3,409 @@ package gcp
 import (
 	"context"
 	"fmt"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
+	monitoring "cloud.google.com/go/monitoring/apiv3/v2"
+	"golang.org/x/oauth2/google"
+	sqladmin "google.golang.org/api/sqladmin/v1"
+
 	"github.com/Xover-Official/Xover/internal/cloud"
 )
 
-// GCPAdapter implements CloudAdapter for Google Cloud Platform
-type GCPAdapter struct {
-	computeService *compute.InstancesClient
-	projectID      string
-	zone           string
+// machineTypePricing is a rough on-demand cost per month for Compute Engine
+// machine types and Cloud SQL tiers. Like the AWS adapter's table, it stands
+// in for the Cloud Billing Catalog API.
+var machineTypePricing = map[string]float64{
+	"e2-micro":      6.11,
+	"e2-small":      12.23,
+	"e2-medium":     24.46,
+	"e2-standard-2": 48.92,
+	"e2-standard-4": 97.83,
+	"n1-standard-1": 24.27,
+	"n1-standard-2": 48.55,
+	"n2-standard-2": 70.90,
+	"n2-standard-4": 141.79,
+
+	"db-f1-micro":      7.67,
+	"db-g1-small":      25.55,
+	"db-custom-1-3840": 49.64,
+	"db-custom-2-7680": 99.28,
+}
+
+// activationNever is the Cloud SQL activation policy of a stopped instance
+const activationNever = "NEVER"
+
+// Adapter implements the cloud.CloudAdapter interface for Google Cloud,
+// covering Compute Engine and Cloud SQL instances.
+type Adapter struct {
+	compute    computeAPI
+	sql        sqlAPI
+	monitoring monitoringAPI
+	project    string
+	region     string
+	dryRun     bool
+	cfg        cloud.CloudConfig
+
+	closers []func() error
 }
 
-// NewGCPAdapter creates a new GCP adapter
-func NewGCPAdapter(projectID, zone string) (*GCPAdapter, error) {
-	ctx := context.Background()
+// New creates a GCP adapter using Application Default Credentials. It scans
+// cfg.Project, or the credentials' project when that is empty, and limits
+// the scan to cfg.Region when it is set.
+func New(ctx context.Context, cfg cloud.CloudConfig) (*Adapter, error) {
+	project := cfg.Project
+	if project == "" {
+		creds, err := google.FindDefaultCredentials(ctx, compute.DefaultAuthScopes()...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load GCP credentials: %w", err)
+		}
+		if creds.ProjectID == "" {
+			return nil, fmt.Errorf("no GCP project configured and none found in the default credentials")
+		}
+		project = creds.ProjectID
+	}
 
-	computeService, err := compute.NewInstancesRESTClient(ctx)
+	instances, err := compute.NewInstancesRESTClient(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create compute client: %w", err)
 	}
+	metrics, err := monitoring.NewMetricClient(ctx)
+	if err != nil {
+		instances.Close()
+		return nil, fmt.Errorf("failed to create monitoring client: %w", err)
+	}
+	sqlService, err := sqladmin.NewService(ctx)
+	if err != nil {
+		instances.Close()
+		metrics.Close()
+		return nil, fmt.Errorf("failed to create Cloud SQL client: %w", err)
+	}
 
-	return &GCPAdapter{
-		computeService: computeService,
-		projectID:      projectID,
-		zone:           zone,
+	return &Adapter{
+		compute:    computeClient{instances: instances},
+		sql:        sqlClient{service: sqlService},
+		monitoring: monitoringClient{metrics: metrics},
+		project:    project,
+		region:     cfg.Region,
+		dryRun:     cfg.DryRun,
+		cfg:        cfg,
+		closers:    []func() error{instances.Close, metrics.Close},
 	}, nil
 }
 
-// FetchResources retrieves all GCP resources
-func (g *GCPAdapter) FetchResources() ([]*cloud.ResourceV2, error) {
-	var resources []*cloud.ResourceV2
+// Close releases the API clients
+func (a *Adapter) Close() error {
+	var firstErr error
+	for _, closeFn := range a.closers {
+		if err := closeFn(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// FetchResources retrieves Compute Engine and Cloud SQL instances and
+// converts them to the canonical ResourceV2 model.
+func (a *Adapter) FetchResources(ctx context.Context) ([]*cloud.ResourceV2, error) {
+	var wg sync.WaitGroup
+	var vmResources, sqlResources []*cloud.ResourceV2
+	var vmErr, sqlErr error
+
+	wg.Add(2)
+
+	// Fetch Compute Engine and Cloud SQL concurrently
+	go func() {
+		defer wg.Done()
+		vmResources, vmErr = a.fetchComputeInstances(ctx)
+	}()
+
+	go func() {
+		defer wg.Done()
+		sqlResources, sqlErr = a.fetchSQLInstances(ctx)
+	}()
+
+	wg.Wait()
+
+	if vmErr != nil {
+		return nil, fmt.Errorf("failed to fetch Compute Engine instances: %w", vmErr)
+	}
+	if sqlErr != nil {
+		return nil, fmt.Errorf("failed to fetch Cloud SQL instances: %w", sqlErr)
+	}
+	return append(vmResources, sqlResources...), nil
+}
 
-	// Fetch Compute Engine instances
-	instances, err := g.fetchComputeInstances()
+func (a *Adapter) fetchComputeInstances(ctx context.Context) ([]*cloud.ResourceV2, error) {
+	instances, err := a.compute.listInstances(ctx, a.project)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch instances: %w", err)
+		return nil, fmt.Errorf("failed to list instances: %w", err)
 	}
-	resources = append(resources, instances...)
 
+	// Without utilization the instances are still worth reporting
+	usage, err := a.fetchUtilization(ctx, computeMetrics, time.Now())
+	if err != nil {
+		log.Printf("Cloud Monitoring metrics incomplete for Compute Engine: %v", err)
+	}
+
+	var resources []*cloud.ResourceV2
+	for _, instance := range instances {
+		resource := computeInstanceToResource(instance, a.project)
+		if a.region != "" && resource.Region != a.region {
+			continue
+		}
+		a.resourceMetrics(ctx, resource, usage[strconv.FormatUint(instance.GetId(), 10)]).Apply(resource)
+		resources = append(resources, resource)
+	}
 	return resources, nil
 }
 
-func (g *GCPAdapter) fetchComputeInstances() ([]*cloud.ResourceV2, error) {
+func (a *Adapter) fetchSQLInstances(ctx context.Context) ([]*cloud.ResourceV2, error) {
+	instances, err := a.sql.listInstances(ctx, a.project)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list instances: %w", err)
+	}
+
+	usage, err := a.fetchUtilization(ctx, sqlMetrics, time.Now())
+	if err != nil {
+		log.Printf("Cloud Monitoring metrics incomplete for Cloud SQL: %v", err)
+	}
+
 	var resources []*cloud.ResourceV2
-	resource := &cloud.ResourceV2{
-		ID:           "gcp-instance-placeholder",
-		Type:         "gce",
-		Provider:     "gcp",
-		Region:       g.zone,
-		State:        "running",
-		CPUUsage:     40.0,
-		MemoryUsage:  50.0,
-		CostPerMonth: 120.0,
-		CreatedAt:    time.Now(),
-		ModifiedAt:   time.Now(),
-	}
-	resources = append(resources, resource)
+	for _, instance := range instances {
+		resource, ok := sqlInstanceToResource(instance, a.project)
+		if !ok {
+			log.Printf("skipping Cloud SQL instance without a name (state %q)", instance.State)
+			continue
+		}
+		if a.region != "" && resource.Region != a.region {
+			continue
+		}
+		a.resourceMetrics(ctx, resource, usage[a.project+":"+resource.ID]).Apply(resource)
+		resources = append(resources, resource)
+	}
 	return resources, nil
 }
 
-// ApplyOptimization applies an optimization to a GCP resource
-func (g *GCPAdapter) ApplyOptimization(resourceID, action string) (string, error) {
-	return fmt.Sprintf("Applied %s to GCP resource %s", action, resourceID), nil
+// GetResource retrieves a single resource by its ID: "<zone>/<name>" for a
+// Compute Engine instance, otherwise a Cloud SQL instance name
+func (a *Adapter) GetResource(ctx context.Context, id string) (*cloud.ResourceV2, error) {
+	if zone, name, ok := strings.Cut(id, "/"); ok {
+		instance, err := a.compute.getInstance(ctx, a.project, zone, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get instance %s: %w", id, err)
+		}
+		resource := computeInstanceToResource(instance, a.project)
+		usage, err := a.fetchUtilization(ctx, computeMetrics, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get metrics for %s: %w", id, err)
+		}
+		a.resourceMetrics(ctx, resource, usage[strconv.FormatUint(instance.GetId(), 10)]).Apply(resource)
+		return resource, nil
+	}
+
+	instance, err := a.sql.getInstance(ctx, a.project, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Cloud SQL instance %s: %w", id, err)
+	}
+	resource, ok := sqlInstanceToResource(instance, a.project)
+	if !ok {
+		return nil, fmt.Errorf("resource %s not found", id)
+	}
+	usage, err := a.fetchUtilization(ctx, sqlMetrics, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metrics for %s: %w", id, err)
+	}
+	a.resourceMetrics(ctx, resource, usage[a.project+":"+id]).Apply(resource)
+	return resource, nil
 }
 
-// Close closes the GCP client
-func (g *GCPAdapter) Close() error {
-	return g.computeService.Close()
+// computeState maps Compute Engine statuses onto the canonical states. A
+// TERMINATED instance is stopped, not deleted, in GCP terms.
+func computeState(status string) string {
+	switch status {
+	case "":
+		return "unknown"
+	case "PROVISIONING", "STAGING", "RUNNING":
+		return "running"
+	case "STOPPING", "SUSPENDING":
+		return "stopping"
+	case "TERMINATED", "SUSPENDED":
+		return "stopped"
+	default:
+		return strings.ToLower(status)
+	}
+}
+
+// sqlState maps a Cloud SQL state and activation policy onto the canonical
+// states; a runnable instance that never activates is stopped
+func sqlState(instance *sqladmin.DatabaseInstance) string {
+	switch instance.State {
+	case "":
+		return "unknown"
+	case "RUNNABLE":
+		if instance.Settings != nil && instance.Settings.ActivationPolicy == activationNever {
+			return "stopped"
+		}
+		return "running"
+	default:
+		return strings.ToLower(instance.State)
+	}
+}
+
+// zoneRegion returns the region of a zone, e.g. us-central1 for us-central1-a
+func zoneRegion(zone string) string {
+	if i := strings.LastIndex(zone, "-"); i > 0 {
+		return zone[:i]
+	}
+	return zone
+}
+
+// parseTimestamp parses the RFC 3339 timestamps the APIs return, using zero
+// time for missing or malformed values
+func parseTimestamp(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// computeInstanceToResource converts a Compute Engine instance to the
+// canonical model, without utilization metrics. Zone and machine type are
+// returned by the API as URLs; only their last segment is kept.
+func computeInstanceToResource(instance *computepb.Instance, project string) *cloud.ResourceV2 {
+	zone := path.Base(instance.GetZone())
+	machineType := path.Base(instance.GetMachineType())
+
+	tags := make(map[string]string, len(instance.GetLabels()))
+	for k, v := range instance.GetLabels() {
+		tags[k] = v
+	}
+
+	resource := &cloud.ResourceV2{
+		ID:           zone + "/" + instance.GetName(),
+		Type:         cloud.ResourceTypeVM,
+		Provider:     cloud.ProviderGCP,
+		Region:       zoneRegion(zone),
+		Account:      project,
+		Tags:         tags,
+		State:        computeState(instance.GetStatus()),
+		CreatedAt:    parseTimestamp(instance.GetCreationTimestamp()),
+		CostPerMonth: machineTypePricing[machineType],
+		Metadata: map[string]interface{}{
+			"instance_type": machineType,
+			"zone":          zone,
+			"instance_id":   strconv.FormatUint(instance.GetId(), 10),
+		},
+	}
+	if stopped := parseTimestamp(instance.GetLastStopTimestamp()); resource.State == "stopped" && !stopped.IsZero() {
+		resource.Metadata["stopped_at"] = stopped
+	}
+	return resource
+}
+
+// sqlInstanceToResource converts a Cloud SQL instance to the canonical
+// model. It returns false if the instance has no name and cannot be tracked.
+func sqlInstanceToResource(instance *sqladmin.DatabaseInstance, project string) (*cloud.ResourceV2, bool) {
+	if instance.Name == "" {
+		return nil, false
+	}
+
+	resource := &cloud.ResourceV2{
+		ID:        instance.Name,
+		Type:      cloud.ResourceTypeCloudSQL,
+		Provider:  cloud.ProviderGCP,
+		Region:    instance.Region,
+		Account:   project,
+		Tags:      make(map[string]string),
+		State:     sqlState(instance),
+		CreatedAt: parseTimestamp(instance.CreateTime),
+		// Cloud SQL encrypts data at rest unconditionally
+		EncryptionEnabled: true,
+		Metadata:          map[string]interface{}{"database_version": instance.DatabaseVersion},
+	}
+
+	if settings := instance.Settings; settings != nil {
+		for k, v := range settings.UserLabels {
+			resource.Tags[k] = v
+		}
+		resource.CostPerMonth = machineTypePricing[settings.Tier]
+		resource.Metadata["instance_class"] = settings.Tier
+		resource.PubliclyAccessible = settings.IpConfiguration != nil && settings.IpConfiguration.Ipv4Enabled
+		resource.BackupEnabled = settings.BackupConfiguration != nil && settings.BackupConfiguration.Enabled
+	}
+	return resource, true
+}
+
+// GetResources looks resources up one at a time; the Compute Engine and
+// Cloud SQL APIs have no multi-instance get
+func (a *Adapter) GetResources(ctx context.Context, ids []string) (map[string]*cloud.ResourceV2, error) {
+	return cloud.GetResourcesOneByOne(ctx, a, ids)
+}
+
+// ApplyOptimization applies an optimization to a GCP resource. In dry run
+// it only returns the estimated savings.
+func (a *Adapter) ApplyOptimization(ctx context.Context, resource *cloud.ResourceV2, action string) (float64, error) {
+	// Protected resources are refused here even if the engine's checks were bypassed
+	if err := a.cfg.Protection.CheckMutation(resource, action); err != nil {
+		log.Printf("protection event: %v", err)
+		return 0, err
+	}
+	actionType, err := cloud.ValidateAction(resource, action)
+	if err != nil {
+		return 0, err
+	}
+
+	estimatedSavings := resource.CostPerMonth * a.cfg.SavingsRatio(action)
+
+	// A per-action override (set by an operator at approval) wins over the global setting
+	if cloud.DryRun(ctx, a.dryRun) {
+		return estimatedSavings, nil
+	}
+
+	switch {
+	case actionType == cloud.ActionStop && resource.Type == cloud.ResourceTypeVM:
+		zone, name, ok := strings.Cut(resource.ID, "/")
+		if !ok {
+			return 0, fmt.Errorf("invalid Compute Engine resource ID %q, want <zone>/<name>", resource.ID)
+		}
+		return estimatedSavings, a.compute.stopInstance(ctx, a.project, zone, name)
+	case actionType == cloud.ActionStop && resource.Type == cloud.ResourceTypeCloudSQL:
+		return estimatedSavings, a.sql.stopInstance(ctx, a.project, resource.ID)
+	default:
+		return 0, fmt.Errorf("%s on %s resources is not implemented by the GCP adapter", actionType, resource.Type)
+	}
+}
+
+// GetSpotPrice returns the hourly Spot VM price for a machine type. GCP
+// prices Spot VMs per region rather than per zone.
+func (a *Adapter) GetSpotPrice(zone, instanceType string) (float64, error) {
+	// Mock implementation - in production, this would call the Cloud Billing Catalog API
+	prices := map[string]float64{
+		"e2-medium":     0.0101,
+		"e2-standard-2": 0.0201,
+		"n1-standard-1": 0.0100,
+		"n2-standard-2": 0.0291,
+	}
+	if price, ok := prices[instanceType]; ok {
+		return price, nil
+	}
+	return 0.0201, nil
+}
+
+// ListZones returns the zones of the configured region
+func (a *Adapter) ListZones() ([]string, error) {
+	// Mock implementation - in production, this would call the Compute Engine zones API
+	region := a.region
+	if region == "" {
+		region = "us-central1"
+	}
+	return []string{region + "-a", region + "-b", region + "-c"}, nil
 }
@@ -0,0 +1,265 @@
+package gcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/compute/apiv1/computepb"
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sqladmin "google.golang.org/api/sqladmin/v1"
+	monitoredres "google.golang.org/genproto/googleapis/api/monitoredres"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+type fakeCompute struct {
+	instances []*computepb.Instance
+	stopped   []string
+}
+
+func (f *fakeCompute) listInstances(context.Context, string) ([]*computepb.Instance, error) {
+	return f.instances, nil
+}
+
+func (f *fakeCompute) getInstance(_ context.Context, _, zone, name string) (*computepb.Instance, error) {
+	for _, instance := range f.instances {
+		if instance.GetName() == name {
+			return instance, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeCompute) stopInstance(_ context.Context, _, zone, name string) error {
+	f.stopped = append(f.stopped, zone+"/"+name)
+	return nil
+}
+
+type fakeSQL struct {
+	instances []*sqladmin.DatabaseInstance
+	stopped   []string
+}
+
+func (f *fakeSQL) listInstances(context.Context, string) ([]*sqladmin.DatabaseInstance, error) {
+	return f.instances, nil
+}
+
+func (f *fakeSQL) getInstance(_ context.Context, _, name string) (*sqladmin.DatabaseInstance, error) {
+	for _, instance := range f.instances {
+		if instance.Name == name {
+			return instance, nil
+		}
+	}
+	return nil, errors.New("not found")
+}
+
+func (f *fakeSQL) stopInstance(_ context.Context, _, name string) error {
+	f.stopped = append(f.stopped, name)
+	return nil
+}
+
+// fakeMonitoring returns canned series per metric filter
+type fakeMonitoring struct {
+	series map[string][]*monitoringpb.TimeSeries
+	err    error
+}
+
+func (f *fakeMonitoring) listTimeSeries(_ context.Context, req *monitoringpb.ListTimeSeriesRequest) ([]*monitoringpb.TimeSeries, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.series[req.GetFilter()], nil
+}
+
+func series(label, id string, value float64) *monitoringpb.TimeSeries {
+	return &monitoringpb.TimeSeries{
+		Resource: &monitoredres.MonitoredResource{Labels: map[string]string{label: id}},
+		Points: []*monitoringpb.Point{{
+			Value: &monitoringpb.TypedValue{Value: &monitoringpb.TypedValue_DoubleValue{DoubleValue: value}},
+		}},
+	}
+}
+
+func newTestAdapter() (*Adapter, *fakeCompute, *fakeSQL) {
+	compute := &fakeCompute{instances: []*computepb.Instance{
+		{
+			Id:                proto.Uint64(42),
+			Name:              proto.String("web-1"),
+			Zone:              proto.String("https://www.googleapis.com/compute/v1/projects/acme/zones/us-central1-a"),
+			MachineType:       proto.String("https://www.googleapis.com/compute/v1/projects/acme/zones/us-central1-a/machineTypes/e2-medium"),
+			Status:            proto.String("RUNNING"),
+			Labels:            map[string]string{"env": "prod"},
+			CreationTimestamp: proto.String("2026-01-02T03:04:05Z"),
+		},
+		{
+			Id:     proto.Uint64(43),
+			Name:   proto.String("batch-eu"),
+			Zone:   proto.String("zones/europe-west1-b"),
+			Status: proto.String("TERMINATED"),
+		},
+	}}
+	sql := &fakeSQL{instances: []*sqladmin.DatabaseInstance{
+		{
+			Name:   "orders-db",
+			Region: "us-central1",
+			State:  "RUNNABLE",
+			Settings: &sqladmin.Settings{
+				Tier:                "db-g1-small",
+				ActivationPolicy:    "ALWAYS",
+				UserLabels:          map[string]string{"team": "orders"},
+				IpConfiguration:     &sqladmin.IpConfiguration{Ipv4Enabled: true},
+				BackupConfiguration: &sqladmin.BackupConfiguration{Enabled: true},
+			},
+		},
+		{Name: ""},
+	}}
+	monitoring := &fakeMonitoring{series: map[string][]*monitoringpb.TimeSeries{
+		computeMetrics[0].filter: {series("instance_id", "42", 0.125)},
+		sqlMetrics[0].filter:     {series("database_id", "acme:orders-db", 0.5)},
+		sqlMetrics[1].filter:     {series("database_id", "acme:orders-db", 0.75)},
+	}}
+
+	adapter := &Adapter{
+		compute:    compute,
+		sql:        sql,
+		monitoring: monitoring,
+		project:    "acme",
+		region:     "us-central1",
+	}
+	return adapter, compute, sql
+}
+
+func TestAdapter_ImplementsCloudAdapter(t *testing.T) {
+	var _ cloud.CloudAdapter = (*Adapter)(nil)
+}
+
+func TestFetchResources_ConvertsInstancesInRegion(t *testing.T) {
+	adapter, _, _ := newTestAdapter()
+
+	resources, err := adapter.FetchResources(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resources, 2, "instances outside us-central1 and nameless databases are skipped")
+
+	vm := resources[0]
+	assert.Equal(t, "us-central1-a/web-1", vm.ID)
+	assert.Equal(t, cloud.ResourceTypeVM, vm.Type)
+	assert.Equal(t, cloud.ProviderGCP, vm.Provider)
+	assert.Equal(t, "us-central1", vm.Region)
+	assert.Equal(t, "acme", vm.Account)
+	assert.Equal(t, "running", vm.State)
+	assert.Equal(t, 12.5, vm.CPUUsage)
+	assert.Zero(t, vm.MemoryUsage, "no Ops Agent data")
+	assert.Equal(t, 24.46, vm.CostPerMonth)
+	assert.Equal(t, "e2-medium", vm.Metadata["instance_type"])
+	assert.Equal(t, map[string]string{"env": "prod"}, vm.Tags)
+	assert.Equal(t, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), vm.CreatedAt)
+
+	db := resources[1]
+	assert.Equal(t, "orders-db", db.ID)
+	assert.Equal(t, cloud.ResourceTypeCloudSQL, db.Type)
+	assert.Equal(t, cloud.ProviderGCP, db.Provider)
+	assert.Equal(t, "running", db.State)
+	assert.Equal(t, 50.0, db.CPUUsage)
+	assert.Equal(t, 75.0, db.MemoryUsage)
+	assert.Equal(t, 25.55, db.CostPerMonth)
+	assert.True(t, db.PubliclyAccessible)
+	assert.True(t, db.BackupEnabled)
+	assert.Equal(t, "orders", db.Tags["team"])
+}
+
+func TestFetchResources_MonitoringFailureKeepsInstances(t *testing.T) {
+	adapter, _, _ := newTestAdapter()
+	adapter.monitoring = &fakeMonitoring{err: errors.New("permission denied")}
+
+	resources, err := adapter.FetchResources(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+	assert.Zero(t, resources[0].CPUUsage)
+}
+
+func TestResourceMetrics_MergesExternalProvider(t *testing.T) {
+	adapter, _, _ := newTestAdapter()
+	adapter.cfg.Metrics = stubMetricsProvider{metrics: cloud.Metrics{cloud.MetricMemoryUsage: 63}}
+
+	resources, err := adapter.FetchResources(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 12.5, resources[0].CPUUsage, "Cloud Monitoring fills the gaps")
+	assert.Equal(t, 63.0, resources[0].MemoryUsage)
+}
+
+func TestStates(t *testing.T) {
+	assert.Equal(t, "stopped", computeState("TERMINATED"))
+	assert.Equal(t, "stopping", computeState("STOPPING"))
+	assert.Equal(t, "unknown", computeState(""))
+
+	stopped := &sqladmin.DatabaseInstance{State: "RUNNABLE", Settings: &sqladmin.Settings{ActivationPolicy: activationNever}}
+	assert.Equal(t, "stopped", sqlState(stopped))
+	assert.Equal(t, "maintenance", sqlState(&sqladmin.DatabaseInstance{State: "MAINTENANCE"}))
+}
+
+func TestGetResource(t *testing.T) {
+	adapter, _, _ := newTestAdapter()
+
+	vm, err := adapter.GetResource(context.Background(), "us-central1-a/web-1")
+	require.NoError(t, err)
+	assert.Equal(t, 12.5, vm.CPUUsage)
+
+	db, err := adapter.GetResource(context.Background(), "orders-db")
+	require.NoError(t, err)
+	assert.Equal(t, 75.0, db.MemoryUsage)
+
+	_, err = adapter.GetResource(context.Background(), "missing-db")
+	assert.Error(t, err)
+}
+
+func TestApplyOptimization_DryRunDoesNotMutate(t *testing.T) {
+	adapter, compute, sql := newTestAdapter()
+	adapter.dryRun = true
+
+	vm := &cloud.ResourceV2{ID: "us-central1-a/web-1", Type: cloud.ResourceTypeVM, State: "running", CostPerMonth: 24.46}
+	savings, err := adapter.ApplyOptimization(context.Background(), vm, "stop")
+	require.NoError(t, err)
+	assert.Equal(t, 24.46, savings)
+	assert.Empty(t, compute.stopped)
+
+	// A per-action override turns dry run off
+	savings, err = adapter.ApplyOptimization(cloud.WithDryRun(context.Background(), false), vm, "stop")
+	require.NoError(t, err)
+	assert.Equal(t, 24.46, savings)
+	assert.Equal(t, []string{"us-central1-a/web-1"}, compute.stopped)
+
+	db := &cloud.ResourceV2{ID: "orders-db", Type: cloud.ResourceTypeCloudSQL, State: "running", CostPerMonth: 25.55}
+	_, err = adapter.ApplyOptimization(cloud.WithDryRun(context.Background(), false), db, "stop")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"orders-db"}, sql.stopped)
+}
+
+func TestApplyOptimization_RefusesProtectedAndInvalid(t *testing.T) {
+	adapter, compute, _ := newTestAdapter()
+	adapter.cfg.Protection = cloud.ProtectionPolicy{ResourceIDs: []string{"*/payment-*"}}
+
+	_, err := adapter.ApplyOptimization(context.Background(), &cloud.ResourceV2{ID: "us-central1-a/payment-api", Type: cloud.ResourceTypeVM}, "stop")
+	assert.ErrorIs(t, err, cloud.ErrResourceProtected)
+
+	_, err = adapter.ApplyOptimization(context.Background(), &cloud.ResourceV2{ID: "orders-db", Type: cloud.ResourceTypeCloudSQL}, "terminate")
+	assert.ErrorIs(t, err, cloud.ErrInvalidAction)
+
+	_, err = adapter.ApplyOptimization(context.Background(), &cloud.ResourceV2{ID: "us-central1-a/web-1", Type: cloud.ResourceTypeVM}, "resize")
+	assert.Error(t, err, "not implemented")
+	assert.Empty(t, compute.stopped)
+}
+
+type stubMetricsProvider struct {
+	metrics cloud.Metrics
+}
+
+func (s stubMetricsProvider) Name() string { return "stub" }
+
+func (s stubMetricsProvider) FetchMetrics(ctx context.Context, resource *cloud.ResourceV2) (cloud.Metrics, error) {
+	return s.metrics, nil
+}
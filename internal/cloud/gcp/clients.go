@@ -0,0 +1,113 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+
+	compute "cloud.google.com/go/compute/apiv1"
+	"cloud.google.com/go/compute/apiv1/computepb"
+	monitoring "cloud.google.com/go/monitoring/apiv3/v2"
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"google.golang.org/api/iterator"
+	sqladmin "google.golang.org/api/sqladmin/v1"
+)
+
+// computeAPI is the part of Compute Engine the adapter uses
+type computeAPI interface {
+	listInstances(ctx context.Context, project string) ([]*computepb.Instance, error)
+	getInstance(ctx context.Context, project, zone, name string) (*computepb.Instance, error)
+	stopInstance(ctx context.Context, project, zone, name string) error
+}
+
+// sqlAPI is the part of the Cloud SQL Admin API the adapter uses
+type sqlAPI interface {
+	listInstances(ctx context.Context, project string) ([]*sqladmin.DatabaseInstance, error)
+	getInstance(ctx context.Context, project, name string) (*sqladmin.DatabaseInstance, error)
+	stopInstance(ctx context.Context, project, name string) error
+}
+
+// monitoringAPI reads time series from Cloud Monitoring
+type monitoringAPI interface {
+	listTimeSeries(ctx context.Context, req *monitoringpb.ListTimeSeriesRequest) ([]*monitoringpb.TimeSeries, error)
+}
+
+type computeClient struct {
+	instances *compute.InstancesClient
+}
+
+// listInstances lists the project's instances in every zone
+func (c computeClient) listInstances(ctx context.Context, project string) ([]*computepb.Instance, error) {
+	it := c.instances.AggregatedList(ctx, &computepb.AggregatedListInstancesRequest{Project: project})
+
+	var instances []*computepb.Instance
+	for {
+		pair, err := it.Next()
+		if err == iterator.Done {
+			return instances, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		instances = append(instances, pair.Value.GetInstances()...)
+	}
+}
+
+func (c computeClient) getInstance(ctx context.Context, project, zone, name string) (*computepb.Instance, error) {
+	return c.instances.Get(ctx, &computepb.GetInstanceRequest{Project: project, Zone: zone, Instance: name})
+}
+
+// stopInstance stops an instance and waits for the operation to finish
+func (c computeClient) stopInstance(ctx context.Context, project, zone, name string) error {
+	op, err := c.instances.Stop(ctx, &computepb.StopInstanceRequest{Project: project, Zone: zone, Instance: name})
+	if err != nil {
+		return err
+	}
+	return op.Wait(ctx)
+}
+
+type sqlClient struct {
+	service *sqladmin.Service
+}
+
+func (c sqlClient) listInstances(ctx context.Context, project string) ([]*sqladmin.DatabaseInstance, error) {
+	var instances []*sqladmin.DatabaseInstance
+	err := c.service.Instances.List(project).Pages(ctx, func(page *sqladmin.InstancesListResponse) error {
+		instances = append(instances, page.Items...)
+		return nil
+	})
+	return instances, err
+}
+
+func (c sqlClient) getInstance(ctx context.Context, project, name string) (*sqladmin.DatabaseInstance, error) {
+	return c.service.Instances.Get(project, name).Context(ctx).Do()
+}
+
+// stopInstance stops a Cloud SQL instance by setting its activation policy
+// to NEVER, which is how the console stops one too
+func (c sqlClient) stopInstance(ctx context.Context, project, name string) error {
+	patch := &sqladmin.DatabaseInstance{Settings: &sqladmin.Settings{ActivationPolicy: activationNever}}
+	if _, err := c.service.Instances.Patch(project, name, patch).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("failed to stop Cloud SQL instance %s: %w", name, err)
+	}
+	return nil
+}
+
+type monitoringClient struct {
+	metrics *monitoring.MetricClient
+}
+
+func (c monitoringClient) listTimeSeries(ctx context.Context, req *monitoringpb.ListTimeSeriesRequest) ([]*monitoringpb.TimeSeries, error) {
+	it := c.metrics.ListTimeSeries(ctx, req)
+
+	var series []*monitoringpb.TimeSeries
+	for {
+		ts, err := it.Next()
+		if err == iterator.Done {
+			return series, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		series = append(series, ts)
+	}
+}
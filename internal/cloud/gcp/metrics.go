@@ -0,0 +1,135 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/monitoring/apiv3/v2/monitoringpb"
+	"go.uber.org/multierr"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+// metricsWindow is how far back utilization is averaged, matching the hour
+// of CloudWatch data the AWS adapter reads
+const metricsWindow = time.Hour
+
+// metricSpec maps a Cloud Monitoring metric onto a cloud metric name
+type metricSpec struct {
+	name    string // cloud.MetricCPUUsage or cloud.MetricMemoryUsage
+	filter  string // metric type plus any label filter
+	idLabel string // monitored resource label identifying the instance
+	scale   float64
+}
+
+var (
+	// computeMetrics are read for Compute Engine instances. Memory is only
+	// reported by VMs running the Ops Agent.
+	computeMetrics = []metricSpec{
+		{name: cloud.MetricCPUUsage, filter: `metric.type = "compute.googleapis.com/instance/cpu/utilization"`, idLabel: "instance_id", scale: 100},
+		{name: cloud.MetricMemoryUsage, filter: `metric.type = "agent.googleapis.com/memory/percent_used" AND metric.labels.state = "used"`, idLabel: "instance_id", scale: 1},
+	}
+	// sqlMetrics are read for Cloud SQL instances, whose database_id label
+	// is "<project>:<instance>"
+	sqlMetrics = []metricSpec{
+		{name: cloud.MetricCPUUsage, filter: `metric.type = "cloudsql.googleapis.com/database/cpu/utilization"`, idLabel: "database_id", scale: 100},
+		{name: cloud.MetricMemoryUsage, filter: `metric.type = "cloudsql.googleapis.com/database/memory/utilization"`, idLabel: "database_id", scale: 100},
+	}
+)
+
+// utilization maps an instance's monitoring ID to its metrics
+type utilization map[string]cloud.Metrics
+
+// fetchUtilization reads the mean of each metric over metricsWindow for
+// every instance in the project with one request per metric, rather than one
+// per instance. Instances without data for a metric have no entry for it, so
+// an external provider can fill the gap.
+func (a *Adapter) fetchUtilization(ctx context.Context, specs []metricSpec, now time.Time) (utilization, error) {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var errs error
+	result := make(utilization)
+
+	for _, spec := range specs {
+		wg.Add(1)
+		go func(spec metricSpec) {
+			defer wg.Done()
+			series, err := a.monitoring.listTimeSeries(ctx, &monitoringpb.ListTimeSeriesRequest{
+				Name:   "projects/" + a.project,
+				Filter: spec.filter,
+				Interval: &monitoringpb.TimeInterval{
+					StartTime: timestamppb.New(now.Add(-metricsWindow)),
+					EndTime:   timestamppb.New(now),
+				},
+				Aggregation: &monitoringpb.Aggregation{
+					AlignmentPeriod:  durationpb.New(metricsWindow),
+					PerSeriesAligner: monitoringpb.Aggregation_ALIGN_MEAN,
+				},
+				View: monitoringpb.ListTimeSeriesRequest_FULL,
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = multierr.Append(errs, fmt.Errorf("%s: %w", spec.name, err))
+				return
+			}
+			for _, ts := range series {
+				id := ts.GetResource().GetLabels()[spec.idLabel]
+				value, ok := latestValue(ts)
+				if id == "" || !ok {
+					continue
+				}
+				if result[id] == nil {
+					result[id] = cloud.Metrics{}
+				}
+				result[id][spec.name] = value * spec.scale
+			}
+		}(spec)
+	}
+
+	wg.Wait()
+	return result, errs
+}
+
+// latestValue returns the newest point of a series. Points are returned
+// newest first.
+func latestValue(ts *monitoringpb.TimeSeries) (float64, bool) {
+	points := ts.GetPoints()
+	if len(points) == 0 {
+		return 0, false
+	}
+	switch v := points[0].GetValue().GetValue().(type) {
+	case *monitoringpb.TypedValue_DoubleValue:
+		return v.DoubleValue, true
+	case *monitoringpb.TypedValue_Int64Value:
+		return float64(v.Int64Value), true
+	}
+	return 0, false
+}
+
+// resourceMetrics combines the Cloud Monitoring metrics of a resource with
+// the configured external provider according to the metrics mode, the same
+// way the AWS adapter combines CloudWatch. An external provider that fails
+// falls back to Cloud Monitoring.
+func (a *Adapter) resourceMetrics(ctx context.Context, resource *cloud.ResourceV2, native cloud.Metrics) cloud.Metrics {
+	provider := a.cfg.Metrics
+	if provider == nil {
+		return native
+	}
+
+	external, err := provider.FetchMetrics(ctx, resource)
+	if err != nil {
+		log.Printf("%s metrics unavailable for %s, falling back to Cloud Monitoring: %v", provider.Name(), resource.ID, err)
+		return native
+	}
+	if a.cfg.MetricsMode == cloud.MetricsModeReplace {
+		return external
+	}
+	return external.Merge(native)
+}
@@ -0,0 +1,97 @@
+package cloud
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// IdleSinceKey is the resource metadata key holding when a load balancer,
+// static IP or volume was first seen idle. Adapters only set it once the resource has
+// been idle for the IdlePolicy's MinIdle.
+const IdleSinceKey = "idle_since"
+
+// DefaultMinIdle is used when IdlePolicy.MinIdle is unset, since idleness
+// can't be observed over an empty window
+const DefaultMinIdle = 7 * 24 * time.Hour
+
+// ErrNotIdle is returned when a cleanup action targets a resource that is in
+// use or hasn't been idle for long enough
+var ErrNotIdle = errors.New("resource is not idle")
+
+// IdlePolicy guards the cleanup of idle load balancers and unattached static
+// IPs and volumes. A load balancer must have served no traffic, and an address
+// or volume must have been unattached, for MinIdle before it is deleted or
+// released.
+type IdlePolicy struct {
+	MinIdle time.Duration `json:"min_idle" yaml:"min_idle"`
+}
+
+// Threshold returns MinIdle, or DefaultMinIdle when it is unset
+func (p IdlePolicy) Threshold() time.Duration {
+	if p.MinIdle <= 0 {
+		return DefaultMinIdle
+	}
+	return p.MinIdle
+}
+
+// IdleSince returns when a resource was first seen idle, and false if it
+// is in use or hasn't been idle for the policy's threshold
+func IdleSince(resource *ResourceV2) (time.Time, bool) {
+	since, ok := resource.Metadata[IdleSinceKey].(time.Time)
+	return since, ok
+}
+
+// CheckIdle returns an error wrapping ErrNotIdle unless the resource has been
+// idle for the policy's threshold
+func (p IdlePolicy) CheckIdle(resource *ResourceV2, now time.Time) error {
+	since, ok := IdleSince(resource)
+	if !ok {
+		return fmt.Errorf("%w: %s is in use", ErrNotIdle, resource.ID)
+	}
+	if idle := now.Sub(since); idle < p.Threshold() {
+		return fmt.Errorf("%w: %s has been idle for %s (min %s)", ErrNotIdle, resource.ID, idle.Round(time.Minute), p.Threshold())
+	}
+	return nil
+}
+
+// quickWinActions are the cleanup actions for idle resources, which free an
+// unused endpoint, address or volume and put nothing running at risk
+var quickWinActions = map[string]ActionType{
+	ResourceTypeLoadBalancer: ActionDeleteLoadBalancer,
+	ResourceTypeElasticIP:    ActionReleaseAddress,
+	ResourceTypeEBS:          ActionDeleteVolume,
+}
+
+// QuickWinAction returns the cleanup action for an idle load balancer,
+// static IP or volume. These are high-confidence, low-risk savings; see
+// AutoApprovable for which may skip human approval. False means the
+// resource is not a quick win.
+func QuickWinAction(resource *ResourceV2) (ActionType, bool) {
+	action, ok := quickWinActions[resource.Type]
+	if !ok {
+		return "", false
+	}
+	if _, idle := IdleSince(resource); !idle {
+		return "", false
+	}
+	return action, true
+}
+
+// IsQuickWin reports whether the action is the cleanup of an idle resource
+func (a ActionType) IsQuickWin() bool {
+	for _, action := range quickWinActions {
+		if action == a {
+			return true
+		}
+	}
+	return false
+}
+
+// AutoApprovable reports whether the action is a quick win that may skip
+// human approval. Deleting a volume destroys its data, no snapshot is taken
+// first and its idle time is only observed in memory, so volume deletions
+// always wait for a human.
+func (a ActionType) AutoApprovable() bool {
+	return a.IsQuickWin() && a != ActionDeleteVolume
+}
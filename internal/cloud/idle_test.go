@@ -0,0 +1,52 @@
+package cloud
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdlePolicy_CheckIdle(t *testing.T) {
+	now := time.Now()
+	policy := IdlePolicy{MinIdle: 7 * 24 * time.Hour}
+	idleFor := func(d time.Duration) *ResourceV2 {
+		return &ResourceV2{ID: "eipalloc-1", Type: ResourceTypeElasticIP, Metadata: map[string]interface{}{IdleSinceKey: now.Add(-d)}}
+	}
+
+	assert.NoError(t, policy.CheckIdle(idleFor(8*24*time.Hour), now))
+	assert.ErrorIs(t, policy.CheckIdle(idleFor(time.Hour), now), ErrNotIdle)
+	assert.ErrorIs(t, policy.CheckIdle(&ResourceV2{ID: "eipalloc-2"}, now), ErrNotIdle)
+
+	// An unset threshold falls back to the default rather than allowing anything
+	assert.ErrorIs(t, IdlePolicy{}.CheckIdle(idleFor(time.Hour), now), ErrNotIdle)
+}
+
+func TestQuickWinAction(t *testing.T) {
+	idle := map[string]interface{}{IdleSinceKey: time.Now().Add(-30 * 24 * time.Hour)}
+
+	action, ok := QuickWinAction(&ResourceV2{Type: ResourceTypeLoadBalancer, Metadata: idle})
+	assert.True(t, ok)
+	assert.Equal(t, ActionDeleteLoadBalancer, action)
+
+	action, ok = QuickWinAction(&ResourceV2{Type: ResourceTypeElasticIP, Metadata: idle})
+	assert.True(t, ok)
+	assert.Equal(t, ActionReleaseAddress, action)
+
+	action, ok = QuickWinAction(&ResourceV2{Type: ResourceTypeEBS, Metadata: idle})
+	assert.True(t, ok)
+	assert.Equal(t, ActionDeleteVolume, action)
+
+	_, ok = QuickWinAction(&ResourceV2{Type: ResourceTypeLoadBalancer})
+	assert.False(t, ok, "in use")
+	_, ok = QuickWinAction(&ResourceV2{Type: ResourceTypeEC2, Metadata: idle})
+	assert.False(t, ok, "not a cleanup type")
+
+	assert.True(t, ActionReleaseAddress.IsQuickWin())
+	assert.False(t, ActionTerminate.IsQuickWin())
+
+	assert.True(t, ActionReleaseAddress.AutoApprovable())
+	assert.True(t, ActionDeleteLoadBalancer.AutoApprovable())
+	assert.False(t, ActionDeleteVolume.AutoApprovable(), "volume deletions always need approval")
+	assert.False(t, ActionTerminate.AutoApprovable())
+}
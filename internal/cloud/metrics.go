@@ -0,0 +1,66 @@
+package cloud
+
+import (
+	"context"
+)
+
+// Metric names shared by adapters and external metrics providers
+const (
+	MetricCPUUsage    = "cpu_usage"
+	MetricMemoryUsage = "memory_usage"
+	MetricNetworkIn   = "network_in"
+	MetricNetworkOut  = "network_out"
+)
+
+// Metrics modes control how an external provider is combined with the
+// provider-native source (e.g. CloudWatch)
+const (
+	// MetricsModeMerge prefers external values and fills gaps from the native source
+	MetricsModeMerge = "merge"
+	// MetricsModeReplace uses only the external provider, falling back to the
+	// native source if it fails
+	MetricsModeReplace = "replace"
+)
+
+// Metrics maps metric names to values. A missing key means the source had no
+// data for that metric, as opposed to a measured zero.
+type Metrics map[string]float64
+
+// Merge returns a copy of m with metrics it lacks taken from fallback
+func (m Metrics) Merge(fallback Metrics) Metrics {
+	merged := make(Metrics, len(m)+len(fallback))
+	for name, value := range fallback {
+		merged[name] = value
+	}
+	for name, value := range m {
+		merged[name] = value
+	}
+	return merged
+}
+
+// Apply copies the metrics onto the resource's utilization fields. Fields
+// without a metric are left unchanged. A memory metric also marks the
+// resource's memory as measured.
+func (m Metrics) Apply(resource *ResourceV2) {
+	if v, ok := m[MetricCPUUsage]; ok {
+		resource.CPUUsage = v
+	}
+	if v, ok := m[MetricMemoryUsage]; ok {
+		resource.MemoryUsage = v
+		resource.MemoryMetricAvailable = true
+	}
+	if v, ok := m[MetricNetworkIn]; ok {
+		resource.NetworkIn = v
+	}
+	if v, ok := m[MetricNetworkOut]; ok {
+		resource.NetworkOut = v
+	}
+}
+
+// MetricsProvider pulls utilization metrics for a resource from a source
+// outside the cloud provider, such as Datadog or Prometheus. Providers match
+// the resource to their own hosts, typically by tag.
+type MetricsProvider interface {
+	Name() string
+	FetchMetrics(ctx context.Context, resource *ResourceV2) (Metrics, error)
+}
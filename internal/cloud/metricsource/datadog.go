@@ -0,0 +1,110 @@
+package metricsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/config"
+)
+
+// datadogWindow is how far back each Datadog query looks
+const datadogWindow = time.Hour
+
+// DefaultDatadogQueries assume the standard Datadog agent host metrics
+var DefaultDatadogQueries = map[string]string{
+	cloud.MetricCPUUsage:    "100 - avg:system.cpu.idle{host:{host}}",
+	cloud.MetricMemoryUsage: "100 * (1 - avg:system.mem.pct_usable{host:{host}})",
+	cloud.MetricNetworkIn:   "sum:system.net.bytes_rcvd{host:{host}}.rollup(sum, 3600)",
+	cloud.MetricNetworkOut:  "sum:system.net.bytes_sent{host:{host}}.rollup(sum, 3600)",
+}
+
+// Datadog reads metrics through the Datadog v1 query API
+type Datadog struct {
+	baseURL string
+	apiKey  string
+	appKey  string
+	hostTag string
+	queries map[string]string
+	client  *http.Client
+}
+
+// NewDatadog creates a Datadog metrics provider. Queries override
+// DefaultDatadogQueries per metric name.
+func NewDatadog(cfg config.DatadogConfig, hostTag string, queries map[string]string, client *http.Client) *Datadog {
+	site := cfg.Site
+	if site == "" {
+		site = "datadoghq.com"
+	}
+	return &Datadog{
+		baseURL: "https://api." + site,
+		apiKey:  cfg.APIKey,
+		appKey:  cfg.AppKey,
+		hostTag: hostTag,
+		queries: mergeQueries(DefaultDatadogQueries, queries),
+		client:  client,
+	}
+}
+
+// Name identifies the provider in logs
+func (d *Datadog) Name() string {
+	return "datadog"
+}
+
+// FetchMetrics returns the latest value of each query for the resource's host
+func (d *Datadog) FetchMetrics(ctx context.Context, resource *cloud.ResourceV2) (cloud.Metrics, error) {
+	return fetchAll(ctx, d.queries, hostFor(resource, d.hostTag), d.query)
+}
+
+type datadogResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Series []struct {
+		Pointlist [][2]*float64 `json:"pointlist"`
+	} `json:"series"`
+}
+
+// query runs a timeseries query and returns its most recent non-null point
+func (d *Datadog) query(ctx context.Context, q string) (float64, bool, error) {
+	now := time.Now()
+	params := url.Values{
+		"query": {q},
+		"from":  {strconv.FormatInt(now.Add(-datadogWindow).Unix(), 10)},
+		"to":    {strconv.FormatInt(now.Unix(), 10)},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.baseURL+"/api/v1/query?"+params.Encode(), nil)
+	if err != nil {
+		return 0, false, err
+	}
+	req.Header.Set("DD-API-KEY", d.apiKey)
+	req.Header.Set("DD-APPLICATION-KEY", d.appKey)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+
+	var body datadogResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, false, fmt.Errorf("decode datadog response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Status == "error" {
+		return 0, false, fmt.Errorf("datadog query failed (status %d): %s", resp.StatusCode, body.Error)
+	}
+
+	for _, series := range body.Series {
+		for i := len(series.Pointlist) - 1; i >= 0; i-- {
+			if value := series.Pointlist[i][1]; value != nil {
+				return *value, true, nil
+			}
+		}
+	}
+	return 0, false, nil
+}
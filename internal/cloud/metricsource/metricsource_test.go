@@ -0,0 +1,84 @@
+package metricsource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWithoutProvider(t *testing.T) {
+	provider, err := New(config.MetricsConfig{})
+	require.NoError(t, err)
+	assert.Nil(t, provider)
+
+	_, err = New(config.MetricsConfig{Provider: "datadog"})
+	assert.Error(t, err, "datadog requires keys")
+}
+
+func TestPrometheusFetchMetricsMatchesHostTag(t *testing.T) {
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		queries = append(queries, r.URL.Query().Get("query"))
+		w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"72.5"]}]}}`))
+	}))
+	defer server.Close()
+
+	provider := NewPrometheus(config.PrometheusConfig{URL: server.URL + "/", BearerToken: "secret"}, "Name",
+		map[string]string{cloud.MetricMemoryUsage: `mem_used_percent{host="{host}"}`}, server.Client())
+
+	metrics, err := provider.FetchMetrics(context.Background(), &cloud.ResourceV2{
+		ID:   "i-123",
+		Tags: map[string]string{"Name": "web-1"},
+	})
+	require.NoError(t, err)
+	assert.Len(t, metrics, 4)
+	assert.Equal(t, 72.5, metrics[cloud.MetricMemoryUsage])
+
+	// The configured query replaces the default and targets the tagged host
+	assert.Contains(t, queries, `mem_used_percent{host="web-1"}`)
+	assert.NotContains(t, queries, DefaultPrometheusQueries[cloud.MetricMemoryUsage])
+}
+
+func TestDatadogFetchMetricsSkipsMissingData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "api", r.Header.Get("DD-API-KEY"))
+		assert.Equal(t, "app", r.Header.Get("DD-APPLICATION-KEY"))
+		if r.URL.Query().Get("query") == "cpu{host:i-123}" {
+			w.Write([]byte(`{"status":"ok","series":[{"pointlist":[[1700000000000,12.0],[1700000060000,null]]}]}`))
+			return
+		}
+		w.Write([]byte(`{"status":"ok","series":[]}`))
+	}))
+	defer server.Close()
+
+	provider := NewDatadog(config.DatadogConfig{APIKey: "api", AppKey: "app"}, "Name", nil, server.Client())
+	provider.baseURL = server.URL
+	provider.queries = map[string]string{
+		cloud.MetricCPUUsage:    "cpu{host:{host}}",
+		cloud.MetricMemoryUsage: "mem{host:{host}}",
+	}
+
+	// Without the host tag the resource ID is used as the host
+	metrics, err := provider.FetchMetrics(context.Background(), &cloud.ResourceV2{ID: "i-123"})
+	require.NoError(t, err)
+	assert.Equal(t, cloud.Metrics{cloud.MetricCPUUsage: 12.0}, metrics)
+}
+
+func TestFetchAllFailsWhenEveryQueryFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":"error","error":"bad query"}`))
+	}))
+	defer server.Close()
+
+	provider := NewPrometheus(config.PrometheusConfig{URL: server.URL}, "", nil, server.Client())
+	_, err := provider.FetchMetrics(context.Background(), &cloud.ResourceV2{ID: "i-123"})
+	assert.ErrorContains(t, err, "bad query")
+}
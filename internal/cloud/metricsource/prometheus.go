@@ -0,0 +1,101 @@
+package metricsource
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/config"
+)
+
+// DefaultPrometheusQueries assume node_exporter metrics whose instance label
+// is the host name, with or without a port
+var DefaultPrometheusQueries = map[string]string{
+	cloud.MetricCPUUsage:    `100 - avg(rate(node_cpu_seconds_total{mode="idle",instance=~"{host}(:.*)?"}[5m])) * 100`,
+	cloud.MetricMemoryUsage: `100 * (1 - sum(node_memory_MemAvailable_bytes{instance=~"{host}(:.*)?"}) / sum(node_memory_MemTotal_bytes{instance=~"{host}(:.*)?"}))`,
+	cloud.MetricNetworkIn:   `sum(increase(node_network_receive_bytes_total{instance=~"{host}(:.*)?",device!="lo"}[1h]))`,
+	cloud.MetricNetworkOut:  `sum(increase(node_network_transmit_bytes_total{instance=~"{host}(:.*)?",device!="lo"}[1h]))`,
+}
+
+// Prometheus reads metrics through the Prometheus HTTP instant query API
+type Prometheus struct {
+	baseURL     string
+	bearerToken string
+	hostTag     string
+	queries     map[string]string
+	client      *http.Client
+}
+
+// NewPrometheus creates a Prometheus metrics provider. Queries override
+// DefaultPrometheusQueries per metric name.
+func NewPrometheus(cfg config.PrometheusConfig, hostTag string, queries map[string]string, client *http.Client) *Prometheus {
+	return &Prometheus{
+		baseURL:     strings.TrimRight(cfg.URL, "/"),
+		bearerToken: cfg.BearerToken,
+		hostTag:     hostTag,
+		queries:     mergeQueries(DefaultPrometheusQueries, queries),
+		client:      client,
+	}
+}
+
+// Name identifies the provider in logs
+func (p *Prometheus) Name() string {
+	return "prometheus"
+}
+
+// FetchMetrics evaluates each query for the resource's host
+func (p *Prometheus) FetchMetrics(ctx context.Context, resource *cloud.ResourceV2) (cloud.Metrics, error) {
+	return fetchAll(ctx, p.queries, hostFor(resource, p.hostTag), p.query)
+}
+
+type prometheusResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Value [2]interface{} `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// query evaluates an instant query and returns the first sample, if any
+func (p *Prometheus) query(ctx context.Context, q string) (float64, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/api/v1/query?"+url.Values{"query": {q}}.Encode(), nil)
+	if err != nil {
+		return 0, false, err
+	}
+	if p.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.bearerToken)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, false, err
+	}
+	defer resp.Body.Close()
+
+	var body prometheusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, false, fmt.Errorf("decode prometheus response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Status != "success" {
+		return 0, false, fmt.Errorf("prometheus query failed (status %d): %s", resp.StatusCode, body.Error)
+	}
+	if body.Data.ResultType != "vector" || len(body.Data.Result) == 0 {
+		return 0, false, nil
+	}
+
+	// Sample values are strings so that NaN and Inf survive JSON
+	raw, _ := body.Data.Result[0].Value[1].(string)
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("parse prometheus sample %q: %w", raw, err)
+	}
+	return value, true, nil
+}
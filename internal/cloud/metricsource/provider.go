@@ -0,0 +1,87 @@
+// Package metricsource pulls resource utilization from external monitoring
+// systems (Datadog, Prometheus) for customers whose metrics, memory in
+// particular, are not in CloudWatch.
+package metricsource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/config"
+)
+
+// HostPlaceholder is replaced in every query with the resource's host name
+const HostPlaceholder = "{host}"
+
+// New returns the configured metrics provider, or nil if none is configured
+func New(cfg config.MetricsConfig) (cloud.MetricsProvider, error) {
+	if cfg.Provider == "" {
+		return nil, nil
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	client := &http.Client{Timeout: timeout}
+
+	switch cfg.Provider {
+	case "datadog":
+		return NewDatadog(cfg.Datadog, cfg.HostTag, cfg.Queries, client), nil
+	case "prometheus":
+		return NewPrometheus(cfg.Prometheus, cfg.HostTag, cfg.Queries, client), nil
+	default:
+		return nil, fmt.Errorf("unknown metrics provider: %s", cfg.Provider)
+	}
+}
+
+// hostFor returns the name a resource is known by in the external system:
+// the value of hostTag if the resource has it, otherwise its ID
+func hostFor(resource *cloud.ResourceV2, hostTag string) string {
+	if hostTag != "" {
+		if host := resource.Tags[hostTag]; host != "" {
+			return host
+		}
+	}
+	return resource.ID
+}
+
+// mergeQueries overlays configured queries on a provider's defaults
+func mergeQueries(defaults, overrides map[string]string) map[string]string {
+	queries := make(map[string]string, len(defaults))
+	for name, query := range defaults {
+		queries[name] = query
+	}
+	for name, query := range overrides {
+		queries[name] = query
+	}
+	return queries
+}
+
+// fetchAll runs every query for the resource's host. Metrics the source has
+// no data for are omitted; an error is returned only if no query succeeded.
+func fetchAll(ctx context.Context, queries map[string]string, host string, query func(ctx context.Context, q string) (float64, bool, error)) (cloud.Metrics, error) {
+	metrics := cloud.Metrics{}
+	var errs []string
+	for name, q := range queries {
+		value, ok, err := query(ctx, strings.ReplaceAll(q, HostPlaceholder, host))
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if ok {
+			metrics[name] = value
+		}
+	}
+	if len(errs) == len(queries) && len(errs) > 0 {
+		return nil, fmt.Errorf("all metric queries failed for host %s: %s", host, strings.Join(errs, "; "))
+	}
+	return metrics, nil
+}
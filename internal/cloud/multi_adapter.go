@@ -0,0 +1,379 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+
+	"github.com/Xover-Official/Xover/internal/cache"
+)
+
+// ErrAmbiguousResource is returned when a native resource ID exists in more
+// than one account or region and must be addressed by its canonical key
+var ErrAmbiguousResource = errors.New("resource ID is ambiguous across accounts/regions")
+
+// ResourceKeyResolver is implemented by adapters that can look resources up
+// by canonical key (see ResourceV2.CanonicalKey)
+type ResourceKeyResolver interface {
+	GetResourceByKey(ctx context.Context, key string) (*ResourceV2, error)
+}
+
+// AccountAdapter is an adapter scoped to a single account and region
+type AccountAdapter struct {
+	Account string
+	Region  string
+	Adapter CloudAdapter
+}
+
+// Defaults for the per-member circuit breaker
+const (
+	DefaultRegionBreakerThreshold = 3
+	DefaultRegionBreakerCooldown  = 5 * time.Minute
+)
+
+// MultiAdapter aggregates adapters for several accounts and regions into one
+// CloudAdapter. Resources are stamped with their account and region and
+// deduplicated by canonical key, and every call is routed to the adapter that
+// owns the resource. A failing account/region does not fail the fetch, and one
+// that keeps failing is skipped for a cooldown by its circuit breaker.
+type MultiAdapter struct {
+	members  []AccountAdapter
+	breakers []*cache.Breaker // parallel to members
+	regions  RegionPolicy
+
+	mu     sync.RWMutex
+	owners map[string]int    // canonical key -> member index, from the last fetch
+	native map[string]string // canonical key -> native ID
+}
+
+// NewMultiAdapter creates an aggregating adapter over the given members
+func NewMultiAdapter(members ...AccountAdapter) *MultiAdapter {
+	a := &MultiAdapter{
+		members: members,
+		owners:  make(map[string]int),
+		native:  make(map[string]string),
+	}
+	return a.WithBreaker(DefaultRegionBreakerThreshold, DefaultRegionBreakerCooldown)
+}
+
+// WithBreaker replaces the per-member circuit breakers: after threshold
+// consecutive failed fetches a member is skipped for cooldown
+func (a *MultiAdapter) WithBreaker(threshold int, cooldown time.Duration) *MultiAdapter {
+	a.breakers = make([]*cache.Breaker, len(a.members))
+	for i := range a.members {
+		a.breakers[i] = cache.NewBreaker(threshold, cooldown)
+	}
+	return a
+}
+
+// WithRegionPolicy drops members in regions the policy excludes, so they are
+// never called, and filters out resources reported in excluded regions
+func (a *MultiAdapter) WithRegionPolicy(policy RegionPolicy) *MultiAdapter {
+	members := a.members[:0:0]
+	breakers := a.breakers[:0:0]
+	for i, member := range a.members {
+		if !policy.Allows(member.Region) {
+			log.Printf("skipping account %s in excluded region %s", member.Account, member.Region)
+			continue
+		}
+		members = append(members, member)
+		breakers = append(breakers, a.breakers[i])
+	}
+	a.members, a.breakers, a.regions = members, breakers, policy
+	return a
+}
+
+// stamp fills in the member's account and region where the adapter left them blank
+func (m AccountAdapter) stamp(resource *ResourceV2) {
+	if resource.Account == "" {
+		resource.Account = m.Account
+	}
+	if resource.Region == "" {
+		resource.Region = m.Region
+	}
+}
+
+// FetchResources fetches from every member concurrently and returns the
+// merged list with duplicate canonical keys removed (first member wins).
+// Members that fail, or whose circuit breaker is open, are reported in a
+// *RegionFetchError returned alongside the other members' resources; use
+// PartialFetch to tell whether the result is usable.
+func (a *MultiAdapter) FetchResources(ctx context.Context) ([]*ResourceV2, error) {
+	results := make([][]*ResourceV2, len(a.members))
+	errs := make([]error, len(a.members))
+
+	var wg sync.WaitGroup
+	for i, member := range a.members {
+		breaker := a.breakers[i]
+		if !breaker.Allow() {
+			errs[i] = ErrRegionUnavailable
+			continue
+		}
+		wg.Add(1)
+		go func(i int, member AccountAdapter) {
+			defer wg.Done()
+			results[i], errs[i] = member.Adapter.FetchResources(ctx)
+			if errs[i] != nil {
+				breaker.Failure()
+			} else {
+				breaker.Success()
+			}
+		}(i, member)
+	}
+	wg.Wait()
+
+	owners := make(map[string]int)
+	native := make(map[string]string)
+	var merged []*ResourceV2
+	var fetchErr RegionFetchError
+	duplicates := 0
+
+	for i, member := range a.members {
+		if errs[i] != nil {
+			fetchErr.Failures = append(fetchErr.Failures, RegionFailure{Account: member.Account, Region: member.Region, Err: errs[i]})
+			continue
+		}
+		fetchErr.Succeeded++
+		for _, resource := range results[i] {
+			if resource == nil {
+				continue
+			}
+			member.stamp(resource)
+			if !a.regions.Allows(resource.Region) {
+				continue
+			}
+			key := resource.CanonicalKey()
+			if _, seen := owners[key]; seen {
+				duplicates++
+				continue
+			}
+			owners[key] = i
+			native[key] = resource.ID
+			merged = append(merged, resource)
+		}
+	}
+
+	if duplicates > 0 {
+		log.Printf("dropped %d duplicate resources across %d accounts/regions", duplicates, len(a.members))
+	}
+
+	if len(fetchErr.Failures) > 0 {
+		log.Printf("failed to fetch %d of %d accounts/regions: %v", len(fetchErr.Failures), len(a.members), &fetchErr)
+		if fetchErr.Succeeded == 0 {
+			return nil, &fetchErr
+		}
+	}
+
+	a.mu.Lock()
+	// Resources of members that failed this time stay routable from the
+	// previous fetch, so pending actions survive a brief regional outage
+	for key, idx := range a.owners {
+		if _, seen := owners[key]; !seen && errs[idx] != nil {
+			owners[key] = idx
+			native[key] = a.native[key]
+		}
+	}
+	a.owners, a.native = owners, native
+	a.mu.Unlock()
+
+	if len(fetchErr.Failures) > 0 {
+		return merged, &fetchErr
+	}
+	return merged, nil
+}
+
+// GetResourceByKey retrieves a resource seen in the last fetch by canonical key
+func (a *MultiAdapter) GetResourceByKey(ctx context.Context, key string) (*ResourceV2, error) {
+	return a.getByKey(ctx, key, CloudAdapter.GetResource)
+}
+
+// GetResource accepts a canonical key or a native ID. A native ID present in
+// more than one account or region returns ErrAmbiguousResource.
+func (a *MultiAdapter) GetResource(ctx context.Context, id string) (*ResourceV2, error) {
+	return a.get(ctx, id, CloudAdapter.GetResource)
+}
+
+// GetResourceMetadata looks a resource up like GetResource, without its
+// metrics when the owning adapter is a MetadataGetter
+func (a *MultiAdapter) GetResourceMetadata(ctx context.Context, id string) (*ResourceV2, error) {
+	return a.get(ctx, id, func(adapter CloudAdapter, ctx context.Context, id string) (*ResourceV2, error) {
+		if getter, ok := adapter.(MetadataGetter); ok {
+			return getter.GetResourceMetadata(ctx, id)
+		}
+		return adapter.GetResource(ctx, id)
+	})
+}
+
+// GetResources groups the IDs, canonical keys or native IDs, by owning
+// member and makes one batch lookup per member. Ambiguous and unknown IDs
+// are left out. A member's failure is returned alongside the resources the
+// other members found.
+func (a *MultiAdapter) GetResources(ctx context.Context, ids []string) (map[string]*ResourceV2, error) {
+	type batch struct {
+		native    []string
+		requested []string // parallel to native
+	}
+	batches := make([]batch, len(a.members))
+	for _, id := range ids {
+		key, err := a.resolve(id)
+		if err != nil {
+			continue
+		}
+		idx, nativeID, ok := a.owner(key)
+		if !ok {
+			if len(a.members) != 1 {
+				continue
+			}
+			idx, nativeID = 0, id
+		}
+		batches[idx].native = append(batches[idx].native, nativeID)
+		batches[idx].requested = append(batches[idx].requested, id)
+	}
+
+	found := make(map[string]*ResourceV2, len(ids))
+	var errs error
+	for idx, b := range batches {
+		if len(b.native) == 0 {
+			continue
+		}
+		member := a.members[idx]
+		resources, err := member.Adapter.GetResources(ctx, b.native)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("account %s region %s: %w", member.Account, member.Region, err))
+		}
+		for i, nativeID := range b.native {
+			if resource, ok := resources[nativeID]; ok {
+				member.stamp(resource)
+				found[b.requested[i]] = resource
+			}
+		}
+	}
+	return found, errs
+}
+
+// resourceLookup is GetResource or an equivalent on a member adapter
+type resourceLookup func(adapter CloudAdapter, ctx context.Context, id string) (*ResourceV2, error)
+
+// get resolves a canonical key or native ID to its owning member and looks
+// the resource up there
+func (a *MultiAdapter) get(ctx context.Context, id string, lookup resourceLookup) (*ResourceV2, error) {
+	key, err := a.resolve(id)
+	switch {
+	case err != nil:
+		return nil, err
+	case key != "":
+		return a.getByKey(ctx, key, lookup)
+	case len(a.members) == 1:
+		return lookup(a.members[0].Adapter, ctx, id)
+	default:
+		return nil, fmt.Errorf("resource not found: %s", id)
+	}
+}
+
+// resolve returns the canonical key for a canonical key or native ID seen in
+// the last fetch, or "" for an ID it has not seen
+func (a *MultiAdapter) resolve(id string) (string, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if _, ok := a.owners[id]; ok {
+		return id, nil
+	}
+	var matches []string
+	for key, nativeID := range a.native {
+		if nativeID == id {
+			matches = append(matches, key)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", nil
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("%s matches %d resources: %w", id, len(matches), ErrAmbiguousResource)
+	}
+}
+
+// owner returns the index of the member owning a canonical key and the
+// resource's native ID there
+func (a *MultiAdapter) owner(key string) (int, string, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	idx, ok := a.owners[key]
+	return idx, a.native[key], ok
+}
+
+func (a *MultiAdapter) getByKey(ctx context.Context, key string, lookup resourceLookup) (*ResourceV2, error) {
+	idx, id, ok := a.owner(key)
+	if !ok {
+		return nil, fmt.Errorf("resource not found: %s", key)
+	}
+
+	member := a.members[idx]
+	resource, err := lookup(member.Adapter, ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	member.stamp(resource)
+	return resource, nil
+}
+
+// ApplyOptimization routes the action to the adapter that owns the resource
+func (a *MultiAdapter) ApplyOptimization(ctx context.Context, resource *ResourceV2, action string) (float64, error) {
+	key := resource.CanonicalKey()
+	a.mu.RLock()
+	idx, ok := a.owners[key]
+	a.mu.RUnlock()
+	if !ok {
+		return 0, fmt.Errorf("no adapter owns resource %s", key)
+	}
+	return a.members[idx].Adapter.ApplyOptimization(ctx, resource, action)
+}
+
+// Rollback routes to the adapter that owns the resource, if it can roll back
+func (a *MultiAdapter) Rollback(ctx context.Context, resource *ResourceV2, action string, snapshot ResourceSnapshot) error {
+	key := resource.CanonicalKey()
+	a.mu.RLock()
+	idx, ok := a.owners[key]
+	a.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no adapter owns resource %s", key)
+	}
+	rollbacker, ok := a.members[idx].Adapter.(Rollbacker)
+	if !ok {
+		return ErrRollbackUnsupported
+	}
+	return rollbacker.Rollback(ctx, resource, action, snapshot)
+}
+
+// GetSpotPrice asks the first member; spot prices are not account specific
+func (a *MultiAdapter) GetSpotPrice(zone, instanceType string) (float64, error) {
+	if len(a.members) == 0 {
+		return 0, fmt.Errorf("no cloud adapters configured")
+	}
+	return a.members[0].Adapter.GetSpotPrice(zone, instanceType)
+}
+
+// ListZones returns the union of every member's zones
+func (a *MultiAdapter) ListZones() ([]string, error) {
+	seen := make(map[string]bool)
+	var zones []string
+	for _, member := range a.members {
+		memberZones, err := member.Adapter.ListZones()
+		if err != nil {
+			return nil, err
+		}
+		for _, zone := range memberZones {
+			if !seen[zone] {
+				seen[zone] = true
+				zones = append(zones, zone)
+			}
+		}
+	}
+	return zones, nil
+}
@@ -0,0 +1,214 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAccountSimulator(ids ...string) *Simulator {
+	sim := &Simulator{}
+	for _, id := range ids {
+		sim.MockResources = append(sim.MockResources, &ResourceV2{ID: id, Provider: ProviderAWS, CostPerMonth: 100})
+	}
+	return sim
+}
+
+func TestMultiAdapter_DedupesByCanonicalKey(t *testing.T) {
+	ctx := context.Background()
+	prod := newAccountSimulator("i-shared", "i-prod")
+	adapter := NewMultiAdapter(
+		AccountAdapter{Account: "111", Region: "us-east-1", Adapter: prod},
+		AccountAdapter{Account: "222", Region: "us-east-1", Adapter: newAccountSimulator("i-shared")},
+		// The same account configured twice yields exact duplicates
+		AccountAdapter{Account: "111", Region: "us-east-1", Adapter: newAccountSimulator("i-prod")},
+	)
+
+	resources, err := adapter.FetchResources(ctx)
+	require.NoError(t, err)
+
+	keys := make([]string, len(resources))
+	for i, r := range resources {
+		keys[i] = r.CanonicalKey()
+	}
+	assert.Equal(t, []string{
+		"aws/111/us-east-1/i-shared",
+		"aws/111/us-east-1/i-prod",
+		"aws/222/us-east-1/i-shared",
+	}, keys)
+
+	_, err = adapter.GetResource(ctx, "i-shared")
+	assert.ErrorIs(t, err, ErrAmbiguousResource)
+
+	resource, err := adapter.GetResource(ctx, "aws/222/us-east-1/i-shared")
+	require.NoError(t, err)
+	assert.Equal(t, "222", resource.Account)
+
+	resource, err = adapter.GetResource(ctx, "i-prod")
+	require.NoError(t, err)
+	assert.Equal(t, "111", resource.Account)
+
+	_, err = adapter.ApplyOptimization(ctx, &ResourceV2{ID: "i-unknown", Provider: ProviderAWS}, "stop")
+	assert.Error(t, err)
+}
+
+// failingAdapter fails every fetch and counts the attempts
+type failingAdapter struct {
+	Simulator
+	calls int
+}
+
+func (f *failingAdapter) FetchResources(ctx context.Context) ([]*ResourceV2, error) {
+	f.calls++
+	return nil, errors.New("region outage")
+}
+
+func TestMultiAdapter_ToleratesRegionFailure(t *testing.T) {
+	ctx := context.Background()
+	down := &failingAdapter{}
+	adapter := NewMultiAdapter(
+		AccountAdapter{Account: "111", Region: "us-east-1", Adapter: newAccountSimulator("i-east")},
+		AccountAdapter{Account: "111", Region: "eu-west-1", Adapter: down},
+	).WithBreaker(2, time.Hour)
+
+	resources, err := adapter.FetchResources(ctx)
+	require.Error(t, err)
+	partial, ok := PartialFetch(err)
+	require.True(t, ok)
+	assert.Equal(t, 1, partial.Succeeded)
+	require.Len(t, partial.Failures, 1)
+	assert.Equal(t, "eu-west-1", partial.Failures[0].Region)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "i-east", resources[0].ID)
+
+	// The second failure opens the breaker, after which the region is skipped
+	_, _ = adapter.FetchResources(ctx)
+	_, err = adapter.FetchResources(ctx)
+	assert.ErrorIs(t, err, ErrRegionUnavailable)
+	assert.Equal(t, 2, down.calls)
+}
+
+func TestMultiAdapter_AllRegionsFailing(t *testing.T) {
+	adapter := NewMultiAdapter(AccountAdapter{Account: "111", Region: "us-east-1", Adapter: &failingAdapter{}})
+
+	resources, err := adapter.FetchResources(context.Background())
+	assert.Nil(t, resources)
+	assert.Error(t, err)
+	_, ok := PartialFetch(err)
+	assert.False(t, ok)
+}
+
+func TestMultiAdapter_RegionPolicy(t *testing.T) {
+	denied := &failingAdapter{}
+	multiRegion := newAccountSimulator("i-east", "i-west")
+	multiRegion.MockResources[1].Region = "us-west-2"
+
+	adapter := NewMultiAdapter(
+		AccountAdapter{Account: "111", Region: "us-east-1", Adapter: multiRegion},
+		AccountAdapter{Account: "111", Region: "ap-south-1", Adapter: denied},
+	).WithRegionPolicy(RegionPolicy{Allow: []string{"us-*"}, Deny: []string{"us-west-*"}})
+
+	resources, err := adapter.FetchResources(context.Background())
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+	assert.Equal(t, "i-east", resources[0].ID)
+	assert.Zero(t, denied.calls, "excluded regions are never called")
+}
+
+// metadataSimulator answers metadata lookups without utilization and
+// counts the full lookups
+type metadataSimulator struct {
+	Simulator
+	fullLookups int
+}
+
+func (m *metadataSimulator) GetResource(ctx context.Context, id string) (*ResourceV2, error) {
+	m.fullLookups++
+	return m.Simulator.GetResource(ctx, id)
+}
+
+func (m *metadataSimulator) GetResourceMetadata(ctx context.Context, id string) (*ResourceV2, error) {
+	resource, err := m.Simulator.GetResource(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	light := *resource
+	light.CPUUsage = 0
+	return &light, nil
+}
+
+func TestMultiAdapter_GetResourceMetadata(t *testing.T) {
+	ctx := context.Background()
+	metadata := &metadataSimulator{Simulator: *newAccountSimulator("i-light")}
+	metadata.MockResources[0].CPUUsage = 40
+	plain := newAccountSimulator("i-plain")
+	plain.MockResources[0].CPUUsage = 40
+	adapter := NewMultiAdapter(
+		AccountAdapter{Account: "111", Region: "us-east-1", Adapter: metadata},
+		AccountAdapter{Account: "222", Region: "us-east-1", Adapter: plain},
+	)
+	_, err := adapter.FetchResources(ctx)
+	require.NoError(t, err)
+
+	resource, err := adapter.GetResourceMetadata(ctx, "aws/111/us-east-1/i-light")
+	require.NoError(t, err)
+	assert.Equal(t, "111", resource.Account)
+	assert.Zero(t, resource.CPUUsage)
+	assert.Zero(t, metadata.fullLookups)
+
+	// Members without a metadata lookup fall back to GetResource
+	resource, err = adapter.GetResourceMetadata(ctx, "i-plain")
+	require.NoError(t, err)
+	assert.Equal(t, "222", resource.Account)
+	assert.Equal(t, 40.0, resource.CPUUsage)
+}
+
+// batchSimulator records the IDs of each GetResources call
+type batchSimulator struct {
+	Simulator
+	batches [][]string
+}
+
+func (b *batchSimulator) GetResources(ctx context.Context, ids []string) (map[string]*ResourceV2, error) {
+	b.batches = append(b.batches, ids)
+	return b.Simulator.GetResources(ctx, ids)
+}
+
+func TestMultiAdapter_GetResourcesBatchesPerMember(t *testing.T) {
+	ctx := context.Background()
+	east := &batchSimulator{Simulator: *newAccountSimulator("i-shared", "i-east", "i-east2")}
+	west := &batchSimulator{Simulator: *newAccountSimulator("i-shared")}
+	adapter := NewMultiAdapter(
+		AccountAdapter{Account: "111", Region: "us-east-1", Adapter: east},
+		AccountAdapter{Account: "111", Region: "us-west-2", Adapter: west},
+	)
+	_, err := adapter.FetchResources(ctx)
+	require.NoError(t, err)
+
+	resources, err := adapter.GetResources(ctx, []string{
+		"i-east", "i-east2", "aws/111/us-west-2/i-shared",
+		"i-shared",  // ambiguous
+		"i-unknown", // never fetched
+	})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"i-east", "i-east2"}}, east.batches)
+	assert.Equal(t, [][]string{{"i-shared"}}, west.batches)
+
+	require.Len(t, resources, 3)
+	assert.Equal(t, "us-east-1", resources["i-east"].Region)
+	assert.Equal(t, "us-west-2", resources["aws/111/us-west-2/i-shared"].Region)
+}
+
+func TestRegionPolicy_Allows(t *testing.T) {
+	policy := RegionPolicy{Allow: []string{"eu-*", "us-east-1"}, Deny: []string{"eu-south-*"}}
+	assert.True(t, policy.Allows("us-east-1"))
+	assert.True(t, policy.Allows("eu-west-1"))
+	assert.False(t, policy.Allows("eu-south-1"))
+	assert.False(t, policy.Allows("ap-south-1"))
+	assert.True(t, policy.Allows(""))
+	assert.True(t, RegionPolicy{}.Allows("ap-south-1"))
+}
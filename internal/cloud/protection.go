@@ -0,0 +1,73 @@
+package cloud
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+)
+
+// Protection tag that always guarantees a resource is never modified,
+// regardless of the configured ProtectionPolicy
+const (
+	ProtectedTagKey   = "talos:protected"
+	ProtectedTagValue = "true"
+)
+
+// ErrResourceProtected is returned when a mutating action targets a protected resource
+var ErrResourceProtected = errors.New("resource is protected")
+
+// ProtectionPolicy identifies resources that must never be modified.
+// Tags maps a tag key to the required value; "*" matches any value.
+// ResourceIDs are glob patterns (path.Match syntax) matched against the resource
+// ID and its tag values, so a pattern also protects a resource by its Name tag.
+type ProtectionPolicy struct {
+	Tags        map[string]string `json:"tags,omitempty" yaml:"tags"`
+	ResourceIDs []string          `json:"resource_ids,omitempty" yaml:"resource_ids"`
+}
+
+// ProtectionReason reports why a resource is protected, or "" if it is not
+func (p ProtectionPolicy) ProtectionReason(resource *ResourceV2) string {
+	if resource == nil {
+		return ""
+	}
+	if resource.Tags[ProtectedTagKey] == ProtectedTagValue {
+		return fmt.Sprintf("tag %s=%s", ProtectedTagKey, ProtectedTagValue)
+	}
+	for key, want := range p.Tags {
+		if got, ok := resource.Tags[key]; ok && (want == "*" || got == want) {
+			return fmt.Sprintf("tag %s=%s", key, got)
+		}
+	}
+	keys := make([]string, 0, len(resource.Tags))
+	for key := range resource.Tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, pattern := range p.ResourceIDs {
+		if matched, _ := path.Match(pattern, resource.ID); matched {
+			return fmt.Sprintf("id matches %q", pattern)
+		}
+		for _, key := range keys {
+			if matched, _ := path.Match(pattern, resource.Tags[key]); matched {
+				return fmt.Sprintf("tag %s=%s matches %q", key, resource.Tags[key], pattern)
+			}
+		}
+	}
+	return ""
+}
+
+// IsProtected reports whether the resource must not be modified
+func (p ProtectionPolicy) IsProtected(resource *ResourceV2) bool {
+	return p.ProtectionReason(resource) != ""
+}
+
+// CheckMutation returns an error wrapping ErrResourceProtected if the action
+// may not be applied to the resource. Adapters call it before any change as
+// the last line of defense.
+func (p ProtectionPolicy) CheckMutation(resource *ResourceV2, action string) error {
+	if reason := p.ProtectionReason(resource); reason != "" {
+		return fmt.Errorf("refusing %s on %s (%s): %w", action, resource.ID, reason, ErrResourceProtected)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package cloud
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtectionPolicy_ProtectionReason(t *testing.T) {
+	policy := ProtectionPolicy{
+		Tags:        map[string]string{"team": "*", "tier": "critical"},
+		ResourceIDs: []string{"db-prod-*", "*payment*"},
+	}
+
+	tests := []struct {
+		name     string
+		resource *ResourceV2
+		want     string
+	}{
+		{"protection tag", &ResourceV2{ID: "i-1", Tags: map[string]string{ProtectedTagKey: ProtectedTagValue}}, "tag talos:protected=true"},
+		{"any tag value", &ResourceV2{ID: "i-1", Tags: map[string]string{"team": "data"}}, "tag team=data"},
+		{"exact tag value", &ResourceV2{ID: "i-1", Tags: map[string]string{"tier": "critical"}}, "tag tier=critical"},
+		{"other tag value", &ResourceV2{ID: "i-1", Tags: map[string]string{"tier": "batch"}}, ""},
+		{"id pattern", &ResourceV2{ID: "db-prod-orders"}, `id matches "db-prod-*"`},
+		{"pattern on a Name tag", &ResourceV2{ID: "i-0abc", Tags: map[string]string{"Name": "payment-gateway"}}, `tag Name=payment-gateway matches "*payment*"`},
+		{"unprotected", &ResourceV2{ID: "i-0def", Tags: map[string]string{"Name": "web-01"}}, ""},
+		{"nil resource", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, policy.ProtectionReason(tt.resource))
+			assert.Equal(t, tt.want != "", policy.IsProtected(tt.resource))
+		})
+	}
+
+	err := policy.CheckMutation(&ResourceV2{ID: "i-0abc", Tags: map[string]string{"Name": "payment-gateway"}}, "terminate")
+	assert.ErrorIs(t, err, ErrResourceProtected)
+}
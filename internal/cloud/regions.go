@@ -0,0 +1,85 @@
+package cloud
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ErrRegionUnavailable is reported for an account/region skipped because its
+// circuit breaker is open after repeated failures
+var ErrRegionUnavailable = errors.New("region temporarily unavailable")
+
+// RegionPolicy limits which regions are scanned. An empty Allow list allows
+// every region; Deny always wins. Entries are glob patterns (path.Match
+// syntax), e.g. "eu-*".
+type RegionPolicy struct {
+	Allow []string `json:"allow,omitempty" yaml:"allow"`
+	Deny  []string `json:"deny,omitempty" yaml:"deny"`
+}
+
+// Allows reports whether a region may be scanned. A blank region is always
+// allowed since the resource's location is unknown.
+func (p RegionPolicy) Allows(region string) bool {
+	if region == "" {
+		return true
+	}
+	if matchesRegion(p.Deny, region) {
+		return false
+	}
+	return len(p.Allow) == 0 || matchesRegion(p.Allow, region)
+}
+
+func matchesRegion(patterns []string, region string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, region); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// RegionFailure is one account/region whose fetch failed
+type RegionFailure struct {
+	Account string
+	Region  string
+	Err     error
+}
+
+// RegionFetchError aggregates the per-region failures of a multi-region
+// fetch. Succeeded counts the accounts/regions that returned resources, so a
+// caller can tell a partial outage from a total one (see PartialFetch).
+type RegionFetchError struct {
+	Failures  []RegionFailure
+	Succeeded int
+}
+
+func (e *RegionFetchError) Error() string {
+	parts := make([]string, len(e.Failures))
+	for i, f := range e.Failures {
+		parts[i] = fmt.Sprintf("account %s in %s: %v", f.Account, f.Region, f.Err)
+	}
+	return fmt.Sprintf("failed to fetch resources from %d of %d accounts/regions: %s",
+		len(e.Failures), len(e.Failures)+e.Succeeded, strings.Join(parts, "; "))
+}
+
+// Unwrap exposes the per-region errors to errors.Is and errors.As
+func (e *RegionFetchError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// PartialFetch reports whether err is a RegionFetchError from a fetch where
+// at least one account/region succeeded, in which case the returned resources
+// are usable and the error only needs to be reported
+func PartialFetch(err error) (*RegionFetchError, bool) {
+	var fetchErr *RegionFetchError
+	if errors.As(err, &fetchErr) && fetchErr.Succeeded > 0 {
+		return fetchErr, true
+	}
+	return nil, false
+}
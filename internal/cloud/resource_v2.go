@@ -1,6 +1,7 @@
 package cloud
 
 import (
+	"strings"
 	"time"
 )
 
@@ -26,6 +27,9 @@ type ResourceV2 struct {
 	NetworkIn   float64 `json:"network_in"`
 	NetworkOut  float64 `json:"network_out"`
 	DiskIO      float64 `json:"disk_io"`
+	// MemoryMetricAvailable is set when a metrics source reported memory.
+	// Without it a zero MemoryUsage means unknown, not idle.
+	MemoryMetricAvailable bool `json:"memory_metric_available"`
 
 	// Cost & Billing
 	CostPerHour  float64 `json:"cost_per_hour"`
@@ -62,6 +66,18 @@ type ResourceV2 struct {
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// CanonicalKey uniquely identifies the resource across providers, accounts and
+// regions, where native IDs alone may collide
+func (r *ResourceV2) CanonicalKey() string {
+	return strings.Join([]string{r.Provider, r.Account, r.Region, r.ID}, "/")
+}
+
+// MemoryKnown reports whether MemoryUsage holds a measurement. Resources
+// built without a metrics source count as known when memory was set.
+func (r *ResourceV2) MemoryKnown() bool {
+	return r.MemoryMetricAvailable || r.MemoryUsage > 0
+}
+
 // GetEfficiencyScore calculates overall efficiency (0-100)
 func (r *ResourceV2) GetEfficiencyScore() float64 {
 	// Weighted score based on utilization and cost
@@ -0,0 +1,31 @@
+package cloud
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRollbackUnsupported is returned by a Rollbacker that cannot undo the
+// given action on the given resource
+var ErrRollbackUnsupported = errors.New("rollback not supported")
+
+// ResourceSnapshot records what an action may change about a resource, taken
+// before the action runs so that a partial failure can be undone
+type ResourceSnapshot struct {
+	State        string `json:"state"`
+	InstanceType string `json:"instance_type,omitempty"`
+}
+
+// SnapshotResource takes a resource's snapshot
+func SnapshotResource(resource *ResourceV2) ResourceSnapshot {
+	instanceType, _ := resource.Metadata["instance_type"].(string)
+	return ResourceSnapshot{State: resource.State, InstanceType: instanceType}
+}
+
+// Rollbacker is implemented by adapters that can restore a resource after an
+// action on it failed part way, e.g. restart an instance stopped for a resize
+// that then failed. Rollback must be safe to call when the action changed
+// nothing.
+type Rollbacker interface {
+	Rollback(ctx context.Context, resource *ResourceV2, action string, snapshot ResourceSnapshot) error
+}
@@ -9,6 +9,7 @@ import (
 // Simulator implements the CloudAdapter interface for testing and simulation.
 type Simulator struct {
 	MockResources []*ResourceV2
+	Protection    ProtectionPolicy
 }
 
 func NewSimulator() *Simulator {
@@ -53,16 +54,19 @@ func (s *Simulator) GetResource(ctx context.Context, id string) (*ResourceV2, er
 	return nil, fmt.Errorf("resource not found: %s", id)
 }
 
+func (s *Simulator) GetResources(ctx context.Context, ids []string) (map[string]*ResourceV2, error) {
+	return GetResourcesOneByOne(ctx, s, ids)
+}
+
 func (s *Simulator) ApplyOptimization(ctx context.Context, resource *ResourceV2, action string) (float64, error) {
-	// Simulate savings: 50% for resize/optimize, 100% for stop/terminate
-	switch action {
-	case "stop", "terminate":
-		return resource.CostPerMonth, nil
-	case "resize", "optimize":
-		return resource.CostPerMonth * 0.5, nil
-	default:
-		return 0, nil
+	if err := s.Protection.CheckMutation(resource, action); err != nil {
+		return 0, err
+	}
+	if _, err := ValidateAction(resource, action); err != nil {
+		return 0, err
 	}
+	// Simulate savings using the default per-action ratios
+	return resource.CostPerMonth * CloudConfig{}.SavingsRatio(action), nil
 }
 
 func (s *Simulator) GetSpotPrice(zone, instanceType string) (float64, error) {
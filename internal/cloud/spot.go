@@ -0,0 +1,29 @@
+package cloud
+
+import "context"
+
+// AvailabilityZoneKey is the resource metadata key holding the zone an
+// instance runs in
+const AvailabilityZoneKey = "availability_zone"
+
+// SpotMarket describes the spot market for an instance type in a zone
+type SpotMarket struct {
+	Zone         string  `json:"zone"`
+	InstanceType string  `json:"instance_type"`
+	Price        float64 `json:"price"` // latest hourly price
+	// PriceVolatility is the standard deviation of the recent prices over
+	// their mean
+	PriceVolatility float64 `json:"price_volatility"`
+	// PlacementScore is the provider's 1-10 likelihood that a spot request
+	// succeeds; 0 when it couldn't be looked up
+	PlacementScore int `json:"placement_score,omitempty"`
+	// InterruptionRisk estimates from 0 to 1 how likely spot capacity is to
+	// be reclaimed, from the placement score and the price volatility
+	InterruptionRisk float64 `json:"interruption_risk"`
+}
+
+// SpotMarketProvider is implemented by adapters that can look up spot
+// market conditions
+type SpotMarketProvider interface {
+	SpotMarket(ctx context.Context, zone, instanceType string) (*SpotMarket, error)
+}
@@ -0,0 +1,150 @@
+package cloud
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+// Tags used by the termination safeguards
+const (
+	// DefaultBackupTagKey holds the time of the resource's last backup or snapshot
+	DefaultBackupTagKey = "talos:last-backup"
+	// TerminateAfterTagKey is set by a soft terminate to the RFC 3339 time
+	// after which the stopped resource may be terminated
+	TerminateAfterTagKey = "talos:terminate-after"
+	// KeepTagKey is an objection to a pending soft terminate; any value
+	// cancels it
+	KeepTagKey = "talos:keep"
+)
+
+// ErrTerminationRefused is returned when a termination fails a safeguard
+var ErrTerminationRefused = errors.New("termination refused")
+
+// ErrTerminationPending is returned when a soft-terminated resource is still
+// inside its grace period
+var ErrTerminationPending = errors.New("termination pending")
+
+// DefaultProtectedEnvironments are the environments whose resources are
+// never terminated when a TerminationPolicy names none
+var DefaultProtectedEnvironments = []string{"production", "prod"}
+
+// TerminationPolicy guards the irreversible terminate action. Resources in a
+// ProtectedEnvironments environment or matching a ProtectedResources pattern
+// are never terminated. Others must have been idle for MinIdle (default
+// DefaultMinIdle) and carry a backup tag no older than MaxBackupAge. With
+// SoftTerminate the resource is first stopped and tagged, and only
+// terminated once GracePeriod passes without a KeepTagKey objection.
+type TerminationPolicy struct {
+	MinIdle          time.Duration `json:"min_idle" yaml:"min_idle"`
+	IdleCPUThreshold float64       `json:"idle_cpu_threshold" yaml:"idle_cpu_threshold"` // percent
+	BackupTag        string        `json:"backup_tag" yaml:"backup_tag"`                 // default DefaultBackupTagKey
+	MaxBackupAge     time.Duration `json:"max_backup_age" yaml:"max_backup_age"`
+	SoftTerminate    bool          `json:"soft_terminate" yaml:"soft_terminate"`
+	GracePeriod      time.Duration `json:"grace_period" yaml:"grace_period"`
+	// ProtectedEnvironments are matched against the resource's "environment"
+	// or "env" tag; nil uses DefaultProtectedEnvironments
+	ProtectedEnvironments []string `json:"protected_environments" yaml:"protected_environments"`
+	// ProtectedResources are glob patterns (path.Match syntax) matched
+	// against the resource ID
+	ProtectedResources []string `json:"protected_resources" yaml:"protected_resources"`
+}
+
+// CheckProtected returns an error wrapping ErrTerminationRefused if the
+// resource's environment or ID puts it out of reach of termination
+func (p TerminationPolicy) CheckProtected(resource *ResourceV2) error {
+	environments := p.ProtectedEnvironments
+	if environments == nil {
+		environments = DefaultProtectedEnvironments
+	}
+	env := resource.Tags["environment"]
+	if env == "" {
+		env = resource.Tags["env"]
+	}
+	for _, protected := range environments {
+		if env != "" && strings.EqualFold(env, protected) {
+			return fmt.Errorf("%w: %s is in the %s environment", ErrTerminationRefused, resource.ID, env)
+		}
+	}
+	for _, pattern := range p.ProtectedResources {
+		if matched, _ := path.Match(pattern, resource.ID); matched {
+			return fmt.Errorf("%w: %s matches protected pattern %q", ErrTerminationRefused, resource.ID, pattern)
+		}
+	}
+	return nil
+}
+
+// IdleThreshold returns MinIdle, or DefaultMinIdle when it is unset, so an
+// unconfigured policy never skips the idle check
+func (p TerminationPolicy) IdleThreshold() time.Duration {
+	if p.MinIdle <= 0 {
+		return DefaultMinIdle
+	}
+	return p.MinIdle
+}
+
+// backupTag returns the tag key holding the last backup time
+func (p TerminationPolicy) backupTag() string {
+	if p.BackupTag == "" {
+		return DefaultBackupTagKey
+	}
+	return p.BackupTag
+}
+
+// CheckBackup returns an error wrapping ErrTerminationRefused unless the
+// resource's backup tag records a backup within MaxBackupAge. The tag value
+// may be an RFC 3339 timestamp or a date.
+func (p TerminationPolicy) CheckBackup(resource *ResourceV2, now time.Time) error {
+	key := p.backupTag()
+	value, ok := resource.Tags[key]
+	if !ok {
+		return fmt.Errorf("%w: %s has no %s tag", ErrTerminationRefused, resource.ID, key)
+	}
+
+	backedUp, err := parseTagTime(value)
+	if err != nil {
+		return fmt.Errorf("%w: %s has an unreadable %s tag %q", ErrTerminationRefused, resource.ID, key, value)
+	}
+	if p.MaxBackupAge > 0 && now.Sub(backedUp) > p.MaxBackupAge {
+		return fmt.Errorf("%w: last backup of %s was %s ago (max %s)",
+			ErrTerminationRefused, resource.ID, now.Sub(backedUp).Round(time.Minute), p.MaxBackupAge)
+	}
+	return nil
+}
+
+// PendingTermination returns when a soft-terminated resource becomes
+// eligible for termination, and false if no soft terminate is pending
+func PendingTermination(resource *ResourceV2) (time.Time, bool) {
+	value, ok := resource.Tags[TerminateAfterTagKey]
+	if !ok {
+		return time.Time{}, false
+	}
+	after, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return after, true
+}
+
+// CheckGracePeriod decides whether a pending soft terminate may proceed. It
+// returns ErrTerminationPending inside the grace period and
+// ErrTerminationRefused if an owner objected.
+func CheckGracePeriod(resource *ResourceV2, after, now time.Time) error {
+	if _, objected := resource.Tags[KeepTagKey]; objected {
+		return fmt.Errorf("%w: %s is tagged %s", ErrTerminationRefused, resource.ID, KeepTagKey)
+	}
+	if now.Before(after) {
+		return fmt.Errorf("%w: %s may be terminated after %s", ErrTerminationPending, resource.ID, after.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// parseTagTime reads a timestamp stored in a tag value
+func parseTagTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,81 @@
+package cloud
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerminationPolicy_CheckBackup(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	policy := TerminationPolicy{MaxBackupAge: 7 * 24 * time.Hour}
+
+	cases := []struct {
+		name string
+		tags map[string]string
+		ok   bool
+	}{
+		{"recent timestamp", map[string]string{DefaultBackupTagKey: "2026-03-09T08:00:00Z"}, true},
+		{"recent date", map[string]string{DefaultBackupTagKey: "2026-03-05"}, true},
+		{"stale", map[string]string{DefaultBackupTagKey: "2026-02-01"}, false},
+		{"unreadable", map[string]string{DefaultBackupTagKey: "yesterday"}, false},
+		{"missing", nil, false},
+	}
+	for _, tc := range cases {
+		err := policy.CheckBackup(&ResourceV2{ID: "i-1", Tags: tc.tags}, now)
+		if tc.ok {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.ErrorIs(t, err, ErrTerminationRefused, tc.name)
+		}
+	}
+
+	custom := TerminationPolicy{BackupTag: "snapshot"}
+	assert.NoError(t, custom.CheckBackup(&ResourceV2{Tags: map[string]string{"snapshot": "2020-01-01"}}, now), "no max age")
+}
+
+func TestCheckGracePeriod(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	resource := &ResourceV2{ID: "i-1", Tags: map[string]string{TerminateAfterTagKey: "2026-03-11T12:00:00Z"}}
+
+	after, pending := PendingTermination(resource)
+	assert.True(t, pending)
+	assert.ErrorIs(t, CheckGracePeriod(resource, after, now), ErrTerminationPending)
+	assert.NoError(t, CheckGracePeriod(resource, after, now.Add(48*time.Hour)))
+
+	resource.Tags[KeepTagKey] = "owner says no"
+	assert.ErrorIs(t, CheckGracePeriod(resource, after, now.Add(48*time.Hour)), ErrTerminationRefused)
+
+	_, pending = PendingTermination(&ResourceV2{})
+	assert.False(t, pending)
+}
+
+func TestTerminationPolicy_CheckProtected(t *testing.T) {
+	policy := TerminationPolicy{ProtectedResources: []string{"db-prod-*", "auth-*"}}
+
+	cases := []struct {
+		name string
+		id   string
+		tags map[string]string
+		ok   bool
+	}{
+		{"production environment", "i-1", map[string]string{"environment": "production"}, false},
+		{"env tag, any case", "i-1", map[string]string{"env": "PROD"}, false},
+		{"protected pattern", "db-prod-orders", nil, false},
+		{"other environment", "i-1", map[string]string{"environment": "staging"}, true},
+		{"untagged", "db-staging-orders", nil, true},
+	}
+	for _, tc := range cases {
+		err := policy.CheckProtected(&ResourceV2{ID: tc.id, Tags: tc.tags})
+		if tc.ok {
+			assert.NoError(t, err, tc.name)
+		} else {
+			assert.ErrorIs(t, err, ErrTerminationRefused, tc.name)
+		}
+	}
+
+	custom := TerminationPolicy{ProtectedEnvironments: []string{"staging"}}
+	assert.ErrorIs(t, custom.CheckProtected(&ResourceV2{Tags: map[string]string{"environment": "staging"}}), ErrTerminationRefused)
+	assert.NoError(t, custom.CheckProtected(&ResourceV2{Tags: map[string]string{"environment": "production"}}), "replaces the defaults")
+}
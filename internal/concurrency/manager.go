@@ -0,0 +1,180 @@
+// Package concurrency bounds and accounts for the goroutines started on hot
+// paths, so sustained load can't grow them without limit.
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// DefaultMaxGoroutines is the ceiling of the default manager
+const DefaultMaxGoroutines = 1000
+
+// ErrSaturated is returned by TryGo when every slot is in use
+var ErrSaturated = errors.New("goroutine limit reached")
+
+// Manager caps how many goroutines run on its behalf at once. Go waits for a
+// free slot; TryGo drops the work instead.
+type Manager struct {
+	slots    chan struct{}
+	wg       sync.WaitGroup
+	rejected atomic.Int64
+}
+
+// NewManager creates a manager allowing maxGoroutines at once; zero or less
+// uses DefaultMaxGoroutines
+func NewManager(maxGoroutines int) *Manager {
+	if maxGoroutines <= 0 {
+		maxGoroutines = DefaultMaxGoroutines
+	}
+	return &Manager{slots: make(chan struct{}, maxGoroutines)}
+}
+
+var defaultManager atomic.Pointer[Manager]
+
+func init() {
+	defaultManager.Store(NewManager(DefaultMaxGoroutines))
+}
+
+// Default returns the process-wide manager shared by the hot paths
+func Default() *Manager {
+	return defaultManager.Load()
+}
+
+// SetDefault replaces the process-wide manager. Call it at startup, before
+// components capture Default.
+func SetDefault(m *Manager) {
+	defaultManager.Store(m)
+}
+
+// Go runs fn on a new goroutine once a slot is free. If ctx ends first, fn is
+// not run and ctx's error is returned.
+func (m *Manager) Go(ctx context.Context, fn func()) error {
+	select {
+	case m.slots <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	m.start(fn)
+	return nil
+}
+
+// TryGo runs fn on a new goroutine if a slot is free, and otherwise drops it
+// and returns ErrSaturated. It suits fire-and-forget work, such as
+// notifications, that must not pile up behind a slow receiver.
+func (m *Manager) TryGo(fn func()) error {
+	select {
+	case m.slots <- struct{}{}:
+	default:
+		m.rejected.Add(1)
+		return ErrSaturated
+	}
+	m.start(fn)
+	return nil
+}
+
+// start runs fn on a goroutine holding an already acquired slot
+func (m *Manager) start(fn func()) {
+	m.wg.Add(1)
+	go func() {
+		defer m.release()
+		fn()
+	}()
+}
+
+func (m *Manager) release() {
+	<-m.slots
+	m.wg.Done()
+}
+
+// Active returns how many goroutines are running
+func (m *Manager) Active() int {
+	return len(m.slots)
+}
+
+// Limit returns the most goroutines that may run at once
+func (m *Manager) Limit() int {
+	return cap(m.slots)
+}
+
+// Rejected returns how many TryGo calls were dropped
+func (m *Manager) Rejected() int64 {
+	return m.rejected.Load()
+}
+
+// Wait blocks until every goroutine has returned, or ctx ends, for a
+// graceful shutdown
+func (m *Manager) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Group is an errgroup.Group whose goroutines also count against a manager's
+// ceiling. Functions run in a group must not start goroutines on the same
+// manager, or a saturated manager would deadlock.
+type Group struct {
+	m   *Manager
+	eg  *errgroup.Group
+	ctx context.Context
+
+	mu      sync.Mutex
+	skipped error
+}
+
+// Group returns a new group and a context canceled when a function in it
+// fails or Wait returns. limit caps the group's own goroutines, as with
+// errgroup's SetLimit; zero or less leaves only the manager's ceiling.
+func (m *Manager) Group(ctx context.Context, limit int) (*Group, context.Context) {
+	eg, groupCtx := errgroup.WithContext(ctx)
+	if limit > 0 {
+		eg.SetLimit(limit)
+	}
+	return &Group{m: m, eg: eg, ctx: groupCtx}, groupCtx
+}
+
+// Go runs fn in the group, blocking while the manager or the group is at its
+// limit. If the group's context ends first, fn is not run and Wait reports
+// the context's error.
+func (g *Group) Go(fn func() error) {
+	select {
+	case g.m.slots <- struct{}{}:
+	case <-g.ctx.Done():
+		g.mu.Lock()
+		if g.skipped == nil {
+			g.skipped = g.ctx.Err()
+		}
+		g.mu.Unlock()
+		return
+	}
+
+	g.m.wg.Add(1)
+	g.eg.Go(func() error {
+		defer g.m.release()
+		return fn()
+	})
+}
+
+// Wait blocks until every function in the group has returned and returns
+// the first error, or the context error if functions were skipped
+func (g *Group) Wait() error {
+	if err := g.eg.Wait(); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.skipped
+}
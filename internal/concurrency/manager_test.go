@@ -0,0 +1,120 @@
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+)
+
+func TestMain(m *testing.M) {
+	goleak.VerifyTestMain(m)
+}
+
+func TestManager_GoWaitsForSlot(t *testing.T) {
+	m := NewManager(2)
+	release := make(chan struct{})
+	var peak, running atomic.Int64
+
+	for i := 0; i < 5; i++ {
+		go func() {
+			_ = m.Go(context.Background(), func() {
+				n := running.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				<-release
+				running.Add(-1)
+			})
+		}()
+	}
+
+	require.Eventually(t, func() bool { return m.Active() == 2 }, time.Second, time.Millisecond)
+	close(release)
+	require.Eventually(t, func() bool { return m.Active() == 0 && running.Load() == 0 }, time.Second, time.Millisecond)
+	require.NoError(t, m.Wait(context.Background()))
+	assert.LessOrEqual(t, peak.Load(), int64(2))
+}
+
+func TestManager_GoReturnsWhenContextEnds(t *testing.T) {
+	m := NewManager(1)
+	release := make(chan struct{})
+	require.NoError(t, m.Go(context.Background(), func() { <-release }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := m.Go(ctx, func() { t.Error("ran without a slot") })
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	require.NoError(t, m.Wait(context.Background()))
+}
+
+func TestManager_TryGoDropsWhenSaturated(t *testing.T) {
+	m := NewManager(1)
+	release := make(chan struct{})
+	require.NoError(t, m.TryGo(func() { <-release }))
+
+	assert.ErrorIs(t, m.TryGo(func() {}), ErrSaturated)
+	assert.Equal(t, int64(1), m.Rejected())
+
+	close(release)
+	require.NoError(t, m.Wait(context.Background()))
+	assert.NoError(t, m.TryGo(func() {}))
+	require.NoError(t, m.Wait(context.Background()))
+}
+
+func TestGroup_LimitsAndReturnsFirstError(t *testing.T) {
+	m := NewManager(10)
+	g, ctx := m.Group(context.Background(), 2)
+	failure := errors.New("boom")
+	var peak, running atomic.Int64
+
+	for i := 0; i < 6; i++ {
+		g.Go(func() error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			if i == 1 {
+				return failure
+			}
+			<-ctx.Done()
+			return nil
+		})
+	}
+
+	assert.ErrorIs(t, g.Wait(), failure)
+	assert.LessOrEqual(t, peak.Load(), int64(2))
+	assert.Zero(t, m.Active())
+}
+
+func TestGroup_CountsAgainstManager(t *testing.T) {
+	m := NewManager(1)
+	release := make(chan struct{})
+	require.NoError(t, m.Go(context.Background(), func() { <-release }))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	g, _ := m.Group(ctx, 0)
+	cancel()
+	g.Go(func() error {
+		t.Error("ran without a slot")
+		return nil
+	})
+	assert.ErrorIs(t, g.Wait(), context.Canceled)
+
+	close(release)
+	require.NoError(t, m.Wait(context.Background()))
+}
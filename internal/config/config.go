@@ -1,8 +1,14 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path"
+	"sort"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -14,19 +20,42 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration `yaml:"read_timeout"`
 	WriteTimeout time.Duration `yaml:"write_timeout"`
 	IdleTimeout  time.Duration `yaml:"idle_timeout"`
+
+	// MaxGoroutines caps the goroutines the hot paths (analysis, actions,
+	// notifications) run at once; 0 uses the built-in default
+	MaxGoroutines int `yaml:"max_goroutines"`
+
+	// TrustedProxies lists the load balancers (IPs or CIDRs) whose
+	// X-Forwarded-For and X-Real-IP headers name the client. Requests from
+	// anywhere else are rate limited by their own address.
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	// RateLimit is each client IP's request budget
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
 }
 
+// RateLimitConfig allows Requests per Window from each client IP
+type RateLimitConfig struct {
+	Requests int           `yaml:"requests"`
+	Window   time.Duration `yaml:"window"`
+}
+
+// AIConfig holds the AI provider keys. Each tier calls its provider directly
+// when that provider's key is set, and goes through OpenRouter otherwise.
 type AIConfig struct {
 	OpenRouterKey        string        `yaml:"openrouter_key"`
 	GeminiAPIKey         string        `yaml:"gemini_api_key"`
 	ClaudeAPIKey         string        `yaml:"claude_api_key"`
 	GPT5MiniAPIKey       string        `yaml:"gpt5_mini_api_key"`
 	DevinKey             string        `yaml:"devin_key"`
-	DevinsAPIKey         string        `yaml:"devins_api_key"`
 	CacheEnabled         bool          `yaml:"cache_enabled"`
 	MaxTokensPerRequest  int           `yaml:"max_tokens_per_request"`
 	MaxRequestsPerMinute int           `yaml:"max_requests_per_minute"`
 	Timeout              time.Duration `yaml:"timeout"`
+	Mock                 bool          `yaml:"mock"`                   // Offline deterministic AI responses for demos and CI
+	MaxPromptTokens      int           `yaml:"max_prompt_tokens"`      // Prompt budget; low-signal sections are truncated beyond it
+	SchemaRetries        int           `yaml:"schema_retries"`         // Re-prompts for responses that violate the decision schema
+	FallbackChain        []string      `yaml:"fallback_chain"`         // Tiers tried in order when a tier's provider is unavailable
+	MaxCostPerCycleUSD   float64       `yaml:"max_cost_per_cycle_usd"` // AI spend allowed per OODA cycle; 0 means unlimited
 }
 
 type AITiersConfig struct {
@@ -47,10 +76,21 @@ type RedisConfig struct {
 	DialTimeout  time.Duration `yaml:"dial_timeout"`
 	ReadTimeout  time.Duration `yaml:"read_timeout"`
 	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// Degraded operation: after BreakerThreshold consecutive failures Redis
+	// calls are skipped for BreakerCooldown, and up to FallbackQueueSize
+	// tasks are buffered in memory until Redis recovers
+	BreakerThreshold  int           `yaml:"breaker_threshold"`
+	BreakerCooldown   time.Duration `yaml:"breaker_cooldown"`
+	FallbackQueueSize int           `yaml:"fallback_queue_size"`
 }
 
 type DatabaseConfig struct {
 	DSN string `yaml:"dsn"`
+	// Connection pool tuning; zero keeps the driver default
+	MaxConns        int           `yaml:"max_conns"`
+	MinConns        int           `yaml:"min_conns"`
+	MaxConnLifetime time.Duration `yaml:"max_conn_lifetime"`
+	MaxConnIdleTime time.Duration `yaml:"max_conn_idle_time"`
 }
 
 type CloudConfig struct {
@@ -58,14 +98,120 @@ type CloudConfig struct {
 	Region               string        `yaml:"region"`
 	DryRun               bool          `yaml:"dry_run"`
 	MaxAPICallsPerMinute int           `yaml:"max_api_calls_per_minute"`
-	RetryAttempts        int           `yaml:"retry_attempts"`
+	RetryAttempts        int           `yaml:"retry_attempts"` // tries per cloud API call, including the first
 	RetryDelay           time.Duration `yaml:"retry_delay"`
 	ResourceTypes        []string      `yaml:"resource_types"`
+	// SavingsRatios maps action type to the fraction of monthly cost it is
+	// assumed to save (e.g. resize: 0.5). Unset actions use the built-in defaults.
+	SavingsRatios map[string]float64 `yaml:"savings_ratios"`
+	Protection    ProtectionConfig   `yaml:"protection"`
+	Termination   TerminationConfig  `yaml:"termination"`
+	Idle          IdleConfig         `yaml:"idle"`
+	Metrics       MetricsConfig      `yaml:"metrics"`
+	Regions       RegionsConfig      `yaml:"regions"`
+}
+
+// RegionsConfig limits the regions that are scanned, as glob patterns such as
+// "eu-*". An empty allow list allows every region and deny always wins.
+// Literal allow entries are scanned alongside the primary region.
+type RegionsConfig struct {
+	Allow []string `yaml:"allow"`
+	Deny  []string `yaml:"deny"`
+}
+
+// ProtectionConfig lists resources that must never be modified, in addition
+// to any resource tagged talos:protected=true
+type ProtectionConfig struct {
+	Tags        map[string]string `yaml:"tags"`         // tag key to value; "*" matches any value
+	ResourceIDs []string          `yaml:"resource_ids"` // glob patterns on IDs and tag values, e.g. "db-prod-*"
+}
+
+// TerminationConfig holds the safeguards for the irreversible terminate
+// action. Resources in protected_environments or matching
+// protected_resources are never terminated. Instances must be idle for
+// min_idle and tagged with a backup no older than max_backup_age; with
+// soft_terminate they are stopped first and terminated after grace_period
+// unless tagged talos:keep.
+type TerminationConfig struct {
+	MinIdle          time.Duration `yaml:"min_idle"`
+	IdleCPUThreshold float64       `yaml:"idle_cpu_threshold"` // percent
+	BackupTag        string        `yaml:"backup_tag"`         // tag holding the last backup time
+	MaxBackupAge     time.Duration `yaml:"max_backup_age"`
+	SoftTerminate    bool          `yaml:"soft_terminate"`
+	GracePeriod      time.Duration `yaml:"grace_period"`
+	// ProtectedEnvironments default to production and prod
+	ProtectedEnvironments []string `yaml:"protected_environments"`
+	// ProtectedResources are glob patterns, added to the T.O.P.A.Z. ones
+	ProtectedResources []string `yaml:"protected_resources"`
+}
+
+// IdleConfig guards the cleanup of idle load balancers and unattached
+// Elastic IPs: they are deleted or released only after serving no traffic, or
+// staying unattached, for min_idle
+type IdleConfig struct {
+	MinIdle time.Duration `yaml:"min_idle"`
+}
+
+// MetricsConfig selects an external metrics source (Datadog or Prometheus)
+// used instead of, or in addition to, the cloud provider's own monitoring
+type MetricsConfig struct {
+	Provider   string            `yaml:"provider"` // datadog or prometheus; empty uses CloudWatch only
+	Mode       string            `yaml:"mode"`     // merge (default) or replace
+	HostTag    string            `yaml:"host_tag"` // resource tag naming the host; empty uses the resource ID
+	Queries    map[string]string `yaml:"queries"`  // metric name to query; {host} is replaced per resource
+	Timeout    time.Duration     `yaml:"timeout"`
+	Datadog    DatadogConfig     `yaml:"datadog"`
+	Prometheus PrometheusConfig  `yaml:"prometheus"`
+}
+
+type DatadogConfig struct {
+	APIKey string `yaml:"api_key"`
+	AppKey string `yaml:"app_key"`
+	Site   string `yaml:"site"` // e.g. datadoghq.eu; defaults to datadoghq.com
+}
+
+type PrometheusConfig struct {
+	URL         string `yaml:"url"`
+	BearerToken string `yaml:"bearer_token"`
+}
+
+// Validate checks the external metrics settings
+func (c MetricsConfig) Validate() error {
+	switch c.Provider {
+	case "":
+		return nil
+	case "datadog":
+		if c.Datadog.APIKey == "" || c.Datadog.AppKey == "" {
+			return fmt.Errorf("datadog metrics require api_key and app_key")
+		}
+	case "prometheus":
+		if c.Prometheus.URL == "" {
+			return fmt.Errorf("prometheus metrics require a url")
+		}
+	default:
+		return fmt.Errorf("unknown metrics provider: %s", c.Provider)
+	}
+
+	switch c.Mode {
+	case "", "merge", "replace":
+	default:
+		return fmt.Errorf("metrics mode must be 'merge' or 'replace'")
+	}
+	for name := range c.Queries {
+		switch name {
+		case "cpu_usage", "memory_usage", "network_in", "network_out":
+		default:
+			return fmt.Errorf("unknown metric in metrics queries: %s", name)
+		}
+	}
+	return nil
 }
 
 type JWTConfig struct {
 	SecretKey     string        `yaml:"secret_key"`
 	TokenDuration time.Duration `yaml:"token_duration"`
+	// RefreshDuration is how long the refresh token issued at sign-in lasts
+	RefreshDuration time.Duration `yaml:"refresh_duration"`
 }
 
 type SSOProviderConfig struct {
@@ -79,6 +225,39 @@ type SSOConfig struct {
 	Google SSOProviderConfig `yaml:"google"`
 	Okta   SSOProviderConfig `yaml:"okta"`
 	Azure  SSOProviderConfig `yaml:"azure"`
+	GitHub SSOProviderConfig `yaml:"github"`
+}
+
+// ChaosConfig enables fault injection for resilience testing. It can never
+// be enabled when the server runs in production mode.
+type ChaosConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	ErrorRate   float64       `yaml:"error_rate"`   // fraction of calls that fail (0-1)
+	LatencyRate float64       `yaml:"latency_rate"` // fraction of calls that are delayed (0-1)
+	Latency     time.Duration `yaml:"latency"`
+	Targets     []string      `yaml:"targets"` // cloud, ai, repository; empty means all
+	Seed        int64         `yaml:"seed"`    // fixed seed for reproducible runs; 0 is random
+}
+
+// Validate checks the chaos settings against the server mode
+func (c ChaosConfig) Validate(mode string) error {
+	if !c.Enabled {
+		return nil
+	}
+	if mode == "production" {
+		return fmt.Errorf("chaos fault injection cannot be enabled in production mode")
+	}
+	if c.ErrorRate < 0 || c.ErrorRate > 1 || c.LatencyRate < 0 || c.LatencyRate > 1 {
+		return fmt.Errorf("chaos error_rate and latency_rate must be between 0 and 1")
+	}
+	for _, target := range c.Targets {
+		switch target {
+		case "cloud", "ai", "repository":
+		default:
+			return fmt.Errorf("unknown chaos target: %s", target)
+		}
+	}
+	return nil
 }
 
 type Config struct {
@@ -91,55 +270,363 @@ type Config struct {
 	Analytics AnalyticsConfig `yaml:"analytics"`
 	JWT       JWTConfig       `yaml:"jwt"`
 	SSO       SSOConfig       `yaml:"sso"`
+	Chaos     ChaosConfig     `yaml:"chaos"`
+	Retention RetentionConfig `yaml:"retention"`
+	Events    EventsConfig    `yaml:"event_stream"`
+	Email     EmailConfig     `yaml:"email"`
+	Reports   ReportsConfig   `yaml:"reports"`
+	Analysis  AnalysisConfig  `yaml:"analysis"`
+	Secrets   SecretsConfig   `yaml:"secrets"`
+}
+
+// SecretsConfig selects where secrets are loaded from. Source is a scheme
+// prefixed location: "env://" (the default), "awssm://NAME_OR_ARN",
+// "ssm:///PATH" or "vault://MOUNT/PATH"; see secrets.NewProvider.
+type SecretsConfig struct {
+	Source          string        `yaml:"source"`
+	RefreshInterval time.Duration `yaml:"refresh_interval"` // how long fetched AWS secrets are reused
+}
+
+// usesEnvironment reports whether secrets come from environment variables,
+// which applyEnv has already read
+func (s SecretsConfig) usesEnvironment() bool {
+	return s.Source == "" || strings.HasPrefix(s.Source, "env://")
+}
+
+// SecretLookup returns the secret stored under key, or "" if there is none
+type SecretLookup func(key string) (string, error)
+
+// AnalysisConfig tunes the engine's orient phase. It is hot-reloadable: the
+// engine picks changes up at the start of its next cycle.
+type AnalysisConfig struct {
+	VectorWeights VectorWeightsConfig `yaml:"vector_weights"`
+}
+
+// VectorWeightsConfig weights the rightsizing, spot, scheduling and cost
+// analysis vectors in the risk score. They must sum to about 1 and are
+// normalized to exactly 1; leaving all of them unset uses the defaults
+// (0.3/0.25/0.2/0.25).
+type VectorWeightsConfig struct {
+	Rightsizing   float64 `yaml:"rightsizing"`
+	SpotArbitrage float64 `yaml:"spot_arbitrage"`
+	Scheduling    float64 `yaml:"scheduling"`
+	CostPatterns  float64 `yaml:"cost_patterns"`
+}
+
+// Validate checks the weights are non-negative and, when set, sum to 1
+// within 0.05
+func (c VectorWeightsConfig) Validate() error {
+	if c == (VectorWeightsConfig{}) {
+		return nil
+	}
+	if c.Rightsizing < 0 || c.SpotArbitrage < 0 || c.Scheduling < 0 || c.CostPatterns < 0 {
+		return fmt.Errorf("analysis.vector_weights must not be negative")
+	}
+	if sum := c.Rightsizing + c.SpotArbitrage + c.Scheduling + c.CostPatterns; sum < 0.95 || sum > 1.05 {
+		return fmt.Errorf("analysis.vector_weights must sum to 1, got %.3f", sum)
+	}
+	return nil
 }
 
 type AnalyticsConfig struct {
 	PersistPath string `yaml:"persist_path"`
+	// Store is where the token tracker keeps its totals: "file" (PersistPath,
+	// the default) or "postgres" (the token_usage table, shared by replicas)
+	Store string `yaml:"store"`
+}
+
+// MinAuditLogRetention is the compliance minimum for audit_log retention;
+// shorter (non-zero) periods are rejected by Validate
+const MinAuditLogRetention = 365 * 24 * time.Hour
+
+// RetentionConfig sets how long each history table is kept before the purge
+// job deletes its rows. A zero period keeps the table forever. LegalHold
+// suspends all purging, and DryRun only counts the rows that would go.
+type RetentionConfig struct {
+	Enabled            bool          `yaml:"enabled"`
+	Interval           time.Duration `yaml:"interval"`
+	BatchSize          int           `yaml:"batch_size"`
+	DryRun             bool          `yaml:"dry_run"`
+	LegalHold          bool          `yaml:"legal_hold"`
+	TokenUsage         time.Duration `yaml:"token_usage"`
+	AIDecisions        time.Duration `yaml:"ai_decisions"`
+	AuditLog           time.Duration `yaml:"audit_log"`
+	SavingsEvents      time.Duration `yaml:"savings_events"`
+	InventorySnapshots time.Duration `yaml:"inventory_snapshots"`
+	EventOutbox        time.Duration `yaml:"event_outbox"`   // delivered stream events
+	LedgerActions      time.Duration `yaml:"ledger_actions"` // finished actions in the SQLite dev ledger
+}
+
+// Periods returns the retention period of each PostgreSQL history table
+func (c RetentionConfig) Periods() map[string]time.Duration {
+	return map[string]time.Duration{
+		"token_usage":         c.TokenUsage,
+		"ai_decisions":        c.AIDecisions,
+		"audit_log":           c.AuditLog,
+		"savings_events":      c.SavingsEvents,
+		"inventory_snapshots": c.InventorySnapshots,
+		"event_outbox":        c.EventOutbox,
+	}
+}
+
+// Validate checks the retention periods against the compliance minimums
+func (c RetentionConfig) Validate() error {
+	if c.AuditLog != 0 && c.AuditLog < MinAuditLogRetention {
+		return fmt.Errorf("retention.audit_log must be at least %s for compliance (0 keeps it forever)", MinAuditLogRetention)
+	}
+	for table, period := range c.Periods() {
+		if period < 0 {
+			return fmt.Errorf("retention.%s must not be negative", table)
+		}
+	}
+	if c.LedgerActions < 0 {
+		return fmt.Errorf("retention.ledger_actions must not be negative")
+	}
+	if c.Enabled && c.Interval <= 0 {
+		return fmt.Errorf("retention.interval must be positive")
+	}
+	return nil
+}
+
+// EventsConfig sends every action lifecycle event, signed, to an external
+// endpoint such as a SIEM. Events are kept in the outbox table until the
+// endpoint accepts them and are delivered in order, at least once.
+type EventsConfig struct {
+	Enabled      bool          `yaml:"enabled"`
+	URL          string        `yaml:"url"`
+	Secret       string        `yaml:"secret"` // HMAC-SHA256 signing key
+	PollInterval time.Duration `yaml:"poll_interval"`
+	BatchSize    int           `yaml:"batch_size"`
+	Timeout      time.Duration `yaml:"timeout"`     // per delivery attempt
+	MaxBackoff   time.Duration `yaml:"max_backoff"` // longest wait between retries
+}
+
+// Validate checks an enabled stream has somewhere to send signed events
+func (c EventsConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.URL == "" {
+		return fmt.Errorf("event_stream.url is required when the event stream is enabled")
+	}
+	if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("event_stream.url must be an http(s) URL")
+	}
+	if c.Secret == "" {
+		return fmt.Errorf("event_stream.secret is required to sign events")
+	}
+	if c.PollInterval <= 0 {
+		return fmt.Errorf("event_stream.poll_interval must be positive")
+	}
+	return nil
 }
 
-// Validate checks the configuration for required fields and valid values
+// EmailConfig is the SMTP server the email notification channel and
+// scheduled reports send through
+type EmailConfig struct {
+	SMTPHost string `yaml:"smtp_host"`
+	SMTPPort int    `yaml:"smtp_port"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// ReportsConfig mails the savings report on a schedule
+type ReportsConfig struct {
+	Enabled       bool                   `yaml:"enabled"`
+	RetryInterval time.Duration          `yaml:"retry_interval"` // wait before retrying a failed delivery
+	MaxAttempts   int                    `yaml:"max_attempts"`   // deliveries tried per scheduled run
+	Schedules     []ReportScheduleConfig `yaml:"schedules"`
+}
+
+// ReportScheduleConfig sends the report for the previous full Period to
+// Recipients whenever Cron fires (five fields, evaluated in UTC)
+type ReportScheduleConfig struct {
+	Name       string   `yaml:"name"`
+	OrgID      string   `yaml:"org_id"` // organization reported on; "" for actions of none
+	Cron       string   `yaml:"cron"`
+	Period     string   `yaml:"period"` // week, month, quarter or year
+	Format     string   `yaml:"format"` // html (default) or pdf
+	Recipients []string `yaml:"recipients"`
+	Subject    string   `yaml:"subject"`
+	LinkURL    string   `yaml:"link_url"`
+}
+
+// Validate checks enabled report schedules can be sent. Cron expressions
+// are parsed when the scheduler starts.
+func (c ReportsConfig) Validate(email EmailConfig) error {
+	if !c.Enabled {
+		return nil
+	}
+	if email.SMTPHost == "" || email.From == "" {
+		return fmt.Errorf("email.smtp_host and email.from are required for scheduled reports")
+	}
+	names := make(map[string]bool)
+	for _, schedule := range c.Schedules {
+		if schedule.Name == "" {
+			return fmt.Errorf("reports.schedules: every schedule needs a name")
+		}
+		if names[schedule.Name] {
+			return fmt.Errorf("reports.schedules: duplicate schedule name %q", schedule.Name)
+		}
+		names[schedule.Name] = true
+		if schedule.Cron == "" {
+			return fmt.Errorf("reports.schedules.%s: cron is required", schedule.Name)
+		}
+		switch schedule.Period {
+		case "week", "month", "quarter", "year":
+		default:
+			return fmt.Errorf("reports.schedules.%s: period must be week, month, quarter or year", schedule.Name)
+		}
+		if schedule.Format != "" && schedule.Format != "html" && schedule.Format != "pdf" {
+			return fmt.Errorf("reports.schedules.%s: format must be html or pdf", schedule.Name)
+		}
+		if len(schedule.Recipients) == 0 {
+			return fmt.Errorf("reports.schedules.%s: at least one recipient is required", schedule.Name)
+		}
+	}
+	return nil
+}
+
+// FieldError is a missing or invalid setting. Field is its YAML path, or
+// the section for checks that span several settings.
+type FieldError struct {
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string { return e.Err.Error() }
+
+func (e *FieldError) Unwrap() error { return e.Err }
+
+// ValidationError lists every problem Validate found, in the order it
+// checks them. Its message is the first problem's.
+type ValidationError struct {
+	Problems []*FieldError
+}
+
+func (e *ValidationError) Error() string { return e.Problems[0].Error() }
+
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Problems))
+	for i, problem := range e.Problems {
+		errs[i] = problem
+	}
+	return errs
+}
+
+// Validate checks the configuration for required fields and valid values,
+// returning a *ValidationError listing every problem
 func (c *Config) Validate() error {
+	var problems []*FieldError
+	fail := func(field string, err error) {
+		if err != nil {
+			problems = append(problems, &FieldError{Field: field, Err: err})
+		}
+	}
+
 	if c.Server.Port == "" {
-		return fmt.Errorf("server port is required")
+		fail("server.port", fmt.Errorf("server port is required"))
 	}
 
 	if c.Server.Mode != "development" && c.Server.Mode != "production" {
-		return fmt.Errorf("server mode must be 'development' or 'production'")
+		fail("server.mode", fmt.Errorf("server mode must be 'development' or 'production'"))
 	}
 
-	if c.AI.OpenRouterKey == "" {
-		return fmt.Errorf("OpenRouter API key is required")
+	if !c.AI.Mock && c.AI.OpenRouterKey == "" && c.AI.GeminiAPIKey == "" && c.AI.ClaudeAPIKey == "" && c.AI.GPT5MiniAPIKey == "" {
+		fail("ai", fmt.Errorf("an AI API key is required: set openrouter_key or a provider key, or enable ai.mock"))
 	}
 
 	if c.JWT.SecretKey == "" {
-		return fmt.Errorf("JWT secret key is required")
-	}
-
-	if len(c.JWT.SecretKey) < 32 {
-		return fmt.Errorf("JWT secret key must be at least 32 characters")
+		fail("jwt.secret_key", fmt.Errorf("JWT secret key is required"))
+	} else if len(c.JWT.SecretKey) < 32 {
+		fail("jwt.secret_key", fmt.Errorf("JWT secret key must be at least 32 characters"))
 	}
 
 	if c.Cloud.Provider == "" {
-		return fmt.Errorf("cloud provider is required")
+		fail("cloud.provider", fmt.Errorf("cloud provider is required"))
 	}
 
 	if c.Cloud.Region == "" {
-		return fmt.Errorf("cloud region is required")
+		fail("cloud.region", fmt.Errorf("cloud region is required"))
+	}
+
+	switch c.Analytics.Store {
+	case "", "file", "postgres":
+	default:
+		fail("analytics.store", fmt.Errorf("analytics store must be 'file' or 'postgres'"))
+	}
+
+	fail("chaos", c.Chaos.Validate(c.Server.Mode))
+
+	for _, pattern := range c.Cloud.Protection.ResourceIDs {
+		if _, err := path.Match(pattern, ""); err != nil {
+			fail("cloud.protection.resource_ids", fmt.Errorf("invalid protected resource pattern %q: %w", pattern, err))
+		}
+	}
+
+	for _, pattern := range c.Cloud.Regions.Allow {
+		if _, err := path.Match(pattern, ""); err != nil {
+			fail("cloud.regions.allow", fmt.Errorf("invalid region pattern %q: %w", pattern, err))
+		}
+	}
+	for _, pattern := range c.Cloud.Regions.Deny {
+		if _, err := path.Match(pattern, ""); err != nil {
+			fail("cloud.regions.deny", fmt.Errorf("invalid region pattern %q: %w", pattern, err))
+		}
+	}
+
+	if c.Server.MaxGoroutines < 0 {
+		fail("server.max_goroutines", fmt.Errorf("server.max_goroutines must not be negative"))
 	}
 
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			fail("server.trusted_proxies", fmt.Errorf("invalid trusted proxy %q: want an IP or CIDR", proxy))
+		}
+	}
+	if c.Server.RateLimit.Requests <= 0 {
+		fail("server.rate_limit.requests", fmt.Errorf("server.rate_limit.requests must be positive"))
+	}
+	if c.Server.RateLimit.Window <= 0 {
+		fail("server.rate_limit.window", fmt.Errorf("server.rate_limit.window must be positive"))
+	}
+
+	fail("retention", c.Retention.Validate())
+	fail("events", c.Events.Validate())
+	fail("reports", c.Reports.Validate(c.Email))
+	fail("cloud.metrics", c.Cloud.Metrics.Validate())
+	fail("analysis.vector_weights", c.Analysis.VectorWeights.Validate())
+
+	actions := make([]string, 0, len(c.Cloud.SavingsRatios))
+	for action := range c.Cloud.SavingsRatios {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		if ratio := c.Cloud.SavingsRatios[action]; ratio < 0 || ratio > 1 {
+			fail("cloud.savings_ratios."+action, fmt.Errorf("cloud savings ratio for %q must be between 0 and 1", action))
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
 	return nil
 }
 
-// Load reads configuration from a YAML file and overrides with environment variables.
-func Load(path string) (*Config, error) {
-	cfg := &Config{
-		// Set production-safe defaults
+// Defaults returns the documented production-safe defaults that Load
+// starts from before applying the YAML file and environment
+func Defaults() *Config {
+	return &Config{
 		Server: ServerConfig{
-			Port:         "8080",
-			Mode:         "production",
-			ReadTimeout:  30 * time.Second,
-			WriteTimeout: 30 * time.Second,
-			IdleTimeout:  120 * time.Second,
+			Port:          "8080",
+			Mode:          "production",
+			ReadTimeout:   30 * time.Second,
+			WriteTimeout:  30 * time.Second,
+			IdleTimeout:   120 * time.Second,
+			MaxGoroutines: 1000,
+			RateLimit:     RateLimitConfig{Requests: 100, Window: time.Hour},
 		},
 		Cloud: CloudConfig{
 			Provider:             "aws",
@@ -149,6 +636,16 @@ func Load(path string) (*Config, error) {
 			RetryAttempts:        3,
 			RetryDelay:           1 * time.Second,
 			ResourceTypes:        []string{"ec2", "rds", "lambda", "ebs"},
+			Termination: TerminationConfig{
+				MinIdle:          7 * 24 * time.Hour,
+				IdleCPUThreshold: 5,
+				BackupTag:        "talos:last-backup",
+				MaxBackupAge:     7 * 24 * time.Hour,
+				SoftTerminate:    true,
+				GracePeriod:      72 * time.Hour,
+			},
+			Idle:    IdleConfig{MinIdle: 7 * 24 * time.Hour},
+			Metrics: MetricsConfig{Mode: "merge", Timeout: 10 * time.Second},
 		},
 		Redis: RedisConfig{
 			Address:      "localhost:6379",
@@ -159,12 +656,42 @@ func Load(path string) (*Config, error) {
 			DialTimeout:  5 * time.Second,
 			ReadTimeout:  3 * time.Second,
 			WriteTimeout: 3 * time.Second,
+
+			BreakerThreshold:  5,
+			BreakerCooldown:   30 * time.Second,
+			FallbackQueueSize: 1000,
 		},
 		Database:  DatabaseConfig{DSN: "host=localhost user=atlas dbname=atlas sslmode=disable"},
+		JWT:       JWTConfig{TokenDuration: 24 * time.Hour, RefreshDuration: 7 * 24 * time.Hour},
 		Analytics: AnalyticsConfig{PersistPath: "./talos_tracker_state.json"},
+		Retention: RetentionConfig{
+			Enabled:            true,
+			Interval:           6 * time.Hour,
+			BatchSize:          1000,
+			TokenUsage:         90 * 24 * time.Hour,
+			AIDecisions:        180 * 24 * time.Hour,
+			AuditLog:           7 * 365 * 24 * time.Hour,
+			SavingsEvents:      2 * 365 * 24 * time.Hour,
+			InventorySnapshots: 90 * 24 * time.Hour,
+			EventOutbox:        30 * 24 * time.Hour,
+			LedgerActions:      90 * 24 * time.Hour,
+		},
+		Events: EventsConfig{
+			PollInterval: 5 * time.Second,
+			BatchSize:    100,
+			Timeout:      10 * time.Second,
+			MaxBackoff:   5 * time.Minute,
+		},
+		Email: EmailConfig{SMTPPort: 587},
+		Reports: ReportsConfig{
+			RetryInterval: 15 * time.Minute,
+			MaxAttempts:   3,
+		},
 		AI: AIConfig{
 			CacheEnabled:         true,
 			MaxTokensPerRequest:  4000,
+			MaxPromptTokens:      3000,
+			SchemaRetries:        2,
 			MaxRequestsPerMinute: 60,
 			Timeout:              30 * time.Second,
 		},
@@ -175,51 +702,78 @@ func Load(path string) (*Config, error) {
 			Oracle:     "gpt-4o",
 		},
 	}
+}
 
-	f, err := os.Open(path)
-	if err != nil {
-		return cfg, err
-	}
-	defer f.Close()
+// Load is the single entry point for configuration used by every binary. It
+// applies, in order of increasing precedence: Defaults, the YAML file at path
+// (with ${VAR} references expanded from the environment), and the
+// environment variables listed in applyEnv; then it validates the result. An
+// empty path configures from defaults and the environment alone.
+func Load(path string) (*Config, error) {
+	return LoadWithSecrets(path, nil)
+}
 
-	decoder := yaml.NewDecoder(f)
-	if err := decoder.Decode(cfg); err != nil {
-		return cfg, err
-	}
+// LoadWithSecrets is Load for deployments that keep credentials in a secret
+// store. Unless secrets.source names the environment, open is called with
+// the secrets settings and every credential the file and environment left
+// unset is read from the store it returns, under the name of its environment
+// variable. Load, which passes a nil open, never reads a store.
+func LoadWithSecrets(path string, open func(SecretsConfig) (SecretLookup, error)) (*Config, error) {
+	cfg := Defaults()
 
-	// Override with environment variables for container-friendly deployment
-	if port := os.Getenv("PORT"); port != "" {
-		cfg.Server.Port = port
-	}
-	if mode := os.Getenv("MODE"); mode != "" {
-		cfg.Server.Mode = mode
-	}
-	if region := os.Getenv("AWS_REGION"); region != "" {
-		cfg.Cloud.Region = region
-	}
-	if openRouterKey := os.Getenv("OPENROUTER_API_KEY"); openRouterKey != "" {
-		cfg.AI.OpenRouterKey = openRouterKey
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
 	}
-	if devinKey := os.Getenv("DEVIN_API_KEY"); devinKey != "" {
-		cfg.AI.DevinKey = devinKey
-	}
-	if redisAddr := os.Getenv("REDIS_ADDR"); redisAddr != "" {
-		cfg.Redis.Address = redisAddr
-	}
-	if redisPassword := os.Getenv("REDIS_PASSWORD"); redisPassword != "" {
-		cfg.Redis.Password = redisPassword
-	}
-	if dbDsn := os.Getenv("DATABASE_DSN"); dbDsn != "" {
-		cfg.Database.DSN = dbDsn
+
+	// Environment variables win over the file for container-friendly deployment
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
 	}
-	if jwtSecret := os.Getenv("JWT_SECRET_KEY"); jwtSecret != "" {
-		cfg.JWT.SecretKey = jwtSecret
+
+	if open != nil && !cfg.Secrets.usesEnvironment() {
+		lookup, err := open(cfg.Secrets)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open secrets source %s: %w", cfg.Secrets.Source, err)
+		}
+		if err := applySecrets(cfg, lookup); err != nil {
+			return nil, err
+		}
 	}
 
-	// Validate configuration after loading
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("configuration validation failed: %w", err)
 	}
 
 	return cfg, nil
 }
+
+// UnknownKeys describes every key in the YAML file at path that no setting
+// reads, such as a misspelled one, with its line. Load ignores these keys.
+// Errors that would make Load fail are left for Load to report.
+func UnknownKeys(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(os.ExpandEnv(string(data))))
+	decoder.KnownFields(true)
+	var typeErr *yaml.TypeError
+	if err := decoder.Decode(Defaults()); !errors.As(err, &typeErr) {
+		return nil, nil
+	}
+
+	var unknown []string
+	for _, message := range typeErr.Errors {
+		if strings.Contains(message, " not found in type ") {
+			unknown = append(unknown, message)
+		}
+	}
+	return unknown, nil
+}
@@ -0,0 +1,222 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+	return path
+}
+
+// setJWTSecret satisfies the JWT secret validation, which has no default
+func setJWTSecret(t *testing.T) {
+	t.Helper()
+	t.Setenv("JWT_SECRET_KEY", "0123456789abcdef0123456789abcdef")
+}
+
+func TestLoad_DefaultsAndEnvOnly(t *testing.T) {
+	setJWTSecret(t)
+	t.Setenv("OPENROUTER_API_KEY", "or-key")
+	t.Setenv("PORT", "9000")
+
+	cfg, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, "9000", cfg.Server.Port)
+	assert.Equal(t, "or-key", cfg.AI.OpenRouterKey)
+	assert.Equal(t, "us-east-1", cfg.Cloud.Region)
+	assert.Equal(t, 24*time.Hour, cfg.JWT.TokenDuration)
+}
+
+func TestLoad_EnvOverridesFile(t *testing.T) {
+	setJWTSecret(t)
+	t.Setenv("FILE_KEY", "from-file")
+	t.Setenv("CLAUDE_API_KEY", "claude-env")
+	t.Setenv("CLOUD_REGION", "eu-west-1")
+	t.Setenv("AWS_REGION", "us-west-2")
+
+	path := writeConfig(t, `
+ai:
+  openrouter_key: "${FILE_KEY}"
+  claude_api_key: "claude-file"
+cloud:
+  region: "ap-south-1"
+redis:
+  cache_ttl: 1m
+`)
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "from-file", cfg.AI.OpenRouterKey)
+	assert.Equal(t, "claude-env", cfg.AI.ClaudeAPIKey)
+	assert.Equal(t, "us-west-2", cfg.Cloud.Region, "the first alias set wins")
+	assert.Equal(t, time.Minute, cfg.Redis.CacheTTL)
+	assert.Equal(t, 10, cfg.Redis.PoolSize, "unset fields keep their defaults")
+}
+
+func TestLoad_ProviderKeyWithoutOpenRouter(t *testing.T) {
+	setJWTSecret(t)
+	t.Setenv("GEMINI_API_KEY", "gemini")
+
+	cfg, err := Load("")
+	require.NoError(t, err)
+	assert.Empty(t, cfg.AI.OpenRouterKey)
+	assert.Equal(t, "gemini", cfg.AI.GeminiAPIKey)
+}
+
+func TestLoad_RequiresAIKeyUnlessMock(t *testing.T) {
+	setJWTSecret(t)
+	_, err := Load("")
+	assert.Error(t, err)
+
+	t.Setenv("AI_MOCK", "true")
+	_, err = Load("")
+	assert.NoError(t, err)
+}
+
+func TestLoad_InvalidEnvValue(t *testing.T) {
+	setJWTSecret(t)
+	t.Setenv("AI_MOCK", "true")
+	t.Setenv("REDIS_DB", "one")
+
+	_, err := Load("")
+	assert.ErrorContains(t, err, "REDIS_DB")
+}
+
+func TestLoad_VectorWeights(t *testing.T) {
+	setJWTSecret(t)
+	t.Setenv("AI_MOCK", "true")
+
+	cfg, err := Load(writeConfig(t, `
+analysis:
+  vector_weights:
+    rightsizing: 0.1
+    spot_arbitrage: 0.1
+    scheduling: 0.5
+    cost_patterns: 0.3
+`))
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, cfg.Analysis.VectorWeights.Scheduling)
+
+	_, err = Load(writeConfig(t, `
+analysis:
+  vector_weights:
+    rightsizing: 0.5
+    scheduling: 0.8
+`))
+	assert.ErrorContains(t, err, "sum to 1")
+}
+
+func TestLoad_RateLimitAndTrustedProxies(t *testing.T) {
+	setJWTSecret(t)
+	t.Setenv("AI_MOCK", "true")
+
+	cfg, err := Load("")
+	require.NoError(t, err)
+	assert.Equal(t, RateLimitConfig{Requests: 100, Window: time.Hour}, cfg.Server.RateLimit)
+	assert.Empty(t, cfg.Server.TrustedProxies, "no forwarded headers are trusted by default")
+
+	cfg, err = Load(writeConfig(t, `
+server:
+  trusted_proxies: ["10.0.0.0/8", "192.0.2.7", "2001:db8::/32"]
+  rate_limit:
+    requests: 600
+    window: 1m
+`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8", "192.0.2.7", "2001:db8::/32"}, cfg.Server.TrustedProxies)
+	assert.Equal(t, RateLimitConfig{Requests: 600, Window: time.Minute}, cfg.Server.RateLimit)
+
+	_, err = Load(writeConfig(t, `
+server:
+  trusted_proxies: ["lb.internal"]
+  rate_limit:
+    requests: 0
+`))
+	var validation *ValidationError
+	require.ErrorAs(t, err, &validation)
+	fields := make([]string, len(validation.Problems))
+	for i, problem := range validation.Problems {
+		fields[i] = problem.Field
+	}
+	assert.Equal(t, []string{"server.trusted_proxies", "server.rate_limit.requests"}, fields)
+}
+
+func TestLoad_ReportsEveryProblem(t *testing.T) {
+	_, err := Load(writeConfig(t, `
+server:
+  mode: staging
+cloud:
+  region: ""
+  savings_ratios:
+    stop: 1.5
+`))
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "server mode must be", "the message is the first problem's")
+
+	var validation *ValidationError
+	require.ErrorAs(t, err, &validation)
+	fields := make([]string, len(validation.Problems))
+	for i, problem := range validation.Problems {
+		fields[i] = problem.Field
+	}
+	assert.Equal(t, []string{"server.mode", "ai", "jwt.secret_key", "cloud.region", "cloud.savings_ratios.stop"}, fields)
+}
+
+func TestUnknownKeys(t *testing.T) {
+	unknown, err := UnknownKeys(writeConfig(t, `
+server:
+  port: "8080"
+  prot: "9090"
+redis:
+  adress: localhost
+`))
+	require.NoError(t, err)
+	require.Len(t, unknown, 2)
+	assert.Contains(t, unknown[0], "line 4: field prot")
+	assert.Contains(t, unknown[1], "line 6: field adress")
+
+	unknown, err = UnknownKeys(writeConfig(t, "server:\n  port: \"8080\"\n"))
+	require.NoError(t, err)
+	assert.Empty(t, unknown)
+}
+
+func TestLoadWithSecrets(t *testing.T) {
+	t.Setenv("AI_MOCK", "true")
+	t.Setenv("SECRETS_SOURCE", "vault://kv/talos")
+	t.Setenv("REDIS_PASSWORD", "redis-env")
+
+	stored := map[string]string{
+		"JWT_SECRET_KEY": "0123456789abcdef0123456789abcdef",
+		"REDIS_PASSWORD": "redis-vault",
+	}
+	var opened SecretsConfig
+	open := func(s SecretsConfig) (SecretLookup, error) {
+		opened = s
+		return func(key string) (string, error) { return stored[key], nil }, nil
+	}
+
+	cfg, err := LoadWithSecrets("", open)
+	require.NoError(t, err)
+	assert.Equal(t, "vault://kv/talos", opened.Source)
+	assert.Equal(t, "0123456789abcdef0123456789abcdef", cfg.JWT.SecretKey)
+	assert.Equal(t, "redis-env", cfg.Redis.Password, "the environment wins over the store")
+
+	_, err = Load("")
+	assert.ErrorContains(t, err, "JWT", "Load never reads the store")
+
+	t.Setenv("SECRETS_SOURCE", "env://")
+	_, err = LoadWithSecrets("", func(SecretsConfig) (SecretLookup, error) {
+		t.Fatal("the environment source needs no store")
+		return nil, nil
+	})
+	assert.Error(t, err)
+}
@@ -4,133 +4,180 @@ import (
 	"fmt"
 	"os"
 	"strconv"
-	"strings"
 	"time"
 )
 
-// EnvironmentConfig loads configuration from environment variables
-type EnvironmentConfig struct {
-	*Config
+// applyEnv overlays environment variables onto cfg, 12-factor style. Where a
+// setting has several names the first one set wins; the later names are
+// older spellings kept so existing deployments continue to work. A value
+// that cannot be parsed is an error rather than silently ignored.
+func applyEnv(cfg *Config) error {
+	env := envOverlay{}
+
+	env.setString(&cfg.Server.Port, "PORT")
+	env.setString(&cfg.Server.Mode, "MODE")
+
+	env.setString(&cfg.AI.OpenRouterKey, "OPENROUTER_API_KEY")
+	env.setString(&cfg.AI.GeminiAPIKey, "GEMINI_API_KEY")
+	env.setString(&cfg.AI.ClaudeAPIKey, "CLAUDE_API_KEY")
+	env.setString(&cfg.AI.GPT5MiniAPIKey, "GPT5_MINI_API_KEY", "GPT5MINI_API_KEY", "GPT5_API_KEY")
+	env.setString(&cfg.AI.DevinKey, "DEVIN_API_KEY")
+	env.setBool(&cfg.AI.CacheEnabled, "AI_CACHE_ENABLED")
+	env.setBool(&cfg.AI.Mock, "AI_MOCK")
+
+	env.setString(&cfg.AITiers.Sentinel, "AI_TIER_SENTINEL")
+	env.setString(&cfg.AITiers.Strategist, "AI_TIER_STRATEGIST")
+	env.setString(&cfg.AITiers.Arbiter, "AI_TIER_ARBITER")
+	env.setString(&cfg.AITiers.Oracle, "AI_TIER_ORACLE")
+
+	env.setString(&cfg.Redis.Address, "REDIS_ADDR", "REDIS_ADDRESS")
+	env.setString(&cfg.Redis.Password, "REDIS_PASSWORD")
+	env.setInt(&cfg.Redis.DB, "REDIS_DB")
+	env.setDuration(&cfg.Redis.CacheTTL, "REDIS_CACHE_TTL")
+
+	env.setString(&cfg.Database.DSN, "DATABASE_DSN", "DATABASE_URL")
+	env.setInt(&cfg.Database.MaxConns, "DATABASE_MAX_CONNS")
+	env.setInt(&cfg.Database.MinConns, "DATABASE_MIN_CONNS")
+	env.setDuration(&cfg.Database.MaxConnLifetime, "DATABASE_MAX_CONN_LIFETIME")
+	env.setDuration(&cfg.Database.MaxConnIdleTime, "DATABASE_MAX_CONN_IDLE_TIME")
+
+	env.setString(&cfg.Secrets.Source, "SECRETS_SOURCE")
+	env.setDuration(&cfg.Secrets.RefreshInterval, "SECRETS_REFRESH_INTERVAL")
+
+	env.setString(&cfg.Cloud.Provider, "CLOUD_PROVIDER")
+	env.setString(&cfg.Cloud.Region, "AWS_REGION", "CLOUD_REGION")
+	env.setBool(&cfg.Cloud.DryRun, "CLOUD_DRY_RUN")
+	env.setString(&cfg.Cloud.Metrics.Datadog.APIKey, "DATADOG_API_KEY")
+	env.setString(&cfg.Cloud.Metrics.Datadog.AppKey, "DATADOG_APP_KEY")
+
+	env.setString(&cfg.Analytics.PersistPath, "ANALYTICS_PATH")
+	env.setString(&cfg.Analytics.Store, "ANALYTICS_STORE")
+
+	env.setString(&cfg.Events.URL, "EVENT_STREAM_URL")
+	env.setString(&cfg.Events.Secret, "EVENT_STREAM_SECRET")
+
+	env.setString(&cfg.Email.SMTPHost, "SMTP_HOST")
+	env.setInt(&cfg.Email.SMTPPort, "SMTP_PORT")
+	env.setString(&cfg.Email.Username, "SMTP_USERNAME")
+	env.setString(&cfg.Email.Password, "SMTP_PASSWORD")
+	env.setString(&cfg.Email.From, "SMTP_FROM")
+
+	env.setString(&cfg.JWT.SecretKey, "JWT_SECRET_KEY", "JWT_SECRET")
+	env.setDuration(&cfg.JWT.TokenDuration, "JWT_TOKEN_DURATION", "JWT_EXPIRATION")
+	env.setDuration(&cfg.JWT.RefreshDuration, "JWT_REFRESH_DURATION")
+
+	env.setString(&cfg.SSO.Google.ClientID, "GOOGLE_CLIENT_ID")
+	env.setString(&cfg.SSO.Google.ClientSecret, "GOOGLE_CLIENT_SECRET")
+	env.setString(&cfg.SSO.Okta.ClientID, "OKTA_CLIENT_ID")
+	env.setString(&cfg.SSO.Okta.ClientSecret, "OKTA_CLIENT_SECRET")
+	env.setString(&cfg.SSO.Okta.Domain, "OKTA_DOMAIN")
+	env.setString(&cfg.SSO.Azure.ClientID, "AZURE_CLIENT_ID")
+	env.setString(&cfg.SSO.Azure.ClientSecret, "AZURE_CLIENT_SECRET")
+	env.setString(&cfg.SSO.Azure.TenantID, "AZURE_TENANT_ID")
+	env.setString(&cfg.SSO.GitHub.ClientID, "GITHUB_CLIENT_ID")
+	env.setString(&cfg.SSO.GitHub.ClientSecret, "GITHUB_CLIENT_SECRET")
+
+	return env.err
 }
 
-// NewEnvironmentConfig creates a new environment-based configuration
-func NewEnvironmentConfig() (*EnvironmentConfig, error) {
-	cfg := &Config{
-		Server: ServerConfig{
-			Port: getEnvOrDefault("PORT", "8080"),
-			Mode: getEnvOrDefault("MODE", "production"),
-		},
-		AI: AIConfig{
-			OpenRouterKey:  getEnvOrDefault("OPENROUTER_API_KEY", ""),
-			GeminiAPIKey:   getEnvOrDefault("GEMINI_API_KEY", ""),
-			ClaudeAPIKey:   getEnvOrDefault("CLAUDE_API_KEY", ""),
-			GPT5MiniAPIKey: getEnvOrDefault("GPT5MINI_API_KEY", ""),
-			DevinKey:       getEnvOrDefault("DEVIN_API_KEY", ""),
-			DevinsAPIKey:   getEnvOrDefault("DEVINS_API_KEY", ""),
-			CacheEnabled:   getEnvBoolOrDefault("AI_CACHE_ENABLED", true),
-		},
-		AITiers: AITiersConfig{
-			Sentinel:   getEnvOrDefault("AI_TIER_SENTINEL", "gemini-1.5-flash"),
-			Strategist: getEnvOrDefault("AI_TIER_STRATEGIST", "gemini-1.5-pro"),
-			Arbiter:    getEnvOrDefault("AI_TIER_ARBITER", "claude-3-5-sonnet-20240620"),
-			Oracle:     getEnvOrDefault("AI_TIER_ORACLE", "gpt-4o"),
-		},
-		Redis: RedisConfig{
-			Address:  getEnvOrDefault("REDIS_ADDRESS", "localhost:6379"),
-			Password: getEnvOrDefault("REDIS_PASSWORD", ""),
-			DB:       getEnvIntOrDefault("REDIS_DB", 0),
-			CacheTTL: getEnvDurationOrDefault("REDIS_CACHE_TTL", 5*time.Minute),
-		},
-		Database: DatabaseConfig{
-			DSN: getEnvOrDefault("DATABASE_DSN", fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-				getEnvOrDefault("DB_HOST", "localhost"),
-				getEnvOrDefault("DB_PORT", "5432"),
-				getEnvOrDefault("DB_USER", "postgres"),
-				getEnvOrDefault("DB_PASSWORD", ""),
-				getEnvOrDefault("DB_NAME", "talos"))),
-		},
-		Cloud: CloudConfig{
-			Provider: getEnvOrDefault("CLOUD_PROVIDER", "aws"),
-			Region:   getEnvOrDefault("CLOUD_REGION", "us-east-1"),
-			DryRun:   getEnvBoolOrDefault("CLOUD_DRY_RUN", false),
-		},
-		Analytics: AnalyticsConfig{
-			PersistPath: getEnvOrDefault("ANALYTICS_PATH", "./data/analytics"),
-		},
-		JWT: JWTConfig{
-			SecretKey:     getEnvOrDefault("JWT_SECRET", "your-secret-key"),
-			TokenDuration: getEnvDurationOrDefault("JWT_EXPIRATION", 24*time.Hour),
-		},
-		SSO: SSOConfig{
-			Google: SSOProviderConfig{
-				ClientID:     getEnvOrDefault("GOOGLE_CLIENT_ID", ""),
-				ClientSecret: getEnvOrDefault("GOOGLE_CLIENT_SECRET", ""),
-			},
-			Okta: SSOProviderConfig{
-				ClientID:     getEnvOrDefault("OKTA_CLIENT_ID", ""),
-				ClientSecret: getEnvOrDefault("OKTA_CLIENT_SECRET", ""),
-				Domain:       getEnvOrDefault("OKTA_DOMAIN", ""),
-			},
-			Azure: SSOProviderConfig{
-				ClientID:     getEnvOrDefault("AZURE_CLIENT_ID", ""),
-				ClientSecret: getEnvOrDefault("AZURE_CLIENT_SECRET", ""),
-				TenantID:     getEnvOrDefault("AZURE_TENANT_ID", ""),
-			},
-		},
-	}
-
-	return &EnvironmentConfig{Config: cfg}, nil
-}
-
-// Validate validates the configuration
-func (ec *EnvironmentConfig) Validate() error {
-	// Validate AI API keys
-	if ec.AI.OpenRouterKey == "" && ec.AI.GeminiAPIKey == "" && ec.AI.ClaudeAPIKey == "" && ec.AI.GPT5MiniAPIKey == "" {
-		return fmt.Errorf("at least one AI API key must be provided")
+// applySecrets fills the credentials still unset after the file and the
+// environment from a secret store, each read under its environment variable
+func applySecrets(cfg *Config, lookup SecretLookup) error {
+	credentials := []struct {
+		dst *string
+		key string
+	}{
+		{&cfg.AI.OpenRouterKey, "OPENROUTER_API_KEY"},
+		{&cfg.AI.GeminiAPIKey, "GEMINI_API_KEY"},
+		{&cfg.AI.ClaudeAPIKey, "CLAUDE_API_KEY"},
+		{&cfg.AI.GPT5MiniAPIKey, "GPT5_MINI_API_KEY"},
+		{&cfg.AI.DevinKey, "DEVIN_API_KEY"},
+		{&cfg.Redis.Password, "REDIS_PASSWORD"},
+		{&cfg.Database.DSN, "DATABASE_DSN"},
+		{&cfg.Cloud.Metrics.Datadog.APIKey, "DATADOG_API_KEY"},
+		{&cfg.Cloud.Metrics.Datadog.AppKey, "DATADOG_APP_KEY"},
+		{&cfg.Events.Secret, "EVENT_STREAM_SECRET"},
+		{&cfg.Email.Password, "SMTP_PASSWORD"},
+		{&cfg.JWT.SecretKey, "JWT_SECRET_KEY"},
+		{&cfg.SSO.Google.ClientSecret, "GOOGLE_CLIENT_SECRET"},
+		{&cfg.SSO.Okta.ClientSecret, "OKTA_CLIENT_SECRET"},
+		{&cfg.SSO.Azure.ClientSecret, "AZURE_CLIENT_SECRET"},
+		{&cfg.SSO.GitHub.ClientSecret, "GITHUB_CLIENT_SECRET"},
 	}
-
-	// In production mode, require valid API keys
-	if ec.Server.Mode == "production" {
-		if ec.AI.GeminiAPIKey == "" || len(ec.AI.GeminiAPIKey) < 20 {
-			return fmt.Errorf("valid Gemini API key required in production mode")
+	for _, credential := range credentials {
+		if *credential.dst != "" {
+			continue
 		}
-		if ec.AI.ClaudeAPIKey == "" || len(ec.AI.ClaudeAPIKey) < 20 {
-			return fmt.Errorf("valid Claude API key required in production mode")
+		value, err := lookup(credential.key)
+		if err != nil {
+			return fmt.Errorf("failed to read secret %s: %w", credential.key, err)
 		}
+		*credential.dst = value
 	}
+	return nil
+}
 
-	if ec.JWT.SecretKey == "your-secret-key" || ec.JWT.SecretKey == "demo-jwt-secret-change-in-production" {
-		return fmt.Errorf("JWT secret must be changed in production")
-	}
+// envOverlay applies environment variables and keeps the first parse error
+type envOverlay struct {
+	err error
+}
 
-	if len(ec.JWT.SecretKey) < 32 {
-		return fmt.Errorf("JWT secret must be at least 32 characters long")
+// lookup returns the first of keys that is set to a non-empty value
+func (e *envOverlay) lookup(keys []string) (string, string, bool) {
+	for _, key := range keys {
+		if value := os.Getenv(key); value != "" {
+			return key, value, true
+		}
 	}
+	return "", "", false
+}
 
-	if ec.Cloud.Provider != "aws" && ec.Cloud.Provider != "azure" && ec.Cloud.Provider != "gcp" {
-		return fmt.Errorf("unsupported cloud provider: %s", ec.Cloud.Provider)
+func (e *envOverlay) fail(key, value string, err error) {
+	if e.err == nil {
+		e.err = fmt.Errorf("invalid value %q for %s: %w", value, key, err)
 	}
-
-	return nil
 }
 
-// GetDatabaseDSN returns the database connection string
-func (ec *EnvironmentConfig) GetDatabaseDSN() string {
-	return ec.Database.DSN
+func (e *envOverlay) setString(dst *string, keys ...string) {
+	if _, value, ok := e.lookup(keys); ok {
+		*dst = value
+	}
 }
 
-// IsProduction returns true if running in production mode
-func (ec *EnvironmentConfig) IsProduction() bool {
-	return ec.Server.Mode == "production"
+func (e *envOverlay) setBool(dst *bool, keys ...string) {
+	if key, value, ok := e.lookup(keys); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			e.fail(key, value, err)
+			return
+		}
+		*dst = parsed
+	}
 }
 
-// IsDevelopment returns true if running in development mode
-func (ec *EnvironmentConfig) IsDevelopment() bool {
-	return ec.Server.Mode == "development"
+func (e *envOverlay) setInt(dst *int, keys ...string) {
+	if key, value, ok := e.lookup(keys); ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			e.fail(key, value, err)
+			return
+		}
+		*dst = parsed
+	}
 }
 
-// Helper functions
+func (e *envOverlay) setDuration(dst *time.Duration, keys ...string) {
+	if key, value, ok := e.lookup(keys); ok {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			e.fail(key, value, err)
+			return
+		}
+		*dst = parsed
+	}
+}
 
+// getEnvOrDefault returns the environment variable key, or defaultValue if unset
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
@@ -138,6 +185,8 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvBoolOrDefault returns the environment variable key parsed as a bool,
+// or defaultValue if unset or invalid
 func getEnvBoolOrDefault(key string, defaultValue bool) bool {
 	if value := os.Getenv(key); value != "" {
 		if parsed, err := strconv.ParseBool(value); err == nil {
@@ -147,15 +196,8 @@ func getEnvBoolOrDefault(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
-func getEnvIntOrDefault(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if parsed, err := strconv.Atoi(value); err == nil {
-			return parsed
-		}
-	}
-	return defaultValue
-}
-
+// getEnvDurationOrDefault returns the environment variable key parsed as a
+// duration, or defaultValue if unset or invalid
 func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if parsed, err := time.ParseDuration(value); err == nil {
@@ -165,61 +207,13 @@ func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Durati
 	return defaultValue
 }
 
-// GetCloudProviderConfig returns provider-specific configuration
-func (ec *EnvironmentConfig) GetCloudProviderConfig() (interface{}, error) {
-	switch strings.ToLower(ec.Cloud.Provider) {
-	case "aws":
-		return map[string]string{
-			"region":            ec.Cloud.Region,
-			"access_key_id":     getEnvOrDefault("AWS_ACCESS_KEY_ID", ""),
-			"secret_access_key": getEnvOrDefault("AWS_SECRET_ACCESS_KEY", ""),
-			"session_token":     getEnvOrDefault("AWS_SESSION_TOKEN", ""),
-		}, nil
-	case "azure":
-		return map[string]string{
-			"subscription_id": getEnvOrDefault("AZURE_SUBSCRIPTION_ID", ""),
-			"tenant_id":       getEnvOrDefault("AZURE_TENANT_ID", ""),
-			"client_id":       getEnvOrDefault("AZURE_CLIENT_ID", ""),
-			"client_secret":   getEnvOrDefault("AZURE_CLIENT_SECRET", ""),
-		}, nil
-	case "gcp":
-		return map[string]string{
-			"project_id":       getEnvOrDefault("GCP_PROJECT_ID", ""),
-			"credentials_file": getEnvOrDefault("GCP_CREDENTIALS_FILE", ""),
-		}, nil
-	default:
-		return nil, fmt.Errorf("unsupported cloud provider: %s", ec.Cloud.Provider)
-	}
-}
-
-// GetAIServiceConfig returns AI service configuration
-func (ec *EnvironmentConfig) GetAIServiceConfig() map[string]interface{} {
-	config := make(map[string]interface{})
-
-	if ec.AI.OpenRouterKey != "" {
-		config["openrouter"] = map[string]string{
-			"api_key":  ec.AI.OpenRouterKey,
-			"base_url": getEnvOrDefault("OPENROUTER_BASE_URL", "https://openrouter.ai/api/v1"),
-		}
-	}
-
-	if ec.AI.GeminiAPIKey != "" {
-		config["gemini"] = map[string]string{
-			"api_key": ec.AI.GeminiAPIKey,
-		}
-	}
-
-	if ec.AI.ClaudeAPIKey != "" {
-		config["claude"] = map[string]string{
-			"api_key": ec.AI.ClaudeAPIKey,
-		}
-	}
-
-	return config
+// IsProduction returns true if running in production mode
+func (c *Config) IsProduction() bool {
+	return c.Server.Mode == "production"
 }
 
 // GetMonitoringConfig returns monitoring and observability configuration
-func (ec *EnvironmentConfig) GetMonitoringConfig() map[string]interface{} {
+func (c *Config) GetMonitoringConfig() map[string]interface{} {
 	return map[string]interface{}{
 		"prometheus": map[string]interface{}{
 			"enabled": getEnvBoolOrDefault("PROMETHEUS_ENABLED", true),
@@ -0,0 +1,320 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Action approval states. Approved actions become PENDING, with "approved"
+// set in their payload, and are picked up by the engine's act phase; rejected
+// actions are never executed.
+const (
+	ActionStatusAwaitingApproval = "AWAITING_APPROVAL"
+	ActionStatusPending          = "PENDING"
+	ActionStatusRejected         = "REJECTED"
+)
+
+// ApprovalStore is the human-approval queue. The engine files actions that
+// need a human decision with CreatePendingApproval; they are not executed
+// until Approve moves them to PENDING, and Reject keeps them from ever running.
+type ApprovalStore interface {
+	CreatePendingApproval(ctx context.Context, orgID string, action *Action) error
+	ListPending(ctx context.Context, orgID string, filter ActionFilter) ([]*Action, error)
+	Approve(ctx context.Context, orgID, id string, actor *AuditLog) error
+	Reject(ctx context.Context, orgID, id string, actor *AuditLog) error
+}
+
+var _ ApprovalStore = (*Repository)(nil)
+
+// ActionFilter selects actions awaiting approval. Unset fields match
+// everything; all set fields must match.
+type ActionFilter struct {
+	ActionType  string   `json:"action_type,omitempty"`
+	Environment string   `json:"environment,omitempty"`  // resource "environment" or "env" tag
+	MaxSavings  *float64 `json:"max_savings,omitempty"`  // estimated_savings strictly below
+	MaxRisk     *float64 `json:"max_risk,omitempty"`     // risk_score strictly below
+	ResourceIDs []string `json:"resource_ids,omitempty"` // exact resource IDs
+}
+
+// where renders the filter as a SQL condition on the actions table aliased
+// "a", restricted to the organization's actions and numbering placeholders
+// after args
+func (f ActionFilter) where(orgID string, args []interface{}) (string, []interface{}) {
+	conditions := []string{"a.status = 'AWAITING_APPROVAL'"}
+	add := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, strings.ReplaceAll(format, "$?", fmt.Sprintf("$%d", len(args))))
+	}
+
+	add("a.org_id IS NOT DISTINCT FROM $?", orgParam(orgID))
+
+	if f.ActionType != "" {
+		add("a.action_type = $?", f.ActionType)
+	}
+	if f.Environment != "" {
+		add(`EXISTS (
+			SELECT 1 FROM resources res
+			WHERE res.cloud_resource_id = a.resource_id
+			  AND (res.tags->>'environment' = $? OR res.tags->>'env' = $?)
+		)`, f.Environment)
+	}
+	if f.MaxSavings != nil {
+		add("a.estimated_savings < $?", *f.MaxSavings)
+	}
+	if f.MaxRisk != nil {
+		add("a.risk_score < $?", *f.MaxRisk)
+	}
+	if len(f.ResourceIDs) > 0 {
+		add("a.resource_id = ANY($?)", f.ResourceIDs)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// CountAwaitingActions reports how many of an organization's actions
+// awaiting approval match the filter
+func (r *Repository) CountAwaitingActions(ctx context.Context, orgID string, filter ActionFilter) (int, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.count_awaiting_actions")
+	defer span.End()
+
+	where, args := filter.where(orgID, nil)
+	query := `SELECT COUNT(*) FROM actions a WHERE ` + where
+
+	var count int
+	if err := r.db.QueryRow(ctx, query, args...).Scan(&count); err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to count awaiting actions: %w", err)
+	}
+
+	return count, nil
+}
+
+// CreatePendingApproval stores an action awaiting approval
+func (r *Repository) CreatePendingApproval(ctx context.Context, orgID string, action *Action) error {
+	action.Status = ActionStatusAwaitingApproval
+	return r.CreateAction(ctx, orgID, action)
+}
+
+// ListPending retrieves an organization's actions awaiting approval that
+// match the filter, oldest first
+func (r *Repository) ListPending(ctx context.Context, orgID string, filter ActionFilter) ([]*Action, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.list_pending_approvals")
+	defer span.End()
+
+	where, args := filter.where(orgID, nil)
+	query := `SELECT ` + actionColumns + ` FROM actions a WHERE ` + where + `
+		ORDER BY a.created_at ASC, a.id::text ASC`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list actions awaiting approval: %w", err)
+	}
+	defer rows.Close()
+
+	actions := []*Action{}
+	for rows.Next() {
+		action, err := scanAction(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan action: %w", err)
+		}
+		actions = append(actions, action)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list actions awaiting approval: %w", err)
+	}
+
+	return actions, nil
+}
+
+// Approve moves an organization's action awaiting approval to PENDING so
+// the engine executes it
+func (r *Repository) Approve(ctx context.Context, orgID, id string, actor *AuditLog) error {
+	return r.ResolveAction(ctx, orgID, id, true, nil, actor)
+}
+
+// Reject keeps an organization's action awaiting approval from ever executing
+func (r *Repository) Reject(ctx context.Context, orgID, id string, actor *AuditLog) error {
+	return r.ResolveAction(ctx, orgID, id, false, nil, actor)
+}
+
+// ResolveAwaitingActions approves (moves to PENDING) or rejects every one of
+// an organization's actions awaiting approval that matches the filter. Each affected action gets its
+// own audit log entry and lifecycle event, written in the same statement,
+// attributed to actor's UserID and IPAddress. It returns the number of
+// actions affected.
+func (r *Repository) ResolveAwaitingActions(ctx context.Context, orgID string, filter ActionFilter, approve bool, actor *AuditLog) (int, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.resolve_awaiting_actions")
+	defer span.End()
+
+	status, auditAction, event := ActionStatusRejected, "action.reject", EventActionRejected
+	if approve {
+		status, auditAction, event = ActionStatusPending, "action.approve", EventActionApproved
+	}
+	if actor == nil {
+		actor = &AuditLog{}
+	}
+
+	args := []interface{}{status, actor.UserID, auditAction, filter, actor.IPAddress, event}
+	where, args := filter.where(orgID, args)
+
+	query := `
+		WITH resolved AS (
+			UPDATE actions a SET status = $1,
+				payload = CASE WHEN $1 = 'PENDING' THEN COALESCE(a.payload, '{}'::jsonb) || '{"approved": true}'::jsonb
+					ELSE a.payload END
+			WHERE ` + where + `
+			RETURNING a.id, a.resource_id, a.action_type, a.risk_score, a.estimated_savings
+		), audited AS (
+			INSERT INTO audit_log (user_id, action, resource_type, resource_id, details, ip_address)
+			SELECT $2, $3, 'action', id::text,
+				jsonb_build_object(
+					'resource_id', resource_id, 'action_type', action_type, 'risk_score', risk_score,
+					'estimated_savings', estimated_savings, 'bulk', true, 'filter', $4::jsonb
+				), $5
+			FROM resolved
+		)
+		INSERT INTO event_outbox (event_type, subject_id, data)
+		SELECT $6, id::text,
+			jsonb_build_object(
+				'action_id', id, 'resource_id', resource_id, 'action_type', action_type,
+				'user_id', $2::text, 'bulk', true
+			)
+		FROM resolved
+	`
+
+	tag, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to resolve awaiting actions: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// ResolveAction approves (moves to PENDING) or rejects a single one of an
+// organization's actions awaiting approval. A non-nil dryRunOverride is stored in the action payload
+// so the engine executes just this action with that dry-run setting; the
+// audit entry, attributed to actor, records whether an override was used.
+// The matching lifecycle event is written in the same statement.
+func (r *Repository) ResolveAction(ctx context.Context, orgID, id string, approve bool, dryRunOverride *bool, actor *AuditLog) error {
+	ctx, span := r.tracer.Start(ctx, "repository.resolve_action")
+	defer span.End()
+
+	status, auditAction, event := ActionStatusRejected, "action.reject", EventActionRejected
+	if approve {
+		status, auditAction, event = ActionStatusPending, "action.approve", EventActionApproved
+	}
+	if actor == nil {
+		actor = &AuditLog{}
+	}
+
+	query := `
+		WITH resolved AS (
+			UPDATE actions a SET status = $2,
+				payload = CASE WHEN $2 = 'PENDING' OR $3::boolean IS NOT NULL
+					THEN COALESCE(a.payload, '{}'::jsonb) || jsonb_strip_nulls(jsonb_build_object(
+						'dry_run_override', $3::boolean, 'approved', CASE WHEN $2 = 'PENDING' THEN true END))
+					ELSE a.payload END
+			WHERE a.id = $1 AND a.status = 'AWAITING_APPROVAL' AND a.org_id IS NOT DISTINCT FROM $8
+			RETURNING a.id, a.resource_id, a.action_type, a.risk_score, a.estimated_savings
+		), audited AS (
+			INSERT INTO audit_log (user_id, action, resource_type, resource_id, details, ip_address)
+			SELECT $4, $5, 'action', id::text,
+				jsonb_build_object(
+					'resource_id', resource_id, 'action_type', action_type, 'risk_score', risk_score,
+					'estimated_savings', estimated_savings, 'bulk', false,
+					'dry_run_override_used', $3::boolean IS NOT NULL, 'dry_run_override', $3::boolean
+				), $6
+			FROM resolved
+		)
+		INSERT INTO event_outbox (event_type, subject_id, data)
+		SELECT $7, id::text,
+			jsonb_build_object(
+				'action_id', id, 'resource_id', resource_id, 'action_type', action_type,
+				'user_id', $4::text, 'bulk', false, 'dry_run_override', $3::boolean
+			)
+		FROM resolved
+	`
+
+	tag, err := r.db.Exec(ctx, query, id, status, dryRunOverride, actor.UserID, auditAction, actor.IPAddress, event, orgParam(orgID))
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to resolve action: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("action %s awaiting approval: %w", id, ErrNotFound)
+	}
+
+	return nil
+}
+
+// ApprovalDetails is an action joined with the most recent AI decision made
+// for its resource before the action was created. Decision is nil if none exists.
+type ApprovalDetails struct {
+	Action   *Action
+	Decision *AIDecision
+}
+
+// GetApprovalDetails loads an organization's action together with the AI
+// decision behind it
+func (r *Repository) GetApprovalDetails(ctx context.Context, orgID, actionID string) (*ApprovalDetails, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.get_approval_details")
+	defer span.End()
+
+	query := `
+		SELECT a.id, a.resource_id, a.action_type, a.status, a.checksum, a.payload, a.risk_score,
+			a.estimated_savings, a.created_at,
+			d.id::text, d.model, d.decision, d.reasoning, d.confidence, d.created_at
+		FROM actions a
+		LEFT JOIN LATERAL (
+			SELECT id, model, decision, reasoning, confidence, created_at
+			FROM ai_decisions
+			WHERE resource_id = a.resource_id AND created_at <= a.created_at
+			ORDER BY created_at DESC
+			LIMIT 1
+		) d ON true
+		WHERE a.id = $1 AND a.org_id IS NOT DISTINCT FROM $2
+	`
+
+	var action Action
+	var decisionID, model, decision *string
+	var decisionAt *time.Time
+	var d AIDecision
+	err := r.db.QueryRow(ctx, query, actionID, orgParam(orgID)).Scan(
+		&action.ID, &action.ResourceID, &action.ActionType, &action.Status, &action.Checksum, &action.Payload,
+		&action.RiskScore, &action.EstimatedSavings, &action.CreatedAt,
+		&decisionID, &model, &decision, &d.Reasoning, &d.Confidence, &decisionAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("action %s: %w", actionID, ErrNotFound)
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get approval details: %w", err)
+	}
+
+	details := &ApprovalDetails{Action: &action}
+	if decisionID != nil {
+		d.ID, d.ResourceID = *decisionID, action.ResourceID
+		if model != nil {
+			d.Model = *model
+		}
+		if decision != nil {
+			d.Decision = *decision
+		}
+		if decisionAt != nil {
+			d.CreatedAt = *decisionAt
+		}
+		details.Decision = &d
+	}
+
+	return details, nil
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_ApprovalStore(t *testing.T) {
+	var store ApprovalStore = testRepository(t)
+	ctx := context.Background()
+
+	newAction := func(id, resourceID string) *Action {
+		return &Action{ID: id, ResourceID: resourceID, ActionType: "resize", Checksum: id[:8], Payload: "{}", RiskScore: 2, EstimatedSavings: 40}
+	}
+	first := newAction("11111111-1111-1111-1111-111111111111", "i-first")
+	second := newAction("22222222-2222-2222-2222-222222222222", "i-second")
+	require.NoError(t, store.CreatePendingApproval(ctx, "", first))
+	require.NoError(t, store.CreatePendingApproval(ctx, "", second))
+	assert.Equal(t, ActionStatusAwaitingApproval, first.Status)
+
+	pending, err := store.ListPending(ctx, "", ActionFilter{})
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, first.ID, pending[0].ID, "oldest first")
+
+	pending, err = store.ListPending(ctx, "", ActionFilter{ResourceIDs: []string{"i-second"}})
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, second.ID, pending[0].ID)
+
+	require.NoError(t, store.Approve(ctx, "", first.ID, nil))
+	require.NoError(t, store.Reject(ctx, "", second.ID, nil))
+	assert.ErrorIs(t, store.Approve(ctx, "", second.ID, nil), ErrNotFound, "only actions awaiting approval resolve")
+	assert.ErrorIs(t, store.Reject(ctx, "", missingID, nil), ErrNotFound)
+
+	pending, err = store.ListPending(ctx, "", ActionFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	repo := store.(*Repository)
+	approved, err := repo.GetActionByID(ctx, "", first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ActionStatusPending, approved.Status)
+	assert.JSONEq(t, `{"approved": true}`, approved.Payload)
+	rejected, err := repo.GetActionByID(ctx, "", second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ActionStatusRejected, rejected.Status)
+}
+
+func TestRepository_ApprovalsAreScopedToOrganization(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+
+	orgA, orgB := &Organization{Name: "Acme"}, &Organization{Name: "Beta"}
+	require.NoError(t, repo.CreateOrganization(ctx, orgA))
+	require.NoError(t, repo.CreateOrganization(ctx, orgB))
+
+	newAction := func(id, resourceID string) *Action {
+		return &Action{ID: id, ResourceID: resourceID, ActionType: "stop", Checksum: id[:8], Payload: "{}", RiskScore: 2, EstimatedSavings: 40}
+	}
+	ours := newAction("33333333-3333-3333-3333-333333333333", "i-ours")
+	theirs := newAction("44444444-4444-4444-4444-444444444444", "i-theirs")
+	require.NoError(t, repo.CreatePendingApproval(ctx, orgA.ID, ours))
+	require.NoError(t, repo.CreatePendingApproval(ctx, orgB.ID, theirs))
+
+	// Org A sees only its own action
+	recent, err := repo.ListRecentActions(ctx, orgA.ID, "", 10)
+	require.NoError(t, err)
+	require.Len(t, recent, 1)
+	assert.Equal(t, ours.ID, recent[0].ID)
+	count, err := repo.CountAwaitingActions(ctx, orgA.ID, ActionFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	details, err := repo.GetApprovalDetails(ctx, orgA.ID, ours.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "i-ours", details.Action.ResourceID)
+	_, err = repo.GetApprovalDetails(ctx, orgA.ID, theirs.ID)
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	// Actions of no organization are not any organization's
+	recent, err = repo.ListRecentActions(ctx, "", "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, recent)
+
+	// Org A cannot resolve org B's action, one at a time or in bulk
+	assert.ErrorIs(t, repo.Approve(ctx, orgA.ID, theirs.ID, nil), ErrNotFound)
+	assert.ErrorIs(t, repo.ResolveAction(ctx, orgA.ID, theirs.ID, false, nil, nil), ErrNotFound)
+	affected, err := repo.ResolveAwaitingActions(ctx, orgA.ID, ActionFilter{}, false, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, affected)
+
+	rejected, err := repo.GetActionByID(ctx, orgA.ID, ours.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ActionStatusRejected, rejected.Status)
+	untouched, err := repo.GetActionByID(ctx, orgB.ID, theirs.ID)
+	require.NoError(t, err)
+	assert.Equal(t, ActionStatusAwaitingApproval, untouched.Status)
+}
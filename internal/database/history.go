@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Resource history entry types
+const (
+	HistoryEntryAction   = "action"
+	HistoryEntryDecision = "decision"
+	HistoryEntrySavings  = "savings"
+)
+
+// ResourceHistoryEntry is one event in a resource's optimization timeline.
+// Which optional fields are set depends on Type.
+type ResourceHistoryEntry struct {
+	Type             string    `json:"type"`
+	ID               string    `json:"id"`
+	Timestamp        time.Time `json:"timestamp"`
+	Summary          string    `json:"summary"` // action type, AI decision, or optimization type
+	Status           *string   `json:"status,omitempty"`
+	Model            *string   `json:"model,omitempty"`
+	Confidence       *float64  `json:"confidence,omitempty"`
+	Reasoning        *string   `json:"reasoning,omitempty"`
+	EstimatedSavings *float64  `json:"estimated_savings,omitempty"`
+	ActualSavings    *float64  `json:"actual_savings,omitempty"`
+	ErrorMessage     *string   `json:"error_message,omitempty"`
+	ActionID         *string   `json:"action_id,omitempty"`
+}
+
+// ResourceHistory is the consolidated optimization timeline for a resource
+type ResourceHistory struct {
+	ResourceID      string                  `json:"resource_id"`
+	Entries         []*ResourceHistoryEntry `json:"entries"`
+	PendingApproval bool                    `json:"pending_approval"`
+	PendingActionID *string                 `json:"pending_action_id,omitempty"`
+	LastActionAt    *time.Time              `json:"last_action_at,omitempty"`
+	CooldownUntil   *time.Time              `json:"cooldown_until,omitempty"`
+	InCooldown      bool                    `json:"in_cooldown"`
+}
+
+// GetResourceHistory returns every action, AI decision and savings event for
+// a resource in chronological order. A resource is in cooldown when its last
+// completed action finished less than cooldown ago.
+func (r *Repository) GetResourceHistory(ctx context.Context, resourceID string, cooldown time.Duration) (*ResourceHistory, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.get_resource_history")
+	defer span.End()
+
+	query := `
+		SELECT 'action', id::text, created_at, action_type, status, NULL::text, NULL::numeric, NULL::text,
+			estimated_savings, NULL::numeric, error_message, NULL::text
+		FROM actions WHERE resource_id = $1
+		UNION ALL
+		SELECT 'decision', id::text, created_at, decision, NULL::text, model, confidence, reasoning,
+			NULL::numeric, NULL::numeric, NULL::text, NULL::text
+		FROM ai_decisions WHERE resource_id = $1
+		UNION ALL
+		SELECT 'savings', id::text, created_at, COALESCE(optimization_type, ''), NULL::text, NULL::text, NULL::numeric, NULL::text,
+			estimated_savings, actual_savings, NULL::text, action_id::text
+		FROM savings_events WHERE resource_id = $1
+		ORDER BY 3 ASC
+	`
+
+	rows, err := r.db.Query(ctx, query, resourceID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to query resource history: %w", err)
+	}
+	defer rows.Close()
+
+	history := &ResourceHistory{ResourceID: resourceID, Entries: []*ResourceHistoryEntry{}}
+	for rows.Next() {
+		var e ResourceHistoryEntry
+		err := rows.Scan(
+			&e.Type, &e.ID, &e.Timestamp, &e.Summary, &e.Status, &e.Model, &e.Confidence, &e.Reasoning,
+			&e.EstimatedSavings, &e.ActualSavings, &e.ErrorMessage, &e.ActionID,
+		)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan history entry: %w", err)
+		}
+		history.Entries = append(history.Entries, &e)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to read resource history: %w", err)
+	}
+
+	var pendingID string
+	err = r.db.QueryRow(ctx, `
+		SELECT id::text FROM actions
+		WHERE resource_id = $1 AND status = 'PENDING'
+		ORDER BY created_at DESC LIMIT 1
+	`, resourceID).Scan(&pendingID)
+	switch {
+	case err == nil:
+		history.PendingApproval = true
+		history.PendingActionID = &pendingID
+	case !errors.Is(err, pgx.ErrNoRows):
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to check pending actions: %w", err)
+	}
+
+	var lastActionAt *time.Time
+	err = r.db.QueryRow(ctx, `
+		SELECT MAX(completed_at) FROM actions
+		WHERE resource_id = $1 AND status = 'COMPLETED'
+	`, resourceID).Scan(&lastActionAt)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to check cooldown: %w", err)
+	}
+	if lastActionAt != nil {
+		until := lastActionAt.Add(cooldown)
+		history.LastActionAt = lastActionAt
+		history.CooldownUntil = &until
+		history.InCooldown = time.Now().Before(until)
+	}
+
+	return history, nil
+}
@@ -0,0 +1,123 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// InventoryItem is one resource captured in an inventory snapshot. The
+// resource is stored as JSON so this package stays independent of the cloud
+// model.
+type InventoryItem struct {
+	ResourceID string
+	Resource   json.RawMessage
+}
+
+// InventorySnapshot is a resource as observed in one OODA cycle, together
+// with the AI decision that was current for it in that cycle
+type InventorySnapshot struct {
+	CycleID    string          `json:"cycle_id"`
+	CapturedAt time.Time       `json:"captured_at"`
+	ResourceID string          `json:"resource_id"`
+	Resource   json.RawMessage `json:"resource"`
+	Decision   *AIDecision     `json:"decision,omitempty"` // nil when no decision was stored
+}
+
+// SaveInventorySnapshot stores the resources observed in a cycle. Saving the
+// same cycle twice keeps the first copy.
+func (r *Repository) SaveInventorySnapshot(ctx context.Context, cycleID string, capturedAt time.Time, items []InventoryItem) error {
+	ctx, span := r.tracer.Start(ctx, "repository.save_inventory_snapshot")
+	defer span.End()
+
+	if len(items) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(items))
+	bodies := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ResourceID
+		bodies[i] = string(item.Resource)
+	}
+
+	query := `
+		INSERT INTO inventory_snapshots (cycle_id, resource_id, resource, captured_at)
+		SELECT $1, r.id, r.body::jsonb, $4
+		FROM unnest($2::text[], $3::text[]) AS r(id, body)
+		ON CONFLICT (cycle_id, resource_id) DO NOTHING
+	`
+	if _, err := r.db.Exec(ctx, query, cycleID, ids, bodies, capturedAt); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to save inventory snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// ListInventorySnapshots returns every snapshot captured in [start, end) in
+// capture order. Each snapshot carries the latest AI decision for its
+// resource made before the resource was next observed, i.e. the decision
+// that cycle acted on.
+func (r *Repository) ListInventorySnapshots(ctx context.Context, start, end time.Time) ([]*InventorySnapshot, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.list_inventory_snapshots")
+	defer span.End()
+
+	query := `
+		WITH snaps AS (
+			SELECT cycle_id, resource_id, resource, captured_at,
+				LEAD(captured_at) OVER (PARTITION BY resource_id ORDER BY captured_at) AS next_at
+			FROM inventory_snapshots
+			WHERE captured_at >= $1 AND captured_at < $2
+		)
+		SELECT s.cycle_id, s.captured_at, s.resource_id, s.resource,
+			d.id::text, d.model, d.decision, d.reasoning, d.confidence, d.tokens_used, d.latency_ms, d.created_at
+		FROM snaps s
+		LEFT JOIN LATERAL (
+			SELECT * FROM ai_decisions
+			WHERE resource_id = s.resource_id AND created_at < COALESCE(s.next_at, 'infinity')
+			ORDER BY created_at DESC
+			LIMIT 1
+		) d ON true
+		ORDER BY s.captured_at, s.resource_id
+	`
+
+	rows, err := r.db.Query(ctx, query, start, end)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to query inventory snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*InventorySnapshot
+	for rows.Next() {
+		var (
+			s         InventorySnapshot
+			id        *string
+			model     *string
+			decision  *string
+			createdAt *time.Time
+			d         AIDecision
+		)
+		err := rows.Scan(
+			&s.CycleID, &s.CapturedAt, &s.ResourceID, &s.Resource,
+			&id, &model, &decision, &d.Reasoning, &d.Confidence, &d.TokensUsed, &d.LatencyMs, &createdAt,
+		)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan inventory snapshot: %w", err)
+		}
+		if id != nil {
+			d.ID, d.ResourceID, d.Model, d.Decision, d.CreatedAt = *id, s.ResourceID, *model, *decision, *createdAt
+			s.Decision = &d
+		}
+		snapshots = append(snapshots, &s)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to read inventory snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
@@ -133,6 +133,29 @@ func (dm *DatabaseManager) HealthCheck(ctx context.Context) error {
 	return dm.pool.Ping(ctx)
 }
 
+// AppliedMigrations returns the versions recorded in schema_migrations by
+// cmd/migrate, sorted
+func (dm *DatabaseManager) AppliedMigrations(ctx context.Context) ([]string, error) {
+	ctx, span := dm.tracer.Start(ctx, "database.applied_migrations")
+	defer span.End()
+
+	rows, err := dm.pool.Query(ctx, "SELECT version FROM schema_migrations ORDER BY version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []string
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan migration version: %w", err)
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
 // Close closes the database connection pool
 func (dm *DatabaseManager) Close() {
 	if dm.pool != nil {
@@ -270,3 +293,29 @@ func ProductionDatabaseConfig() DatabaseConfig {
 		HealthCheckPeriod: 30 * time.Second,
 	}
 }
+
+// ConfigFromDSN builds a DatabaseConfig from a libpq-style DSN or postgres:// URL,
+// keeping the default pool settings
+func ConfigFromDSN(dsn string) (DatabaseConfig, error) {
+	parsed, err := pgconn.ParseConfig(dsn)
+	if err != nil {
+		return DatabaseConfig{}, fmt.Errorf("failed to parse database DSN: %w", err)
+	}
+
+	config := DefaultDatabaseConfig()
+	config.Host = parsed.Host
+	config.Port = int(parsed.Port)
+	config.Database = parsed.Database
+	config.Username = parsed.User
+	config.Password = parsed.Password
+	if parsed.TLSConfig == nil {
+		config.SSLMode = "disable"
+	} else {
+		config.SSLMode = "require"
+	}
+	if parsed.ConnectTimeout > 0 {
+		config.ConnectTimeout = parsed.ConnectTimeout
+	}
+
+	return config, nil
+}
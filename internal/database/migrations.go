@@ -0,0 +1,57 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Migration is a schema change in the migrations directory. Its up script
+// applies it and its down script, when there is one, reverts it.
+type Migration struct {
+	Version  string // e.g. "001_initial_schema", as recorded in schema_migrations
+	UpPath   string
+	DownPath string // "" if the migration cannot be reverted
+}
+
+// ListMigrations returns the migrations in dir ordered by version. Each is a
+// VERSION.up.sql script with an optional VERSION.down.sql beside it; a down
+// script without an up script is an error.
+func ListMigrations(dir string) ([]Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	byVersion := make(map[string]*Migration)
+	var downs []string
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(dir, name)
+		switch {
+		case entry.IsDir():
+		case strings.HasSuffix(name, ".up.sql"):
+			version := strings.TrimSuffix(name, ".up.sql")
+			byVersion[version] = &Migration{Version: version, UpPath: path}
+		case strings.HasSuffix(name, ".down.sql"):
+			downs = append(downs, path)
+		}
+	}
+	for _, path := range downs {
+		version := strings.TrimSuffix(filepath.Base(path), ".down.sql")
+		migration, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("down migration %s has no up migration", path)
+		}
+		migration.DownPath = path
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListMigrations(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"002_users.up.sql", "001_init.down.sql", "001_init.up.sql", "README.md",
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+
+	migrations, err := ListMigrations(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: "001_init", UpPath: filepath.Join(dir, "001_init.up.sql"), DownPath: filepath.Join(dir, "001_init.down.sql")},
+		{Version: "002_users", UpPath: filepath.Join(dir, "002_users.up.sql")},
+	}, migrations)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "003_orphan.down.sql"), nil, 0o644))
+	_, err = ListMigrations(dir)
+	assert.Error(t, err)
+}
+
+func TestListMigrations_RepositoryMigrationsAreReversible(t *testing.T) {
+	migrations, err := ListMigrations("../../migrations")
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for _, migration := range migrations {
+		assert.NotEmpty(t, migration.DownPath, migration.Version)
+	}
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Lifecycle event types written to the event outbox. Action events are
+// written in the same statement as the change they describe, so an event is
+// never lost or emitted for a change that didn't happen.
+const (
+	EventOpportunityFound = "opportunity.found"
+	EventActionCreated    = "action.created"
+	EventActionApproved   = "action.approved"
+	EventActionRejected   = "action.rejected"
+	EventActionExecuted   = "action.executed"
+	EventActionFailed     = "action.failed"
+	EventActionRolledBack = "action.rolled_back"
+	EventSavingsVerified  = "savings.verified"
+)
+
+// OutboxEvent is a lifecycle event awaiting delivery. Sequence orders the
+// event feed; Data is the event-specific JSON object.
+type OutboxEvent struct {
+	Sequence   int64           `json:"sequence"`
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	SubjectID  string          `json:"subject_id"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Attempts   int             `json:"attempts"`
+}
+
+// AppendEvent writes an event that isn't tied to a row change, such as an
+// opportunity found during analysis
+func (r *Repository) AppendEvent(ctx context.Context, eventType, subjectID string, data map[string]interface{}) error {
+	ctx, span := r.tracer.Start(ctx, "repository.append_event")
+	defer span.End()
+
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	query := `INSERT INTO event_outbox (event_type, subject_id, data) VALUES ($1, $2, $3)`
+	if _, err := r.db.Exec(ctx, query, eventType, subjectID, data); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to append %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// ListUndeliveredEvents returns up to limit undelivered events, oldest first
+func (r *Repository) ListUndeliveredEvents(ctx context.Context, limit int) ([]*OutboxEvent, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.list_undelivered_events")
+	defer span.End()
+
+	query := `
+		SELECT sequence, id::text, event_type, subject_id, data, occurred_at, attempts
+		FROM event_outbox
+		WHERE delivered_at IS NULL
+		ORDER BY sequence
+		LIMIT $1
+	`
+
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list undelivered events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*OutboxEvent
+	for rows.Next() {
+		var event OutboxEvent
+		var data []byte
+		if err := rows.Scan(&event.Sequence, &event.ID, &event.Type, &event.SubjectID, &data, &event.OccurredAt, &event.Attempts); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan event: %w", err)
+		}
+		event.Data = data
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list undelivered events: %w", err)
+	}
+	return events, nil
+}
+
+// MarkEventDelivered records that the event with the given sequence was
+// accepted by the endpoint
+func (r *Repository) MarkEventDelivered(ctx context.Context, sequence int64) error {
+	ctx, span := r.tracer.Start(ctx, "repository.mark_event_delivered")
+	defer span.End()
+
+	query := `UPDATE event_outbox SET delivered_at = NOW(), attempts = attempts + 1, last_error = NULL WHERE sequence = $1`
+	if _, err := r.db.Exec(ctx, query, sequence); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to mark event %d delivered: %w", sequence, err)
+	}
+	return nil
+}
+
+// RecordEventFailure records a failed delivery attempt; the event stays
+// queued and is retried
+func (r *Repository) RecordEventFailure(ctx context.Context, sequence int64, reason string) error {
+	ctx, span := r.tracer.Start(ctx, "repository.record_event_failure")
+	defer span.End()
+
+	query := `UPDATE event_outbox SET attempts = attempts + 1, last_error = $2 WHERE sequence = $1`
+	if _, err := r.db.Exec(ctx, query, sequence, reason); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to record event %d failure: %w", sequence, err)
+	}
+	return nil
+}
@@ -0,0 +1,330 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SavingsReport aggregates savings, AI cost and action outcomes for a period
+type SavingsReport struct {
+	PeriodStart           time.Time          `json:"period_start"`
+	PeriodEnd             time.Time          `json:"period_end"`
+	TotalRealizedSavings  float64            `json:"total_realized_savings"`
+	TotalEstimatedSavings float64            `json:"total_estimated_savings"`
+	TotalAICost           float64            `json:"total_ai_cost"`
+	TotalTokens           int64              `json:"total_tokens"`
+	NetROI                float64            `json:"net_roi"`
+	ROIPercentage         float64            `json:"roi_percentage"`
+	ActionsTotal          int                `json:"actions_total"`
+	ActionsCompleted      int                `json:"actions_completed"`
+	ActionsFailed         int                `json:"actions_failed"`
+	SuccessRate           float64            `json:"success_rate"`
+	TopOptimizations      []*TopOptimization `json:"top_optimizations"`
+}
+
+// TopOptimization is a single high-impact savings event in a report
+type TopOptimization struct {
+	ResourceID       string    `json:"resource_id"`
+	OptimizationType string    `json:"optimization_type"`
+	ActualSavings    float64   `json:"actual_savings"`
+	EstimatedSavings float64   `json:"estimated_savings"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// GetSavingsReport aggregates an organization's savings_events and actions,
+// and token_usage, for [start, end). AI spend is not recorded per
+// organization, so the token totals cover every organization.
+func (r *Repository) GetSavingsReport(ctx context.Context, orgID string, start, end time.Time, topN int) (*SavingsReport, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.get_savings_report")
+	defer span.End()
+
+	if !end.After(start) {
+		return nil, fmt.Errorf("invalid report period: end must be after start")
+	}
+	if topN <= 0 {
+		topN = 10
+	}
+
+	report := &SavingsReport{
+		PeriodStart: start,
+		PeriodEnd:   end,
+	}
+
+	savingsQuery := `
+		SELECT COALESCE(SUM(actual_savings), 0), COALESCE(SUM(estimated_savings), 0)
+		FROM savings_events
+		WHERE created_at >= $1 AND created_at < $2 AND org_id IS NOT DISTINCT FROM $3
+	`
+	if err := r.db.QueryRow(ctx, savingsQuery, start, end, orgParam(orgID)).Scan(&report.TotalRealizedSavings, &report.TotalEstimatedSavings); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to aggregate savings: %w", err)
+	}
+
+	tokenQuery := `
+		SELECT COALESCE(SUM(cost_usd), 0), COALESCE(SUM(tokens), 0)
+		FROM token_usage
+		WHERE created_at >= $1 AND created_at < $2
+	`
+	if err := r.db.QueryRow(ctx, tokenQuery, start, end).Scan(&report.TotalAICost, &report.TotalTokens); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to aggregate token usage: %w", err)
+	}
+
+	actionQuery := `
+		SELECT
+			COUNT(*),
+			COUNT(*) FILTER (WHERE status = 'COMPLETED'),
+			COUNT(*) FILTER (WHERE status = 'FAILED')
+		FROM actions
+		WHERE created_at >= $1 AND created_at < $2 AND org_id IS NOT DISTINCT FROM $3
+	`
+	if err := r.db.QueryRow(ctx, actionQuery, start, end, orgParam(orgID)).Scan(&report.ActionsTotal, &report.ActionsCompleted, &report.ActionsFailed); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to aggregate actions: %w", err)
+	}
+
+	topQuery := `
+		SELECT resource_id, COALESCE(optimization_type, ''), COALESCE(actual_savings, 0),
+			   COALESCE(estimated_savings, 0), created_at
+		FROM savings_events
+		WHERE created_at >= $1 AND created_at < $2 AND org_id IS NOT DISTINCT FROM $4
+		ORDER BY actual_savings DESC NULLS LAST
+		LIMIT $3
+	`
+	rows, err := r.db.Query(ctx, topQuery, start, end, topN, orgParam(orgID))
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get top optimizations: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var top TopOptimization
+		if err := rows.Scan(&top.ResourceID, &top.OptimizationType, &top.ActualSavings, &top.EstimatedSavings, &top.CreatedAt); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan top optimization: %w", err)
+		}
+		report.TopOptimizations = append(report.TopOptimizations, &top)
+	}
+
+	report.NetROI, report.ROIPercentage = netROI(report.TotalRealizedSavings, report.TotalAICost)
+	finished := report.ActionsCompleted + report.ActionsFailed
+	if finished > 0 {
+		report.SuccessRate = float64(report.ActionsCompleted) / float64(finished)
+	}
+
+	return report, nil
+}
+
+// netROI returns realized savings net of AI cost, and that as a percentage
+// of the cost (zero when nothing was spent)
+func netROI(savings, cost float64) (float64, float64) {
+	net := savings - cost
+	if cost <= 0 {
+		return net, 0
+	}
+	return net, net / cost * 100
+}
+
+// SavingsByType totals the savings events of one optimization type
+type SavingsByType struct {
+	OptimizationType string  `json:"optimization_type"`
+	Events           int     `json:"events"`
+	EstimatedSavings float64 `json:"estimated_savings"`
+	ActualSavings    float64 `json:"actual_savings"`
+}
+
+// GetSavingsByTimeRange totals an organization's savings events in
+// [from, to) by optimization type, largest realized savings first
+func (r *Repository) GetSavingsByTimeRange(ctx context.Context, orgID string, from, to time.Time) ([]*SavingsByType, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.get_savings_by_time_range")
+	defer span.End()
+
+	if !to.After(from) {
+		return nil, fmt.Errorf("invalid time range: to must be after from")
+	}
+
+	query := `
+		SELECT COALESCE(optimization_type, ''), COUNT(*),
+			   COALESCE(SUM(estimated_savings), 0), COALESCE(SUM(actual_savings), 0)
+		FROM savings_events
+		WHERE created_at >= $1 AND created_at < $2 AND org_id IS NOT DISTINCT FROM $3
+		GROUP BY 1
+		ORDER BY 4 DESC, 1
+	`
+	rows, err := r.db.Query(ctx, query, from, to, orgParam(orgID))
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to aggregate savings: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []*SavingsByType
+	for rows.Next() {
+		var t SavingsByType
+		if err := rows.Scan(&t.OptimizationType, &t.Events, &t.EstimatedSavings, &t.ActualSavings); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan savings totals: %w", err)
+		}
+		totals = append(totals, &t)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to read savings totals: %w", err)
+	}
+
+	return totals, nil
+}
+
+// ROISummary compares realized savings with AI spend over a window
+type ROISummary struct {
+	WindowStart      time.Time        `json:"window_start"`
+	WindowEnd        time.Time        `json:"window_end"`
+	TotalSavings     float64          `json:"total_savings"` // realized
+	EstimatedSavings float64          `json:"estimated_savings"`
+	TotalAICost      float64          `json:"total_ai_cost"`
+	NetROI           float64          `json:"net_roi"`
+	ROIPercentage    float64          `json:"roi_percentage"`
+	ByType           []*SavingsByType `json:"by_type"`
+}
+
+// GetROISummary combines an organization's savings_events and the
+// token_usage over the window ending now into the net return on AI spend.
+// As in GetSavingsReport, the AI spend is that of every organization.
+func (r *Repository) GetROISummary(ctx context.Context, orgID string, window time.Duration) (*ROISummary, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.get_roi_summary")
+	defer span.End()
+
+	if window <= 0 {
+		return nil, fmt.Errorf("invalid ROI window: must be positive")
+	}
+	end := time.Now().UTC()
+	summary := &ROISummary{WindowStart: end.Add(-window), WindowEnd: end}
+
+	byType, err := r.GetSavingsByTimeRange(ctx, orgID, summary.WindowStart, summary.WindowEnd)
+	if err != nil {
+		return nil, err
+	}
+	summary.ByType = byType
+	for _, t := range byType {
+		summary.TotalSavings += t.ActualSavings
+		summary.EstimatedSavings += t.EstimatedSavings
+	}
+
+	costQuery := `
+		SELECT COALESCE(SUM(cost_usd), 0)
+		FROM token_usage
+		WHERE created_at >= $1 AND created_at < $2
+	`
+	if err := r.db.QueryRow(ctx, costQuery, summary.WindowStart, summary.WindowEnd).Scan(&summary.TotalAICost); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to aggregate token usage: %w", err)
+	}
+
+	summary.NetROI, summary.ROIPercentage = netROI(summary.TotalSavings, summary.TotalAICost)
+	return summary, nil
+}
+
+// SavingsSample is one savings event's estimated and realized savings
+type SavingsSample struct {
+	OptimizationType string  `json:"optimization_type"`
+	EstimatedSavings float64 `json:"estimated_savings"`
+	ActualSavings    float64 `json:"actual_savings"`
+}
+
+// ListSavingsSamples returns the savings events in [start, end) that record
+// both an estimate and a realized value
+func (r *Repository) ListSavingsSamples(ctx context.Context, start, end time.Time) ([]*SavingsSample, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.list_savings_samples")
+	defer span.End()
+
+	query := `
+		SELECT COALESCE(optimization_type, ''), estimated_savings, actual_savings
+		FROM savings_events
+		WHERE created_at >= $1 AND created_at < $2
+			AND estimated_savings IS NOT NULL AND actual_savings IS NOT NULL
+		ORDER BY created_at
+	`
+	rows, err := r.db.Query(ctx, query, start, end)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to query savings samples: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []*SavingsSample
+	for rows.Next() {
+		var sample SavingsSample
+		if err := rows.Scan(&sample.OptimizationType, &sample.EstimatedSavings, &sample.ActualSavings); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan savings sample: %w", err)
+		}
+		samples = append(samples, &sample)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to read savings samples: %w", err)
+	}
+
+	return samples, nil
+}
+
+// Report delivery statuses
+const (
+	ReportDeliverySending = "SENDING"
+	ReportDeliverySent    = "SENT"
+	ReportDeliveryFailed  = "FAILED"
+)
+
+// ClaimReportDelivery claims the right to send schedule's report for
+// [start, end). It returns false if the period was already sent or is being
+// sent; a previously failed delivery can be claimed again.
+func (r *Repository) ClaimReportDelivery(ctx context.Context, schedule string, start, end time.Time, recipients []string) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.claim_report_delivery")
+	defer span.End()
+
+	query := `
+		INSERT INTO report_deliveries (schedule, period_start, period_end, recipients)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (schedule, period_start, period_end) DO UPDATE
+		SET status = 'SENDING', attempts = report_deliveries.attempts + 1,
+			recipients = EXCLUDED.recipients, error_message = NULL
+		WHERE report_deliveries.status = 'FAILED'
+	`
+	tag, err := r.db.Exec(ctx, query, schedule, start, end, recipients)
+	if err != nil {
+		span.RecordError(err)
+		return false, fmt.Errorf("failed to claim report delivery: %w", err)
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// FinishReportDelivery records the outcome of a claimed delivery: SENT, or
+// FAILED with sendErr so that it can be claimed and retried
+func (r *Repository) FinishReportDelivery(ctx context.Context, schedule string, start, end time.Time, sendErr error) error {
+	ctx, span := r.tracer.Start(ctx, "repository.finish_report_delivery")
+	defer span.End()
+
+	status := ReportDeliverySent
+	var errorMessage *string
+	var sentAt *time.Time
+	if sendErr != nil {
+		msg := sendErr.Error()
+		status, errorMessage = ReportDeliveryFailed, &msg
+	} else {
+		now := time.Now()
+		sentAt = &now
+	}
+
+	query := `
+		UPDATE report_deliveries
+		SET status = $4, error_message = $5, sent_at = $6
+		WHERE schedule = $1 AND period_start = $2 AND period_end = $3
+	`
+	if _, err := r.db.Exec(ctx, query, schedule, start, end, status, errorMessage, sentAt); err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to record report delivery: %w", err)
+	}
+	return nil
+}
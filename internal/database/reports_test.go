@@ -0,0 +1,185 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestNetROI(t *testing.T) {
+	net, pct := netROI(1250.5, 342.75)
+	assert.InDelta(t, 907.75, net, 1e-9)
+	assert.InDelta(t, 264.84, pct, 0.01)
+
+	// Nothing spent: no percentage rather than a division by zero
+	net, pct = netROI(100, 0)
+	assert.Equal(t, 100.0, net)
+	assert.Zero(t, pct)
+
+	net, pct = netROI(50, 100)
+	assert.Equal(t, -50.0, net)
+	assert.Equal(t, -50.0, pct)
+}
+
+func TestGetSavingsReport_RejectsEmptyPeriod(t *testing.T) {
+	// The period is checked before the database is queried
+	repo := &Repository{tracer: noop.NewTracerProvider().Tracer("")}
+	start := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, end := range []time.Time{start, start.Add(-time.Hour)} {
+		_, err := repo.GetSavingsReport(context.Background(), "", start, end, 10)
+		assert.ErrorContains(t, err, "invalid report period")
+	}
+}
+
+func TestRepository_SavingsAreScopedToOrganization(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+
+	orgA, orgB := &Organization{Name: "Acme"}, &Organization{Name: "Beta"}
+	require.NoError(t, repo.CreateOrganization(ctx, orgA))
+	require.NoError(t, repo.CreateOrganization(ctx, orgB))
+
+	stop := "stop"
+	for _, org := range []struct {
+		id, actionID, eventID string
+		savings               float64
+	}{
+		{orgA.ID, "55555555-5555-5555-5555-555555555555", "66666666-6666-6666-6666-666666666666", 30},
+		{orgB.ID, "77777777-7777-7777-7777-777777777777", "88888888-8888-8888-8888-888888888888", 500},
+	} {
+		action := &Action{ID: org.actionID, ResourceID: "i-" + org.actionID[:8], ActionType: stop, Status: "COMPLETED", Checksum: org.actionID[:8], Payload: "{}"}
+		require.NoError(t, repo.CreateAction(ctx, org.id, action))
+		savings := org.savings
+		require.NoError(t, repo.CreateSavingsEvent(ctx, org.id, &SavingsEvent{
+			ID: org.eventID, ActionID: &action.ID, ResourceID: action.ResourceID,
+			OptimizationType: &stop, EstimatedSavings: &savings, ActualSavings: &savings,
+		}))
+	}
+
+	now := time.Now().UTC()
+	report, err := repo.GetSavingsReport(ctx, orgA.ID, now.Add(-time.Hour), now.Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, report.TotalRealizedSavings)
+	assert.Equal(t, 1, report.ActionsTotal)
+	require.Len(t, report.TopOptimizations, 1)
+	assert.Equal(t, 30.0, report.TopOptimizations[0].ActualSavings)
+
+	summary, err := repo.GetROISummary(ctx, orgA.ID, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, summary.TotalSavings)
+
+	summary, err = repo.GetROISummary(ctx, "", time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, summary.TotalSavings, "savings of no organization are not any organization's")
+}
+
+// savingsFixture is a completed action of no organization and the savings
+// event it realized at a given time
+type savingsFixture struct {
+	optimization      string
+	estimated, actual float64
+	at                time.Time
+}
+
+func seedSavings(t *testing.T, repo *Repository, fixtures []savingsFixture) {
+	t.Helper()
+	ctx := context.Background()
+	for i, f := range fixtures {
+		actionID := fmt.Sprintf("a0000000-0000-0000-0000-%012d", i)
+		eventID := fmt.Sprintf("e0000000-0000-0000-0000-%012d", i)
+		action := &Action{ID: actionID, ResourceID: fmt.Sprintf("i-%d", i), ActionType: f.optimization, Status: "COMPLETED", Checksum: fmt.Sprintf("%08d", i), Payload: "{}"}
+		require.NoError(t, repo.CreateAction(ctx, "", action))
+		optimization, estimated, actual := f.optimization, f.estimated, f.actual
+		require.NoError(t, repo.CreateSavingsEvent(ctx, "", &SavingsEvent{
+			ID: eventID, ActionID: &action.ID, ResourceID: action.ResourceID,
+			OptimizationType: &optimization, EstimatedSavings: &estimated, ActualSavings: &actual,
+		}))
+		_, err := repo.db.Exec(ctx, "UPDATE savings_events SET created_at = $1 WHERE id = $2", f.at, eventID)
+		require.NoError(t, err)
+	}
+}
+
+func TestRepository_GetSavingsByTimeRange(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	seedSavings(t, repo, []savingsFixture{
+		{"stop", 40, 35, now.Add(-2 * time.Hour)},
+		{"stop", 20, 25, now.Add(-3 * time.Hour)},
+		{"resize", 100, 90, now.Add(-time.Hour)},
+		{"stop", 999, 999, now.Add(-48 * time.Hour)},
+	})
+
+	totals, err := repo.GetSavingsByTimeRange(ctx, "", now.Add(-24*time.Hour), now)
+	require.NoError(t, err)
+	assert.Equal(t, []*SavingsByType{
+		{OptimizationType: "resize", Events: 1, EstimatedSavings: 100, ActualSavings: 90},
+		{OptimizationType: "stop", Events: 2, EstimatedSavings: 60, ActualSavings: 60},
+	}, totals, "largest realized savings first")
+
+	totals, err = repo.GetSavingsByTimeRange(ctx, "", now.Add(-72*time.Hour), now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, []*SavingsByType{{OptimizationType: "stop", Events: 1, EstimatedSavings: 999, ActualSavings: 999}}, totals)
+
+	// The range is half-open
+	totals, err = repo.GetSavingsByTimeRange(ctx, "", now.Add(-2*time.Hour+time.Second), now.Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, totals)
+
+	_, err = repo.GetSavingsByTimeRange(ctx, "", now, now)
+	assert.ErrorContains(t, err, "invalid time range")
+}
+
+func TestRepository_GetROISummary(t *testing.T) {
+	repo := testRepository(t)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	_, err := repo.db.Exec(ctx, "TRUNCATE token_usage")
+	require.NoError(t, err)
+	seedSavings(t, repo, []savingsFixture{
+		{"stop", 50, 40, now.Add(-time.Hour)},
+		{"resize", 100, 80, now.Add(-2 * time.Hour)},
+		{"stop", 1000, 1000, now.Add(-10 * 24 * time.Hour)},
+	})
+	for i, usage := range []struct {
+		cost float64
+		at   time.Time
+	}{
+		{20, now.Add(-time.Hour)},
+		{500, now.Add(-10 * 24 * time.Hour)},
+	} {
+		id := fmt.Sprintf("70000000-0000-0000-0000-%012d", i)
+		require.NoError(t, repo.RecordTokenUsage(ctx, &TokenUsage{ID: id, Model: "gpt", Tokens: 1000, CostUSD: usage.cost}))
+		_, err := repo.db.Exec(ctx, "UPDATE token_usage SET created_at = $1 WHERE id = $2", usage.at, id)
+		require.NoError(t, err)
+	}
+
+	summary, err := repo.GetROISummary(ctx, "", 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 120.0, summary.TotalSavings)
+	assert.Equal(t, 150.0, summary.EstimatedSavings)
+	assert.Equal(t, 20.0, summary.TotalAICost)
+	assert.Equal(t, 100.0, summary.NetROI)
+	assert.Equal(t, 500.0, summary.ROIPercentage)
+	require.Len(t, summary.ByType, 2)
+	assert.Equal(t, "resize", summary.ByType[0].OptimizationType)
+
+	// A longer window takes in the older savings and spend
+	summary, err = repo.GetROISummary(ctx, "", 30*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1120.0, summary.TotalSavings)
+	assert.Equal(t, 520.0, summary.TotalAICost)
+	assert.Equal(t, 600.0, summary.NetROI)
+	assert.InDelta(t, 115.38, summary.ROIPercentage, 0.01)
+
+	_, err = repo.GetROISummary(ctx, "", 0)
+	assert.ErrorContains(t, err, "invalid ROI window")
+}
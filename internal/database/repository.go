@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -40,6 +41,34 @@ type Action struct {
 	StartedAt        *time.Time `json:"started_at" db:"started_at"`
 	CompletedAt      *time.Time `json:"completed_at" db:"completed_at"`
 	ErrorMessage     *string    `json:"error_message" db:"error_message"`
+	OrgID            *string    `json:"org_id,omitempty" db:"org_id"` // nil for actions of no organization
+}
+
+// actionColumns are the columns scanned by scanAction
+const actionColumns = `id, resource_id, action_type, status, checksum, payload, risk_score, estimated_savings,
+			   created_at, started_at, completed_at, error_message, org_id`
+
+func scanAction(row pgx.Row) (*Action, error) {
+	var action Action
+	err := row.Scan(
+		&action.ID, &action.ResourceID, &action.ActionType, &action.Status,
+		&action.Checksum, &action.Payload, &action.RiskScore, &action.EstimatedSavings,
+		&action.CreatedAt, &action.StartedAt, &action.CompletedAt, &action.ErrorMessage, &action.OrgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &action, nil
+}
+
+// orgParam is the org_id an organization ID is stored as: actions and savings
+// of no organization ("") have a NULL org_id. Queries compare it with
+// IS NOT DISTINCT FROM, so they never match another organization's rows.
+func orgParam(orgID string) *string {
+	if orgID == "" {
+		return nil
+	}
+	return &orgID
 }
 
 // AIDecision represents an AI decision
@@ -59,12 +88,26 @@ type AIDecision struct {
 type TokenUsage struct {
 	ID          string    `json:"id" db:"id"`
 	Model       string    `json:"model" db:"model"`
+	Tier        string    `json:"tier" db:"tier"` // "" when the tier is unknown
 	Tokens      int       `json:"tokens" db:"tokens"`
 	CostUSD     float64   `json:"cost_usd" db:"cost_usd"`
 	RequestType *string   `json:"request_type" db:"request_type"`
+	Requests    int       `json:"requests" db:"requests"`       // requests the row covers
+	SavingsUSD  float64   `json:"savings_usd" db:"savings_usd"` // savings those requests identified
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 }
 
+// TokenUsageTotal is a model's usage on one tier summed over all of token_usage
+type TokenUsageTotal struct {
+	Model       string    `json:"model"`
+	Tier        string    `json:"tier"`
+	Tokens      int       `json:"tokens"`
+	CostUSD     float64   `json:"cost_usd"`
+	Requests    int       `json:"requests"`
+	SavingsUSD  float64   `json:"savings_usd"`
+	FirstUsedAt time.Time `json:"first_used_at"`
+}
+
 // SavingsEvent represents a savings event
 type SavingsEvent struct {
 	ID               string    `json:"id" db:"id"`
@@ -73,6 +116,7 @@ type SavingsEvent struct {
 	OptimizationType *string   `json:"optimization_type" db:"optimization_type"`
 	EstimatedSavings *float64  `json:"estimated_savings" db:"estimated_savings"`
 	ActualSavings    *float64  `json:"actual_savings" db:"actual_savings"`
+	OrgID            *string   `json:"org_id,omitempty" db:"org_id"`
 	CreatedAt        time.Time `json:"created_at" db:"created_at"`
 }
 
@@ -81,17 +125,22 @@ type Organization struct {
 	ID        string                 `json:"id" db:"id"`
 	Name      string                 `json:"name" db:"name"`
 	CreatedAt time.Time              `json:"created_at" db:"created_at"`
+	UpdatedAt *time.Time             `json:"updated_at,omitempty" db:"updated_at"`
 	Settings  map[string]interface{} `json:"settings" db:"settings"`
 }
 
 // User represents a user
 type User struct {
-	ID             string     `json:"id" db:"id"`
-	Email          string     `json:"email" db:"email"`
-	OrganizationID *string    `json:"organization_id" db:"organization_id"`
-	Role           string     `json:"role" db:"role"`
-	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
-	LastLogin      *time.Time `json:"last_login" db:"last_login"`
+	ID              string     `json:"id" db:"id"`
+	Email           string     `json:"email" db:"email"`
+	OrganizationID  *string    `json:"organization_id" db:"org_id"`
+	Role            string     `json:"role" db:"role"`
+	Status          string     `json:"status" db:"status"`
+	PasswordHash    *string    `json:"-" db:"password_hash"`
+	InviteExpiresAt *time.Time `json:"invite_expires_at,omitempty" db:"invite_expires_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty" db:"updated_at"`
+	LastLogin       *time.Time `json:"last_login" db:"last_login"`
 }
 
 // Resource represents a cloud resource
@@ -121,19 +170,31 @@ type AuditLog struct {
 	CreatedAt    time.Time              `json:"created_at" db:"created_at"`
 }
 
-// CreateAction creates a new action
-func (r *Repository) CreateAction(ctx context.Context, action *Action) error {
+// CreateAction creates a new action owned by an organization ("" for none),
+// together with an action.created event
+func (r *Repository) CreateAction(ctx context.Context, orgID string, action *Action) error {
 	ctx, span := r.tracer.Start(ctx, "repository.create_action")
 	defer span.End()
 
+	action.OrgID = orgParam(orgID)
 	query := `
-		INSERT INTO actions (id, resource_id, action_type, status, checksum, payload, risk_score, estimated_savings)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		WITH created AS (
+			INSERT INTO actions (id, resource_id, action_type, status, checksum, payload, risk_score, estimated_savings, org_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id, resource_id, action_type, status, risk_score, estimated_savings
+		)
+		INSERT INTO event_outbox (event_type, subject_id, data)
+		SELECT 'action.created', id::text,
+			jsonb_build_object(
+				'action_id', id, 'resource_id', resource_id, 'action_type', action_type, 'status', status,
+				'risk_score', risk_score, 'estimated_savings', estimated_savings
+			)
+		FROM created
 	`
 
 	_, err := r.db.Exec(ctx, query,
 		action.ID, action.ResourceID, action.ActionType, action.Status,
-		action.Checksum, action.Payload, action.RiskScore, action.EstimatedSavings,
+		action.Checksum, action.Payload, action.RiskScore, action.EstimatedSavings, action.OrgID,
 	)
 	if err != nil {
 		span.RecordError(err)
@@ -143,43 +204,51 @@ func (r *Repository) CreateAction(ctx context.Context, action *Action) error {
 	return nil
 }
 
-// GetActionByID retrieves an action by ID
-func (r *Repository) GetActionByID(ctx context.Context, id string) (*Action, error) {
+// GetActionByID retrieves an action by ID. Another organization's action is
+// reported as not found.
+func (r *Repository) GetActionByID(ctx context.Context, orgID, id string) (*Action, error) {
 	ctx, span := r.tracer.Start(ctx, "repository.get_action_by_id")
 	defer span.End()
 
-	query := `
-		SELECT id, resource_id, action_type, status, checksum, payload, risk_score, estimated_savings,
-			   created_at, started_at, completed_at, error_message
-		FROM actions WHERE id = $1
-	`
+	query := `SELECT ` + actionColumns + ` FROM actions WHERE id = $1 AND org_id IS NOT DISTINCT FROM $2`
 
-	var action Action
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&action.ID, &action.ResourceID, &action.ActionType, &action.Status,
-		&action.Checksum, &action.Payload, &action.RiskScore, &action.EstimatedSavings,
-		&action.CreatedAt, &action.StartedAt, &action.CompletedAt, &action.ErrorMessage,
-	)
+	action, err := scanAction(r.db.QueryRow(ctx, query, id, orgParam(orgID)))
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return nil, fmt.Errorf("action not found: %s", id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("action %s: %w", id, ErrNotFound)
 		}
 		span.RecordError(err)
 		return nil, fmt.Errorf("failed to get action: %w", err)
 	}
 
-	return &action, nil
+	return action, nil
 }
 
-// UpdateActionStatus updates an action's status
+// UpdateActionStatus updates an action's status. Moving to COMPLETED,
+// FAILED or ROLLED_BACK also writes the matching lifecycle event.
 func (r *Repository) UpdateActionStatus(ctx context.Context, id, status string, startedAt, completedAt *time.Time, errorMessage *string) error {
 	ctx, span := r.tracer.Start(ctx, "repository.update_action_status")
 	defer span.End()
 
 	query := `
-		UPDATE actions 
-		SET status = $2, started_at = $3, completed_at = $4, error_message = $5
-		WHERE id = $1
+		WITH updated AS (
+			UPDATE actions
+			SET status = $2, started_at = $3, completed_at = $4, error_message = $5
+			WHERE id = $1
+			RETURNING id, resource_id, action_type, status, completed_at, error_message
+		)
+		INSERT INTO event_outbox (event_type, subject_id, data)
+		SELECT CASE status
+				WHEN 'COMPLETED' THEN 'action.executed'
+				WHEN 'FAILED' THEN 'action.failed'
+				ELSE 'action.rolled_back'
+			END, id::text,
+			jsonb_build_object(
+				'action_id', id, 'resource_id', resource_id, 'action_type', action_type, 'status', status,
+				'completed_at', completed_at, 'error_message', error_message
+			)
+		FROM updated
+		WHERE status IN ('COMPLETED', 'FAILED', 'ROLLED_BACK')
 	`
 
 	_, err := r.db.Exec(ctx, query, id, status, startedAt, completedAt, errorMessage)
@@ -191,39 +260,160 @@ func (r *Repository) UpdateActionStatus(ctx context.Context, id, status string,
 	return nil
 }
 
-// GetPendingActions retrieves all pending actions
-func (r *Repository) GetPendingActions(ctx context.Context) ([]*Action, error) {
-	ctx, span := r.tracer.Start(ctx, "repository.get_pending_actions")
+// MergeActionPayload adds fields to an action's payload, replacing any
+// top-level keys it already has
+func (r *Repository) MergeActionPayload(ctx context.Context, id string, fields map[string]interface{}) error {
+	ctx, span := r.tracer.Start(ctx, "repository.merge_action_payload")
 	defer span.End()
 
 	query := `
-		SELECT id, resource_id, action_type, status, checksum, payload, risk_score, estimated_savings,
-			   created_at, started_at, completed_at, error_message
-		FROM actions WHERE status = 'PENDING'
-		ORDER BY created_at ASC
-		LIMIT 100
+		UPDATE actions
+		SET payload = COALESCE(payload, '{}'::jsonb) || $2::jsonb
+		WHERE id = $1
 	`
 
-	rows, err := r.db.Query(ctx, query)
+	tag, err := r.db.Exec(ctx, query, id, fields)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to update action payload: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("action %s: %w", id, ErrNotFound)
+	}
+
+	return nil
+}
+
+// ClaimAction atomically moves a PENDING action to IN_PROGRESS. It returns
+// false if the action was already claimed, so concurrent workers and engine
+// instances never execute the same action twice.
+func (r *Repository) ClaimAction(ctx context.Context, id string, startedAt time.Time) (bool, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.claim_action")
+	defer span.End()
+
+	query := `
+		UPDATE actions
+		SET status = 'IN_PROGRESS', started_at = $2
+		WHERE id = $1 AND status = 'PENDING'
+	`
+
+	tag, err := r.db.Exec(ctx, query, id, startedAt)
 	if err != nil {
 		span.RecordError(err)
-		return nil, fmt.Errorf("failed to get pending actions: %w", err)
+		return false, fmt.Errorf("failed to claim action: %w", err)
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// DefaultPendingActionsPageSize is the page size used when none is configured
+const DefaultPendingActionsPageSize = 100
+
+// ActionCursor is a keyset position in the pending-action queue, which is
+// ordered by (created_at, id)
+type ActionCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        string    `json:"id"`
+}
+
+// GetPendingActionsPage retrieves up to limit of an organization's pending
+// actions after the cursor (nil for the first page). The returned cursor is
+// nil once the last page has been read.
+func (r *Repository) GetPendingActionsPage(ctx context.Context, orgID string, after *ActionCursor, limit int) ([]*Action, *ActionCursor, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.get_pending_actions_page")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = DefaultPendingActionsPageSize
+	}
+
+	query := `SELECT ` + actionColumns + ` FROM actions WHERE status = 'PENDING' AND org_id IS NOT DISTINCT FROM $1`
+	args := []interface{}{orgParam(orgID)}
+	if after != nil {
+		query += ` AND (created_at, id::text) > ($2, $3)`
+		args = append(args, after.CreatedAt, after.ID)
+	}
+	query += fmt.Sprintf(` ORDER BY created_at ASC, id::text ASC LIMIT $%d`, len(args)+1)
+	args = append(args, limit)
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		return nil, nil, fmt.Errorf("failed to get pending actions: %w", err)
 	}
 	defer rows.Close()
 
 	var actions []*Action
 	for rows.Next() {
-		var action Action
-		err := rows.Scan(
-			&action.ID, &action.ResourceID, &action.ActionType, &action.Status,
-			&action.Checksum, &action.Payload, &action.RiskScore, &action.EstimatedSavings,
-			&action.CreatedAt, &action.StartedAt, &action.CompletedAt, &action.ErrorMessage,
-		)
+		action, err := scanAction(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, nil, fmt.Errorf("failed to scan action: %w", err)
+		}
+		actions = append(actions, action)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, nil, fmt.Errorf("failed to get pending actions: %w", err)
+	}
+
+	if len(actions) < limit {
+		return actions, nil, nil
+	}
+	last := actions[len(actions)-1]
+	return actions, &ActionCursor{CreatedAt: last.CreatedAt, ID: last.ID}, nil
+}
+
+// GetPendingActions retrieves all of an organization's pending actions,
+// oldest first, reading them in pages of pageSize
+func (r *Repository) GetPendingActions(ctx context.Context, orgID string, pageSize int) ([]*Action, error) {
+	var all []*Action
+	var cursor *ActionCursor
+	for {
+		page, next, err := r.GetPendingActionsPage(ctx, orgID, cursor, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		if next == nil {
+			return all, nil
+		}
+		cursor = next
+	}
+}
+
+// ListRecentActions retrieves up to limit of an organization's actions,
+// newest first. A non-empty status restricts the list to actions in that status.
+func (r *Repository) ListRecentActions(ctx context.Context, orgID, status string, limit int) ([]*Action, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.list_recent_actions")
+	defer span.End()
+
+	if limit <= 0 {
+		limit = DefaultPendingActionsPageSize
+	}
+
+	query := `SELECT ` + actionColumns + ` FROM actions WHERE ($1 = '' OR status = $1) AND org_id IS NOT DISTINCT FROM $3
+		ORDER BY created_at DESC, id::text DESC LIMIT $2`
+
+	rows, err := r.db.Query(ctx, query, status, limit, orgParam(orgID))
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list actions: %w", err)
+	}
+	defer rows.Close()
+
+	actions := []*Action{}
+	for rows.Next() {
+		action, err := scanAction(rows)
 		if err != nil {
 			span.RecordError(err)
 			return nil, fmt.Errorf("failed to scan action: %w", err)
 		}
-		actions = append(actions, &action)
+		actions = append(actions, action)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list actions: %w", err)
 	}
 
 	return actions, nil
@@ -257,12 +447,12 @@ func (r *Repository) RecordTokenUsage(ctx context.Context, usage *TokenUsage) er
 	defer span.End()
 
 	query := `
-		INSERT INTO token_usage (id, model, tokens, cost_usd, request_type)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO token_usage (id, model, tier, tokens, cost_usd, request_type)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
 
 	_, err := r.db.Exec(ctx, query,
-		usage.ID, usage.Model, usage.Tokens, usage.CostUSD, usage.RequestType,
+		usage.ID, usage.Model, usage.Tier, usage.Tokens, usage.CostUSD, usage.RequestType,
 	)
 	if err != nil {
 		span.RecordError(err)
@@ -272,19 +462,97 @@ func (r *Repository) RecordTokenUsage(ctx context.Context, usage *TokenUsage) er
 	return nil
 }
 
-// CreateSavingsEvent creates a new savings event
-func (r *Repository) CreateSavingsEvent(ctx context.Context, event *SavingsEvent) error {
+// RecordTokenUsageBatch records several usage rows in one transaction, so a
+// failed write can be retried without counting any row twice
+func (r *Repository) RecordTokenUsageBatch(ctx context.Context, usages []*TokenUsage) error {
+	ctx, span := r.tracer.Start(ctx, "repository.record_token_usage_batch")
+	defer span.End()
+
+	query := `
+		INSERT INTO token_usage (model, tier, tokens, cost_usd, request_type, requests, savings_usd)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	err := r.db.Transaction(ctx, func(tx pgx.Tx) error {
+		for _, usage := range usages {
+			if _, err := tx.Exec(ctx, query,
+				usage.Model, usage.Tier, usage.Tokens, usage.CostUSD, usage.RequestType, usage.Requests, usage.SavingsUSD,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to record token usage: %w", err)
+	}
+
+	return nil
+}
+
+// GetTokenUsageTotals sums token_usage per model and tier over all time
+func (r *Repository) GetTokenUsageTotals(ctx context.Context) ([]*TokenUsageTotal, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.get_token_usage_totals")
+	defer span.End()
+
+	query := `
+		SELECT model, tier, SUM(tokens), SUM(cost_usd), SUM(requests), SUM(savings_usd), MIN(created_at)
+		FROM token_usage
+		GROUP BY model, tier
+		ORDER BY model, tier
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get token usage totals: %w", err)
+	}
+	defer rows.Close()
+
+	var totals []*TokenUsageTotal
+	for rows.Next() {
+		total := &TokenUsageTotal{}
+		if err := rows.Scan(&total.Model, &total.Tier, &total.Tokens, &total.CostUSD, &total.Requests, &total.SavingsUSD, &total.FirstUsedAt); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan token usage totals: %w", err)
+		}
+		totals = append(totals, total)
+	}
+	if err := rows.Err(); err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get token usage totals: %w", err)
+	}
+
+	return totals, nil
+}
+
+// CreateSavingsEvent records the realized savings of an organization's
+// action ("" for none), together with a savings.verified event
+func (r *Repository) CreateSavingsEvent(ctx context.Context, orgID string, event *SavingsEvent) error {
 	ctx, span := r.tracer.Start(ctx, "repository.create_savings_event")
 	defer span.End()
 
+	event.OrgID = orgParam(orgID)
 	query := `
-		INSERT INTO savings_events (id, action_id, resource_id, optimization_type, estimated_savings, actual_savings)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		WITH created AS (
+			INSERT INTO savings_events (id, action_id, resource_id, optimization_type, estimated_savings, actual_savings, org_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, action_id, resource_id, optimization_type, estimated_savings, actual_savings
+		)
+		INSERT INTO event_outbox (event_type, subject_id, data)
+		SELECT 'savings.verified', id::text,
+			jsonb_build_object(
+				'savings_event_id', id, 'action_id', action_id, 'resource_id', resource_id,
+				'optimization_type', optimization_type, 'estimated_savings', estimated_savings,
+				'actual_savings', actual_savings
+			)
+		FROM created
 	`
 
 	_, err := r.db.Exec(ctx, query,
 		event.ID, event.ActionID, event.ResourceID, event.OptimizationType,
-		event.EstimatedSavings, event.ActualSavings,
+		event.EstimatedSavings, event.ActualSavings, event.OrgID,
 	)
 	if err != nil {
 		span.RecordError(err)
@@ -304,7 +572,7 @@ func (r *Repository) GetTokenUsageStats(ctx context.Context, timeRange time.Dura
 			model,
 			SUM(tokens) as total_tokens,
 			SUM(cost_usd) as total_cost,
-			COUNT(*) as request_count
+			SUM(requests) as request_count
 		FROM token_usage 
 		WHERE created_at >= NOW() - INTERVAL '1 hour' * $1
 		GROUP BY model
@@ -0,0 +1,71 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// purgeColumns maps each table the retention job may purge to the timestamp
+// column its age is measured by. Anything else is rejected so the table name
+// can be interpolated into queries safely.
+var purgeColumns = map[string]string{
+	"token_usage":         "created_at",
+	"ai_decisions":        "created_at",
+	"audit_log":           "created_at",
+	"savings_events":      "created_at",
+	"inventory_snapshots": "captured_at",
+	"event_outbox":        "delivered_at", // undelivered events are never purged
+}
+
+func purgeColumn(table string) (string, error) {
+	column, ok := purgeColumns[table]
+	if !ok {
+		return "", fmt.Errorf("table %q does not support retention purges", table)
+	}
+	return column, nil
+}
+
+// CountBefore counts the rows of a history table older than before
+func (r *Repository) CountBefore(ctx context.Context, table string, before time.Time) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.count_before")
+	defer span.End()
+
+	column, err := purgeColumn(table)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s WHERE %s < $1`, table, column)
+	if err := r.db.QueryRow(ctx, query, before).Scan(&count); err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to count %s rows: %w", table, err)
+	}
+	return count, nil
+}
+
+// DeleteBatchBefore deletes up to limit rows of a history table older than
+// before and returns how many were deleted. Deleting in bounded batches keeps
+// each statement's locks short.
+func (r *Repository) DeleteBatchBefore(ctx context.Context, table string, before time.Time, limit int) (int64, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.delete_batch_before")
+	defer span.End()
+
+	column, err := purgeColumn(table)
+	if err != nil {
+		return 0, err
+	}
+
+	query := fmt.Sprintf(`
+		DELETE FROM %[1]s WHERE id IN (
+			SELECT id FROM %[1]s WHERE %[2]s < $1 LIMIT $2
+		)
+	`, table, column)
+	tag, err := r.db.Exec(ctx, query, before, limit)
+	if err != nil {
+		span.RecordError(err)
+		return 0, fmt.Errorf("failed to purge %s rows: %w", table, err)
+	}
+	return tag.RowsAffected(), nil
+}
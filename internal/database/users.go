@@ -0,0 +1,309 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	// ErrNotFound is returned when the requested row does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a write violates a uniqueness constraint
+	ErrConflict = errors.New("already exists")
+)
+
+// User lifecycle states
+const (
+	UserStatusInvited  = "invited"
+	UserStatusActive   = "active"
+	UserStatusDisabled = "disabled"
+)
+
+const userColumns = `id, email, org_id, role, status, password_hash, invite_expires_at, created_at, updated_at, last_login`
+
+// wrapWriteError maps PostgreSQL unique violations to ErrConflict
+func wrapWriteError(err error, format string, args ...interface{}) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), ErrConflict)
+	}
+	return fmt.Errorf("%s: %w", fmt.Sprintf(format, args...), err)
+}
+
+func scanUser(row pgx.Row) (*User, error) {
+	var user User
+	err := row.Scan(
+		&user.ID, &user.Email, &user.OrganizationID, &user.Role, &user.Status,
+		&user.PasswordHash, &user.InviteExpiresAt, &user.CreatedAt, &user.UpdatedAt, &user.LastLogin,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateOrganization creates a new organization
+func (r *Repository) CreateOrganization(ctx context.Context, org *Organization) error {
+	ctx, span := r.tracer.Start(ctx, "repository.create_organization")
+	defer span.End()
+
+	query := `
+		INSERT INTO organizations (name, settings)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query, org.Name, org.Settings).Scan(&org.ID, &org.CreatedAt)
+	if err != nil {
+		span.RecordError(err)
+		return wrapWriteError(err, "failed to create organization %q", org.Name)
+	}
+
+	return nil
+}
+
+// GetOrganization retrieves an organization by ID
+func (r *Repository) GetOrganization(ctx context.Context, id string) (*Organization, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.get_organization")
+	defer span.End()
+
+	query := `SELECT id, name, created_at, updated_at, settings FROM organizations WHERE id = $1`
+
+	var org Organization
+	err := r.db.QueryRow(ctx, query, id).Scan(&org.ID, &org.Name, &org.CreatedAt, &org.UpdatedAt, &org.Settings)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("organization %s: %w", id, ErrNotFound)
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get organization: %w", err)
+	}
+
+	return &org, nil
+}
+
+// ListOrganizations retrieves all organizations ordered by name
+func (r *Repository) ListOrganizations(ctx context.Context) ([]*Organization, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.list_organizations")
+	defer span.End()
+
+	query := `SELECT id, name, created_at, updated_at, settings FROM organizations ORDER BY name ASC`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list organizations: %w", err)
+	}
+	defer rows.Close()
+
+	var orgs []*Organization
+	for rows.Next() {
+		var org Organization
+		if err := rows.Scan(&org.ID, &org.Name, &org.CreatedAt, &org.UpdatedAt, &org.Settings); err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		orgs = append(orgs, &org)
+	}
+
+	return orgs, nil
+}
+
+// UpdateOrganization updates an organization's name and settings
+func (r *Repository) UpdateOrganization(ctx context.Context, org *Organization) error {
+	ctx, span := r.tracer.Start(ctx, "repository.update_organization")
+	defer span.End()
+
+	query := `
+		UPDATE organizations
+		SET name = $2, settings = $3, updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query, org.ID, org.Name, org.Settings).Scan(&org.CreatedAt, &org.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("organization %s: %w", org.ID, ErrNotFound)
+		}
+		span.RecordError(err)
+		return wrapWriteError(err, "failed to update organization %s", org.ID)
+	}
+
+	return nil
+}
+
+// CreateUser creates a new user. PasswordHash must already be hashed by the security manager.
+func (r *Repository) CreateUser(ctx context.Context, user *User) error {
+	ctx, span := r.tracer.Start(ctx, "repository.create_user")
+	defer span.End()
+
+	if user.Status == "" {
+		user.Status = UserStatusActive
+	}
+
+	query := `
+		INSERT INTO users (email, org_id, role, status, password_hash)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(ctx, query,
+		strings.ToLower(user.Email), user.OrganizationID, user.Role, user.Status, user.PasswordHash,
+	).Scan(&user.ID, &user.CreatedAt)
+	if err != nil {
+		span.RecordError(err)
+		return wrapWriteError(err, "failed to create user %q", user.Email)
+	}
+
+	return nil
+}
+
+// InviteUser creates a user in the invited state. Only the SHA-256 of the
+// invitation token is stored; the caller hands the raw token to the invitee.
+func (r *Repository) InviteUser(ctx context.Context, user *User, inviteTokenHash string, expiresAt time.Time) error {
+	ctx, span := r.tracer.Start(ctx, "repository.invite_user")
+	defer span.End()
+
+	query := `
+		INSERT INTO users (email, org_id, role, status, invite_token_hash, invite_expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	user.Status = UserStatusInvited
+	user.InviteExpiresAt = &expiresAt
+	err := r.db.QueryRow(ctx, query,
+		strings.ToLower(user.Email), user.OrganizationID, user.Role, user.Status, inviteTokenHash, expiresAt,
+	).Scan(&user.ID, &user.CreatedAt)
+	if err != nil {
+		span.RecordError(err)
+		return wrapWriteError(err, "failed to invite user %q", user.Email)
+	}
+
+	return nil
+}
+
+// AcceptInvite activates an invited user, setting their password and
+// consuming the invitation token
+func (r *Repository) AcceptInvite(ctx context.Context, inviteTokenHash, passwordHash string) (*User, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.accept_invite")
+	defer span.End()
+
+	query := `
+		UPDATE users
+		SET status = 'active', password_hash = $2, invite_token_hash = NULL,
+			invite_expires_at = NULL, updated_at = NOW()
+		WHERE invite_token_hash = $1 AND status = 'invited' AND invite_expires_at > NOW()
+		RETURNING ` + userColumns
+
+	user, err := scanUser(r.db.QueryRow(ctx, query, inviteTokenHash, passwordHash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("invitation invalid or expired: %w", ErrNotFound)
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to accept invitation: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetUserByID retrieves a user by ID
+func (r *Repository) GetUserByID(ctx context.Context, id string) (*User, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.get_user_by_id")
+	defer span.End()
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	user, err := scanUser(r.db.QueryRow(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("user %s: %w", id, ErrNotFound)
+		}
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// ListUsers retrieves users, optionally restricted to one organization
+func (r *Repository) ListUsers(ctx context.Context, orgID string) ([]*User, error) {
+	ctx, span := r.tracer.Start(ctx, "repository.list_users")
+	defer span.End()
+
+	query := `SELECT ` + userColumns + ` FROM users`
+	var args []interface{}
+	if orgID != "" {
+		query += ` WHERE org_id = $1`
+		args = append(args, orgID)
+	}
+	query += ` ORDER BY email ASC`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	var users []*User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// UpdateUser updates a user's organization, role and status
+func (r *Repository) UpdateUser(ctx context.Context, user *User) error {
+	ctx, span := r.tracer.Start(ctx, "repository.update_user")
+	defer span.End()
+
+	query := `
+		UPDATE users
+		SET org_id = $2, role = $3, status = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING ` + userColumns
+
+	updated, err := scanUser(r.db.QueryRow(ctx, query, user.ID, user.OrganizationID, user.Role, user.Status))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("user %s: %w", user.ID, ErrNotFound)
+		}
+		span.RecordError(err)
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+
+	*user = *updated
+	return nil
+}
+
+// SetUserPassword replaces a user's password hash
+func (r *Repository) SetUserPassword(ctx context.Context, id, passwordHash string) error {
+	ctx, span := r.tracer.Start(ctx, "repository.set_user_password")
+	defer span.End()
+
+	tag, err := r.db.Exec(ctx, `UPDATE users SET password_hash = $2, updated_at = NOW() WHERE id = $1`, id, passwordHash)
+	if err != nil {
+		span.RecordError(err)
+		return fmt.Errorf("failed to set user password: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("user %s: %w", id, ErrNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package deployment
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/dynamic"
+)
+
+// fieldManager owns the fields DeployToKubernetes applies
+const fieldManager = "talos"
+
+// applyOrder is the order kinds are applied in, so namespaces and
+// configuration exist before the workloads that use them. Other kinds go last.
+var applyOrder = map[string]int{
+	"Namespace":               0,
+	"ConfigMap":               1,
+	"Secret":                  1,
+	"Service":                 2,
+	"Deployment":              3,
+	"HorizontalPodAutoscaler": 4,
+}
+
+// DeployOptions controls DeployToKubernetes
+type DeployOptions struct {
+	// DryRun has the API server validate each object without persisting
+	// it. A namespace that does not exist yet is not created, so objects
+	// in it fail the dry run.
+	DryRun bool
+}
+
+// ApplyResult is the outcome of applying one object from the manifests
+type ApplyResult struct {
+	File      string
+	Kind      string
+	Name      string
+	Namespace string // empty for cluster-scoped objects
+	Action    string // "created" or "configured"; empty if Err is set
+	Err       error
+}
+
+func (r ApplyResult) String() string {
+	if r.Err != nil {
+		return fmt.Sprintf("%s/%s failed: %v", r.Kind, r.Name, r.Err)
+	}
+	return fmt.Sprintf("%s/%s %s", r.Kind, r.Name, r.Action)
+}
+
+// manifestObject is one object decoded from a manifest file
+type manifestObject struct {
+	file string
+	obj  *unstructured.Unstructured
+}
+
+// DeployToKubernetes server-side applies every object in the manifests,
+// namespaced objects going into the manager's namespace. An object that fails
+// does not stop the rest; the error counts the failures and the results say
+// which. Cancelling ctx stops before the next object.
+func (dm *DeploymentManager) DeployToKubernetes(ctx context.Context, manifests map[string][]byte, opts DeployOptions) ([]ApplyResult, error) {
+	objects, err := decodeManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]ApplyResult, 0, len(objects))
+	failed := 0
+	for _, object := range objects {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		result := dm.apply(ctx, object, opts)
+		if result.Err != nil {
+			failed++
+		}
+		results = append(results, result)
+	}
+
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d objects failed to apply", failed, len(results))
+	}
+	return results, nil
+}
+
+// apply creates or updates one object
+func (dm *DeploymentManager) apply(ctx context.Context, object manifestObject, opts DeployOptions) ApplyResult {
+	obj := object.obj
+	result := ApplyResult{File: object.file, Kind: obj.GetKind(), Name: obj.GetName()}
+
+	gvk := obj.GroupVersionKind()
+	mapping, err := dm.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		result.Err = fmt.Errorf("unknown resource %s: %w", gvk, err)
+		return result
+	}
+
+	var client dynamic.ResourceInterface = dm.dynamicClient.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		obj.SetNamespace(dm.namespace)
+		result.Namespace = dm.namespace
+		client = dm.dynamicClient.Resource(mapping.Resource).Namespace(dm.namespace)
+	}
+
+	// Apply creates and updates alike; the lookup is only for the report
+	action := "configured"
+	if _, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		action = "created"
+	} else if err != nil {
+		result.Err = fmt.Errorf("failed to get %s: %w", obj.GetName(), err)
+		return result
+	}
+
+	if _, err := client.Apply(ctx, obj.GetName(), obj, applyOptions(opts)); err != nil {
+		result.Err = fmt.Errorf("failed to apply %s: %w", obj.GetName(), err)
+		return result
+	}
+	result.Action = action
+	return result
+}
+
+// applyOptions forces ownership of conflicting fields, as
+// kubectl apply --server-side --force-conflicts does, so edits made by hand
+// are overwritten by the manifests
+func applyOptions(opts DeployOptions) metav1.ApplyOptions {
+	options := metav1.ApplyOptions{FieldManager: fieldManager, Force: true}
+	if opts.DryRun {
+		options.DryRun = []string{metav1.DryRunAll}
+	}
+	return options
+}
+
+// decodeManifests decodes every object in the manifests, in apply order
+func decodeManifests(manifests map[string][]byte) ([]manifestObject, error) {
+	files := make([]string, 0, len(manifests))
+	for file := range manifests {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var objects []manifestObject
+	for _, file := range files {
+		decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(manifests[file]), 4096)
+		for {
+			var raw runtime.RawExtension
+			err := decoder.Decode(&raw)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode manifest %s: %w", file, err)
+			}
+			if raw.Raw = bytes.TrimSpace(raw.Raw); len(raw.Raw) == 0 || string(raw.Raw) == "null" {
+				continue // empty document
+			}
+
+			// Unstructured's own decoding keeps integers as int64
+			obj := &unstructured.Unstructured{}
+			if err := obj.UnmarshalJSON(raw.Raw); err != nil {
+				return nil, fmt.Errorf("failed to decode manifest %s: %w", file, err)
+			}
+			if obj.GetName() == "" {
+				return nil, fmt.Errorf("manifest %s has a %s without a name", file, obj.GetKind())
+			}
+			objects = append(objects, manifestObject{file: file, obj: obj})
+		}
+	}
+
+	sort.SliceStable(objects, func(i, j int) bool {
+		return kindOrder(objects[i].obj.GetKind()) < kindOrder(objects[j].obj.GetKind())
+	})
+	return objects, nil
+}
+
+func kindOrder(kind string) int {
+	if order, ok := applyOrder[kind]; ok {
+		return order
+	}
+	return len(applyOrder)
+}
@@ -0,0 +1,150 @@
+package deployment
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeDeploymentManager returns a manager whose dynamic client writes to
+// the returned clientset, which also serves discovery for the kinds the
+// manifests use
+func newFakeDeploymentManager(namespace string) (*DeploymentManager, *fake.Clientset) {
+	clientset := fake.NewClientset()
+	clientset.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "namespaces", Kind: "Namespace"},
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+			{Name: "secrets", Kind: "Secret", Namespaced: true},
+			{Name: "services", Kind: "Service", Namespaced: true},
+		}},
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{
+			{Name: "deployments", Kind: "Deployment", Namespaced: true},
+		}},
+		{GroupVersion: "autoscaling/v2", APIResources: []metav1.APIResource{
+			{Name: "horizontalpodautoscalers", Kind: "HorizontalPodAutoscaler", Namespaced: true},
+		}},
+	}
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(scheme.Scheme, nil)
+	dynamicClient.PrependReactor("*", "*", k8stesting.ObjectReaction(clientset.Tracker()))
+	return newDeploymentManager(clientset, dynamicClient, namespace, nil), clientset
+}
+
+// validManifests is GenerateKubernetesManifests with the placeholder secret
+// values, which are not valid base64, dropped
+func validManifests(t *testing.T, dm *DeploymentManager) map[string][]byte {
+	manifests, err := dm.GenerateKubernetesManifests()
+	require.NoError(t, err)
+	delete(manifests, "secret.yaml")
+	return manifests
+}
+
+func TestDeployToKubernetes_CreatesThenUpdates(t *testing.T) {
+	ctx := context.Background()
+	dm, clientset := newFakeDeploymentManager("talos")
+	manifests := validManifests(t, dm)
+
+	results, err := dm.DeployToKubernetes(ctx, manifests, DeployOptions{})
+	require.NoError(t, err)
+	require.Len(t, results, 7)
+	assert.Equal(t, "Namespace", results[0].Kind, "namespace first")
+	assert.Equal(t, "HorizontalPodAutoscaler", results[len(results)-1].Kind, "autoscaler last")
+	for _, result := range results {
+		assert.Equal(t, "created", result.Action, result.String())
+	}
+
+	_, err = clientset.CoreV1().Namespaces().Get(ctx, "talos", metav1.GetOptions{})
+	require.NoError(t, err)
+	dashboard, err := clientset.AppsV1().Deployments("talos").Get(ctx, "dashboard", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), *dashboard.Spec.Replicas)
+	configMap, err := clientset.CoreV1().ConfigMaps("talos").Get(ctx, "talos-config", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "redis:6379", configMap.Data["REDIS_ADDRESS"])
+	_, err = clientset.AutoscalingV2().HorizontalPodAutoscalers("talos").Get(ctx, "dashboard-hpa", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// Applying again updates in place rather than duplicating
+	manifests["dashboard-deployment.yaml"] = bytes.Replace(manifests["dashboard-deployment.yaml"], []byte("replicas: 3"), []byte("replicas: 5"), 1)
+	results, err = dm.DeployToKubernetes(ctx, manifests, DeployOptions{})
+	require.NoError(t, err)
+	for _, result := range results {
+		assert.Equal(t, "configured", result.Action, result.String())
+	}
+
+	deployments, err := clientset.AppsV1().Deployments("talos").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, deployments.Items, 2)
+	dashboard, err = clientset.AppsV1().Deployments("talos").Get(ctx, "dashboard", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(5), *dashboard.Spec.Replicas)
+}
+
+func TestDeployToKubernetes_ReportsEachFailure(t *testing.T) {
+	dm, clientset := newFakeDeploymentManager("talos")
+	manifests := validManifests(t, dm)
+	manifests["crd.yaml"] = []byte("apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: gadget\n")
+
+	results, err := dm.DeployToKubernetes(context.Background(), manifests, DeployOptions{})
+	assert.EqualError(t, err, "1 of 8 objects failed to apply")
+	require.Len(t, results, 8)
+
+	failed := results[len(results)-1]
+	assert.Equal(t, "Widget", failed.Kind)
+	assert.Error(t, failed.Err)
+	assert.Empty(t, failed.Action)
+
+	// The other objects were still applied
+	_, err = clientset.AppsV1().Deployments("talos").Get(context.Background(), "dashboard", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestDeployToKubernetes_UsesManagerNamespace(t *testing.T) {
+	source := &DeploymentManager{namespace: "talos"}
+	dm, clientset := newFakeDeploymentManager("talos-staging")
+
+	_, err := dm.DeployToKubernetes(context.Background(), validManifests(t, source), DeployOptions{})
+	require.NoError(t, err)
+
+	_, err = clientset.AppsV1().Deployments("talos-staging").Get(context.Background(), "dashboard", metav1.GetOptions{})
+	assert.NoError(t, err)
+	_, err = clientset.AppsV1().Deployments("talos").Get(context.Background(), "dashboard", metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestDeployToKubernetes_StopsWhenCancelled(t *testing.T) {
+	dm, clientset := newFakeDeploymentManager("talos")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := dm.DeployToKubernetes(ctx, validManifests(t, dm), DeployOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, results)
+	assert.Empty(t, clientset.Actions())
+}
+
+func TestDeployToKubernetes_RejectsBadManifest(t *testing.T) {
+	dm, _ := newFakeDeploymentManager("talos")
+
+	_, err := dm.DeployToKubernetes(context.Background(), map[string][]byte{"bad.yaml": []byte("kind: ConfigMap\napiVersion: v1\nmetadata: {}\n")}, DeployOptions{})
+	assert.Error(t, err)
+}
+
+func TestApplyOptions(t *testing.T) {
+	options := applyOptions(DeployOptions{})
+	assert.Equal(t, fieldManager, options.FieldManager)
+	assert.True(t, options.Force)
+	assert.Empty(t, options.DryRun)
+
+	// The fake clients drop apply options, so dry run is checked here
+	assert.Equal(t, []string{metav1.DryRunAll}, applyOptions(DeployOptions{DryRun: true}).DryRun)
+}
@@ -1,21 +1,27 @@
 package deployment
 
 import (
-	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/restmapper"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
 // DeploymentManager handles application deployment
 type DeploymentManager struct {
-	kubeClient *kubernetes.Clientset
-	namespace  string
-	logger     interface{} // Simplified logger interface
+	kubeClient    kubernetes.Interface
+	dynamicClient dynamic.Interface
+	mapper        meta.RESTMapper
+	namespace     string
+	logger        interface{} // Simplified logger interface
 }
 
 // NewDeploymentManager creates a new deployment manager
@@ -30,11 +36,41 @@ func NewDeploymentManager(kubeconfigPath, namespace string, logger interface{})
 		return nil, fmt.Errorf("failed to create kubernetes client: %w", err)
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic kubernetes client: %w", err)
+	}
+
+	return newDeploymentManager(clientset, dynamicClient, namespace, logger), nil
+}
+
+// newDeploymentManager creates a deployment manager around the given clients.
+// Resource mappings are discovered through kubeClient when first needed.
+func newDeploymentManager(kubeClient kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, logger interface{}) *DeploymentManager {
 	return &DeploymentManager{
-		kubeClient: clientset,
-		namespace:  namespace,
-		logger:     logger,
-	}, nil
+		kubeClient:    kubeClient,
+		dynamicClient: dynamicClient,
+		mapper:        restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(kubeClient.Discovery())),
+		namespace:     namespace,
+		logger:        logger,
+	}
+}
+
+// Values shared by the Kubernetes manifests and the Terraform configuration
+const (
+	dashboardImage    = "talos/dashboard:latest"
+	dashboardReplicas = 3
+)
+
+// talosConfig is the talos-config ConfigMap, in the order it is written
+var talosConfig = []struct{ key, value string }{
+	{"MODE", "production"},
+	{"REDIS_ADDRESS", "redis:6379"},
+	{"DB_HOST", "postgres"},
+	{"DB_PORT", "5432"},
+	{"DB_NAME", "talos"},
+	{"CLOUD_PROVIDER", "aws"},
+	{"CLOUD_REGION", "us-east-1"},
 }
 
 // DockerComposeConfig represents Docker Compose configuration
@@ -141,7 +177,6 @@ func (dm *DeploymentManager) GenerateDockerCompose() (*DockerComposeConfig, erro
 			"DB_HOST":        "postgres",
 			"DB_PORT":        "5432",
 			"DB_NAME":        "talos",
-			"WORKER_ID":      "${WORKER_ID:-worker-1}",
 			"CLOUD_PROVIDER": "${CLOUD_PROVIDER:-aws}",
 			"CLOUD_REGION":   "${CLOUD_REGION:-us-east-1}",
 			"CLOUD_DRY_RUN":  "${CLOUD_DRY_RUN:-true}",
@@ -265,21 +300,18 @@ metadata:
 	manifests["namespace.yaml"] = []byte(namespaceData)
 
 	// ConfigMap
-	configMapData := fmt.Sprintf(`apiVersion: v1
+	var configMapData strings.Builder
+	fmt.Fprintf(&configMapData, `apiVersion: v1
 kind: ConfigMap
 metadata:
   name: talos-config
   namespace: %s
 data:
-  MODE: "production"
-  REDIS_ADDRESS: "redis:6379"
-  DB_HOST: "postgres"
-  DB_PORT: "5432"
-  DB_NAME: "talos"
-  CLOUD_PROVIDER: "aws"
-  CLOUD_REGION: "us-east-1"
 `, dm.namespace)
-	manifests["configmap.yaml"] = []byte(configMapData)
+	for _, entry := range talosConfig {
+		fmt.Fprintf(&configMapData, "  %s: %q\n", entry.key, entry.value)
+	}
+	manifests["configmap.yaml"] = []byte(configMapData.String())
 
 	// Secret
 	secretData := fmt.Sprintf(`apiVersion: v1
@@ -349,7 +381,7 @@ metadata:
   name: dashboard
   namespace: %s
 spec:
-  replicas: 3
+  replicas: %d
   selector:
     matchLabels:
       app: dashboard
@@ -360,7 +392,7 @@ spec:
     spec:
       containers:
       - name: dashboard
-        image: talos/dashboard:latest
+        image: %s
         ports:
         - containerPort: 8080
         env:
@@ -392,7 +424,7 @@ spec:
             port: 8080
           initialDelaySeconds: 5
           periodSeconds: 5
-`, dm.namespace)
+`, dm.namespace, dashboardReplicas, dashboardImage)
 	manifests["dashboard-deployment.yaml"] = []byte(dashboardDeployment)
 
 	// Services
@@ -464,30 +496,21 @@ func (dm *DeploymentManager) SaveKubernetesManifests(manifests map[string][]byte
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	for filename, data := range manifests {
+	return writeFiles(manifests, outputDir)
+}
+
+// writeFiles writes each file into outputDir
+func writeFiles(files map[string][]byte, outputDir string) error {
+	for filename, data := range files {
 		path := filepath.Join(outputDir, filename)
 		if err := os.WriteFile(path, data, 0644); err != nil {
-			return fmt.Errorf("failed to write manifest %s: %w", filename, err)
+			return fmt.Errorf("failed to write %s: %w", filename, err)
 		}
 	}
 
 	return nil
 }
 
-// DeployToKubernetes deploys the application to Kubernetes
-func (dm *DeploymentManager) DeployToKubernetes(ctx context.Context, manifests map[string][]byte) error {
-	// This would implement actual Kubernetes deployment
-	// For now, it's a placeholder that would use the kubernetes client
-	// to create/update resources in the cluster
-
-	fmt.Println("Deploying to Kubernetes...")
-	for filename := range manifests {
-		fmt.Printf("  - %s\n", filename)
-	}
-
-	return nil
-}
-
 // GenerateDeploymentPackage creates a complete deployment package
 func (dm *DeploymentManager) GenerateDeploymentPackage(outputDir string) error {
 	// Create output directory
@@ -516,6 +539,11 @@ func (dm *DeploymentManager) GenerateDeploymentPackage(outputDir string) error {
 		return fmt.Errorf("failed to save kubernetes manifests: %w", err)
 	}
 
+	// Generate Terraform configuration
+	if err := dm.GenerateTerraform(filepath.Join(outputDir, "terraform")); err != nil {
+		return fmt.Errorf("failed to generate terraform: %w", err)
+	}
+
 	// Generate environment file
 	envFile := `# Environment Configuration
 PORT=8080
@@ -652,6 +680,16 @@ For Kubernetes deployment, use the manifests in the kubernetes/ directory:
 bash
 kubectl apply -f kubernetes/
 
+### Terraform Deployment
+
+The terraform/ directory holds the same Kubernetes resources as Terraform
+configuration. Create the talos-secrets Secret first, then:
+
+bash
+cd terraform
+terraform init
+terraform apply -var image=talos/dashboard:latest -var namespace=talos -var replicas=3
+
 ### Monitoring
 
 - **Prometheus**: Metrics collection and alerting
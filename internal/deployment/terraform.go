@@ -0,0 +1,337 @@
+package deployment
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// terraformVersions pins the Kubernetes provider; 2.16 added the
+// autoscaling/v2 HPA resource. Provider configuration (kubeconfig, context)
+// is left to the caller's root module.
+const terraformVersions = `terraform {
+  required_providers {
+    kubernetes = {
+      source  = "hashicorp/kubernetes"
+      version = ">= 2.16"
+    }
+  }
+}
+`
+
+// terraformRedis is redis-deployment.yaml and redis-service.yaml
+const terraformRedis = `resource "kubernetes_deployment" "redis" {
+  metadata {
+    name      = "redis"
+    namespace = kubernetes_namespace.talos.metadata[0].name
+  }
+
+  spec {
+    replicas = 1
+
+    selector {
+      match_labels = {
+        app = "redis"
+      }
+    }
+
+    template {
+      metadata {
+        labels = {
+          app = "redis"
+        }
+      }
+
+      spec {
+        container {
+          name  = "redis"
+          image = "redis:7-alpine"
+
+          port {
+            container_port = 6379
+          }
+
+          resources {
+            requests = {
+              memory = "128Mi"
+              cpu    = "100m"
+            }
+            limits = {
+              memory = "256Mi"
+              cpu    = "250m"
+            }
+          }
+
+          liveness_probe {
+            exec {
+              command = ["redis-cli", "ping"]
+            }
+            initial_delay_seconds = 30
+            period_seconds        = 10
+          }
+
+          readiness_probe {
+            exec {
+              command = ["redis-cli", "ping"]
+            }
+            initial_delay_seconds = 5
+            period_seconds        = 5
+          }
+        }
+      }
+    }
+  }
+}
+
+resource "kubernetes_service" "redis" {
+  metadata {
+    name      = "redis"
+    namespace = kubernetes_namespace.talos.metadata[0].name
+  }
+
+  spec {
+    selector = {
+      app = "redis"
+    }
+
+    port {
+      port        = 6379
+      target_port = 6379
+    }
+
+    type = "ClusterIP"
+  }
+}
+`
+
+// terraformDashboard is dashboard-deployment.yaml, dashboard-service.yaml and
+// hpa.yaml. The talos-secrets Secret is not managed here so its values stay
+// out of Terraform state.
+const terraformDashboard = `resource "kubernetes_deployment" "dashboard" {
+  metadata {
+    name      = "dashboard"
+    namespace = kubernetes_namespace.talos.metadata[0].name
+  }
+
+  spec {
+    replicas = var.replicas
+
+    selector {
+      match_labels = {
+        app = "dashboard"
+      }
+    }
+
+    template {
+      metadata {
+        labels = {
+          app = "dashboard"
+        }
+      }
+
+      spec {
+        container {
+          name  = "dashboard"
+          image = var.image
+
+          port {
+            container_port = 8080
+          }
+
+          env {
+            name  = "PORT"
+            value = "8080"
+          }
+          env {
+            name  = "REDIS_ADDRESS"
+            value = "redis:6379"
+          }
+
+          env_from {
+            config_map_ref {
+              name = kubernetes_config_map.talos_config.metadata[0].name
+            }
+          }
+          env_from {
+            secret_ref {
+              name = "talos-secrets"
+            }
+          }
+
+          resources {
+            requests = {
+              memory = "256Mi"
+              cpu    = "250m"
+            }
+            limits = {
+              memory = "512Mi"
+              cpu    = "500m"
+            }
+          }
+
+          liveness_probe {
+            http_get {
+              path = "/health"
+              port = 8080
+            }
+            initial_delay_seconds = 30
+            period_seconds        = 10
+          }
+
+          readiness_probe {
+            http_get {
+              path = "/health"
+              port = 8080
+            }
+            initial_delay_seconds = 5
+            period_seconds        = 5
+          }
+        }
+      }
+    }
+  }
+
+  # The autoscaler owns the replica count once the deployment exists
+  lifecycle {
+    ignore_changes = [spec[0].replicas]
+  }
+}
+
+resource "kubernetes_service" "dashboard" {
+  metadata {
+    name      = "dashboard"
+    namespace = kubernetes_namespace.talos.metadata[0].name
+  }
+
+  spec {
+    selector = {
+      app = "dashboard"
+    }
+
+    port {
+      port        = 8080
+      target_port = 8080
+    }
+
+    type = "LoadBalancer"
+  }
+}
+
+resource "kubernetes_horizontal_pod_autoscaler_v2" "dashboard" {
+  metadata {
+    name      = "dashboard-hpa"
+    namespace = kubernetes_namespace.talos.metadata[0].name
+  }
+
+  spec {
+    min_replicas = 2
+    max_replicas = 10
+
+    scale_target_ref {
+      api_version = "apps/v1"
+      kind        = "Deployment"
+      name        = kubernetes_deployment.dashboard.metadata[0].name
+    }
+
+    metric {
+      type = "Resource"
+      resource {
+        name = "cpu"
+        target {
+          type                = "Utilization"
+          average_utilization = 70
+        }
+      }
+    }
+
+    metric {
+      type = "Resource"
+      resource {
+        name = "memory"
+        target {
+          type                = "Utilization"
+          average_utilization = 80
+        }
+      }
+    }
+  }
+}
+`
+
+// GenerateTerraformFiles generates Terraform configuration for the resources
+// GenerateKubernetesManifests describes, with the image, namespace and
+// dashboard replicas as variables defaulting to the manifests' values
+func (dm *DeploymentManager) GenerateTerraformFiles() (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	files["versions.tf"] = []byte(terraformVersions)
+
+	files["variables.tf"] = []byte(fmt.Sprintf(`variable "image" {
+  description = "Dashboard container image"
+  type        = string
+  default     = %q
+}
+
+variable "namespace" {
+  description = "Namespace the resources are created in"
+  type        = string
+  default     = %q
+}
+
+variable "replicas" {
+  description = "Dashboard replicas before the autoscaler takes over"
+  type        = number
+  default     = %d
+}
+`, dashboardImage, dm.namespace, dashboardReplicas))
+
+	// Namespace and ConfigMap
+	width := 0
+	for _, entry := range talosConfig {
+		if len(entry.key) > width {
+			width = len(entry.key)
+		}
+	}
+	var namespaceData strings.Builder
+	namespaceData.WriteString(`resource "kubernetes_namespace" "talos" {
+  metadata {
+    name = var.namespace
+    labels = {
+      name = var.namespace
+    }
+  }
+}
+
+resource "kubernetes_config_map" "talos_config" {
+  metadata {
+    name      = "talos-config"
+    namespace = kubernetes_namespace.talos.metadata[0].name
+  }
+
+  data = {
+`)
+	for _, entry := range talosConfig {
+		fmt.Fprintf(&namespaceData, "    %-*s = %q\n", width, entry.key, entry.value)
+	}
+	namespaceData.WriteString("  }\n}\n")
+	files["namespace.tf"] = []byte(namespaceData.String())
+
+	files["redis.tf"] = []byte(terraformRedis)
+	files["dashboard.tf"] = []byte(terraformDashboard)
+
+	return files, nil
+}
+
+// GenerateTerraform writes the Terraform configuration into outputDir
+func (dm *DeploymentManager) GenerateTerraform(outputDir string) error {
+	files, err := dm.GenerateTerraformFiles()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	return writeFiles(files, outputDir)
+}
@@ -0,0 +1,125 @@
+package deployment
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkHCLDelimiters reports the first unbalanced brace, bracket or string in
+// src. It is a syntax smoke test for when terraform is not installed.
+func checkHCLDelimiters(t *testing.T, name string, src []byte) {
+	t.Helper()
+	var open []byte
+	closing := map[byte]byte{'}': '{', ']': '[', ')': '('}
+	line := 1
+	for i := 0; i < len(src); i++ {
+		switch c := src[i]; c {
+		case '\n':
+			line++
+		case '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			line++
+		case '"':
+			for i++; i < len(src) && src[i] != '"'; i++ {
+				if src[i] == '\\' {
+					i++
+				} else if src[i] == '\n' {
+					t.Fatalf("%s:%d: unterminated string", name, line)
+				}
+			}
+			if i == len(src) {
+				t.Fatalf("%s:%d: unterminated string", name, line)
+			}
+		case '{', '[', '(':
+			open = append(open, c)
+		case '}', ']', ')':
+			if len(open) == 0 || open[len(open)-1] != closing[c] {
+				t.Fatalf("%s:%d: unexpected %q", name, line, c)
+			}
+			open = open[:len(open)-1]
+		}
+	}
+	if len(open) > 0 {
+		t.Fatalf("%s: %d unclosed delimiters", name, len(open))
+	}
+}
+
+func TestGenerateTerraformFiles(t *testing.T) {
+	dm := &DeploymentManager{namespace: "talos-prod"}
+
+	files, err := dm.GenerateTerraformFiles()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"versions.tf", "variables.tf", "namespace.tf", "redis.tf", "dashboard.tf"}, keys(files))
+	for name, src := range files {
+		checkHCLDelimiters(t, name, src)
+	}
+
+	variables := string(files["variables.tf"])
+	assert.Contains(t, variables, `default     = "talos-prod"`)
+	assert.Contains(t, variables, `default     = "talos/dashboard:latest"`)
+	assert.Contains(t, variables, `default     = 3`)
+
+	namespace := string(files["namespace.tf"])
+	for _, entry := range talosConfig {
+		assert.Regexp(t, entry.key+` += "`+entry.value+`"`, namespace)
+	}
+
+	dashboard := string(files["dashboard.tf"])
+	assert.Contains(t, dashboard, `resource "kubernetes_deployment" "dashboard"`)
+	assert.Contains(t, dashboard, `resource "kubernetes_service" "dashboard"`)
+	assert.Contains(t, dashboard, `resource "kubernetes_horizontal_pod_autoscaler_v2" "dashboard"`)
+	assert.Contains(t, dashboard, "image = var.image")
+	assert.Contains(t, dashboard, "replicas = var.replicas")
+}
+
+func TestGenerateTerraform_Validates(t *testing.T) {
+	terraform, err := exec.LookPath("terraform")
+	if err != nil {
+		t.Skip("terraform not installed")
+	}
+
+	dir := t.TempDir()
+	dm := &DeploymentManager{namespace: "talos"}
+	require.NoError(t, dm.GenerateTerraform(dir))
+
+	// init downloads the Kubernetes provider validate needs
+	initCmd := exec.Command(terraform, "init", "-backend=false", "-input=false")
+	initCmd.Dir = dir
+	if output, err := initCmd.CombinedOutput(); err != nil {
+		t.Skipf("terraform init failed, probably offline: %v\n%s", err, output)
+	}
+
+	validate := exec.Command(terraform, "validate")
+	validate.Dir = dir
+	output, err := validate.CombinedOutput()
+	assert.NoError(t, err, string(output))
+}
+
+func TestGenerateTerraform_WritesFiles(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "terraform")
+	dm := &DeploymentManager{namespace: "talos"}
+	require.NoError(t, dm.GenerateTerraform(dir))
+
+	files, err := dm.GenerateTerraformFiles()
+	require.NoError(t, err)
+	for name, want := range files {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		assert.Equal(t, string(want), string(got))
+	}
+}
+
+func keys(files map[string][]byte) []string {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/Xover-Official/Xover/internal/monitoring"
+	"go.uber.org/zap"
+)
+
+// maxReasoningBullets caps how much AI reasoning an approval notification carries
+const maxReasoningBullets = 3
+
+// ApprovalSource loads an action with the AI decision behind it
+type ApprovalSource interface {
+	GetApprovalDetails(ctx context.Context, orgID, actionID string) (*database.ApprovalDetails, error)
+}
+
+// AlertRaiser delivers alerts to notification channels
+type AlertRaiser interface {
+	RaiseAlert(ctx context.Context, alert *monitoring.Alert)
+}
+
+// ApprovalNotifier sends a cost-impact preview for every action queued for approval
+type ApprovalNotifier struct {
+	source ApprovalSource
+	alerts AlertRaiser
+	logger *zap.Logger
+}
+
+// NewApprovalNotifier creates an approval notifier
+func NewApprovalNotifier(source ApprovalSource, alerts AlertRaiser, logger *zap.Logger) *ApprovalNotifier {
+	return &ApprovalNotifier{source: source, alerts: alerts, logger: logger}
+}
+
+// Notify raises the approval alert for an organization's action
+func (n *ApprovalNotifier) Notify(ctx context.Context, orgID, actionID string) error {
+	details, err := n.source.GetApprovalDetails(ctx, orgID, actionID)
+	if err != nil {
+		return fmt.Errorf("failed to load approval details: %w", err)
+	}
+
+	preview := buildApprovalPreview(details)
+	n.alerts.RaiseAlert(ctx, monitoring.NewApprovalAlert(preview))
+	n.logger.Debug("Approval notification sent",
+		zap.String("action_id", actionID),
+		zap.Float64("estimated_savings", preview.MonthlySavings),
+	)
+	return nil
+}
+
+// actionPayload is the part of an action's payload an approval preview uses
+type actionPayload struct {
+	Recommendations []string          `json:"recommendations"`
+	Confidence      float64           `json:"confidence"`
+	Plan            map[string]string `json:"plan"`
+}
+
+// buildApprovalPreview combines the action's recorded plan with the AI
+// decision's reasoning, falling back to the payload when no decision exists
+func buildApprovalPreview(details *database.ApprovalDetails) *monitoring.ApprovalPreview {
+	action := details.Action
+	preview := &monitoring.ApprovalPreview{
+		ActionID:       action.ID,
+		ResourceID:     action.ResourceID,
+		ActionType:     action.ActionType,
+		MonthlySavings: action.EstimatedSavings,
+		RiskScore:      action.RiskScore,
+	}
+
+	var payload actionPayload
+	_ = json.Unmarshal([]byte(action.Payload), &payload)
+	preview.CurrentType = payload.Plan["current_type"]
+	preview.ProposedType = payload.Plan["proposed_type"]
+	preview.Confidence = payload.Confidence
+	reasoning := payload.Recommendations
+
+	if decision := details.Decision; decision != nil {
+		preview.Model = decision.Model
+		if decision.Confidence != nil {
+			preview.Confidence = *decision.Confidence
+		}
+		if decision.Reasoning != nil {
+			if bullets := reasoningBullets(*decision.Reasoning); len(bullets) > 0 {
+				reasoning = bullets
+			}
+		}
+	}
+
+	if len(reasoning) > maxReasoningBullets {
+		reasoning = reasoning[:maxReasoningBullets]
+	}
+	preview.Reasoning = reasoning
+	return preview
+}
+
+// reasoningBullets splits free-form AI reasoning into bullet lines
+func reasoningBullets(reasoning string) []string {
+	var bullets []string
+	for _, line := range strings.Split(reasoning, "\n") {
+		line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "-*•0123456789.)"))
+		if line != "" {
+			bullets = append(bullets, line)
+		}
+	}
+	return bullets
+}
+
+// actionPlan describes the planned change for an action's payload
+func actionPlan(resource *cloud.ResourceV2) map[string]string {
+	plan := map[string]string{}
+	for _, key := range []string{"instance_type", "instance_class"} {
+		if current, ok := resource.Metadata[key].(string); ok && current != "" {
+			plan["current_type"] = current
+			break
+		}
+	}
+	if resource.RightSizeRecommendation != "" {
+		plan["proposed_type"] = resource.RightSizeRecommendation
+	}
+	return plan
+}
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/Xover-Official/Xover/internal/monitoring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type MockApprovalSource struct {
+	mock.Mock
+}
+
+func (m *MockApprovalSource) GetApprovalDetails(ctx context.Context, orgID, actionID string) (*database.ApprovalDetails, error) {
+	args := m.Called(ctx, orgID, actionID)
+	details, _ := args.Get(0).(*database.ApprovalDetails)
+	return details, args.Error(1)
+}
+
+type recordingAlerts struct {
+	alerts []*monitoring.Alert
+}
+
+func (r *recordingAlerts) RaiseAlert(ctx context.Context, alert *monitoring.Alert) {
+	r.alerts = append(r.alerts, alert)
+}
+
+func TestBuildApprovalPreview(t *testing.T) {
+	payload, _ := json.Marshal(map[string]interface{}{
+		"recommendations": []string{"a", "b", "c", "d"},
+		"confidence":      0.6,
+		"plan":            map[string]string{"current_type": "m5.2xlarge", "proposed_type": "m5.large"},
+	})
+	details := &database.ApprovalDetails{Action: &database.Action{
+		ID: "act-1", ResourceID: "i-0abc", ActionType: "optimize",
+		Payload: string(payload), RiskScore: 3, EstimatedSavings: 120,
+	}}
+
+	preview := buildApprovalPreview(details)
+	assert.Equal(t, "m5.2xlarge → m5.large", preview.Change())
+	assert.Equal(t, 120.0, preview.MonthlySavings)
+	assert.Equal(t, 0.6, preview.Confidence)
+	assert.Equal(t, []string{"a", "b", "c"}, preview.Reasoning)
+
+	reasoning := "1. CPU idle\n- Memory low\n\n* Steady traffic\n- Spare capacity"
+	confidence := 0.9
+	details.Decision = &database.AIDecision{Model: "gemini-1.5-pro", Reasoning: &reasoning, Confidence: &confidence}
+
+	preview = buildApprovalPreview(details)
+	assert.Equal(t, "gemini-1.5-pro", preview.Model)
+	assert.Equal(t, 0.9, preview.Confidence)
+	assert.Equal(t, []string{"CPU idle", "Memory low", "Steady traffic"}, preview.Reasoning)
+}
+
+func TestOODAEngine_DecideNotifiesApprovers(t *testing.T) {
+	resource := &cloud.ResourceV2{
+		ID: "web-01", Type: "ec2", RightSizeRecommendation: "m5.large",
+		Metadata: map[string]interface{}{"instance_type": "m5.2xlarge"},
+	}
+	opportunities := []*OptimizationOpportunity{{Resource: resource, RiskScore: 2, EstimatedSavings: 40}}
+
+	var created *database.Action
+	mockRepo := new(MockRepository)
+	mockRepo.On("CreateAction", mock.Anything, "", mock.Anything).Run(func(args mock.Arguments) {
+		created = args.Get(2).(*database.Action)
+	}).Return(nil)
+
+	config := DefaultEngineConfig()
+	config.RequireHumanApproval = true
+	engine := NewOODAEngine(nil, new(MockCloudAdapter), mockRepo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), config)
+	alerts := &recordingAlerts{}
+	engine.SetApprovalNotifier(NewApprovalNotifier(approvalSourceFunc(func(ctx context.Context, _, id string) (*database.ApprovalDetails, error) {
+		return &database.ApprovalDetails{Action: created}, nil
+	}), alerts, zap.NewNop()))
+
+	_, err := engine.decide(context.Background(), opportunities)
+	require.NoError(t, err)
+	require.Len(t, alerts.alerts, 1)
+
+	alert := alerts.alerts[0]
+	assert.Equal(t, "approval", alert.Labels["kind"])
+	preview := alert.Annotations[monitoring.ApprovalAnnotation].(*monitoring.ApprovalPreview)
+	assert.Equal(t, created.ID, preview.ActionID)
+	assert.Equal(t, "m5.2xlarge → m5.large", preview.Change())
+	assert.Equal(t, 40.0, preview.MonthlySavings)
+}
+
+func TestApprovalNotifierPropagatesLoadErrors(t *testing.T) {
+	source := new(MockApprovalSource)
+	source.On("GetApprovalDetails", mock.Anything, "org-1", "act-1").Return(nil, database.ErrNotFound)
+	alerts := &recordingAlerts{}
+
+	err := NewApprovalNotifier(source, alerts, zap.NewNop()).Notify(context.Background(), "org-1", "act-1")
+
+	assert.ErrorIs(t, err, database.ErrNotFound)
+	assert.Empty(t, alerts.alerts)
+}
+
+type approvalSourceFunc func(ctx context.Context, orgID, actionID string) (*database.ApprovalDetails, error)
+
+func (f approvalSourceFunc) GetApprovalDetails(ctx context.Context, orgID, actionID string) (*database.ApprovalDetails, error) {
+	return f(ctx, orgID, actionID)
+}
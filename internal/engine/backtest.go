@@ -0,0 +1,186 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/database"
+	"go.uber.org/zap"
+)
+
+// BacktestOutcomeAct marks a replayed resource that would have produced an action
+const BacktestOutcomeAct = "act"
+
+// BacktestRepository provides the history replayed by a Backtester
+type BacktestRepository interface {
+	ListInventorySnapshots(ctx context.Context, start, end time.Time) ([]*database.InventorySnapshot, error)
+}
+
+// Backtester replays historical inventory snapshots through the orient and
+// decide policy to compare a candidate EngineConfig against a baseline. It
+// reuses the AI decisions stored at the time, so nothing is executed and no
+// model is called.
+type Backtester struct {
+	repository BacktestRepository
+	baseline   *EngineConfig
+	logger     *zap.Logger
+}
+
+// BacktestOutcome summarizes the decisions one config would have made
+type BacktestOutcome struct {
+	Actions          int            `json:"actions"`
+	EstimatedSavings float64        `json:"estimated_savings"`
+	Skipped          map[string]int `json:"skipped"` // skip reason -> count
+}
+
+// BacktestChange is a replayed resource whose outcome or estimated savings
+// differ between the baseline and candidate configs
+type BacktestChange struct {
+	CycleID      string  `json:"cycle_id"`
+	ResourceID   string  `json:"resource_id"`
+	Baseline     string  `json:"baseline"`  // BacktestOutcomeAct or a skip reason
+	Candidate    string  `json:"candidate"` // BacktestOutcomeAct or a skip reason
+	SavingsDelta float64 `json:"savings_delta"`
+}
+
+// BacktestReport compares a candidate config to the baseline over a period
+type BacktestReport struct {
+	Start            time.Time        `json:"start"`
+	End              time.Time        `json:"end"`
+	Cycles           int              `json:"cycles"`
+	Replayed         int              `json:"replayed"`
+	MissingDecisions int              `json:"missing_decisions"` // snapshots without a stored AI decision
+	Baseline         BacktestOutcome  `json:"baseline"`
+	Candidate        BacktestOutcome  `json:"candidate"`
+	ActionDelta      int              `json:"action_delta"`  // candidate minus baseline
+	SavingsDelta     float64          `json:"savings_delta"` // candidate minus baseline
+	Changes          []BacktestChange `json:"changes"`
+}
+
+// NewBacktester creates a backtester comparing candidates against baseline
+func NewBacktester(repository BacktestRepository, baseline *EngineConfig, logger *zap.Logger) *Backtester {
+	return &Backtester{
+		repository: repository,
+		baseline:   baseline,
+		logger:     logger,
+	}
+}
+
+// Run replays every snapshot captured in [start, end) under both configs
+func (b *Backtester) Run(ctx context.Context, start, end time.Time, candidate *EngineConfig) (*BacktestReport, error) {
+	if !end.After(start) {
+		return nil, fmt.Errorf("invalid backtest period: end must be after start")
+	}
+	for _, cfg := range []*EngineConfig{b.baseline, candidate} {
+		if !cfg.VectorWeights.IsZero() {
+			if err := cfg.VectorWeights.Validate(); err != nil {
+				return nil, fmt.Errorf("invalid backtest config: %w", err)
+			}
+		}
+	}
+
+	snapshots, err := b.repository.ListInventorySnapshots(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inventory snapshots: %w", err)
+	}
+
+	report := &BacktestReport{
+		Start:     start,
+		End:       end,
+		Baseline:  BacktestOutcome{Skipped: map[string]int{}},
+		Candidate: BacktestOutcome{Skipped: map[string]int{}},
+		Changes:   []BacktestChange{},
+	}
+	baseline := &OODAEngine{config: b.baseline}
+	replay := &OODAEngine{config: candidate}
+
+	cycles := make(map[string]bool)
+	for _, snapshot := range snapshots {
+		cycles[snapshot.CycleID] = true
+		if snapshot.Decision == nil {
+			report.MissingDecisions++
+			continue
+		}
+
+		var resource cloud.ResourceV2
+		if err := json.Unmarshal(snapshot.Resource, &resource); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot of %s in %s: %w", snapshot.ResourceID, snapshot.CycleID, err)
+		}
+		report.Replayed++
+
+		baseOutcome, baseSavings := baseline.replayDecision(&resource, snapshot.Decision)
+		candOutcome, candSavings := replay.replayDecision(&resource, snapshot.Decision)
+		report.Baseline.add(baseOutcome, baseSavings)
+		report.Candidate.add(candOutcome, candSavings)
+
+		if baseOutcome != candOutcome || baseSavings != candSavings {
+			report.Changes = append(report.Changes, BacktestChange{
+				CycleID:      snapshot.CycleID,
+				ResourceID:   snapshot.ResourceID,
+				Baseline:     baseOutcome,
+				Candidate:    candOutcome,
+				SavingsDelta: candSavings - baseSavings,
+			})
+		}
+	}
+
+	report.Cycles = len(cycles)
+	report.ActionDelta = report.Candidate.Actions - report.Baseline.Actions
+	report.SavingsDelta = report.Candidate.EstimatedSavings - report.Baseline.EstimatedSavings
+
+	b.logger.Info("Backtest completed",
+		zap.Int("cycles", report.Cycles),
+		zap.Int("replayed", report.Replayed),
+		zap.Int("missing_decisions", report.MissingDecisions),
+		zap.Int("action_delta", report.ActionDelta),
+		zap.Float64("savings_delta", report.SavingsDelta),
+	)
+	return report, nil
+}
+
+// add counts one replayed decision
+func (o *BacktestOutcome) add(outcome string, savings float64) {
+	if outcome != BacktestOutcomeAct {
+		o.Skipped[outcome]++
+		return
+	}
+	o.Actions++
+	o.EstimatedSavings += savings
+}
+
+// replayDecision applies the engine's orient and decide policy to a stored
+// resource and AI decision. It returns BacktestOutcomeAct and the estimated
+// savings when an action would have been created, or the skip reason.
+func (e *OODAEngine) replayDecision(resource *cloud.ResourceV2, decision *database.AIDecision) (string, float64) {
+	if e.config.Protection.ProtectionReason(resource) != "" {
+		return SkipReasonProtected, 0
+	}
+
+	vectors := e.analysisVectors(resource, e.config.VectorWeights.resolved())
+	recommendation := e.parseRecommendations(decision.Decision)
+	opportunity := &OptimizationOpportunity{
+		Resource:         resource,
+		AnalysisVectors:  vectors,
+		RiskScore:        e.calculateRiskScore(vectors),
+		Recommendations:  recommendation.Recommendations,
+		EstimatedSavings: e.estimateSavings(resource, recommendation.Recommendations),
+		Confidence:       recommendation.Confidence,
+	}
+	if recommendation.RiskScore > 0 {
+		opportunity.RiskScore = recommendation.RiskScore
+	}
+	if decision.Confidence != nil {
+		opportunity.Confidence = *decision.Confidence
+	}
+
+	if opportunity.EstimatedSavings < e.config.MinSavingsThreshold {
+		return SkipReasonBelowMinSavings, 0
+	}
+	if reason, _ := decisionSkipReason(e.config, opportunity); reason != "" {
+		return reason, 0
+	}
+	return BacktestOutcomeAct, opportunity.EstimatedSavings
+}
@@ -0,0 +1,63 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type stubSnapshotRepository struct {
+	snapshots []*database.InventorySnapshot
+}
+
+func (s *stubSnapshotRepository) ListInventorySnapshots(ctx context.Context, start, end time.Time) ([]*database.InventorySnapshot, error) {
+	return s.snapshots, nil
+}
+
+func snapshotOf(t *testing.T, cycleID string, resource *cloud.ResourceV2, decision string) *database.InventorySnapshot {
+	body, err := json.Marshal(resource)
+	require.NoError(t, err)
+	snapshot := &database.InventorySnapshot{CycleID: cycleID, ResourceID: resource.ID, Resource: body}
+	if decision != "" {
+		snapshot.Decision = &database.AIDecision{ResourceID: resource.ID, Decision: decision}
+	}
+	return snapshot
+}
+
+func TestBacktester_ComparesCandidateToBaseline(t *testing.T) {
+	idle := &cloud.ResourceV2{ID: "i-idle", Type: cloud.ResourceTypeEC2, State: "running", CPUUsage: 0.05, MemoryUsage: 0.1, CostPerMonth: 200}
+	small := &cloud.ResourceV2{ID: "i-small", Type: cloud.ResourceTypeEC2, State: "running", CPUUsage: 0.5, MemoryUsage: 0.5, CostPerMonth: 60}
+	repo := &stubSnapshotRepository{snapshots: []*database.InventorySnapshot{
+		snapshotOf(t, "cycle-1", idle, "Resize to t3.small"),
+		snapshotOf(t, "cycle-1", small, "Resize to t3.nano"),
+		snapshotOf(t, "cycle-2", idle, ""),
+	}}
+
+	baseline := DefaultEngineConfig()
+	candidate := DefaultEngineConfig()
+	candidate.MinSavingsThreshold = 20 // drops the small resource
+
+	report, err := NewBacktester(repo, baseline, zap.NewNop()).Run(context.Background(), time.Now().Add(-time.Hour), time.Now(), candidate)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, report.Cycles)
+	assert.Equal(t, 2, report.Replayed)
+	assert.Equal(t, 1, report.MissingDecisions)
+	assert.Equal(t, 2, report.Baseline.Actions)
+	assert.Equal(t, 1, report.Candidate.Actions)
+	assert.Equal(t, -1, report.ActionDelta)
+	assert.Equal(t, 1, report.Candidate.Skipped[SkipReasonBelowMinSavings])
+	assert.InDelta(t, -13.2, report.SavingsDelta, 0.001) // 60 * 0.2 * 1.1
+
+	require.Len(t, report.Changes, 1)
+	assert.Equal(t, "i-small", report.Changes[0].ResourceID)
+	assert.Equal(t, BacktestOutcomeAct, report.Changes[0].Baseline)
+	assert.Equal(t, SkipReasonBelowMinSavings, report.Changes[0].Candidate)
+}
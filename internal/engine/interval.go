@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Defaults for the adaptive cycle interval settings left unset
+const (
+	defaultIntervalBackoff      = 2.0
+	defaultIntervalSpeedup      = 2.0
+	defaultQuietCyclesToBackoff = 2
+	// defaultHighSavingsFactor times MinSavingsThreshold makes an
+	// opportunity high-savings when HighSavingsThreshold is unset
+	defaultHighSavingsFactor = 10.0
+)
+
+// CycleScheduler decides how long to wait before the next OODA cycle.
+// Cycles that find nothing actionable push the interval toward
+// MaxCycleInterval; a cycle with a high-savings opportunity pulls it toward
+// MinCycleInterval. Without both bounds the interval stays CycleInterval.
+type CycleScheduler struct {
+	base        time.Duration
+	min, max    time.Duration
+	backoff     float64
+	speedup     float64
+	quietLimit  int
+	highSavings float64
+
+	interval time.Duration
+	quiet    int // consecutive cycles with nothing actionable
+}
+
+// NewCycleScheduler returns a scheduler starting at cfg.CycleInterval
+func NewCycleScheduler(cfg *EngineConfig) *CycleScheduler {
+	s := &CycleScheduler{
+		base:        cfg.CycleInterval,
+		min:         cfg.MinCycleInterval,
+		max:         cfg.MaxCycleInterval,
+		backoff:     cfg.IntervalBackoff,
+		speedup:     cfg.IntervalSpeedup,
+		quietLimit:  cfg.QuietCyclesToBackoff,
+		highSavings: cfg.HighSavingsThreshold,
+	}
+	if s.backoff <= 1 {
+		s.backoff = defaultIntervalBackoff
+	}
+	if s.speedup <= 1 {
+		s.speedup = defaultIntervalSpeedup
+	}
+	if s.quietLimit <= 0 {
+		s.quietLimit = defaultQuietCyclesToBackoff
+	}
+	if s.highSavings <= 0 {
+		s.highSavings = cfg.MinSavingsThreshold * defaultHighSavingsFactor
+	}
+	s.interval = s.clamp(s.base)
+	return s
+}
+
+// adaptive reports whether the interval may change at all
+func (s *CycleScheduler) adaptive() bool {
+	return s.min > 0 && s.max >= s.min
+}
+
+func (s *CycleScheduler) clamp(d time.Duration) time.Duration {
+	if !s.adaptive() {
+		return d
+	}
+	return max(s.min, min(d, s.max))
+}
+
+// Interval returns the current wait between cycles
+func (s *CycleScheduler) Interval() time.Duration {
+	return s.interval
+}
+
+// Next records a completed cycle and returns the wait before the next one.
+// A cycle with actionable but no high-savings opportunities returns the
+// interval to CycleInterval.
+func (s *CycleScheduler) Next(summary CycleSummary) time.Duration {
+	if !s.adaptive() {
+		return s.interval
+	}
+	switch {
+	case summary.TopSavings >= s.highSavings:
+		s.quiet = 0
+		s.interval = s.clamp(time.Duration(float64(s.interval) / s.speedup))
+	case summary.DecisionsMade == 0:
+		s.quiet++
+		if s.quiet >= s.quietLimit {
+			s.interval = s.clamp(time.Duration(float64(s.interval) * s.backoff))
+		}
+	default:
+		s.quiet = 0
+		s.interval = s.clamp(s.base)
+	}
+	return s.interval
+}
+
+// Run executes cycles until ctx is done, waiting the adaptive interval
+// between them. A failed cycle is logged and leaves the interval unchanged.
+func (e *OODAEngine) Run(ctx context.Context) error {
+	return runCycles(ctx, NewCycleScheduler(e.config), e.runCycle, time.After, e.logger)
+}
+
+// runCycles is Run with the cycle and the timer replaceable for tests
+func runCycles(ctx context.Context, scheduler *CycleScheduler, cycle func(context.Context) (CycleSummary, error), after func(time.Duration) <-chan time.Time, logger *zap.Logger) error {
+	for {
+		interval := scheduler.Interval()
+		if summary, err := cycle(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			logger.Error("OODA cycle failed", zap.Error(err))
+		} else if next := scheduler.Next(summary); next != interval {
+			logger.Info("OODA cycle interval adjusted",
+				zap.Duration("from", interval),
+				zap.Duration("to", next),
+				zap.Int("decisions_made", summary.DecisionsMade),
+				zap.Float64("top_savings", summary.TopSavings),
+			)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-after(scheduler.Interval()):
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func adaptiveConfig() *EngineConfig {
+	cfg := DefaultEngineConfig()
+	cfg.CycleInterval = 30 * time.Minute
+	cfg.MinCycleInterval = 5 * time.Minute
+	cfg.MaxCycleInterval = 4 * time.Hour
+	cfg.MinSavingsThreshold = 10 // high savings from $100/mo
+	return cfg
+}
+
+// fakeCycles plays back one summary, or an error, per cycle and cancels
+// the context after the last
+type fakeCycles struct {
+	results []fakeCycle
+	cancel  context.CancelFunc
+}
+
+type fakeCycle struct {
+	decisions  int
+	topSavings float64
+	err        error
+}
+
+func (f *fakeCycles) run(ctx context.Context) (CycleSummary, error) {
+	next := f.results[0]
+	f.results = f.results[1:]
+	if len(f.results) == 0 {
+		f.cancel()
+	}
+	return CycleSummary{DecisionsMade: next.decisions, TopSavings: next.topSavings}, next.err
+}
+
+// runFakeCycles runs the cycles and returns every interval Run waited
+func runFakeCycles(t *testing.T, cfg *EngineConfig, cycles ...fakeCycle) []time.Duration {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	fake := &fakeCycles{results: cycles, cancel: cancel}
+
+	var waits []time.Duration
+	after := func(d time.Duration) <-chan time.Time {
+		if ctx.Err() != nil {
+			return nil // leaves Run only the cancelled context to select
+		}
+		waits = append(waits, d)
+		fired := make(chan time.Time, 1)
+		fired <- time.Now()
+		return fired
+	}
+	err := runCycles(ctx, NewCycleScheduler(cfg), fake.run, after, zap.NewNop())
+	assert.ErrorIs(t, err, context.Canceled)
+	return waits
+}
+
+func TestCycleScheduler_BacksOffWhenQuietAndSpeedsUpOnHighSavings(t *testing.T) {
+	waits := runFakeCycles(t, adaptiveConfig(),
+		fakeCycle{decisions: 0},                      // quiet 1: unchanged
+		fakeCycle{decisions: 0},                      // quiet 2: back off
+		fakeCycle{decisions: 0},                      // keeps backing off
+		fakeCycle{decisions: 0},                      // capped at the max
+		fakeCycle{decisions: 0},                      // stays at the max
+		fakeCycle{decisions: 2, topSavings: 40},      // actionable: back to the base
+		fakeCycle{decisions: 1, topSavings: 250},     // high savings: speed up
+		fakeCycle{decisions: 3, topSavings: 500},     // again
+		fakeCycle{decisions: 1, topSavings: 120},     // floored at the min
+		fakeCycle{err: errors.New("observe failed")}, // unchanged
+		fakeCycle{decisions: 0},
+	)
+
+	assert.Equal(t, []time.Duration{
+		30 * time.Minute,
+		time.Hour,
+		2 * time.Hour,
+		4 * time.Hour,
+		4 * time.Hour,
+		30 * time.Minute,
+		15 * time.Minute,
+		7*time.Minute + 30*time.Second,
+		5 * time.Minute,
+		5 * time.Minute,
+	}, waits)
+}
+
+func TestCycleScheduler_FixedWithoutBounds(t *testing.T) {
+	cfg := adaptiveConfig()
+	cfg.MinCycleInterval, cfg.MaxCycleInterval = 0, 0
+
+	waits := runFakeCycles(t, cfg,
+		fakeCycle{decisions: 0},
+		fakeCycle{decisions: 0},
+		fakeCycle{decisions: 0},
+		fakeCycle{decisions: 1, topSavings: 900},
+		fakeCycle{decisions: 0},
+	)
+
+	assert.Equal(t, []time.Duration{30 * time.Minute, 30 * time.Minute, 30 * time.Minute, 30 * time.Minute}, waits)
+}
+
+func TestCycleScheduler_ConfiguredFactors(t *testing.T) {
+	cfg := adaptiveConfig()
+	cfg.IntervalBackoff = 1.5
+	cfg.QuietCyclesToBackoff = 1
+	cfg.IntervalSpeedup = 3
+	cfg.HighSavingsThreshold = 1000
+	s := NewCycleScheduler(cfg)
+
+	assert.Equal(t, 45*time.Minute, s.Next(CycleSummary{}))
+	assert.Equal(t, 45*time.Minute+22*time.Minute+30*time.Second, s.Next(CycleSummary{}))
+	assert.Equal(t, 30*time.Minute, s.Next(CycleSummary{DecisionsMade: 4, TopSavings: 999}), "below the threshold")
+	assert.Equal(t, 10*time.Minute, s.Next(CycleSummary{DecisionsMade: 4, TopSavings: 1000}))
+}
+
+func TestCycleScheduler_ClampsBaseInterval(t *testing.T) {
+	cfg := adaptiveConfig()
+	cfg.CycleInterval = time.Minute
+
+	assert.Equal(t, 5*time.Minute, NewCycleScheduler(cfg).Interval())
+}
@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Action outcomes counted by CycleMetrics. Actions are counted once when the
+// decide phase queues them and again when the act phase executes them.
+const (
+	actionQueued           = "queued"
+	actionAwaitingApproval = "awaiting_approval"
+	actionSucceeded        = "succeeded"
+	actionFailed           = "failed"
+	actionSkipped          = "skipped" // claimed elsewhere or held by the autonomy flags
+)
+
+// CycleMetrics exports what each OODA phase took and produced. Every method
+// is safe to call on a nil *CycleMetrics.
+type CycleMetrics struct {
+	phaseDuration    *prometheus.HistogramVec
+	resourcesScanned prometheus.Counter
+	opportunities    *prometheus.CounterVec
+	actions          *prometheus.CounterVec
+}
+
+// NewCycleMetrics creates the engine's phase and outcome collectors
+func NewCycleMetrics() *CycleMetrics {
+	return &CycleMetrics{
+		phaseDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "talos_ooda_phase_duration_seconds",
+			Help:    "Duration of each OODA phase",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 12), // 50ms to ~100s
+		}, []string{"phase"}),
+		resourcesScanned: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "talos_ooda_resources_scanned_total",
+			Help: "Resources returned by the observe phase",
+		}),
+		opportunities: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "talos_ooda_opportunities_total",
+			Help: "Optimization opportunities found by the orient phase, by action",
+		}, []string{"action"}),
+		actions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "talos_ooda_actions_total",
+			Help: "Actions queued by the decide phase and executed by the act phase, by status",
+		}, []string{"status"}),
+	}
+}
+
+// Register exposes the collectors through reg, usually
+// prometheus.DefaultRegisterer so that they are served on /metrics
+func (m *CycleMetrics) Register(reg prometheus.Registerer) error {
+	collectors := []prometheus.Collector{m.phaseDuration, m.resourcesScanned, m.opportunities, m.actions}
+	for i, c := range collectors {
+		if err := reg.Register(c); err != nil {
+			for _, registered := range collectors[:i] {
+				reg.Unregister(registered)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *CycleMetrics) observePhase(phase string, d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.phaseDuration.WithLabelValues(phase).Observe(d.Seconds())
+}
+
+func (m *CycleMetrics) addResources(n int) {
+	if m == nil {
+		return
+	}
+	m.resourcesScanned.Add(float64(n))
+}
+
+func (m *CycleMetrics) countOpportunity(action string) {
+	if m == nil {
+		return
+	}
+	m.opportunities.WithLabelValues(action).Inc()
+}
+
+func (m *CycleMetrics) countAction(status string) {
+	if m == nil {
+		return
+	}
+	m.actions.WithLabelValues(status).Inc()
+}
@@ -0,0 +1,64 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func TestOODAEngine_RunCycleRecordsMetrics(t *testing.T) {
+	idle := map[string]interface{}{cloud.IdleSinceKey: time.Now().Add(-30 * 24 * time.Hour)}
+	address := &cloud.ResourceV2{ID: "eipalloc-1", Type: cloud.ResourceTypeElasticIP, State: "unassociated", CostPerMonth: 3.65, Metadata: idle}
+
+	mockAdapter := new(MockCloudAdapter)
+	mockRepo := new(MockRepository)
+	config := DefaultEngineConfig()
+	config.AutoApproveQuickWins = true
+	engine := NewOODAEngine(nil, mockAdapter, mockRepo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), config)
+
+	reg := prometheus.NewRegistry()
+	require.NoError(t, engine.RegisterMetrics(reg))
+
+	// The idle address becomes a queued quick win; the act phase also finds
+	// an action whose resource is gone and one claimed elsewhere
+	queued := &database.Action{}
+	mockAdapter.On("FetchResources", mock.Anything).Return([]*cloud.ResourceV2{address}, nil)
+	mockRepo.On("CreateAction", mock.Anything, "", mock.Anything).
+		Run(func(args mock.Arguments) { *queued = *args.Get(2).(*database.Action) }).Return(nil)
+	mockRepo.On("GetPendingActionsPage", mock.Anything, "", (*database.ActionCursor)(nil), config.PendingActionsPage).
+		Return([]*database.Action{
+			queued,
+			{ID: "gone", ResourceID: "vol-gone", ActionType: string(cloud.ActionDeleteVolume)},
+			{ID: "taken", ResourceID: "vol-taken", ActionType: string(cloud.ActionDeleteVolume)},
+		}, (*database.ActionCursor)(nil), nil)
+	mockRepo.On("ClaimAction", mock.Anything, "taken", mock.Anything).Return(false, nil)
+	mockRepo.On("ClaimAction", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	mockAdapter.On("GetResource", mock.Anything, "eipalloc-1").Return(address, nil)
+	mockAdapter.On("GetResource", mock.Anything, "vol-gone").Return((*cloud.ResourceV2)(nil), assert.AnError)
+	mockAdapter.On("ApplyOptimization", mock.Anything, address, string(cloud.ActionReleaseAddress)).Return(3.65, nil)
+	mockRepo.On("UpdateActionStatus", mock.Anything, mock.Anything, "COMPLETED", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateSavingsEvent", mock.Anything, "", mock.Anything).Return(nil)
+
+	require.NoError(t, engine.RunCycle(context.Background()))
+
+	m := engine.cycleMetrics
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.resourcesScanned))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.opportunities.WithLabelValues(string(cloud.ActionReleaseAddress))))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.actions.WithLabelValues(actionQueued)))
+	assert.Equal(t, 0.0, testutil.ToFloat64(m.actions.WithLabelValues(actionAwaitingApproval)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.actions.WithLabelValues(actionSucceeded)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.actions.WithLabelValues(actionFailed)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.actions.WithLabelValues(actionSkipped)))
+	// An engine's collectors can only be served once per registry
+	assert.Error(t, engine.RegisterMetrics(reg))
+}
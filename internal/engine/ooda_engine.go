@@ -8,15 +8,23 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Xover-Official/Xover/internal/ai"
 	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/concurrency"
 	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/Xover-Official/Xover/internal/features"
+	"github.com/Xover-Official/Xover/internal/metrics"
 	"github.com/Xover-Official/Xover/internal/security"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
@@ -46,6 +54,16 @@ type OptimizationOpportunity struct {
 	Recommendations  []string
 	EstimatedSavings float64
 	Confidence       float64
+	// Action is the action to queue; empty means cloud.ActionOptimize
+	Action cloud.ActionType
+}
+
+// action returns the action the opportunity queues
+func (o *OptimizationOpportunity) action() cloud.ActionType {
+	if o.Action == "" {
+		return cloud.ActionOptimize
+	}
+	return o.Action
 }
 
 // AnalysisVector represents a dimension of analysis
@@ -57,11 +75,86 @@ type AnalysisVector struct {
 	Confidence float64
 }
 
+// Reasons a resource or opportunity is skipped, recorded in SkippedResource
+// and backtest reports
+const (
+	// SkipReasonAnalysisTimeout is recorded when a resource analysis exceeds MaxAnalysisTime
+	SkipReasonAnalysisTimeout = "analysis_timeout"
+	// SkipReasonProtected is recorded when a resource matches the protection policy
+	SkipReasonProtected = "protected"
+	// SkipReasonAIBudget is recorded when the cycle's AI cost budget ran out
+	// before a resource was analyzed
+	SkipReasonAIBudget = "ai_budget_exhausted"
+	// SkipReasonBelowMinSavings marks an opportunity under MinSavingsThreshold
+	SkipReasonBelowMinSavings = "below_min_savings"
+	// SkipReasonRiskThreshold marks an opportunity above RiskThreshold
+	SkipReasonRiskThreshold = "risk_threshold"
+	// SkipReasonInvalidAction marks an opportunity whose action the resource doesn't support
+	SkipReasonInvalidAction = "invalid_action"
+	// SkipReasonAutonomy marks an opportunity whose action the autonomy flags skip
+	SkipReasonAutonomy = "autonomy_skip"
+)
+
+// DefaultMaxTerminationRisk is the risk score, on the 0-10 scale of
+// RiskThreshold, that terminate actions must stay below by default
+const DefaultMaxTerminationRisk = 3.0
+
+// ErrAnalysisTimeout is returned when a single resource analysis exceeds MaxAnalysisTime
+var ErrAnalysisTimeout = errors.New("resource analysis timed out")
+
+// SkippedResource records a resource that was skipped during a cycle and why
+type SkippedResource struct {
+	ResourceID string
+	Reason     string
+	Error      string
+}
+
+// EngineMetrics is a snapshot of cumulative engine counters
+type EngineMetrics struct {
+	CyclesCompleted   int64
+	ResourcesAnalyzed int64
+	AnalysisFailures  int64
+	AnalysisTimeouts  int64
+}
+
+// engineCounters holds the live counters behind EngineMetrics
+type engineCounters struct {
+	cyclesCompleted   atomic.Int64
+	resourcesAnalyzed atomic.Int64
+	analysisFailures  atomic.Int64
+	analysisTimeouts  atomic.Int64
+}
+
 // Repository defines the interface for data persistence required by the engine
 type Repository interface {
-	CreateAction(ctx context.Context, action *database.Action) error
+	CreateAction(ctx context.Context, orgID string, action *database.Action) error
 	UpdateActionStatus(ctx context.Context, id string, status string, startedAt *time.Time, completedAt *time.Time, errorMsg *string) error
-	CreateSavingsEvent(ctx context.Context, event *database.SavingsEvent) error
+	ClaimAction(ctx context.Context, id string, startedAt time.Time) (bool, error)
+	CreateSavingsEvent(ctx context.Context, orgID string, event *database.SavingsEvent) error
+	GetPendingActionsPage(ctx context.Context, orgID string, after *database.ActionCursor, limit int) ([]*database.Action, *database.ActionCursor, error)
+}
+
+// HistoryRecorder is implemented by repositories that keep the inventory
+// snapshots and AI decisions replayed by Backtester. Recording is skipped
+// when the repository doesn't implement it.
+type HistoryRecorder interface {
+	SaveInventorySnapshot(ctx context.Context, cycleID string, capturedAt time.Time, items []database.InventoryItem) error
+	CreateAIDecision(ctx context.Context, decision *database.AIDecision) error
+}
+
+// EventRecorder is implemented by repositories that keep the outbound
+// lifecycle event stream. Action events are written by the repository
+// itself; the engine records opportunities, which have no row of their own.
+type EventRecorder interface {
+	AppendEvent(ctx context.Context, eventType, subjectID string, data map[string]interface{}) error
+}
+
+// PayloadRecorder is implemented by repositories that can add to a stored
+// action's payload. The engine records the pre-action snapshot and the
+// outcome of any rollback there; recording is skipped when the repository
+// doesn't implement it.
+type PayloadRecorder interface {
+	MergeActionPayload(ctx context.Context, id string, fields map[string]interface{}) error
 }
 
 // OODAEngine implements the OODA loop for cloud optimization
@@ -73,6 +166,18 @@ type OODAEngine struct {
 	logger         *zap.Logger
 	tracer         trace.Tracer
 	config         *EngineConfig
+	counters       engineCounters
+	cycleMetrics   *CycleMetrics
+	approvalStore  database.ApprovalStore
+	approvals      *ApprovalNotifier  // nil disables approval notifications
+	onCycle        func(CycleSummary) // nil publishes nothing
+	workers        *concurrency.Manager
+	autonomy       *features.AutonomyGate
+	weights        atomic.Pointer[VectorWeights]   // read once at the start of each cycle
+	vectors        map[string][]AnalysisVectorFunc // by resource type
+
+	skippedMu   sync.RWMutex
+	lastSkipped []SkippedResource
 }
 
 // EngineConfig holds configuration for the OODA engine
@@ -86,6 +191,43 @@ type EngineConfig struct {
 	EnableAutoExecution   bool          `yaml:"enable_auto_execution"`
 	RequireHumanApproval  bool          `yaml:"require_human_approval"`
 	DefaultSavingsRatio   float64       `yaml:"default_savings_ratio"`
+	PendingActionsPage    int           `yaml:"pending_actions_page"` // page size when draining pending actions
+	ActionConcurrency     int           `yaml:"action_concurrency"`   // workers executing actions in the act phase
+	ActionOrder           string        `yaml:"action_order"`         // savings (default), risk or created
+	// MinCycleInterval and MaxCycleInterval bound the adaptive interval Run
+	// waits between cycles; without both the interval stays CycleInterval
+	MinCycleInterval time.Duration `yaml:"min_cycle_interval"`
+	MaxCycleInterval time.Duration `yaml:"max_cycle_interval"`
+	// IntervalBackoff multiplies the interval after QuietCyclesToBackoff
+	// consecutive cycles with nothing actionable, and after each one that
+	// follows (default 2)
+	IntervalBackoff      float64 `yaml:"interval_backoff"`
+	QuietCyclesToBackoff int     `yaml:"quiet_cycles_to_backoff"` // default 2
+	// IntervalSpeedup divides the interval after a cycle finds an opportunity
+	// saving at least HighSavingsThreshold a month (default 2, and 10 times
+	// MinSavingsThreshold)
+	IntervalSpeedup      float64 `yaml:"interval_speedup"`
+	HighSavingsThreshold float64 `yaml:"high_savings_threshold"`
+	// MaxTerminationRisk is the risk score a terminate action must stay
+	// below to execute; not positive uses DefaultMaxTerminationRisk
+	MaxTerminationRisk float64 `yaml:"max_termination_risk"`
+	// AutoApproveQuickWins queues idle load balancer and address cleanups
+	// without human approval even when RequireHumanApproval is set. Volume
+	// deletions always wait for approval. Off by default.
+	AutoApproveQuickWins bool `yaml:"auto_approve_quick_wins"`
+	// Protection lists resources the engine never analyzes or acts on. The
+	// defaults protect TOPAZ's critical resource IDs (ai.DefaultProtectedResources).
+	Protection cloud.ProtectionPolicy `yaml:"protection"`
+	// Autonomy executes, queues for approval or skips actions by type,
+	// environment and tags; actions no flag selects follow the settings above
+	Autonomy features.AutonomyFlags `yaml:"autonomy"`
+	// VectorWeights weight the analysis vectors in the risk score; unset
+	// uses DefaultVectorWeights. SetVectorWeights changes them at runtime.
+	VectorWeights VectorWeights `yaml:"vector_weights"`
+	// OrganizationID owns the actions and savings this engine records; it
+	// only executes that organization's actions. Empty for a single-tenant
+	// deployment.
+	OrganizationID string `yaml:"organization_id"`
 }
 
 // NewOODAEngine creates a new OODA engine
@@ -98,7 +240,7 @@ func NewOODAEngine(
 	tracer trace.Tracer,
 	config *EngineConfig,
 ) *OODAEngine {
-	return &OODAEngine{
+	e := &OODAEngine{
 		aiOrchestrator: aiOrchestrator,
 		cloudAdapter:   cloudAdapter,
 		repository:     repository,
@@ -106,52 +248,159 @@ func NewOODAEngine(
 		logger:         logger,
 		tracer:         tracer,
 		config:         config,
+		workers:        concurrency.Default(),
+		autonomy:       features.NewAutonomyGate(config.Autonomy, nil),
+		cycleMetrics:   NewCycleMetrics(),
+	}
+	if store, ok := repository.(database.ApprovalStore); ok {
+		e.approvalStore = store
 	}
+	e.vectors = e.defaultVectorRegistry()
+	if err := e.SetVectorWeights(config.VectorWeights); err != nil {
+		logger.Warn("Invalid analysis vector weights, using the defaults", zap.Error(err))
+		e.SetVectorWeights(VectorWeights{})
+	}
+	return e
+}
+
+// SetWorkers replaces the manager bounding the engine's analysis and action
+// goroutines
+func (e *OODAEngine) SetWorkers(workers *concurrency.Manager) {
+	e.workers = workers
+}
+
+// SetAutonomy replaces the configured autonomy flags with a gate that can be
+// changed at runtime; it is refreshed at the start of every cycle
+func (e *OODAEngine) SetAutonomy(gate *features.AutonomyGate) {
+	e.autonomy = gate
+}
+
+// SetApprovalStore replaces the queue that actions awaiting human approval
+// are filed in. By default it is the repository, if that implements
+// database.ApprovalStore; without one they are stored like any other action.
+func (e *OODAEngine) SetApprovalStore(store database.ApprovalStore) {
+	e.approvalStore = store
+}
+
+// SetApprovalNotifier enables a cost-impact notification for every action
+// queued for human approval
+func (e *OODAEngine) SetApprovalNotifier(notifier *ApprovalNotifier) {
+	e.approvals = notifier
+}
+
+// RegisterMetrics exposes the engine's phase durations and its opportunity
+// and action counters through reg
+func (e *OODAEngine) RegisterMetrics(reg prometheus.Registerer) error {
+	return e.cycleMetrics.Register(reg)
+}
+
+// CycleSummary describes a completed OODA cycle
+type CycleSummary struct {
+	StartedAt          time.Time     `json:"started_at"`
+	Duration           time.Duration `json:"duration_ns"`
+	ResourcesScanned   int           `json:"resources_scanned"`
+	OpportunitiesFound int           `json:"opportunities_found"`
+	DecisionsMade      int           `json:"decisions_made"`
+	ActionsExecuted    int           `json:"actions_executed"`
+	TopSavings         float64       `json:"top_savings"` // largest estimated monthly savings found
+}
+
+// SetCycleListener calls listener after every completed cycle, on the
+// goroutine that ran it, so it should not block. Failed cycles are not
+// reported.
+func (e *OODAEngine) SetCycleListener(listener func(CycleSummary)) {
+	e.onCycle = listener
 }
 
 // RunCycle executes a complete OODA cycle
 func (e *OODAEngine) RunCycle(ctx context.Context) error {
+	_, err := e.runCycle(ctx)
+	return err
+}
+
+// runCycle executes a cycle and summarizes it
+func (e *OODAEngine) runCycle(ctx context.Context) (CycleSummary, error) {
 	ctx, span := e.tracer.Start(ctx, "ooda.cycle")
 	defer span.End()
 
+	// The duration sample carries this cycle's trace ID as an exemplar
+	start := time.Now()
+	var failedPhase string
+	defer func() { metrics.RecordOODACycle(ctx, time.Since(start), failedPhase) }()
+
+	// Weights changed mid-cycle apply from the next cycle, so every resource
+	// in this one is scored the same way
+	weights := e.VectorWeights()
 	e.logger.Info("Starting OODA cycle")
+	e.logger.Info("Analysis vector weights", weights.logFields()...)
 
 	// OBSERVE: Scan cloud resources
+	phaseStart := time.Now()
 	resources, err := e.observe(ctx)
+	e.cycleMetrics.observePhase("observe", time.Since(phaseStart))
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("observe phase failed: %w", err)
+		failedPhase = "observe"
+		return CycleSummary{}, fmt.Errorf("observe phase failed: %w", err)
 	}
+	e.recordInventory(ctx, fmt.Sprintf("cycle-%d", start.UnixNano()), start, resources)
 
 	// ORIENT: Multi-vector analysis
-	opportunities, err := e.orient(ctx, resources)
+	phaseStart = time.Now()
+	opportunities, err := e.orient(ctx, resources, weights)
+	e.cycleMetrics.observePhase("orient", time.Since(phaseStart))
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("orient phase failed: %w", err)
+		failedPhase = "orient"
+		return CycleSummary{}, fmt.Errorf("orient phase failed: %w", err)
 	}
 
+	// Flag changes made since the last cycle apply to this cycle's decisions
+	e.refreshAutonomy(ctx)
+
 	// DECIDE: Risk assessment and prioritization
+	phaseStart = time.Now()
 	decisions, err := e.decide(ctx, opportunities)
+	e.cycleMetrics.observePhase("decide", time.Since(phaseStart))
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("decide phase failed: %w", err)
+		failedPhase = "decide"
+		return CycleSummary{}, fmt.Errorf("decide phase failed: %w", err)
 	}
 
-	// ACT: Execute optimizations
-	results, err := e.act(ctx, decisions)
+	// ACT: Execute every pending action, including those left over from earlier cycles
+	phaseStart = time.Now()
+	results, err := e.act(ctx)
+	e.cycleMetrics.observePhase("act", time.Since(phaseStart))
 	if err != nil {
 		span.RecordError(err)
-		return fmt.Errorf("act phase failed: %w", err)
+		failedPhase = "act"
+		return CycleSummary{}, fmt.Errorf("act phase failed: %w", err)
 	}
 
+	e.counters.cyclesCompleted.Add(1)
 	e.logger.Info("OODA cycle completed",
 		zap.Int("resources_scanned", len(resources)),
 		zap.Int("opportunities_found", len(opportunities)),
 		zap.Int("decisions_made", len(decisions)),
 		zap.Int("actions_executed", len(results)),
 	)
+	summary := CycleSummary{
+		StartedAt:          start,
+		Duration:           time.Since(start),
+		ResourcesScanned:   len(resources),
+		OpportunitiesFound: len(opportunities),
+		DecisionsMade:      len(decisions),
+		ActionsExecuted:    len(results),
+	}
+	for _, opportunity := range opportunities {
+		summary.TopSavings = max(summary.TopSavings, opportunity.EstimatedSavings)
+	}
+	if e.onCycle != nil {
+		e.onCycle(summary)
+	}
 
-	return nil
+	return summary, nil
 }
 
 // observe scans and collects cloud resources
@@ -162,126 +411,303 @@ func (e *OODAEngine) observe(ctx context.Context) ([]*cloud.ResourceV2, error) {
 	e.logger.Info("Observing cloud resources")
 
 	resources, err := e.cloudAdapter.FetchResources(ctx)
-	if err != nil {
+	if partial, ok := cloud.PartialFetch(err); ok {
+		// Carry on with the regions that answered; the rest are retried next cycle
+		span.RecordError(err)
+		e.logger.Warn("Some regions failed during observe",
+			zap.Int("failed", len(partial.Failures)),
+			zap.Int("succeeded", partial.Succeeded),
+			zap.Error(err),
+		)
+	} else if err != nil {
 		return nil, fmt.Errorf("failed to fetch resources: %w", err)
 	}
 
+	e.cycleMetrics.addResources(len(resources))
 	e.logger.Info("Successfully observed resources", zap.Int("count", len(resources)))
 	return resources, nil
 }
 
+// recordInventory snapshots the observed resources for later backtests.
+// Failures are logged rather than failing the cycle.
+func (e *OODAEngine) recordInventory(ctx context.Context, cycleID string, capturedAt time.Time, resources []*cloud.ResourceV2) {
+	recorder, ok := e.repository.(HistoryRecorder)
+	if !ok || len(resources) == 0 {
+		return
+	}
+
+	items := make([]database.InventoryItem, 0, len(resources))
+	for _, r := range resources {
+		body, err := json.Marshal(r)
+		if err != nil {
+			e.logger.Warn("Failed to encode resource snapshot", zap.String("resource_id", r.ID), zap.Error(err))
+			continue
+		}
+		items = append(items, database.InventoryItem{ResourceID: r.ID, Resource: body})
+	}
+
+	if err := recorder.SaveInventorySnapshot(ctx, cycleID, capturedAt, items); err != nil {
+		e.logger.Warn("Failed to save inventory snapshot", zap.String("cycle_id", cycleID), zap.Error(err))
+	}
+}
+
 // orient performs multi-vector analysis on resources concurrently
-func (e *OODAEngine) orient(ctx context.Context, resources []*cloud.ResourceV2) ([]*OptimizationOpportunity, error) {
+func (e *OODAEngine) orient(ctx context.Context, resources []*cloud.ResourceV2, weights VectorWeights) ([]*OptimizationOpportunity, error) {
 	ctx, span := e.tracer.Start(ctx, "ooda.orient")
 	defer span.End()
 
+	// Protected resources are skipped entirely; the adapter refuses them too
+	var skipped []SkippedResource
+	analyzable := make([]*cloud.ResourceV2, 0, len(resources))
+	for _, r := range resources {
+		if reason := e.config.Protection.ProtectionReason(r); reason != "" {
+			skipped = append(skipped, SkippedResource{ResourceID: r.ID, Reason: SkipReasonProtected, Error: reason})
+			e.logger.Info("Protection event: skipping protected resource",
+				zap.String("resource_id", r.ID),
+				zap.String("reason", reason),
+			)
+			continue
+		}
+		analyzable = append(analyzable, r)
+	}
+	resources = analyzable
+
 	e.logger.Info("Orienting - performing concurrent multi-vector analysis", zap.Int("resource_count", len(resources)))
 
+	// Every analysis in the cycle shares one AI cost budget
+	if e.aiOrchestrator != nil {
+		ctx = e.aiOrchestrator.BeginBudgetScope(ctx)
+	}
+
 	type result struct {
-		opp *OptimizationOpportunity
-		err error
+		resourceID string
+		opp        *OptimizationOpportunity
+		err        error
 	}
 
-	resChan := make(chan result, len(resources))
 	workerCount := e.config.MaxConcurrentAnalysis
 	if workerCount <= 0 {
 		workerCount = 10 // Default safe fallback
 	}
-	if len(resources) < workerCount {
-		workerCount = len(resources)
-	}
-
-	jobs := make(chan *cloud.ResourceV2, len(resources))
-	var wg sync.WaitGroup
-
-	// Start workers
-	for i := 0; i < workerCount; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for r := range jobs {
-				select {
-				case <-ctx.Done():
-					return
-				default:
-					opp, err := e.analyzeResource(ctx, r)
-					resChan <- result{opp, err}
-				}
+
+	// Each analysis writes its own slot; a canceled cycle leaves the rest
+	// unset and orients on what was analyzed
+	results := make([]*result, len(resources))
+	group, _ := e.workers.Group(ctx, workerCount)
+	for i, r := range resources {
+		group.Go(func() error {
+			if ctx.Err() != nil {
+				return nil
 			}
-		}()
+			opp, err := e.analyzeResource(ctx, r, weights)
+			results[i] = &result{r.ID, opp, err}
+			return nil
+		})
 	}
-
-	// Feed jobs
-	go func() {
-		for _, r := range resources {
-			jobs <- r
-		}
-		close(jobs)
-	}()
-
-	// Wait and close results
-	go func() {
-		wg.Wait()
-		close(resChan)
-	}()
+	_ = group.Wait()
 
 	var opportunities []*OptimizationOpportunity
-	for res := range resChan {
+	for _, res := range results {
+		if res == nil {
+			continue
+		}
 		if res.err != nil {
-			e.logger.Warn("Failed to analyze resource", zap.Error(res.err))
+			if errors.Is(res.err, ErrAnalysisTimeout) {
+				e.counters.analysisTimeouts.Add(1)
+				skipped = append(skipped, SkippedResource{
+					ResourceID: res.resourceID,
+					Reason:     SkipReasonAnalysisTimeout,
+					Error:      res.err.Error(),
+				})
+				e.logger.Warn("Skipping resource, analysis timed out",
+					zap.String("resource_id", res.resourceID),
+					zap.String("reason", SkipReasonAnalysisTimeout),
+					zap.Duration("max_analysis_time", e.config.MaxAnalysisTime),
+				)
+				continue
+			}
+			if ai.IsBudgetExceeded(res.err) {
+				skipped = append(skipped, SkippedResource{
+					ResourceID: res.resourceID,
+					Reason:     SkipReasonAIBudget,
+					Error:      res.err.Error(),
+				})
+				e.logger.Warn("Skipping resource, AI cost budget exhausted",
+					zap.String("resource_id", res.resourceID),
+					zap.String("reason", SkipReasonAIBudget),
+					zap.Float64("spent_usd", ai.BudgetSpent(ctx)),
+				)
+				continue
+			}
+			e.counters.analysisFailures.Add(1)
+			e.logger.Warn("Failed to analyze resource", zap.String("resource_id", res.resourceID), zap.Error(res.err))
 			continue
 		}
-		if res.opp != nil && res.opp.EstimatedSavings >= e.config.MinSavingsThreshold {
+		e.counters.resourcesAnalyzed.Add(1)
+		// Quick wins carry no risk, so even small savings are worth taking
+		if res.opp != nil && (res.opp.EstimatedSavings >= e.config.MinSavingsThreshold || res.opp.action().IsQuickWin()) {
 			opportunities = append(opportunities, res.opp)
+			e.cycleMetrics.countOpportunity(string(res.opp.action()))
+			e.recordOpportunity(ctx, res.opp)
 		}
 	}
 
-	e.logger.Info("Orientation completed", zap.Int("opportunities", len(opportunities)))
+	e.skippedMu.Lock()
+	e.lastSkipped = skipped
+	e.skippedMu.Unlock()
+	span.SetAttributes(attribute.Int("ooda.skipped_resources", len(skipped)))
+
+	e.logger.Info("Orientation completed", zap.Int("opportunities", len(opportunities)), zap.Int("skipped", len(skipped)))
 	return opportunities, nil
 }
 
+// recordOpportunity adds an opportunity.found event to the event stream when
+// the repository keeps one
+func (e *OODAEngine) recordOpportunity(ctx context.Context, opportunity *OptimizationOpportunity) {
+	recorder, ok := e.repository.(EventRecorder)
+	if !ok {
+		return
+	}
+	err := recorder.AppendEvent(ctx, database.EventOpportunityFound, opportunity.Resource.ID, map[string]interface{}{
+		"resource_id":       opportunity.Resource.ID,
+		"resource_type":     opportunity.Resource.Type,
+		"action_type":       string(opportunity.action()),
+		"estimated_savings": opportunity.EstimatedSavings,
+		"risk_score":        opportunity.RiskScore,
+		"confidence":        opportunity.Confidence,
+	})
+	if err != nil {
+		e.logger.Warn("Failed to record opportunity event", zap.String("resource_id", opportunity.Resource.ID), zap.Error(err))
+	}
+}
+
 // analyzeResource performs comprehensive analysis on a single resource
-func (e *OODAEngine) analyzeResource(ctx context.Context, resource *cloud.ResourceV2) (*OptimizationOpportunity, error) {
+func (e *OODAEngine) analyzeResource(ctx context.Context, resource *cloud.ResourceV2, weights VectorWeights) (*OptimizationOpportunity, error) {
 	ctx, span := e.tracer.Start(ctx, "ooda.analyze_resource")
 	defer span.End()
 
 	span.SetAttributes(attribute.String("resource.id", resource.ID), attribute.String("resource.type", resource.Type))
 
-	vectors := []AnalysisVector{
-		e.analyzeRightsizing(resource),
-		e.analyzeSpotArbitrage(resource),
-		e.analyzeScheduling(resource),
-		e.analyzeCostPatterns(resource),
+	// Idle load balancers and addresses need no AI analysis, and in-use ones
+	// have nothing else to optimize
+	if action, ok := cloud.QuickWinAction(resource); ok {
+		return quickWinOpportunity(resource, action), nil
+	}
+	if len(cloud.SupportedActions(resource.Type)) > 0 && !cloud.ActionOptimize.ValidFor(resource.Type) {
+		return nil, nil
 	}
 
+	vectors := e.analysisVectors(resource, weights)
+
 	// Calculate weighted risk score
 	riskScore := e.calculateRiskScore(vectors)
 
+	// Bound the AI call so one slow model response can't stall the whole cycle
+	analysisCtx := ctx
+	if e.config.MaxAnalysisTime > 0 {
+		var cancel context.CancelFunc
+		analysisCtx, cancel = context.WithTimeout(ctx, e.config.MaxAnalysisTime)
+		defer cancel()
+	}
+
 	// Generate AI-powered recommendations
-	recommendations, confidence, err := e.generateRecommendations(ctx, resource, vectors)
+	recommendation, err := e.generateRecommendations(analysisCtx, resource, vectors)
 	if err != nil {
+		if ctx.Err() == nil && errors.Is(analysisCtx.Err(), context.DeadlineExceeded) {
+			span.SetAttributes(attribute.String("ooda.skip_reason", SkipReasonAnalysisTimeout))
+			return nil, fmt.Errorf("%w: %s exceeded %s", ErrAnalysisTimeout, resource.ID, e.config.MaxAnalysisTime)
+		}
 		return nil, fmt.Errorf("failed to generate recommendations: %w", err)
 	}
 
+	// The model's own risk assessment takes precedence when it gives one
+	if recommendation.RiskScore > 0 {
+		riskScore = recommendation.RiskScore
+	}
+
 	// Estimate savings
-	estimatedSavings := e.estimateSavings(resource, recommendations)
+	estimatedSavings := e.estimateSavings(resource, recommendation.Recommendations)
 
 	return &OptimizationOpportunity{
 		Resource:         resource,
 		AnalysisVectors:  vectors,
 		RiskScore:        riskScore,
-		Recommendations:  recommendations,
+		Recommendations:  recommendation.Recommendations,
 		EstimatedSavings: estimatedSavings,
-		Confidence:       confidence,
+		Confidence:       recommendation.Confidence,
+		Action:           recommendedAction(resource, recommendation.Recommendations),
 	}, nil
 }
 
+// recommendationVerbs map the verb an AI recommendation leads with to the
+// action that carries it out
+var recommendationVerbs = []struct {
+	verbs  []string
+	action cloud.ActionType
+}{
+	{[]string{"resize", "downsize", "rightsize", "right-size"}, cloud.ActionResize},
+	{[]string{"stop", "shut down", "shutdown"}, cloud.ActionStop},
+	{[]string{"terminate"}, cloud.ActionTerminate},
+}
+
+// recommendedAction picks the concrete action for an analyzed resource: the
+// first recommendation leading with a verb for an action its type supports,
+// otherwise a resize when a target type is known. A resize always needs the
+// target type. Anything else stays cloud.ActionOptimize.
+func recommendedAction(resource *cloud.ResourceV2, recommendations []string) cloud.ActionType {
+	canResize := resource.RightSizeRecommendation != "" && cloud.ActionResize.ValidFor(resource.Type)
+	for _, recommendation := range recommendations {
+		recommendation = strings.ToLower(strings.TrimSpace(recommendation))
+		for _, candidate := range recommendationVerbs {
+			if (candidate.action == cloud.ActionResize && !canResize) || !candidate.action.ValidFor(resource.Type) {
+				continue
+			}
+			for _, verb := range candidate.verbs {
+				if strings.HasPrefix(recommendation, verb) {
+					return candidate.action
+				}
+			}
+		}
+	}
+	if canResize {
+		return cloud.ActionResize
+	}
+	return cloud.ActionOptimize
+}
+
+// quickWinOpportunity is the cleanup of an idle resource, which saves its
+// whole monthly cost
+func quickWinOpportunity(resource *cloud.ResourceV2, action cloud.ActionType) *OptimizationOpportunity {
+	reason := fmt.Sprintf("%s is idle", resource.ID)
+	if since, ok := cloud.IdleSince(resource); ok {
+		reason = fmt.Sprintf("%s has been idle since %s", resource.ID, since.Format("2006-01-02"))
+	}
+	return &OptimizationOpportunity{
+		Resource:         resource,
+		RiskScore:        1,
+		Recommendations:  []string{reason},
+		EstimatedSavings: resource.CostPerMonth,
+		Confidence:       1,
+		Action:           action,
+	}
+}
+
+// analysisVectors runs the rule-based analysis vectors registered for the
+// resource's type and weights them
+func (e *OODAEngine) analysisVectors(resource *cloud.ResourceV2, weights VectorWeights) []AnalysisVector {
+	analyzers := e.vectorsFor(resource.Type)
+	vectors := make([]AnalysisVector, 0, len(analyzers))
+	for _, analyze := range analyzers {
+		vector := analyze(resource)
+		vector.Weight = weights.weight(vector.Name)
+		vectors = append(vectors, vector)
+	}
+	return vectors
+}
+
 // analyzeRightsizing analyzes CPU/memory utilization patterns
 func (e *OODAEngine) analyzeRightsizing(resource *cloud.ResourceV2) AnalysisVector {
-	vector := AnalysisVector{
-		Name:   "rightsizing",
-		Weight: 0.3,
-	}
+	vector := AnalysisVector{Name: VectorRightsizing}
 
 	// CPU utilization analysis
 	if resource.CPUUsage < 0.2 {
@@ -295,8 +721,10 @@ func (e *OODAEngine) analyzeRightsizing(resource *cloud.ResourceV2) AnalysisVect
 		vector.Findings = append(vector.Findings, "CPU utilization within optimal range")
 	}
 
-	// Memory utilization analysis
-	if resource.MemoryUsage < 0.3 {
+	// Memory utilization analysis, skipped when no source measured memory
+	if !resource.MemoryKnown() {
+		vector.Findings = append(vector.Findings, "Memory utilization unknown")
+	} else if resource.MemoryUsage < 0.3 {
 		vector.Score = (vector.Score + 0.8) / 2
 		vector.Findings = append(vector.Findings, "Low memory utilization detected")
 	} else if resource.MemoryUsage > 0.9 {
@@ -308,33 +736,67 @@ func (e *OODAEngine) analyzeRightsizing(resource *cloud.ResourceV2) AnalysisVect
 	return vector
 }
 
-// analyzeSpotArbitrage analyzes spot instance opportunities
+// analyzeSpotArbitrage analyzes spot instance opportunities. When the
+// adapter can look up the spot market, candidates on capacity likely to be
+// interrupted score lower.
 func (e *OODAEngine) analyzeSpotArbitrage(resource *cloud.ResourceV2) AnalysisVector {
-	vector := AnalysisVector{
-		Name:   "spot_arbitrage",
-		Weight: 0.25,
-	}
+	vector := AnalysisVector{Name: VectorSpotArbitrage}
 
 	// Check if resource is suitable for spot instances
-	if resource.Type == "ec2" && resource.CPUUsage < 0.7 {
-		vector.Score = 0.7
-		vector.Findings = append(vector.Findings, "Candidate for spot instance optimization")
-		vector.Confidence = 0.6
-	} else {
+	if resource.Type != "ec2" || resource.CPUUsage >= 0.7 {
 		vector.Score = 0.2
 		vector.Findings = append(vector.Findings, "Not suitable for spot instances")
 		vector.Confidence = 0.8
+		return vector
+	}
+
+	vector.Score = 0.7
+	vector.Findings = append(vector.Findings, "Candidate for spot instance optimization")
+	vector.Confidence = 0.6
+
+	if market, ok := e.spotMarket(resource); ok {
+		vector.Score -= 0.5 * market.InterruptionRisk
+		placement := "unknown"
+		if market.PlacementScore > 0 {
+			placement = fmt.Sprintf("%d/10", market.PlacementScore)
+		}
+		vector.Findings = append(vector.Findings, fmt.Sprintf("Spot interruption risk %.0f%% (placement score %s, price volatility %.0f%%)",
+			market.InterruptionRisk*100, placement, market.PriceVolatility*100))
+		vector.Confidence = 0.8
 	}
 
 	return vector
 }
 
+// spotMarketTimeout bounds a spot market lookup during analysis
+const spotMarketTimeout = 15 * time.Second
+
+// spotMarket looks up the spot market for an instance's type and zone,
+// when the adapter supports it
+func (e *OODAEngine) spotMarket(resource *cloud.ResourceV2) (*cloud.SpotMarket, bool) {
+	provider, ok := e.cloudAdapter.(cloud.SpotMarketProvider)
+	if !ok {
+		return nil, false
+	}
+	zone, _ := resource.Metadata[cloud.AvailabilityZoneKey].(string)
+	instanceType, _ := resource.Metadata["instance_type"].(string)
+	if zone == "" || instanceType == "" {
+		return nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), spotMarketTimeout)
+	defer cancel()
+	market, err := provider.SpotMarket(ctx, zone, instanceType)
+	if err != nil {
+		e.logger.Debug("Spot market lookup failed", zap.String("resource_id", resource.ID), zap.Error(err))
+		return nil, false
+	}
+	return market, true
+}
+
 // analyzeScheduling analyzes scheduling opportunities
 func (e *OODAEngine) analyzeScheduling(resource *cloud.ResourceV2) AnalysisVector {
-	vector := AnalysisVector{
-		Name:   "scheduling",
-		Weight: 0.2,
-	}
+	vector := AnalysisVector{Name: VectorScheduling}
 
 	// Check for non-production workloads
 	if resource.Tags != nil {
@@ -358,10 +820,7 @@ func (e *OODAEngine) analyzeScheduling(resource *cloud.ResourceV2) AnalysisVecto
 
 // analyzeCostPatterns analyzes cost patterns and trends
 func (e *OODAEngine) analyzeCostPatterns(resource *cloud.ResourceV2) AnalysisVector {
-	vector := AnalysisVector{
-		Name:   "cost_patterns",
-		Weight: 0.25,
-	}
+	vector := AnalysisVector{Name: VectorCostPatterns}
 
 	// Analyze cost efficiency
 	if resource.CostPerMonth > 100 {
@@ -394,7 +853,7 @@ func (e *OODAEngine) calculateRiskScore(vectors []AnalysisVector) float64 {
 }
 
 // generateRecommendations uses AI to generate optimization recommendations
-func (e *OODAEngine) generateRecommendations(ctx context.Context, resource *cloud.ResourceV2, vectors []AnalysisVector) ([]string, float64, error) {
+func (e *OODAEngine) generateRecommendations(ctx context.Context, resource *cloud.ResourceV2, vectors []AnalysisVector) (*aiRecommendation, error) {
 	ctx, span := e.tracer.Start(ctx, "ooda.generate_recommendations")
 	defer span.End()
 
@@ -402,15 +861,55 @@ func (e *OODAEngine) generateRecommendations(ctx context.Context, resource *clou
 	analysisContext := e.buildAnalysisContext(resource, vectors)
 
 	// Get AI recommendation
-	response, err := e.aiOrchestrator.Analyze(ctx, analysisContext, e.calculateRiskScore(vectors), resource)
+	response, decision, err := e.aiOrchestrator.AnalyzeDecision(ctx, analysisContext, e.calculateRiskScore(vectors), resource)
 	if err != nil {
-		return nil, 0, fmt.Errorf("AI analysis failed: %w", err)
+		return nil, fmt.Errorf("AI analysis failed: %w", err)
+	}
+	e.recordDecision(ctx, resource, response)
+
+	if decision != nil {
+		return &aiRecommendation{
+			Recommendations: decision.Recommendations,
+			Confidence:      decision.Confidence,
+			RiskScore:       decision.RiskScore,
+		}, nil
+	}
+
+	// The model never matched the schema; read the response as free text
+	recommendation := e.parseRecommendations(response.Content)
+	if recommendation.Confidence == 0 {
+		recommendation.Confidence = response.Confidence
+	}
+	return recommendation, nil
+}
+
+// recordDecision stores the AI response for a resource so backtests can
+// replay it without calling the model again
+func (e *OODAEngine) recordDecision(ctx context.Context, resource *cloud.ResourceV2, response *ai.AIResponse) {
+	recorder, ok := e.repository.(HistoryRecorder)
+	if !ok {
+		return
 	}
 
-	// Parse recommendations from AI response
-	recommendations := e.parseRecommendations(response.Content)
+	confidence := response.Confidence
+	tokens := response.TokensUsed
+	latency := int(response.Latency.Milliseconds())
+	decision := &database.AIDecision{
+		ID:         uuid.NewString(),
+		ResourceID: resource.ID,
+		Model:      response.Model,
+		Decision:   response.Content,
+		Confidence: &confidence,
+		TokensUsed: &tokens,
+		LatencyMs:  &latency,
+	}
+	if response.Reasoning != "" {
+		decision.Reasoning = &response.Reasoning
+	}
 
-	return recommendations, response.Confidence, nil
+	if err := recorder.CreateAIDecision(ctx, decision); err != nil {
+		e.logger.Warn("Failed to record AI decision", zap.String("resource_id", resource.ID), zap.Error(err))
+	}
 }
 
 // buildAnalysisContext builds the analysis context for AI
@@ -437,31 +936,97 @@ Analysis Vectors:
 	context += `
 Please provide specific optimization recommendations for this resource.
 Consider the risk factors and provide actionable steps.
-Format as a list of recommendations.
-`
+Respond with only a JSON object matching this JSON Schema:
+` + ai.DecisionSchemaJSON + "\n"
 
 	return context
 }
 
-// parseRecommendations parses AI response into recommendation list
-func (e *OODAEngine) parseRecommendations(aiResponse string) []string {
-	// Simple parsing - in production, use more sophisticated parsing
+// aiRecommendation is what the engine reads from an AI response
+type aiRecommendation struct {
+	Recommendations []string
+	// Confidence (0-1) and RiskScore (0-10) are zero when the response
+	// doesn't give them
+	Confidence float64
+	RiskScore  float64
+}
+
+// parseRecommendations reads an AI response. A response matching
+// ai.DecisionSchema, or a JSON object shaped like ai.TOPAZDecision as the
+// ROSES prompt asks for, yields its fields, also inside a fenced code block.
+// Anything else is read line by line as a list of recommendations.
+func (e *OODAEngine) parseRecommendations(aiResponse string) *aiRecommendation {
+	if decision, err := ai.ParseDecision(aiResponse); err == nil {
+		return &aiRecommendation{
+			Recommendations: decision.Recommendations,
+			Confidence:      decision.Confidence,
+			RiskScore:       decision.RiskScore,
+		}
+	}
+	if recommendation, ok := parseTOPAZRecommendation(aiResponse); ok {
+		return recommendation
+	}
+
 	var recommendations []string
 
 	// Split by lines and filter non-empty
 	lines := strings.Split(aiResponse, "\n")
 	for _, line := range lines {
 		line = strings.TrimSpace(line)
-		// Filter out empty lines, markdown headers, and list markers
-		if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "Here are") {
+		// Filter out empty lines, code fences, markdown headers, and list markers
+		if line != "" && !strings.HasPrefix(line, "```") && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "Here are") {
 			line = strings.TrimPrefix(line, "- ")
 			line = strings.TrimPrefix(line, "* ")
-			line = strings.TrimPrefix(line, "1. ")
+			line = trimListNumber(line)
 			recommendations = append(recommendations, line)
 		}
 	}
 
-	return recommendations
+	return &aiRecommendation{Recommendations: recommendations}
+}
+
+// parseTOPAZRecommendation reads the JSON object in a response as an
+// ai.TOPAZDecision. It fails unless the object has a recommendation.
+func parseTOPAZRecommendation(aiResponse string) (*aiRecommendation, bool) {
+	start := strings.Index(aiResponse, "{")
+	end := strings.LastIndex(aiResponse, "}")
+	if start < 0 || end < start {
+		return nil, false
+	}
+
+	var decision ai.TOPAZDecision
+	if err := json.Unmarshal([]byte(aiResponse[start:end+1]), &decision); err != nil {
+		return nil, false
+	}
+	recommendation := strings.TrimSpace(decision.Recommendation)
+	if recommendation == "" {
+		return nil, false
+	}
+
+	// The ROSES prompt asks for a 0-100 risk score; models sometimes give
+	// confidence as a percentage too
+	risk := decision.RiskScore
+	if risk > 10 {
+		risk /= 10
+	}
+	confidence := decision.Confidence
+	if confidence > 1 {
+		confidence /= 100
+	}
+	return &aiRecommendation{
+		Recommendations: []string{recommendation},
+		Confidence:      min(max(confidence, 0), 1),
+		RiskScore:       min(max(risk, 0), 10),
+	}, true
+}
+
+// trimListNumber strips an ordered list marker such as "2. " from a line
+func trimListNumber(line string) string {
+	digits := len(line) - len(strings.TrimLeft(line, "0123456789"))
+	if digits > 0 && strings.HasPrefix(line[digits:], ". ") {
+		return line[digits+2:]
+	}
+	return line
 }
 
 // estimateSavings estimates potential savings from recommendations
@@ -489,22 +1054,59 @@ func (e *OODAEngine) decide(ctx context.Context, opportunities []*OptimizationOp
 	var actions []*database.Action
 
 	for _, opportunity := range opportunities {
-		// Check risk threshold
-		if opportunity.RiskScore > e.config.RiskThreshold {
+		switch reason, err := decisionSkipReason(e.config, opportunity); reason {
+		case SkipReasonProtected:
+			e.logger.Info("Protection event: skipping opportunity on protected resource",
+				zap.String("resource_id", opportunity.Resource.ID),
+				zap.String("reason", SkipReasonProtected),
+				zap.Error(err),
+			)
+			continue
+		case SkipReasonRiskThreshold:
 			e.logger.Info("Skipping high-risk opportunity",
 				zap.String("resource_id", opportunity.Resource.ID),
 				zap.Float64("risk_score", opportunity.RiskScore),
 				zap.Float64("threshold", e.config.RiskThreshold),
 			)
 			continue
+		case SkipReasonInvalidAction:
+			e.logger.Info("Skipping opportunity with invalid action",
+				zap.String("resource_id", opportunity.Resource.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+
+		actionType := opportunity.action()
+		mode := e.autonomy.Mode(string(actionType), opportunity.Resource.Tags)
+		if mode == features.AutonomySkip {
+			e.logger.Info("Skipping opportunity disabled by autonomy flags",
+				zap.String("resource_id", opportunity.Resource.ID),
+				zap.String("action_type", string(actionType)),
+				zap.String("reason", SkipReasonAutonomy),
+			)
+			continue
+		}
+
+		// Actions wait for a human decision when the autonomy flags say so;
+		// without a flag, unless approval is disabled or the action is an
+		// auto-approved quick win
+		status := database.ActionStatusPending
+		switch mode {
+		case features.AutonomyApprove:
+			status = database.ActionStatusAwaitingApproval
+		case "":
+			if e.requiresApproval(actionType) {
+				status = database.ActionStatusAwaitingApproval
+			}
 		}
 
 		// Create action record
 		action := &database.Action{
 			ID:               e.generateActionID(opportunity),
 			ResourceID:       opportunity.Resource.ID,
-			ActionType:       "optimize",
-			Status:           "PENDING",
+			ActionType:       string(actionType),
+			Status:           status,
 			Checksum:         e.generateChecksum(opportunity),
 			RiskScore:        opportunity.RiskScore,
 			EstimatedSavings: opportunity.EstimatedSavings,
@@ -512,6 +1114,8 @@ func (e *OODAEngine) decide(ctx context.Context, opportunities []*OptimizationOp
 
 		// Serialize recommendations to payload
 		payload := map[string]interface{}{
+			"resource_key":    opportunity.Resource.CanonicalKey(),
+			"plan":            actionPlan(opportunity.Resource),
 			"recommendations": opportunity.Recommendations,
 			"confidence":      opportunity.Confidence,
 			"vectors":         opportunity.AnalysisVectors,
@@ -519,13 +1123,30 @@ func (e *OODAEngine) decide(ctx context.Context, opportunities []*OptimizationOp
 		payloadBytes, _ := json.Marshal(payload)
 		action.Payload = string(payloadBytes)
 
-		// Store action in database
-		err := e.repository.CreateAction(ctx, action)
+		// Store action in database; actions that need a human decision go to
+		// the approval queue
+		var err error
+		if status == database.ActionStatusAwaitingApproval && e.approvalStore != nil {
+			err = e.approvalStore.CreatePendingApproval(ctx, e.config.OrganizationID, action)
+		} else {
+			err = e.repository.CreateAction(ctx, e.config.OrganizationID, action)
+		}
 		if err != nil {
 			e.logger.Error("Failed to create action", zap.Error(err))
 			continue
 		}
 
+		if status == database.ActionStatusAwaitingApproval {
+			e.cycleMetrics.countAction(actionAwaitingApproval)
+		} else {
+			e.cycleMetrics.countAction(actionQueued)
+		}
+		if status == database.ActionStatusAwaitingApproval && e.approvals != nil {
+			if err := e.approvals.Notify(ctx, e.config.OrganizationID, action.ID); err != nil {
+				e.logger.Warn("Failed to send approval notification", zap.String("action_id", action.ID), zap.Error(err))
+			}
+		}
+
 		actions = append(actions, action)
 	}
 
@@ -533,64 +1154,311 @@ func (e *OODAEngine) decide(ctx context.Context, opportunities []*OptimizationOp
 	return actions, nil
 }
 
-// act executes the optimization actions
-func (e *OODAEngine) act(ctx context.Context, actions []*database.Action) ([]*database.SavingsEvent, error) {
+// decisionSkipReason applies the decide-phase policy in cfg to an
+// opportunity. It returns the reason no action should be created, or "" if
+// one should; backtests call it with candidate configs.
+func decisionSkipReason(cfg *EngineConfig, opportunity *OptimizationOpportunity) (string, error) {
+	if err := cfg.Protection.CheckMutation(opportunity.Resource, string(opportunity.action())); err != nil {
+		return SkipReasonProtected, err
+	}
+	if opportunity.RiskScore > cfg.RiskThreshold {
+		return SkipReasonRiskThreshold, nil
+	}
+	// Never queue an action that execution would reject
+	if _, err := cloud.ValidateAction(opportunity.Resource, string(opportunity.action())); err != nil {
+		return SkipReasonInvalidAction, err
+	}
+	return "", nil
+}
+
+// Action execution orders for EngineConfig.ActionOrder
+const (
+	ActionOrderSavings = "savings" // highest estimated savings first
+	ActionOrderRisk    = "risk"    // lowest risk first
+	ActionOrderCreated = "created" // oldest first
+)
+
+// act drains the pending-action queue and executes the actions on a bounded
+// worker pool, highest priority first. Actions for the same resource run
+// sequentially on one worker so a resource is never changed concurrently.
+func (e *OODAEngine) act(ctx context.Context) ([]*database.SavingsEvent, error) {
 	ctx, span := e.tracer.Start(ctx, "ooda.act")
 	defer span.End()
 
 	e.logger.Info("Acting - executing optimization actions")
 
-	var results []*database.SavingsEvent
-
-	for _, action := range actions {
-		result, err := e.executeAction(ctx, action)
+	// Keyset pagination guarantees progress even if an action stays PENDING
+	var pending []*database.Action
+	var cursor *database.ActionCursor
+	for {
+		actions, next, err := e.repository.GetPendingActionsPage(ctx, e.config.OrganizationID, cursor, e.config.PendingActionsPage)
 		if err != nil {
-			e.logger.Error("Failed to execute action", zap.String("action_id", action.ID), zap.Error(err))
-			continue
+			span.RecordError(err)
+			return nil, fmt.Errorf("failed to load pending actions: %w", err)
 		}
-
-		if result != nil {
-			results = append(results, result)
+		pending = append(pending, actions...)
+		if next == nil {
+			break
 		}
+		cursor = next
 	}
 
-	e.logger.Info("Act phase completed", zap.Int("savings_recorded", len(results)))
+	lanes := e.resourceLanes(pending)
+	workerCount := e.config.ActionConcurrency
+	if workerCount <= 0 {
+		workerCount = 1
+	}
+	if len(lanes) < workerCount {
+		workerCount = len(lanes)
+	}
+	preloaded := &lanePreloader{engine: e, lanes: lanes, batch: workerCount, maxAge: maxPreloadAge}
+
+	var (
+		mu       sync.Mutex
+		results  []*database.SavingsEvent
+		executed atomic.Int64
+	)
+
+	// Lanes start in priority order as workers free up
+	group, _ := e.workers.Group(ctx, workerCount)
+	for l, lane := range lanes {
+		group.Go(func() error {
+			for i, action := range lane {
+				if ctx.Err() != nil {
+					return nil
+				}
+				// Later actions in the lane see the resource as the earlier ones left it
+				var resource *cloud.ResourceV2
+				if i == 0 {
+					resource = preloaded.resource(ctx, l)
+				}
+				executed.Add(1)
+				result, err := e.executeAction(ctx, action, resource)
+				if err != nil {
+					e.cycleMetrics.countAction(actionFailed)
+					e.logger.Error("Failed to execute action", zap.String("action_id", action.ID), zap.Error(err))
+					continue
+				}
+				if result == nil {
+					e.cycleMetrics.countAction(actionSkipped)
+				} else {
+					e.cycleMetrics.countAction(actionSucceeded)
+					mu.Lock()
+					results = append(results, result)
+					mu.Unlock()
+				}
+			}
+			return nil
+		})
+	}
+	// A canceled cycle stops starting lanes; the running ones finish their action
+	_ = group.Wait()
+
+	e.logger.Info("Act phase completed",
+		zap.Int("actions_processed", int(executed.Load())),
+		zap.Int("savings_recorded", len(results)),
+		zap.Int("workers", workerCount),
+	)
 	return results, nil
 }
 
-// executeAction executes a single optimization action
-func (e *OODAEngine) executeAction(ctx context.Context, action *database.Action) (*database.SavingsEvent, error) {
+// resourceLanes groups actions by resource, orders each group by priority and
+// orders the groups by their highest-priority action
+func (e *OODAEngine) resourceLanes(actions []*database.Action) [][]*database.Action {
+	sorted := make([]*database.Action, len(actions))
+	copy(sorted, actions)
+	less := actionLess(e.config.ActionOrder)
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+
+	index := make(map[string]int)
+	var lanes [][]*database.Action
+	for _, action := range sorted {
+		i, ok := index[action.ResourceID]
+		if !ok {
+			i = len(lanes)
+			index[action.ResourceID] = i
+			lanes = append(lanes, nil)
+		}
+		lanes[i] = append(lanes[i], action)
+	}
+	return lanes
+}
+
+// maxPreloadAge is how long a batch of preloaded resources is trusted. A lane
+// starting later has its resources looked up again, so the protection and
+// validation checks of its first action see current state and tags.
+const maxPreloadAge = 30 * time.Second
+
+// lanePreloader looks up the resources of the lanes' first actions in
+// batches of one worker pool's worth of lanes, as the lanes start, rather
+// than all at once before any worker runs
+type lanePreloader struct {
+	engine *OODAEngine
+	lanes  [][]*database.Action
+	batch  int
+	maxAge time.Duration
+
+	mu         sync.Mutex
+	resources  map[string]*cloud.ResourceV2
+	loadedFrom int // lanes in [loadedFrom, loadedTo) have been looked up
+	loadedTo   int
+	loadedAt   time.Time
+}
+
+// resource returns the resource of lane l's first action. The batch holding
+// lane l is looked up if it is not the last one looked up or that one is
+// older than maxAge. Batches are aligned to multiples of the batch size, so
+// lanes of one batch starting out of order share a lookup. nil means
+// executeAction looks the resource up itself.
+func (p *lanePreloader) resource(ctx context.Context, l int) *cloud.ResourceV2 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if l < p.loadedFrom || l >= p.loadedTo || time.Since(p.loadedAt) > p.maxAge {
+		size := max(p.batch, 1)
+		p.loadedFrom = l / size * size
+		p.loadedTo = min(p.loadedFrom+size, len(p.lanes))
+		p.resources = p.engine.preloadResources(ctx, p.lanes[p.loadedFrom:p.loadedTo])
+		p.loadedAt = time.Now()
+	}
+	return p.resources[p.engine.lookupID(p.lanes[l][0])]
+}
+
+// preloadResources looks up the resource of each lane's first action in one
+// batch, rather than with one GetResource call per action. Resources it
+// misses are looked up by executeAction.
+func (e *OODAEngine) preloadResources(ctx context.Context, lanes [][]*database.Action) map[string]*cloud.ResourceV2 {
+	if len(lanes) == 0 {
+		return nil
+	}
+	ids := make([]string, len(lanes))
+	for i, lane := range lanes {
+		ids[i] = e.lookupID(lane[0])
+	}
+	resources, err := e.cloudAdapter.GetResources(ctx, ids)
+	if err != nil {
+		e.logger.Warn("Batch resource lookup failed, looking resources up per action", zap.Error(err))
+	}
+	return resources
+}
+
+// lookupID is the ID an action's resource is looked up by: its canonical key
+// when the adapter spans accounts, otherwise its native ID
+func (e *OODAEngine) lookupID(action *database.Action) string {
+	if _, keyed := e.cloudAdapter.(cloud.ResourceKeyResolver); keyed {
+		if key := resourceKey(action); key != "" {
+			return key
+		}
+	}
+	return action.ResourceID
+}
+
+// actionLess returns the priority ordering for an ActionOrder; ties keep
+// queue (creation) order
+func actionLess(order string) func(a, b *database.Action) bool {
+	switch order {
+	case ActionOrderRisk:
+		return func(a, b *database.Action) bool { return a.RiskScore < b.RiskScore }
+	case ActionOrderCreated:
+		return func(a, b *database.Action) bool { return false }
+	default:
+		return func(a, b *database.Action) bool { return a.EstimatedSavings > b.EstimatedSavings }
+	}
+}
+
+// executeAction executes a single optimization action. resource is the
+// action's resource if the act phase has already looked it up, else nil.
+func (e *OODAEngine) executeAction(ctx context.Context, action *database.Action, resource *cloud.ResourceV2) (*database.SavingsEvent, error) {
 	ctx, span := e.tracer.Start(ctx, "ooda.execute_action")
 	defer span.End()
 
-	// Update action status to in progress
-	now := time.Now()
-	err := e.repository.UpdateActionStatus(ctx, action.ID, "IN_PROGRESS", &now, nil, nil)
+	// Claim the action; another worker or engine instance may already own it
+	claimed, err := e.repository.ClaimAction(ctx, action.ID, time.Now())
 	if err != nil {
-		return nil, fmt.Errorf("failed to update action status: %w", err)
+		return nil, fmt.Errorf("failed to claim action: %w", err)
+	}
+	if !claimed {
+		e.logger.Info("Action already claimed, skipping", zap.String("action_id", action.ID))
+		return nil, nil
+	}
+
+	if resource == nil {
+		if resource, err = e.lookupResource(ctx, action); err != nil {
+			return nil, fmt.Errorf("failed to get resource: %w", err)
+		}
 	}
 
-	// Get resource details
-	resource, err := e.cloudAdapter.GetResource(ctx, action.ResourceID)
+	// An optimize action queued before decide picked concrete actions runs
+	// as the action its plan recommends, so it is checked as that action
+	requested := action.ActionType
+	if requested == string(cloud.ActionOptimize) {
+		requested = string(concreteAction(resource, action))
+	}
+
+	if err := e.config.Protection.CheckMutation(resource, requested); err != nil {
+		e.logger.Warn("Protection event: refusing action on protected resource",
+			zap.String("action_id", action.ID),
+			zap.String("resource_id", resource.ID),
+			zap.Error(err),
+		)
+		errorMsg := err.Error()
+		e.repository.UpdateActionStatus(ctx, action.ID, "FAILED", nil, nil, &errorMsg)
+		return nil, err
+	}
+
+	// Reject actions the resource's type or state does not allow before
+	// anything reaches the cloud adapter
+	actionType, err := cloud.ValidateAction(resource, requested)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get resource: %w", err)
+		e.logger.Warn("Refusing invalid action",
+			zap.String("action_id", action.ID),
+			zap.String("resource_id", resource.ID),
+			zap.Error(err),
+		)
+		errorMsg := err.Error()
+		e.repository.UpdateActionStatus(ctx, action.ID, "FAILED", nil, nil, &errorMsg)
+		return nil, err
+	}
+
+	// The autonomy flags may have changed since the action was queued
+	if e.holdForAutonomy(ctx, action, actionType, resource) {
+		return nil, nil
 	}
 
+	// An operator may have approved this one action with its own dry-run setting
+	if dryRun := dryRunOverride(action); dryRun != nil {
+		e.logger.Info("Executing action with dry-run override",
+			zap.String("action_id", action.ID),
+			zap.Bool("dry_run", *dryRun),
+		)
+		ctx = cloud.WithDryRun(ctx, *dryRun)
+	}
+
+	// Snapshot what the action may change, so a partial failure can be undone
+	snapshot := cloud.SnapshotResource(resource)
+	e.recordPayload(ctx, action.ID, map[string]interface{}{"snapshot": snapshot})
+
 	// Execute optimization based on action type
 	var actualSavings float64
-	switch action.ActionType {
-	case "optimize":
-		actualSavings, err = e.executeOptimization(ctx, resource, action)
-	case "terminate":
+	switch actionType {
+	case cloud.ActionOptimize:
+		actualSavings, err = e.executeOptimization(ctx, resource)
+	case cloud.ActionTerminate:
 		actualSavings, err = e.executeTermination(ctx, resource, action)
+	case cloud.ActionResize:
+		actualSavings, err = e.executeResize(ctx, resource, action)
+	case cloud.ActionStop:
+		actualSavings, err = e.executeStop(ctx, resource)
+	case cloud.ActionDeleteLoadBalancer, cloud.ActionReleaseAddress, cloud.ActionDeleteVolume:
+		actualSavings, err = e.executeCleanup(ctx, resource, actionType)
 	default:
-		err = fmt.Errorf("unknown action type: %s", action.ActionType)
+		err = fmt.Errorf("%w: engine does not execute %s actions", cloud.ErrInvalidAction, actionType)
 	}
 
 	if err != nil {
-		// Update action status to failed
-		errorMsg := err.Error()
-		e.repository.UpdateActionStatus(ctx, action.ID, "FAILED", nil, nil, &errorMsg)
+		// Update action status to failed, or rolled back if the resource
+		// could be restored
+		status, errorMsg := e.rollback(ctx, action, resource, snapshot, err)
+		e.repository.UpdateActionStatus(ctx, action.ID, status, nil, nil, &errorMsg)
 		return nil, fmt.Errorf("action execution failed: %w", err)
 	}
 
@@ -611,25 +1479,206 @@ func (e *OODAEngine) executeAction(ctx context.Context, action *database.Action)
 		ActualSavings:    &actualSavings,
 	}
 
-	err = e.repository.CreateSavingsEvent(ctx, savingsEvent)
+	err = e.repository.CreateSavingsEvent(ctx, e.config.OrganizationID, savingsEvent)
 	if err != nil {
 		e.logger.Warn("Failed to create savings event", zap.Error(err))
 	}
+	metrics.RecordSavingsAccuracy(action.ActionType, action.EstimatedSavings, actualSavings)
 
 	return savingsEvent, nil
 }
 
-// executeOptimization executes resource optimization
-func (e *OODAEngine) executeOptimization(ctx context.Context, resource *cloud.ResourceV2, action *database.Action) (float64, error) {
-	// Parse action payload
-	var payload map[string]interface{}
-	err := json.Unmarshal([]byte(action.Payload), &payload)
+// lookupResource gets an action's resource, by canonical key when the
+// adapter spans accounts. Acting needs the resource's type, state and tags
+// but not its utilization, so adapters that can skip the metrics lookup do.
+func (e *OODAEngine) lookupResource(ctx context.Context, action *database.Action) (*cloud.ResourceV2, error) {
+	resolver, keyed := e.cloudAdapter.(cloud.ResourceKeyResolver)
+	getter, light := e.cloudAdapter.(cloud.MetadataGetter)
+	switch key := resourceKey(action); {
+	case light && keyed && key != "":
+		return getter.GetResourceMetadata(ctx, key)
+	case light:
+		return getter.GetResourceMetadata(ctx, action.ResourceID)
+	case keyed && key != "":
+		return resolver.GetResourceByKey(ctx, key)
+	default:
+		return e.cloudAdapter.GetResource(ctx, action.ResourceID)
+	}
+}
+
+// rollback restores a resource after its action failed, when the adapter
+// can, and records the outcome in the action's payload. It returns the
+// action's final status and error message.
+func (e *OODAEngine) rollback(ctx context.Context, action *database.Action, resource *cloud.ResourceV2, snapshot cloud.ResourceSnapshot, cause error) (string, string) {
+	errorMsg := cause.Error()
+	rollbacker, ok := e.cloudAdapter.(cloud.Rollbacker)
+	if !ok {
+		return "FAILED", errorMsg
+	}
+
+	// A half-applied change is undone even if the cycle was canceled
+	err := rollbacker.Rollback(context.WithoutCancel(ctx), resource, action.ActionType, snapshot)
+	if errors.Is(err, cloud.ErrRollbackUnsupported) {
+		return "FAILED", errorMsg
+	}
+
+	outcome := map[string]interface{}{"succeeded": err == nil}
+	if err != nil {
+		outcome["error"] = err.Error()
+	}
+	e.recordPayload(ctx, action.ID, map[string]interface{}{"rollback": outcome})
+
+	if err != nil {
+		e.logger.Error("Failed to roll back action",
+			zap.String("action_id", action.ID),
+			zap.String("resource_id", resource.ID),
+			zap.Error(err),
+		)
+		return "FAILED", fmt.Sprintf("%s; rollback failed: %v", errorMsg, err)
+	}
+	e.logger.Info("Rolled back failed action",
+		zap.String("action_id", action.ID),
+		zap.String("resource_id", resource.ID),
+	)
+	return "ROLLED_BACK", errorMsg
+}
+
+// recordPayload adds fields to an action's stored payload when the
+// repository supports it
+func (e *OODAEngine) recordPayload(ctx context.Context, actionID string, fields map[string]interface{}) {
+	recorder, ok := e.repository.(PayloadRecorder)
+	if !ok {
+		return
+	}
+	if err := recorder.MergeActionPayload(ctx, actionID, fields); err != nil {
+		e.logger.Warn("Failed to record action payload", zap.String("action_id", actionID), zap.Error(err))
+	}
+}
+
+// resourceKey returns the canonical resource key recorded in an action's
+// payload, or "" for actions created before keys were recorded
+func resourceKey(action *database.Action) string {
+	var payload struct {
+		ResourceKey string `json:"resource_key"`
+	}
+	if err := json.Unmarshal([]byte(action.Payload), &payload); err != nil {
+		return ""
+	}
+	return payload.ResourceKey
+}
+
+// holdForAutonomy puts a claimed action back when the autonomy flags no
+// longer let it run and reports whether it did. The action is checked as
+// actionType, the action it executes as. A skipped action returns to PENDING
+// until its flag allows it again; an action that was never approved goes to
+// AWAITING_APPROVAL when its flag now requires approval, or when it was
+// queued as an optimize action that turned out to be one decide would have
+// queued for approval.
+func (e *OODAEngine) holdForAutonomy(ctx context.Context, action *database.Action, actionType cloud.ActionType, resource *cloud.ResourceV2) bool {
+	var status string
+	switch e.autonomy.Mode(string(actionType), resource.Tags) {
+	case features.AutonomySkip:
+		status = database.ActionStatusPending
+	case features.AutonomyApprove:
+		if approved(action) {
+			return false
+		}
+		status = database.ActionStatusAwaitingApproval
+	default:
+		if string(actionType) == action.ActionType || approved(action) || !e.requiresApproval(actionType) {
+			return false
+		}
+		status = database.ActionStatusAwaitingApproval
+	}
+
+	e.logger.Info("Autonomy flags hold action",
+		zap.String("action_id", action.ID),
+		zap.String("action_type", string(actionType)),
+		zap.String("status", status),
+	)
+	if err := e.repository.UpdateActionStatus(ctx, action.ID, status, nil, nil, nil); err != nil {
+		e.logger.Error("Failed to return held action", zap.String("action_id", action.ID), zap.Error(err))
+		return true
+	}
+	if status == database.ActionStatusAwaitingApproval && e.approvals != nil {
+		if err := e.approvals.Notify(ctx, e.config.OrganizationID, action.ID); err != nil {
+			e.logger.Warn("Failed to send approval notification", zap.String("action_id", action.ID), zap.Error(err))
+		}
+	}
+	return true
+}
+
+// refreshAutonomy picks up autonomy flags changed at runtime, keeping the
+// current ones if they can't be loaded
+func (e *OODAEngine) refreshAutonomy(ctx context.Context) {
+	changed, err := e.autonomy.Refresh(ctx)
 	if err != nil {
-		return 0, fmt.Errorf("failed to parse action payload: %w", err)
+		e.logger.Warn("Failed to refresh autonomy flags", zap.Error(err))
+		return
 	}
+	if changed {
+		flags := e.autonomy.Flags()
+		e.logger.Info("Autonomy flags changed",
+			zap.String("updated_by", flags.UpdatedBy),
+			zap.Time("updated_at", flags.UpdatedAt),
+			zap.String("default", string(flags.Default)),
+			zap.Int("rules", len(flags.Rules)),
+		)
+	}
+}
+
+// approved reports whether a human approved the action
+func approved(action *database.Action) bool {
+	var payload struct {
+		Approved bool `json:"approved"`
+	}
+	if err := json.Unmarshal([]byte(action.Payload), &payload); err != nil {
+		return false
+	}
+	return payload.Approved
+}
+
+// dryRunOverride returns the per-action dry-run override recorded in an
+// action's payload at approval, or nil to use the adapter's configured setting
+func dryRunOverride(action *database.Action) *bool {
+	var payload struct {
+		DryRunOverride *bool `json:"dry_run_override"`
+	}
+	if err := json.Unmarshal([]byte(action.Payload), &payload); err != nil {
+		return nil
+	}
+	return payload.DryRunOverride
+}
 
-	// Execute optimization via cloud adapter
-	savings, err := e.cloudAdapter.ApplyOptimization(ctx, resource, "optimize")
+// requiresApproval reports whether actions of actionType that no autonomy
+// flag selects wait for a human decision
+func (e *OODAEngine) requiresApproval(actionType cloud.ActionType) bool {
+	return e.config.RequireHumanApproval && !(e.config.AutoApproveQuickWins && actionType.AutoApprovable())
+}
+
+// concreteAction resolves an optimize action from its plan and
+// recommendations the way decide picks concrete actions; it stays
+// cloud.ActionOptimize when nothing concrete is recommended
+func concreteAction(resource *cloud.ResourceV2, action *database.Action) cloud.ActionType {
+	var payload struct {
+		Plan            map[string]string `json:"plan"`
+		Recommendations []string          `json:"recommendations"`
+	}
+	if err := json.Unmarshal([]byte(action.Payload), &payload); err != nil {
+		return cloud.ActionOptimize
+	}
+
+	planned := *resource
+	if target := payload.Plan["proposed_type"]; target != "" {
+		planned.RightSizeRecommendation = target
+	}
+	return recommendedAction(&planned, payload.Recommendations)
+}
+
+// executeOptimization executes an optimize action with nothing concrete
+// recommended
+func (e *OODAEngine) executeOptimization(ctx context.Context, resource *cloud.ResourceV2) (float64, error) {
+	savings, err := e.cloudAdapter.ApplyOptimization(ctx, resource, string(cloud.ActionOptimize))
 	if err != nil {
 		return 0, fmt.Errorf("cloud optimization failed: %w", err)
 	}
@@ -638,9 +1687,19 @@ func (e *OODAEngine) executeOptimization(ctx context.Context, resource *cloud.Re
 }
 
 // executeTermination executes resource termination
-func (e *OODAEngine) executeTermination(ctx context.Context, resource *cloud.ResourceV2, _ *database.Action) (float64, error) {
+func (e *OODAEngine) executeTermination(ctx context.Context, resource *cloud.ResourceV2, action *database.Action) (float64, error) {
+	// Termination is irreversible, so only low-risk actions may go ahead;
+	// the adapter applies the resource-level safeguards
+	limit := e.config.MaxTerminationRisk
+	if limit <= 0 {
+		limit = DefaultMaxTerminationRisk
+	}
+	if action.RiskScore >= limit {
+		return 0, fmt.Errorf("%w: risk score %.1f of %s is not below %.1f", cloud.ErrTerminationRefused, action.RiskScore, resource.ID, limit)
+	}
+
 	// Execute termination via cloud adapter
-	savings, err := e.cloudAdapter.ApplyOptimization(ctx, resource, "terminate")
+	savings, err := e.cloudAdapter.ApplyOptimization(ctx, resource, string(cloud.ActionTerminate))
 	if err != nil {
 		return 0, fmt.Errorf("cloud termination failed: %w", err)
 	}
@@ -648,6 +1707,73 @@ func (e *OODAEngine) executeTermination(ctx context.Context, resource *cloud.Res
 	return savings, nil
 }
 
+// executeResize resizes a resource to the type proposed in the action's plan
+func (e *OODAEngine) executeResize(ctx context.Context, resource *cloud.ResourceV2, action *database.Action) (float64, error) {
+	var payload struct {
+		Plan map[string]string `json:"plan"`
+	}
+	if err := json.Unmarshal([]byte(action.Payload), &payload); err != nil {
+		return 0, fmt.Errorf("failed to parse action payload: %w", err)
+	}
+	target := payload.Plan["proposed_type"]
+	if target == "" {
+		return 0, fmt.Errorf("%w: action %s has no proposed type", cloud.ErrInvalidAction, action.ID)
+	}
+
+	// The adapter reads the target from the resource; the observed resource
+	// is left as it was
+	resized := *resource
+	resized.RightSizeRecommendation = target
+	savings, err := e.cloudAdapter.ApplyOptimization(ctx, &resized, string(cloud.ActionResize))
+	if err != nil {
+		return 0, fmt.Errorf("cloud resize failed: %w", err)
+	}
+
+	return savings, nil
+}
+
+// executeStop stops a resource
+func (e *OODAEngine) executeStop(ctx context.Context, resource *cloud.ResourceV2) (float64, error) {
+	savings, err := e.cloudAdapter.ApplyOptimization(ctx, resource, string(cloud.ActionStop))
+	if err != nil {
+		return 0, fmt.Errorf("cloud stop failed: %w", err)
+	}
+
+	return savings, nil
+}
+
+// executeCleanup deletes an idle load balancer or unattached volume, or
+// releases an unattached address; the adapter checks again that the resource
+// is idle
+func (e *OODAEngine) executeCleanup(ctx context.Context, resource *cloud.ResourceV2, actionType cloud.ActionType) (float64, error) {
+	savings, err := e.cloudAdapter.ApplyOptimization(ctx, resource, string(actionType))
+	if err != nil {
+		return 0, fmt.Errorf("cloud cleanup failed: %w", err)
+	}
+
+	return savings, nil
+}
+
+// Metrics returns a snapshot of the engine's cumulative counters
+func (e *OODAEngine) Metrics() EngineMetrics {
+	return EngineMetrics{
+		CyclesCompleted:   e.counters.cyclesCompleted.Load(),
+		ResourcesAnalyzed: e.counters.resourcesAnalyzed.Load(),
+		AnalysisFailures:  e.counters.analysisFailures.Load(),
+		AnalysisTimeouts:  e.counters.analysisTimeouts.Load(),
+	}
+}
+
+// LastSkipped returns the resources skipped during the most recent orient phase
+func (e *OODAEngine) LastSkipped() []SkippedResource {
+	e.skippedMu.RLock()
+	defer e.skippedMu.RUnlock()
+
+	skipped := make([]SkippedResource, len(e.lastSkipped))
+	copy(skipped, e.lastSkipped)
+	return skipped
+}
+
 // generateActionID generates a unique action ID
 func (e *OODAEngine) generateActionID(opportunity *OptimizationOpportunity) string {
 	timestamp := time.Now().Unix()
@@ -662,8 +1788,10 @@ func (e *OODAEngine) generateSavingsEventID(action *database.Action) string {
 
 // generateChecksum generates a checksum for idempotency
 func (e *OODAEngine) generateChecksum(opportunity *OptimizationOpportunity) string {
+	// The canonical key includes account and region, so identical native IDs
+	// in different accounts never share a checksum
 	data := fmt.Sprintf("%s-%s-%v",
-		opportunity.Resource.ID,
+		opportunity.Resource.CanonicalKey(),
 		opportunity.Resource.Type,
 		opportunity.Recommendations)
 
@@ -683,6 +1811,10 @@ func DefaultEngineConfig() *EngineConfig {
 		EnableAutoExecution:   false,
 		RequireHumanApproval:  true,
 		DefaultSavingsRatio:   0.2,
+		PendingActionsPage:    database.DefaultPendingActionsPageSize,
+		ActionConcurrency:     4,
+		ActionOrder:           ActionOrderSavings,
+		Protection:            cloud.ProtectionPolicy{ResourceIDs: append([]string(nil), ai.DefaultProtectedResources...)},
 	}
 }
 
@@ -698,5 +1830,9 @@ func ProductionEngineConfig() *EngineConfig {
 		EnableAutoExecution:   false,
 		RequireHumanApproval:  true,
 		DefaultSavingsRatio:   0.2,
+		PendingActionsPage:    database.DefaultPendingActionsPageSize,
+		ActionConcurrency:     8,
+		ActionOrder:           ActionOrderSavings,
+		Protection:            cloud.ProtectionPolicy{ResourceIDs: append([]string(nil), ai.DefaultProtectedResources...)},
 	}
 }
@@ -2,14 +2,19 @@ package engine
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/Xover-Official/Xover/internal/ai"
 	"github.com/Xover-Official/Xover/internal/cloud"
 	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/Xover-Official/Xover/internal/features"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
@@ -30,6 +35,11 @@ func (m *MockCloudAdapter) GetResource(ctx context.Context, id string) (*cloud.R
 	return args.Get(0).(*cloud.ResourceV2), args.Error(1)
 }
 
+// GetResources finds nothing, so the engine looks each resource up itself
+func (m *MockCloudAdapter) GetResources(ctx context.Context, ids []string) (map[string]*cloud.ResourceV2, error) {
+	return nil, nil
+}
+
 func (m *MockCloudAdapter) ApplyOptimization(ctx context.Context, resource *cloud.ResourceV2, action string) (float64, error) {
 	args := m.Called(ctx, resource, action)
 	return args.Get(0).(float64), args.Error(1)
@@ -49,8 +59,8 @@ type MockRepository struct {
 	mock.Mock
 }
 
-func (m *MockRepository) CreateAction(ctx context.Context, action *database.Action) error {
-	args := m.Called(ctx, action)
+func (m *MockRepository) CreateAction(ctx context.Context, orgID string, action *database.Action) error {
+	args := m.Called(ctx, orgID, action)
 	return args.Error(0)
 }
 
@@ -59,11 +69,21 @@ func (m *MockRepository) UpdateActionStatus(ctx context.Context, id string, stat
 	return args.Error(0)
 }
 
-func (m *MockRepository) CreateSavingsEvent(ctx context.Context, event *database.SavingsEvent) error {
-	args := m.Called(ctx, event)
+func (m *MockRepository) ClaimAction(ctx context.Context, id string, startedAt time.Time) (bool, error) {
+	args := m.Called(ctx, id, startedAt)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRepository) CreateSavingsEvent(ctx context.Context, orgID string, event *database.SavingsEvent) error {
+	args := m.Called(ctx, orgID, event)
 	return args.Error(0)
 }
 
+func (m *MockRepository) GetPendingActionsPage(ctx context.Context, orgID string, after *database.ActionCursor, limit int) ([]*database.Action, *database.ActionCursor, error) {
+	args := m.Called(ctx, orgID, after, limit)
+	return args.Get(0).([]*database.Action), args.Get(1).(*database.ActionCursor), args.Error(2)
+}
+
 type MockAIClient struct {
 	mock.Mock
 }
@@ -73,6 +93,10 @@ func (m *MockAIClient) Analyze(ctx context.Context, request ai.AIRequest) (*ai.A
 	return args.Get(0).(*ai.AIResponse), args.Error(1)
 }
 
+func (m *MockAIClient) AnalyzeStream(ctx context.Context, request ai.AIRequest) (<-chan ai.StreamChunk, error) {
+	return ai.StreamAnalyze(ctx, request, m.Analyze), nil
+}
+
 func (m *MockAIClient) GetEstimatedCost(request ai.AIRequest) float64 { return 0.0 }
 func (m *MockAIClient) GetModel() string                              { return "mock-model" }
 func (m *MockAIClient) GetTier() int                                  { return 1 }
@@ -155,7 +179,7 @@ func TestOODAEngine_Orient(t *testing.T) {
 	mockAIClient.On("Analyze", mock.Anything, mock.Anything).Return(mockAIResponse, nil)
 
 	// Execute
-	opportunities, err := engine.orient(context.Background(), resources)
+	opportunities, err := engine.orient(context.Background(), resources, engine.VectorWeights())
 
 	// Verify
 	assert.NoError(t, err)
@@ -173,3 +197,1156 @@ func TestOODAEngine_Orient(t *testing.T) {
 	}
 	assert.Greater(t, rightsizingScore, 0.7, "Rightsizing score should be high for underutilized resource")
 }
+
+func TestOODAEngine_OrientAnalysisTimeout(t *testing.T) {
+	mockAdapter := new(MockCloudAdapter)
+	mockRepo := new(MockRepository)
+	slowAIClient := new(MockAIClient)
+	logger := zap.NewNop()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+
+	orchestrator, err := ai.NewUnifiedOrchestrator(&ai.Config{}, nil, logger)
+	assert.NoError(t, err)
+	orchestrator.GetFactory().SetClient("sentinel", slowAIClient)
+	orchestrator.GetFactory().SetClient("strategist", slowAIClient)
+
+	config := DefaultEngineConfig()
+	config.MaxAnalysisTime = 50 * time.Millisecond
+	engine := NewOODAEngine(orchestrator, mockAdapter, mockRepo, nil, logger, tracer, config)
+
+	// Block until the per-resource deadline fires
+	slowAIClient.On("Analyze", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Return((*ai.AIResponse)(nil), context.DeadlineExceeded)
+
+	resources := []*cloud.ResourceV2{
+		{ID: "slow-1", Type: "ec2", CPUUsage: 0.05, MemoryUsage: 0.1, CostPerMonth: 50},
+	}
+
+	start := time.Now()
+	opportunities, err := engine.orient(context.Background(), resources, engine.VectorWeights())
+
+	assert.NoError(t, err)
+	assert.Empty(t, opportunities)
+	assert.Less(t, time.Since(start), 2*time.Second, "orient should not stall on a slow AI call")
+	assert.Equal(t, int64(1), engine.Metrics().AnalysisTimeouts)
+
+	skipped := engine.LastSkipped()
+	assert.Len(t, skipped, 1)
+	assert.Equal(t, "slow-1", skipped[0].ResourceID)
+	assert.Equal(t, SkipReasonAnalysisTimeout, skipped[0].Reason)
+}
+
+func TestOODAEngine_OrientAIBudget(t *testing.T) {
+	mockAdapter := new(MockCloudAdapter)
+	mockRepo := new(MockRepository)
+	aiClient := new(MockAIClient)
+	logger := zap.NewNop()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+
+	orchestrator, err := ai.NewUnifiedOrchestrator(&ai.Config{MaxCostPerCycleUSD: 0.05}, nil, logger)
+	assert.NoError(t, err)
+	orchestrator.GetFactory().SetClient("sentinel", aiClient)
+	orchestrator.GetFactory().SetClient("strategist", aiClient)
+
+	config := DefaultEngineConfig()
+	config.MaxConcurrentAnalysis = 1
+	engine := NewOODAEngine(orchestrator, mockAdapter, mockRepo, nil, logger, tracer, config)
+
+	aiClient.On("Analyze", mock.Anything, mock.Anything).
+		Return(&ai.AIResponse{Content: "- Downsize to t3.micro", Confidence: 0.9, CostUSD: 0.03}, nil)
+
+	resources := []*cloud.ResourceV2{
+		{ID: "res-1", Type: "ec2", CPUUsage: 0.05, MemoryUsage: 0.1, CostPerMonth: 50},
+		{ID: "res-2", Type: "ec2", CPUUsage: 0.05, MemoryUsage: 0.1, CostPerMonth: 50},
+		{ID: "res-3", Type: "ec2", CPUUsage: 0.05, MemoryUsage: 0.1, CostPerMonth: 50},
+	}
+	_, err = engine.orient(context.Background(), resources, engine.VectorWeights())
+	assert.NoError(t, err)
+
+	// $0.06 is spent after two analyses, so the third is skipped
+	aiClient.AssertNumberOfCalls(t, "Analyze", 2)
+	skipped := engine.LastSkipped()
+	if assert.Len(t, skipped, 1) {
+		assert.Equal(t, SkipReasonAIBudget, skipped[0].Reason)
+	}
+	assert.Equal(t, int64(0), engine.Metrics().AnalysisFailures)
+
+	// Each cycle gets a fresh budget
+	_, err = engine.orient(context.Background(), resources[:1], engine.VectorWeights())
+	assert.NoError(t, err)
+	aiClient.AssertNumberOfCalls(t, "Analyze", 3)
+}
+
+func TestOODAEngine_ActDrainsAllPages(t *testing.T) {
+	mockAdapter := new(MockCloudAdapter)
+	mockRepo := new(MockRepository)
+	logger := zap.NewNop()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+
+	config := DefaultEngineConfig()
+	config.PendingActionsPage = 2
+	engine := NewOODAEngine(nil, mockAdapter, mockRepo, nil, logger, tracer, config)
+
+	created := time.Now()
+	firstPage := []*database.Action{
+		{ID: "a1", ResourceID: "res-1", ActionType: "optimize", CreatedAt: created},
+		{ID: "a2", ResourceID: "res-2", ActionType: "optimize", CreatedAt: created},
+	}
+	cursor := &database.ActionCursor{CreatedAt: created, ID: "a2"}
+	secondPage := []*database.Action{
+		{ID: "a3", ResourceID: "res-3", ActionType: "optimize", CreatedAt: created},
+	}
+
+	mockRepo.On("GetPendingActionsPage", mock.Anything, "", (*database.ActionCursor)(nil), 2).Return(firstPage, cursor, nil)
+	mockRepo.On("GetPendingActionsPage", mock.Anything, "", cursor, 2).Return(secondPage, (*database.ActionCursor)(nil), nil)
+	mockRepo.On("ClaimAction", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	mockAdapter.On("GetResource", mock.Anything, mock.Anything).Return((*cloud.ResourceV2)(nil), assert.AnError)
+
+	_, err := engine.act(context.Background())
+
+	assert.NoError(t, err)
+	mockRepo.AssertNumberOfCalls(t, "GetPendingActionsPage", 2)
+	mockRepo.AssertNumberOfCalls(t, "ClaimAction", 3)
+}
+
+func TestOODAEngine_SkipsProtectedResources(t *testing.T) {
+	mockAdapter := new(MockCloudAdapter)
+	mockRepo := new(MockRepository)
+	logger := zap.NewNop()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+
+	config := DefaultEngineConfig()
+	config.Protection = cloud.ProtectionPolicy{ResourceIDs: []string{"db-prod-*"}}
+	engine := NewOODAEngine(nil, mockAdapter, mockRepo, nil, logger, tracer, config)
+
+	resources := []*cloud.ResourceV2{
+		{ID: "db-prod-01", Type: "rds", CostPerMonth: 450},
+		{ID: "web-01", Type: "ec2", CostPerMonth: 50, Tags: map[string]string{cloud.ProtectedTagKey: "true"}},
+	}
+
+	opportunities, err := engine.orient(context.Background(), resources, engine.VectorWeights())
+
+	assert.NoError(t, err)
+	assert.Empty(t, opportunities)
+	skipped := engine.LastSkipped()
+	assert.Len(t, skipped, 2)
+	for _, s := range skipped {
+		assert.Equal(t, SkipReasonProtected, s.Reason)
+	}
+
+	// Actions queued before the resource became protected are refused
+	protected := resources[1]
+	mockRepo.On("GetPendingActionsPage", mock.Anything, "", (*database.ActionCursor)(nil), config.PendingActionsPage).
+		Return([]*database.Action{{ID: "a1", ResourceID: protected.ID, ActionType: "terminate"}}, (*database.ActionCursor)(nil), nil)
+	mockRepo.On("ClaimAction", mock.Anything, "a1", mock.Anything).Return(true, nil)
+	mockRepo.On("UpdateActionStatus", mock.Anything, "a1", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockAdapter.On("GetResource", mock.Anything, protected.ID).Return(protected, nil)
+
+	results, err := engine.act(context.Background())
+
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+	mockAdapter.AssertNotCalled(t, "ApplyOptimization", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertCalled(t, "UpdateActionStatus", mock.Anything, "a1", "FAILED", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestOODAEngine_DecideQueuesForApproval(t *testing.T) {
+	logger := zap.NewNop()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+	opportunities := []*OptimizationOpportunity{
+		{Resource: &cloud.ResourceV2{ID: "web-01", Type: "ec2"}, RiskScore: 2, EstimatedSavings: 40},
+	}
+
+	for _, requireApproval := range []bool{true, false} {
+		mockRepo := new(MockRepository)
+		mockRepo.On("CreateAction", mock.Anything, "", mock.Anything).Return(nil)
+
+		config := DefaultEngineConfig()
+		config.RequireHumanApproval = requireApproval
+		engine := NewOODAEngine(nil, new(MockCloudAdapter), mockRepo, nil, logger, tracer, config)
+
+		actions, err := engine.decide(context.Background(), opportunities)
+
+		assert.NoError(t, err)
+		assert.Len(t, actions, 1)
+		want := database.ActionStatusPending
+		if requireApproval {
+			want = database.ActionStatusAwaitingApproval
+		}
+		assert.Equal(t, want, actions[0].Status)
+	}
+}
+
+func TestOODAEngine_DecideSkipsProtectedResources(t *testing.T) {
+	opportunity := func(id string, tags map[string]string) *OptimizationOpportunity {
+		return &OptimizationOpportunity{Resource: &cloud.ResourceV2{ID: id, Type: "ec2", Tags: tags}, RiskScore: 2, EstimatedSavings: 40}
+	}
+	opportunities := []*OptimizationOpportunity{
+		opportunity("db-prod-orders", nil),
+		opportunity("i-0abc", map[string]string{"Name": "payment-gateway"}),
+		opportunity("db-staging-orders", nil),
+		opportunity("i-0def", map[string]string{"Name": "web-01"}),
+		opportunity("i-0ghi", map[string]string{cloud.ProtectedTagKey: cloud.ProtectedTagValue}),
+	}
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("CreateAction", mock.Anything, "", mock.Anything).Return(nil)
+	engine := NewOODAEngine(nil, new(MockCloudAdapter), mockRepo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+
+	actions, err := engine.decide(context.Background(), opportunities)
+	require.NoError(t, err)
+	var ids []string
+	for _, action := range actions {
+		ids = append(ids, action.ResourceID)
+	}
+	assert.Equal(t, []string{"db-staging-orders", "i-0def"}, ids, "TOPAZ's patterns protect IDs and tag values by default")
+
+	// A configured policy replaces the defaults; the protection tag always applies
+	config := DefaultEngineConfig()
+	config.Protection = cloud.ProtectionPolicy{ResourceIDs: []string{"db-*"}}
+	engine = NewOODAEngine(nil, new(MockCloudAdapter), mockRepo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), config)
+
+	actions, err = engine.decide(context.Background(), opportunities)
+	require.NoError(t, err)
+	ids = nil
+	for _, action := range actions {
+		ids = append(ids, action.ResourceID)
+	}
+	assert.Equal(t, []string{"i-0abc", "i-0def"}, ids)
+}
+
+func TestOODAEngine_DecideAutoApprovesQuickWins(t *testing.T) {
+	idle := map[string]interface{}{cloud.IdleSinceKey: time.Now().Add(-30 * 24 * time.Hour)}
+	resource := &cloud.ResourceV2{ID: "eipalloc-1", Type: cloud.ResourceTypeElasticIP, State: "unassociated", CostPerMonth: 3.65, Metadata: idle}
+
+	for _, autoApprove := range []bool{true, false} {
+		mockRepo := new(MockRepository)
+		mockRepo.On("CreateAction", mock.Anything, "", mock.Anything).Return(nil)
+
+		config := DefaultEngineConfig()
+		config.AutoApproveQuickWins = autoApprove
+		engine := NewOODAEngine(nil, new(MockCloudAdapter), mockRepo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), config)
+
+		// Quick wins skip the AI and the minimum savings threshold
+		opportunity, err := engine.analyzeResource(context.Background(), resource, DefaultVectorWeights())
+		require.NoError(t, err)
+		assert.Equal(t, cloud.ActionReleaseAddress, opportunity.Action)
+		assert.Equal(t, 3.65, opportunity.EstimatedSavings)
+
+		actions, err := engine.decide(context.Background(), []*OptimizationOpportunity{opportunity})
+		require.NoError(t, err)
+		require.Len(t, actions, 1)
+		assert.Equal(t, string(cloud.ActionReleaseAddress), actions[0].ActionType)
+		want := database.ActionStatusAwaitingApproval
+		if autoApprove {
+			want = database.ActionStatusPending
+		}
+		assert.Equal(t, want, actions[0].Status)
+	}
+
+	// Quick wins need an explicit opt-in
+	assert.False(t, DefaultEngineConfig().AutoApproveQuickWins)
+	assert.False(t, ProductionEngineConfig().AutoApproveQuickWins)
+
+	// Deleting a volume destroys its data, so it always waits for approval
+	volume := &cloud.ResourceV2{ID: "vol-1", Type: cloud.ResourceTypeEBS, State: "available", CostPerMonth: 40, Metadata: idle}
+	mockRepo := new(MockRepository)
+	mockRepo.On("CreateAction", mock.Anything, "", mock.Anything).Return(nil)
+	config := DefaultEngineConfig()
+	config.AutoApproveQuickWins = true
+	engine := NewOODAEngine(nil, new(MockCloudAdapter), mockRepo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), config)
+	opportunity, err := engine.analyzeResource(context.Background(), volume, DefaultVectorWeights())
+	require.NoError(t, err)
+	actions, err := engine.decide(context.Background(), []*OptimizationOpportunity{opportunity})
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.Equal(t, string(cloud.ActionDeleteVolume), actions[0].ActionType)
+	assert.Equal(t, database.ActionStatusAwaitingApproval, actions[0].Status)
+
+	// An in-use load balancer has nothing to optimize
+	opportunity, err = NewOODAEngine(nil, nil, nil, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig()).
+		analyzeResource(context.Background(), &cloud.ResourceV2{ID: "arn:lb", Type: cloud.ResourceTypeLoadBalancer}, DefaultVectorWeights())
+	assert.NoError(t, err)
+	assert.Nil(t, opportunity)
+}
+
+func TestOODAEngine_ChecksumDistinguishesAccounts(t *testing.T) {
+	engine := NewOODAEngine(nil, nil, nil, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+
+	opportunity := func(account, region string) *OptimizationOpportunity {
+		return &OptimizationOpportunity{
+			Resource:        &cloud.ResourceV2{ID: "i-0abc", Type: "ec2", Provider: "aws", Account: account, Region: region},
+			Recommendations: []string{"resize"},
+		}
+	}
+
+	base := engine.generateChecksum(opportunity("111", "us-east-1"))
+	assert.Equal(t, base, engine.generateChecksum(opportunity("111", "us-east-1")))
+	assert.NotEqual(t, base, engine.generateChecksum(opportunity("222", "us-east-1")))
+	assert.NotEqual(t, base, engine.generateChecksum(opportunity("111", "eu-west-1")))
+}
+
+func TestOODAEngine_ActOrdersBySavingsAndSkipsClaimed(t *testing.T) {
+	mockAdapter := new(MockCloudAdapter)
+	mockRepo := new(MockRepository)
+	logger := zap.NewNop()
+	tracer := trace.NewNoopTracerProvider().Tracer("")
+
+	config := DefaultEngineConfig()
+	config.ActionConcurrency = 1
+	engine := NewOODAEngine(nil, mockAdapter, mockRepo, nil, logger, tracer, config)
+
+	pending := []*database.Action{
+		{ID: "small", ResourceID: "res-1", ActionType: "optimize", EstimatedSavings: 5},
+		{ID: "large", ResourceID: "res-2", ActionType: "optimize", EstimatedSavings: 500},
+		{ID: "taken", ResourceID: "res-3", ActionType: "optimize", EstimatedSavings: 50},
+	}
+	var claimed []string
+	mockRepo.On("GetPendingActionsPage", mock.Anything, "", (*database.ActionCursor)(nil), config.PendingActionsPage).
+		Return(pending, (*database.ActionCursor)(nil), nil)
+	mockRepo.On("ClaimAction", mock.Anything, "taken", mock.Anything).
+		Run(func(args mock.Arguments) { claimed = append(claimed, "taken") }).Return(false, nil)
+	mockRepo.On("ClaimAction", mock.Anything, mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { claimed = append(claimed, args.String(1)) }).Return(true, nil)
+	mockAdapter.On("GetResource", mock.Anything, mock.Anything).Return((*cloud.ResourceV2)(nil), assert.AnError)
+
+	_, err := engine.act(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"large", "taken", "small"}, claimed)
+	// An action claimed elsewhere is never executed
+	mockAdapter.AssertNotCalled(t, "GetResource", mock.Anything, "res-3")
+}
+
+func TestOODAEngine_ResourceLanes(t *testing.T) {
+	config := DefaultEngineConfig()
+	config.ActionOrder = ActionOrderRisk
+	engine := NewOODAEngine(nil, nil, nil, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), config)
+
+	lanes := engine.resourceLanes([]*database.Action{
+		{ID: "a1", ResourceID: "res-1", RiskScore: 4},
+		{ID: "a2", ResourceID: "res-2", RiskScore: 1},
+		{ID: "a3", ResourceID: "res-1", RiskScore: 2},
+	})
+
+	// Each resource gets exactly one lane, so no two workers touch it at once
+	assert.Len(t, lanes, 2)
+	assert.Equal(t, "a2", lanes[0][0].ID)
+	assert.Equal(t, []string{"a3", "a1"}, []string{lanes[1][0].ID, lanes[1][1].ID})
+}
+
+func TestOODAEngine_RightsizingSkipsUnknownMemory(t *testing.T) {
+	engine := NewOODAEngine(nil, nil, nil, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+
+	// Busy CPU with no memory metric: the missing memory must not read as idle
+	unknown := engine.analyzeRightsizing(&cloud.ResourceV2{CPUUsage: 0.5})
+	assert.Equal(t, 0.5, unknown.Score)
+	assert.Contains(t, unknown.Findings, "Memory utilization unknown")
+
+	measured := engine.analyzeRightsizing(&cloud.ResourceV2{CPUUsage: 0.5, MemoryMetricAvailable: true})
+	assert.Equal(t, 0.65, measured.Score)
+}
+
+func TestOODAEngine_ExecuteHonorsDryRunOverride(t *testing.T) {
+	mockAdapter := new(MockCloudAdapter)
+	mockRepo := new(MockRepository)
+	engine := NewOODAEngine(nil, mockAdapter, mockRepo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+
+	resource := &cloud.ResourceV2{ID: "web-01", Type: cloud.ResourceTypeEC2, CostPerMonth: 100}
+	mockRepo.On("ClaimAction", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	mockRepo.On("UpdateActionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateSavingsEvent", mock.Anything, "", mock.Anything).Return(nil)
+	mockAdapter.On("GetResource", mock.Anything, resource.ID).Return(resource, nil)
+
+	var dryRuns []bool
+	mockAdapter.On("ApplyOptimization", mock.Anything, resource, "optimize").
+		Run(func(args mock.Arguments) {
+			dryRuns = append(dryRuns, cloud.DryRun(args.Get(0).(context.Context), true))
+		}).Return(50.0, nil)
+
+	// An operator approved the first action to run live; the second follows the global setting
+	for _, payload := range []string{`{"dry_run_override": false}`, `{}`} {
+		_, err := engine.executeAction(context.Background(), &database.Action{
+			ID: "a1", ResourceID: resource.ID, ActionType: "optimize", Payload: payload,
+		}, nil)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []bool{false, true}, dryRuns)
+}
+
+func TestOODAEngine_ExecuteResizeUsesPlannedType(t *testing.T) {
+	mockAdapter := new(MockCloudAdapter)
+	mockRepo := new(MockRepository)
+	engine := NewOODAEngine(nil, mockAdapter, mockRepo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+
+	resource := &cloud.ResourceV2{ID: "web-01", Type: cloud.ResourceTypeEC2, State: "running", CostPerMonth: 120}
+	mockRepo.On("ClaimAction", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	mockRepo.On("UpdateActionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateSavingsEvent", mock.Anything, "", mock.Anything).Return(nil)
+	mockAdapter.On("GetResource", mock.Anything, resource.ID).Return(resource, nil)
+	mockAdapter.On("ApplyOptimization", mock.Anything, mock.MatchedBy(func(r *cloud.ResourceV2) bool {
+		return r.RightSizeRecommendation == "m5.large"
+	}), "resize").Return(60.0, nil)
+
+	_, err := engine.executeAction(context.Background(), &database.Action{
+		ID: "a1", ResourceID: resource.ID, ActionType: "resize",
+		Payload: `{"plan": {"current_type": "m5.xlarge", "proposed_type": "m5.large"}}`,
+	}, nil)
+	require.NoError(t, err)
+	assert.Empty(t, resource.RightSizeRecommendation, "the observed resource is not modified")
+
+	_, err = engine.executeAction(context.Background(), &database.Action{
+		ID: "a2", ResourceID: resource.ID, ActionType: "resize", Payload: `{"plan": {}}`,
+	}, nil)
+	assert.ErrorIs(t, err, cloud.ErrInvalidAction)
+}
+
+func TestOODAEngine_ExecuteTerminateRequiresLowRisk(t *testing.T) {
+	mockAdapter := new(MockCloudAdapter)
+	mockRepo := new(MockRepository)
+	engine := NewOODAEngine(nil, mockAdapter, mockRepo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+
+	resource := &cloud.ResourceV2{ID: "web-01", Type: cloud.ResourceTypeEC2, State: "stopped", CostPerMonth: 80}
+	mockRepo.On("ClaimAction", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	mockRepo.On("UpdateActionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateSavingsEvent", mock.Anything, "", mock.Anything).Return(nil)
+	mockAdapter.On("GetResource", mock.Anything, resource.ID).Return(resource, nil)
+	mockAdapter.On("ApplyOptimization", mock.Anything, resource, "terminate").Return(80.0, nil).Once()
+
+	_, err := engine.executeAction(context.Background(), &database.Action{
+		ID: "a1", ResourceID: resource.ID, ActionType: "terminate", RiskScore: DefaultMaxTerminationRisk,
+	}, nil)
+	assert.ErrorIs(t, err, cloud.ErrTerminationRefused)
+	mockAdapter.AssertNotCalled(t, "ApplyOptimization", mock.Anything, mock.Anything, mock.Anything)
+
+	event, err := engine.executeAction(context.Background(), &database.Action{
+		ID: "a2", ResourceID: resource.ID, ActionType: "terminate", RiskScore: 1,
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 80.0, *event.ActualSavings)
+	mockAdapter.AssertExpectations(t)
+}
+
+// MockMetadataAdapter is a MockCloudAdapter that can skip metrics lookups
+type MockMetadataAdapter struct {
+	MockCloudAdapter
+}
+
+func (m *MockMetadataAdapter) GetResourceMetadata(ctx context.Context, id string) (*cloud.ResourceV2, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*cloud.ResourceV2), args.Error(1)
+}
+
+func TestOODAEngine_ExecuteLooksUpMetadataOnly(t *testing.T) {
+	mockAdapter := new(MockMetadataAdapter)
+	mockRepo := new(MockRepository)
+	engine := NewOODAEngine(nil, mockAdapter, mockRepo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+
+	resource := &cloud.ResourceV2{ID: "web-01", Type: cloud.ResourceTypeEC2, State: "running", CostPerMonth: 100}
+	mockRepo.On("ClaimAction", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	mockRepo.On("UpdateActionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateSavingsEvent", mock.Anything, "", mock.Anything).Return(nil)
+	mockAdapter.On("GetResourceMetadata", mock.Anything, resource.ID).Return(resource, nil)
+	mockAdapter.On("ApplyOptimization", mock.Anything, resource, "optimize").Return(50.0, nil)
+
+	_, err := engine.executeAction(context.Background(), &database.Action{
+		ID: "a1", ResourceID: resource.ID, ActionType: "optimize", Payload: `{}`,
+	}, nil)
+	require.NoError(t, err)
+	mockAdapter.AssertNotCalled(t, "GetResource", mock.Anything, mock.Anything)
+	mockAdapter.AssertExpectations(t)
+}
+
+// batchingAdapter serves GetResources from resources and records each batch
+type batchingAdapter struct {
+	*MockCloudAdapter
+	resources map[string]*cloud.ResourceV2
+	batches   [][]string
+}
+
+func (a *batchingAdapter) GetResources(_ context.Context, ids []string) (map[string]*cloud.ResourceV2, error) {
+	a.batches = append(a.batches, ids)
+	found := make(map[string]*cloud.ResourceV2)
+	for _, id := range ids {
+		if resource, ok := a.resources[id]; ok {
+			found[id] = resource
+		}
+	}
+	return found, nil
+}
+
+func TestOODAEngine_ActPreloadsResources(t *testing.T) {
+	web1 := &cloud.ResourceV2{ID: "web-01", Type: cloud.ResourceTypeEC2, State: "running", CostPerMonth: 100}
+	web2 := &cloud.ResourceV2{ID: "web-02", Type: cloud.ResourceTypeEC2, State: "running", CostPerMonth: 50}
+	adapter := &batchingAdapter{
+		MockCloudAdapter: new(MockCloudAdapter),
+		resources:        map[string]*cloud.ResourceV2{web1.ID: web1, web2.ID: web2},
+	}
+	mockRepo := new(MockRepository)
+	engine := NewOODAEngine(nil, adapter, mockRepo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+
+	mockRepo.On("GetPendingActionsPage", mock.Anything, "", (*database.ActionCursor)(nil), mock.Anything).
+		Return([]*database.Action{
+			{ID: "a1", ResourceID: web1.ID, ActionType: "optimize", Payload: `{}`, EstimatedSavings: 50},
+			{ID: "a2", ResourceID: web2.ID, ActionType: "optimize", Payload: `{}`, EstimatedSavings: 25},
+			{ID: "a3", ResourceID: web1.ID, ActionType: "optimize", Payload: `{}`, EstimatedSavings: 10},
+		}, (*database.ActionCursor)(nil), nil)
+	mockRepo.On("ClaimAction", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	mockRepo.On("UpdateActionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateSavingsEvent", mock.Anything, "", mock.Anything).Return(nil)
+	adapter.On("GetResource", mock.Anything, web1.ID).Return(web1, nil)
+	adapter.On("ApplyOptimization", mock.Anything, mock.Anything, "optimize").Return(10.0, nil)
+
+	events, err := engine.act(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+	assert.Equal(t, [][]string{{web1.ID, web2.ID}}, adapter.batches)
+	// Only the second action on web-01 needs a fresh lookup
+	adapter.AssertNumberOfCalls(t, "GetResource", 1)
+}
+
+func TestLanePreloader_LooksUpBatchesAsLanesStart(t *testing.T) {
+	adapter := &batchingAdapter{MockCloudAdapter: new(MockCloudAdapter), resources: map[string]*cloud.ResourceV2{
+		"res-1": {ID: "res-1"}, "res-2": {ID: "res-2"}, "res-3": {ID: "res-3"},
+	}}
+	engine := NewOODAEngine(nil, adapter, new(MockRepository), nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+	lanes := [][]*database.Action{{{ResourceID: "res-1"}}, {{ResourceID: "res-2"}}, {{ResourceID: "res-3"}}}
+	preloader := &lanePreloader{engine: engine, lanes: lanes, batch: 2, maxAge: time.Minute}
+
+	// Lanes of a batch may start in either order
+	assert.Equal(t, "res-2", preloader.resource(context.Background(), 1).ID)
+	assert.Equal(t, "res-1", preloader.resource(context.Background(), 0).ID)
+	assert.Equal(t, [][]string{{"res-1", "res-2"}}, adapter.batches, "one lookup per worker pool's worth of lanes")
+
+	// A lane starting after the batch went stale sees a fresh lookup
+	preloader.loadedAt = time.Now().Add(-2 * time.Minute)
+	adapter.resources["res-2"] = &cloud.ResourceV2{ID: "res-2", Tags: map[string]string{"talos:protected": "true"}}
+	assert.Equal(t, "true", preloader.resource(context.Background(), 1).Tags["talos:protected"])
+	assert.Equal(t, "res-3", preloader.resource(context.Background(), 2).ID)
+	assert.Equal(t, [][]string{{"res-1", "res-2"}, {"res-1", "res-2"}, {"res-3"}}, adapter.batches)
+}
+
+func TestOODAEngine_DecideFollowsAutonomyFlags(t *testing.T) {
+	prod := &cloud.ResourceV2{ID: "web-prod", Type: "ec2", Tags: map[string]string{"environment": "production"}}
+	dev := &cloud.ResourceV2{ID: "web-dev", Type: "ec2", Tags: map[string]string{"env": "dev"}}
+	batch := &cloud.ResourceV2{ID: "batch-1", Type: "ec2", Tags: map[string]string{"team": "data"}}
+
+	mockRepo := new(MockRepository)
+	mockRepo.On("CreateAction", mock.Anything, "", mock.Anything).Return(nil)
+
+	config := DefaultEngineConfig()
+	config.RequireHumanApproval = true
+	config.Autonomy = features.AutonomyFlags{Rules: []features.AutonomyRule{
+		{ActionType: "optimize", Environment: "dev", Mode: features.AutonomyExecute},
+		{ActionType: "optimize", Environment: "production", Mode: features.AutonomySkip},
+	}}
+	engine := NewOODAEngine(nil, new(MockCloudAdapter), mockRepo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), config)
+
+	actions, err := engine.decide(context.Background(), []*OptimizationOpportunity{
+		{Resource: prod, EstimatedSavings: 40},
+		{Resource: dev, EstimatedSavings: 40},
+		{Resource: batch, EstimatedSavings: 40},
+	})
+
+	require.NoError(t, err)
+	require.Len(t, actions, 2)
+	assert.Equal(t, dev.ID, actions[0].ResourceID)
+	assert.Equal(t, database.ActionStatusPending, actions[0].Status)
+	// Without a matching flag the approval setting applies
+	assert.Equal(t, batch.ID, actions[1].ResourceID)
+	assert.Equal(t, database.ActionStatusAwaitingApproval, actions[1].Status)
+}
+
+func TestOODAEngine_ExecuteHoldsActionsTheFlagsNoLongerAllow(t *testing.T) {
+	mockAdapter := new(MockCloudAdapter)
+	mockRepo := new(MockRepository)
+	engine := NewOODAEngine(nil, mockAdapter, mockRepo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+	engine.SetAutonomy(features.NewAutonomyGate(features.AutonomyFlags{Default: features.AutonomyApprove}, nil))
+
+	resource := &cloud.ResourceV2{ID: "web-01", Type: cloud.ResourceTypeEC2, CostPerMonth: 100}
+	mockRepo.On("ClaimAction", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+	mockRepo.On("UpdateActionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockRepo.On("CreateSavingsEvent", mock.Anything, "", mock.Anything).Return(nil)
+	mockAdapter.On("GetResource", mock.Anything, resource.ID).Return(resource, nil)
+	mockAdapter.On("ApplyOptimization", mock.Anything, resource, "optimize").Return(50.0, nil)
+
+	// Queued without approval, so it now waits for one
+	_, err := engine.executeAction(context.Background(), &database.Action{ID: "queued", ResourceID: resource.ID, ActionType: "optimize", Payload: `{}`}, nil)
+	require.NoError(t, err)
+	mockRepo.AssertCalled(t, "UpdateActionStatus", mock.Anything, "queued", database.ActionStatusAwaitingApproval, mock.Anything, mock.Anything, mock.Anything)
+	mockAdapter.AssertNotCalled(t, "ApplyOptimization", mock.Anything, mock.Anything, mock.Anything)
+
+	// A human already approved this one
+	_, err = engine.executeAction(context.Background(), &database.Action{ID: "approved", ResourceID: resource.ID, ActionType: "optimize", Payload: `{"approved": true}`}, nil)
+	require.NoError(t, err)
+	mockAdapter.AssertNumberOfCalls(t, "ApplyOptimization", 1)
+
+	// Skipped actions stay queued, even approved ones
+	engine.SetAutonomy(features.NewAutonomyGate(features.AutonomyFlags{Default: features.AutonomySkip}, nil))
+	_, err = engine.executeAction(context.Background(), &database.Action{ID: "skipped", ResourceID: resource.ID, ActionType: "optimize", Payload: `{"approved": true}`}, nil)
+	require.NoError(t, err)
+	mockRepo.AssertCalled(t, "UpdateActionStatus", mock.Anything, "skipped", database.ActionStatusPending, mock.Anything, mock.Anything, mock.Anything)
+	mockAdapter.AssertNumberOfCalls(t, "ApplyOptimization", 1)
+}
+
+// eventRecordingRepository is a repository that keeps the event stream
+type eventRecordingRepository struct {
+	*MockRepository
+	events []string
+}
+
+func (r *eventRecordingRepository) AppendEvent(_ context.Context, eventType, subjectID string, _ map[string]interface{}) error {
+	r.events = append(r.events, eventType+":"+subjectID)
+	return nil
+}
+
+func TestOODAEngine_OrientRecordsOpportunityEvents(t *testing.T) {
+	idle := map[string]interface{}{cloud.IdleSinceKey: time.Now().Add(-30 * 24 * time.Hour)}
+	resources := []*cloud.ResourceV2{
+		{ID: "eipalloc-1", Type: cloud.ResourceTypeElasticIP, State: "unassociated", CostPerMonth: 3.65, Metadata: idle},
+		{ID: "arn:lb", Type: cloud.ResourceTypeLoadBalancer},
+	}
+	repo := &eventRecordingRepository{MockRepository: new(MockRepository)}
+	engine := NewOODAEngine(nil, new(MockCloudAdapter), repo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+
+	opportunities, err := engine.orient(context.Background(), resources, engine.VectorWeights())
+
+	require.NoError(t, err)
+	require.Len(t, opportunities, 1)
+	assert.Equal(t, []string{database.EventOpportunityFound + ":eipalloc-1"}, repo.events)
+}
+
+func TestOODAEngine_ParseRecommendations(t *testing.T) {
+	engine := NewOODAEngine(nil, nil, nil, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+
+	t.Run("json", func(t *testing.T) {
+		parsed := engine.parseRecommendations(`{"recommendation": "Downsize to t3.small", "risk_score": 30, "confidence": 0.85, "reasoning": ["CPU under 5%"]}`)
+		assert.Equal(t, []string{"Downsize to t3.small"}, parsed.Recommendations)
+		assert.Equal(t, 0.85, parsed.Confidence)
+		assert.Equal(t, 3.0, parsed.RiskScore)
+	})
+
+	t.Run("fenced json", func(t *testing.T) {
+		parsed := engine.parseRecommendations("Here is my analysis:\n```json\n{\"recommendation\": \"Release the idle IP\", \"risk_score\": 4, \"confidence\": 90}\n```")
+		assert.Equal(t, []string{"Release the idle IP"}, parsed.Recommendations)
+		assert.Equal(t, 0.9, parsed.Confidence)
+		assert.Equal(t, 4.0, parsed.RiskScore)
+	})
+
+	t.Run("bullet list", func(t *testing.T) {
+		parsed := engine.parseRecommendations("Here are my recommendations:\n- Stop the instance\n* Snapshot the volume\n12. Delete the snapshot {after 30 days}")
+		assert.Equal(t, []string{"Stop the instance", "Snapshot the volume", "Delete the snapshot {after 30 days}"}, parsed.Recommendations)
+		assert.Zero(t, parsed.Confidence)
+		assert.Zero(t, parsed.RiskScore)
+	})
+}
+
+// queueRepository keeps actions in memory and hands act() only the PENDING
+// ones of the organization asking, like the database queue
+type queueRepository struct {
+	mu      sync.Mutex
+	actions map[string]*database.Action
+}
+
+func (r *queueRepository) CreateAction(_ context.Context, orgID string, action *database.Action) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *action
+	if orgID != "" {
+		stored.OrgID = &orgID
+	}
+	r.actions[action.ID] = &stored
+	return nil
+}
+
+func (r *queueRepository) UpdateActionStatus(_ context.Context, id string, status string, _ *time.Time, _ *time.Time, _ *string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.actions[id].Status = status
+	return nil
+}
+
+func (r *queueRepository) ClaimAction(_ context.Context, id string, _ time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.actions[id].Status != database.ActionStatusPending {
+		return false, nil
+	}
+	r.actions[id].Status = "EXECUTING"
+	return true, nil
+}
+
+func (r *queueRepository) CreateSavingsEvent(context.Context, string, *database.SavingsEvent) error {
+	return nil
+}
+
+func (r *queueRepository) GetPendingActionsPage(_ context.Context, orgID string, _ *database.ActionCursor, _ int) ([]*database.Action, *database.ActionCursor, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var pending []*database.Action
+	for _, action := range r.actions {
+		if action.Status == database.ActionStatusPending && actionOrg(action) == orgID {
+			copied := *action
+			pending = append(pending, &copied)
+		}
+	}
+	return pending, nil, nil
+}
+
+// approvalQueue is a database.ApprovalStore over a queueRepository's actions
+type approvalQueue struct {
+	repo    *queueRepository
+	created int
+}
+
+var _ database.ApprovalStore = (*approvalQueue)(nil)
+
+func (q *approvalQueue) CreatePendingApproval(ctx context.Context, orgID string, action *database.Action) error {
+	q.created++
+	action.Status = database.ActionStatusAwaitingApproval
+	return q.repo.CreateAction(ctx, orgID, action)
+}
+
+func (q *approvalQueue) ListPending(_ context.Context, orgID string, _ database.ActionFilter) ([]*database.Action, error) {
+	q.repo.mu.Lock()
+	defer q.repo.mu.Unlock()
+	pending := []*database.Action{}
+	for _, action := range q.repo.actions {
+		if action.Status == database.ActionStatusAwaitingApproval && actionOrg(action) == orgID {
+			copied := *action
+			pending = append(pending, &copied)
+		}
+	}
+	return pending, nil
+}
+
+// Approve and Reject resolve an action the way Repository.ResolveAction does
+func (q *approvalQueue) Approve(_ context.Context, orgID, id string, _ *database.AuditLog) error {
+	return q.resolve(orgID, id, database.ActionStatusPending, `{"approved": true}`)
+}
+
+func (q *approvalQueue) Reject(_ context.Context, orgID, id string, _ *database.AuditLog) error {
+	return q.resolve(orgID, id, database.ActionStatusRejected, "")
+}
+
+func (q *approvalQueue) resolve(orgID, id, status, payload string) error {
+	q.repo.mu.Lock()
+	defer q.repo.mu.Unlock()
+	action, ok := q.repo.actions[id]
+	if !ok || action.Status != database.ActionStatusAwaitingApproval || actionOrg(action) != orgID {
+		return fmt.Errorf("action %s awaiting approval: %w", id, database.ErrNotFound)
+	}
+	action.Status = status
+	if payload != "" {
+		action.Payload = payload
+	}
+	return nil
+}
+
+// actionOrg is the organization an action was created for, "" for none
+func actionOrg(action *database.Action) string {
+	if action.OrgID == nil {
+		return ""
+	}
+	return *action.OrgID
+}
+
+func TestOODAEngine_ActWaitsForApproval(t *testing.T) {
+	ctx := context.Background()
+	mockAdapter := new(MockCloudAdapter)
+	repo := &queueRepository{actions: make(map[string]*database.Action)}
+	store := &approvalQueue{repo: repo}
+	config := DefaultEngineConfig()
+	config.RequireHumanApproval = true
+	engine := NewOODAEngine(nil, mockAdapter, repo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), config)
+	engine.SetApprovalStore(store)
+
+	approved := &cloud.ResourceV2{ID: "web-01", Type: cloud.ResourceTypeEC2, CostPerMonth: 100}
+	rejected := &cloud.ResourceV2{ID: "web-02", Type: cloud.ResourceTypeEC2, CostPerMonth: 100}
+	for _, resource := range []*cloud.ResourceV2{approved, rejected} {
+		mockAdapter.On("GetResource", mock.Anything, resource.ID).Return(resource, nil)
+		mockAdapter.On("ApplyOptimization", mock.Anything, resource, "optimize").Return(50.0, nil)
+	}
+
+	actions, err := engine.decide(ctx, []*OptimizationOpportunity{
+		{Resource: approved, EstimatedSavings: 40},
+		{Resource: rejected, EstimatedSavings: 40},
+	})
+	require.NoError(t, err)
+	require.Len(t, actions, 2)
+	assert.Equal(t, 2, store.created, "filed with the approval store")
+	pending, err := store.ListPending(ctx, "", database.ActionFilter{})
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+
+	// Queued for approval, so act() leaves them alone
+	events, err := engine.act(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	mockAdapter.AssertNotCalled(t, "ApplyOptimization", mock.Anything, mock.Anything, mock.Anything)
+
+	require.NoError(t, store.Approve(ctx, "", actions[0].ID, nil))
+	require.NoError(t, store.Reject(ctx, "", actions[1].ID, nil))
+	assert.ErrorIs(t, store.Approve(ctx, "", actions[1].ID, nil), database.ErrNotFound, "a rejected action can't be approved")
+
+	events, err = engine.act(ctx)
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+	mockAdapter.AssertNumberOfCalls(t, "ApplyOptimization", 1)
+	mockAdapter.AssertCalled(t, "ApplyOptimization", mock.Anything, approved, "optimize")
+	assert.Equal(t, database.ActionStatusRejected, repo.actions[actions[1].ID].Status)
+	pending, err = store.ListPending(ctx, "", database.ActionFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+// resizingAdapter simulates an instance that is stopped for a resize which
+// then fails
+type resizingAdapter struct {
+	*MockCloudAdapter
+	instance    *cloud.ResourceV2
+	rollbackErr error
+}
+
+func (a *resizingAdapter) GetResource(context.Context, string) (*cloud.ResourceV2, error) {
+	resource := *a.instance
+	return &resource, nil
+}
+
+func (a *resizingAdapter) ApplyOptimization(context.Context, *cloud.ResourceV2, string) (float64, error) {
+	a.instance.State = "stopped"
+	return 0, errors.New("InsufficientInstanceCapacity")
+}
+
+func (a *resizingAdapter) Rollback(_ context.Context, _ *cloud.ResourceV2, _ string, snapshot cloud.ResourceSnapshot) error {
+	if a.rollbackErr != nil {
+		return a.rollbackErr
+	}
+	a.instance.State = snapshot.State
+	a.instance.Metadata = map[string]interface{}{"instance_type": snapshot.InstanceType}
+	return nil
+}
+
+// payloadRecordingRepository is a repository that keeps payload additions
+type payloadRecordingRepository struct {
+	*MockRepository
+	payload map[string]interface{}
+}
+
+func (r *payloadRecordingRepository) MergeActionPayload(_ context.Context, _ string, fields map[string]interface{}) error {
+	for key, value := range fields {
+		r.payload[key] = value
+	}
+	return nil
+}
+
+func TestOODAEngine_ExecuteRollsBackFailedResize(t *testing.T) {
+	for _, rollbackErr := range []error{nil, errors.New("instance is terminated")} {
+		adapter := &resizingAdapter{
+			MockCloudAdapter: new(MockCloudAdapter),
+			instance: &cloud.ResourceV2{
+				ID: "web-01", Type: cloud.ResourceTypeEC2, State: "running", CostPerMonth: 120,
+				Metadata: map[string]interface{}{"instance_type": "m5.xlarge"},
+			},
+			rollbackErr: rollbackErr,
+		}
+		repo := &payloadRecordingRepository{MockRepository: new(MockRepository), payload: make(map[string]interface{})}
+		repo.On("ClaimAction", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+		repo.On("UpdateActionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		engine := NewOODAEngine(nil, adapter, repo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+
+		_, err := engine.executeAction(context.Background(), &database.Action{
+			ID: "a1", ResourceID: "web-01", ActionType: "resize",
+			Payload: `{"plan": {"current_type": "m5.xlarge", "proposed_type": "m5.large"}}`,
+		}, nil)
+		require.Error(t, err)
+		assert.Equal(t, cloud.ResourceSnapshot{State: "running", InstanceType: "m5.xlarge"}, repo.payload["snapshot"])
+
+		if rollbackErr == nil {
+			// The stop is reversed
+			assert.Equal(t, "running", adapter.instance.State)
+			assert.Equal(t, map[string]interface{}{"succeeded": true}, repo.payload["rollback"])
+			repo.AssertCalled(t, "UpdateActionStatus", mock.Anything, "a1", "ROLLED_BACK", mock.Anything, mock.Anything, mock.Anything)
+			continue
+		}
+		assert.Equal(t, "stopped", adapter.instance.State)
+		assert.Equal(t, map[string]interface{}{"succeeded": false, "error": "instance is terminated"}, repo.payload["rollback"])
+		repo.AssertCalled(t, "UpdateActionStatus", mock.Anything, "a1", "FAILED", mock.Anything, mock.Anything,
+			mock.MatchedBy(func(msg *string) bool {
+				return *msg == "cloud resize failed: InsufficientInstanceCapacity; rollback failed: instance is terminated"
+			}))
+	}
+}
+
+func TestOODAEngine_ActsOnlyOnItsOrganizationsActions(t *testing.T) {
+	repo := &queueRepository{actions: make(map[string]*database.Action)}
+	resource := &cloud.ResourceV2{ID: "web-01", Type: cloud.ResourceTypeEC2, CostPerMonth: 100}
+	newEngine := func(orgID string) (*OODAEngine, *MockCloudAdapter) {
+		adapter := new(MockCloudAdapter)
+		adapter.On("GetResource", mock.Anything, resource.ID).Return(resource, nil)
+		adapter.On("ApplyOptimization", mock.Anything, resource, "optimize").Return(50.0, nil)
+		config := DefaultEngineConfig()
+		config.RequireHumanApproval = false
+		config.OrganizationID = orgID
+		return NewOODAEngine(nil, adapter, repo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), config), adapter
+	}
+	acme, acmeAdapter := newEngine("org-acme")
+	globex, globexAdapter := newEngine("org-globex")
+
+	actions, err := acme.decide(context.Background(), []*OptimizationOpportunity{{Resource: resource, EstimatedSavings: 40}})
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	require.NotNil(t, repo.actions[actions[0].ID].OrgID)
+	assert.Equal(t, "org-acme", *repo.actions[actions[0].ID].OrgID)
+
+	// Another organization's engine doesn't see the action
+	events, err := globex.act(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	globexAdapter.AssertNotCalled(t, "ApplyOptimization", mock.Anything, mock.Anything, mock.Anything)
+	assert.Equal(t, database.ActionStatusPending, repo.actions[actions[0].ID].Status)
+
+	events, err = acme.act(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, events, 1)
+	acmeAdapter.AssertNumberOfCalls(t, "ApplyOptimization", 1)
+}
+
+func TestRecommendedAction(t *testing.T) {
+	instance := func(state, target string) *cloud.ResourceV2 {
+		return &cloud.ResourceV2{ID: "web-01", Type: cloud.ResourceTypeEC2, State: state, RightSizeRecommendation: target}
+	}
+	tests := []struct {
+		name            string
+		resource        *cloud.ResourceV2
+		recommendations []string
+		want            cloud.ActionType
+	}{
+		{"resize to the known target", instance("running", "m5.large"), []string{"Downsize to m5.large"}, cloud.ActionResize},
+		{"known target without a verb", instance("running", "m5.large"), []string{"CPU is mostly idle"}, cloud.ActionResize},
+		{"first verb wins", instance("running", "m5.large"), []string{"Stop outside business hours", "Resize to m5.large"}, cloud.ActionStop},
+		{"resize needs a target", instance("running", ""), []string{"Resize to a smaller type", "Terminate if unused"}, cloud.ActionTerminate},
+		{"shut down", instance("running", ""), []string{"  Shut down overnight"}, cloud.ActionStop},
+		{"unsupported for the type", &cloud.ResourceV2{ID: "db-1", Type: cloud.ResourceTypeRDS}, []string{"Terminate the replica"}, cloud.ActionOptimize},
+		{"nothing concrete", instance("running", ""), []string{"Review the reservation coverage"}, cloud.ActionOptimize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, recommendedAction(tt.resource, tt.recommendations))
+		})
+	}
+}
+
+// recordingAdapter serves one resource and records each action applied to it
+// with the instance type it was asked to resize to
+type recordingAdapter struct {
+	*MockCloudAdapter
+	resource *cloud.ResourceV2
+	applied  []string
+}
+
+func (a *recordingAdapter) GetResource(context.Context, string) (*cloud.ResourceV2, error) {
+	resource := *a.resource
+	return &resource, nil
+}
+
+func (a *recordingAdapter) ApplyOptimization(_ context.Context, resource *cloud.ResourceV2, action string) (float64, error) {
+	a.applied = append(a.applied, action+":"+resource.RightSizeRecommendation)
+	return 60, nil
+}
+
+func TestOODAEngine_ResizesEndToEnd(t *testing.T) {
+	resource := &cloud.ResourceV2{
+		ID: "web-01", Type: cloud.ResourceTypeEC2, State: "running", CPUUsage: 0.04, MemoryUsage: 0.1, CostPerMonth: 140,
+		Metadata:                map[string]interface{}{"instance_type": "m5.xlarge"},
+		RightSizeRecommendation: "m5.large",
+	}
+	adapter := &recordingAdapter{MockCloudAdapter: new(MockCloudAdapter), resource: resource}
+	repo := &queueRepository{actions: make(map[string]*database.Action)}
+
+	aiClient := new(MockAIClient)
+	aiClient.On("Analyze", mock.Anything, mock.Anything).Return(&ai.AIResponse{
+		Content: `{"risk_score": 2, "confidence": 0.9, "recommendations": ["Downsize to m5.large"], "reasoning": ["CPU below 5%"]}`,
+	}, nil)
+	orchestrator, err := ai.NewUnifiedOrchestrator(&ai.Config{}, nil, zap.NewNop())
+	require.NoError(t, err)
+	orchestrator.GetFactory().SetClient("sentinel", aiClient)
+	orchestrator.GetFactory().SetClient("strategist", aiClient)
+
+	config := DefaultEngineConfig()
+	config.RequireHumanApproval = false
+	engine := NewOODAEngine(orchestrator, adapter, repo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), config)
+
+	opportunities, err := engine.orient(context.Background(), []*cloud.ResourceV2{resource}, engine.VectorWeights())
+	require.NoError(t, err)
+	require.Len(t, opportunities, 1)
+	assert.Equal(t, cloud.ActionResize, opportunities[0].Action)
+
+	actions, err := engine.decide(context.Background(), opportunities)
+	require.NoError(t, err)
+	require.Len(t, actions, 1)
+	assert.Equal(t, string(cloud.ActionResize), actions[0].ActionType)
+	assert.Contains(t, actions[0].Payload, `"proposed_type":"m5.large"`)
+
+	events, err := engine.act(context.Background())
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, 60.0, *events[0].ActualSavings)
+	assert.Equal(t, []string{"resize:m5.large"}, adapter.applied)
+	assert.Equal(t, "COMPLETED", repo.actions[actions[0].ID].Status)
+}
+
+func TestOODAEngine_ExecuteOptimizationResolvesConcreteAction(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    []string
+	}{
+		{"planned resize", `{"plan": {"proposed_type": "m5.large"}, "recommendations": ["Downsize to m5.large"]}`, []string{"resize:m5.large"}},
+		{"stop", `{"recommendations": ["Stop outside business hours"]}`, []string{"stop:"}},
+		{"nothing concrete", `{"recommendations": ["Review the reservation coverage"]}`, []string{"optimize:"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &recordingAdapter{
+				MockCloudAdapter: new(MockCloudAdapter),
+				resource:         &cloud.ResourceV2{ID: "web-01", Type: cloud.ResourceTypeEC2, State: "running", CostPerMonth: 140},
+			}
+			repo := new(MockRepository)
+			repo.On("ClaimAction", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+			repo.On("UpdateActionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			repo.On("CreateSavingsEvent", mock.Anything, "", mock.Anything).Return(nil)
+			config := DefaultEngineConfig()
+			config.RequireHumanApproval = false
+			engine := NewOODAEngine(nil, adapter, repo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), config)
+
+			_, err := engine.executeAction(context.Background(), &database.Action{
+				ID: "a1", ResourceID: "web-01", ActionType: "optimize", Payload: tt.payload,
+			}, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, adapter.applied)
+		})
+	}
+}
+
+func TestOODAEngine_PromotedOptimizeHonorsApproval(t *testing.T) {
+	const terminate = `{"recommendations": ["Terminate the idle instance"]}`
+	approveTerminate := features.AutonomyFlags{Rules: []features.AutonomyRule{{ActionType: "terminate", Mode: features.AutonomyApprove}}}
+	skipTerminate := features.AutonomyFlags{Rules: []features.AutonomyRule{{ActionType: "terminate", Mode: features.AutonomySkip}}}
+
+	tests := []struct {
+		name            string
+		requireApproval bool
+		flags           features.AutonomyFlags
+		payload         string
+		wantStatus      string
+		want            []string
+	}{
+		{"terminate flagged for approval", false, approveTerminate, terminate, database.ActionStatusAwaitingApproval, nil},
+		{"approval required by config", true, features.AutonomyFlags{}, terminate, database.ActionStatusAwaitingApproval, nil},
+		{"terminate skipped by flags", false, skipTerminate, terminate, database.ActionStatusPending, nil},
+		{"approved by a human", false, approveTerminate, `{"approved": true, "recommendations": ["Terminate the idle instance"]}`, "COMPLETED", []string{"terminate:"}},
+		{"no approval needed", false, features.AutonomyFlags{}, terminate, "COMPLETED", []string{"terminate:"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &recordingAdapter{
+				MockCloudAdapter: new(MockCloudAdapter),
+				resource:         &cloud.ResourceV2{ID: "web-01", Type: cloud.ResourceTypeEC2, State: "running", CostPerMonth: 140},
+			}
+			repo := new(MockRepository)
+			repo.On("ClaimAction", mock.Anything, mock.Anything, mock.Anything).Return(true, nil)
+			repo.On("UpdateActionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			repo.On("CreateSavingsEvent", mock.Anything, "", mock.Anything).Return(nil)
+			config := DefaultEngineConfig()
+			config.RequireHumanApproval = tt.requireApproval
+			config.Autonomy = tt.flags
+			engine := NewOODAEngine(nil, adapter, repo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), config)
+
+			_, err := engine.executeAction(context.Background(), &database.Action{
+				ID: "a1", ResourceID: "web-01", ActionType: "optimize", Payload: tt.payload,
+			}, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, adapter.applied)
+			repo.AssertCalled(t, "UpdateActionStatus", mock.Anything, "a1", tt.wantStatus, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// terminationAdapter applies a cloud.TerminationPolicy to terminate actions
+// the way the provider adapters do, and records the resources it terminated
+type terminationAdapter struct {
+	*MockCloudAdapter
+	policy     cloud.TerminationPolicy
+	resources  map[string]*cloud.ResourceV2
+	terminated []string
+}
+
+func (a *terminationAdapter) GetResource(_ context.Context, id string) (*cloud.ResourceV2, error) {
+	resource := *a.resources[id]
+	return &resource, nil
+}
+
+func (a *terminationAdapter) ApplyOptimization(_ context.Context, resource *cloud.ResourceV2, action string) (float64, error) {
+	if action != string(cloud.ActionTerminate) {
+		return 0, fmt.Errorf("unexpected action %s", action)
+	}
+	if err := a.policy.CheckProtected(resource); err != nil {
+		return 0, err
+	}
+	if err := a.policy.CheckBackup(resource, time.Now()); err != nil {
+		return 0, err
+	}
+	a.terminated = append(a.terminated, resource.ID)
+	return resource.CostPerMonth, nil
+}
+
+func TestOODAEngine_ActTerminatesThroughTerminationPolicy(t *testing.T) {
+	backedUp := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	instance := func(id string, tags map[string]string) *cloud.ResourceV2 {
+		return &cloud.ResourceV2{ID: id, Type: cloud.ResourceTypeEC2, State: "stopped", CostPerMonth: 80, Tags: tags}
+	}
+	adapter := &terminationAdapter{
+		MockCloudAdapter: new(MockCloudAdapter),
+		policy:           cloud.TerminationPolicy{MaxBackupAge: 24 * time.Hour, ProtectedResources: []string{"i-keep-*"}},
+		resources: map[string]*cloud.ResourceV2{
+			"i-dev":       instance("i-dev", map[string]string{"environment": "dev", cloud.DefaultBackupTagKey: backedUp}),
+			"i-prod":      instance("i-prod", map[string]string{"environment": "production", cloud.DefaultBackupTagKey: backedUp}),
+			"i-keep-db":   instance("i-keep-db", map[string]string{cloud.DefaultBackupTagKey: backedUp}),
+			"i-no-backup": instance("i-no-backup", nil),
+		},
+	}
+	repo := &queueRepository{actions: make(map[string]*database.Action)}
+	config := DefaultEngineConfig()
+	config.RequireHumanApproval = false
+	engine := NewOODAEngine(nil, adapter, repo, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), config)
+
+	var opportunities []*OptimizationOpportunity
+	for _, id := range []string{"i-dev", "i-prod", "i-keep-db", "i-no-backup"} {
+		resource := adapter.resources[id]
+		recommendations := []string{"Terminate the idle instance"}
+		opportunities = append(opportunities, &OptimizationOpportunity{
+			Resource: resource, RiskScore: 1, Recommendations: recommendations, EstimatedSavings: 80,
+			Action: recommendedAction(resource, recommendations),
+		})
+	}
+	actions, err := engine.decide(context.Background(), opportunities)
+	require.NoError(t, err)
+	require.Len(t, actions, 4)
+
+	_, err = engine.act(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"i-dev"}, adapter.terminated)
+
+	statuses := make(map[string]string)
+	for _, action := range repo.actions {
+		assert.Equal(t, string(cloud.ActionTerminate), action.ActionType)
+		statuses[action.ResourceID] = action.Status
+	}
+	assert.Equal(t, map[string]string{
+		"i-dev": "COMPLETED", "i-prod": "FAILED", "i-keep-db": "FAILED", "i-no-backup": "FAILED",
+	}, statuses)
+}
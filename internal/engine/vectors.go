@@ -0,0 +1,92 @@
+package engine
+
+import (
+	"fmt"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+)
+
+// VectorIdle names the vector scoring how long a volume, address or load
+// balancer has gone unused
+const VectorIdle = "idle"
+
+// idleVectorWeight is the idle vector's weight. VectorWeights don't cover it
+// since no resource type gets it alongside the spot or scheduling vectors.
+const idleVectorWeight = 0.6
+
+// AnalysisVectorFunc scores one aspect of a resource; the engine sets the
+// returned vector's weight
+type AnalysisVectorFunc func(resource *cloud.ResourceV2) AnalysisVector
+
+// defaultVectorRegistry lists the analysis vectors for each resource type.
+// Spot capacity only exists for instances, and storage and addresses only
+// cost money while idle.
+func (e *OODAEngine) defaultVectorRegistry() map[string][]AnalysisVectorFunc {
+	compute := []AnalysisVectorFunc{e.analyzeRightsizing, e.analyzeSpotArbitrage, e.analyzeScheduling, e.analyzeCostPatterns}
+	database := []AnalysisVectorFunc{e.analyzeRightsizing, e.analyzeScheduling, e.analyzeCostPatterns}
+	unused := []AnalysisVectorFunc{e.analyzeIdle, e.analyzeCostPatterns}
+	return map[string][]AnalysisVectorFunc{
+		cloud.ResourceTypeEC2:          compute,
+		cloud.ResourceTypeVM:           compute,
+		cloud.ResourceTypeRDS:          database,
+		cloud.ResourceTypeCloudSQL:     database,
+		cloud.ResourceTypeAzureSQL:     database,
+		cloud.ResourceTypeEBS:          unused,
+		cloud.ResourceTypeStorage:      unused,
+		cloud.ResourceTypeElasticIP:    unused,
+		cloud.ResourceTypeLoadBalancer: unused,
+	}
+}
+
+// defaultVectors are run for resource types without vectors of their own
+func (e *OODAEngine) defaultVectors() []AnalysisVectorFunc {
+	return []AnalysisVectorFunc{e.analyzeRightsizing, e.analyzeSpotArbitrage, e.analyzeScheduling, e.analyzeCostPatterns}
+}
+
+// SetAnalysisVectors replaces the vectors run for a resource type; no
+// vectors restores the built-in set for it. Call it before the engine runs.
+func (e *OODAEngine) SetAnalysisVectors(resourceType string, vectors ...AnalysisVectorFunc) {
+	if e.vectors == nil {
+		e.vectors = e.defaultVectorRegistry()
+	}
+	if len(vectors) == 0 {
+		vectors = e.defaultVectorRegistry()[resourceType]
+	}
+	if len(vectors) == 0 {
+		delete(e.vectors, resourceType)
+		return
+	}
+	e.vectors[resourceType] = vectors
+}
+
+// vectorsFor returns the vectors run for a resource type. Engines built
+// for a backtest have no registry and use the built-in one.
+func (e *OODAEngine) vectorsFor(resourceType string) []AnalysisVectorFunc {
+	registry := e.vectors
+	if registry == nil {
+		registry = e.defaultVectorRegistry()
+	}
+	if vectors, ok := registry[resourceType]; ok {
+		return vectors
+	}
+	return e.defaultVectors()
+}
+
+// analyzeIdle scores an unattached volume or address, or a load balancer
+// without traffic. Adapters only mark a resource idle once it has been for
+// the idle policy's minimum.
+func (e *OODAEngine) analyzeIdle(resource *cloud.ResourceV2) AnalysisVector {
+	vector := AnalysisVector{Name: VectorIdle}
+
+	if since, ok := cloud.IdleSince(resource); ok {
+		vector.Score = 0.9
+		vector.Findings = append(vector.Findings, fmt.Sprintf("Idle since %s", since.Format("2006-01-02")))
+		vector.Confidence = 0.9
+	} else {
+		vector.Score = 0.1
+		vector.Findings = append(vector.Findings, "In use or only recently idle")
+		vector.Confidence = 0.7
+	}
+
+	return vector
+}
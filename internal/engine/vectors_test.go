@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func vectorNames(vectors []AnalysisVector) []string {
+	names := make([]string, 0, len(vectors))
+	for _, v := range vectors {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func TestOODAEngine_AnalysisVectorsByResourceType(t *testing.T) {
+	engine := NewOODAEngine(nil, nil, nil, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+	weights := engine.VectorWeights()
+
+	ec2 := vectorNames(engine.analysisVectors(&cloud.ResourceV2{ID: "i-1", Type: cloud.ResourceTypeEC2}, weights))
+	assert.Equal(t, []string{VectorRightsizing, VectorSpotArbitrage, VectorScheduling, VectorCostPatterns}, ec2)
+
+	rds := vectorNames(engine.analysisVectors(&cloud.ResourceV2{ID: "db-1", Type: cloud.ResourceTypeRDS}, weights))
+	assert.NotContains(t, rds, VectorSpotArbitrage)
+	assert.Contains(t, rds, VectorRightsizing)
+
+	for _, resourceType := range []string{cloud.ResourceTypeEBS, cloud.ResourceTypeElasticIP} {
+		names := vectorNames(engine.analysisVectors(&cloud.ResourceV2{ID: "r-1", Type: resourceType}, weights))
+		assert.Equal(t, []string{VectorIdle, VectorCostPatterns}, names, resourceType)
+	}
+
+	unknown := vectorNames(engine.analysisVectors(&cloud.ResourceV2{ID: "q-1", Type: "queue"}, weights))
+	assert.Equal(t, ec2, unknown, "unknown types get the default set")
+}
+
+func TestOODAEngine_IdleVector(t *testing.T) {
+	engine := NewOODAEngine(nil, nil, nil, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+	since := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+
+	idle := engine.analysisVectors(&cloud.ResourceV2{ID: "vol-1", Type: cloud.ResourceTypeEBS, Metadata: map[string]interface{}{cloud.IdleSinceKey: since}}, engine.VectorWeights())
+	assert.Equal(t, VectorIdle, idle[0].Name)
+	assert.Equal(t, 0.9, idle[0].Score)
+	assert.Equal(t, []string{"Idle since 2026-09-01"}, idle[0].Findings)
+	assert.Equal(t, idleVectorWeight, idle[0].Weight)
+
+	attached := engine.analyzeIdle(&cloud.ResourceV2{ID: "vol-2", Type: cloud.ResourceTypeEBS})
+	assert.Less(t, attached.Score, idle[0].Score)
+}
+
+func TestOODAEngine_SetAnalysisVectors(t *testing.T) {
+	engine := NewOODAEngine(nil, nil, nil, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+	queueDepth := func(resource *cloud.ResourceV2) AnalysisVector {
+		return AnalysisVector{Name: "queue_depth", Score: 0.4}
+	}
+
+	engine.SetAnalysisVectors(cloud.ResourceTypeRDS, queueDepth)
+	assert.Equal(t, []string{"queue_depth"}, vectorNames(engine.analysisVectors(&cloud.ResourceV2{Type: cloud.ResourceTypeRDS}, engine.VectorWeights())))
+
+	engine.SetAnalysisVectors(cloud.ResourceTypeRDS)
+	assert.Equal(t, []string{VectorRightsizing, VectorScheduling, VectorCostPatterns},
+		vectorNames(engine.analysisVectors(&cloud.ResourceV2{Type: cloud.ResourceTypeRDS}, engine.VectorWeights())), "restored")
+}
+
+// spotAdapter serves one spot market for every lookup
+type spotAdapter struct {
+	MockCloudAdapter
+	market *cloud.SpotMarket
+}
+
+func (s *spotAdapter) SpotMarket(ctx context.Context, zone, instanceType string) (*cloud.SpotMarket, error) {
+	if s.market == nil {
+		return nil, errors.New("no spot prices")
+	}
+	return s.market, nil
+}
+
+func TestOODAEngine_SpotVectorUsesInterruptionRisk(t *testing.T) {
+	adapter := &spotAdapter{}
+	engine := NewOODAEngine(nil, adapter, nil, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+	instance := &cloud.ResourceV2{ID: "i-1", Type: cloud.ResourceTypeEC2, CPUUsage: 0.3, Metadata: map[string]interface{}{
+		"instance_type":           "c5.xlarge",
+		cloud.AvailabilityZoneKey: "us-east-1a",
+	}}
+
+	unknown := engine.analyzeSpotArbitrage(instance)
+	assert.Equal(t, 0.7, unknown.Score, "no market data keeps the flat score")
+
+	adapter.market = &cloud.SpotMarket{PlacementScore: 9, InterruptionRisk: 0.05}
+	safe := engine.analyzeSpotArbitrage(instance)
+	adapter.market = &cloud.SpotMarket{PlacementScore: 2, PriceVolatility: 0.3, InterruptionRisk: 0.9}
+	risky := engine.analyzeSpotArbitrage(instance)
+
+	assert.Less(t, risky.Score, safe.Score)
+	assert.InDelta(t, 0.25, risky.Score, 1e-9)
+	assert.Contains(t, risky.Findings, "Spot interruption risk 90% (placement score 2/10, price volatility 30%)")
+
+	rds := engine.analyzeSpotArbitrage(&cloud.ResourceV2{ID: "db-1", Type: cloud.ResourceTypeRDS})
+	assert.Equal(t, 0.2, rds.Score)
+}
@@ -0,0 +1,167 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/Xover-Official/Xover/internal/config"
+	"go.uber.org/zap"
+)
+
+// Analysis vector names, as reported in AnalysisVector.Name
+const (
+	VectorRightsizing   = "rightsizing"
+	VectorSpotArbitrage = "spot_arbitrage"
+	VectorScheduling    = "scheduling"
+	VectorCostPatterns  = "cost_patterns"
+)
+
+// vectorWeightTolerance is how far the weights may sum from 1 before they
+// are rejected; anything within it is normalized away
+const vectorWeightTolerance = 0.05
+
+// VectorWeights weight the rule-based analysis vectors in the risk score.
+// The zero value means DefaultVectorWeights.
+type VectorWeights struct {
+	Rightsizing   float64 `yaml:"rightsizing"`
+	SpotArbitrage float64 `yaml:"spot_arbitrage"`
+	Scheduling    float64 `yaml:"scheduling"`
+	CostPatterns  float64 `yaml:"cost_patterns"`
+}
+
+// DefaultVectorWeights returns the built-in weights
+func DefaultVectorWeights() VectorWeights {
+	return VectorWeights{
+		Rightsizing:   0.3,
+		SpotArbitrage: 0.25,
+		Scheduling:    0.2,
+		CostPatterns:  0.25,
+	}
+}
+
+// VectorWeightsFromConfig converts the configured weights
+func VectorWeightsFromConfig(c config.VectorWeightsConfig) VectorWeights {
+	return VectorWeights{
+		Rightsizing:   c.Rightsizing,
+		SpotArbitrage: c.SpotArbitrage,
+		Scheduling:    c.Scheduling,
+		CostPatterns:  c.CostPatterns,
+	}
+}
+
+// IsZero reports whether no weight is set
+func (w VectorWeights) IsZero() bool {
+	return w == VectorWeights{}
+}
+
+// Sum returns the total of the weights
+func (w VectorWeights) Sum() float64 {
+	return w.Rightsizing + w.SpotArbitrage + w.Scheduling + w.CostPatterns
+}
+
+// Validate checks the weights are non-negative and sum to about 1
+func (w VectorWeights) Validate() error {
+	for name, weight := range map[string]float64{
+		VectorRightsizing:   w.Rightsizing,
+		VectorSpotArbitrage: w.SpotArbitrage,
+		VectorScheduling:    w.Scheduling,
+		VectorCostPatterns:  w.CostPatterns,
+	} {
+		if weight < 0 || math.IsNaN(weight) {
+			return fmt.Errorf("vector weight %s must not be negative", name)
+		}
+	}
+	if sum := w.Sum(); math.Abs(sum-1) > vectorWeightTolerance {
+		return fmt.Errorf("vector weights must sum to 1, got %.3f", sum)
+	}
+	return nil
+}
+
+// resolved returns the defaults for zero weights and otherwise the weights
+// scaled to sum to exactly 1
+func (w VectorWeights) resolved() VectorWeights {
+	if w.IsZero() {
+		return DefaultVectorWeights()
+	}
+	sum := w.Sum()
+	return VectorWeights{
+		Rightsizing:   w.Rightsizing / sum,
+		SpotArbitrage: w.SpotArbitrage / sum,
+		Scheduling:    w.Scheduling / sum,
+		CostPatterns:  w.CostPatterns / sum,
+	}
+}
+
+// weight returns the weight of the named vector
+func (w VectorWeights) weight(name string) float64 {
+	switch name {
+	case VectorRightsizing:
+		return w.Rightsizing
+	case VectorSpotArbitrage:
+		return w.SpotArbitrage
+	case VectorScheduling:
+		return w.Scheduling
+	case VectorCostPatterns:
+		return w.CostPatterns
+	case VectorIdle:
+		return idleVectorWeight
+	}
+	return 0
+}
+
+func (w VectorWeights) logFields() []zap.Field {
+	return []zap.Field{
+		zap.Float64("rightsizing", w.Rightsizing),
+		zap.Float64("spot_arbitrage", w.SpotArbitrage),
+		zap.Float64("scheduling", w.Scheduling),
+		zap.Float64("cost_patterns", w.CostPatterns),
+	}
+}
+
+// VectorWeights returns the weights the next cycle will use
+func (e *OODAEngine) VectorWeights() VectorWeights {
+	if w := e.weights.Load(); w != nil {
+		return *w
+	}
+	return e.config.VectorWeights.resolved()
+}
+
+// SetVectorWeights validates and normalizes weights and makes them current;
+// cycles already running keep the weights they started with. Zero weights
+// restore the defaults.
+func (e *OODAEngine) SetVectorWeights(weights VectorWeights) error {
+	if !weights.IsZero() {
+		if err := weights.Validate(); err != nil {
+			return err
+		}
+	}
+	resolved := weights.resolved()
+	e.weights.Store(&resolved)
+	return nil
+}
+
+// WatchConfig applies the analysis vector weights of every config received
+// on updates, such as those from config.UnifiedConfigService.Watch, until ctx
+// is done or updates is closed. Invalid weights are logged and ignored.
+func (e *OODAEngine) WatchConfig(ctx context.Context, updates <-chan *config.Config) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case cfg, ok := <-updates:
+			if !ok {
+				return
+			}
+			if cfg == nil {
+				continue
+			}
+			weights := VectorWeightsFromConfig(cfg.Analysis.VectorWeights)
+			if err := e.SetVectorWeights(weights); err != nil {
+				e.logger.Warn("Ignoring invalid analysis vector weights", zap.Error(err))
+				continue
+			}
+			e.logger.Info("Analysis vector weights reloaded", e.VectorWeights().logFields()...)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/cloud"
+	"github.com/Xover-Official/Xover/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func TestVectorWeights_Validate(t *testing.T) {
+	assert.NoError(t, DefaultVectorWeights().Validate())
+	assert.NoError(t, VectorWeights{Rightsizing: 0.5, Scheduling: 0.52}.Validate(), "within tolerance")
+	assert.Error(t, VectorWeights{Rightsizing: 0.5, Scheduling: 0.8}.Validate())
+	assert.Error(t, VectorWeights{Rightsizing: 1.2, Scheduling: -0.2}.Validate())
+}
+
+func TestOODAEngine_SetVectorWeights(t *testing.T) {
+	engine := NewOODAEngine(nil, nil, nil, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+	assert.Equal(t, DefaultVectorWeights(), engine.VectorWeights())
+
+	require.NoError(t, engine.SetVectorWeights(VectorWeights{Rightsizing: 0.51, Scheduling: 0.51}))
+	weights := engine.VectorWeights()
+	assert.InDelta(t, 1, weights.Sum(), 1e-9, "weights are normalized")
+	assert.InDelta(t, 0.5, weights.Scheduling, 1e-9)
+
+	assert.Error(t, engine.SetVectorWeights(VectorWeights{Scheduling: 2}))
+	assert.InDelta(t, 0.5, engine.VectorWeights().Scheduling, 1e-9, "invalid weights are not applied")
+
+	vectors := engine.analysisVectors(&cloud.ResourceV2{ID: "i-1", Type: "ec2"}, engine.VectorWeights())
+	for _, v := range vectors {
+		assert.Equal(t, engine.VectorWeights().weight(v.Name), v.Weight, v.Name)
+	}
+}
+
+func TestOODAEngine_WatchConfigReloadsWeights(t *testing.T) {
+	engine := NewOODAEngine(nil, nil, nil, nil, zap.NewNop(), trace.NewNoopTracerProvider().Tracer(""), DefaultEngineConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	updates := make(chan *config.Config, 1)
+	go engine.WatchConfig(ctx, updates)
+
+	cfg := &config.Config{}
+	cfg.Analysis.VectorWeights = config.VectorWeightsConfig{Rightsizing: 0.1, SpotArbitrage: 0.1, Scheduling: 0.6, CostPatterns: 0.2}
+	updates <- cfg
+
+	assert.Eventually(t, func() bool {
+		return engine.VectorWeights().Scheduling > 0.59
+	}, time.Second, 10*time.Millisecond)
+}
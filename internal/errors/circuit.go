@@ -0,0 +1,156 @@
+package errors
+
+import (
+	stderrors "errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned instead of calling a dependency whose circuit
+// breaker is open
+var ErrCircuitOpen = stderrors.New("circuit breaker is open")
+
+// CircuitState represents circuit breaker state
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// CircuitBreaker stops calling a failing dependency. It opens after
+// threshold consecutive failures and rejects calls for timeout. It then
+// half-opens and lets a single probe through: a successful probe closes it,
+// a failed one opens it again.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	timeout   time.Duration
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+	probing   bool // a half-open probe is in flight
+	now       func() time.Time
+}
+
+// NewCircuitBreaker creates a closed circuit breaker
+func NewCircuitBreaker(threshold int, timeout time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CircuitBreaker{threshold: threshold, timeout: timeout, now: time.Now}
+}
+
+// Call runs fn unless the circuit is open, in which case it returns
+// ErrCircuitOpen without calling it
+func (cb *CircuitBreaker) Call(fn func() error) error {
+	if err := cb.allow(); err != nil {
+		return err
+	}
+	err := fn()
+	cb.record(err)
+	return err
+}
+
+// State returns the current state, half-opening the circuit if its timeout
+// has passed
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.halfOpenIfDue()
+	return cb.state
+}
+
+func (cb *CircuitBreaker) allow() error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.halfOpenIfDue()
+	switch cb.state {
+	case CircuitOpen:
+		return ErrCircuitOpen
+	case CircuitHalfOpen:
+		if cb.probing {
+			return ErrCircuitOpen
+		}
+		cb.probing = true
+	}
+	return nil
+}
+
+func (cb *CircuitBreaker) record(err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.state == CircuitHalfOpen {
+		cb.probing = false
+		if err != nil {
+			cb.trip()
+			return
+		}
+		cb.state = CircuitClosed
+		cb.failures = 0
+		return
+	}
+
+	if err == nil {
+		cb.failures = 0
+		return
+	}
+	cb.failures++
+	if cb.state == CircuitClosed && cb.failures >= cb.threshold {
+		cb.trip()
+	}
+}
+
+// trip opens the circuit. The caller must hold cb.mu.
+func (cb *CircuitBreaker) trip() {
+	cb.state = CircuitOpen
+	cb.openedAt = cb.now()
+	cb.failures = 0
+}
+
+// halfOpenIfDue half-opens an open circuit whose timeout has passed. The
+// caller must hold cb.mu.
+func (cb *CircuitBreaker) halfOpenIfDue() {
+	if cb.state == CircuitOpen && cb.now().Sub(cb.openedAt) >= cb.timeout {
+		cb.state = CircuitHalfOpen
+		cb.probing = false
+	}
+}
+
+// circuitBreakers holds the breakers behind WithCircuitBreaker, keyed by
+// dependency name
+var circuitBreakers = struct {
+	sync.Mutex
+	byName map[string]*CircuitBreaker
+}{byName: make(map[string]*CircuitBreaker)}
+
+// circuitBreakerFor returns the shared breaker for name, creating it with
+// threshold and timeout on first use
+func circuitBreakerFor(name string, threshold int, timeout time.Duration) *CircuitBreaker {
+	circuitBreakers.Lock()
+	defer circuitBreakers.Unlock()
+
+	cb, ok := circuitBreakers.byName[name]
+	if !ok {
+		cb = NewCircuitBreaker(threshold, timeout)
+		circuitBreakers.byName[name] = cb
+	}
+	return cb
+}
@@ -0,0 +1,82 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker_States(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cb := NewCircuitBreaker(3, time.Minute)
+	cb.now = func() time.Time { return now }
+
+	calls := 0
+	fail := func() error { calls++; return fmt.Errorf("unavailable") }
+	succeed := func() error { calls++; return nil }
+
+	// A success resets the failure count
+	assert.Error(t, cb.Call(fail))
+	assert.Error(t, cb.Call(fail))
+	require.NoError(t, cb.Call(succeed))
+	assert.Error(t, cb.Call(fail))
+	assert.Error(t, cb.Call(fail))
+	assert.Equal(t, CircuitClosed, cb.State())
+
+	// The third consecutive failure trips it, and open calls are short-circuited
+	assert.Error(t, cb.Call(fail))
+	assert.Equal(t, CircuitOpen, cb.State())
+	calls = 0
+	assert.ErrorIs(t, cb.Call(succeed), ErrCircuitOpen)
+	assert.Zero(t, calls)
+
+	// After the timeout a failed probe opens it again
+	now = now.Add(time.Minute)
+	assert.Equal(t, CircuitHalfOpen, cb.State())
+	assert.EqualError(t, cb.Call(fail), "unavailable")
+	assert.Equal(t, CircuitOpen, cb.State())
+	assert.ErrorIs(t, cb.Call(succeed), ErrCircuitOpen)
+
+	// and a successful one closes it
+	now = now.Add(time.Minute)
+	calls = 0
+	require.NoError(t, cb.Call(succeed))
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, CircuitClosed, cb.State())
+}
+
+func TestCircuitBreaker_SingleHalfOpenProbe(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cb := NewCircuitBreaker(1, time.Second)
+	cb.now = func() time.Time { return now }
+
+	assert.Error(t, cb.Call(func() error { return fmt.Errorf("down") }))
+	now = now.Add(time.Second)
+
+	// Calls made while the probe is in flight are rejected
+	var during error
+	require.NoError(t, cb.Call(func() error {
+		during = cb.Call(func() error { return nil })
+		return nil
+	}))
+	assert.ErrorIs(t, during, ErrCircuitOpen)
+	assert.Equal(t, CircuitClosed, cb.State())
+}
+
+func TestWithCircuitBreaker_SharedByName(t *testing.T) {
+	name := t.Name()
+	for i := 0; i < 2; i++ {
+		assert.Error(t, WithCircuitBreaker(name, 2, time.Hour, func() error { return fmt.Errorf("down") }))
+	}
+
+	called := false
+	err := WithCircuitBreaker(name, 2, time.Hour, func() error { called = true; return nil })
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.False(t, called)
+
+	// Other dependencies have their own breaker
+	require.NoError(t, WithCircuitBreaker(name+"-other", 2, time.Hour, func() error { return nil }))
+}
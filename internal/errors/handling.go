@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"runtime"
 	"time"
 
@@ -11,6 +12,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/Xover-Official/Xover/internal/logger"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
@@ -239,6 +241,17 @@ func NewAIServiceError(model, service string, cause error) *TalosError {
 		Build()
 }
 
+// NewAIBudgetExceededError creates an error for an AI call refused because
+// its cost budget is spent
+func NewAIBudgetExceededError(spentUSD, budgetUSD float64) *TalosError {
+	return NewErrorBuilder(ErrAIInsufficientTokens, "AI cost budget exceeded").
+		Description(fmt.Sprintf("AI calls have cost $%.4f, over the $%.4f budget", spentUSD, budgetUSD)).
+		Severity(SeverityMedium).
+		Context("spent_usd", spentUSD).
+		Context("budget_usd", budgetUSD).
+		Build()
+}
+
 // NewOptimizationFailedError creates an optimization failed error
 func NewOptimizationFailedError(resourceID, action string, cause error) *TalosError {
 	return NewErrorBuilder(ErrOptimizationFailed, fmt.Sprintf("Optimization failed for resource %s", resourceID)).
@@ -390,6 +403,10 @@ type ErrorMetrics struct {
 	ErrorsByCode   map[ErrorCode]int64
 	ErrorsByHour   map[string]int64
 	CriticalErrors int64
+
+	// Prometheus counters mirroring the totals above; see Register
+	errorsTotal   *prometheus.CounterVec
+	criticalTotal prometheus.Counter
 }
 
 // NewErrorMetrics creates new error metrics
@@ -397,7 +414,28 @@ func NewErrorMetrics() *ErrorMetrics {
 	return &ErrorMetrics{
 		ErrorsByCode: make(map[ErrorCode]int64),
 		ErrorsByHour: make(map[string]int64),
+		errorsTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "talos_errors_total",
+			Help: "Total number of errors recorded, by error code and severity",
+		}, []string{"code", "severity"}),
+		criticalTotal: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "talos_errors_critical_total",
+			Help: "Total number of critical errors recorded",
+		}),
+	}
+}
+
+// Register exposes the error counters through reg, usually
+// prometheus.DefaultRegisterer so that they are served on /metrics
+func (m *ErrorMetrics) Register(reg prometheus.Registerer) error {
+	if err := reg.Register(m.errorsTotal); err != nil {
+		return err
+	}
+	if err := reg.Register(m.criticalTotal); err != nil {
+		reg.Unregister(m.errorsTotal)
+		return err
 	}
+	return nil
 }
 
 // Record records an error in metrics (thread-safe)
@@ -410,6 +448,7 @@ func (m *ErrorMetrics) Record(err *TalosError) {
 
 	m.TotalErrors++
 	m.ErrorsByCode[err.Code]++
+	m.errorsTotal.WithLabelValues(string(err.Code), string(err.Severity)).Inc()
 
 	hour := err.Timestamp.Format("2006-01-02T15")
 	m.ErrorsByHour[hour]++
@@ -426,6 +465,7 @@ func (m *ErrorMetrics) Record(err *TalosError) {
 
 	if err.Severity == SeverityCritical {
 		m.CriticalErrors++
+		m.criticalTotal.Inc()
 	}
 }
 
@@ -663,22 +703,68 @@ func (e *EnhancedTalosError) WithCause(cause error) *EnhancedTalosError {
 }
 
 // Recovery helpers
+
+// sleep waits for d, returning early with the context's error if ctx is
+// done. Tests replace it to record the delays.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// jitter picks a random delay in [0, ceiling]
+var jitter = func(ceiling time.Duration) time.Duration {
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// WithRetry calls fn up to maxRetries times, waiting delay between attempts
 func WithRetry(ctx context.Context, maxRetries int, delay time.Duration, fn func() error) error {
+	return retry(ctx, maxRetries, func(int) time.Duration { return delay }, fn)
+}
+
+// WithRetryBackoff calls fn up to maxRetries times with exponential backoff
+// and full jitter: the wait after attempt n (counting from 0) is random up
+// to base*2^n, capped at maxDelay. The randomness keeps callers that fail
+// together from retrying together.
+func WithRetryBackoff(ctx context.Context, maxRetries int, base, maxDelay time.Duration, fn func() error) error {
+	return retry(ctx, maxRetries, func(attempt int) time.Duration {
+		return jitter(backoffCeiling(base, maxDelay, attempt))
+	}, fn)
+}
+
+// backoffCeiling returns base*2^attempt, capped at maxDelay
+func backoffCeiling(base, maxDelay time.Duration, attempt int) time.Duration {
+	d := base
+	for i := 0; i < attempt && d < maxDelay; i++ {
+		if d > maxDelay/2 {
+			return maxDelay
+		}
+		d *= 2
+	}
+	return min(d, maxDelay)
+}
+
+func retry(ctx context.Context, maxRetries int, delay func(attempt int) time.Duration, fn func() error) error {
 	var err error
 	for i := 0; i < maxRetries; i++ {
 		if ctx.Err() != nil {
 			return ctx.Err()
 		}
 		err = fn()
-		if err == nil {
+		if err == nil || i == maxRetries-1 {
 			break
 		}
 
-		// Log retry attempt
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(delay):
+		if serr := sleep(ctx, delay(i)); serr != nil {
+			return serr
 		}
 	}
 	return err
@@ -694,10 +780,11 @@ func WithFallback(primary, fallback func() error) error {
 	return fallback()
 }
 
-func WithCircuitBreaker(threshold int, timeout time.Duration, fn func() error) error {
-	// Simple circuit breaker implementation
-	// In production, this would track failure rates and open/close the circuit
-	return fn()
+// WithCircuitBreaker calls fn through the circuit breaker shared by every
+// call for the named dependency. The breaker is created with threshold and
+// timeout on the first call for name.
+func WithCircuitBreaker(name string, threshold int, timeout time.Duration, fn func() error) error {
+	return circuitBreakerFor(name, threshold, timeout).Call(fn)
 }
 
 func WithGracefulDegradation(degradedService string, normal, degraded func() error) error {
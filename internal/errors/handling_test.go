@@ -0,0 +1,126 @@
+package errors
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorMetrics_Prometheus(t *testing.T) {
+	m := NewErrorMetrics()
+	reg := prometheus.NewRegistry()
+	require.NoError(t, m.Register(reg))
+
+	m.Record(NewValidationError("bad input"))
+	m.Record(NewValidationError("worse input"))
+	m.Record(NewInternalError("boom", fmt.Errorf("disk full")))
+	m.Record(NewResourceNotFoundError("instance", "i-123"))
+	m.Record(nil)
+
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.errorsTotal.WithLabelValues(string(ErrInvalidInput), string(SeverityLow))))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.errorsTotal.WithLabelValues(string(ErrInternalError), string(SeverityCritical))))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.errorsTotal.WithLabelValues(string(ErrResourceNotFound), string(SeverityMedium))))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.criticalTotal))
+
+	stats := m.GetStats()
+	assert.Equal(t, int64(4), stats["total_errors"])
+	assert.Equal(t, int64(1), stats["critical_errors"])
+}
+
+func TestErrorMetrics_RegisterTwice(t *testing.T) {
+	m := NewErrorMetrics()
+	reg := prometheus.NewRegistry()
+	require.NoError(t, m.Register(reg))
+	assert.Error(t, m.Register(reg))
+}
+
+// recordSleeps replaces sleep for the test and returns the delays it was
+// asked for
+func recordSleeps(t *testing.T) *[]time.Duration {
+	var slept []time.Duration
+	orig := sleep
+	sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return ctx.Err()
+	}
+	t.Cleanup(func() { sleep = orig })
+	return &slept
+}
+
+func TestWithRetryBackoff_Bounds(t *testing.T) {
+	slept := recordSleeps(t)
+	base, maxDelay := 100*time.Millisecond, time.Second
+
+	calls := 0
+	err := WithRetryBackoff(context.Background(), 6, base, maxDelay, func() error {
+		calls++
+		return fmt.Errorf("attempt %d", calls)
+	})
+	assert.EqualError(t, err, "attempt 6")
+	assert.Equal(t, 6, calls)
+
+	// No wait after the last attempt
+	require.Len(t, *slept, 5)
+	ceilings := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second}
+	for i, d := range *slept {
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, ceilings[i], "attempt %d", i)
+	}
+}
+
+func TestWithRetryBackoff_Grows(t *testing.T) {
+	slept := recordSleeps(t)
+	orig := jitter
+	jitter = func(ceiling time.Duration) time.Duration { return ceiling }
+	t.Cleanup(func() { jitter = orig })
+
+	_ = WithRetryBackoff(context.Background(), 8, 10*time.Millisecond, 500*time.Millisecond, func() error {
+		return fmt.Errorf("down")
+	})
+	assert.Equal(t, []time.Duration{
+		10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 80 * time.Millisecond,
+		160 * time.Millisecond, 320 * time.Millisecond, 500 * time.Millisecond,
+	}, *slept)
+
+	assert.Equal(t, time.Duration(1<<62), backoffCeiling(time.Second, 1<<62, 200))
+}
+
+func TestWithRetryBackoff_StopsOnSuccessAndCancel(t *testing.T) {
+	slept := recordSleeps(t)
+
+	calls := 0
+	err := WithRetryBackoff(context.Background(), 5, time.Millisecond, time.Second, func() error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("down")
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Len(t, *slept, 2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = WithRetryBackoff(ctx, 5, time.Millisecond, time.Second, func() error {
+		calls++
+		cancel()
+		return fmt.Errorf("down")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+}
+
+func TestWithRetry_FixedDelay(t *testing.T) {
+	slept := recordSleeps(t)
+
+	err := WithRetry(context.Background(), 3, 50*time.Millisecond, func() error { return fmt.Errorf("down") })
+	assert.EqualError(t, err, "down")
+	assert.Equal(t, []time.Duration{50 * time.Millisecond, 50 * time.Millisecond}, *slept)
+}
@@ -0,0 +1,252 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/database"
+	"go.uber.org/zap"
+)
+
+// StreamSchemaVersion is the version of the StreamEvent envelope. It changes
+// only when a field is removed or changes meaning.
+const StreamSchemaVersion = 1
+
+// Headers sent with every stream delivery
+const (
+	HeaderSignature = "X-Talos-Signature" // "t=<unix seconds>,v1=<hex HMAC-SHA256>"
+	HeaderEventType = "X-Talos-Event"
+	HeaderEventID   = "X-Talos-Event-Id"
+	HeaderSequence  = "X-Talos-Sequence"
+)
+
+// ErrInvalidSignature is returned by VerifySignature for a missing, malformed,
+// stale or wrong signature
+var ErrInvalidSignature = errors.New("invalid event signature")
+
+// StreamEvent is the JSON body POSTed for each lifecycle event. Sequence
+// increases with every event, so consumers can order the feed and drop
+// redeliveries of a sequence they have already processed.
+type StreamEvent struct {
+	SchemaVersion int             `json:"schema_version"`
+	ID            string          `json:"id"`
+	Sequence      int64           `json:"sequence"`
+	Type          string          `json:"type"`
+	SubjectID     string          `json:"subject_id"` // action, resource or savings event ID
+	OccurredAt    time.Time       `json:"occurred_at"`
+	Data          json.RawMessage `json:"data"`
+}
+
+// NewStreamEvent wraps an outbox event in the stream envelope
+func NewStreamEvent(event *database.OutboxEvent) StreamEvent {
+	data := event.Data
+	if len(data) == 0 {
+		data = json.RawMessage("{}")
+	}
+	return StreamEvent{
+		SchemaVersion: StreamSchemaVersion,
+		ID:            event.ID,
+		Sequence:      event.Sequence,
+		Type:          event.Type,
+		SubjectID:     event.SubjectID,
+		OccurredAt:    event.OccurredAt.UTC(),
+		Data:          data,
+	}
+}
+
+// Sign returns the HeaderSignature value for body sent at timestamp: an
+// HMAC-SHA256, keyed by secret, of "<unix seconds>.<body>"
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + unix + ",v1=" + signature(secret, unix, body)
+}
+
+func signature(secret, unix string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unix))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature checks a HeaderSignature value against body, rejecting
+// signatures older than tolerance to limit replays
+func VerifySignature(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var unix, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			unix = value
+		case "v1":
+			sig = value
+		}
+	}
+	seconds, err := strconv.ParseInt(unix, 10, 64)
+	if err != nil || sig == "" {
+		return fmt.Errorf("%w: malformed header", ErrInvalidSignature)
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > tolerance || age < -tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(secret, unix, body))) {
+		return fmt.Errorf("%w: signature mismatch", ErrInvalidSignature)
+	}
+	return nil
+}
+
+// Outbox is the durable queue of lifecycle events the relay delivers
+type Outbox interface {
+	ListUndeliveredEvents(ctx context.Context, limit int) ([]*database.OutboxEvent, error)
+	MarkEventDelivered(ctx context.Context, sequence int64) error
+	RecordEventFailure(ctx context.Context, sequence int64, reason string) error
+}
+
+// RelayConfig configures a WebhookRelay
+type RelayConfig struct {
+	URL          string
+	Secret       string
+	PollInterval time.Duration
+	BatchSize    int
+	Timeout      time.Duration
+	MaxBackoff   time.Duration
+}
+
+// WebhookRelay POSTs outbox events to an endpoint in sequence order. An event
+// is marked delivered only after a 2xx response, so delivery is at least
+// once; a failed event is retried with backoff and holds back the events
+// after it, so the feed is never reordered.
+type WebhookRelay struct {
+	outbox Outbox
+	cfg    RelayConfig
+	client *http.Client
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewWebhookRelay creates a relay delivering from outbox
+func NewWebhookRelay(outbox Outbox, cfg RelayConfig, logger *zap.Logger) *WebhookRelay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxBackoff < cfg.PollInterval {
+		cfg.MaxBackoff = cfg.PollInterval
+	}
+	return &WebhookRelay{
+		outbox: outbox,
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Run delivers events until ctx is canceled, polling the outbox every
+// PollInterval and backing off while the endpoint fails
+func (r *WebhookRelay) Run(ctx context.Context) {
+	r.logger.Info("event stream started", zap.String("url", r.cfg.URL))
+	failures := 0
+	for {
+		wait := r.cfg.PollInterval
+		delivered, err := r.DeliverPending(ctx)
+		switch {
+		case ctx.Err() != nil:
+			return
+		case err != nil:
+			failures++
+			wait = r.backoff(failures)
+			r.logger.Warn("event stream delivery failed", zap.Int("failures", failures), zap.Duration("retry_in", wait), zap.Error(err))
+		default:
+			failures = 0
+			if delivered == r.cfg.BatchSize {
+				wait = 0 // more are likely waiting
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// backoff doubles the poll interval with each consecutive failure, up to
+// MaxBackoff
+func (r *WebhookRelay) backoff(failures int) time.Duration {
+	wait := r.cfg.PollInterval
+	for i := 1; i < failures && wait < r.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, r.cfg.MaxBackoff)
+}
+
+// DeliverPending sends one batch of undelivered events in sequence order and
+// returns how many were delivered. It stops at the first failure.
+func (r *WebhookRelay) DeliverPending(ctx context.Context) (int, error) {
+	pending, err := r.outbox.ListUndeliveredEvents(ctx, r.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, event := range pending {
+		if err := r.deliver(ctx, event); err != nil {
+			if recordErr := r.outbox.RecordEventFailure(ctx, event.Sequence, err.Error()); recordErr != nil {
+				r.logger.Warn("failed to record event delivery failure", zap.Int64("sequence", event.Sequence), zap.Error(recordErr))
+			}
+			return i, fmt.Errorf("event %d (%s): %w", event.Sequence, event.Type, err)
+		}
+		// A redelivery after this fails is harmless; consumers dedupe by sequence
+		if err := r.outbox.MarkEventDelivered(ctx, event.Sequence); err != nil {
+			return i, err
+		}
+	}
+	return len(pending), nil
+}
+
+// deliver POSTs one signed event
+func (r *WebhookRelay) deliver(ctx context.Context, event *database.OutboxEvent) error {
+	body, err := json.Marshal(NewStreamEvent(event))
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderSignature, Sign(r.cfg.Secret, r.now(), body))
+	req.Header.Set(HeaderEventType, event.Type)
+	req.Header.Set(HeaderEventID, event.ID)
+	req.Header.Set(HeaderSequence, strconv.FormatInt(event.Sequence, 10))
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return nil
+}
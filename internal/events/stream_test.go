@@ -0,0 +1,147 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type memoryOutbox struct {
+	mu        sync.Mutex
+	events    []*database.OutboxEvent
+	delivered map[int64]bool
+	failures  map[int64]int
+}
+
+func newMemoryOutbox(types ...string) *memoryOutbox {
+	o := &memoryOutbox{delivered: map[int64]bool{}, failures: map[int64]int{}}
+	for i, eventType := range types {
+		o.events = append(o.events, &database.OutboxEvent{
+			Sequence:   int64(i + 1),
+			ID:         "evt-" + eventType,
+			Type:       eventType,
+			SubjectID:  "action-1",
+			Data:       json.RawMessage(`{"action_id":"action-1"}`),
+			OccurredAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		})
+	}
+	return o
+}
+
+func (o *memoryOutbox) ListUndeliveredEvents(_ context.Context, limit int) ([]*database.OutboxEvent, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	var pending []*database.OutboxEvent
+	for _, event := range o.events {
+		if !o.delivered[event.Sequence] && len(pending) < limit {
+			pending = append(pending, event)
+		}
+	}
+	return pending, nil
+}
+
+func (o *memoryOutbox) MarkEventDelivered(_ context.Context, sequence int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.delivered[sequence] = true
+	return nil
+}
+
+func (o *memoryOutbox) RecordEventFailure(_ context.Context, sequence int64, _ string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.failures[sequence]++
+	return nil
+}
+
+func TestWebhookRelay_DeliversSignedEventsInOrder(t *testing.T) {
+	const secret = "s3cret"
+	var received []StreamEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		require.NoError(t, VerifySignature(secret, r.Header.Get(HeaderSignature), body, time.Now(), time.Minute))
+
+		var event StreamEvent
+		require.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, event.Type, r.Header.Get(HeaderEventType))
+		received = append(received, event)
+	}))
+	defer server.Close()
+
+	outbox := newMemoryOutbox(database.EventActionCreated, database.EventActionApproved, database.EventActionExecuted)
+	relay := NewWebhookRelay(outbox, RelayConfig{URL: server.URL, Secret: secret, BatchSize: 2}, zap.NewNop())
+
+	delivered, err := relay.DeliverPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	delivered, err = relay.DeliverPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	require.Len(t, received, 3)
+	for i, event := range received {
+		assert.Equal(t, int64(i+1), event.Sequence)
+		assert.Equal(t, StreamSchemaVersion, event.SchemaVersion)
+	}
+	assert.Equal(t, database.EventActionExecuted, received[2].Type)
+	assert.JSONEq(t, `{"action_id":"action-1"}`, string(received[0].Data))
+}
+
+func TestWebhookRelay_FailureHoldsBackLaterEvents(t *testing.T) {
+	failing := true
+	var sequences []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sequences = append(sequences, r.Header.Get(HeaderSequence))
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	outbox := newMemoryOutbox(database.EventActionCreated, database.EventActionExecuted)
+	relay := NewWebhookRelay(outbox, RelayConfig{URL: server.URL, Secret: "k"}, zap.NewNop())
+
+	delivered, err := relay.DeliverPending(context.Background())
+	assert.Error(t, err)
+	assert.Zero(t, delivered)
+	assert.Equal(t, 1, outbox.failures[1])
+	assert.False(t, outbox.delivered[1])
+
+	// The failed event is retried first once the endpoint recovers
+	failing = false
+	delivered, err = relay.DeliverPending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	assert.Equal(t, []string{"1", "1", "2"}, sequences)
+}
+
+func TestWebhookRelay_Backoff(t *testing.T) {
+	relay := NewWebhookRelay(nil, RelayConfig{PollInterval: time.Second, MaxBackoff: 5 * time.Second}, zap.NewNop())
+
+	assert.Equal(t, time.Second, relay.backoff(1))
+	assert.Equal(t, 2*time.Second, relay.backoff(2))
+	assert.Equal(t, 4*time.Second, relay.backoff(3))
+	assert.Equal(t, 5*time.Second, relay.backoff(10))
+}
+
+func TestVerifySignature(t *testing.T) {
+	body := []byte(`{"sequence":1}`)
+	now := time.Now()
+	header := Sign("key", now, body)
+
+	assert.NoError(t, VerifySignature("key", header, body, now, time.Minute))
+	assert.ErrorIs(t, VerifySignature("other", header, body, now, time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature("key", header, []byte(`{"sequence":2}`), now, time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature("key", header, body, now.Add(10*time.Minute), time.Minute), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifySignature("key", "garbage", body, now, time.Minute), ErrInvalidSignature)
+}
@@ -0,0 +1,189 @@
+package features
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// AutonomyMode is what the engine does with an action selected by the
+// autonomy flags
+type AutonomyMode string
+
+const (
+	AutonomyExecute AutonomyMode = "execute" // run without human approval
+	AutonomyApprove AutonomyMode = "approve" // wait for human approval
+	AutonomySkip    AutonomyMode = "skip"    // neither queue nor run
+)
+
+// Valid reports whether the mode is known
+func (m AutonomyMode) Valid() bool {
+	switch m {
+	case AutonomyExecute, AutonomyApprove, AutonomySkip:
+		return true
+	}
+	return false
+}
+
+// AutonomyRule selects actions by type, environment and resource tags.
+// Unset selectors match everything; a tag value of "*" matches any value.
+type AutonomyRule struct {
+	ActionType  string            `json:"action_type,omitempty" yaml:"action_type"`
+	Environment string            `json:"environment,omitempty" yaml:"environment"` // resource "environment" or "env" tag
+	Tags        map[string]string `json:"tags,omitempty" yaml:"tags"`
+	Mode        AutonomyMode      `json:"mode" yaml:"mode"`
+}
+
+// specificity counts the rule's selectors; the most specific match wins
+func (r AutonomyRule) specificity() int {
+	n := len(r.Tags)
+	if r.ActionType != "" {
+		n++
+	}
+	if r.Environment != "" {
+		n++
+	}
+	return n
+}
+
+func (r AutonomyRule) matches(actionType, environment string, tags map[string]string) bool {
+	if r.ActionType != "" && r.ActionType != actionType {
+		return false
+	}
+	if r.Environment != "" && r.Environment != environment {
+		return false
+	}
+	for key, want := range r.Tags {
+		got, ok := tags[key]
+		if !ok || (want != "*" && got != want) {
+			return false
+		}
+	}
+	return true
+}
+
+// AutonomyFlags decide, per action type, environment and tag selector,
+// whether the engine executes an action, queues it for approval or skips it.
+// An empty Default leaves actions no rule matches to the engine's own
+// approval settings, so empty flags change nothing. SafeMode queues every
+// action that is not skipped for approval, whatever the rules say, so it can
+// be toggled without losing them.
+type AutonomyFlags struct {
+	Default   AutonomyMode   `json:"default,omitempty" yaml:"default"`
+	Rules     []AutonomyRule `json:"rules,omitempty" yaml:"rules"`
+	SafeMode  bool           `json:"safe_mode,omitempty" yaml:"safe_mode"`
+	UpdatedBy string         `json:"updated_by,omitempty" yaml:"-"`
+	UpdatedAt time.Time      `json:"updated_at,omitempty" yaml:"-"`
+}
+
+// Validate checks every mode is known
+func (f AutonomyFlags) Validate() error {
+	if f.Default != "" && !f.Default.Valid() {
+		return fmt.Errorf("unknown autonomy default mode %q", f.Default)
+	}
+	for i, rule := range f.Rules {
+		if !rule.Mode.Valid() {
+			return fmt.Errorf("autonomy rule %d: unknown mode %q", i, rule.Mode)
+		}
+	}
+	return nil
+}
+
+// Mode returns the mode for an action of actionType on a resource with the
+// given tags. The most specific matching rule wins, the earlier one on a
+// tie; without a match it returns Default. In safe mode anything but
+// AutonomySkip becomes AutonomyApprove.
+func (f AutonomyFlags) Mode(actionType string, tags map[string]string) AutonomyMode {
+	environment := Environment(tags)
+	best := -1
+	mode := f.Default
+	for _, rule := range f.Rules {
+		if s := rule.specificity(); s > best && rule.matches(actionType, environment, tags) {
+			best, mode = s, rule.Mode
+		}
+	}
+	if f.SafeMode && mode != AutonomySkip {
+		return AutonomyApprove
+	}
+	return mode
+}
+
+// Environment returns a resource's environment from its "environment" or
+// "env" tag
+func Environment(tags map[string]string) string {
+	if env := tags["environment"]; env != "" {
+		return env
+	}
+	return tags["env"]
+}
+
+// AutonomyStore persists autonomy flags so they can be changed at runtime
+// and shared between processes
+type AutonomyStore interface {
+	// LoadAutonomy returns the stored flags, or nil if none were stored
+	LoadAutonomy(ctx context.Context) (*AutonomyFlags, error)
+	SaveAutonomy(ctx context.Context, flags *AutonomyFlags) error
+}
+
+// AutonomyGate serves the current autonomy flags. It starts from the
+// configured flags; once flags are stored, Refresh picks them up, so a change
+// takes effect without a restart.
+type AutonomyGate struct {
+	store AutonomyStore // nil keeps the configured flags
+	flags atomic.Pointer[AutonomyFlags]
+}
+
+// NewAutonomyGate creates a gate serving initial until the store holds flags
+func NewAutonomyGate(initial AutonomyFlags, store AutonomyStore) *AutonomyGate {
+	g := &AutonomyGate{store: store}
+	g.flags.Store(&initial)
+	return g
+}
+
+// Flags returns the current flags
+func (g *AutonomyGate) Flags() AutonomyFlags {
+	return *g.flags.Load()
+}
+
+// Mode returns the current mode for an action; see AutonomyFlags.Mode
+func (g *AutonomyGate) Mode(actionType string, tags map[string]string) AutonomyMode {
+	return g.flags.Load().Mode(actionType, tags)
+}
+
+// Refresh loads the stored flags and reports whether they changed. On error
+// the current flags are kept.
+func (g *AutonomyGate) Refresh(ctx context.Context) (bool, error) {
+	if g.store == nil {
+		return false, nil
+	}
+	stored, err := g.store.LoadAutonomy(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to load autonomy flags: %w", err)
+	}
+	if stored == nil {
+		return false, nil
+	}
+	if err := stored.Validate(); err != nil {
+		return false, fmt.Errorf("stored autonomy flags are invalid: %w", err)
+	}
+	previous := g.flags.Swap(stored)
+	return !previous.UpdatedAt.Equal(stored.UpdatedAt), nil
+}
+
+// Update validates flags, stamps them with actor and the time, stores them and
+// makes them current. It returns the flags they replaced for auditing.
+func (g *AutonomyGate) Update(ctx context.Context, flags AutonomyFlags, actor string) (AutonomyFlags, error) {
+	if err := flags.Validate(); err != nil {
+		return AutonomyFlags{}, err
+	}
+	flags.UpdatedBy = actor
+	flags.UpdatedAt = time.Now().UTC()
+
+	if g.store != nil {
+		if err := g.store.SaveAutonomy(ctx, &flags); err != nil {
+			return AutonomyFlags{}, fmt.Errorf("failed to save autonomy flags: %w", err)
+		}
+	}
+	return *g.flags.Swap(&flags), nil
+}
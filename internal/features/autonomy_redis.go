@@ -0,0 +1,50 @@
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// AutonomyKey is the Redis key holding the runtime autonomy flags
+const AutonomyKey = "talos:autonomy_flags"
+
+// RedisAutonomyStore keeps autonomy flags in Redis, where every engine and
+// dashboard instance sees the same values
+type RedisAutonomyStore struct {
+	client *redis.Client
+}
+
+// NewRedisAutonomyStore creates a store on client
+func NewRedisAutonomyStore(client *redis.Client) *RedisAutonomyStore {
+	return &RedisAutonomyStore{client: client}
+}
+
+// LoadAutonomy returns the stored flags, or nil if none were stored
+func (s *RedisAutonomyStore) LoadAutonomy(ctx context.Context) (*AutonomyFlags, error) {
+	data, err := s.client.Get(ctx, AutonomyKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var flags AutonomyFlags
+	if err := json.Unmarshal(data, &flags); err != nil {
+		return nil, fmt.Errorf("failed to decode autonomy flags: %w", err)
+	}
+	return &flags, nil
+}
+
+// SaveAutonomy stores flags without expiry
+func (s *RedisAutonomyStore) SaveAutonomy(ctx context.Context, flags *AutonomyFlags) error {
+	data, err := json.Marshal(flags)
+	if err != nil {
+		return fmt.Errorf("failed to encode autonomy flags: %w", err)
+	}
+	return s.client.Set(ctx, AutonomyKey, data, 0).Err()
+}
@@ -0,0 +1,99 @@
+package features
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryAutonomyStore struct {
+	flags *AutonomyFlags
+}
+
+func (s *memoryAutonomyStore) LoadAutonomy(context.Context) (*AutonomyFlags, error) {
+	return s.flags, nil
+}
+
+func (s *memoryAutonomyStore) SaveAutonomy(_ context.Context, flags *AutonomyFlags) error {
+	stored := *flags
+	s.flags = &stored
+	return nil
+}
+
+func TestAutonomyFlags_Mode(t *testing.T) {
+	flags := AutonomyFlags{
+		Default: AutonomyApprove,
+		Rules: []AutonomyRule{
+			{ActionType: "release_address", Mode: AutonomyExecute},
+			{ActionType: "release_address", Environment: "production", Mode: AutonomyApprove},
+			{Tags: map[string]string{"team": "*"}, Environment: "dev", Mode: AutonomyExecute},
+			{ActionType: "terminate", Mode: AutonomySkip},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		actionType string
+		tags       map[string]string
+		want       AutonomyMode
+	}{
+		{"action type rule", "release_address", map[string]string{"env": "staging"}, AutonomyExecute},
+		{"more specific rule wins", "release_address", map[string]string{"environment": "production"}, AutonomyApprove},
+		{"tag wildcard", "optimize", map[string]string{"env": "dev", "team": "web"}, AutonomyExecute},
+		{"tag missing", "optimize", map[string]string{"env": "dev"}, AutonomyApprove},
+		{"skip", "terminate", nil, AutonomySkip},
+		{"default", "optimize", nil, AutonomyApprove},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, flags.Mode(tt.actionType, tt.tags))
+		})
+	}
+
+	assert.Empty(t, AutonomyFlags{}.Mode("optimize", nil), "empty flags defer to the engine")
+
+	flags.SafeMode = true
+	assert.Equal(t, AutonomyApprove, flags.Mode("release_address", nil), "safe mode overrides execute")
+	assert.Equal(t, AutonomySkip, flags.Mode("terminate", nil), "safe mode keeps skips")
+	assert.Equal(t, AutonomyApprove, AutonomyFlags{SafeMode: true}.Mode("optimize", nil))
+}
+
+func TestAutonomyFlags_Validate(t *testing.T) {
+	assert.NoError(t, AutonomyFlags{}.Validate())
+	assert.Error(t, AutonomyFlags{Default: "always"}.Validate())
+	assert.Error(t, AutonomyFlags{Rules: []AutonomyRule{{ActionType: "optimize"}}}.Validate())
+}
+
+func TestAutonomyGate_UpdateAndRefresh(t *testing.T) {
+	ctx := context.Background()
+	store := &memoryAutonomyStore{}
+	configured := AutonomyFlags{Default: AutonomyApprove}
+
+	writer := NewAutonomyGate(configured, store)
+	reader := NewAutonomyGate(configured, store)
+
+	changed, err := reader.Refresh(ctx)
+	require.NoError(t, err)
+	assert.False(t, changed, "nothing stored keeps the configured flags")
+	assert.Equal(t, AutonomyApprove, reader.Mode("optimize", nil))
+
+	previous, err := writer.Update(ctx, AutonomyFlags{Default: AutonomyExecute}, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, AutonomyApprove, previous.Default)
+
+	changed, err = reader.Refresh(ctx)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, AutonomyExecute, reader.Mode("optimize", nil))
+	assert.Equal(t, "user-1", reader.Flags().UpdatedBy)
+
+	changed, err = reader.Refresh(ctx)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	_, err = writer.Update(ctx, AutonomyFlags{Default: "sometimes"}, "user-1")
+	assert.Error(t, err)
+	assert.Equal(t, AutonomyExecute, writer.Mode("optimize", nil), "invalid flags are not applied")
+}
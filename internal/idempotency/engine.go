@@ -4,20 +4,32 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/Xover-Official/Xover/internal/logger"
 	"github.com/Xover-Official/Xover/pkg/models"
 	"github.com/google/uuid"
 )
 
+// ErrInProgress is returned by ExecuteGuarded when the same action is already
+// pending, in this process or another worker sharing the ledger
+var ErrInProgress = errors.New("action already in progress")
+
 type Engine struct {
-	ledger       *Ledger
+	ledger       Ledger
+	ttl          time.Duration
 	PersonalMode bool
 }
 
-func NewEngine(ledger *Ledger) *Engine {
-	return &Engine{ledger: ledger}
+// NewEngine returns an engine whose ledger entries expire after ttl, after
+// which the same action runs again. A ttl of zero uses DefaultTTL.
+func NewEngine(ledger Ledger, ttl time.Duration) *Engine {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Engine{ledger: ledger, ttl: ttl}
 }
 
 func (e *Engine) ResumePendingTasks(handler func(requestID, checksum string) (interface{}, func() (string, error), error)) error {
@@ -60,27 +72,14 @@ func (e *Engine) ExecuteGuarded(agent logger.Agent, actionName string, payload i
 		return "", fmt.Errorf("failed to generate checksum: %w", err)
 	}
 
-	// Check if already completed OR pending
-	existing, err := e.ledger.GetByChecksum(checksum)
-	if err != nil {
-		return "", fmt.Errorf("ledger lookup failed: %w", err)
-	}
-
-	if existing != nil {
-		if existing.Status == models.StatusCompleted {
-			logger.LogAction(agent, actionName, "SKIPPED", fmt.Sprintf("Idempotent hit for checksum %s", checksum))
-			return existing.ResourceID, nil
-		}
-		if existing.Status == models.StatusPending {
-			logger.LogAction(agent, actionName, "RESUMING", fmt.Sprintf("Recovered from previous crash. RequestID: %s", existing.RequestID))
-			requestID := existing.RequestID
-			return e.executeAction(agent, actionName, requestID, payload, actionFn)
-		}
-	}
-
-	// Record New Pending
+	// Claim the action. Whoever records the pending entry first runs it;
+	// expired entries don't count, so the action runs again.
 	requestID := uuid.New().String()
-	if err := e.ledger.RecordPending(requestID, checksum); err != nil {
+	err = e.ledger.RecordPending(requestID, checksum, e.ttl)
+	if errors.Is(err, ErrAlreadyRecorded) {
+		return e.alreadyRecorded(agent, actionName, checksum)
+	}
+	if err != nil {
 		return "", fmt.Errorf("failed to record pending action: %w", err)
 	}
 
@@ -92,14 +91,14 @@ func (e *Engine) ExecuteGuarded(agent logger.Agent, actionName string, payload i
 	// Dynamic override for 'Black Hole' test scenario
 	currentRisk := 4.5
 	if actionName == "SafetyOverrideTest" {
-		currentRisk = 9.8 
+		currentRisk = 9.8
 	} else if actionName == "AdversarialTest" {
 		currentRisk = 10.0 // Hard Block
 	}
 
 	// STRICT BOUNDARY CHECK: Anything >= 5.0 requires approval
 	if currentRisk >= DefaultRiskThreshold {
-		logger.LogAction(logger.Auditor, actionName, "AWAITING_APPROVAL", 
+		logger.LogAction(logger.Auditor, actionName, "AWAITING_APPROVAL",
 			fmt.Sprintf("GOVERNANCE BLOCK: Risk Score %.1f exceeds threshold %.1f. Need explicit owner sign-off for RequestID: %s", currentRisk, DefaultRiskThreshold, requestID))
 		return "AWAITING_APPROVAL", nil
 	}
@@ -107,11 +106,29 @@ func (e *Engine) ExecuteGuarded(agent logger.Agent, actionName string, payload i
 	return e.executeAction(agent, actionName, requestID, payload, actionFn)
 }
 
+// alreadyRecorded returns the result of an action another caller claimed. A
+// pending action is left to its owner, or to ResumePendingTasks if the owner
+// crashed.
+func (e *Engine) alreadyRecorded(agent logger.Agent, actionName, checksum string) (string, error) {
+	existing, err := e.ledger.GetByChecksum(checksum)
+	if err != nil {
+		return "", fmt.Errorf("ledger lookup failed: %w", err)
+	}
+	if existing != nil && existing.Status == models.StatusCompleted {
+		logger.LogAction(agent, actionName, "SKIPPED", fmt.Sprintf("Idempotent hit for checksum %s", checksum))
+		return existing.ResourceID, nil
+	}
+	if existing != nil {
+		logger.LogAction(agent, actionName, "IN_PROGRESS", fmt.Sprintf("Already claimed. RequestID: %s", existing.RequestID))
+	}
+	return "", fmt.Errorf("%w: checksum %s", ErrInProgress, checksum)
+}
+
 func (e *Engine) executeAction(agent logger.Agent, actionName string, requestID string, payload interface{}, actionFn func() (string, error)) (string, error) {
 	// FINAL INTEGRITY CHECK: Re-calculate checksum to detect context drift or tampering
 	currentChecksum, _ := e.GenerateChecksum(payload)
 	existing, _ := e.ledger.GetByChecksum(currentChecksum)
-	
+
 	if existing == nil || existing.RequestID != requestID {
 		logger.LogAction(logger.Auditor, actionName, "SECURITY_BLOCK", "Checksum mismatch detected! Possible context drift or ledger tampering. Refusing execution.")
 		return "", fmt.Errorf("integrity violation: checksum mismatch for task %s", requestID)
@@ -121,6 +138,10 @@ func (e *Engine) executeAction(agent logger.Agent, actionName string, requestID
 	resourceID, err := actionFn()
 	if err != nil {
 		logger.LogAction(agent, actionName, "FAILED", err.Error())
+		// Release the claim so the action can be retried
+		if releaseErr := e.ledger.Release(requestID); releaseErr != nil {
+			logger.LogAction(logger.Auditor, actionName, "FAILED", fmt.Sprintf("failed to release RequestID %s: %v", requestID, releaseErr))
+		}
 		return "", err
 	}
 
@@ -0,0 +1,145 @@
+package idempotency
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xover-Official/Xover/internal/logger"
+	"github.com/Xover-Official/Xover/pkg/models"
+)
+
+func newTestLedger(t *testing.T) (*SQLiteLedger, *time.Time) {
+	t.Helper()
+	ledger, err := NewSQLiteLedger(filepath.Join(t.TempDir(), "ledger.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { ledger.db.Close() })
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ledger.now = func() time.Time { return now }
+	return ledger, &now
+}
+
+func TestExecuteGuarded_ReExecutesAfterExpiry(t *testing.T) {
+	ledger, now := newTestLedger(t)
+	engine := NewEngine(ledger, time.Hour)
+
+	runs := 0
+	action := func() (string, error) {
+		runs++
+		return "i-123", nil
+	}
+	payload := map[string]string{"action": "stop", "instance": "i-123"}
+
+	result, err := engine.ExecuteGuarded(logger.Builder, "StopInstance", payload, action)
+	require.NoError(t, err)
+	assert.Equal(t, "i-123", result)
+	assert.Equal(t, 1, runs)
+
+	*now = now.Add(59 * time.Minute)
+	_, err = engine.ExecuteGuarded(logger.Builder, "StopInstance", payload, action)
+	require.NoError(t, err)
+	assert.Equal(t, 1, runs, "skipped while the entry is live")
+
+	*now = now.Add(2 * time.Minute)
+	_, err = engine.ExecuteGuarded(logger.Builder, "StopInstance", payload, action)
+	require.NoError(t, err)
+	assert.Equal(t, 2, runs, "expired entries are a cache miss")
+}
+
+func TestNewEngine_DefaultTTL(t *testing.T) {
+	assert.Equal(t, DefaultTTL, NewEngine(nil, 0).ttl)
+	assert.Equal(t, time.Minute, NewEngine(nil, time.Minute).ttl)
+}
+
+func TestLedger_Cleanup(t *testing.T) {
+	ledger, now := newTestLedger(t)
+	require.NoError(t, ledger.RecordPending("short", "a", time.Hour))
+	require.NoError(t, ledger.RecordPending("long", "b", 48*time.Hour))
+
+	*now = now.Add(3 * time.Hour)
+	removed, err := ledger.Cleanup(4 * time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, removed, "expired only two hours ago")
+
+	removed, err = ledger.Cleanup(time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), removed)
+
+	rec, err := ledger.GetByChecksum("b")
+	require.NoError(t, err)
+	require.NotNil(t, rec)
+	assert.Equal(t, "long", rec.RequestID)
+	assert.Equal(t, models.StatusPending, rec.Status)
+	assert.Equal(t, now.Add(45*time.Hour), rec.ExpiresAt.UTC())
+}
+
+func TestNewSQLiteLedger_MigratesLegacySchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`
+	CREATE TABLE idempotency_ledger (
+		request_id TEXT PRIMARY KEY,
+		checksum TEXT NOT NULL,
+		status TEXT NOT NULL,
+		resource_id TEXT,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	);
+	INSERT INTO idempotency_ledger VALUES ('old', 'c', 'COMPLETED', 'i-1', '2025-01-01 00:00:00', '2025-01-01 00:00:00');`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	ledger, err := NewSQLiteLedger(path)
+	require.NoError(t, err)
+	defer ledger.db.Close()
+
+	// Entries written before expiry existed never expire or get cleaned up
+	removed, err := ledger.Cleanup(0)
+	require.NoError(t, err)
+	assert.Zero(t, removed)
+	rec, err := ledger.GetByChecksum("c")
+	require.NoError(t, err)
+	require.NotNil(t, rec)
+	assert.Equal(t, "i-1", rec.ResourceID)
+	assert.True(t, rec.ExpiresAt.IsZero())
+}
+
+func TestExecuteGuarded_FailedActionCanBeRetried(t *testing.T) {
+	ledger, _ := newTestLedger(t)
+	engine := NewEngine(ledger, time.Hour)
+	payload := map[string]string{"action": "stop", "instance": "i-123"}
+
+	_, err := engine.ExecuteGuarded(logger.Builder, "StopInstance", payload, func() (string, error) {
+		return "", errors.New("throttled")
+	})
+	require.Error(t, err)
+
+	result, err := engine.ExecuteGuarded(logger.Builder, "StopInstance", payload, func() (string, error) {
+		return "i-123", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "i-123", result)
+}
+
+func TestExecuteGuarded_PendingActionIsNotRunTwice(t *testing.T) {
+	ledger, _ := newTestLedger(t)
+	engine := NewEngine(ledger, time.Hour)
+	payload := map[string]string{"action": "stop", "instance": "i-123"}
+
+	checksum, err := engine.GenerateChecksum(payload)
+	require.NoError(t, err)
+	require.NoError(t, ledger.RecordPending("other-worker", checksum, time.Hour))
+
+	_, err = engine.ExecuteGuarded(logger.Builder, "StopInstance", payload, func() (string, error) {
+		t.Fatal("ran an action another caller claimed")
+		return "", nil
+	})
+	assert.ErrorIs(t, err, ErrInProgress)
+}
@@ -0,0 +1,160 @@
+package idempotency
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Xover-Official/Xover/pkg/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisOpTimeout bounds each Redis call the ledger makes
+const redisOpTimeout = 5 * time.Second
+
+// RedisLedger shares the ledger between workers. Each checksum is claimed
+// with SET NX, so only one worker runs a given action, and Redis expires
+// entries after their TTL.
+type RedisLedger struct {
+	client *redis.Client
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisLedger returns a ledger storing its entries in client
+func NewRedisLedger(client *redis.Client) *RedisLedger {
+	return &RedisLedger{client: client, prefix: "talos:idempotency:", now: time.Now}
+}
+
+func (l *RedisLedger) checksumKey(checksum string) string {
+	return l.prefix + "checksum:" + checksum
+}
+
+// requestKey maps a request ID back to its checksum
+func (l *RedisLedger) requestKey(requestID string) string {
+	return l.prefix + "request:" + requestID
+}
+
+func (l *RedisLedger) RecordPending(requestID, checksum string, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	now := l.now().UTC()
+	data, err := json.Marshal(models.ActionRecord{
+		RequestID: requestID,
+		Checksum:  checksum,
+		Status:    models.StatusPending,
+		CreatedAt: now,
+		UpdatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		return err
+	}
+
+	claimed, err := l.client.SetNX(ctx, l.checksumKey(checksum), data, ttl).Result()
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return ErrAlreadyRecorded
+	}
+	return l.client.Set(ctx, l.requestKey(requestID), checksum, ttl).Err()
+}
+
+func (l *RedisLedger) Complete(requestID, resourceID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	rec, err := l.byRequestID(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	rec.Status = models.StatusCompleted
+	rec.ResourceID = resourceID
+	rec.UpdatedAt = l.now().UTC()
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return l.client.SetArgs(ctx, l.checksumKey(rec.Checksum), data, redis.SetArgs{Mode: "XX", KeepTTL: true}).Err()
+}
+
+func (l *RedisLedger) Release(requestID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	rec, err := l.byRequestID(ctx, requestID)
+	if err != nil {
+		return err
+	}
+	if rec.Status != models.StatusPending {
+		return nil
+	}
+	return l.client.Del(ctx, l.checksumKey(rec.Checksum), l.requestKey(requestID)).Err()
+}
+
+func (l *RedisLedger) GetPendingTasks() ([]models.ActionRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	var tasks []models.ActionRecord
+	iter := l.client.Scan(ctx, 0, l.checksumKey("*"), 100).Iterator()
+	for iter.Next(ctx) {
+		rec, err := l.get(ctx, iter.Val())
+		if err != nil {
+			return nil, err
+		}
+		if rec != nil && rec.Status == models.StatusPending {
+			tasks = append(tasks, *rec)
+		}
+	}
+	return tasks, iter.Err()
+}
+
+func (l *RedisLedger) GetByChecksum(checksum string) (*models.ActionRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), redisOpTimeout)
+	defer cancel()
+
+	return l.get(ctx, l.checksumKey(checksum))
+}
+
+// byRequestID returns the entry recorded for a request ID. It belongs to
+// another request if the checksum has since been claimed again.
+func (l *RedisLedger) byRequestID(ctx context.Context, requestID string) (*models.ActionRecord, error) {
+	checksum, err := l.client.Get(ctx, l.requestKey(requestID)).Result()
+	if err == redis.Nil {
+		return nil, fmt.Errorf("no ledger entry for request %s", requestID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rec, err := l.get(ctx, l.checksumKey(checksum))
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil || rec.RequestID != requestID {
+		return nil, fmt.Errorf("no ledger entry for request %s", requestID)
+	}
+	return rec, nil
+}
+
+// get returns the entry stored at key, or nil if it doesn't exist
+func (l *RedisLedger) get(ctx context.Context, key string) (*models.ActionRecord, error) {
+	data, err := l.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var rec models.ActionRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("corrupt ledger entry %s: %w", key, err)
+	}
+	return &rec, nil
+}
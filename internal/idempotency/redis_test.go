@@ -0,0 +1,109 @@
+package idempotency
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xover-Official/Xover/internal/logger"
+	"github.com/Xover-Official/Xover/pkg/models"
+)
+
+func newTestRedisLedger(t *testing.T) (*RedisLedger, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return NewRedisLedger(client), server
+}
+
+func TestRedisLedger_ConcurrentCallsExecuteOnce(t *testing.T) {
+	ledger, _ := newTestRedisLedger(t)
+	// Two workers sharing one Redis
+	workers := []*Engine{NewEngine(ledger, time.Hour), NewEngine(ledger, time.Hour)}
+	payload := map[string]string{"action": "stop", "instance": "i-123"}
+
+	var mu sync.Mutex
+	runs := 0
+	release := make(chan struct{})
+	action := func() (string, error) {
+		mu.Lock()
+		runs++
+		mu.Unlock()
+		<-release
+		return "i-123", nil
+	}
+
+	type result struct {
+		resourceID string
+		err        error
+	}
+	results := make(chan result, len(workers))
+	for _, engine := range workers {
+		go func(engine *Engine) {
+			resourceID, err := engine.ExecuteGuarded(logger.Builder, "StopInstance", payload, action)
+			results <- result{resourceID, err}
+		}(engine)
+	}
+
+	// The winner blocks in the action, so the loser returns first
+	loser := <-results
+	assert.ErrorIs(t, loser.err, ErrInProgress)
+	close(release)
+	winner := <-results
+	require.NoError(t, winner.err)
+	assert.Equal(t, "i-123", winner.resourceID)
+	assert.Equal(t, 1, runs)
+
+	// Later calls are idempotent hits
+	resourceID, err := workers[1].ExecuteGuarded(logger.Builder, "StopInstance", payload, action)
+	require.NoError(t, err)
+	assert.Equal(t, "i-123", resourceID)
+	assert.Equal(t, 1, runs)
+}
+
+func TestRedisLedger_EntriesExpire(t *testing.T) {
+	ledger, server := newTestRedisLedger(t)
+	require.NoError(t, ledger.RecordPending("req-1", "abc", time.Hour))
+	assert.ErrorIs(t, ledger.RecordPending("req-2", "abc", time.Hour), ErrAlreadyRecorded)
+
+	require.NoError(t, ledger.Complete("req-1", "i-123"))
+	rec, err := ledger.GetByChecksum("abc")
+	require.NoError(t, err)
+	require.NotNil(t, rec)
+	assert.Equal(t, models.StatusCompleted, rec.Status)
+	assert.Equal(t, time.Hour, server.TTL(ledger.checksumKey("abc")), "completing keeps the TTL")
+
+	server.FastForward(time.Hour)
+	rec, err = ledger.GetByChecksum("abc")
+	require.NoError(t, err)
+	assert.Nil(t, rec)
+	assert.NoError(t, ledger.RecordPending("req-2", "abc", time.Hour))
+}
+
+func TestRedisLedger_PendingAndRelease(t *testing.T) {
+	ledger, _ := newTestRedisLedger(t)
+	require.NoError(t, ledger.RecordPending("req-1", "abc", time.Hour))
+	require.NoError(t, ledger.RecordPending("req-2", "def", time.Hour))
+	require.NoError(t, ledger.Complete("req-2", "i-456"))
+
+	pending, err := ledger.GetPendingTasks()
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	assert.Equal(t, "req-1", pending[0].RequestID)
+
+	require.NoError(t, ledger.Release("req-1"))
+	require.NoError(t, ledger.Release("req-2"), "completed entries are kept")
+	pending, err = ledger.GetPendingTasks()
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+
+	rec, err := ledger.GetByChecksum("def")
+	require.NoError(t, err)
+	require.NotNil(t, rec)
+}
@@ -0,0 +1,149 @@
+package idempotency
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/Xover-Official/Xover/pkg/models"
+	_ "modernc.org/sqlite"
+)
+
+// SQLiteLedger keeps the ledger in a local SQLite file. It only guards the
+// processes sharing that file; use RedisLedger across workers.
+type SQLiteLedger struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+func NewSQLiteLedger(dbPath string) (*SQLiteLedger, error) {
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := `
+	CREATE TABLE IF NOT EXISTS idempotency_ledger (
+		request_id TEXT PRIMARY KEY,
+		checksum TEXT NOT NULL,
+		status TEXT NOT NULL,
+		resource_id TEXT,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		expires_at DATETIME
+	);
+	CREATE INDEX IF NOT EXISTS idx_checksum ON idempotency_ledger(checksum);
+	`
+	if _, err := db.Exec(schema); err != nil {
+		return nil, err
+	}
+
+	// Ledgers created before entries expired lack the column. Their existing
+	// entries keep a NULL expiry and never expire.
+	if _, err := db.Exec(`ALTER TABLE idempotency_ledger ADD COLUMN expires_at DATETIME`); err != nil &&
+		!strings.Contains(err.Error(), "duplicate column") {
+		return nil, err
+	}
+
+	return &SQLiteLedger{db: db, now: time.Now}, nil
+}
+
+// RecordPending inserts a pending entry that expires after ttl, unless the
+// checksum already has an unexpired entry
+func (l *SQLiteLedger) RecordPending(requestID, checksum string, ttl time.Duration) error {
+	now := l.now().UTC()
+	result, err := l.db.Exec(`
+		INSERT INTO idempotency_ledger (request_id, checksum, status, created_at, updated_at, expires_at)
+		SELECT ?, ?, ?, ?, ?, ?
+		WHERE NOT EXISTS (
+			SELECT 1 FROM idempotency_ledger
+			WHERE checksum = ? AND (expires_at IS NULL OR expires_at > ?)
+		)`,
+		requestID, checksum, models.StatusPending, now, now, now.Add(ttl), checksum, now)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrAlreadyRecorded
+	}
+	return err
+}
+
+func (l *SQLiteLedger) Complete(requestID, resourceID string) error {
+	_, err := l.db.Exec(`
+		UPDATE idempotency_ledger
+		SET status = ?, resource_id = ?, updated_at = ?
+		WHERE request_id = ?`,
+		models.StatusCompleted, resourceID, l.now().UTC(), requestID)
+	return err
+}
+
+func (l *SQLiteLedger) Release(requestID string) error {
+	_, err := l.db.Exec(`
+		DELETE FROM idempotency_ledger WHERE request_id = ? AND status = ?`,
+		requestID, models.StatusPending)
+	return err
+}
+
+// GetPendingTasks returns the unexpired pending entries
+func (l *SQLiteLedger) GetPendingTasks() ([]models.ActionRecord, error) {
+	rows, err := l.db.Query(`
+		SELECT request_id, checksum, status, COALESCE(resource_id, ''), created_at, updated_at, expires_at
+		FROM idempotency_ledger
+		WHERE status = ? AND (expires_at IS NULL OR expires_at > ?)`,
+		models.StatusPending, l.now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tasks []models.ActionRecord
+	for rows.Next() {
+		rec, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *rec)
+	}
+	return tasks, rows.Err()
+}
+
+// GetByChecksum returns the newest unexpired entry for a checksum, or nil if
+// there is none. Expired entries are treated as absent so the action can run
+// again.
+func (l *SQLiteLedger) GetByChecksum(checksum string) (*models.ActionRecord, error) {
+	row := l.db.QueryRow(`
+		SELECT request_id, checksum, status, COALESCE(resource_id, ''), created_at, updated_at, expires_at
+		FROM idempotency_ledger
+		WHERE checksum = ? AND (expires_at IS NULL OR expires_at > ?)
+		ORDER BY created_at DESC LIMIT 1`,
+		checksum, l.now().UTC())
+
+	rec, err := scanRecord(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return rec, err
+}
+
+// Cleanup deletes entries that expired more than olderThan ago and returns
+// how many were removed. Cleanup(0) purges every expired entry.
+func (l *SQLiteLedger) Cleanup(olderThan time.Duration) (int64, error) {
+	result, err := l.db.Exec(`
+		DELETE FROM idempotency_ledger WHERE expires_at IS NOT NULL AND expires_at <= ?`,
+		l.now().UTC().Add(-olderThan))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func scanRecord(row interface{ Scan(...any) error }) (*models.ActionRecord, error) {
+	var rec models.ActionRecord
+	var expiresAt sql.NullTime
+	if err := row.Scan(&rec.RequestID, &rec.Checksum, &rec.Status, &rec.ResourceID, &rec.CreatedAt, &rec.UpdatedAt, &expiresAt); err != nil {
+		return nil, err
+	}
+	rec.ExpiresAt = expiresAt.Time
+	return &rec, nil
+}
@@ -23,8 +23,18 @@ type OODALoop struct {
 	tokenTracker *analytics.TokenTracker
 	logger       *zap.Logger
 	stopChan     chan struct{}
+
+	mu     sync.Mutex
+	paused bool
+
+	// Replaced in tests; nil tick means a ticker every cycleInterval
+	tick  <-chan time.Time
+	cycle func() error
 }
 
+// cycleInterval is how often Start runs a cycle
+const cycleInterval = 5 * time.Minute
+
 // NewOODALoop creates a new OODA loop with zap logger
 func NewOODALoop(cfg *config.Config, ledger persistence.Ledger, orchestrator *ai.UnifiedOrchestrator, tracker *analytics.TokenTracker, l *zap.Logger) *OODALoop {
 	if l == nil {
@@ -44,20 +54,20 @@ func NewOODALoop(cfg *config.Config, ledger persistence.Ledger, orchestrator *ai
 func (o *OODALoop) Start() error {
 	o.logger.Info("🔄 OODA Loop started", zap.String("mode", o.config.Server.Mode))
 
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
+	tick := o.tick
+	if tick == nil {
+		ticker := time.NewTicker(cycleInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
 
 	// Run immediately on start
-	if err := o.runCycle(); err != nil {
-		o.logger.Error("Initial cycle error", zap.Error(err))
-	}
+	o.runUnlessPaused("Initial cycle error")
 
 	for {
 		select {
-		case <-ticker.C:
-			if err := o.runCycle(); err != nil {
-				o.logger.Error("Cycle error", zap.Error(err))
-			}
+		case <-tick:
+			o.runUnlessPaused("Cycle error")
 		case <-o.stopChan:
 			o.logger.Info("🛑 OODA Loop stopped")
 			return nil
@@ -70,6 +80,49 @@ func (o *OODALoop) Stop() {
 	close(o.stopChan)
 }
 
+// Pause makes the loop skip its cycles until Resume, e.g. during a deploy
+// freeze. The timer keeps running and a cycle already underway finishes.
+func (o *OODALoop) Pause() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if !o.paused {
+		o.paused = true
+		o.logger.Info("⏸️ OODA Loop paused")
+	}
+}
+
+// Resume lets the loop run cycles again from its next tick
+func (o *OODALoop) Resume() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.paused {
+		o.paused = false
+		o.logger.Info("▶️ OODA Loop resumed")
+	}
+}
+
+// IsPaused reports whether the loop is skipping its cycles
+func (o *OODALoop) IsPaused() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.paused
+}
+
+// runUnlessPaused runs one cycle, logging a failure with msg
+func (o *OODALoop) runUnlessPaused(msg string) {
+	if o.IsPaused() {
+		o.logger.Debug("OODA cycle skipped while paused")
+		return
+	}
+	cycle := o.cycle
+	if cycle == nil {
+		cycle = o.runCycle
+	}
+	if err := cycle(); err != nil {
+		o.logger.Error(msg, zap.Error(err))
+	}
+}
+
 // runCycle executes one complete OODA cycle
 func (o *OODALoop) runCycle() error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
@@ -97,11 +150,11 @@ func (o *OODALoop) runCycle() error {
 	o.logger.Info("⚡ ACT complete", zap.Int("applied", applied))
 
 	// Print cycle summary
-	stats := o.tokenTracker.GetBreakdown()
+	stats := o.tokenTracker.GetSnapshot()
 	o.logger.Info("✅ Cycle complete",
-		zap.Float64("total_cost", stats["total_cost"].(float64)),
-		zap.Float64("projected_savings", stats["projected_savings"].(float64)),
-		zap.Float64("roi", stats["roi"].(float64)),
+		zap.Float64("total_cost", stats.TotalCostUSD),
+		zap.Float64("projected_savings", stats.TotalSavingsUSD),
+		zap.Float64("roi", stats.NetROI),
 	)
 
 	return nil
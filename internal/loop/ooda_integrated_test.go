@@ -0,0 +1,92 @@
+package loop
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newTestLoop returns a loop driven by the returned tick channel that
+// counts its cycles and signals each one on ran
+func newTestLoop(t *testing.T) (*OODALoop, chan time.Time, *atomic.Int32, chan struct{}) {
+	t.Helper()
+	o := NewOODALoop(&config.Config{}, nil, nil, nil, zap.NewNop())
+	tick := make(chan time.Time)
+	ran := make(chan struct{}, 10)
+	var cycles atomic.Int32
+	o.tick = tick
+	o.cycle = func() error {
+		cycles.Add(1)
+		ran <- struct{}{}
+		return nil
+	}
+	return o, tick, &cycles, ran
+}
+
+func waitForCycle(t *testing.T, ran chan struct{}) {
+	t.Helper()
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no cycle ran")
+	}
+}
+
+func TestOODALoop_PauseSkipsCyclesUntilResume(t *testing.T) {
+	o, tick, cycles, ran := newTestLoop(t)
+	done := make(chan error, 1)
+	go func() { done <- o.Start() }()
+	waitForCycle(t, ran) // the initial cycle
+
+	o.Pause()
+	assert.True(t, o.IsPaused())
+	// The loop takes one tick at a time, so the second send returns only
+	// after the first tick was handled
+	tick <- time.Now()
+	tick <- time.Now()
+	assert.Equal(t, int32(1), cycles.Load(), "no cycle runs while paused")
+
+	o.Resume()
+	assert.False(t, o.IsPaused())
+	tick <- time.Now()
+	waitForCycle(t, ran)
+	assert.Equal(t, int32(2), cycles.Load())
+
+	o.Stop()
+	require.NoError(t, <-done)
+}
+
+func TestOODALoop_PausedBeforeStartSkipsInitialCycle(t *testing.T) {
+	o, tick, cycles, ran := newTestLoop(t)
+	o.Pause()
+	done := make(chan error, 1)
+	go func() { done <- o.Start() }()
+
+	tick <- time.Now()
+	tick <- time.Now()
+	assert.Zero(t, cycles.Load())
+
+	o.Resume()
+	tick <- time.Now()
+	waitForCycle(t, ran)
+
+	o.Stop()
+	require.NoError(t, <-done)
+}
+
+func TestOODALoop_PauseAndResumeAreIdempotent(t *testing.T) {
+	o, _, _, _ := newTestLoop(t)
+
+	o.Resume()
+	assert.False(t, o.IsPaused())
+	o.Pause()
+	o.Pause()
+	assert.True(t, o.IsPaused())
+	o.Resume()
+	assert.False(t, o.IsPaused())
+}
@@ -2,21 +2,28 @@ package manager
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/Xover-Official/Xover/internal/ai"
 	"github.com/Xover-Official/Xover/internal/analytics"
+	"github.com/Xover-Official/Xover/internal/cache"
 	"github.com/Xover-Official/Xover/internal/config"
 	"github.com/Xover-Official/Xover/internal/persistence"
+	"github.com/Xover-Official/Xover/internal/taskstate"
 )
 
 // Task represents a distributed work item
@@ -30,10 +37,26 @@ type Task struct {
 	MaxAttempts int                    `json:"max_attempts"`
 }
 
+// DeadTask is a task a worker gave up on after MaxAttempts, as recorded in
+// the tasks:dead list
+type DeadTask struct {
+	Task
+	Error    string    `json:"error"`
+	WorkerID string    `json:"worker_id"`
+	FailedAt time.Time `json:"failed_at"`
+	Raw      string    `json:"raw,omitempty"` // set when the task could not be decoded
+}
+
+// deadLetterQueue holds tasks workers gave up on, newest first
+const deadLetterQueue = "tasks:dead"
+
+// maxDeadTasks caps how many dead tasks one request returns
+const maxDeadTasks = 1000
+
 // EnterpriseManager manages the distributed Talos system
 type EnterpriseManager struct {
 	id           string
-	redis        *redis.Client
+	redis        *resilientRedis
 	db           persistence.Ledger
 	orchestrator *ai.UnifiedOrchestrator
 	tokenTracker *analytics.TokenTracker
@@ -47,26 +70,52 @@ type EnterpriseManager struct {
 	shutdownChan chan struct{}
 }
 
-// NewEnterpriseManager creates a new enterprise manager
+// redisCheckInterval is how often the manager pings Redis to detect recovery
+const redisCheckInterval = 5 * time.Second
+
+// scanInterval is how often periodic scans are scheduled. Scheduled task IDs
+// are derived from the interval bucket, so every replica computes the same ID.
+const scanInterval = 5 * time.Minute
+
+// taskDedupTTL is how long an enqueued task ID is remembered; a task with
+// the same ID enqueued within this window is rejected as a duplicate
+const taskDedupTTL = 2 * scanInterval
+
+// ErrDuplicateTask is returned when a task with the same ID was enqueued recently
+var ErrDuplicateTask = errors.New("task already enqueued")
+
+// NewEnterpriseManager creates a new enterprise manager. An unreachable Redis
+// is not fatal: the manager starts degraded, buffers tasks locally and
+// reconnects in the background.
 func NewEnterpriseManager(cfg *config.Config, db persistence.Ledger, orchestrator *ai.UnifiedOrchestrator, tracker *analytics.TokenTracker) (*EnterpriseManager, error) {
 	// Connect to Redis
 	rdb := redis.NewClient(&redis.Options{
-		Addr:     cfg.Redis.Address,
-		Password: cfg.Redis.Password,
-		DB:       cfg.Redis.DB,
+		Addr:         cfg.Redis.Address,
+		Password:     cfg.Redis.Password,
+		DB:           cfg.Redis.DB,
+		MaxRetries:   cfg.Redis.MaxRetries,
+		PoolSize:     cfg.Redis.PoolSize,
+		MinIdleConns: cfg.Redis.MinIdleConns,
+		DialTimeout:  cfg.Redis.DialTimeout,
+		ReadTimeout:  cfg.Redis.ReadTimeout,
+		WriteTimeout: cfg.Redis.WriteTimeout,
 	})
+	breaker := cache.NewBreaker(cfg.Redis.BreakerThreshold, cfg.Redis.BreakerCooldown)
+	client := newResilientRedis(rdb, breaker, cfg.Redis.FallbackQueueSize)
 
 	// Test Redis connection
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := rdb.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+		log.Printf("⚠️  Redis unavailable, starting in degraded mode: %v", err)
+		client.record(err)
+		breaker.Trip()
 	}
 
 	manager := &EnterpriseManager{
 		id:           fmt.Sprintf("manager-%d", time.Now().Unix()),
-		redis:        rdb,
+		redis:        client,
 		db:           db,
 		orchestrator: orchestrator,
 		tokenTracker: tracker,
@@ -88,6 +137,7 @@ func (m *EnterpriseManager) Start(ctx context.Context) error {
 	}
 
 	// Start background tasks
+	go m.redis.Monitor(ctx, redisCheckInterval, m.shutdownChan)
 	go m.taskScheduler(ctx)
 	go m.workerMonitor(ctx)
 	go m.metricsCollector(ctx)
@@ -126,6 +176,13 @@ func (m *EnterpriseManager) Shutdown() error {
 		log.Printf("⚠️  Error shutting down server: %v", err)
 	}
 
+	// Give buffered tasks a last chance to reach Redis
+	if buffered := m.redis.Buffered(); buffered > 0 {
+		if drained, err := m.redis.Drain(ctx); err != nil {
+			log.Printf("⚠️  Lost %d buffered tasks, Redis unavailable: %v", buffered-drained, err)
+		}
+	}
+
 	// Close Redis connection
 	if err := m.redis.Close(); err != nil {
 		log.Printf("⚠️  Error closing Redis: %v", err)
@@ -137,6 +194,23 @@ func (m *EnterpriseManager) Shutdown() error {
 
 // startAPIServer starts the HTTP API server
 func (m *EnterpriseManager) startAPIServer() error {
+	m.server = &http.Server{
+		Addr:    ":8080",
+		Handler: m.routes(),
+	}
+
+	log.Println("🌐 API server starting on :8080")
+	go func() {
+		if err := m.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("❌ Server error: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+// routes builds the API router
+func (m *EnterpriseManager) routes() http.Handler {
 	router := mux.NewRouter()
 
 	// API routes
@@ -145,6 +219,7 @@ func (m *EnterpriseManager) startAPIServer() error {
 	// Task management
 	api.HandleFunc("/tasks", m.createTaskHandler).Methods("POST")
 	api.HandleFunc("/tasks", m.listTasksHandler).Methods("GET")
+	api.HandleFunc("/tasks/dead", m.deadTasksHandler).Methods("GET") // before {id} matches "dead"
 	api.HandleFunc("/tasks/{id}", m.getTaskHandler).Methods("GET")
 
 	// Worker management
@@ -157,24 +232,12 @@ func (m *EnterpriseManager) startAPIServer() error {
 	// Health check
 	router.HandleFunc("/health", m.healthHandler).Methods("GET")
 
-	m.server = &http.Server{
-		Addr:    ":8080",
-		Handler: router,
-	}
-
-	log.Println("🌐 API server starting on :8080")
-	go func() {
-		if err := m.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("❌ Server error: %v", err)
-		}
-	}()
-
-	return nil
+	return router
 }
 
 // taskScheduler schedules periodic tasks
 func (m *EnterpriseManager) taskScheduler(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Minute)
+	ticker := time.NewTicker(scanInterval)
 	defer ticker.Stop()
 
 	for {
@@ -196,11 +259,12 @@ func (m *EnterpriseManager) scheduleScanTasks(ctx context.Context) {
 	// Create scan tasks for different providers
 	providers := []string{"aws", "azure", "gcp"}
 	regions := []string{"us-east-1", "us-west-2", "eu-west-1"}
+	bucket := time.Now().Truncate(scanInterval)
 
 	for _, provider := range providers {
 		for _, region := range regions {
 			task := Task{
-				ID:       fmt.Sprintf("scan-%s-%s-%d", provider, region, time.Now().Unix()),
+				ID:       scanTaskID(provider, region, bucket),
 				Type:     "scan",
 				Priority: 3,
 				Payload: map[string]interface{}{
@@ -213,26 +277,73 @@ func (m *EnterpriseManager) scheduleScanTasks(ctx context.Context) {
 				MaxAttempts: 3,
 			}
 
-			if err := m.enqueueTask(ctx, task); err != nil {
+			err := m.enqueueTask(ctx, task)
+			if errors.Is(err, ErrDuplicateTask) {
+				log.Printf("⏭️  Scan %s/%s already scheduled for this interval", provider, region)
+			} else if err != nil {
 				log.Printf("⚠️  Failed to enqueue scan task: %v", err)
 			}
 		}
 	}
 }
 
-// enqueueTask adds a task to the Redis queue
+// scanTaskID derives a deterministic ID for the scan of provider/region in
+// the interval starting at bucket
+func scanTaskID(provider, region string, bucket time.Time) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%d", provider, region, bucket.Unix())))
+	return "scan-" + hex.EncodeToString(sum[:8])
+}
+
+// enqueueTask adds a task to the Redis queue and records its state for the
+// task API. A SET NX guard on the task ID rejects the same logical task with
+// ErrDuplicateTask within taskDedupTTL. A task buffered while Redis is down
+// has no recorded state.
 func (m *EnterpriseManager) enqueueTask(ctx context.Context, task Task) error {
 	taskData, err := json.Marshal(task)
 	if err != nil {
 		return fmt.Errorf("failed to marshal task: %w", err)
 	}
 
+	dedupKey := "tasks:dedup:" + task.ID
+	if !m.redis.Claim(ctx, dedupKey, taskDedupTTL) {
+		return fmt.Errorf("%w: %s", ErrDuplicateTask, task.ID)
+	}
+
 	queue := "tasks:normal"
 	if task.Priority > 5 {
 		queue = "tasks:high_priority"
 	}
 
-	return m.redis.LPush(ctx, queue, taskData).Err()
+	// Recorded before the push so a worker's first update finds it
+	state := taskstate.State{
+		ID:          task.ID,
+		Type:        task.Type,
+		Priority:    task.Priority,
+		Payload:     task.Payload,
+		Status:      taskstate.StatusQueued,
+		Attempts:    task.Attempts,
+		MaxAttempts: task.MaxAttempts,
+		CreatedAt:   task.CreatedAt,
+	}
+	recorded := m.redis.Do(ctx, func(ctx context.Context, client *redis.Client) error {
+		return taskstate.Create(ctx, client, state)
+	}) == nil
+
+	buffered, err := m.redis.Enqueue(ctx, queue, taskData)
+	if err != nil {
+		// Let a retry of the same task through
+		m.redis.Release(ctx, dedupKey)
+		if recorded {
+			m.redis.Do(ctx, func(ctx context.Context, client *redis.Client) error {
+				return taskstate.Delete(ctx, client, task.ID)
+			})
+		}
+		return err
+	}
+	if buffered {
+		log.Printf("📥 Redis unavailable, buffered task %s locally", task.ID)
+	}
+	return nil
 }
 
 // workerMonitor monitors active workers
@@ -254,7 +365,11 @@ func (m *EnterpriseManager) workerMonitor(ctx context.Context) {
 
 // checkWorkerHealth verifies worker status
 func (m *EnterpriseManager) checkWorkerHealth(ctx context.Context) {
-	workers, err := m.redis.SMembers(ctx, "workers:active").Result()
+	var workers []string
+	err := m.redis.Do(ctx, func(ctx context.Context, client *redis.Client) (err error) {
+		workers, err = client.SMembers(ctx, "workers:active").Result()
+		return err
+	})
 	if err != nil {
 		log.Printf("⚠️  Failed to get active workers: %v", err)
 		return
@@ -264,10 +379,16 @@ func (m *EnterpriseManager) checkWorkerHealth(ctx context.Context) {
 
 	for _, workerID := range workers {
 		key := fmt.Sprintf("workers:%s", workerID)
-		data, err := m.redis.Get(ctx, key).Result()
+		var data string
+		err := m.redis.Do(ctx, func(ctx context.Context, client *redis.Client) (err error) {
+			data, err = client.Get(ctx, key).Result()
+			return err
+		})
 		if err == redis.Nil {
 			// Worker heartbeat expired, remove from active set
-			m.redis.SRem(ctx, "workers:active", workerID)
+			m.redis.Do(ctx, func(ctx context.Context, client *redis.Client) error {
+				return client.SRem(ctx, "workers:active", workerID).Err()
+			})
 			log.Printf("⚠️  Worker %s heartbeat expired", workerID)
 		} else if err != nil {
 			log.Printf("⚠️  Error checking worker %s: %v", workerID, err)
@@ -300,46 +421,75 @@ func (m *EnterpriseManager) metricsCollector(ctx context.Context) {
 
 // collectMetrics gathers system metrics
 func (m *EnterpriseManager) collectMetrics(ctx context.Context) {
-	// Get worker count
-	workers, _ := m.redis.SMembers(ctx, "workers:active").Result()
-	workerCount := len(workers)
+	var workerCount int
+	var highPriorityQueue, normalQueue, deadQueue int64
+	redisErr := m.redis.Do(ctx, func(ctx context.Context, client *redis.Client) error {
+		// Get worker count
+		workers, err := client.SMembers(ctx, "workers:active").Result()
+		if err != nil {
+			return err
+		}
+		workerCount = len(workers)
 
-	// Get queue sizes
-	highPriorityQueue, _ := m.redis.LLen(ctx, "tasks:high_priority").Result()
-	normalQueue, _ := m.redis.LLen(ctx, "tasks:normal").Result()
+		// Get queue sizes
+		highPriorityQueue, _ = client.LLen(ctx, "tasks:high_priority").Result()
+		normalQueue, _ = client.LLen(ctx, "tasks:normal").Result()
+		deadQueue, _ = client.LLen(ctx, deadLetterQueue).Result()
+		return nil
+	})
 
 	// Get token tracker stats
-	stats := m.tokenTracker.GetStats()
+	stats := m.tokenTracker.GetSnapshot()
 
 	metrics := map[string]interface{}{
 		"timestamp":           time.Now().Unix(),
 		"worker_count":        workerCount,
 		"high_priority_queue": highPriorityQueue,
 		"normal_queue":        normalQueue,
-		"total_tokens":        stats["total_tokens"],
-		"total_cost":          stats["total_cost_usd"],
-		"total_savings":       stats["total_savings_usd"],
+		"dead_queue":          deadQueue,
+		"total_tokens":        stats.TotalTokens,
+		"total_cost":          stats.TotalCostUSD,
+		"total_savings":       stats.TotalSavingsUSD,
+	}
+
+	if redisErr != nil {
+		log.Printf("⚠️  Redis unavailable, skipping metrics collection: %v", redisErr)
+		return
 	}
 
 	// Store metrics in Redis
 	metricsData, _ := json.Marshal(metrics)
-	m.redis.LPush(ctx, "metrics:timeline", metricsData)
-	m.redis.LTrim(ctx, "metrics:timeline", 0, 1000) // Keep last 1000 data points
+	m.redis.Do(ctx, func(ctx context.Context, client *redis.Client) error {
+		pipe := client.TxPipeline()
+		pipe.LPush(ctx, "metrics:timeline", metricsData)
+		pipe.LTrim(ctx, "metrics:timeline", 0, 1000) // Keep last 1000 data points
+		_, err := pipe.Exec(ctx)
+		return err
+	})
 
-	log.Printf("📈 Metrics: workers=%d, queues=%d/%d, cost=$%.2f, savings=$%.2f",
-		workerCount, highPriorityQueue, normalQueue,
-		stats["total_cost_usd"], stats["total_savings_usd"])
+	log.Printf("📈 Metrics: workers=%d, queues=%d/%d, dead=%d, cost=$%.2f, savings=$%.2f",
+		workerCount, highPriorityQueue, normalQueue, deadQueue,
+		stats.TotalCostUSD, stats.TotalSavingsUSD)
 }
 
 // HTTP Handlers
 
+// healthHandler reports "degraded" rather than failing while Redis is down,
+// since tasks are still accepted and buffered locally
 func (m *EnterpriseManager) healthHandler(w http.ResponseWriter, r *http.Request) {
+	redisHealth := m.redis.Health()
+	status := "healthy"
+	if redisHealth.Status != RedisStatusUp {
+		status = "degraded"
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(map[string]string{
-		"status":     "healthy",
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":     status,
 		"manager_id": m.id,
 		"version":    "2.0.0",
+		"redis":      redisHealth,
 	})
 }
 
@@ -350,12 +500,18 @@ func (m *EnterpriseManager) createTaskHandler(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	task.ID = fmt.Sprintf("task-%d", time.Now().Unix())
+	// A client-provided ID doubles as an idempotency key for retries
+	if task.ID == "" {
+		task.ID = uuid.NewString()
+	}
 	task.CreatedAt = time.Now()
 	task.Attempts = 0
 	task.MaxAttempts = 3
 
-	if err := m.enqueueTask(r.Context(), task); err != nil {
+	if err := m.enqueueTask(r.Context(), task); errors.Is(err, ErrDuplicateTask) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	} else if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -365,22 +521,115 @@ func (m *EnterpriseManager) createTaskHandler(w http.ResponseWriter, r *http.Req
 	json.NewEncoder(w).Encode(task)
 }
 
+// listTasksHandler pages through recorded tasks, newest first.
+// ?status= filters by status; ?limit= (default 50) and ?offset= page.
 func (m *EnterpriseManager) listTasksHandler(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement task listing from Redis
+	query := r.URL.Query()
+	opts := taskstate.ListOptions{Status: taskstate.Status(query.Get("status")), Limit: 50}
+	if opts.Status != "" && !opts.Status.Valid() {
+		http.Error(w, fmt.Sprintf("unknown status %q", opts.Status), http.StatusBadRequest)
+		return
+	}
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || limit < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		opts.Limit = min(limit, taskstate.MaxPageSize)
+	}
+	if value := query.Get("offset"); value != "" {
+		offset, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || offset < 0 {
+			http.Error(w, "offset must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		opts.Offset = offset
+	}
+
+	var page taskstate.Page
+	err := m.redis.Do(r.Context(), func(ctx context.Context, client *redis.Client) (err error) {
+		page, err = taskstate.List(ctx, client, opts)
+		return err
+	})
+	if err != nil {
+		http.Error(w, err.Error(), redisErrorStatus(err))
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode([]Task{})
+	json.NewEncoder(w).Encode(page)
 }
 
 func (m *EnterpriseManager) getTaskHandler(w http.ResponseWriter, r *http.Request) {
-	// TODO: Implement task retrieval
+	var state taskstate.State
+	found := true
+	err := m.redis.Do(r.Context(), func(ctx context.Context, client *redis.Client) (err error) {
+		state, err = taskstate.Get(ctx, client, mux.Vars(r)["id"])
+		if errors.Is(err, taskstate.ErrNotFound) {
+			// Not a Redis failure, so it must not count against the breaker
+			found = false
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		http.Error(w, err.Error(), redisErrorStatus(err))
+		return
+	}
+	if !found {
+		http.Error(w, "Task not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+}
+
+// deadTasksHandler lists the dead-letter queue, newest first. ?limit= caps
+// the count, 100 by default.
+func (m *EnterpriseManager) deadTasksHandler(w http.ResponseWriter, r *http.Request) {
+	limit := 100
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, maxDeadTasks)
+	}
+
+	var entries []string
+	err := m.redis.Do(r.Context(), func(ctx context.Context, client *redis.Client) (err error) {
+		entries, err = client.LRange(ctx, deadLetterQueue, 0, int64(limit-1)).Result()
+		return err
+	})
+	if err != nil {
+		http.Error(w, err.Error(), redisErrorStatus(err))
+		return
+	}
+
+	dead := make([]DeadTask, 0, len(entries))
+	for _, entry := range entries {
+		var task DeadTask
+		if err := json.Unmarshal([]byte(entry), &task); err != nil {
+			task = DeadTask{Error: fmt.Sprintf("failed to decode dead task: %v", err), Raw: entry}
+		}
+		dead = append(dead, task)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]string{"id": mux.Vars(r)["id"]})
+	json.NewEncoder(w).Encode(dead)
 }
 
 func (m *EnterpriseManager) listWorkersHandler(w http.ResponseWriter, r *http.Request) {
-	workers, err := m.redis.SMembers(r.Context(), "workers:active").Result()
+	var workers []string
+	err := m.redis.Do(r.Context(), func(ctx context.Context, client *redis.Client) (err error) {
+		workers, err = client.SMembers(ctx, "workers:active").Result()
+		return err
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), redisErrorStatus(err))
 		return
 	}
 
@@ -392,12 +641,16 @@ func (m *EnterpriseManager) getWorkerHandler(w http.ResponseWriter, r *http.Requ
 	workerID := mux.Vars(r)["id"]
 	key := fmt.Sprintf("workers:%s", workerID)
 
-	data, err := m.redis.Get(r.Context(), key).Result()
+	var data string
+	err := m.redis.Do(r.Context(), func(ctx context.Context, client *redis.Client) (err error) {
+		data, err = client.Get(ctx, key).Result()
+		return err
+	})
 	if err == redis.Nil {
 		http.Error(w, "Worker not found", http.StatusNotFound)
 		return
 	} else if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), redisErrorStatus(err))
 		return
 	}
 
@@ -406,12 +659,25 @@ func (m *EnterpriseManager) getWorkerHandler(w http.ResponseWriter, r *http.Requ
 }
 
 func (m *EnterpriseManager) metricsHandler(w http.ResponseWriter, r *http.Request) {
-	metrics, err := m.redis.LRange(r.Context(), "metrics:timeline", 0, 100).Result()
+	var metrics []string
+	err := m.redis.Do(r.Context(), func(ctx context.Context, client *redis.Client) (err error) {
+		metrics, err = client.LRange(ctx, "metrics:timeline", 0, 100).Result()
+		return err
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), redisErrorStatus(err))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(metrics)
 }
+
+// redisErrorStatus maps a Redis error to an HTTP status; an open breaker
+// means the dependency is unavailable rather than an internal failure
+func redisErrorStatus(err error) int {
+	if errors.Is(err, cache.ErrCircuitOpen) {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,199 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xover-Official/Xover/internal/cache"
+	"github.com/Xover-Official/Xover/internal/taskstate"
+)
+
+func newTestManager(t *testing.T) (*EnterpriseManager, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return &EnterpriseManager{
+		id:           "manager-test",
+		redis:        newResilientRedis(client, cache.NewBreaker(5, time.Minute), 0),
+		shutdownChan: make(chan struct{}),
+	}, server
+}
+
+func getDeadTasks(t *testing.T, m *EnterpriseManager, query string) (*httptest.ResponseRecorder, []DeadTask) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	m.routes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/v1/tasks/dead"+query, nil))
+	var dead []DeadTask
+	if recorder.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &dead))
+	}
+	return recorder, dead
+}
+
+func TestDeadTasksHandler(t *testing.T) {
+	m, server := newTestManager(t)
+
+	// Workers push the newest on the left
+	older, err := json.Marshal(DeadTask{Task: Task{ID: "task-1", Attempts: 3, MaxAttempts: 3}, Error: "boom", WorkerID: "worker-1"})
+	require.NoError(t, err)
+	newer, err := json.Marshal(DeadTask{Task: Task{ID: "task-2"}, Error: "bang", WorkerID: "worker-2"})
+	require.NoError(t, err)
+	_, err = server.Lpush(deadLetterQueue, string(older))
+	require.NoError(t, err)
+	_, err = server.Lpush(deadLetterQueue, string(newer))
+	require.NoError(t, err)
+
+	recorder, dead := getDeadTasks(t, m, "")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Len(t, dead, 2)
+	assert.Equal(t, "task-2", dead[0].ID)
+	assert.Equal(t, "task-1", dead[1].ID)
+	assert.Equal(t, "boom", dead[1].Error)
+	assert.Equal(t, "worker-1", dead[1].WorkerID)
+	assert.Equal(t, 3, dead[1].Attempts)
+
+	_, dead = getDeadTasks(t, m, "?limit=1")
+	require.Len(t, dead, 1)
+	assert.Equal(t, "task-2", dead[0].ID)
+}
+
+func TestDeadTasksHandler_Empty(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	recorder, dead := getDeadTasks(t, m, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, "[]", recorder.Body.String())
+	assert.Empty(t, dead)
+}
+
+func TestDeadTasksHandler_KeepsUndecodableEntries(t *testing.T) {
+	m, server := newTestManager(t)
+	_, err := server.Lpush(deadLetterQueue, "{not json")
+	require.NoError(t, err)
+
+	_, dead := getDeadTasks(t, m, "")
+	require.Len(t, dead, 1)
+	assert.Equal(t, "{not json", dead[0].Raw)
+	assert.NotEmpty(t, dead[0].Error)
+}
+
+func TestDeadTasksHandler_RejectsBadLimit(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	for _, query := range []string{"?limit=0", "?limit=-1", "?limit=many"} {
+		recorder, _ := getDeadTasks(t, m, query)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+}
+
+func TestDeadTasksHandler_RedisDown(t *testing.T) {
+	m, server := newTestManager(t)
+	server.Close()
+
+	recorder, _ := getDeadTasks(t, m, "")
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func getJSON(t *testing.T, m *EnterpriseManager, target string, into interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	m.routes().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, target, nil))
+	if recorder.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), into))
+	}
+	return recorder
+}
+
+// enqueueTasks enqueues count scan tasks a second apart, task-0 oldest
+func enqueueTasks(t *testing.T, m *EnterpriseManager, count int) {
+	t.Helper()
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < count; i++ {
+		require.NoError(t, m.enqueueTask(context.Background(), Task{
+			ID:          fmt.Sprintf("task-%d", i),
+			Type:        "scan",
+			CreatedAt:   start.Add(time.Duration(i) * time.Second),
+			MaxAttempts: 3,
+		}))
+	}
+}
+
+func TestListTasksHandler_Pages(t *testing.T) {
+	m, _ := newTestManager(t)
+	enqueueTasks(t, m, 5)
+
+	var seen []string
+	for offset := 0; offset < 6; offset += 2 {
+		var page taskstate.Page
+		recorder := getJSON(t, m, fmt.Sprintf("/api/v1/tasks?limit=2&offset=%d", offset), &page)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		assert.Equal(t, int64(5), page.Total)
+		assert.Equal(t, int64(2), page.Limit)
+		assert.Equal(t, int64(offset), page.Offset)
+		for _, task := range page.Tasks {
+			seen = append(seen, task.ID)
+		}
+	}
+	assert.Equal(t, []string{"task-4", "task-3", "task-2", "task-1", "task-0"}, seen)
+}
+
+func TestListTasksHandler_FiltersByStatus(t *testing.T) {
+	m, _ := newTestManager(t)
+	enqueueTasks(t, m, 3)
+	m.redis.Do(context.Background(), func(ctx context.Context, client *redis.Client) error {
+		return taskstate.Apply(ctx, client, "task-1", taskstate.Update{Status: taskstate.StatusCompleted, Attempts: 0, WorkerID: "worker-1", Result: "done"})
+	})
+
+	var page taskstate.Page
+	recorder := getJSON(t, m, "/api/v1/tasks?status=completed", &page)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, "task-1", page.Tasks[0].ID)
+	assert.Equal(t, "done", page.Tasks[0].Result)
+
+	recorder = getJSON(t, m, "/api/v1/tasks?status=queued", &page)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, int64(2), page.Total)
+}
+
+func TestListTasksHandler_RejectsBadQuery(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	for _, query := range []string{"?status=lost", "?limit=0", "?limit=ten", "?offset=-1"} {
+		var page taskstate.Page
+		recorder := getJSON(t, m, "/api/v1/tasks"+query, &page)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, query)
+	}
+}
+
+func TestGetTaskHandler(t *testing.T) {
+	m, _ := newTestManager(t)
+	enqueueTasks(t, m, 1)
+
+	var state taskstate.State
+	recorder := getJSON(t, m, "/api/v1/tasks/task-0", &state)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "task-0", state.ID)
+	assert.Equal(t, "scan", state.Type)
+	assert.Equal(t, taskstate.StatusQueued, state.Status)
+	assert.Equal(t, 3, state.MaxAttempts)
+}
+
+func TestGetTaskHandler_NotFound(t *testing.T) {
+	m, _ := newTestManager(t)
+
+	recorder := getJSON(t, m, "/api/v1/tasks/missing", &taskstate.State{})
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, RedisStatusUp, m.redis.Health().Status, "a missing task is not a Redis failure")
+}
@@ -0,0 +1,257 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/cache"
+	"github.com/go-redis/redis/v8"
+)
+
+// Redis health statuses reported by /health
+const (
+	RedisStatusUp       = "up"
+	RedisStatusDegraded = "degraded" // reachable again, buffered tasks still draining
+	RedisStatusDown     = "down"
+)
+
+// RedisHealth describes the manager's Redis connection
+type RedisHealth struct {
+	Status        string             `json:"status"`
+	Breaker       cache.BreakerState `json:"breaker"`
+	BufferedTasks int                `json:"buffered_tasks"`
+	DroppedTasks  int64              `json:"dropped_tasks"`
+	LastError     string             `json:"last_error,omitempty"`
+	LastSuccess   *time.Time         `json:"last_success,omitempty"`
+}
+
+// bufferedTask is a queue push waiting for Redis to come back
+type bufferedTask struct {
+	queue string
+	data  []byte
+}
+
+// resilientRedis guards Redis calls with a circuit breaker and buffers task
+// pushes in memory while Redis is unavailable, draining them once it recovers
+type resilientRedis struct {
+	client      *redis.Client
+	breaker     *cache.Breaker
+	maxBuffered int
+
+	mu          sync.Mutex
+	buffer      []bufferedTask
+	dropped     int64
+	lastErr     error
+	lastSuccess time.Time
+	localClaims map[string]time.Time // claim key to expiry, used while Redis is down
+}
+
+func newResilientRedis(client *redis.Client, breaker *cache.Breaker, maxBuffered int) *resilientRedis {
+	if maxBuffered <= 0 {
+		maxBuffered = 1000
+	}
+	return &resilientRedis{
+		client:      client,
+		breaker:     breaker,
+		maxBuffered: maxBuffered,
+		localClaims: make(map[string]time.Time),
+	}
+}
+
+// Do runs fn against Redis unless the breaker is open. redis.Nil is a
+// normal result, not a failure.
+func (r *resilientRedis) Do(ctx context.Context, fn func(ctx context.Context, client *redis.Client) error) error {
+	if !r.breaker.Allow() {
+		return cache.ErrCircuitOpen
+	}
+	err := fn(ctx, r.client)
+	r.record(err)
+	return err
+}
+
+// record feeds a call's outcome into the breaker
+func (r *resilientRedis) record(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err == nil || errors.Is(err, redis.Nil) {
+		r.breaker.Success()
+		r.lastSuccess = time.Now()
+		return
+	}
+	r.breaker.Failure()
+	r.lastErr = err
+}
+
+// Enqueue pushes a task onto a queue. While Redis is unavailable, or while
+// earlier tasks are still buffered, the task is buffered locally so queue
+// order is kept; buffered reports whether that happened.
+func (r *resilientRedis) Enqueue(ctx context.Context, queue string, data []byte) (buffered bool, err error) {
+	if r.Buffered() == 0 {
+		err = r.Do(ctx, func(ctx context.Context, client *redis.Client) error {
+			return client.LPush(ctx, queue, data).Err()
+		})
+		if err == nil {
+			return false, nil
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.buffer) >= r.maxBuffered {
+		r.buffer = r.buffer[1:]
+		r.dropped++
+		log.Printf("⚠️  Redis fallback queue full, dropped oldest task")
+	}
+	r.buffer = append(r.buffer, bufferedTask{queue: queue, data: data})
+	return true, nil
+}
+
+// Claim sets key with SET NX so that only the first caller within ttl wins,
+// across all replicas. While Redis is unavailable claims are tracked locally,
+// which still stops this replica from claiming the same key twice.
+func (r *resilientRedis) Claim(ctx context.Context, key string, ttl time.Duration) bool {
+	var claimed bool
+	err := r.Do(ctx, func(ctx context.Context, client *redis.Client) (err error) {
+		claimed, err = client.SetNX(ctx, key, time.Now().Unix(), ttl).Result()
+		return err
+	})
+	if err == nil {
+		return claimed
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for k, expiry := range r.localClaims {
+		if now.After(expiry) {
+			delete(r.localClaims, k)
+		}
+	}
+	if _, exists := r.localClaims[key]; exists {
+		return false
+	}
+	r.localClaims[key] = now.Add(ttl)
+	return true
+}
+
+// Release drops a claim so the key can be claimed again
+func (r *resilientRedis) Release(ctx context.Context, key string) {
+	r.mu.Lock()
+	delete(r.localClaims, key)
+	r.mu.Unlock()
+
+	r.Do(ctx, func(ctx context.Context, client *redis.Client) error {
+		return client.Del(ctx, key).Err()
+	})
+}
+
+// Buffered returns the number of tasks waiting for Redis
+func (r *resilientRedis) Buffered() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.buffer)
+}
+
+// Drain pushes buffered tasks to Redis in order, stopping at the first failure
+func (r *resilientRedis) Drain(ctx context.Context) (int, error) {
+	drained := 0
+	for {
+		r.mu.Lock()
+		if len(r.buffer) == 0 {
+			r.mu.Unlock()
+			return drained, nil
+		}
+		task := r.buffer[0]
+		r.mu.Unlock()
+
+		err := r.Do(ctx, func(ctx context.Context, client *redis.Client) error {
+			return client.LPush(ctx, task.queue, task.data).Err()
+		})
+		if err != nil {
+			return drained, err
+		}
+
+		r.mu.Lock()
+		r.buffer = r.buffer[1:]
+		r.mu.Unlock()
+		drained++
+	}
+}
+
+// Monitor pings Redis every interval so the breaker recovers without
+// traffic, and drains buffered tasks once Redis is reachable
+func (r *resilientRedis) Monitor(ctx context.Context, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-stop:
+			return
+		case <-ticker.C:
+			r.check(ctx)
+		}
+	}
+}
+
+// check pings Redis and drains the fallback queue when it is reachable
+func (r *resilientRedis) check(ctx context.Context) {
+	wasDown := r.breaker.State() != cache.BreakerClosed
+	err := r.Do(ctx, func(ctx context.Context, client *redis.Client) error {
+		return client.Ping(ctx).Err()
+	})
+	if err != nil {
+		return
+	}
+	if wasDown {
+		log.Println("✅ Redis connection restored")
+	}
+
+	if r.Buffered() > 0 {
+		drained, err := r.Drain(ctx)
+		if err != nil {
+			log.Printf("⚠️  Redis drain interrupted after %d tasks: %v", drained, err)
+			return
+		}
+		log.Printf("📤 Drained %d buffered tasks to Redis", drained)
+	}
+}
+
+// Health reports the connection state for /health
+func (r *resilientRedis) Health() RedisHealth {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	health := RedisHealth{
+		Status:        RedisStatusUp,
+		Breaker:       r.breaker.State(),
+		BufferedTasks: len(r.buffer),
+		DroppedTasks:  r.dropped,
+	}
+	if health.Breaker != cache.BreakerClosed {
+		health.Status = RedisStatusDown
+	} else if len(r.buffer) > 0 {
+		health.Status = RedisStatusDegraded
+	}
+	if r.lastErr != nil {
+		health.LastError = r.lastErr.Error()
+	}
+	if !r.lastSuccess.IsZero() {
+		lastSuccess := r.lastSuccess
+		health.LastSuccess = &lastSuccess
+	}
+	return health
+}
+
+// Close closes the underlying client
+func (r *resilientRedis) Close() error {
+	return r.client.Close()
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceIDLabel is the exemplar label Grafana uses to link a sample to its trace
+const TraceIDLabel = "trace_id"
+
+// ObserveWithTrace records a histogram sample, attaching the trace ID of the
+// span in ctx as an exemplar when the span is sampled
+func ObserveWithTrace(ctx context.Context, observer prometheus.Observer, value float64) {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok && spanCtx.IsSampled() {
+		exemplarObserver.ObserveWithExemplar(value, prometheus.Labels{TraceIDLabel: spanCtx.TraceID().String()})
+		return
+	}
+	observer.Observe(value)
+}
+
+// Handler serves the default registry on /metrics. OpenMetrics is negotiated
+// when the scraper asks for it, which is the only format that carries exemplars.
+func Handler() http.Handler {
+	return promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	)
+}
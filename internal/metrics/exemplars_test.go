@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestObserveWithTrace(t *testing.T) {
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "test_duration_seconds",
+		Buckets: []float64{1, 10},
+	})
+
+	traceID := trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	sampled := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     trace.SpanID{0, 0, 0, 0, 0, 0, 0, 1},
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	ObserveWithTrace(sampled, histogram, 0.5)
+	ObserveWithTrace(context.Background(), histogram, 5)
+
+	var m dto.Metric
+	require.NoError(t, histogram.Write(&m))
+	buckets := m.GetHistogram().GetBucket()
+	require.Len(t, buckets, 2)
+
+	exemplar := buckets[0].GetExemplar()
+	require.NotNil(t, exemplar)
+	assert.Equal(t, 0.5, exemplar.GetValue())
+	require.Len(t, exemplar.GetLabel(), 1)
+	assert.Equal(t, TraceIDLabel, exemplar.GetLabel()[0].GetName())
+	assert.Equal(t, traceID.String(), exemplar.GetLabel()[0].GetValue())
+
+	// Samples without a sampled span carry no exemplar
+	assert.Nil(t, buckets[1].GetExemplar())
+	assert.Equal(t, uint64(2), m.GetHistogram().GetSampleCount())
+}
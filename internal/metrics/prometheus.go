@@ -1,8 +1,11 @@
 package metrics
 
 import (
+	"context"
 	"fmt"
+	"time"
 
+	"github.com/Xover-Official/Xover/internal/concurrency"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -42,6 +45,31 @@ var (
 		[]string{"model"},
 	)
 
+	AIModelRequestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "talos_ai_model_request_duration_seconds",
+			Help:    "Latency of each AI model call, including retries, by model and analysis type",
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+		},
+		[]string{"model", "analysis_type"},
+	)
+
+	AIModelRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "talos_ai_model_requests_total",
+			Help: "AI model calls by model, analysis type and outcome (success/error)",
+		},
+		[]string{"model", "analysis_type", "outcome"},
+	)
+
+	AISchemaValidations = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "talos_ai_schema_validations_total",
+			Help: "AI decision responses checked against the decision schema, by model and result (valid/violation)",
+		},
+		[]string{"model", "result"},
+	)
+
 	// Cloud Resource Metrics
 	ResourcesDiscovered = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -83,6 +111,23 @@ var (
 		},
 	)
 
+	SavingsAccuracyRatio = promauto.NewSummaryVec(
+		prometheus.SummaryOpts{
+			Name:       "talos_savings_accuracy_ratio",
+			Help:       "Realized over estimated savings per executed optimization (1 = exact estimate)",
+			Objectives: map[float64]float64{0.1: 0.01, 0.5: 0.01, 0.9: 0.01},
+			MaxAge:     30 * 24 * time.Hour,
+		},
+		[]string{"type"},
+	)
+
+	SavingsEstimateMAPE = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "talos_savings_estimate_mape_percent",
+			Help: "Mean absolute percentage error of savings estimates over the accuracy window",
+		},
+	)
+
 	// OODA Loop Metrics
 	OODALoopDuration = promauto.NewHistogram(
 		prometheus.HistogramOpts{
@@ -135,6 +180,22 @@ var (
 		},
 	)
 
+	RetentionRowsPurged = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "talos_retention_rows_purged_total",
+			Help: "Rows deleted by the retention purge job, by table",
+		},
+		[]string{"table"},
+	)
+
+	RetentionRowsEligible = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "talos_retention_rows_eligible",
+			Help: "Rows past their retention period as of the last dry-run purge, by table",
+		},
+		[]string{"table"},
+	)
+
 	// Cache Metrics
 	CacheHitRatio = promauto.NewGauge(
 		prometheus.GaugeOpts{
@@ -151,6 +212,23 @@ var (
 		[]string{"operation", "result"}, // get/set, hit/miss/error
 	)
 
+	// Concurrency Metrics
+	GoroutinesActive = promauto.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "talos_goroutines_active",
+			Help: "Goroutines running under the shared concurrency manager",
+		},
+		func() float64 { return float64(concurrency.Default().Active()) },
+	)
+
+	GoroutinesRejected = promauto.NewCounterFunc(
+		prometheus.CounterOpts{
+			Name: "talos_goroutines_rejected_total",
+			Help: "Work dropped because the goroutine ceiling was reached",
+		},
+		func() float64 { return float64(concurrency.Default().Rejected()) },
+	)
+
 	// API Metrics
 	HTTPRequestsTotal = promauto.NewCounterVec(
 		prometheus.CounterOpts{
@@ -179,12 +257,52 @@ func RecordAIRequest(model string, tier int, status string, duration float64, to
 	AICostUSD.WithLabelValues(model).Add(cost)
 }
 
+// RecordAIModelCall records the latency and outcome of a single AI model call,
+// linking the latency sample to the active trace
+func RecordAIModelCall(ctx context.Context, model, analysisType string, duration time.Duration, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	ObserveWithTrace(ctx, AIModelRequestDuration.WithLabelValues(model, analysisType), duration.Seconds())
+	AIModelRequestsTotal.WithLabelValues(model, analysisType, outcome).Inc()
+}
+
+// RecordSchemaValidation counts an AI response checked against the decision
+// schema; the violation rate is violation over all results
+func RecordSchemaValidation(model string, valid bool) {
+	result := "valid"
+	if !valid {
+		result = "violation"
+	}
+	AISchemaValidations.WithLabelValues(model, result).Inc()
+}
+
+// RecordOODACycle records the duration of an OODA cycle, linking the sample
+// to the cycle's trace, and counts the failing phase if any
+func RecordOODACycle(ctx context.Context, duration time.Duration, failedPhase string) {
+	ObserveWithTrace(ctx, OODALoopDuration, duration.Seconds())
+	if failedPhase != "" {
+		OODALoopPhaseErrors.WithLabelValues(failedPhase).Inc()
+	}
+}
+
 // RecordOptimization records an optimization metric
 func RecordOptimization(provider, resourceType, action string, savingsUSD float64) {
 	ResourcesOptimized.WithLabelValues(provider, resourceType, action).Inc()
 	OptimizationSavingsUSD.WithLabelValues(provider, resourceType).Add(savingsUSD)
 }
 
+// RecordSavingsAccuracy records how an executed optimization's realized
+// savings compare to its estimate. Events without a positive estimate are
+// ignored since they have no meaningful ratio.
+func RecordSavingsAccuracy(optimizationType string, estimated, actual float64) {
+	if estimated <= 0 {
+		return
+	}
+	SavingsAccuracyRatio.WithLabelValues(optimizationType).Observe(actual / estimated)
+}
+
 // UpdateHealthStatus updates health check status
 func UpdateHealthStatus(component string, healthy bool) {
 	status := 0.0
@@ -194,6 +312,16 @@ func UpdateHealthStatus(component string, healthy bool) {
 	HealthCheckStatus.WithLabelValues(component).Set(status)
 }
 
+// RecordRetentionPurged counts rows deleted by the retention purge job
+func RecordRetentionPurged(table string, rows int64) {
+	RetentionRowsPurged.WithLabelValues(table).Add(float64(rows))
+}
+
+// RecordRetentionEligible records how many rows a dry-run purge would delete
+func RecordRetentionEligible(table string, rows int64) {
+	RetentionRowsEligible.WithLabelValues(table).Set(float64(rows))
+}
+
 func RecordCacheOperation(operation, result string) {
 	CacheOperations.WithLabelValues(operation, result).Inc()
 }
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAIModelCall(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		outcome string
+		other   string
+	}{
+		{"success", nil, "success", "error"},
+		{"error", errors.New("status: 500"), "error", "success"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := "test-model-" + tt.name
+			RecordAIModelCall(context.Background(), model, "ec2", 250*time.Millisecond, tt.err)
+			RecordAIModelCall(context.Background(), model, "ec2", time.Second, tt.err)
+
+			assert.Equal(t, 2.0, testutil.ToFloat64(AIModelRequestsTotal.WithLabelValues(model, "ec2", tt.outcome)))
+			assert.Zero(t, testutil.ToFloat64(AIModelRequestsTotal.WithLabelValues(model, "ec2", tt.other)))
+			assert.Zero(t, testutil.ToFloat64(AIModelRequestsTotal.WithLabelValues(model, "rds", tt.outcome)),
+				"calls are labelled by analysis type")
+		})
+	}
+}
@@ -4,9 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"net/http"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/Xover-Official/Xover/internal/concurrency"
 	"github.com/Xover-Official/Xover/internal/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
@@ -100,10 +104,17 @@ type AlertManager struct {
 	alerts   map[string]*Alert
 	rules    map[string]*AlertRule
 	channels map[string]*NotificationChannel
+	routing  *RoutingConfig // nil sends every alert to every channel
 	mu       sync.RWMutex
 	logger   *log.Logger
 	metrics  *AlertMetrics
 	notifier *Notifier
+	workers  *concurrency.Manager // bounds notification goroutines
+	queries  PromQLEvaluator      // nil fails every rule evaluation
+	onFire   func(Alert)          // nil publishes nothing
+
+	silences     map[string]time.Time // alert ID to the end of its silence
+	silenceStore SilenceStore         // nil keeps silences in memory only
 }
 
 // AlertMetrics tracks alert-related metrics
@@ -115,6 +126,18 @@ type AlertMetrics struct {
 	AlertsBySeverity *prometheus.CounterVec
 }
 
+var (
+	sharedAlertMetrics     *AlertMetrics
+	sharedAlertMetricsOnce sync.Once
+)
+
+// defaultAlertMetrics returns the alert metrics registered with the default
+// registry. They are created once, since registering them twice panics.
+func defaultAlertMetrics() *AlertMetrics {
+	sharedAlertMetricsOnce.Do(func() { sharedAlertMetrics = NewAlertMetrics() })
+	return sharedAlertMetrics
+}
+
 // NewAlertMetrics creates new alert metrics
 func NewAlertMetrics() *AlertMetrics {
 	return &AlertMetrics{
@@ -141,19 +164,76 @@ func NewAlertMetrics() *AlertMetrics {
 	}
 }
 
-// NewAlertManager creates a new alert manager
-func NewAlertManager(logger *log.Logger) *AlertManager {
+// NewAlertManager creates a new alert manager whose rules are evaluated by
+// queries, usually a PrometheusEvaluator. Silences are persisted in
+// silences, and the ones still in effect are loaded from it.
+func NewAlertManager(logger *log.Logger, queries PromQLEvaluator, silences SilenceStore) *AlertManager {
 	if logger == nil {
 		logger = log.Default()
 	}
 
-	return &AlertManager{
-		alerts:   make(map[string]*Alert),
-		rules:    make(map[string]*AlertRule),
-		channels: make(map[string]*NotificationChannel),
-		logger:   logger,
-		metrics:  NewAlertMetrics(),
-		notifier: NewNotifier(logger),
+	am := &AlertManager{
+		alerts:       make(map[string]*Alert),
+		rules:        make(map[string]*AlertRule),
+		channels:     make(map[string]*NotificationChannel),
+		logger:       logger,
+		metrics:      defaultAlertMetrics(),
+		notifier:     NewNotifier(logger),
+		workers:      concurrency.Default(),
+		queries:      queries,
+		silences:     make(map[string]time.Time),
+		silenceStore: silences,
+	}
+
+	if silences != nil {
+		loaded, err := silences.LoadSilences()
+		if err != nil {
+			logger.Printf("Failed to load alert silences: %v", err)
+		}
+		now := time.Now()
+		for _, silence := range loaded {
+			if silence.Until.After(now) {
+				am.silences[silence.AlertID] = silence.Until
+			}
+		}
+	}
+	return am
+}
+
+// SetEmailSender delivers email channel notifications through sender
+// instead of only logging them
+func (am *AlertManager) SetEmailSender(sender EmailSender) {
+	am.notifier.SetEmailSender(sender)
+}
+
+// SetWorkers replaces the manager bounding notification goroutines
+func (am *AlertManager) SetWorkers(workers *concurrency.Manager) {
+	am.workers = workers
+}
+
+// notify sends notifications in the background. When the goroutine ceiling
+// is reached the notification is dropped rather than queued, so a stuck
+// channel can't pile up goroutines under sustained alerting.
+func (am *AlertManager) notify(alert *Alert, send func()) {
+	if err := am.workers.TryGo(send); err != nil {
+		am.logger.Printf("Dropped notification for alert %s: %v", alert.ID, err)
+	}
+}
+
+// SetAlertListener calls listener with a copy of every alert that fires:
+// a new rule breach, a breach outliving its silence, or a raised alert.
+// It is called with the manager locked, so it must not block or call back
+// into the manager.
+func (am *AlertManager) SetAlertListener(listener func(Alert)) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.onFire = listener
+}
+
+// fired passes a copy of alert to the listener. Callers hold am.mu.
+func (am *AlertManager) fired(alert *Alert) {
+	if am.onFire != nil {
+		am.onFire(*alert)
 	}
 }
 
@@ -184,6 +264,33 @@ func (am *AlertManager) AddChannel(channel *NotificationChannel) {
 	am.logger.Printf("Added notification channel: %s", channel.Name)
 }
 
+// SetRouting installs routing rules that select which channels receive each
+// alert. Passing nil restores the default of notifying every channel.
+func (am *AlertManager) SetRouting(routing *RoutingConfig) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	am.routing = routing
+}
+
+// channelsFor returns the channels an alert is routed to. Callers must hold am.mu.
+func (am *AlertManager) channelsFor(alert *Alert) map[string]*NotificationChannel {
+	if am.routing == nil {
+		return am.channels
+	}
+
+	channels := make(map[string]*NotificationChannel)
+	for _, id := range am.routing.Route(alert) {
+		channel, ok := am.channels[id]
+		if !ok {
+			am.logger.Printf("Alert %s routed to unknown channel: %s", alert.ID, id)
+			continue
+		}
+		channels[id] = channel
+	}
+	return channels
+}
+
 // EvaluateRules evaluates all alert rules
 func (am *AlertManager) EvaluateRules(ctx context.Context) error {
 	am.mu.RLock()
@@ -211,8 +318,10 @@ func (am *AlertManager) evaluateRule(ctx context.Context, rule *AlertRule) error
 		return nil
 	}
 
-	// Execute the query (this would integrate with your metrics system)
-	currentValue, err := am.executeQuery(ctx, rule.Query)
+	if am.queries == nil {
+		return fmt.Errorf("no query evaluator configured")
+	}
+	currentValue, err := am.queries.Evaluate(ctx, rule.Query)
 	if err != nil {
 		return fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -229,7 +338,24 @@ func (am *AlertManager) evaluateRule(ctx context.Context, rule *AlertRule) error
 
 	existingAlert, exists := am.alerts[alertID]
 
-	if breached && (!exists || existingAlert.Status == StatusResolved) {
+	// A silenced alert neither fires nor notifies, even after a restart
+	if breached && am.silenced(alertID) {
+		return nil
+	}
+
+	if breached && exists && existingAlert.Status == StatusSilenced {
+		// The silence ended while the alert was still firing
+		existingAlert.Status = StatusActive
+		existingAlert.SilencedUntil = nil
+		existingAlert.Current = currentValue
+
+		channels := am.channelsFor(existingAlert)
+		am.notify(existingAlert, func() { am.notifier.SendNotifications(ctx, existingAlert, channels) })
+		am.fired(existingAlert)
+
+		am.logger.Printf("Alert silence ended: %s", existingAlert.Title)
+
+	} else if breached && (!exists || existingAlert.Status == StatusResolved) {
 		// Create new alert
 		alert := &Alert{
 			ID:          alertID,
@@ -251,12 +377,15 @@ func (am *AlertManager) evaluateRule(ctx context.Context, rule *AlertRule) error
 		am.metrics.AlertsBySeverity.WithLabelValues(string(rule.Severity)).Inc()
 
 		// Send notifications
-		go am.notifier.SendNotifications(ctx, alert, am.channels)
+		channels := am.channelsFor(alert)
+		am.notify(alert, func() { am.notifier.SendNotifications(ctx, alert, channels) })
+		am.fired(alert)
 
 		am.logger.Printf("Alert triggered: %s", alert.Title)
 
-	} else if !breached && exists && existingAlert.Status == StatusActive {
-		// Resolve alert
+	} else if !breached && exists && existingAlert.Status != StatusResolved {
+		// Resolve alert. A silenced alert resolves quietly.
+		wasActive := existingAlert.Status == StatusActive
 		resolvedAt := time.Now()
 		existingAlert.Status = StatusResolved
 		existingAlert.ResolvedAt = &resolvedAt
@@ -265,7 +394,10 @@ func (am *AlertManager) evaluateRule(ctx context.Context, rule *AlertRule) error
 		am.metrics.AlertsResolved.Inc()
 
 		// Send resolution notifications
-		go am.notifier.SendResolutionNotifications(ctx, existingAlert, am.channels)
+		if wasActive {
+			channels := am.channelsFor(existingAlert)
+			am.notify(existingAlert, func() { am.notifier.SendResolutionNotifications(ctx, existingAlert, channels) })
+		}
 
 		am.logger.Printf("Alert resolved: %s", existingAlert.Title)
 	}
@@ -293,13 +425,6 @@ func (am *AlertManager) checkThreshold(value float64, threshold Threshold) bool
 	}
 }
 
-// executeQuery executes a metrics query (placeholder implementation)
-func (am *AlertManager) executeQuery(ctx context.Context, query string) (float64, error) {
-	// This would integrate with Prometheus, InfluxDB, or your metrics system
-	// For now, return a mock value
-	return 75.0, nil
-}
-
 // GetAlerts returns all alerts
 func (am *AlertManager) GetAlerts() []*Alert {
 	am.mu.RLock()
@@ -328,7 +453,8 @@ func (am *AlertManager) GetActiveAlerts() []*Alert {
 	return activeAlerts
 }
 
-// SilenceAlert silences an alert
+// SilenceAlert silences an alert. The silence is persisted, so the alert
+// stays muted across a restart.
 func (am *AlertManager) SilenceAlert(alertID string, duration time.Duration) error {
 	am.mu.Lock()
 	defer am.mu.Unlock()
@@ -339,6 +465,12 @@ func (am *AlertManager) SilenceAlert(alertID string, duration time.Duration) err
 	}
 
 	silencedUntil := time.Now().Add(duration)
+	if am.silenceStore != nil {
+		if err := am.silenceStore.SaveSilence(Silence{AlertID: alertID, Until: silencedUntil}); err != nil {
+			return fmt.Errorf("failed to persist silence for %s: %w", alertID, err)
+		}
+	}
+	am.silences[alertID] = silencedUntil
 	alert.SilencedUntil = &silencedUntil
 	alert.Status = StatusSilenced
 
@@ -346,9 +478,103 @@ func (am *AlertManager) SilenceAlert(alertID string, duration time.Duration) err
 	return nil
 }
 
+// silenced reports whether an alert is silenced, forgetting its silence
+// once it has ended. Callers must hold am.mu for writing.
+func (am *AlertManager) silenced(alertID string) bool {
+	until, ok := am.silences[alertID]
+	if ok && !until.After(time.Now()) {
+		delete(am.silences, alertID)
+		return false
+	}
+	return ok
+}
+
+// GetSilences returns the silences in effect, ordered by alert ID
+func (am *AlertManager) GetSilences() []Silence {
+	am.mu.RLock()
+	defer am.mu.RUnlock()
+
+	now := time.Now()
+	silences := make([]Silence, 0, len(am.silences))
+	for id, until := range am.silences {
+		if until.After(now) {
+			silences = append(silences, Silence{AlertID: id, Until: until})
+		}
+	}
+	sort.Slice(silences, func(i, j int) bool { return silences[i].AlertID < silences[j].AlertID })
+	return silences
+}
+
+// RemoveSilence ends an alert's silence early. A silenced alert that is still
+// firing becomes active again.
+func (am *AlertManager) RemoveSilence(alertID string) error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	if _, ok := am.silences[alertID]; !ok {
+		return errors.NewResourceNotFoundError("silence", alertID)
+	}
+	if am.silenceStore != nil {
+		if err := am.silenceStore.DeleteSilence(alertID); err != nil {
+			return fmt.Errorf("failed to remove silence for %s: %w", alertID, err)
+		}
+	}
+	delete(am.silences, alertID)
+
+	if alert, ok := am.alerts[alertID]; ok && alert.Status == StatusSilenced {
+		alert.Status = StatusActive
+		alert.SilencedUntil = nil
+	}
+	am.logger.Printf("Alert silence removed: %s", alertID)
+	return nil
+}
+
+// RaiseAlert records an alert raised outside rule evaluation, such as an
+// approval request, and notifies its routed channels
+func (am *AlertManager) RaiseAlert(ctx context.Context, alert *Alert) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	if alert.Status == "" {
+		alert.Status = StatusActive
+	}
+	if alert.Timestamp.IsZero() {
+		alert.Timestamp = time.Now()
+	}
+
+	am.alerts[alert.ID] = alert
+	am.metrics.AlertsTotal.Inc()
+	am.metrics.AlertsActive.Inc()
+	am.metrics.AlertsByType.WithLabelValues(string(alert.Type)).Inc()
+	am.metrics.AlertsBySeverity.WithLabelValues(string(alert.Severity)).Inc()
+
+	channels := am.channelsFor(alert)
+	am.notify(alert, func() { am.notifier.SendNotifications(ctx, alert, channels) })
+	am.fired(alert)
+
+	am.logger.Printf("Alert raised: %s", alert.Title)
+}
+
+// BudgetAlertHook returns a callback for analytics.TokenTracker.SetBudgetAlert
+// that raises a cost alert when AI spend reaches thresholdUSD
+func (am *AlertManager) BudgetAlertHook(thresholdUSD float64) func(currentUSD float64) {
+	return func(currentUSD float64) {
+		am.RaiseAlert(context.Background(), NewBudgetAlert(currentUSD, thresholdUSD))
+	}
+}
+
+// Templates returns the notification templates, so callers can override them
+func (am *AlertManager) Templates() *NotificationTemplates {
+	return am.notifier.templates
+}
+
 // Notifier handles sending alert notifications
 type Notifier struct {
-	logger *log.Logger
+	logger       *log.Logger
+	templates    *NotificationTemplates
+	email        EmailSender // nil only logs email notifications
+	http         *http.Client
+	pagerDutyURL string
 }
 
 // NewNotifier creates a new notifier
@@ -356,7 +582,12 @@ func NewNotifier(logger *log.Logger) *Notifier {
 	if logger == nil {
 		logger = log.Default()
 	}
-	return &Notifier{logger: logger}
+	return &Notifier{
+		logger:       logger,
+		templates:    DefaultNotificationTemplates(),
+		http:         &http.Client{Timeout: 10 * time.Second},
+		pagerDutyURL: PagerDutyEventsURL,
+	}
 }
 
 // SendNotifications sends alert notifications through all channels
@@ -366,8 +597,9 @@ func (n *Notifier) SendNotifications(ctx context.Context, alert *Alert, channels
 			continue
 		}
 
-		// Rate limiting: don't spam notifications
-		if time.Since(channel.LastSent) < 5*time.Minute {
+		// Rate limiting: don't spam notifications. Approval requests are each
+		// individually actionable, so they are never suppressed.
+		if approvalPreview(alert) == nil && time.Since(channel.LastSent) < 5*time.Minute {
 			continue
 		}
 
@@ -392,17 +624,37 @@ func (n *Notifier) SendResolutionNotifications(ctx context.Context, alert *Alert
 	}
 }
 
+// TestChannel sends a test alert through one channel, ignoring its enabled
+// flag and rate limit, and reports whether delivery succeeded
+func (n *Notifier) TestChannel(ctx context.Context, channel *NotificationChannel) error {
+	alert := &Alert{
+		ID:          "talos-test",
+		Type:        AlertTypeSystem,
+		Severity:    SeverityInfo,
+		Status:      StatusActive,
+		Title:       "Talos test notification",
+		Description: "This is a test notification; no action is needed.",
+		Timestamp:   time.Now(),
+	}
+	return n.sendNotification(ctx, alert, channel)
+}
+
 // sendNotification sends a single notification
 func (n *Notifier) sendNotification(ctx context.Context, alert *Alert, channel *NotificationChannel) error {
+	message, err := n.templates.Render(channel.Type, alert)
+	if err != nil {
+		return err
+	}
+
 	switch channel.Type {
 	case "email":
-		return n.sendEmailNotification(ctx, alert, channel)
+		return n.sendEmailNotification(ctx, alert, channel, message)
 	case "slack":
-		return n.sendSlackNotification(ctx, alert, channel)
+		return n.sendSlackNotification(ctx, alert, channel, message)
 	case "webhook":
-		return n.sendWebhookNotification(ctx, alert, channel)
+		return n.sendWebhookNotification(ctx, alert, channel, message)
 	case "pagerduty":
-		return n.sendPagerDutyNotification(ctx, alert, channel)
+		return n.sendPagerDutyNotification(ctx, alert, channel, message)
 	default:
 		return fmt.Errorf("unsupported notification channel type: %s", channel.Type)
 	}
@@ -410,28 +662,83 @@ func (n *Notifier) sendNotification(ctx context.Context, alert *Alert, channel *
 
 // sendResolutionNotification sends a resolution notification
 func (n *Notifier) sendResolutionNotification(ctx context.Context, alert *Alert, channel *NotificationChannel) error {
+	// PagerDuty closes the incident rather than receiving a new message
+	if channel.Type == "pagerduty" {
+		return n.resolvePagerDutyIncident(ctx, alert, channel)
+	}
 	// Similar to sendNotification but with resolution message
 	return n.sendNotification(ctx, alert, channel)
 }
 
-// Placeholder implementations for notification methods
-func (n *Notifier) sendEmailNotification(ctx context.Context, alert *Alert, channel *NotificationChannel) error {
-	n.logger.Printf("Email notification sent for alert: %s", alert.Title)
-	return nil
+// SetEmailSender sets the transport email channels deliver through
+func (n *Notifier) SetEmailSender(sender EmailSender) {
+	n.email = sender
 }
 
-func (n *Notifier) sendSlackNotification(ctx context.Context, alert *Alert, channel *NotificationChannel) error {
-	n.logger.Printf("Slack notification sent for alert: %s", alert.Title)
-	return nil
+// sendEmailNotification mails the rendered message to the channel's "to"
+// addresses. The template's leading "Subject:" line becomes the subject,
+// falling back to the channel's "subject" setting.
+func (n *Notifier) sendEmailNotification(ctx context.Context, alert *Alert, channel *NotificationChannel, message string) error {
+	// A channel with its own SMTP server overrides the notifier's sender
+	sender := n.email
+	if cfg, ok, err := channelSMTPConfig(channel); err != nil {
+		return err
+	} else if ok {
+		sender = NewSMTPSender(cfg)
+	}
+	if sender == nil {
+		n.logger.Printf("Email notification sent for alert: %s\n%s", alert.Title, message)
+		return nil
+	}
+
+	subject, _ := channel.Config["subject"].(string)
+	if first, rest, ok := strings.Cut(message, "\n"); ok && strings.HasPrefix(first, "Subject: ") {
+		subject, message = strings.TrimPrefix(first, "Subject: "), strings.TrimLeft(rest, "\n")
+	}
+	htmlBody, err := renderAlertEmailHTML(alert, message)
+	if err != nil {
+		return err
+	}
+	return sender.Send(ctx, &EmailMessage{
+		To:       channelRecipients(channel),
+		Subject:  subject,
+		Body:     message,
+		HTMLBody: htmlBody,
+	})
 }
 
-func (n *Notifier) sendWebhookNotification(ctx context.Context, alert *Alert, channel *NotificationChannel) error {
-	n.logger.Printf("Webhook notification sent for alert: %s", alert.Title)
+// channelRecipients reads an email channel's "to" setting, either a
+// comma-separated string or a list
+func channelRecipients(channel *NotificationChannel) []string {
+	var to []string
+	switch v := channel.Config["to"].(type) {
+	case string:
+		for _, addr := range strings.Split(v, ",") {
+			if addr = strings.TrimSpace(addr); addr != "" {
+				to = append(to, addr)
+			}
+		}
+	case []string:
+		to = append(to, v...)
+	case []interface{}:
+		for _, addr := range v {
+			if s, ok := addr.(string); ok {
+				to = append(to, s)
+			}
+		}
+	}
+	return to
+}
+
+// Placeholder implementations for notification methods
+
+func (n *Notifier) sendSlackNotification(ctx context.Context, alert *Alert, channel *NotificationChannel, message string) error {
+	n.logger.Printf("Slack notification sent for alert: %s\n%s", alert.Title, message)
 	return nil
 }
 
-func (n *Notifier) sendPagerDutyNotification(ctx context.Context, alert *Alert, channel *NotificationChannel) error {
-	n.logger.Printf("PagerDuty notification sent for alert: %s", alert.Title)
+func (n *Notifier) sendWebhookNotification(ctx context.Context, alert *Alert, channel *NotificationChannel, message string) error {
+	n.logger.Printf("Webhook notification sent for alert: %s\n%s", alert.Title, message)
 	return nil
 }
 
@@ -481,7 +788,7 @@ func DefaultAlertRules() []*AlertRule {
 				Value:    0.0,
 				Duration: "1m",
 			},
-			Query:    "up",
+			Query:    "min(up)",
 			Labels:   map[string]string{"team": "sre"},
 			Enabled:  true,
 			Interval: 30 * time.Second,
@@ -513,7 +820,7 @@ func DefaultAlertRules() []*AlertRule {
 				Value:    5.0,
 				Duration: "10m",
 			},
-			Query:    "rate(optimization_failures[10m])",
+			Query:    "sum(rate(optimization_failures[10m]))",
 			Labels:   map[string]string{"team": "automation"},
 			Enabled:  true,
 			Interval: 2 * time.Minute,
@@ -544,6 +851,16 @@ func DefaultNotificationChannels() []*NotificationChannel {
 			},
 			Enabled: true,
 		},
+		{
+			ID:   "slack-finance",
+			Name: "Slack Finance",
+			Type: "slack",
+			Config: map[string]interface{}{
+				"webhook_url": "https://hooks.slack.com/services/...",
+				"channel":     "#finance",
+			},
+			Enabled: true,
+		},
 		{
 			ID:   "pagerduty-critical",
 			Name: "PagerDuty Critical",
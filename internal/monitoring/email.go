@@ -0,0 +1,385 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig is the outgoing mail server used by the email channel
+type SMTPConfig struct {
+	Host       string
+	Port       int // defaults to 587
+	Username   string
+	Password   string
+	From       string
+	Timeout    time.Duration // bounds connecting and the whole exchange; defaults to 30s
+	RequireTLS bool          // fail rather than send in the clear if the server lacks STARTTLS
+}
+
+// defaultSMTPTimeout applies when SMTPConfig.Timeout is unset
+const defaultSMTPTimeout = 30 * time.Second
+
+// EmailAttachment is a file attached to an email
+type EmailAttachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// EmailMessage is a plain-text or HTML email with optional attachments
+type EmailMessage struct {
+	To          []string
+	Subject     string
+	Body        string
+	HTML        bool   // Body is HTML rather than plain text
+	HTMLBody    string // HTML version of a plain-text Body, sent as multipart/alternative
+	Attachments []EmailAttachment
+}
+
+// EmailSender delivers email; SMTPSender is the production implementation
+type EmailSender interface {
+	Send(ctx context.Context, msg *EmailMessage) error
+}
+
+// SMTPSender sends email through an SMTP server, upgrading to TLS with
+// STARTTLS when the server offers it
+type SMTPSender struct {
+	cfg      SMTPConfig
+	sendMail func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+	now      func() time.Time
+}
+
+// NewSMTPSender creates a sender for the given server
+func NewSMTPSender(cfg SMTPConfig) *SMTPSender {
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultSMTPTimeout
+	}
+	s := &SMTPSender{cfg: cfg, now: time.Now}
+	s.sendMail = s.deliver
+	return s
+}
+
+// deliver sends msg over one SMTP session, upgrading to TLS with STARTTLS
+// when the server offers it. It is smtp.SendMail with a timeout and
+// RequireTLS.
+func (s *SMTPSender) deliver(addr string, auth smtp.Auth, from string, to []string, msg []byte) error {
+	conn, err := net.DialTimeout("tcp", addr, s.cfg.Timeout)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(s.cfg.Timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+	client, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	} else if s.cfg.RequireTLS {
+		return fmt.Errorf("%s does not support STARTTLS", addr)
+	}
+
+	if auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return fmt.Errorf("%s does not support authentication", addr)
+		}
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(from); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return fmt.Errorf("recipient %s rejected: %w", recipient, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// Send delivers msg to all of its recipients
+func (s *SMTPSender) Send(ctx context.Context, msg *EmailMessage) error {
+	if len(msg.To) == 0 {
+		return errors.New("email has no recipients")
+	}
+	from, err := mail.ParseAddress(s.cfg.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address %q: %w", s.cfg.From, err)
+	}
+	to := make([]string, 0, len(msg.To))
+	for _, recipient := range msg.To {
+		addr, err := mail.ParseAddress(recipient)
+		if err != nil {
+			return fmt.Errorf("invalid recipient address %q: %w", recipient, err)
+		}
+		to = append(to, addr.Address)
+	}
+
+	body, err := s.build(from, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.cfg.Username != "" {
+		auth = smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)
+	}
+
+	// net/smtp has no context support, so honour cancellation around the call
+	done := make(chan error, 1)
+	go func() {
+		done <- s.sendMail(net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port)), auth, from.Address, to, body)
+	}()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send email: %w", err)
+		}
+		return nil
+	}
+}
+
+// build renders msg as a MIME message: the body alone, or a multipart/mixed
+// message when there are attachments
+func (s *SMTPSender) build(from *mail.Address, msg *EmailMessage) ([]byte, error) {
+	var buf bytes.Buffer
+	header := textproto.MIMEHeader{}
+	header.Set("From", from.String())
+	header.Set("To", strings.Join(msg.To, ", "))
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header.Set("Date", s.now().Format(time.RFC1123Z))
+	header.Set("MIME-Version", "1.0")
+
+	bodyHeader, body, err := buildBody(msg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(msg.Attachments) == 0 {
+		for key, values := range bodyHeader {
+			header[key] = values
+		}
+		writeHeader(&buf, header)
+		buf.Write(body)
+		return buf.Bytes(), nil
+	}
+
+	mw := multipart.NewWriter(&buf)
+	header.Set("Content-Type", "multipart/mixed; boundary="+mw.Boundary())
+	writeHeader(&buf, header)
+
+	part, err := mw.CreatePart(bodyHeader)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(body); err != nil {
+		return nil, err
+	}
+
+	for _, attachment := range msg.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(part, attachment.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// buildBody encodes the message body and returns its part headers. A
+// plain-text body with an HTML version becomes multipart/alternative.
+func buildBody(msg *EmailMessage) (textproto.MIMEHeader, []byte, error) {
+	var buf bytes.Buffer
+	if msg.HTMLBody == "" || msg.HTML {
+		bodyType := "text/plain; charset=utf-8"
+		if msg.HTML {
+			bodyType = "text/html; charset=utf-8"
+		}
+		if err := writeQuotedPrintable(&buf, msg.Body); err != nil {
+			return nil, nil, err
+		}
+		return textproto.MIMEHeader{
+			"Content-Type":              {bodyType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		}, buf.Bytes(), nil
+	}
+
+	// Clients show the last alternative they support, so HTML goes last
+	mw := multipart.NewWriter(&buf)
+	for _, alt := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", msg.Body},
+		{"text/html; charset=utf-8", msg.HTMLBody},
+	} {
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {alt.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := writeQuotedPrintable(part, alt.body); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, nil, err
+	}
+	return textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + mw.Boundary()},
+	}, buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for _, key := range []string{"From", "To", "Subject", "Date", "MIME-Version", "Content-Type", "Content-Transfer-Encoding"} {
+		if value := header.Get(key); value != "" {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+func writeQuotedPrintable(w io.Writer, s string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(s)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeBase64 writes data base64-encoded in 76-character lines (RFC 2045)
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := fmt.Fprintf(w, "%s\r\n", encoded[:76]); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := fmt.Fprintf(w, "%s\r\n", encoded)
+	return err
+}
+
+// alertEmailHTML is the HTML version of an alert email. The rendered text
+// message is kept verbatim so template overrides apply to both versions.
+var alertEmailHTML = htmltemplate.Must(htmltemplate.New("alert-email").Parse(`<!DOCTYPE html>
+<html><body style="font-family: sans-serif">
+<h2>{{.Alert.Title}}</h2>
+<p><strong>Severity:</strong> {{.Alert.Severity}}{{with .Alert.Type}} &middot; <strong>Type:</strong> {{.}}{{end}}</p>
+<pre style="font-family: monospace; white-space: pre-wrap">{{.Message}}</pre>
+</body></html>
+`))
+
+func renderAlertEmailHTML(alert *Alert, message string) (string, error) {
+	var buf bytes.Buffer
+	err := alertEmailHTML.Execute(&buf, struct {
+		Alert   *Alert
+		Message string
+	}{alert, message})
+	if err != nil {
+		return "", fmt.Errorf("failed to render email HTML: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// channelSMTPConfig reads an email channel's own SMTP server from its
+// host, port, username, password, from, timeout and require_tls settings.
+// It reports false when the channel has no host.
+func channelSMTPConfig(channel *NotificationChannel) (SMTPConfig, bool, error) {
+	host, _ := channel.Config["host"].(string)
+	if host == "" {
+		return SMTPConfig{}, false, nil
+	}
+
+	cfg := SMTPConfig{Host: host}
+	cfg.Username, _ = channel.Config["username"].(string)
+	cfg.Password, _ = channel.Config["password"].(string)
+	cfg.RequireTLS, _ = channel.Config["require_tls"].(bool)
+	cfg.From, _ = channel.Config["from"].(string)
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+
+	switch port := channel.Config["port"].(type) {
+	case nil:
+	case int:
+		cfg.Port = port
+	case float64: // decoded from JSON
+		cfg.Port = int(port)
+	case string:
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return SMTPConfig{}, false, fmt.Errorf("email channel %s: invalid port %q", channel.ID, port)
+		}
+		cfg.Port = p
+	default:
+		return SMTPConfig{}, false, fmt.Errorf("email channel %s: invalid port %v", channel.ID, port)
+	}
+
+	switch timeout := channel.Config["timeout"].(type) {
+	case nil:
+	case time.Duration:
+		cfg.Timeout = timeout
+	case string:
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return SMTPConfig{}, false, fmt.Errorf("email channel %s: invalid timeout %q", channel.ID, timeout)
+		}
+		cfg.Timeout = d
+	default:
+		return SMTPConfig{}, false, fmt.Errorf("email channel %s: invalid timeout %v", channel.ID, timeout)
+	}
+	return cfg, true, nil
+}
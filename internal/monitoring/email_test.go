@@ -0,0 +1,309 @@
+package monitoring
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type capturedMail struct {
+	addr string
+	from string
+	to   []string
+	msg  []byte
+}
+
+func newCapturingSender(cfg SMTPConfig) (*SMTPSender, *capturedMail) {
+	captured := &capturedMail{}
+	sender := NewSMTPSender(cfg)
+	sender.now = func() time.Time { return time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC) }
+	sender.sendMail = func(addr string, _ smtp.Auth, from string, to []string, msg []byte) error {
+		captured.addr, captured.from, captured.to, captured.msg = addr, from, to, msg
+		return nil
+	}
+	return sender, captured
+}
+
+func TestSMTPSender_SendWithAttachment(t *testing.T) {
+	sender, captured := newCapturingSender(SMTPConfig{Host: "smtp.example.com", From: "Talos <talos@example.com>"})
+
+	err := sender.Send(context.Background(), &EmailMessage{
+		To:      []string{"finance@example.com", "CFO <cfo@example.com>"},
+		Subject: "Savings report – January",
+		Body:    "<p>Your report is attached.</p>",
+		HTML:    true,
+		Attachments: []EmailAttachment{{
+			Filename:    "report.pdf",
+			ContentType: "application/pdf",
+			Data:        []byte("%PDF-1.4 fake"),
+		}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "smtp.example.com:587", captured.addr)
+	assert.Equal(t, "talos@example.com", captured.from)
+	assert.Equal(t, []string{"finance@example.com", "cfo@example.com"}, captured.to)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(captured.msg)))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Savings report – January", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/mixed", mediaType)
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	body, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "text/html; charset=utf-8", body.Header.Get("Content-Type"))
+	html, _ := io.ReadAll(body) // multipart decodes quoted-printable
+	assert.Equal(t, "<p>Your report is attached.</p>", string(html))
+
+	attachment, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "report.pdf", attachment.FileName())
+	assert.Equal(t, "application/pdf", attachment.Header.Get("Content-Type"))
+}
+
+func TestSMTPSender_RejectsInvalidAddresses(t *testing.T) {
+	sender, _ := newCapturingSender(SMTPConfig{Host: "smtp.example.com", From: "talos@example.com"})
+
+	assert.Error(t, sender.Send(context.Background(), &EmailMessage{Subject: "no recipients"}))
+	assert.Error(t, sender.Send(context.Background(), &EmailMessage{To: []string{"not an address"}}))
+}
+
+type recordingEmailSender struct {
+	sent []*EmailMessage
+}
+
+func (r *recordingEmailSender) Send(_ context.Context, msg *EmailMessage) error {
+	r.sent = append(r.sent, msg)
+	return nil
+}
+
+func TestNotifier_EmailChannelUsesSender(t *testing.T) {
+	sender := &recordingEmailSender{}
+	notifier := NewNotifier(nil)
+	notifier.SetEmailSender(sender)
+
+	channel := &NotificationChannel{ID: "email-admin", Type: "email", Enabled: true, Config: map[string]interface{}{
+		"to": "admin@example.com, ops@example.com",
+	}}
+	alert := &Alert{ID: "a1", Title: "Disk full", Description: "Volume at 95%", Severity: SeverityCritical}
+	require.NoError(t, notifier.sendNotification(context.Background(), alert, channel))
+
+	require.Len(t, sender.sent, 1)
+	assert.Equal(t, []string{"admin@example.com", "ops@example.com"}, sender.sent[0].To)
+	assert.Equal(t, "[Talos] [CRITICAL] Disk full", sender.sent[0].Subject)
+	assert.Contains(t, sender.sent[0].Body, "Volume at 95%")
+	assert.NotContains(t, sender.sent[0].Body, "Subject:")
+}
+
+func TestNotifier_TestChannelIgnoresRateLimit(t *testing.T) {
+	sender := &recordingEmailSender{}
+	notifier := NewNotifier(nil)
+	notifier.SetEmailSender(sender)
+
+	channel := &NotificationChannel{ID: "email-admin", Type: "email", LastSent: time.Now(), Config: map[string]interface{}{
+		"to": "admin@example.com",
+	}}
+	require.NoError(t, notifier.TestChannel(context.Background(), channel))
+	require.Len(t, sender.sent, 1)
+	assert.Contains(t, sender.sent[0].Subject, "Talos test notification")
+
+	assert.Error(t, notifier.TestChannel(context.Background(), &NotificationChannel{Type: "carrier-pigeon"}))
+}
+
+// fakeSMTPServer speaks just enough SMTP for net/smtp: EHLO with AUTH PLAIN
+// and no STARTTLS, MAIL, RCPT, DATA and QUIT
+type fakeSMTPServer struct {
+	listener   net.Listener
+	rejectAuth bool
+
+	mu    sync.Mutex
+	auth  string
+	from  string
+	rcpts []string
+	data  []byte
+}
+
+func startFakeSMTPServer(t *testing.T, rejectAuth bool) *fakeSMTPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &fakeSMTPServer{listener: listener, rejectAuth: rejectAuth}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server
+}
+
+func (s *fakeSMTPServer) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	tp.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		verb, arg, _ := strings.Cut(line, " ")
+		s.mu.Lock()
+		switch strings.ToUpper(verb) {
+		case "EHLO", "HELO":
+			tp.PrintfLine("250-fake")
+			tp.PrintfLine("250 AUTH PLAIN")
+		case "AUTH":
+			s.auth = arg
+			if s.rejectAuth {
+				tp.PrintfLine("535 5.7.8 Authentication credentials invalid")
+			} else {
+				tp.PrintfLine("235 2.7.0 Authentication successful")
+			}
+		case "MAIL":
+			s.from = arg
+			tp.PrintfLine("250 OK")
+		case "RCPT":
+			s.rcpts = append(s.rcpts, arg)
+			tp.PrintfLine("250 OK")
+		case "DATA":
+			tp.PrintfLine("354 Go ahead")
+			s.data, _ = tp.ReadDotBytes()
+			tp.PrintfLine("250 OK")
+		case "QUIT":
+			tp.PrintfLine("221 Bye")
+			s.mu.Unlock()
+			return
+		default:
+			tp.PrintfLine("502 Not implemented")
+		}
+		s.mu.Unlock()
+	}
+}
+
+func smtpChannel(server *fakeSMTPServer) *NotificationChannel {
+	return &NotificationChannel{ID: "email-ops", Type: "email", Enabled: true, Config: map[string]interface{}{
+		"host":     "127.0.0.1",
+		"port":     strconv.Itoa(server.port()),
+		"username": "talos@example.com",
+		"password": "hunter2",
+		"to":       "ops@example.com, CFO <cfo@example.com>",
+		"timeout":  "5s",
+	}}
+}
+
+func TestNotifier_EmailChannelSMTPServer(t *testing.T) {
+	server := startFakeSMTPServer(t, false)
+	notifier := NewNotifier(nil)
+
+	alert := &Alert{ID: "a1", Title: "Disk full", Description: "Volume at <95%>", Severity: SeverityCritical}
+	require.NoError(t, notifier.sendNotification(context.Background(), alert, smtpChannel(server)))
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.NotEmpty(t, server.auth, "authenticated with the channel's credentials")
+	assert.Equal(t, "FROM:<talos@example.com>", server.from)
+	assert.Equal(t, []string{"TO:<ops@example.com>", "TO:<cfo@example.com>"}, server.rcpts)
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(server.data)))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "[Talos] [CRITICAL] Disk full", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+
+	text, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "text/plain; charset=utf-8", text.Header.Get("Content-Type"))
+	body, _ := io.ReadAll(text)
+	assert.Contains(t, string(body), "Volume at <95%>")
+
+	html, err := reader.NextPart()
+	require.NoError(t, err)
+	assert.Equal(t, "text/html; charset=utf-8", html.Header.Get("Content-Type"))
+	body, _ = io.ReadAll(html)
+	assert.Contains(t, string(body), "<h2>Disk full</h2>")
+	assert.Contains(t, string(body), "Volume at &lt;95%&gt;", "escaped")
+}
+
+func TestNotifier_EmailChannelSMTPAuthFailure(t *testing.T) {
+	server := startFakeSMTPServer(t, true)
+	notifier := NewNotifier(nil)
+
+	err := notifier.sendNotification(context.Background(), &Alert{ID: "a1", Title: "Disk full"}, smtpChannel(server))
+	assert.ErrorContains(t, err, "authentication failed")
+}
+
+func TestSMTPSender_RequireTLS(t *testing.T) {
+	server := startFakeSMTPServer(t, false)
+	sender := NewSMTPSender(SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "talos@example.com", RequireTLS: true})
+
+	err := sender.Send(context.Background(), &EmailMessage{To: []string{"ops@example.com"}, Subject: "s", Body: "b"})
+	assert.ErrorContains(t, err, "does not support STARTTLS")
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	assert.Empty(t, server.data, "nothing sent in the clear")
+}
+
+func TestSMTPSender_Timeout(t *testing.T) {
+	// Accepts connections but never greets
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	sender := NewSMTPSender(SMTPConfig{
+		Host: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port,
+		From: "talos@example.com", Timeout: 50 * time.Millisecond,
+	})
+	start := time.Now()
+	err = sender.Send(context.Background(), &EmailMessage{To: []string{"ops@example.com"}, Subject: "s", Body: "b"})
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestChannelSMTPConfig(t *testing.T) {
+	_, ok, err := channelSMTPConfig(&NotificationChannel{Config: map[string]interface{}{"to": "a@example.com"}})
+	require.NoError(t, err)
+	assert.False(t, ok, "no host uses the notifier's sender")
+
+	cfg, ok, err := channelSMTPConfig(&NotificationChannel{Config: map[string]interface{}{
+		"host": "smtp.example.com", "port": float64(465), "from": "alerts@example.com", "require_tls": true,
+	}})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, SMTPConfig{Host: "smtp.example.com", Port: 465, From: "alerts@example.com", RequireTLS: true}, cfg)
+
+	_, _, err = channelSMTPConfig(&NotificationChannel{Config: map[string]interface{}{"host": "h", "port": "smtp"}})
+	assert.Error(t, err)
+}
@@ -6,8 +6,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Xover-Official/Xover/internal/metrics"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
 
@@ -251,7 +251,7 @@ func (ms *MonitoringService) SetActiveWorkers(count int) {
 
 // GetMetricsHandler returns the Prometheus metrics handler
 func (ms *MonitoringService) GetMetricsHandler() http.Handler {
-	return promhttp.Handler()
+	return metrics.Handler()
 }
 
 // GetMetrics returns current metrics as a map
@@ -0,0 +1,145 @@
+package monitoring
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// PagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const PagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// pagerDutySummaryLimit is the longest summary PagerDuty accepts
+const pagerDutySummaryLimit = 1024
+
+// pagerDutyEvent is an Events API v2 trigger or resolve event. The alert ID
+// is the dedup key, so resolving an alert closes the incident it opened.
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"` // trigger or resolve
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string                 `json:"summary"`
+	Source        string                 `json:"source"`
+	Severity      string                 `json:"severity"`
+	Timestamp     string                 `json:"timestamp,omitempty"`
+	Component     string                 `json:"component,omitempty"`
+	Class         string                 `json:"class,omitempty"`
+	CustomDetails map[string]interface{} `json:"custom_details,omitempty"`
+}
+
+// pagerDutySeverity maps an alert severity to one PagerDuty accepts
+func pagerDutySeverity(severity AlertSeverity) string {
+	switch severity {
+	case SeverityCritical:
+		return "critical"
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// pagerDutyRoutingKey reads the channel's integration key
+func pagerDutyRoutingKey(channel *NotificationChannel) (string, error) {
+	key, _ := channel.Config["service_key"].(string)
+	if key == "" {
+		return "", fmt.Errorf("pagerduty channel %s has no service_key", channel.ID)
+	}
+	return key, nil
+}
+
+// sendPagerDutyNotification triggers an incident for the alert
+func (n *Notifier) sendPagerDutyNotification(ctx context.Context, alert *Alert, channel *NotificationChannel, message string) error {
+	key, err := pagerDutyRoutingKey(channel)
+	if err != nil {
+		return err
+	}
+
+	summary := alert.Title
+	if alert.Description != "" {
+		summary += ": " + alert.Description
+	}
+	if len(summary) > pagerDutySummaryLimit {
+		summary = summary[:pagerDutySummaryLimit]
+	}
+	source := alert.EntityID
+	if source == "" {
+		source = "talos"
+	}
+
+	details := map[string]interface{}{
+		"message":       message,
+		"current_value": alert.Current,
+	}
+	if len(alert.Labels) > 0 {
+		details["labels"] = alert.Labels
+	}
+	if alert.Threshold != nil {
+		details["threshold"] = fmt.Sprintf("%s %s %g", alert.Threshold.Metric, alert.Threshold.Operator, alert.Threshold.Value)
+	}
+
+	return n.sendPagerDutyEvent(ctx, &pagerDutyEvent{
+		RoutingKey:  key,
+		EventAction: "trigger",
+		DedupKey:    alert.ID,
+		Payload: &pagerDutyPayload{
+			Summary:       summary,
+			Source:        source,
+			Severity:      pagerDutySeverity(alert.Severity),
+			Timestamp:     alert.Timestamp.UTC().Format(time.RFC3339),
+			Component:     alert.EntityType,
+			Class:         string(alert.Type),
+			CustomDetails: details,
+		},
+	})
+}
+
+// resolvePagerDutyIncident resolves the incident the alert triggered
+func (n *Notifier) resolvePagerDutyIncident(ctx context.Context, alert *Alert, channel *NotificationChannel) error {
+	key, err := pagerDutyRoutingKey(channel)
+	if err != nil {
+		return err
+	}
+	return n.sendPagerDutyEvent(ctx, &pagerDutyEvent{
+		RoutingKey:  key,
+		EventAction: "resolve",
+		DedupKey:    alert.ID,
+	})
+}
+
+func (n *Notifier) sendPagerDutyEvent(ctx context.Context, event *pagerDutyEvent) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.pagerDutyURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("pagerduty %s for %s: %w", event.EventAction, event.DedupKey, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("pagerduty %s for %s failed (status %d): %s",
+			event.EventAction, event.DedupKey, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	n.logger.Printf("PagerDuty %s sent for alert: %s", event.EventAction, event.DedupKey)
+	return nil
+}
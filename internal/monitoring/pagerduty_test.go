@@ -0,0 +1,92 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPagerDutyTest(t *testing.T, status int) (*Notifier, *[]pagerDutyEvent) {
+	t.Helper()
+	var events []pagerDutyEvent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var event pagerDutyEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		events = append(events, event)
+		w.WriteHeader(status)
+		io.WriteString(w, `{"status":"success","message":"Event processed"}`)
+	}))
+	t.Cleanup(server.Close)
+
+	notifier := NewNotifier(log.New(io.Discard, "", 0))
+	notifier.http = server.Client()
+	notifier.pagerDutyURL = server.URL
+	return notifier, &events
+}
+
+func TestPagerDuty_TriggerThenResolveShareDedupKey(t *testing.T) {
+	notifier, events := newPagerDutyTest(t, http.StatusAccepted)
+	channel := &NotificationChannel{ID: "pd", Type: "pagerduty", Enabled: true, Config: map[string]interface{}{"service_key": "routing-123"}}
+	channels := map[string]*NotificationChannel{"pd": channel}
+	alert := &Alert{
+		ID:          "service-unavailable-availability",
+		Type:        AlertTypeAvailability,
+		Severity:    SeverityCritical,
+		Status:      StatusActive,
+		Title:       "Service Unavailable alert",
+		Description: "Service Unavailable: 0.00 == 0.00",
+		Timestamp:   time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Labels:      map[string]string{"team": "sre"},
+	}
+
+	notifier.SendNotifications(context.Background(), alert, channels)
+	notifier.SendResolutionNotifications(context.Background(), alert, channels)
+
+	require.Len(t, *events, 2)
+	trigger, resolve := (*events)[0], (*events)[1]
+
+	assert.Equal(t, "trigger", trigger.EventAction)
+	assert.Equal(t, "routing-123", trigger.RoutingKey)
+	assert.Equal(t, alert.ID, trigger.DedupKey)
+	require.NotNil(t, trigger.Payload)
+	assert.Equal(t, "critical", trigger.Payload.Severity)
+	assert.Equal(t, "Service Unavailable alert: Service Unavailable: 0.00 == 0.00", trigger.Payload.Summary)
+	assert.Equal(t, "talos", trigger.Payload.Source)
+	assert.Equal(t, "2026-03-01T12:00:00Z", trigger.Payload.Timestamp)
+	assert.Equal(t, "availability", trigger.Payload.Class)
+
+	assert.Equal(t, "resolve", resolve.EventAction)
+	assert.Equal(t, "routing-123", resolve.RoutingKey)
+	assert.Equal(t, trigger.DedupKey, resolve.DedupKey, "resolve closes the incident the trigger opened")
+	assert.Nil(t, resolve.Payload)
+}
+
+func TestPagerDutySeverity(t *testing.T) {
+	assert.Equal(t, "critical", pagerDutySeverity(SeverityCritical))
+	assert.Equal(t, "error", pagerDutySeverity(SeverityError))
+	assert.Equal(t, "warning", pagerDutySeverity(SeverityWarning))
+	assert.Equal(t, "info", pagerDutySeverity(SeverityInfo))
+	assert.Equal(t, "info", pagerDutySeverity(""))
+}
+
+func TestPagerDuty_Errors(t *testing.T) {
+	notifier, events := newPagerDutyTest(t, http.StatusBadRequest)
+	alert := &Alert{ID: "a", Title: "t", Severity: SeverityWarning}
+
+	err := notifier.sendNotification(context.Background(), alert, &NotificationChannel{ID: "pd", Type: "pagerduty"})
+	assert.ErrorContains(t, err, "no service_key")
+	assert.Empty(t, *events)
+
+	err = notifier.sendNotification(context.Background(), alert,
+		&NotificationChannel{ID: "pd", Type: "pagerduty", Config: map[string]interface{}{"service_key": "k"}})
+	assert.ErrorContains(t, err, "status 400")
+}
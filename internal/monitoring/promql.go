@@ -0,0 +1,107 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Xover-Official/Xover/internal/config"
+)
+
+// PromQLEvaluator runs an alert rule's query and returns its current value
+type PromQLEvaluator interface {
+	Evaluate(ctx context.Context, query string) (float64, error)
+}
+
+// PrometheusEvaluator evaluates rule queries through the Prometheus HTTP
+// instant query API. A query must return a scalar or a single-series vector,
+// so rules over many series aggregate them, e.g. min(up).
+type PrometheusEvaluator struct {
+	baseURL     string
+	bearerToken string
+	client      *http.Client
+}
+
+// NewPrometheusEvaluator creates an evaluator for the Prometheus server at
+// cfg.URL. A nil client uses http.DefaultClient.
+func NewPrometheusEvaluator(cfg config.PrometheusConfig, client *http.Client) *PrometheusEvaluator {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &PrometheusEvaluator{
+		baseURL:     strings.TrimRight(cfg.URL, "/"),
+		bearerToken: cfg.BearerToken,
+		client:      client,
+	}
+}
+
+type promQLResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+// Evaluate runs an instant query and returns its value
+func (p *PrometheusEvaluator) Evaluate(ctx context.Context, query string) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/api/v1/query?"+url.Values{"query": {query}}.Encode(), nil)
+	if err != nil {
+		return 0, err
+	}
+	if p.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+p.bearerToken)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	var body promQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return 0, fmt.Errorf("decode prometheus response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || body.Status != "success" {
+		return 0, fmt.Errorf("prometheus query failed (status %d): %s", resp.StatusCode, body.Error)
+	}
+
+	var sample [2]interface{}
+	switch body.Data.ResultType {
+	case "scalar":
+		if err := json.Unmarshal(body.Data.Result, &sample); err != nil {
+			return 0, fmt.Errorf("decode prometheus scalar: %w", err)
+		}
+	case "vector":
+		var series []struct {
+			Value [2]interface{} `json:"value"`
+		}
+		if err := json.Unmarshal(body.Data.Result, &series); err != nil {
+			return 0, fmt.Errorf("decode prometheus vector: %w", err)
+		}
+		switch len(series) {
+		case 0:
+			return 0, fmt.Errorf("query %q returned no data", query)
+		case 1:
+			sample = series[0].Value
+		default:
+			return 0, fmt.Errorf("query %q returned %d series; aggregate it to one", query, len(series))
+		}
+	default:
+		return 0, fmt.Errorf("query %q returned unsupported result type %q", query, body.Data.ResultType)
+	}
+
+	// Sample values are strings so that NaN and Inf survive JSON
+	raw, _ := sample[1].(string)
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse prometheus sample %q: %w", raw, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,101 @@
+package monitoring
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xover-Official/Xover/internal/config"
+)
+
+type stubEvaluator struct {
+	value float64
+	query string
+}
+
+func (s *stubEvaluator) Evaluate(_ context.Context, query string) (float64, error) {
+	s.query = query
+	return s.value, nil
+}
+
+func TestEvaluateRule_FiresAndResolves(t *testing.T) {
+	queries := &stubEvaluator{}
+	am := NewAlertManager(log.New(io.Discard, "", 0), queries, nil)
+	rule := &AlertRule{
+		ID: "high-cpu-usage", Name: "High CPU Usage", Type: AlertTypePerformance, Severity: SeverityWarning,
+		Threshold: Threshold{Operator: ">", Value: 80},
+		Query:     "avg(cpu_usage)",
+		Enabled:   true,
+	}
+	am.AddRule(rule)
+
+	queries.value = 60
+	require.NoError(t, am.EvaluateRules(context.Background()))
+	assert.Equal(t, "avg(cpu_usage)", queries.query)
+	assert.Empty(t, am.GetActiveAlerts())
+
+	queries.value = 92.5
+	require.NoError(t, am.EvaluateRules(context.Background()))
+	active := am.GetActiveAlerts()
+	require.Len(t, active, 1)
+	assert.Equal(t, 92.5, active[0].Current)
+
+	queries.value = 79
+	require.NoError(t, am.EvaluateRules(context.Background()))
+	assert.Empty(t, am.GetActiveAlerts())
+	alerts := am.GetAlerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StatusResolved, alerts[0].Status)
+	assert.NotNil(t, alerts[0].ResolvedAt)
+}
+
+func TestEvaluateRule_NoEvaluator(t *testing.T) {
+	am := NewAlertManager(log.New(io.Discard, "", 0), nil, nil)
+	err := am.evaluateRule(context.Background(), &AlertRule{ID: "r", Threshold: Threshold{Operator: ">", Value: 1}})
+	assert.ErrorContains(t, err, "no query evaluator")
+	assert.Empty(t, am.GetAlerts())
+}
+
+func TestPrometheusEvaluator(t *testing.T) {
+	responses := map[string]string{
+		"min(up)":      `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"0"]}]}}`,
+		"scalar(1.5)":  `{"status":"success","data":{"resultType":"scalar","result":[1700000000,"1.5"]}}`,
+		"up":           `{"status":"success","data":{"resultType":"vector","result":[{"value":[1,"1"]},{"value":[1,"0"]}]}}`,
+		"absent_thing": `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/query", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		body, ok := responses[r.URL.Query().Get("query")]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			body = `{"status":"error","error":"parse error"}`
+		}
+		io.WriteString(w, body)
+	}))
+	defer server.Close()
+
+	evaluator := NewPrometheusEvaluator(config.PrometheusConfig{URL: server.URL + "/", BearerToken: "secret"}, server.Client())
+	ctx := context.Background()
+
+	value, err := evaluator.Evaluate(ctx, "min(up)")
+	require.NoError(t, err)
+	assert.Zero(t, value)
+
+	value, err = evaluator.Evaluate(ctx, "scalar(1.5)")
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, value)
+
+	_, err = evaluator.Evaluate(ctx, "up")
+	assert.ErrorContains(t, err, "2 series")
+	_, err = evaluator.Evaluate(ctx, "absent_thing")
+	assert.ErrorContains(t, err, "no data")
+	_, err = evaluator.Evaluate(ctx, "bad(")
+	assert.ErrorContains(t, err, "parse error")
+}
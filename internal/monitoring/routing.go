@@ -0,0 +1,110 @@
+package monitoring
+
+// RoutingRule selects the notification channels for alerts matching its
+// criteria. Empty criteria match everything; all non-empty criteria must match.
+type RoutingRule struct {
+	Name       string            `json:"name" yaml:"name"`
+	Types      []AlertType       `json:"types,omitempty" yaml:"types,omitempty"`
+	Severities []AlertSeverity   `json:"severities,omitempty" yaml:"severities,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Channels   []string          `json:"channels" yaml:"channels"` // notification channel IDs
+	// Continue keeps evaluating later rules after this one matches,
+	// like Alertmanager's "continue" flag
+	Continue bool `json:"continue,omitempty" yaml:"continue,omitempty"`
+}
+
+// RoutingConfig is the routing tree: ordered rules plus a catch-all
+type RoutingConfig struct {
+	Rules           []RoutingRule `json:"rules" yaml:"rules"`
+	DefaultChannels []string      `json:"default_channels" yaml:"default_channels"`
+}
+
+// Matches reports whether the alert satisfies all of the rule's criteria
+func (r RoutingRule) Matches(alert *Alert) bool {
+	if len(r.Types) > 0 && !containsType(r.Types, alert.Type) {
+		return false
+	}
+	if len(r.Severities) > 0 && !containsSeverity(r.Severities, alert.Severity) {
+		return false
+	}
+	for key, value := range r.Labels {
+		if alert.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Route returns the channel IDs an alert should be sent to. Rules are
+// evaluated in order; the first match wins unless it sets Continue. Alerts
+// matching no rule go to DefaultChannels.
+func (c *RoutingConfig) Route(alert *Alert) []string {
+	var channels []string
+	seen := make(map[string]bool)
+	matched := false
+
+	for _, rule := range c.Rules {
+		if !rule.Matches(alert) {
+			continue
+		}
+		matched = true
+		for _, id := range rule.Channels {
+			if !seen[id] {
+				seen[id] = true
+				channels = append(channels, id)
+			}
+		}
+		if !rule.Continue {
+			break
+		}
+	}
+
+	if !matched {
+		return append([]string(nil), c.DefaultChannels...)
+	}
+	return channels
+}
+
+func containsType(types []AlertType, t AlertType) bool {
+	for _, candidate := range types {
+		if candidate == t {
+			return true
+		}
+	}
+	return false
+}
+
+func containsSeverity(severities []AlertSeverity, s AlertSeverity) bool {
+	for _, candidate := range severities {
+		if candidate == s {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRoutingConfig pages on-call only for critical alerts, sends approval
+// requests to the alerts channel and admins, cost notices to finance, and
+// everything else to the general alerts channel
+func DefaultRoutingConfig() *RoutingConfig {
+	return &RoutingConfig{
+		Rules: []RoutingRule{
+			{
+				Name:       "critical",
+				Severities: []AlertSeverity{SeverityCritical},
+				Channels:   []string{"pagerduty-critical", "slack-alerts"},
+			},
+			{
+				Name:     "approvals",
+				Labels:   map[string]string{"kind": "approval"},
+				Channels: []string{"slack-alerts", "email-admin"},
+			},
+			{
+				Name:     "cost",
+				Types:    []AlertType{AlertTypeCost},
+				Channels: []string{"slack-finance", "email-admin"},
+			},
+		},
+		DefaultChannels: []string{"slack-alerts"},
+	}
+}
@@ -0,0 +1,60 @@
+package monitoring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultRoutingConfig(t *testing.T) {
+	routing := DefaultRoutingConfig()
+
+	tests := []struct {
+		name     string
+		alert    *Alert
+		expected []string
+	}{
+		{
+			name:     "critical pages on-call",
+			alert:    &Alert{Type: AlertTypeAvailability, Severity: SeverityCritical},
+			expected: []string{"pagerduty-critical", "slack-alerts"},
+		},
+		{
+			name:     "approval requests go to alerts and admins",
+			alert:    NewApprovalAlert(&ApprovalPreview{ActionID: "act-1", ActionType: "optimize"}),
+			expected: []string{"slack-alerts", "email-admin"},
+		},
+		{
+			name:     "cost goes to finance",
+			alert:    &Alert{Type: AlertTypeCost, Severity: SeverityError},
+			expected: []string{"slack-finance", "email-admin"},
+		},
+		{
+			name:     "everything else uses the catch-all",
+			alert:    &Alert{Type: AlertTypePerformance, Severity: SeverityWarning},
+			expected: []string{"slack-alerts"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, routing.Route(tt.alert))
+		})
+	}
+}
+
+func TestRoutingRuleLabelsAndContinue(t *testing.T) {
+	routing := &RoutingConfig{
+		Rules: []RoutingRule{
+			{Labels: map[string]string{"team": "sre"}, Channels: []string{"slack-sre"}, Continue: true},
+			{Severities: []AlertSeverity{SeverityCritical}, Channels: []string{"pagerduty", "slack-sre"}},
+		},
+		DefaultChannels: []string{"slack-alerts"},
+	}
+
+	alert := &Alert{Severity: SeverityCritical, Labels: map[string]string{"team": "sre"}}
+	assert.Equal(t, []string{"slack-sre", "pagerduty"}, routing.Route(alert))
+
+	alert = &Alert{Severity: SeverityWarning, Labels: map[string]string{"team": "finance"}}
+	assert.Equal(t, []string{"slack-alerts"}, routing.Route(alert))
+}
@@ -0,0 +1,123 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Silence mutes an alert until a time
+type Silence struct {
+	AlertID string    `json:"alert_id"`
+	Until   time.Time `json:"until"`
+}
+
+// SilenceStore persists silences so they survive a restart
+type SilenceStore interface {
+	LoadSilences() ([]Silence, error)
+	SaveSilence(silence Silence) error
+	DeleteSilence(alertID string) error
+}
+
+// FileSilenceStore keeps silences in a JSON file
+type FileSilenceStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSilenceStore stores silences at path, which is created on the first
+// silence
+func NewFileSilenceStore(path string) *FileSilenceStore {
+	return &FileSilenceStore{path: path}
+}
+
+func (s *FileSilenceStore) LoadSilences() ([]Silence, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	silences, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Silence, 0, len(silences))
+	for id, until := range silences {
+		list = append(list, Silence{AlertID: id, Until: until})
+	}
+	return list, nil
+}
+
+func (s *FileSilenceStore) SaveSilence(silence Silence) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	silences, err := s.read()
+	if err != nil {
+		return err
+	}
+	silences[silence.AlertID] = silence.Until
+	return s.write(silences)
+}
+
+func (s *FileSilenceStore) DeleteSilence(alertID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	silences, err := s.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := silences[alertID]; !ok {
+		return nil
+	}
+	delete(silences, alertID)
+	return s.write(silences)
+}
+
+// read returns the stored silences keyed by alert ID, dropping expired ones
+func (s *FileSilenceStore) read() (map[string]time.Time, error) {
+	silences := make(map[string]time.Time)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return silences, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &silences); err != nil {
+		return nil, fmt.Errorf("invalid silence file %s: %w", s.path, err)
+	}
+
+	now := time.Now()
+	for id, until := range silences {
+		if !until.After(now) {
+			delete(silences, id)
+		}
+	}
+	return silences, nil
+}
+
+// write replaces the file atomically, so a crash can't leave it truncated
+func (s *FileSilenceStore) write(silences map[string]time.Time) error {
+	data, err := json.MarshalIndent(silences, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
@@ -0,0 +1,148 @@
+package monitoring
+
+import (
+	"context"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func silenceTestRule() *AlertRule {
+	return &AlertRule{
+		ID: "high-cpu-usage", Name: "High CPU Usage", Type: AlertTypePerformance, Severity: SeverityWarning,
+		Threshold: Threshold{Operator: ">", Value: 80},
+		Query:     "avg(cpu_usage)",
+		Enabled:   true,
+	}
+}
+
+func TestSilence_SurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "silences.json")
+	queries := &stubEvaluator{value: 95}
+	ctx := context.Background()
+
+	am := NewAlertManager(log.New(io.Discard, "", 0), queries, NewFileSilenceStore(path))
+	am.AddRule(silenceTestRule())
+	require.NoError(t, am.EvaluateRules(ctx))
+	active := am.GetActiveAlerts()
+	require.Len(t, active, 1)
+	alertID := active[0].ID
+	require.NoError(t, am.SilenceAlert(alertID, time.Hour))
+	assert.Empty(t, am.GetActiveAlerts())
+
+	// A restarted manager loads the silence and doesn't fire the alert again
+	restarted := NewAlertManager(log.New(io.Discard, "", 0), queries, NewFileSilenceStore(path))
+	restarted.AddRule(silenceTestRule())
+	require.NoError(t, restarted.EvaluateRules(ctx))
+	assert.Empty(t, restarted.GetAlerts())
+
+	silences := restarted.GetSilences()
+	require.Len(t, silences, 1)
+	assert.Equal(t, alertID, silences[0].AlertID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), silences[0].Until, time.Minute)
+
+	// Removing the silence lets the alert fire, and the removal is persisted
+	require.NoError(t, restarted.RemoveSilence(alertID))
+	require.NoError(t, restarted.EvaluateRules(ctx))
+	assert.Len(t, restarted.GetActiveAlerts(), 1)
+
+	stored, err := NewFileSilenceStore(path).LoadSilences()
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+}
+
+func TestSilence_RemoveReactivatesFiringAlert(t *testing.T) {
+	queries := &stubEvaluator{value: 95}
+	ctx := context.Background()
+
+	am := NewAlertManager(log.New(io.Discard, "", 0), queries, nil)
+	am.AddRule(silenceTestRule())
+	require.NoError(t, am.EvaluateRules(ctx))
+	alertID := am.GetActiveAlerts()[0].ID
+
+	require.NoError(t, am.SilenceAlert(alertID, time.Hour))
+	require.NoError(t, am.EvaluateRules(ctx))
+	assert.Empty(t, am.GetActiveAlerts())
+
+	require.NoError(t, am.RemoveSilence(alertID))
+	active := am.GetActiveAlerts()
+	require.Len(t, active, 1)
+	assert.Nil(t, active[0].SilencedUntil)
+
+	assert.Error(t, am.RemoveSilence(alertID))
+}
+
+func TestSilence_SilencedAlertResolvesQuietly(t *testing.T) {
+	queries := &stubEvaluator{value: 95}
+	ctx := context.Background()
+
+	am := NewAlertManager(log.New(io.Discard, "", 0), queries, nil)
+	am.AddRule(silenceTestRule())
+	require.NoError(t, am.EvaluateRules(ctx))
+	alertID := am.GetActiveAlerts()[0].ID
+	require.NoError(t, am.SilenceAlert(alertID, time.Hour))
+
+	queries.value = 10
+	require.NoError(t, am.EvaluateRules(ctx))
+	alerts := am.GetAlerts()
+	require.Len(t, alerts, 1)
+	assert.Equal(t, StatusResolved, alerts[0].Status)
+}
+
+func TestAlertListener_FiresOncePerBreach(t *testing.T) {
+	queries := &stubEvaluator{value: 95}
+	ctx := context.Background()
+
+	am := NewAlertManager(log.New(io.Discard, "", 0), queries, nil)
+	var fired []Alert
+	am.SetAlertListener(func(alert Alert) { fired = append(fired, alert) })
+	am.AddRule(silenceTestRule())
+
+	require.NoError(t, am.EvaluateRules(ctx))
+	require.NoError(t, am.EvaluateRules(ctx))
+	require.Len(t, fired, 1, "a breach that continues does not fire again")
+	assert.Equal(t, StatusActive, fired[0].Status)
+
+	// A silenced breach is quiet until the silence ends
+	alertID := fired[0].ID
+	require.NoError(t, am.SilenceAlert(alertID, time.Hour))
+	require.NoError(t, am.EvaluateRules(ctx))
+	assert.Len(t, fired, 1)
+
+	queries.value = 10
+	require.NoError(t, am.EvaluateRules(ctx))
+	assert.Len(t, fired, 1, "resolutions are not reported")
+
+	am.BudgetAlertHook(50)(51.25)
+	require.Len(t, fired, 2)
+	assert.Equal(t, BudgetAlertID, fired[1].ID)
+}
+
+func TestFileSilenceStore(t *testing.T) {
+	store := NewFileSilenceStore(filepath.Join(t.TempDir(), "silences.json"))
+
+	silences, err := store.LoadSilences()
+	require.NoError(t, err)
+	assert.Empty(t, silences)
+
+	until := time.Now().Add(time.Hour).Truncate(time.Second)
+	require.NoError(t, store.SaveSilence(Silence{AlertID: "a", Until: until}))
+	require.NoError(t, store.SaveSilence(Silence{AlertID: "expired", Until: time.Now().Add(-time.Minute)}))
+
+	silences, err = store.LoadSilences()
+	require.NoError(t, err)
+	require.Len(t, silences, 1)
+	assert.Equal(t, "a", silences[0].AlertID)
+	assert.True(t, until.Equal(silences[0].Until))
+
+	require.NoError(t, store.DeleteSilence("a"))
+	require.NoError(t, store.DeleteSilence("missing"))
+	silences, err = store.LoadSilences()
+	require.NoError(t, err)
+	assert.Empty(t, silences)
+}
@@ -0,0 +1,178 @@
+package monitoring
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ApprovalAnnotation is the Alert.Annotations key holding an *ApprovalPreview
+const ApprovalAnnotation = "approval"
+
+// ApprovalPreview is what an approver needs to decide on a queued action:
+// the planned change, the money involved and why the AI proposed it
+type ApprovalPreview struct {
+	ActionID       string   `json:"action_id"`
+	ResourceID     string   `json:"resource_id"`
+	ActionType     string   `json:"action_type"`
+	CurrentType    string   `json:"current_type,omitempty"`
+	ProposedType   string   `json:"proposed_type,omitempty"`
+	MonthlySavings float64  `json:"estimated_monthly_savings"`
+	RiskScore      float64  `json:"risk_score"`
+	Confidence     float64  `json:"confidence"`
+	Model          string   `json:"model,omitempty"`
+	Reasoning      []string `json:"reasoning,omitempty"` // top reasoning bullets
+}
+
+// Change describes the planned change, e.g. "m5.2xlarge → m5.large"
+func (p *ApprovalPreview) Change() string {
+	switch {
+	case p.CurrentType != "" && p.ProposedType != "":
+		return p.CurrentType + " → " + p.ProposedType
+	case p.ProposedType != "":
+		return "→ " + p.ProposedType
+	default:
+		return p.ActionType
+	}
+}
+
+// NewApprovalAlert builds the notification for an action awaiting approval
+func NewApprovalAlert(preview *ApprovalPreview) *Alert {
+	return &Alert{
+		ID:       "approval-" + preview.ActionID,
+		Type:     AlertTypeOptimization,
+		Severity: SeverityInfo,
+		Status:   StatusActive,
+		Title: fmt.Sprintf("Approval needed: %s %s (saves $%.2f/mo)",
+			preview.ActionType, preview.ResourceID, preview.MonthlySavings),
+		Description: fmt.Sprintf("%s on %s, risk %.1f, confidence %.0f%%",
+			preview.Change(), preview.ResourceID, preview.RiskScore, preview.Confidence*100),
+		EntityID:    preview.ResourceID,
+		EntityType:  "resource",
+		Timestamp:   time.Now(),
+		Labels:      map[string]string{"kind": "approval", "action_type": preview.ActionType},
+		Annotations: map[string]interface{}{ApprovalAnnotation: preview},
+		Current:     preview.MonthlySavings,
+	}
+}
+
+// BudgetAlertID identifies the AI spend budget alert
+const BudgetAlertID = "ai-budget"
+
+// NewBudgetAlert builds the notification for AI spend reaching its budget
+func NewBudgetAlert(currentUSD, thresholdUSD float64) *Alert {
+	return &Alert{
+		ID:          BudgetAlertID,
+		Type:        AlertTypeCost,
+		Severity:    SeverityWarning,
+		Status:      StatusActive,
+		Title:       fmt.Sprintf("AI spend reached its $%.2f budget", thresholdUSD),
+		Description: fmt.Sprintf("AI token cost this budget period is $%.2f", currentUSD),
+		EntityType:  "ai_budget",
+		Timestamp:   time.Now(),
+		Labels:      map[string]string{"kind": "budget"},
+		Threshold:   &Threshold{Metric: "total_cost_usd", Operator: ">=", Value: thresholdUSD},
+		Current:     currentUSD,
+	}
+}
+
+// approvalPreview returns the preview attached to an alert, if any
+func approvalPreview(alert *Alert) *ApprovalPreview {
+	preview, _ := alert.Annotations[ApprovalAnnotation].(*ApprovalPreview)
+	return preview
+}
+
+// notificationData is the value templates are executed against
+type notificationData struct {
+	Alert    *Alert
+	Approval *ApprovalPreview
+}
+
+// NotificationTemplates renders alerts into per-channel-type messages
+type NotificationTemplates struct {
+	templates map[string]*template.Template
+	fallback  *template.Template
+}
+
+var templateFuncs = template.FuncMap{
+	"money": func(v float64) string { return fmt.Sprintf("$%.2f", v) },
+	"pct":   func(v float64) string { return fmt.Sprintf("%.0f%%", v*100) },
+	"upper": strings.ToUpper,
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// defaultTemplates are keyed by NotificationChannel.Type
+var defaultTemplates = map[string]string{
+	"slack": `{{if .Approval}}{{with .Approval}}:raised_hand: *Approval needed* for ` + "`{{.ResourceID}}`" + `
+*Plan:* {{.ActionType}} ({{.Change}})
+*Estimated savings:* {{money .MonthlySavings}}/month
+*Risk:* {{printf "%.1f" .RiskScore}}  *Confidence:* {{pct .Confidence}}{{if .Model}} ({{.Model}}){{end}}
+{{range .Reasoning}}• {{.}}
+{{end}}{{end}}{{else}}*[{{upper (print .Alert.Severity)}}]* {{.Alert.Title}}
+{{.Alert.Description}}{{end}}`,
+
+	"email": `{{if .Approval}}{{with .Approval}}Subject: [Talos] Approval needed: {{.ActionType}} {{.ResourceID}} ({{money .MonthlySavings}}/mo)
+
+Action:             {{.ActionID}}
+Resource:           {{.ResourceID}}
+Planned change:     {{.ActionType}} ({{.Change}})
+Estimated savings:  {{money .MonthlySavings}} per month
+Risk score:         {{printf "%.1f" .RiskScore}}
+Confidence:         {{pct .Confidence}}{{if .Model}} ({{.Model}}){{end}}
+{{if .Reasoning}}
+Why:
+{{range .Reasoning}}  - {{.}}
+{{end}}{{end}}{{end}}{{else}}Subject: [Talos] [{{upper (print .Alert.Severity)}}] {{.Alert.Title}}
+
+{{.Alert.Description}}
+{{end}}`,
+
+	"pagerduty": `{{.Alert.Title}}{{with .Approval}} - {{.Change}}, risk {{printf "%.1f" .RiskScore}}{{end}}`,
+
+	"webhook": `{"alert":{{json .Alert}}{{if .Approval}},"approval":{{json .Approval}}{{end}}}`,
+}
+
+const fallbackTemplate = `[{{upper (print .Alert.Severity)}}] {{.Alert.Title}}: {{.Alert.Description}}`
+
+// DefaultNotificationTemplates returns the built-in templates for every channel type
+func DefaultNotificationTemplates() *NotificationTemplates {
+	t := &NotificationTemplates{
+		templates: make(map[string]*template.Template),
+		fallback:  template.Must(template.New("fallback").Funcs(templateFuncs).Parse(fallbackTemplate)),
+	}
+	for channelType, text := range defaultTemplates {
+		t.templates[channelType] = template.Must(template.New(channelType).Funcs(templateFuncs).Parse(text))
+	}
+	return t
+}
+
+// Set overrides the template for a channel type
+func (t *NotificationTemplates) Set(channelType, text string) error {
+	tmpl, err := template.New(channelType).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid %s notification template: %w", channelType, err)
+	}
+	t.templates[channelType] = tmpl
+	return nil
+}
+
+// Render formats an alert for a channel type, using the fallback template
+// for types without their own
+func (t *NotificationTemplates) Render(channelType string, alert *Alert) (string, error) {
+	tmpl, ok := t.templates[channelType]
+	if !ok {
+		tmpl = t.fallback
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, notificationData{Alert: alert, Approval: approvalPreview(alert)}); err != nil {
+		return "", fmt.Errorf("failed to render %s notification: %w", channelType, err)
+	}
+	return buf.String(), nil
+}
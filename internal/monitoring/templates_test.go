@@ -0,0 +1,84 @@
+package monitoring
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderApprovalAlert(t *testing.T) {
+	alert := NewApprovalAlert(&ApprovalPreview{
+		ActionID:       "act-1",
+		ResourceID:     "i-0abc",
+		ActionType:     "optimize",
+		CurrentType:    "m5.2xlarge",
+		ProposedType:   "m5.large",
+		MonthlySavings: 212.5,
+		RiskScore:      2.5,
+		Confidence:     0.87,
+		Model:          "claude-3-5-sonnet",
+		Reasoning:      []string{"CPU under 10% for 14 days", "Memory under 30%"},
+	})
+	templates := DefaultNotificationTemplates()
+
+	for _, channelType := range []string{"slack", "email"} {
+		t.Run(channelType, func(t *testing.T) {
+			message, err := templates.Render(channelType, alert)
+			require.NoError(t, err)
+			assert.Contains(t, message, "$212.50")
+			assert.Contains(t, message, "m5.2xlarge → m5.large")
+			assert.Contains(t, message, "87%")
+			assert.Contains(t, message, "CPU under 10% for 14 days")
+			assert.Contains(t, message, "Memory under 30%")
+		})
+	}
+
+	t.Run("webhook", func(t *testing.T) {
+		message, err := templates.Render("webhook", alert)
+		require.NoError(t, err)
+
+		var body struct {
+			Approval ApprovalPreview `json:"approval"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(message), &body))
+		assert.Equal(t, 212.5, body.Approval.MonthlySavings)
+		assert.Equal(t, "m5.large", body.Approval.ProposedType)
+	})
+}
+
+func TestRenderFallsBackForPlainAlerts(t *testing.T) {
+	templates := DefaultNotificationTemplates()
+	alert := &Alert{Severity: SeverityWarning, Title: "High CPU", Description: "web-01 at 95%"}
+
+	message, err := templates.Render("slack", alert)
+	require.NoError(t, err)
+	assert.Contains(t, message, "[WARNING]")
+	assert.Contains(t, message, "High CPU")
+
+	message, err = templates.Render("sms", alert)
+	require.NoError(t, err)
+	assert.Equal(t, "[WARNING] High CPU: web-01 at 95%", message)
+
+	require.NoError(t, templates.Set("sms", "{{.Alert.Title}}"))
+	message, err = templates.Render("sms", alert)
+	require.NoError(t, err)
+	assert.Equal(t, "High CPU", message)
+
+	assert.Error(t, templates.Set("sms", "{{.Alert.Title"))
+}
+
+func TestBudgetAlertHook_RaisesCostAlert(t *testing.T) {
+	am := NewAlertManager(log.New(io.Discard, "", 0), nil, nil)
+	am.BudgetAlertHook(50)(51.25)
+
+	active := am.GetActiveAlerts()
+	require.Len(t, active, 1)
+	assert.Equal(t, BudgetAlertID, active[0].ID)
+	assert.Equal(t, AlertTypeCost, active[0].Type)
+	assert.Equal(t, 51.25, active[0].Current)
+	assert.Equal(t, 50.0, active[0].Threshold.Value)
+}
@@ -13,6 +13,7 @@ type Ledger interface {
 	MarkFailed(ctx context.Context, actionID string, errorMsg string) error
 	GetActionByChecksum(ctx context.Context, checksum string) (*Action, error)
 	GetStats(ctx context.Context) (map[string]int, error)
+	HealthCheck(ctx context.Context) error
 	Close()
 }
 
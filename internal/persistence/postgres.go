@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/Xover-Official/Xover/internal/config"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -14,19 +15,91 @@ type PostgresLedger struct {
 	pool *pgxpool.Pool
 }
 
-// NewPostgresLedger creates a new PostgreSQL-backed ledger
+// PostgresConfig tunes the ledger's connection pool. Zero fields keep the
+// pgxpool defaults.
+type PostgresConfig struct {
+	MaxConns        int32
+	MinConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+}
+
+// PostgresConfigFrom takes the pool settings from the database section of
+// the application config
+func PostgresConfigFrom(db config.DatabaseConfig) PostgresConfig {
+	return PostgresConfig{
+		MaxConns:        int32(db.MaxConns),
+		MinConns:        int32(db.MinConns),
+		MaxConnLifetime: db.MaxConnLifetime,
+		MaxConnIdleTime: db.MaxConnIdleTime,
+	}
+}
+
+// healthCheckTimeout bounds HealthCheck so a hung database fails the probe
+// instead of stalling it
+const healthCheckTimeout = 2 * time.Second
+
+// NewPostgresLedger creates a new PostgreSQL-backed ledger with the default pool settings
 func NewPostgresLedger(connString string) (*PostgresLedger, error) {
-	pool, err := pgxpool.New(context.Background(), connString)
+	return NewPostgresLedgerWithConfig(connString, PostgresConfig{})
+}
+
+// NewPostgresLedgerWithConfig creates a new PostgreSQL-backed ledger whose
+// pool is tuned by cfg
+func NewPostgresLedgerWithConfig(connString string, cfg PostgresConfig) (*PostgresLedger, error) {
+	poolCfg, err := poolConfig(connString, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
 
-	// Test connection
-	if err := pool.Ping(context.Background()); err != nil {
+	ledger := &PostgresLedger{pool: pool}
+	if err := ledger.HealthCheck(context.Background()); err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return &PostgresLedger{pool: pool}, nil
+	return ledger, nil
+}
+
+// poolConfig parses connString and applies the non-zero fields of cfg
+func poolConfig(connString string, cfg PostgresConfig) (*pgxpool.Config, error) {
+	poolCfg, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse connection string: %w", err)
+	}
+	if cfg.MaxConns > 0 {
+		poolCfg.MaxConns = cfg.MaxConns
+	}
+	if cfg.MinConns > 0 {
+		poolCfg.MinConns = cfg.MinConns
+	}
+	if cfg.MaxConnLifetime > 0 {
+		poolCfg.MaxConnLifetime = cfg.MaxConnLifetime
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		poolCfg.MaxConnIdleTime = cfg.MaxConnIdleTime
+	}
+	if poolCfg.MinConns > poolCfg.MaxConns {
+		return nil, fmt.Errorf("min conns (%d) exceeds max conns (%d)", poolCfg.MinConns, poolCfg.MaxConns)
+	}
+	return poolCfg, nil
+}
+
+// HealthCheck runs a trivial query to confirm the database is reachable
+func (p *PostgresLedger) HealthCheck(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	var one int
+	if err := p.pool.QueryRow(ctx, "SELECT 1").Scan(&one); err != nil {
+		return fmt.Errorf("ledger health check failed: %w", err)
+	}
+	return nil
 }
 
 // RecordAction records a new action in the ledger
@@ -0,0 +1,67 @@
+package persistence
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConnString = "postgres://talos@127.0.0.1:1/talos?sslmode=disable"
+
+func TestPoolConfig_AppliesSettings(t *testing.T) {
+	cfg, err := poolConfig(testConnString, PostgresConfig{
+		MaxConns:        20,
+		MinConns:        2,
+		MaxConnLifetime: 45 * time.Minute,
+		MaxConnIdleTime: 5 * time.Minute,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(20), cfg.MaxConns)
+	assert.Equal(t, int32(2), cfg.MinConns)
+	assert.Equal(t, 45*time.Minute, cfg.MaxConnLifetime)
+	assert.Equal(t, 5*time.Minute, cfg.MaxConnIdleTime)
+}
+
+func TestPoolConfig_ZeroKeepsDefaults(t *testing.T) {
+	defaults, err := pgxpool.ParseConfig(testConnString)
+	require.NoError(t, err)
+
+	cfg, err := poolConfig(testConnString, PostgresConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, defaults.MaxConns, cfg.MaxConns)
+	assert.Equal(t, defaults.MinConns, cfg.MinConns)
+	assert.Equal(t, defaults.MaxConnLifetime, cfg.MaxConnLifetime)
+	assert.Equal(t, defaults.MaxConnIdleTime, cfg.MaxConnIdleTime)
+}
+
+func TestPoolConfig_RejectsMinAboveMax(t *testing.T) {
+	_, err := poolConfig(testConnString, PostgresConfig{MaxConns: 2, MinConns: 5})
+	assert.Error(t, err)
+}
+
+func TestPostgresLedger_HealthCheckFailsFastOnClosedPool(t *testing.T) {
+	cfg, err := poolConfig(testConnString, PostgresConfig{})
+	require.NoError(t, err)
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	require.NoError(t, err)
+	ledger := &PostgresLedger{pool: pool}
+	ledger.Close()
+
+	start := time.Now()
+	err = ledger.HealthCheck(context.Background())
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), healthCheckTimeout)
+}
+
+func TestSQLiteLedger_HealthCheck(t *testing.T) {
+	ledger, err := NewSQLiteLedger(t.TempDir() + "/talos.db")
+	require.NoError(t, err)
+
+	assert.NoError(t, ledger.HealthCheck(context.Background()))
+	ledger.Close()
+	assert.Error(t, ledger.HealthCheck(context.Background()))
+}
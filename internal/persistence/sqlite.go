@@ -245,6 +245,62 @@ func (s *SQLiteLedger) GetStats(ctx context.Context) (map[string]int, error) {
 	}, nil
 }
 
+// CountBefore counts the finished actions created before the cutoff. The
+// actions table is the only table the ledger can purge; pending actions are
+// never purged since recovery depends on them.
+func (s *SQLiteLedger) CountBefore(ctx context.Context, table string, before time.Time) (int64, error) {
+	if table != "actions" {
+		return 0, fmt.Errorf("table %q does not support retention purges", table)
+	}
+
+	var count int64
+	query := `SELECT COUNT(*) FROM actions WHERE created_at < ? AND status IN ('completed', 'failed')`
+	if err := s.db.QueryRowContext(ctx, query, before).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count actions: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteBatchBefore deletes up to limit finished actions created before the
+// cutoff and returns how many were deleted
+func (s *SQLiteLedger) DeleteBatchBefore(ctx context.Context, table string, before time.Time, limit int) (int64, error) {
+	if table != "actions" {
+		return 0, fmt.Errorf("table %q does not support retention purges", table)
+	}
+
+	query := `
+		DELETE FROM actions WHERE id IN (
+			SELECT id FROM actions WHERE created_at < ? AND status IN ('completed', 'failed') LIMIT ?
+		)
+	`
+	result, err := s.db.ExecContext(ctx, query, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge actions: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Compact returns the space freed by purges to the filesystem; SQLite files
+// never shrink on their own
+func (s *SQLiteLedger) Compact(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, "VACUUM"); err != nil {
+		return fmt.Errorf("failed to vacuum ledger: %w", err)
+	}
+	return nil
+}
+
+// HealthCheck runs a trivial query to confirm the database is usable
+func (s *SQLiteLedger) HealthCheck(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	var one int
+	if err := s.db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return fmt.Errorf("ledger health check failed: %w", err)
+	}
+	return nil
+}
+
 // Close closes the database connection
 func (s *SQLiteLedger) Close() {
 	s.db.Close()
@@ -0,0 +1,180 @@
+// Copyright (c) 2026 Project Atlas (Talos)
+// Licensed under the MIT License. See LICENSE in the project root for license information.
+
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/database"
+)
+
+// Format is the output format of a generated report
+type Format string
+
+const (
+	FormatHTML Format = "html"
+	FormatPDF  Format = "pdf"
+)
+
+// Source provides the aggregated data a savings report is built from
+type Source interface {
+	GetSavingsReport(ctx context.Context, orgID string, start, end time.Time, topN int) (*database.SavingsReport, error)
+}
+
+// Generator renders "here's what Talos saved you" reports
+type Generator struct {
+	source  Source
+	tmpl    *template.Template
+	pdfTool string
+	topN    int
+}
+
+// NewGenerator creates a report generator backed by the given source
+func NewGenerator(source Source) *Generator {
+	funcs := template.FuncMap{
+		"usd":     func(v float64) string { return fmt.Sprintf("$%.2f", v) },
+		"percent": func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+		"mul100":  func(v float64) float64 { return v * 100 },
+		"date":    func(t time.Time) string { return t.Format("Jan 2, 2006") },
+	}
+
+	return &Generator{
+		source:  source,
+		tmpl:    template.Must(template.New("report").Funcs(funcs).Parse(reportTemplate)),
+		pdfTool: "wkhtmltopdf",
+		topN:    10,
+	}
+}
+
+// PeriodRange resolves a named period ("week", "month", "quarter", "year")
+// to the previous full calendar period relative to now
+func PeriodRange(period string, now time.Time) (time.Time, time.Time, error) {
+	now = now.UTC()
+	switch strings.ToLower(period) {
+	case "", "month":
+		end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return end.AddDate(0, -1, 0), end, nil
+	case "week":
+		end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		end = end.AddDate(0, 0, -int(end.Weekday()))
+		return end.AddDate(0, 0, -7), end, nil
+	case "quarter":
+		firstMonth := time.Month((int(now.Month())-1)/3*3 + 1)
+		end := time.Date(now.Year(), firstMonth, 1, 0, 0, 0, 0, time.UTC)
+		return end.AddDate(0, -3, 0), end, nil
+	case "year":
+		end := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+		return end.AddDate(-1, 0, 0), end, nil
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("unknown report period: %s", period)
+	}
+}
+
+// Generate builds an organization's report for [start, end) and writes it
+// in the requested format
+func (g *Generator) Generate(ctx context.Context, orgID string, start, end time.Time, format Format, w io.Writer) error {
+	data, err := g.source.GetSavingsReport(ctx, orgID, start, end, g.topN)
+	if err != nil {
+		return fmt.Errorf("failed to load report data: %w", err)
+	}
+
+	switch format {
+	case FormatHTML, "":
+		return g.RenderHTML(w, data)
+	case FormatPDF:
+		var html bytes.Buffer
+		if err := g.RenderHTML(&html, data); err != nil {
+			return err
+		}
+		return g.renderPDF(ctx, &html, w)
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+}
+
+// RenderHTML renders report data as a standalone HTML document
+func (g *Generator) RenderHTML(w io.Writer, data *database.SavingsReport) error {
+	if err := g.tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+// renderPDF converts rendered HTML to PDF using wkhtmltopdf
+func (g *Generator) renderPDF(ctx context.Context, html io.Reader, w io.Writer) error {
+	path, err := exec.LookPath(g.pdfTool)
+	if err != nil {
+		return fmt.Errorf("PDF output requires %s on PATH: %w", g.pdfTool, err)
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path, "--quiet", "-", "-")
+	cmd.Stdin = html
+	cmd.Stdout = w
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("PDF conversion failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+const reportTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Talos Savings Report {{date .PeriodStart}} - {{date .PeriodEnd}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; color: #1f2933; margin: 40px; }
+h1 { margin-bottom: 4px; }
+.period { color: #616e7c; margin-top: 0; }
+.cards { display: flex; gap: 16px; margin: 24px 0; }
+.card { flex: 1; border: 1px solid #e4e7eb; border-radius: 8px; padding: 16px; }
+.card .label { color: #616e7c; font-size: 12px; text-transform: uppercase; }
+.card .value { font-size: 24px; font-weight: 600; margin-top: 4px; }
+table { width: 100%; border-collapse: collapse; }
+th, td { text-align: left; padding: 8px; border-bottom: 1px solid #e4e7eb; }
+th { background: #f5f7fa; }
+td.num { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Here's what Talos saved you</h1>
+<p class="period">{{date .PeriodStart}} &ndash; {{date .PeriodEnd}}</p>
+
+<div class="cards">
+  <div class="card"><div class="label">Realized savings</div><div class="value">{{usd .TotalRealizedSavings}}</div></div>
+  <div class="card"><div class="label">AI cost</div><div class="value">{{usd .TotalAICost}}</div></div>
+  <div class="card"><div class="label">Net ROI</div><div class="value">{{usd .NetROI}}</div></div>
+  <div class="card"><div class="label">ROI</div><div class="value">{{percent .ROIPercentage}}</div></div>
+</div>
+
+<h2>Actions</h2>
+<table>
+  <tr><th>Total</th><th>Completed</th><th>Failed</th><th>Success rate</th></tr>
+  <tr><td>{{.ActionsTotal}}</td><td>{{.ActionsCompleted}}</td><td>{{.ActionsFailed}}</td><td>{{percent (mul100 .SuccessRate)}}</td></tr>
+</table>
+
+<h2>Top optimizations</h2>
+{{if .TopOptimizations}}
+<table>
+  <tr><th>Resource</th><th>Type</th><th>Estimated</th><th>Realized</th><th>Date</th></tr>
+  {{range .TopOptimizations}}
+  <tr><td>{{.ResourceID}}</td><td>{{.OptimizationType}}</td><td class="num">{{usd .EstimatedSavings}}</td><td class="num">{{usd .ActualSavings}}</td><td>{{date .CreatedAt}}</td></tr>
+  {{end}}
+</table>
+{{else}}
+<p>No optimizations were recorded in this period.</p>
+{{end}}
+
+<p class="period">Estimated savings for the period: {{usd .TotalEstimatedSavings}} &middot; Tokens used: {{.TotalTokens}}</p>
+</body>
+</html>
+`
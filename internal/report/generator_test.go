@@ -0,0 +1,104 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodRange(t *testing.T) {
+	now := time.Date(2026, 5, 14, 15, 30, 0, 0, time.UTC) // a Thursday
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		period     string
+		start, end time.Time
+	}{
+		{"", day(2026, 4, 1), day(2026, 5, 1)},
+		{"month", day(2026, 4, 1), day(2026, 5, 1)},
+		{"Week", day(2026, 5, 3), day(2026, 5, 10)},
+		{"quarter", day(2026, 1, 1), day(2026, 4, 1)},
+		{"year", day(2025, 1, 1), day(2026, 1, 1)},
+	}
+	for _, tt := range tests {
+		start, end, err := PeriodRange(tt.period, now)
+		require.NoError(t, err, tt.period)
+		assert.Equal(t, tt.start, start, tt.period)
+		assert.Equal(t, tt.end, end, tt.period)
+	}
+
+	_, _, err := PeriodRange("fortnight", now)
+	assert.ErrorContains(t, err, "unknown report period")
+}
+
+// fixedSource returns a copy of report for any period, or err
+type fixedSource struct {
+	report database.SavingsReport
+	err    error
+}
+
+func (f fixedSource) GetSavingsReport(_ context.Context, _ string, start, end time.Time, _ int) (*database.SavingsReport, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	report := f.report
+	report.PeriodStart, report.PeriodEnd = start, end
+	return &report, nil
+}
+
+func TestGenerator_RendersReportFigures(t *testing.T) {
+	start, end := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	source := fixedSource{report: database.SavingsReport{
+		TotalRealizedSavings: 1250.5,
+		TotalAICost:          342.75,
+		NetROI:               907.75,
+		ROIPercentage:        264.84,
+		ActionsTotal:         12,
+		ActionsCompleted:     8,
+		ActionsFailed:        2,
+		SuccessRate:          0.8,
+		TopOptimizations: []*database.TopOptimization{
+			{ResourceID: "i-0abc", OptimizationType: "resize", ActualSavings: 410, EstimatedSavings: 450, CreatedAt: start.AddDate(0, 0, 3)},
+		},
+	}}
+
+	var out bytes.Buffer
+	require.NoError(t, NewGenerator(source).Generate(context.Background(), "", start, end, FormatHTML, &out))
+	html := out.String()
+	for _, want := range []string{
+		"Apr 1, 2026", "May 1, 2026",
+		"$1250.50", "$342.75", "$907.75", "264.8%",
+		"<td>12</td><td>8</td><td>2</td><td>80.0%</td>",
+		"i-0abc", "$410.00", "$450.00",
+	} {
+		assert.Contains(t, html, want)
+	}
+	assert.NotContains(t, html, "No optimizations were recorded")
+
+	out.Reset()
+	require.NoError(t, NewGenerator(fixedSource{}).Generate(context.Background(), "", start, end, "", &out))
+	assert.Contains(t, out.String(), "No optimizations were recorded in this period.")
+}
+
+func TestGenerator_Errors(t *testing.T) {
+	start, end := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	var out bytes.Buffer
+
+	err := NewGenerator(fixedSource{err: errors.New("connection refused")}).Generate(context.Background(), "", start, end, FormatHTML, &out)
+	assert.ErrorContains(t, err, "failed to load report data: connection refused")
+
+	err = NewGenerator(fixedSource{}).Generate(context.Background(), "", start, end, "docx", &out)
+	assert.ErrorContains(t, err, "unsupported report format: docx")
+
+	generator := NewGenerator(fixedSource{})
+	generator.pdfTool = "talos-missing-pdf-tool"
+	err = generator.Generate(context.Background(), "", start, end, FormatPDF, &out)
+	assert.ErrorContains(t, err, "PDF output requires talos-missing-pdf-tool on PATH")
+	assert.Empty(t, out.String(), "nothing is written when the conversion cannot run")
+}
@@ -0,0 +1,257 @@
+// Copyright (c) 2026 Project Atlas (Talos)
+// Licensed under the MIT License. See LICENSE in the project root for license information.
+
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/monitoring"
+	"github.com/Xover-Official/Xover/internal/scheduler"
+	"go.uber.org/zap"
+)
+
+// Schedule mails the savings report for the previous full Period to
+// Recipients each time Cron fires, e.g. "0 8 1 * *" with period "month"
+// sends last month's report at 08:00 UTC on the 1st
+type Schedule struct {
+	Name       string
+	OrgID      string // organization reported on; "" for actions of none
+	Cron       string
+	Period     string // week, month, quarter or year
+	Format     Format
+	Recipients []string
+	Subject    string // defaults to "Talos savings report: <start> - <end>"
+	LinkURL    string // optional dashboard link included in the email
+}
+
+// DeliveryLog records which periods each schedule has sent, so a report is
+// mailed at most once per period even across restarts and replicas
+type DeliveryLog interface {
+	ClaimReportDelivery(ctx context.Context, schedule string, start, end time.Time, recipients []string) (bool, error)
+	FinishReportDelivery(ctx context.Context, schedule string, start, end time.Time, sendErr error) error
+}
+
+// Scheduler delivers scheduled reports by email
+type Scheduler struct {
+	generator     *Generator
+	deliveries    DeliveryLog
+	mailer        monitoring.EmailSender
+	jobs          []*scheduledReport
+	retryInterval time.Duration
+	maxAttempts   int
+	logger        *zap.Logger
+	now           func() time.Time
+}
+
+// scheduledReport tracks the next run of one schedule. due is the cron fire
+// time being retried after a failed delivery, zero otherwise.
+type scheduledReport struct {
+	Schedule
+	cron     *scheduler.CronSchedule
+	next     time.Time
+	due      time.Time
+	attempts int
+}
+
+// NewScheduler validates the schedules and creates a scheduler sending
+// through mailer
+func NewScheduler(generator *Generator, deliveries DeliveryLog, mailer monitoring.EmailSender, schedules []Schedule, logger *zap.Logger) (*Scheduler, error) {
+	s := &Scheduler{
+		generator:     generator,
+		deliveries:    deliveries,
+		mailer:        mailer,
+		retryInterval: 15 * time.Minute,
+		maxAttempts:   3,
+		logger:        logger,
+		now:           time.Now,
+	}
+	for _, schedule := range schedules {
+		cron, err := scheduler.ParseCron(schedule.Cron)
+		if err != nil {
+			return nil, fmt.Errorf("report schedule %q: %w", schedule.Name, err)
+		}
+		if _, _, err := PeriodRange(schedule.Period, time.Now()); err != nil {
+			return nil, fmt.Errorf("report schedule %q: %w", schedule.Name, err)
+		}
+		if len(schedule.Recipients) == 0 {
+			return nil, fmt.Errorf("report schedule %q has no recipients", schedule.Name)
+		}
+		if schedule.Format == "" {
+			schedule.Format = FormatHTML
+		}
+		s.jobs = append(s.jobs, &scheduledReport{Schedule: schedule, cron: cron})
+	}
+	return s, nil
+}
+
+// SetRetry sets how often, and how many times in total, a failed delivery is
+// attempted before waiting for the next scheduled run
+func (s *Scheduler) SetRetry(interval time.Duration, maxAttempts int) {
+	if interval > 0 {
+		s.retryInterval = interval
+	}
+	if maxAttempts > 0 {
+		s.maxAttempts = maxAttempts
+	}
+}
+
+// Run delivers reports as their schedules fire until ctx is canceled. Cron
+// times are evaluated in UTC.
+func (s *Scheduler) Run(ctx context.Context) {
+	now := s.now().UTC()
+	for _, job := range s.jobs {
+		job.next = job.cron.Next(now)
+		s.logger.Info("report schedule started",
+			zap.String("schedule", job.Name),
+			zap.String("cron", job.Cron),
+			zap.Time("next_run", job.next),
+		)
+	}
+
+	for {
+		job := s.nextJob()
+		if job == nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(job.next)):
+		}
+
+		s.runJob(ctx, job)
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// nextJob returns the job that runs soonest, or nil if none will run again
+func (s *Scheduler) nextJob() *scheduledReport {
+	var next *scheduledReport
+	for _, job := range s.jobs {
+		if job.next.IsZero() {
+			continue
+		}
+		if next == nil || job.next.Before(next.next) {
+			next = job
+		}
+	}
+	return next
+}
+
+// runJob delivers the job's due report and works out when it runs next:
+// after retryInterval if delivery failed and attempts remain, otherwise at
+// the next cron time
+func (s *Scheduler) runJob(ctx context.Context, job *scheduledReport) {
+	due := job.due
+	if due.IsZero() {
+		due = job.next
+	}
+
+	_, err := s.Deliver(ctx, job.Schedule, due)
+	if err != nil && ctx.Err() == nil {
+		job.attempts++
+		if job.attempts < s.maxAttempts {
+			job.due, job.next = due, s.now().Add(s.retryInterval)
+			s.logger.Warn("scheduled report delivery failed; will retry",
+				zap.String("schedule", job.Name),
+				zap.Int("attempt", job.attempts),
+				zap.Time("retry_at", job.next),
+				zap.Error(err),
+			)
+			return
+		}
+		s.logger.Error("scheduled report delivery failed; giving up until the next run",
+			zap.String("schedule", job.Name),
+			zap.Int("attempts", job.attempts),
+			zap.Error(err),
+		)
+	}
+
+	job.due, job.attempts = time.Time{}, 0
+	job.next = job.cron.Next(s.now().UTC())
+}
+
+// Deliver sends the schedule's report for the period before at, unless it
+// was already sent. It reports whether an email was sent.
+func (s *Scheduler) Deliver(ctx context.Context, schedule Schedule, at time.Time) (bool, error) {
+	start, end, err := PeriodRange(schedule.Period, at)
+	if err != nil {
+		return false, err
+	}
+
+	claimed, err := s.deliveries.ClaimReportDelivery(ctx, schedule.Name, start, end, schedule.Recipients)
+	if err != nil {
+		return false, err
+	}
+	if !claimed {
+		s.logger.Info("scheduled report already delivered",
+			zap.String("schedule", schedule.Name),
+			zap.Time("period_start", start),
+			zap.Time("period_end", end),
+		)
+		return false, nil
+	}
+
+	sendErr := s.send(ctx, schedule, start, end)
+	if err := s.deliveries.FinishReportDelivery(ctx, schedule.Name, start, end, sendErr); err != nil {
+		s.logger.Warn("failed to record report delivery", zap.String("schedule", schedule.Name), zap.Error(err))
+	}
+	if sendErr != nil {
+		return false, sendErr
+	}
+
+	s.logger.Info("scheduled report delivered",
+		zap.String("schedule", schedule.Name),
+		zap.Time("period_start", start),
+		zap.Time("period_end", end),
+		zap.Strings("recipients", schedule.Recipients),
+	)
+	return true, nil
+}
+
+// send generates the report and mails it as an attachment
+func (s *Scheduler) send(ctx context.Context, schedule Schedule, start, end time.Time) error {
+	format := schedule.Format
+	if format == "" {
+		format = FormatHTML
+	}
+
+	var buf bytes.Buffer
+	if err := s.generator.Generate(ctx, schedule.OrgID, start, end, format, &buf); err != nil {
+		return err
+	}
+
+	period := fmt.Sprintf("%s - %s", start.Format("Jan 2, 2006"), end.Format("Jan 2, 2006"))
+	subject := schedule.Subject
+	if subject == "" {
+		subject = "Talos savings report: " + period
+	}
+	body := fmt.Sprintf("<p>The Talos savings report for %s is attached.</p>", html.EscapeString(period))
+	if schedule.LinkURL != "" {
+		body += fmt.Sprintf(`<p><a href="%s">View it in the dashboard</a></p>`, html.EscapeString(schedule.LinkURL))
+	}
+
+	contentType := "text/html; charset=utf-8"
+	if format == FormatPDF {
+		contentType = "application/pdf"
+	}
+	return s.mailer.Send(ctx, &monitoring.EmailMessage{
+		To:      schedule.Recipients,
+		Subject: subject,
+		Body:    body,
+		HTML:    true,
+		Attachments: []monitoring.EmailAttachment{{
+			Filename:    fmt.Sprintf("talos-report-%s.%s", start.Format("2006-01-02"), format),
+			ContentType: contentType,
+			Data:        buf.Bytes(),
+		}},
+	})
+}
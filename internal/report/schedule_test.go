@@ -0,0 +1,159 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/database"
+	"github.com/Xover-Official/Xover/internal/monitoring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type staticSource struct{}
+
+func (staticSource) GetSavingsReport(_ context.Context, _ string, start, end time.Time, _ int) (*database.SavingsReport, error) {
+	return &database.SavingsReport{PeriodStart: start, PeriodEnd: end, TotalRealizedSavings: 1234.5}, nil
+}
+
+// memoryDeliveryLog mirrors the report_deliveries claim semantics
+type memoryDeliveryLog struct {
+	status map[string]string
+}
+
+func (m *memoryDeliveryLog) key(schedule string, start, end time.Time) string {
+	return schedule + "|" + start.String() + "|" + end.String()
+}
+
+func (m *memoryDeliveryLog) ClaimReportDelivery(_ context.Context, schedule string, start, end time.Time, _ []string) (bool, error) {
+	key := m.key(schedule, start, end)
+	if status, ok := m.status[key]; ok && status != database.ReportDeliveryFailed {
+		return false, nil
+	}
+	m.status[key] = database.ReportDeliverySending
+	return true, nil
+}
+
+func (m *memoryDeliveryLog) FinishReportDelivery(_ context.Context, schedule string, start, end time.Time, sendErr error) error {
+	status := database.ReportDeliverySent
+	if sendErr != nil {
+		status = database.ReportDeliveryFailed
+	}
+	m.status[m.key(schedule, start, end)] = status
+	return nil
+}
+
+type fakeMailer struct {
+	sent []*monitoring.EmailMessage
+	err  error
+}
+
+func (f *fakeMailer) Send(_ context.Context, msg *monitoring.EmailMessage) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+func newTestScheduler(t *testing.T, mailer *fakeMailer) (*Scheduler, *memoryDeliveryLog) {
+	t.Helper()
+	deliveries := &memoryDeliveryLog{status: map[string]string{}}
+	s, err := NewScheduler(NewGenerator(staticSource{}), deliveries, mailer, []Schedule{{
+		Name:       "monthly-finance",
+		Cron:       "0 8 1 * *",
+		Period:     "month",
+		Recipients: []string{"finance@example.com"},
+		LinkURL:    "https://talos.example.com/api/report?period=month",
+	}}, zap.NewNop())
+	require.NoError(t, err)
+	return s, deliveries
+}
+
+func TestScheduler_DeliverSendsOncePerPeriod(t *testing.T) {
+	mailer := &fakeMailer{}
+	s, _ := newTestScheduler(t, mailer)
+	schedule := s.jobs[0].Schedule
+	fire := time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC)
+
+	sent, err := s.Deliver(context.Background(), schedule, fire)
+	require.NoError(t, err)
+	assert.True(t, sent)
+
+	// A second replica, or a restart, firing for the same period sends nothing
+	sent, err = s.Deliver(context.Background(), schedule, fire.Add(time.Minute))
+	require.NoError(t, err)
+	assert.False(t, sent)
+
+	require.Len(t, mailer.sent, 1)
+	msg := mailer.sent[0]
+	assert.Equal(t, []string{"finance@example.com"}, msg.To)
+	assert.Equal(t, "Talos savings report: Jan 1, 2026 - Feb 1, 2026", msg.Subject)
+	assert.Contains(t, msg.Body, "https://talos.example.com/api/report?period=month")
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, "talos-report-2026-01-01.html", msg.Attachments[0].Filename)
+	assert.Contains(t, string(msg.Attachments[0].Data), "$1234.50")
+}
+
+func TestScheduler_FailedDeliveryIsRetried(t *testing.T) {
+	mailer := &fakeMailer{err: errors.New("connection refused")}
+	s, deliveries := newTestScheduler(t, mailer)
+	now := time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	job := s.jobs[0]
+	job.next = now
+	s.runJob(context.Background(), job)
+
+	assert.Equal(t, 1, job.attempts)
+	assert.Equal(t, now, job.due)
+	assert.Equal(t, now.Add(15*time.Minute), job.next)
+	assert.Contains(t, deliveries.status, deliveries.key("monthly-finance", time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), now.Add(-8*time.Hour)))
+
+	// The retry fires later but still sends January's report
+	mailer.err = nil
+	now = now.Add(15 * time.Minute)
+	s.runJob(context.Background(), job)
+
+	require.Len(t, mailer.sent, 1)
+	assert.Equal(t, "talos-report-2026-01-01.html", mailer.sent[0].Attachments[0].Filename)
+	assert.Zero(t, job.attempts)
+	assert.True(t, job.due.IsZero())
+	assert.Equal(t, time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC), job.next)
+}
+
+func TestScheduler_GivesUpAfterMaxAttempts(t *testing.T) {
+	mailer := &fakeMailer{err: errors.New("connection refused")}
+	s, _ := newTestScheduler(t, mailer)
+	s.SetRetry(time.Minute, 2)
+	now := time.Date(2026, 2, 1, 8, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	job := s.jobs[0]
+	job.next = now
+	s.runJob(context.Background(), job)
+	s.runJob(context.Background(), job)
+
+	assert.Zero(t, job.attempts)
+	assert.Equal(t, time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC), job.next)
+}
+
+func TestNewScheduler_Validates(t *testing.T) {
+	valid := Schedule{Name: "s", Cron: "@monthly", Period: "month", Recipients: []string{"a@example.com"}}
+
+	for name, mutate := range map[string]func(*Schedule){
+		"bad cron":      func(s *Schedule) { s.Cron = "every month" },
+		"bad period":    func(s *Schedule) { s.Period = "fortnight" },
+		"no recipients": func(s *Schedule) { s.Recipients = nil },
+	} {
+		t.Run(name, func(t *testing.T) {
+			schedule := valid
+			mutate(&schedule)
+			_, err := NewScheduler(NewGenerator(staticSource{}), nil, nil, []Schedule{schedule}, zap.NewNop())
+			assert.Error(t, err)
+		})
+	}
+}
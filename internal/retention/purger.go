@@ -0,0 +1,163 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Xover-Official/Xover/internal/metrics"
+	"go.uber.org/zap"
+)
+
+// DefaultBatchSize is the number of rows deleted per statement when the
+// policy does not set one
+const DefaultBatchSize = 1000
+
+// Store deletes aged rows from history tables
+type Store interface {
+	// CountBefore counts the rows of table older than before
+	CountBefore(ctx context.Context, table string, before time.Time) (int64, error)
+	// DeleteBatchBefore deletes up to limit rows of table older than before
+	// and returns how many were deleted
+	DeleteBatchBefore(ctx context.Context, table string, before time.Time, limit int) (int64, error)
+}
+
+// Compacter is implemented by stores that must reclaim space after a purge,
+// such as the SQLite dev ledger
+type Compacter interface {
+	Compact(ctx context.Context) error
+}
+
+// Policy sets how long each table is kept. A zero period keeps the table
+// forever. LegalHold suspends all purging, and DryRun only counts the rows
+// that would be deleted.
+type Policy struct {
+	Periods   map[string]time.Duration
+	BatchSize int
+	DryRun    bool
+	LegalHold bool
+}
+
+// Result is the outcome of purging one table
+type Result struct {
+	Table  string    `json:"table"`
+	Cutoff time.Time `json:"cutoff"`
+	Rows   int64     `json:"rows"` // deleted, or eligible in a dry run
+	DryRun bool      `json:"dry_run"`
+}
+
+// Purger deletes rows older than their table's retention period
+type Purger struct {
+	store  Store
+	policy Policy
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewPurger creates a purger for the tables in the policy
+func NewPurger(store Store, policy Policy, logger *zap.Logger) *Purger {
+	if policy.BatchSize <= 0 {
+		policy.BatchSize = DefaultBatchSize
+	}
+	return &Purger{store: store, policy: policy, logger: logger, now: time.Now}
+}
+
+// RunOnce purges every table with a retention period, in name order. A
+// failing table is logged and the others are still purged; the first error
+// is returned along with the results so far.
+func (p *Purger) RunOnce(ctx context.Context) ([]Result, error) {
+	if p.policy.LegalHold {
+		p.logger.Info("retention purge skipped: legal hold is active")
+		return nil, nil
+	}
+
+	tables := make([]string, 0, len(p.policy.Periods))
+	for table, period := range p.policy.Periods {
+		if period > 0 {
+			tables = append(tables, table)
+		}
+	}
+	sort.Strings(tables)
+
+	var results []Result
+	var firstErr error
+	purged := false
+	for _, table := range tables {
+		result, err := p.purgeTable(ctx, table, p.now().Add(-p.policy.Periods[table]))
+		if err != nil {
+			p.logger.Error("retention purge failed", zap.String("table", table), zap.Error(err))
+			if firstErr == nil {
+				firstErr = err
+			}
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		results = append(results, result)
+		purged = purged || (!result.DryRun && result.Rows > 0)
+		p.logger.Info("retention purge complete",
+			zap.String("table", table),
+			zap.Time("cutoff", result.Cutoff),
+			zap.Int64("rows", result.Rows),
+			zap.Bool("dry_run", result.DryRun),
+		)
+	}
+
+	if compacter, ok := p.store.(Compacter); ok && purged {
+		if err := compacter.Compact(ctx); err != nil {
+			p.logger.Warn("failed to compact after retention purge", zap.Error(err))
+		}
+	}
+
+	return results, firstErr
+}
+
+// purgeTable deletes a table's rows older than cutoff in batches, so no
+// single statement holds locks for long
+func (p *Purger) purgeTable(ctx context.Context, table string, cutoff time.Time) (Result, error) {
+	result := Result{Table: table, Cutoff: cutoff, DryRun: p.policy.DryRun}
+
+	if p.policy.DryRun {
+		count, err := p.store.CountBefore(ctx, table, cutoff)
+		if err != nil {
+			return result, err
+		}
+		result.Rows = count
+		metrics.RecordRetentionEligible(table, count)
+		return result, nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("purge of %s interrupted after %d rows: %w", table, result.Rows, err)
+		}
+		deleted, err := p.store.DeleteBatchBefore(ctx, table, cutoff, p.policy.BatchSize)
+		if err != nil {
+			return result, err
+		}
+		result.Rows += deleted
+		metrics.RecordRetentionPurged(table, deleted)
+		if deleted < int64(p.policy.BatchSize) {
+			return result, nil
+		}
+	}
+}
+
+// Start runs RunOnce immediately and then every interval until ctx is done
+func (p *Purger) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		_, _ = p.RunOnce(ctx)
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// memoryStore holds row timestamps per table and records batch sizes
+type memoryStore struct {
+	rows      map[string][]time.Time
+	batches   []int64
+	fail      map[string]error
+	compacted bool
+}
+
+func (m *memoryStore) CountBefore(ctx context.Context, table string, before time.Time) (int64, error) {
+	var count int64
+	for _, t := range m.rows[table] {
+		if t.Before(before) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *memoryStore) DeleteBatchBefore(ctx context.Context, table string, before time.Time, limit int) (int64, error) {
+	if err := m.fail[table]; err != nil {
+		return 0, err
+	}
+	var kept []time.Time
+	var deleted int64
+	for _, t := range m.rows[table] {
+		if t.Before(before) && deleted < int64(limit) {
+			deleted++
+			continue
+		}
+		kept = append(kept, t)
+	}
+	m.rows[table] = kept
+	m.batches = append(m.batches, deleted)
+	return deleted, nil
+}
+
+func (m *memoryStore) Compact(ctx context.Context) error {
+	m.compacted = true
+	return nil
+}
+
+func newMemoryStore(now time.Time) *memoryStore {
+	old := now.Add(-100 * 24 * time.Hour)
+	recent := now.Add(-time.Hour)
+	return &memoryStore{
+		rows: map[string][]time.Time{
+			"token_usage": {old, old, old, old, old, recent},
+			"audit_log":   {old, recent},
+		},
+		fail: map[string]error{},
+	}
+}
+
+func newTestPurger(store Store, policy Policy, now time.Time) *Purger {
+	p := NewPurger(store, policy, zap.NewNop())
+	p.now = func() time.Time { return now }
+	return p
+}
+
+func TestPurger_DeletesInBatches(t *testing.T) {
+	now := time.Now()
+	store := newMemoryStore(now)
+	p := newTestPurger(store, Policy{
+		Periods:   map[string]time.Duration{"token_usage": 90 * 24 * time.Hour, "audit_log": 0},
+		BatchSize: 2,
+	}, now)
+
+	results, err := p.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 1, "a zero period keeps the table forever")
+	assert.Equal(t, "token_usage", results[0].Table)
+	assert.Equal(t, int64(5), results[0].Rows)
+	assert.Equal(t, []int64{2, 2, 1}, store.batches)
+	assert.Len(t, store.rows["token_usage"], 1)
+	assert.Len(t, store.rows["audit_log"], 2)
+	assert.True(t, store.compacted)
+}
+
+func TestPurger_DryRunOnlyCounts(t *testing.T) {
+	now := time.Now()
+	store := newMemoryStore(now)
+	p := newTestPurger(store, Policy{
+		Periods: map[string]time.Duration{"token_usage": 90 * 24 * time.Hour},
+		DryRun:  true,
+	}, now)
+
+	results, err := p.RunOnce(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].DryRun)
+	assert.Equal(t, int64(5), results[0].Rows)
+	assert.Len(t, store.rows["token_usage"], 6)
+	assert.False(t, store.compacted)
+}
+
+func TestPurger_LegalHoldSkipsEverything(t *testing.T) {
+	now := time.Now()
+	store := newMemoryStore(now)
+	p := newTestPurger(store, Policy{
+		Periods:   map[string]time.Duration{"token_usage": time.Hour},
+		LegalHold: true,
+	}, now)
+
+	results, err := p.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, results)
+	assert.Len(t, store.rows["token_usage"], 6)
+}
+
+func TestPurger_ContinuesPastFailingTable(t *testing.T) {
+	now := time.Now()
+	store := newMemoryStore(now)
+	store.fail["audit_log"] = errors.New("permission denied")
+	p := newTestPurger(store, Policy{
+		Periods: map[string]time.Duration{"token_usage": time.Hour, "audit_log": time.Hour},
+	}, now)
+
+	results, err := p.RunOnce(context.Background())
+	assert.Error(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "token_usage", results[0].Table)
+}
@@ -0,0 +1,50 @@
+package risk
+
+import "fmt"
+
+// RiskConfig tunes how the engine scores optimizations. Weights scale each
+// input: CPU and memory utilization into risk, and the savings into impact.
+// A result whose confidence is below ConfidenceFloor scores zero.
+type RiskConfig struct {
+	CPUWeight       float64      `json:"cpu_weight" yaml:"cpu_weight"`
+	MemoryWeight    float64      `json:"memory_weight" yaml:"memory_weight"`
+	CostWeight      float64      `json:"cost_weight" yaml:"cost_weight"`
+	Confidence      float64      `json:"confidence" yaml:"confidence"`
+	ConfidenceFloor float64      `json:"confidence_floor" yaml:"confidence_floor"`
+	Sizing          SizingPolicy `json:"sizing" yaml:"sizing"`
+}
+
+// DefaultRiskConfig weighs CPU, memory and cost equally with 0.8 confidence
+// and no floor, matching the original scoring
+func DefaultRiskConfig() RiskConfig {
+	return RiskConfig{
+		CPUWeight:    1,
+		MemoryWeight: 1,
+		CostWeight:   1,
+		Confidence:   0.8,
+		Sizing:       DefaultSizingPolicy(),
+	}
+}
+
+// Validate checks that weights are non-negative, confidences are within 0-1
+// and the sizing thresholds are percentages
+func (c RiskConfig) Validate() error {
+	for name, weight := range map[string]float64{"cpu": c.CPUWeight, "memory": c.MemoryWeight, "cost": c.CostWeight} {
+		if weight < 0 {
+			return fmt.Errorf("%s weight must not be negative, got %v", name, weight)
+		}
+	}
+	if c.CPUWeight == 0 && c.MemoryWeight == 0 {
+		return fmt.Errorf("at least one of the cpu and memory weights must be positive")
+	}
+	if c.Confidence <= 0 || c.Confidence > 1 {
+		return fmt.Errorf("confidence must be in (0, 1], got %v", c.Confidence)
+	}
+	if c.ConfidenceFloor < 0 || c.ConfidenceFloor > 1 {
+		return fmt.Errorf("confidence floor must be in [0, 1], got %v", c.ConfidenceFloor)
+	}
+	if c.Sizing.MaxCPU <= 0 || c.Sizing.MaxCPU > 100 || c.Sizing.MaxMemory <= 0 || c.Sizing.MaxMemory > 100 {
+		return fmt.Errorf("sizing thresholds must be in (0, 100], got cpu %v and memory %v", c.Sizing.MaxCPU, c.Sizing.MaxMemory)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEngine_MatchesDefaultConfig(t *testing.T) {
+	engine := NewEngine()
+	metrics := CloudMetrics{CPUUsage: 20, MemoryUsage: 50}
+
+	// The original scoring: impact / max(cpu, memory) * 0.8
+	result := engine.CalculateScore(100, metrics)
+	assert.Equal(t, 50.0, result.Risk)
+	assert.InDelta(t, 1.6, result.Score, 1e-9)
+	assert.Equal(t, DefaultSizingPolicy(), engine.Sizing)
+}
+
+func TestNewEngineWithConfig_WeightsChangeTheScore(t *testing.T) {
+	metrics := CloudMetrics{CPUUsage: 30, MemoryUsage: 20}
+	score := func(cfg RiskConfig) float64 {
+		engine, err := NewEngineWithConfig(cfg)
+		require.NoError(t, err)
+		return engine.CalculateScore(100, metrics).Score
+	}
+
+	base := score(DefaultRiskConfig())
+
+	memoryHeavy := DefaultRiskConfig()
+	memoryHeavy.MemoryWeight = 3 // memory risk 60 now outweighs CPU 30
+	assert.Less(t, score(memoryHeavy), base)
+
+	costHeavy := DefaultRiskConfig()
+	costHeavy.CostWeight = 2
+	assert.InDelta(t, 2*base, score(costHeavy), 1e-9)
+
+	cautious := DefaultRiskConfig()
+	cautious.ConfidenceFloor = 0.9
+	assert.Zero(t, score(cautious), "0.8 confidence is below the floor")
+}
+
+func TestNewEngineWithConfig_SizingThresholds(t *testing.T) {
+	cfg := DefaultRiskConfig()
+	cfg.Sizing = SizingPolicy{MaxCPU: 25, MaxMemory: 30}
+	engine, err := NewEngineWithConfig(cfg)
+	require.NoError(t, err)
+
+	// Safe under the default 40/50, not under the conservative policy
+	metrics := CloudMetrics{CPUUsage: 30, MemoryUsage: 20}
+	assert.True(t, NewEngine().SizingSafety(metrics).Safe)
+	assert.False(t, engine.SizingSafety(metrics).Safe)
+}
+
+func TestRiskConfig_Validate(t *testing.T) {
+	assert.NoError(t, DefaultRiskConfig().Validate())
+
+	for name, mutate := range map[string]func(*RiskConfig){
+		"negative weight":   func(c *RiskConfig) { c.CostWeight = -1 },
+		"no usage weight":   func(c *RiskConfig) { c.CPUWeight, c.MemoryWeight = 0, 0 },
+		"zero confidence":   func(c *RiskConfig) { c.Confidence = 0 },
+		"floor above 1":     func(c *RiskConfig) { c.ConfidenceFloor = 1.5 },
+		"sizing over 100%":  func(c *RiskConfig) { c.Sizing.MaxMemory = 120 },
+		"missing threshold": func(c *RiskConfig) { c.Sizing.MaxCPU = 0 },
+	} {
+		cfg := DefaultRiskConfig()
+		mutate(&cfg)
+		_, err := NewEngineWithConfig(cfg)
+		assert.Error(t, err, name)
+	}
+}
@@ -8,34 +8,67 @@ import (
 )
 
 type ScoreResult struct {
-	Score      float64 `json:"score"`
-	Impact     float64 `json:"impact"`     // Monthly savings in USD
-	Risk       float64 `json:"risk"`       // 1-100 logic (Higher is riskier)
-	Confidence float64 `json:"confidence"` // 0-1
+	Score      float64      `json:"score"`
+	Impact     float64      `json:"impact"`     // Monthly savings in USD
+	Risk       float64      `json:"risk"`       // 1-100 logic (Higher is riskier)
+	Confidence float64      `json:"confidence"` // 0-1
+	Sizing     SizingSafety `json:"sizing"`     // Whether downsizing is safe, and by how much
 }
 
 type Engine struct {
 	DefaultConfidence float64
+	Sizing            SizingPolicy
+
+	cpuWeight       float64
+	memoryWeight    float64
+	costWeight      float64
+	confidenceFloor float64
 }
 
+// NewEngine returns an engine with DefaultRiskConfig
 func NewEngine() *Engine {
-	return &Engine{
-		DefaultConfidence: 0.8,
+	engine, _ := NewEngineWithConfig(DefaultRiskConfig())
+	return engine
+}
+
+// NewEngineWithConfig returns an engine scoring with the given weights,
+// confidence and sizing thresholds
+func NewEngineWithConfig(cfg RiskConfig) (*Engine, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid risk config: %w", err)
 	}
+	return &Engine{
+		DefaultConfidence: cfg.Confidence,
+		Sizing:            cfg.Sizing,
+		cpuWeight:         cfg.CPUWeight,
+		memoryWeight:      cfg.MemoryWeight,
+		costWeight:        cfg.CostWeight,
+		confidenceFloor:   cfg.ConfidenceFloor,
+	}, nil
 }
 
-// CalculateScore implements $Score = (Impact / Risk) \times Confidence$
+// SizingSafety checks the metrics against the engine's sizing policy
+func (e *Engine) SizingSafety(metrics CloudMetrics) SizingSafety {
+	return e.Sizing.Evaluate(metrics.CPUUsage, metrics.MemoryUsage)
+}
+
+// CalculateScore implements $Score = (Impact / Risk) \times Confidence$, with
+// impact and risk scaled by the engine's weights
 func (e *Engine) CalculateScore(impact float64, metrics CloudMetrics) ScoreResult {
 	// Base risk calculation: Higher usage = Higher risk
-	// We use max of CPU and Memory as primary risk factor
-	usageRisk := math.Max(metrics.CPUUsage, metrics.MemoryUsage)
-	
+	// We use the max of weighted CPU and Memory as primary risk factor
+	usageRisk := math.Max(e.cpuWeight*metrics.CPUUsage, e.memoryWeight*metrics.MemoryUsage)
+
 	// Normalize risk to 1-100, ensuring it's never 0 to avoid division by zero
 	risk := math.Max(1.0, usageRisk)
-	
-	score := (impact / risk) * e.DefaultConfidence
 
-	logger.LogAction(logger.Architect, "RiskCalculation", "COMPLETED", 
+	score := (e.costWeight * impact / risk) * e.DefaultConfidence
+	// Too little confidence to act on in a conservative environment
+	if e.DefaultConfidence < e.confidenceFloor {
+		score = 0
+	}
+
+	logger.LogAction(logger.Architect, "RiskCalculation", "COMPLETED",
 		fmt.Sprintf("Impact: %.2f, Risk: %.2f, Score: %.4f", impact, risk, score))
 
 	return ScoreResult{
@@ -43,5 +76,6 @@ func (e *Engine) CalculateScore(impact float64, metrics CloudMetrics) ScoreResul
 		Impact:     impact,
 		Risk:       risk,
 		Confidence: e.DefaultConfidence,
+		Sizing:     e.SizingSafety(metrics),
 	}
 }
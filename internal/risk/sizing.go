@@ -0,0 +1,46 @@
+package risk
+
+import "fmt"
+
+// SizingPolicy is the utilization, in percent, below which a resource is
+// safe to downsize. It is the single source for the rightsizing thresholds.
+type SizingPolicy struct {
+	MaxCPU    float64 `json:"max_cpu" yaml:"max_cpu"`
+	MaxMemory float64 `json:"max_memory" yaml:"max_memory"`
+}
+
+// DefaultSizingPolicy allows downsizing below 40% CPU and 50% memory
+func DefaultSizingPolicy() SizingPolicy {
+	return SizingPolicy{MaxCPU: 40, MaxMemory: 50}
+}
+
+// SizingSafety is a sizing decision with its margins. Headroom is the
+// smaller of the CPU and memory margins, in percentage points below the
+// threshold; it is negative when a resource is over a threshold.
+type SizingSafety struct {
+	Safe           bool    `json:"safe"`
+	CPUHeadroom    float64 `json:"cpu_headroom"`
+	MemoryHeadroom float64 `json:"memory_headroom"`
+	Headroom       float64 `json:"headroom"`
+}
+
+// Evaluate checks CPU and memory utilization against the policy
+func (p SizingPolicy) Evaluate(cpuUsage, memoryUsage float64) SizingSafety {
+	cpu := p.MaxCPU - cpuUsage
+	memory := p.MaxMemory - memoryUsage
+	headroom := min(cpu, memory)
+	return SizingSafety{
+		Safe:           headroom > 0,
+		CPUHeadroom:    cpu,
+		MemoryHeadroom: memory,
+		Headroom:       headroom,
+	}
+}
+
+// String describes the decision, e.g. "safe, with 15.0% headroom"
+func (s SizingSafety) String() string {
+	if s.Safe {
+		return fmt.Sprintf("safe, with %.1f%% headroom", s.Headroom)
+	}
+	return fmt.Sprintf("unsafe, %.1f%% over threshold", -s.Headroom)
+}
@@ -0,0 +1,39 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSizingPolicy_Evaluate(t *testing.T) {
+	policy := DefaultSizingPolicy()
+
+	safe := policy.Evaluate(25, 35)
+	assert.True(t, safe.Safe)
+	assert.Equal(t, 15.0, safe.CPUHeadroom)
+	assert.Equal(t, 15.0, safe.MemoryHeadroom)
+	assert.Equal(t, 15.0, safe.Headroom)
+	assert.Equal(t, "safe, with 15.0% headroom", safe.String())
+
+	// Memory is the tighter margin
+	tight := policy.Evaluate(10, 48)
+	assert.True(t, tight.Safe)
+	assert.Equal(t, 2.0, tight.Headroom)
+
+	unsafe := policy.Evaluate(45, 20)
+	assert.False(t, unsafe.Safe)
+	assert.Equal(t, -5.0, unsafe.Headroom)
+	assert.Equal(t, "unsafe, 5.0% over threshold", unsafe.String())
+
+	assert.False(t, policy.Evaluate(40, 10).Safe, "the threshold itself is not safe")
+}
+
+func TestEngine_CalculateScoreIncludesSizing(t *testing.T) {
+	engine := NewEngine()
+	engine.Sizing = SizingPolicy{MaxCPU: 60, MaxMemory: 70}
+
+	result := engine.CalculateScore(100, CloudMetrics{CPUUsage: 50, MemoryUsage: 40})
+	assert.True(t, result.Sizing.Safe)
+	assert.Equal(t, 10.0, result.Sizing.Headroom)
+}
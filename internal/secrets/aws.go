@@ -0,0 +1,276 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// DefaultRefreshInterval is how long the AWS providers reuse fetched secrets
+// before fetching them again
+const DefaultRefreshInterval = 5 * time.Minute
+
+// secretCache holds a provider's secrets between refreshes. Loading happens
+// under the lock so concurrent misses cost one fetch.
+type secretCache struct {
+	load    func(ctx context.Context) (map[string]string, error)
+	refresh time.Duration
+	now     func() time.Time
+
+	mu      sync.Mutex
+	values  map[string]string
+	expires time.Time
+}
+
+func newSecretCache(refresh time.Duration, load func(ctx context.Context) (map[string]string, error)) *secretCache {
+	if refresh <= 0 {
+		refresh = DefaultRefreshInterval
+	}
+	return &secretCache{load: load, refresh: refresh, now: time.Now}
+}
+
+func (c *secretCache) get(key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.values == nil || !c.now().Before(c.expires) {
+		ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+		defer cancel()
+		values, err := c.load(ctx)
+		if err != nil {
+			return "", err
+		}
+		c.values, c.expires = values, c.now().Add(c.refresh)
+	}
+
+	value, ok := c.values[key]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", key, ErrSecretNotFound)
+	}
+	return value, nil
+}
+
+// put records a value just written so reads see it before the next refresh
+func (c *secretCache) put(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.values != nil {
+		c.values[key] = value
+	}
+}
+
+// secretsManagerAPI is the part of the Secrets Manager API the provider uses
+type secretsManagerAPI interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+	PutSecretValue(ctx context.Context, params *secretsmanager.PutSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.PutSecretValueOutput, error)
+}
+
+// AWSSecretsManagerProvider is a SecretProvider backed by one AWS Secrets
+// Manager secret whose value is a JSON object with a field per key
+type AWSSecretsManagerProvider struct {
+	client   secretsManagerAPI
+	secretID string // name or ARN
+	cache    *secretCache
+
+	writeMu sync.Mutex // serialises read-modify-write of the secret
+}
+
+// NewAWSSecretsManagerProvider creates a provider for the secret with the
+// given name or ARN, using the default AWS credential chain. A refresh of
+// zero uses DefaultRefreshInterval.
+func NewAWSSecretsManagerProvider(ctx context.Context, secretID string, refresh time.Duration) (*AWSSecretsManagerProvider, error) {
+	awsCfg, err := loadAWSConfig(ctx, secretID)
+	if err != nil {
+		return nil, err
+	}
+	return newAWSSecretsManagerProvider(secretsmanager.NewFromConfig(awsCfg), secretID, refresh), nil
+}
+
+func newAWSSecretsManagerProvider(client secretsManagerAPI, secretID string, refresh time.Duration) *AWSSecretsManagerProvider {
+	p := &AWSSecretsManagerProvider{client: client, secretID: secretID}
+	p.cache = newSecretCache(refresh, p.load)
+	return p
+}
+
+// Get returns the key's field of the secret
+func (p *AWSSecretsManagerProvider) Get(key string) (string, error) {
+	return p.cache.get(key)
+}
+
+// Set writes the key's field as a new version of the secret
+func (p *AWSSecretsManagerProvider) Set(key, value string) error {
+	return p.write(key, value, false)
+}
+
+// Rotate writes a new value for an existing field as a new version of the secret
+func (p *AWSSecretsManagerProvider) Rotate(key, value string) error {
+	return p.write(key, value, true)
+}
+
+func (p *AWSSecretsManagerProvider) load(ctx context.Context) (map[string]string, error) {
+	output, err := p.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(p.secretID)})
+	var notFound *smtypes.ResourceNotFoundException
+	if errors.As(err, &notFound) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %s: %w", p.secretID, err)
+	}
+
+	values := make(map[string]string)
+	if output.SecretString == nil {
+		return values, nil
+	}
+	if err := json.Unmarshal([]byte(*output.SecretString), &values); err != nil {
+		return nil, fmt.Errorf("secret %s is not a JSON object of strings: %w", p.secretID, err)
+	}
+	return values, nil
+}
+
+// write re-reads the secret rather than trusting the cache, so fields changed
+// elsewhere since the last refresh are kept
+func (p *AWSSecretsManagerProvider) write(key, value string, mustExist bool) error {
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	values, err := p.load(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := values[key]; mustExist && !ok {
+		return fmt.Errorf("%s: %w", key, ErrSecretNotFound)
+	}
+	values[key] = value
+
+	encoded, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("failed to encode secret %s: %w", p.secretID, err)
+	}
+	if _, err := p.client.PutSecretValue(ctx, &secretsmanager.PutSecretValueInput{
+		SecretId:     aws.String(p.secretID),
+		SecretString: aws.String(string(encoded)),
+	}); err != nil {
+		return fmt.Errorf("failed to write secret %s: %w", key, err)
+	}
+	p.cache.put(key, value)
+	return nil
+}
+
+// ssmAPI is the part of the SSM API the provider uses
+type ssmAPI interface {
+	ssm.GetParametersByPathAPIClient
+	PutParameter(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error)
+}
+
+// SSMParameterProvider is a SecretProvider backed by SSM Parameter Store.
+// Each key is a SecureString parameter directly under a path, e.g.
+// /talos/production/JWT_SECRET.
+type SSMParameterProvider struct {
+	client ssmAPI
+	path   string // without a trailing slash
+	cache  *secretCache
+}
+
+// NewSSMParameterProvider creates a provider for the parameters under path,
+// using the default AWS credential chain. A refresh of zero uses
+// DefaultRefreshInterval.
+func NewSSMParameterProvider(ctx context.Context, path string, refresh time.Duration) (*SSMParameterProvider, error) {
+	awsCfg, err := loadAWSConfig(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	return newSSMParameterProvider(ssm.NewFromConfig(awsCfg), path, refresh), nil
+}
+
+func newSSMParameterProvider(client ssmAPI, path string, refresh time.Duration) *SSMParameterProvider {
+	p := &SSMParameterProvider{client: client, path: strings.TrimSuffix(path, "/")}
+	p.cache = newSecretCache(refresh, p.load)
+	return p
+}
+
+// Get returns the parameter path/key
+func (p *SSMParameterProvider) Get(key string) (string, error) {
+	return p.cache.get(key)
+}
+
+// Set writes the parameter path/key, creating it if needed
+func (p *SSMParameterProvider) Set(key, value string) error {
+	return p.put(key, value)
+}
+
+// Rotate overwrites the parameter path/key if it exists
+func (p *SSMParameterProvider) Rotate(key, value string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	values, err := p.load(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := values[key]; !ok {
+		return fmt.Errorf("%s: %w", key, ErrSecretNotFound)
+	}
+	return p.put(key, value)
+}
+
+func (p *SSMParameterProvider) load(ctx context.Context) (map[string]string, error) {
+	values := make(map[string]string)
+	paginator := ssm.NewGetParametersByPathPaginator(p.client, &ssm.GetParametersByPathInput{
+		Path:           aws.String(p.path),
+		WithDecryption: aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parameters under %s: %w", p.path, err)
+		}
+		for _, param := range page.Parameters {
+			name := strings.TrimPrefix(aws.ToString(param.Name), p.path+"/")
+			values[name] = aws.ToString(param.Value)
+		}
+	}
+	return values, nil
+}
+
+func (p *SSMParameterProvider) put(key, value string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	if _, err := p.client.PutParameter(ctx, &ssm.PutParameterInput{
+		Name:      aws.String(p.path + "/" + key),
+		Value:     aws.String(value),
+		Type:      ssmtypes.ParameterTypeSecureString,
+		Overwrite: aws.Bool(true),
+	}); err != nil {
+		return fmt.Errorf("failed to write parameter %s: %w", key, err)
+	}
+	p.cache.put(key, value)
+	return nil
+}
+
+// loadAWSConfig loads the default AWS config, in the ARN's region when given one
+func loadAWSConfig(ctx context.Context, arn string) (aws.Config, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if parts := strings.Split(arn, ":"); len(parts) > 3 && parts[0] == "arn" && parts[3] != "" {
+		opts = append(opts, awsconfig.WithRegion(parts[3]))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return awsCfg, nil
+}
@@ -0,0 +1,220 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	smtypes "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSecretsManager struct {
+	secret *string // nil until the secret exists
+	gets   int
+	puts   int
+}
+
+func (f *fakeSecretsManager) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	f.gets++
+	if f.secret == nil {
+		return nil, &smtypes.ResourceNotFoundException{Message: aws.String("not found")}
+	}
+	return &secretsmanager.GetSecretValueOutput{SecretString: f.secret}, nil
+}
+
+func (f *fakeSecretsManager) PutSecretValue(ctx context.Context, params *secretsmanager.PutSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.PutSecretValueOutput, error) {
+	f.puts++
+	f.secret = params.SecretString
+	return &secretsmanager.PutSecretValueOutput{}, nil
+}
+
+func (f *fakeSecretsManager) fields(t *testing.T) map[string]string {
+	var values map[string]string
+	require.NoError(t, json.Unmarshal([]byte(*f.secret), &values))
+	return values
+}
+
+type fakeSSM struct {
+	params map[string]string
+	gets   int
+}
+
+func (f *fakeSSM) GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	f.gets++
+	output := &ssm.GetParametersByPathOutput{}
+	for name, value := range f.params {
+		if strings.HasPrefix(name, *params.Path+"/") {
+			output.Parameters = append(output.Parameters, ssmtypes.Parameter{Name: aws.String(name), Value: aws.String(value)})
+		}
+	}
+	return output, nil
+}
+
+func (f *fakeSSM) PutParameter(ctx context.Context, params *ssm.PutParameterInput, optFns ...func(*ssm.Options)) (*ssm.PutParameterOutput, error) {
+	f.params[*params.Name] = *params.Value
+	return &ssm.PutParameterOutput{}, nil
+}
+
+func TestAWSSecretsManagerProvider_CachesUntilRefresh(t *testing.T) {
+	client := &fakeSecretsManager{secret: aws.String(`{"JWT_SECRET":"first"}`)}
+	provider := newAWSSecretsManagerProvider(client, "talos/production", time.Minute)
+	now := time.Now()
+	provider.cache.now = func() time.Time { return now }
+
+	value, err := provider.Get("JWT_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "first", value)
+
+	client.secret = aws.String(`{"JWT_SECRET":"second"}`)
+	value, _ = provider.Get("JWT_SECRET")
+	assert.Equal(t, "first", value)
+	assert.Equal(t, 1, client.gets)
+
+	now = now.Add(time.Minute)
+	value, _ = provider.Get("JWT_SECRET")
+	assert.Equal(t, "second", value)
+	assert.Equal(t, 2, client.gets)
+
+	_, err = provider.Get("REDIS_PASSWORD")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+}
+
+func TestAWSSecretsManagerProvider_SetAndRotate(t *testing.T) {
+	client := &fakeSecretsManager{}
+	provider := newAWSSecretsManagerProvider(client, "talos/production", time.Minute)
+
+	_, err := provider.Get("JWT_SECRET")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+	assert.True(t, errors.Is(provider.Rotate("JWT_SECRET", "value"), ErrSecretNotFound))
+
+	require.NoError(t, provider.Set("JWT_SECRET", "first"))
+	require.NoError(t, provider.Set("REDIS_PASSWORD", "redis"))
+	require.NoError(t, provider.Rotate("JWT_SECRET", "second"))
+	assert.Equal(t, map[string]string{"JWT_SECRET": "second", "REDIS_PASSWORD": "redis"}, client.fields(t))
+	assert.Equal(t, 3, client.puts)
+
+	value, err := provider.Get("JWT_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "second", value)
+}
+
+func TestAWSSecretsManagerProvider_RejectsNonJSONSecret(t *testing.T) {
+	client := &fakeSecretsManager{secret: aws.String("plain-string")}
+	provider := newAWSSecretsManagerProvider(client, "talos/production", time.Minute)
+
+	_, err := provider.Get("JWT_SECRET")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrSecretNotFound))
+}
+
+func TestSSMParameterProvider_GetSetRotate(t *testing.T) {
+	client := &fakeSSM{params: map[string]string{
+		"/talos/production/JWT_SECRET": "first",
+		"/talos/staging/JWT_SECRET":    "staging",
+	}}
+	provider := newSSMParameterProvider(client, "/talos/production/", time.Minute)
+	now := time.Now()
+	provider.cache.now = func() time.Time { return now }
+
+	value, err := provider.Get("JWT_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "first", value)
+	_, err = provider.Get("REDIS_PASSWORD")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+	assert.Equal(t, 1, client.gets)
+
+	require.NoError(t, provider.Rotate("JWT_SECRET", "second"))
+	assert.True(t, errors.Is(provider.Rotate("REDIS_PASSWORD", "redis"), ErrSecretNotFound))
+	require.NoError(t, provider.Set("REDIS_PASSWORD", "redis"))
+	assert.Equal(t, "second", client.params["/talos/production/JWT_SECRET"])
+	assert.Equal(t, "redis", client.params["/talos/production/REDIS_PASSWORD"])
+
+	value, _ = provider.Get("REDIS_PASSWORD")
+	assert.Equal(t, "redis", value)
+}
+
+func TestSecretManager_ValidatesFetchedSecrets(t *testing.T) {
+	values := map[string]string{
+		"GEMINI_API_KEY": "AIzaSyD9xQ2mLw7Rt4Vb8Nc3Hj6",
+		"CLAUDE_API_KEY": "sk-ant-REDACTED",
+		"JWT_SECRET":     "Xk9#mQ2$vL7@pR4!wN8&zT5^bJ3*hF6%",
+		"REDIS_PASSWORD": "Rd7#Lq2!Vx9@",
+		"DATABASE_DSN":   "postgres://admin@db/talos", // weak: skipped
+	}
+	encoded, err := json.Marshal(values)
+	require.NoError(t, err)
+	client := &fakeSecretsManager{secret: aws.String(string(encoded))}
+	sm := NewSecretManagerWithProvider(nopLogger{}, newAWSSecretsManagerProvider(client, "talos/production", time.Minute))
+
+	require.NoError(t, sm.LoadSecrets())
+	jwt, err := sm.GetSecret("JWT_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, values["JWT_SECRET"], jwt)
+	_, err = sm.GetSecret("DATABASE_DSN")
+	assert.Error(t, err)
+	assert.Equal(t, 1, client.gets)
+
+	values["JWT_SECRET"] = "too-short"
+	encoded, _ = json.Marshal(values)
+	client.secret = aws.String(string(encoded))
+	sm = NewSecretManagerWithProvider(nopLogger{}, newAWSSecretsManagerProvider(client, "talos/production", time.Minute))
+	assert.Error(t, sm.LoadSecrets())
+}
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		source, scheme, location string
+		wantErr                  bool
+	}{
+		{source: "", scheme: "env"},
+		{source: "env://", scheme: "env"},
+		{source: "awssm://talos/production", scheme: "awssm", location: "talos/production"},
+		{source: "awssm://arn:aws:secretsmanager:eu-west-1:123456789012:secret:talos", scheme: "awssm", location: "arn:aws:secretsmanager:eu-west-1:123456789012:secret:talos"},
+		{source: "ssm:///talos/production", scheme: "ssm", location: "/talos/production"},
+		{source: "vault://secret/talos", scheme: "vault", location: "secret/talos"},
+		{source: "awssm://", wantErr: true},
+		{source: "ssm://talos", wantErr: true},
+		{source: "ssm:///", wantErr: true},
+		{source: "vault://secret", wantErr: true},
+		{source: "gcpsm://talos", wantErr: true},
+		{source: "talos/production", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			scheme, location, err := parseSource(tt.source)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.scheme, scheme)
+			assert.Equal(t, tt.location, location)
+		})
+	}
+}
+
+func TestNewLookup(t *testing.T) {
+	t.Setenv("JWT_SECRET", "from-env")
+
+	lookup, err := NewLookup(context.Background(), "env://", 0)
+	require.NoError(t, err)
+	value, err := lookup("JWT_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "from-env", value)
+
+	value, err = lookup("TALOS_UNSET_SECRET")
+	require.NoError(t, err, "a missing secret is not an error")
+	assert.Empty(t, value)
+
+	_, err = NewLookup(context.Background(), "gcpsm://talos", 0)
+	assert.Error(t, err)
+}
@@ -3,6 +3,7 @@ package secrets
 import (
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -11,8 +12,9 @@ import (
 
 // SecretManager handles secure secret management
 type SecretManager struct {
-	secrets map[string]string
-	logger  Logger
+	secrets  map[string]string
+	provider SecretProvider
+	logger   Logger
 }
 
 // Logger interface for logging
@@ -22,15 +24,30 @@ type Logger interface {
 	Error(msg string)
 }
 
-// NewSecretManager creates a new secret manager
+// NewSecretManager creates a new secret manager that reads environment variables
 func NewSecretManager(logger Logger) *SecretManager {
+	return NewSecretManagerWithProvider(logger, EnvSecretProvider{})
+}
+
+// NewSecretManagerWithProvider creates a new secret manager backed by provider
+func NewSecretManagerWithProvider(logger Logger, provider SecretProvider) *SecretManager {
 	return &SecretManager{
-		secrets: make(map[string]string),
-		logger:  logger,
+		secrets:  make(map[string]string),
+		provider: provider,
+		logger:   logger,
+	}
+}
+
+// lookup returns the provider's value for key, or "" if it has none
+func (sm *SecretManager) lookup(key string) (string, error) {
+	value, err := sm.provider.Get(key)
+	if errors.Is(err, ErrSecretNotFound) {
+		return "", nil
 	}
+	return value, err
 }
 
-// LoadSecrets loads secrets from environment variables
+// LoadSecrets loads secrets from the provider
 func (sm *SecretManager) LoadSecrets() error {
 	// Required secrets
 	requiredSecrets := map[string]string{
@@ -58,7 +75,10 @@ func (sm *SecretManager) LoadSecrets() error {
 
 	// Load required secrets
 	for key, description := range requiredSecrets {
-		value := os.Getenv(key)
+		value, err := sm.lookup(key)
+		if err != nil {
+			return fmt.Errorf("failed to load secret %s: %w", key, err)
+		}
 		if value == "" {
 			return fmt.Errorf("required secret %s is not set: %s", key, description)
 		}
@@ -74,7 +94,11 @@ func (sm *SecretManager) LoadSecrets() error {
 
 	// Load optional secrets
 	for key := range optionalSecrets {
-		value := os.Getenv(key)
+		value, err := sm.lookup(key)
+		if err != nil {
+			sm.logger.Warn(fmt.Sprintf("Failed to load optional secret %s, skipping: %v", key, err))
+			continue
+		}
 		if value != "" {
 			if err := sm.validateSecret(key, value); err != nil {
 				sm.logger.Warn(fmt.Sprintf("Invalid optional secret %s, skipping: %v", key, err))
@@ -207,15 +231,13 @@ func (sm *SecretManager) ValidateSecrets() error {
 	return nil
 }
 
-// RotateSecret rotates a secret (placeholder for actual rotation logic)
+// RotateSecret replaces a secret with a newly generated one, writing it back
+// to the provider before the loaded copy is updated
 func (sm *SecretManager) RotateSecret(key string) error {
-	// In a real implementation, this would:
-	// 1. Generate a new secure secret
-	// 2. Update the secret in the secret store
-	// 3. Update any dependent services
-	// 4. Log the rotation
-
 	newSecret := sm.generateSecureSecret()
+	if err := sm.provider.Rotate(key, newSecret); err != nil {
+		return fmt.Errorf("failed to rotate secret %s: %w", key, err)
+	}
 	sm.secrets[key] = newSecret
 
 	sm.logger.Info(fmt.Sprintf("Rotated secret: %s", key))
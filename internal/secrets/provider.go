@@ -0,0 +1,140 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// providerTimeout bounds each call a remote SecretProvider makes
+const providerTimeout = 10 * time.Second
+
+// ErrSecretNotFound is returned by a SecretProvider that has no value for a key
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider is the store SecretManager loads secrets from and writes
+// rotated secrets back to
+type SecretProvider interface {
+	// Get returns the secret's value, or ErrSecretNotFound
+	Get(key string) (string, error)
+	// Set stores the secret, creating it if needed
+	Set(key, value string) error
+	// Rotate replaces an existing secret's value; it fails with
+	// ErrSecretNotFound rather than create a secret
+	Rotate(key, value string) error
+}
+
+// EnvSecretProvider reads secrets from environment variables. Set and
+// Rotate only change this process's environment.
+type EnvSecretProvider struct{}
+
+// Get returns the environment variable key
+func (EnvSecretProvider) Get(key string) (string, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return "", fmt.Errorf("%s: %w", key, ErrSecretNotFound)
+	}
+	return value, nil
+}
+
+// Set sets the environment variable key
+func (EnvSecretProvider) Set(key, value string) error {
+	return os.Setenv(key, value)
+}
+
+// Rotate sets the environment variable key if it is already set
+func (p EnvSecretProvider) Rotate(key, value string) error {
+	if _, err := p.Get(key); err != nil {
+		return err
+	}
+	return p.Set(key, value)
+}
+
+// NewProvider returns the provider a secrets source names:
+//
+//	"" or "env://"            environment variables
+//	"awssm://NAME_OR_ARN"     an AWS Secrets Manager secret holding a JSON object
+//	"ssm:///PATH"             SSM parameters under PATH
+//	"vault://MOUNT/PATH"      a Vault KV v2 secret; the address and credentials
+//	                          come from VAULT_ADDR and VAULT_TOKEN, or
+//	                          VAULT_ROLE_ID and VAULT_SECRET_ID
+//
+// The AWS providers reuse fetched values for refresh; zero uses
+// DefaultRefreshInterval.
+func NewProvider(ctx context.Context, source string, refresh time.Duration) (SecretProvider, error) {
+	scheme, location, err := parseSource(source)
+	if err != nil {
+		return nil, err
+	}
+
+	switch scheme {
+	case "env":
+		return EnvSecretProvider{}, nil
+	case "awssm":
+		return NewAWSSecretsManagerProvider(ctx, location, refresh)
+	case "ssm":
+		return NewSSMParameterProvider(ctx, location, refresh)
+	default: // vault
+		mount, path, _ := strings.Cut(location, "/")
+		return NewVaultSecretProvider(VaultConfig{
+			Address:  os.Getenv("VAULT_ADDR"),
+			Mount:    mount,
+			Path:     path,
+			Token:    os.Getenv("VAULT_TOKEN"),
+			RoleID:   os.Getenv("VAULT_ROLE_ID"),
+			SecretID: os.Getenv("VAULT_SECRET_ID"),
+		})
+	}
+}
+
+// NewLookup returns a lookup over the provider source names, as
+// config.LoadWithSecrets takes, that reports a missing secret as "" rather
+// than ErrSecretNotFound
+func NewLookup(ctx context.Context, source string, refresh time.Duration) (func(key string) (string, error), error) {
+	provider, err := NewProvider(ctx, source, refresh)
+	if err != nil {
+		return nil, err
+	}
+	return func(key string) (string, error) {
+		value, err := provider.Get(key)
+		if errors.Is(err, ErrSecretNotFound) {
+			return "", nil
+		}
+		return value, err
+	}, nil
+}
+
+// parseSource splits a secrets source into its scheme and location. URL
+// parsing is not used because ARNs are not valid URL hosts.
+func parseSource(source string) (scheme, location string, err error) {
+	if source == "" {
+		return "env", "", nil
+	}
+	scheme, location, ok := strings.Cut(source, "://")
+	if !ok {
+		return "", "", fmt.Errorf("secrets source %q has no scheme", source)
+	}
+
+	switch scheme {
+	case "env":
+		return scheme, "", nil
+	case "awssm":
+		if location == "" {
+			return "", "", fmt.Errorf("secrets source %q names no secret", source)
+		}
+	case "ssm":
+		if !strings.HasPrefix(location, "/") || strings.Trim(location, "/") == "" {
+			return "", "", fmt.Errorf("secrets source %q needs an absolute parameter path, e.g. ssm:///talos/production", source)
+		}
+	case "vault":
+		if mount, path, _ := strings.Cut(location, "/"); mount == "" || path == "" {
+			return "", "", fmt.Errorf("secrets source %q needs a mount and path, e.g. vault://secret/talos", source)
+		}
+	default:
+		return "", "", fmt.Errorf("unknown secrets source scheme %q", scheme)
+	}
+	return scheme, location, nil
+}
@@ -1,7 +1,10 @@
 package secrets
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
 
 	vault "github.com/hashicorp/vault/api"
 )
@@ -85,3 +88,143 @@ func (v *VaultClient) GetDatabaseCredentials() (string, string, error) {
 
 	return userSecret, passSecret, nil
 }
+
+// VaultConfig locates the KV v2 secret that holds Talos's secrets, one field
+// per key, and says how to authenticate. AppRole is used when RoleID is set,
+// otherwise Token.
+type VaultConfig struct {
+	Address  string
+	Mount    string // KV v2 mount path, "secret" if empty
+	Path     string // secret path under the mount, e.g. "talos/production"
+	Token    string
+	RoleID   string
+	SecretID string
+}
+
+// VaultSecretProvider is a SecretProvider backed by a Vault KV v2 secret
+type VaultSecretProvider struct {
+	kv   *vault.KVv2
+	path string
+
+	mu sync.Mutex // serialises read-modify-write of the secret
+}
+
+// NewVaultSecretProvider logs in to Vault and returns a provider for cfg.Path
+func NewVaultSecretProvider(cfg VaultConfig) (*VaultSecretProvider, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("vault secret path is required")
+	}
+	config := vault.DefaultConfig()
+	config.Address = cfg.Address
+
+	client, err := vault.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Vault client: %w", err)
+	}
+	if err := vaultLogin(client, cfg); err != nil {
+		return nil, err
+	}
+
+	mount := cfg.Mount
+	if mount == "" {
+		mount = "secret"
+	}
+	return &VaultSecretProvider{kv: client.KVv2(mount), path: cfg.Path}, nil
+}
+
+// vaultLogin sets the client's token, exchanging the AppRole credentials for one if configured
+func vaultLogin(client *vault.Client, cfg VaultConfig) error {
+	switch {
+	case cfg.RoleID != "":
+		ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+		defer cancel()
+		secret, err := client.Logical().WriteWithContext(ctx, "auth/approle/login", map[string]interface{}{
+			"role_id":   cfg.RoleID,
+			"secret_id": cfg.SecretID,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to log in to Vault with AppRole: %w", err)
+		}
+		if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+			return fmt.Errorf("vault AppRole login returned no token")
+		}
+		client.SetToken(secret.Auth.ClientToken)
+	case cfg.Token != "":
+		client.SetToken(cfg.Token)
+	default:
+		return fmt.Errorf("vault token or AppRole role ID is required")
+	}
+	return nil
+}
+
+// Get returns the key's field of the secret
+func (v *VaultSecretProvider) Get(key string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	data, _, err := v.read(ctx)
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[key].(string)
+	if !ok {
+		return "", fmt.Errorf("%s: %w", key, ErrSecretNotFound)
+	}
+	return value, nil
+}
+
+// Set writes the key's field as a new version of the secret
+func (v *VaultSecretProvider) Set(key, value string) error {
+	return v.write(key, value, false)
+}
+
+// Rotate writes a new value for an existing field as a new version of the secret
+func (v *VaultSecretProvider) Rotate(key, value string) error {
+	return v.write(key, value, true)
+}
+
+// read returns the secret's fields and version; a secret that does not exist
+// yet reads as empty at version 0
+func (v *VaultSecretProvider) read(ctx context.Context) (map[string]interface{}, int, error) {
+	secret, err := v.kv.Get(ctx, v.path)
+	if errors.Is(err, vault.ErrSecretNotFound) {
+		return map[string]interface{}{}, 0, nil
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to read secret: %w", err)
+	}
+
+	data := make(map[string]interface{}, len(secret.Data)+1)
+	for k, val := range secret.Data {
+		data[k] = val
+	}
+	version := 0
+	if secret.VersionMetadata != nil {
+		version = secret.VersionMetadata.Version
+	}
+	return data, version, nil
+}
+
+// write updates one field and writes the secret back with check-and-set, so a
+// concurrent writer's change is not silently lost
+func (v *VaultSecretProvider) write(key, value string, mustExist bool) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), providerTimeout)
+	defer cancel()
+
+	data, version, err := v.read(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := data[key]; mustExist && !ok {
+		return fmt.Errorf("%s: %w", key, ErrSecretNotFound)
+	}
+	data[key] = value
+
+	if _, err := v.kv.Put(ctx, v.path, data, vault.WithCheckAndSet(version)); err != nil {
+		return fmt.Errorf("failed to write secret %s: %w", key, err)
+	}
+	return nil
+}
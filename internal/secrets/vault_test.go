@@ -0,0 +1,183 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockVault serves the KV v2 and AppRole endpoints VaultSecretProvider uses
+type mockVault struct {
+	mu      sync.Mutex
+	token   string
+	data    map[string]interface{}
+	version int
+	writes  int
+}
+
+func newMockVault(t *testing.T, token string, data map[string]interface{}) (*mockVault, *httptest.Server) {
+	m := &mockVault{token: token, data: data}
+	if data != nil {
+		m.version = 1
+	}
+	server := httptest.NewServer(m)
+	t.Cleanup(server.Close)
+	return m, server
+}
+
+func (m *mockVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r.URL.Path == "/v1/auth/approle/login" {
+		var login map[string]string
+		json.NewDecoder(r.Body).Decode(&login)
+		if login["role_id"] != "talos-role" || login["secret_id"] != "talos-secret-id" {
+			vaultError(w, http.StatusBadRequest, "invalid role or secret ID")
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"auth": map[string]interface{}{"client_token": m.token}})
+		return
+	}
+	if r.Header.Get("X-Vault-Token") != m.token {
+		vaultError(w, http.StatusForbidden, "permission denied")
+		return
+	}
+	if r.URL.Path != "/v1/secret/data/talos" {
+		vaultError(w, http.StatusNotFound)
+		return
+	}
+
+	metadata := func() map[string]interface{} {
+		return map[string]interface{}{
+			"version":       m.version,
+			"created_time":  time.Now().UTC().Format(time.RFC3339),
+			"deletion_time": "",
+			"destroyed":     false,
+		}
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if m.data == nil {
+			vaultError(w, http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"data": m.data, "metadata": metadata()},
+		})
+	case http.MethodPut, http.MethodPost:
+		var body struct {
+			Data    map[string]interface{} `json:"data"`
+			Options struct {
+				CAS *int `json:"cas"`
+			} `json:"options"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Options.CAS != nil && *body.Options.CAS != m.version {
+			vaultError(w, http.StatusBadRequest, "check-and-set parameter did not match the current version")
+			return
+		}
+		m.data = body.Data
+		m.version++
+		m.writes++
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": metadata()})
+	default:
+		vaultError(w, http.StatusMethodNotAllowed)
+	}
+}
+
+func vaultError(w http.ResponseWriter, status int, errs ...string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{"errors": append([]string{}, errs...)})
+}
+
+func TestVaultSecretProvider_GetSetRotate(t *testing.T) {
+	m, server := newMockVault(t, "s.root", map[string]interface{}{"JWT_SECRET": "old-value"})
+	provider, err := NewVaultSecretProvider(VaultConfig{Address: server.URL, Path: "talos", Token: "s.root"})
+	require.NoError(t, err)
+
+	value, err := provider.Get("JWT_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "old-value", value)
+
+	_, err = provider.Get("REDIS_PASSWORD")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+
+	require.NoError(t, provider.Set("REDIS_PASSWORD", "redis-value"))
+	require.NoError(t, provider.Rotate("JWT_SECRET", "new-value"))
+	assert.Equal(t, map[string]interface{}{"JWT_SECRET": "new-value", "REDIS_PASSWORD": "redis-value"}, m.data)
+	assert.Equal(t, 3, m.version)
+
+	err = provider.Rotate("GEMINI_API_KEY", "value")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+	assert.Equal(t, 2, m.writes)
+}
+
+func TestVaultSecretProvider_SetCreatesSecret(t *testing.T) {
+	m, server := newMockVault(t, "s.root", nil)
+	provider, err := NewVaultSecretProvider(VaultConfig{Address: server.URL, Path: "talos", Token: "s.root"})
+	require.NoError(t, err)
+
+	_, err = provider.Get("JWT_SECRET")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+
+	require.NoError(t, provider.Set("JWT_SECRET", "value"))
+	assert.Equal(t, map[string]interface{}{"JWT_SECRET": "value"}, m.data)
+}
+
+func TestVaultSecretProvider_AppRoleLogin(t *testing.T) {
+	_, server := newMockVault(t, "s.approle", map[string]interface{}{"JWT_SECRET": "value"})
+
+	provider, err := NewVaultSecretProvider(VaultConfig{
+		Address: server.URL, Path: "talos", RoleID: "talos-role", SecretID: "talos-secret-id",
+	})
+	require.NoError(t, err)
+	value, err := provider.Get("JWT_SECRET")
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	_, err = NewVaultSecretProvider(VaultConfig{
+		Address: server.URL, Path: "talos", RoleID: "talos-role", SecretID: "wrong",
+	})
+	assert.Error(t, err)
+}
+
+func TestVaultSecretProvider_BadTokenFails(t *testing.T) {
+	_, server := newMockVault(t, "s.root", map[string]interface{}{"JWT_SECRET": "value"})
+	provider, err := NewVaultSecretProvider(VaultConfig{Address: server.URL, Path: "talos", Token: "s.wrong"})
+	require.NoError(t, err)
+
+	_, err = provider.Get("JWT_SECRET")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrSecretNotFound))
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Info(string)  {}
+func (nopLogger) Warn(string)  {}
+func (nopLogger) Error(string) {}
+
+func TestSecretManager_RotateSecretWritesToVault(t *testing.T) {
+	m, server := newMockVault(t, "s.root", map[string]interface{}{"JWT_SECRET": "old-value"})
+	provider, err := NewVaultSecretProvider(VaultConfig{Address: server.URL, Path: "talos", Token: "s.root"})
+	require.NoError(t, err)
+	sm := NewSecretManagerWithProvider(nopLogger{}, provider)
+
+	require.NoError(t, sm.RotateSecret("JWT_SECRET"))
+	rotated, err := sm.GetSecret("JWT_SECRET")
+	require.NoError(t, err)
+	assert.NotEqual(t, "old-value", rotated)
+	assert.Equal(t, rotated, m.data["JWT_SECRET"])
+
+	assert.Error(t, sm.RotateSecret("GEMINI_API_KEY"))
+	_, err = sm.GetSecret("GEMINI_API_KEY")
+	assert.Error(t, err)
+}
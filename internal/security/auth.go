@@ -12,7 +12,6 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 	"go.uber.org/zap"
-	"sync"
 )
 
 // SecurityManager handles all security-related operations
@@ -22,8 +21,16 @@ type SecurityManager struct {
 	refreshExpiry time.Duration
 	logger        *zap.Logger
 	rateLimiter   *RateLimiter
+	proxies       TrustedProxies
 }
 
+// Each client IP may make DefaultRateLimit requests per DefaultRateLimitWindow
+// unless the manager is given another limit
+const (
+	DefaultRateLimit       = 100
+	DefaultRateLimitWindow = time.Hour
+)
+
 // NewSecurityManager creates a new security manager
 func NewSecurityManager(jwtSecret string, tokenExpiry, refreshExpiry time.Duration, logger *zap.Logger) *SecurityManager {
 	return &SecurityManager{
@@ -31,10 +38,21 @@ func NewSecurityManager(jwtSecret string, tokenExpiry, refreshExpiry time.Durati
 		tokenExpiry:   tokenExpiry,
 		refreshExpiry: refreshExpiry,
 		logger:        logger,
-		rateLimiter:   NewRateLimiter(100, time.Hour), // 100 requests per hour
+		rateLimiter:   NewRateLimiter(DefaultRateLimit, DefaultRateLimitWindow),
 	}
 }
 
+// SetRateLimit lets each client IP make limit requests per window
+func (sm *SecurityManager) SetRateLimit(limit int, window time.Duration) {
+	sm.rateLimiter = NewRateLimiter(limit, window)
+}
+
+// SetTrustedProxies names the proxies whose forwarded headers identify the
+// client. Without any, clients are identified by their own address.
+func (sm *SecurityManager) SetTrustedProxies(proxies TrustedProxies) {
+	sm.proxies = proxies
+}
+
 // Claims represents JWT claims
 type Claims struct {
 	UserID   string   `json:"user_id"`
@@ -132,52 +150,6 @@ func (sm *SecurityManager) GenerateAPIKey() (string, error) {
 	return base64.URLEncoding.EncodeToString(bytes), nil
 }
 
-// RateLimiter implements thread-safe rate limiting
-type RateLimiter struct {
-	mu       sync.RWMutex
-	requests map[string][]time.Time
-	limit    int
-	window   time.Duration
-}
-
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
-	return &RateLimiter{
-		requests: make(map[string][]time.Time),
-		limit:    limit,
-		window:   window,
-	}
-}
-
-// Allow checks if a request is allowed (thread-safe)
-func (rl *RateLimiter) Allow(key string) bool {
-	rl.mu.Lock()
-	defer rl.mu.Unlock()
-
-	now := time.Now()
-	
-	// Clean old requests
-	requests, exists := rl.requests[key]
-	if exists {
-		var validRequests []time.Time
-		for _, req := range requests {
-			if now.Sub(req) < rl.window {
-				validRequests = append(validRequests, req)
-			}
-		}
-		rl.requests[key] = validRequests
-	}
-	
-	// Check if under limit
-	if len(rl.requests[key]) >= rl.limit {
-		return false
-	}
-	
-	// Add current request
-	rl.requests[key] = append(rl.requests[key], now)
-	return true
-}
-
 // SecurityMiddleware provides HTTP security middleware
 type SecurityMiddleware struct {
 	securityManager *SecurityManager
@@ -202,7 +174,7 @@ func (sm *SecurityMiddleware) AuthMiddleware(next http.HandlerFunc) http.Handler
 		}
 
 		// Check rate limiting
-		clientIP := getClientIP(r)
+		clientIP := sm.securityManager.proxies.ClientIP(r)
 		if !sm.securityManager.rateLimiter.Allow(clientIP) {
 			sm.logger.Warn("Rate limit exceeded", zap.String("ip", clientIP))
 			http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
@@ -283,7 +255,7 @@ func (sm *SecurityMiddleware) LoggingMiddleware(next http.HandlerFunc) http.Hand
 			zap.String("path", r.URL.Path),
 			zap.Int("status", wrapped.statusCode),
 			zap.Duration("duration", duration),
-			zap.String("ip", getClientIP(r)),
+			zap.String("ip", sm.securityManager.proxies.ClientIP(r)),
 			zap.String("user_agent", r.UserAgent()),
 		)
 	}
@@ -300,23 +272,6 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
-// getClientIP extracts the real client IP
-func getClientIP(r *http.Request) string {
-	// Check X-Forwarded-For header
-	if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
-		ips := strings.Split(xff, ",")
-		return strings.TrimSpace(ips[0])
-	}
-	
-	// Check X-Real-IP header
-	if xri := r.Header.Get("X-Real-IP"); xri != "" {
-		return xri
-	}
-	
-	// Fall back to RemoteAddr
-	return strings.Split(r.RemoteAddr, ":")[0]
-}
-
 // InputValidator provides input validation utilities
 type InputValidator struct{}
 
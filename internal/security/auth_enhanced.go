@@ -1,11 +1,16 @@
 package security
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -38,7 +43,9 @@ type EnhancedSecurityManager struct {
 	refreshExpiry time.Duration
 	logger        *zap.Logger
 	rateLimiter   *RateLimiter
+	proxies       TrustedProxies
 	auditLogger   *zap.Logger
+	revocations   RevocationStore
 }
 
 // NewEnhancedSecurityManager creates a new security manager with audit logging
@@ -51,11 +58,29 @@ func NewEnhancedSecurityManager(jwtSecret string, tokenExpiry, refreshExpiry tim
 		tokenExpiry:   tokenExpiry,
 		refreshExpiry: refreshExpiry,
 		logger:        logger,
-		rateLimiter:   NewRateLimiter(100, time.Hour), // 100 requests per hour
+		rateLimiter:   NewRateLimiter(DefaultRateLimit, DefaultRateLimitWindow),
 		auditLogger:   auditLogger,
+		revocations:   NewMemoryRevocationStore(),
 	}
 }
 
+// SetRevocationStore replaces the in-memory revocation store, e.g. with a
+// RedisRevocationStore so every instance sees a logout
+func (sm *EnhancedSecurityManager) SetRevocationStore(store RevocationStore) {
+	sm.revocations = store
+}
+
+// SetRateLimit lets each client IP make limit requests per window
+func (sm *EnhancedSecurityManager) SetRateLimit(limit int, window time.Duration) {
+	sm.rateLimiter = NewRateLimiter(limit, window)
+}
+
+// SetTrustedProxies names the proxies whose forwarded headers identify the
+// client. Without any, clients are identified by their own address.
+func (sm *EnhancedSecurityManager) SetTrustedProxies(proxies TrustedProxies) {
+	sm.proxies = proxies
+}
+
 // EnhancedClaims represents JWT claims with enhanced security
 type EnhancedClaims struct {
 	UserID    string   `json:"user_id"`
@@ -64,11 +89,27 @@ type EnhancedClaims struct {
 	SessionID string   `json:"session_id"`
 	LastLogin int64    `json:"last_login"`
 	JTI       string   `json:"jti"` // JWT ID for token revocation
+	TokenType string   `json:"token_type,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// GenerateTokenPair generates access and refresh tokens with audit logging
+// Token types, so a refresh token can't be exchanged using an access token
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// ErrTokenRevoked is returned when a token's JTI or session has been revoked
+var ErrTokenRevoked = errors.New("token revoked")
+
+// GenerateTokenPair generates access and refresh tokens for a new session
+// with audit logging
 func (sm *EnhancedSecurityManager) GenerateTokenPair(userID, username string, roles []string, ipAddress, userAgent string) (accessToken, refreshToken string, err error) {
+	return sm.issueTokenPair(userID, username, roles, sm.generateSessionID(), time.Now(), ipAddress, userAgent)
+}
+
+// issueTokenPair signs an access and refresh token for a session
+func (sm *EnhancedSecurityManager) issueTokenPair(userID, username string, roles []string, sessionID string, lastLogin time.Time, ipAddress, userAgent string) (accessToken, refreshToken string, err error) {
 	requestID := sm.generateRequestID()
 
 	// Log token generation attempt
@@ -86,7 +127,6 @@ func (sm *EnhancedSecurityManager) GenerateTokenPair(userID, username string, ro
 	})
 
 	now := time.Now()
-	sessionID := sm.generateSessionID()
 	jti := sm.generateJTI()
 
 	// Generate access token
@@ -95,8 +135,9 @@ func (sm *EnhancedSecurityManager) GenerateTokenPair(userID, username string, ro
 		Username:  username,
 		Roles:     roles,
 		SessionID: sessionID,
-		LastLogin: now.Unix(),
+		LastLogin: lastLogin.Unix(),
 		JTI:       jti,
+		TokenType: TokenTypeAccess,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(sm.tokenExpiry)),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -126,19 +167,22 @@ func (sm *EnhancedSecurityManager) GenerateTokenPair(userID, username string, ro
 	}
 
 	// Generate refresh token
+	refreshJTI := sm.generateJTI()
 	refreshClaims := &EnhancedClaims{
 		UserID:    userID,
 		Username:  username,
 		Roles:     roles,
 		SessionID: sessionID,
-		LastLogin: now.Unix(),
-		JTI:       sm.generateJTI(),
+		LastLogin: lastLogin.Unix(),
+		JTI:       refreshJTI,
+		TokenType: TokenTypeRefresh,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(now.Add(sm.refreshExpiry)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "talos-atlas",
 			Subject:   userID,
+			ID:        refreshJTI,
 		},
 	}
 
@@ -181,8 +225,15 @@ func (sm *EnhancedSecurityManager) GenerateTokenPair(userID, username string, ro
 	return accessToken, refreshToken, nil
 }
 
-// ValidateToken validates a JWT token with comprehensive audit logging
+// ValidateToken validates an access token with comprehensive audit logging.
+// Refresh tokens are rejected; they are only accepted by RefreshTokenPair
+// and Logout.
 func (sm *EnhancedSecurityManager) ValidateToken(tokenString, ipAddress, userAgent string) (*EnhancedClaims, error) {
+	return sm.validateToken(tokenString, TokenTypeAccess, ipAddress, userAgent)
+}
+
+// validateToken validates a JWT token of the given type
+func (sm *EnhancedSecurityManager) validateToken(tokenString, tokenType, ipAddress, userAgent string) (*EnhancedClaims, error) {
 	requestID := sm.generateRequestID()
 
 	// Log validation attempt
@@ -240,6 +291,55 @@ func (sm *EnhancedSecurityManager) ValidateToken(tokenString, ipAddress, userAge
 			return nil, fmt.Errorf("invalid token: missing session_id")
 		}
 
+		// Access tokens from before token types were introduced have none
+		if (claims.TokenType == TokenTypeRefresh) != (tokenType == TokenTypeRefresh) {
+			reason := "not a refresh token"
+			if tokenType == TokenTypeAccess {
+				reason = "refresh token used as an access token"
+			}
+			sm.logSecurityEvent(SecurityAuditEvent{
+				Timestamp: time.Now(),
+				EventType: "token_validation_failed",
+				UserID:    claims.UserID,
+				IPAddress: ipAddress,
+				UserAgent: userAgent,
+				Resource:  "jwt_token",
+				Action:    "validate",
+				Success:   false,
+				Reason:    reason,
+				RequestID: requestID,
+				RiskScore: 8,
+			})
+			return nil, fmt.Errorf("invalid token: %s", reason)
+		}
+
+		// Reject revoked tokens and sessions, and fail closed if revocations
+		// can't be checked
+		revoked, err := sm.isRevoked(claims)
+		if err != nil || revoked {
+			reason := ErrTokenRevoked.Error()
+			if err != nil {
+				reason = fmt.Sprintf("revocation check failed: %v", err)
+			}
+			sm.logSecurityEvent(SecurityAuditEvent{
+				Timestamp: time.Now(),
+				EventType: "token_validation_failed",
+				UserID:    claims.UserID,
+				IPAddress: ipAddress,
+				UserAgent: userAgent,
+				Resource:  "jwt_token",
+				Action:    "validate",
+				Success:   false,
+				Reason:    reason,
+				RequestID: requestID,
+				RiskScore: 8,
+			})
+			if revoked {
+				return nil, fmt.Errorf("invalid token: %w", ErrTokenRevoked)
+			}
+			return nil, fmt.Errorf("invalid token: %s", reason)
+		}
+
 		// Log successful validation
 		sm.logSecurityEvent(SecurityAuditEvent{
 			Timestamp: time.Now(),
@@ -278,6 +378,147 @@ func (sm *EnhancedSecurityManager) ValidateToken(tokenString, ipAddress, userAge
 	}
 }
 
+// RevokeToken invalidates the token with the given JWT ID until it expires
+func (sm *EnhancedSecurityManager) RevokeToken(jti string, until time.Time) error {
+	if jti == "" {
+		return fmt.Errorf("cannot revoke a token without a jti")
+	}
+	if err := sm.revocations.Revoke(context.Background(), jti, until); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", err)
+	}
+	sm.logRevocation(jti, until)
+	return nil
+}
+
+// logRevocation records a token revocation in the audit log
+func (sm *EnhancedSecurityManager) logRevocation(jti string, until time.Time) {
+	sm.logSecurityEvent(SecurityAuditEvent{
+		Timestamp: time.Now(),
+		EventType: "token_revoked",
+		Resource:  "jwt_token",
+		Action:    "revoke",
+		Success:   true,
+		RequestID: sm.generateRequestID(),
+		RiskScore: 3,
+		Metadata: map[string]interface{}{
+			"jti":   jti,
+			"until": until,
+		},
+	})
+}
+
+// sessionRevocationKey is the revocation entry that invalidates every token
+// of a session
+func sessionRevocationKey(sessionID string) string {
+	return "session:" + sessionID
+}
+
+// isRevoked reports whether a token or its whole session has been revoked
+func (sm *EnhancedSecurityManager) isRevoked(claims *EnhancedClaims) (bool, error) {
+	for _, key := range []string{claims.JTI, sessionRevocationKey(claims.SessionID)} {
+		revoked, err := sm.revocations.IsRevoked(context.Background(), key)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	return false, nil
+}
+
+// RefreshTokenPair exchanges a refresh token for a new pair in the same
+// session. The old refresh token is claimed atomically before the new pair
+// is issued, so each can be used once even by concurrent requests.
+// Presenting a revoked refresh token, or losing the claim to another
+// request, means it was copied: the whole session is revoked and the caller
+// has to log in again.
+func (sm *EnhancedSecurityManager) RefreshTokenPair(refreshToken, ipAddress, userAgent string) (accessToken, newRefreshToken string, err error) {
+	claims, err := sm.validateToken(refreshToken, TokenTypeRefresh, ipAddress, userAgent)
+	if errors.Is(err, ErrTokenRevoked) {
+		sm.handleRefreshTokenReuse(refreshToken, ipAddress, userAgent)
+		return "", "", err
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	// Rotate before issuing, so a failure can't leave the old token usable
+	claimed, err := sm.revocations.Claim(context.Background(), claims.JTI, claims.ExpiresAt.Time)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to revoke token: %w", err)
+	}
+	if !claimed {
+		sm.handleRefreshTokenReuse(refreshToken, ipAddress, userAgent)
+		return "", "", fmt.Errorf("invalid token: %w", ErrTokenRevoked)
+	}
+	sm.logRevocation(claims.JTI, claims.ExpiresAt.Time)
+
+	return sm.issueTokenPair(claims.UserID, claims.Username, claims.Roles, claims.SessionID,
+		time.Unix(claims.LastLogin, 0), ipAddress, userAgent)
+}
+
+// handleRefreshTokenReuse revokes the session of a reused refresh token,
+// cutting off both the legitimate holder and whoever copied it
+func (sm *EnhancedSecurityManager) handleRefreshTokenReuse(refreshToken, ipAddress, userAgent string) {
+	claims := &EnhancedClaims{}
+	// The signature was already verified by validateToken
+	if _, _, err := jwt.NewParser().ParseUnverified(refreshToken, claims); err != nil || claims.SessionID == "" {
+		return
+	}
+
+	// Covers every token the session can have been issued so far
+	until := time.Now().Add(sm.refreshExpiry)
+	err := sm.revocations.Revoke(context.Background(), sessionRevocationKey(claims.SessionID), until)
+
+	event := SecurityAuditEvent{
+		Timestamp: time.Now(),
+		EventType: "refresh_token_reuse",
+		UserID:    claims.UserID,
+		Username:  claims.Username,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		Resource:  "jwt_refresh_token",
+		Action:    "refresh",
+		Success:   false,
+		Reason:    "revoked refresh token reused; session revoked",
+		RequestID: sm.generateRequestID(),
+		RiskScore: 10,
+		Metadata: map[string]interface{}{
+			"session_id": claims.SessionID,
+			"jti":        claims.JTI,
+		},
+	}
+	if err != nil {
+		event.Reason = fmt.Sprintf("revoked refresh token reused; failed to revoke session: %v", err)
+	}
+	sm.logSecurityEvent(event)
+}
+
+// Logout revokes a session's access token and, if given, its refresh token
+func (sm *EnhancedSecurityManager) Logout(accessToken, refreshToken, ipAddress, userAgent string) error {
+	access, err := sm.ValidateToken(accessToken, ipAddress, userAgent)
+	if err != nil {
+		return err
+	}
+
+	var refresh *EnhancedClaims
+	if refreshToken != "" {
+		refresh, err = sm.validateToken(refreshToken, TokenTypeRefresh, ipAddress, userAgent)
+		if err != nil {
+			return fmt.Errorf("invalid refresh token: %w", err)
+		}
+		if refresh.SessionID != access.SessionID {
+			return fmt.Errorf("refresh token belongs to another session")
+		}
+	}
+
+	if err := sm.RevokeToken(access.JTI, access.ExpiresAt.Time); err != nil {
+		return err
+	}
+	if refresh != nil {
+		return sm.RevokeToken(refresh.JTI, refresh.ExpiresAt.Time)
+	}
+	return nil
+}
+
 // HashPassword hashes a password with audit logging
 func (sm *EnhancedSecurityManager) HashPassword(password string) (string, error) {
 	requestID := sm.generateRequestID()
@@ -451,14 +692,61 @@ func (sm *EnhancedSecurityManager) generateJTI() string {
 	return base64.URLEncoding.EncodeToString(b)
 }
 
+// GetLogoutHandler returns an HTTP handler that revokes the bearer access
+// token and the refresh token in the optional JSON body
+func (sm *EnhancedSecurityManager) GetLogoutHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		accessToken, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			http.Error(w, "Authorization header required", http.StatusUnauthorized)
+			return
+		}
+
+		var body struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := sm.Logout(accessToken, body.RefreshToken, sm.proxies.ClientIP(r), r.UserAgent()); err != nil {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
 // GetSecurityMiddleware returns HTTP middleware with security audit logging
 func (sm *EnhancedSecurityManager) GetSecurityMiddleware() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Extract IP address
-			ipAddress := r.RemoteAddr
-			if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
-				ipAddress = strings.Split(forwarded, ",")[0]
+			ipAddress := sm.proxies.ClientIP(r)
+
+			// Each client IP has its own budget
+			if !sm.rateLimiter.Allow(ipAddress) {
+				retryAfter := sm.rateLimiter.RetryAfter(ipAddress)
+				sm.logSecurityEvent(SecurityAuditEvent{
+					Timestamp: time.Now(),
+					EventType: "rate_limit_exceeded",
+					IPAddress: ipAddress,
+					UserAgent: r.Header.Get("User-Agent"),
+					Resource:  r.URL.Path,
+					Action:    r.Method,
+					Success:   false,
+					RequestID: sm.generateRequestID(),
+					RiskScore: 5,
+				})
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				http.Error(w, "Rate limit exceeded", http.StatusTooManyRequests)
+				return
 			}
 
 			// Log request
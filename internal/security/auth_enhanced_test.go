@@ -0,0 +1,218 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newTestSecurityManager() *EnhancedSecurityManager {
+	return NewEnhancedSecurityManager("test-secret", 15*time.Minute, time.Hour, zap.NewNop())
+}
+
+func TestValidateToken_RejectsRevokedToken(t *testing.T) {
+	sm := newTestSecurityManager()
+	access, _, err := sm.GenerateTokenPair("u-1", "alice", []string{"admin"}, "10.0.0.1", "test")
+	require.NoError(t, err)
+
+	claims, err := sm.ValidateToken(access, "10.0.0.1", "test")
+	require.NoError(t, err)
+
+	require.NoError(t, sm.RevokeToken(claims.JTI, claims.ExpiresAt.Time))
+	_, err = sm.ValidateToken(access, "10.0.0.1", "test")
+	assert.ErrorContains(t, err, "revoked", "well-formed and unexpired, but revoked")
+}
+
+func TestValidateToken_RejectsRefreshToken(t *testing.T) {
+	sm := newTestSecurityManager()
+	_, refresh, err := sm.GenerateTokenPair("u-1", "alice", []string{"admin"}, "10.0.0.1", "test")
+	require.NoError(t, err)
+
+	_, err = sm.ValidateToken(refresh, "10.0.0.1", "test")
+	assert.ErrorContains(t, err, "refresh token used as an access token")
+	_, _, err = sm.RefreshTokenPair(refresh, "10.0.0.1", "test")
+	assert.NoError(t, err, "still accepted for a refresh")
+}
+
+func TestRevocationStores_ClaimOnce(t *testing.T) {
+	server := miniredis.RunT(t)
+	stores := map[string]RevocationStore{
+		"memory": NewMemoryRevocationStore(),
+		"redis":  NewRedisRevocationStore(redis.NewClient(&redis.Options{Addr: server.Addr()})),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			until := time.Now().Add(time.Hour)
+			claimed, err := store.Claim(t.Context(), "jti-1", until)
+			require.NoError(t, err)
+			assert.True(t, claimed)
+			revoked, err := store.IsRevoked(t.Context(), "jti-1")
+			require.NoError(t, err)
+			assert.True(t, revoked)
+
+			claimed, err = store.Claim(t.Context(), "jti-1", until)
+			require.NoError(t, err)
+			assert.False(t, claimed, "already claimed")
+
+			require.NoError(t, store.Revoke(t.Context(), "jti-2", until))
+			claimed, err = store.Claim(t.Context(), "jti-2", until)
+			require.NoError(t, err)
+			assert.False(t, claimed, "already revoked")
+		})
+	}
+}
+
+func TestMemoryRevocationStore_ForgetsExpiredTokens(t *testing.T) {
+	store := NewMemoryRevocationStore()
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.Revoke(t.Context(), "jti-1", now.Add(time.Minute)))
+	revoked, _ := store.IsRevoked(t.Context(), "jti-1")
+	assert.True(t, revoked)
+
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, store.Revoke(t.Context(), "jti-2", now.Add(time.Minute)))
+	revoked, _ = store.IsRevoked(t.Context(), "jti-1")
+	assert.False(t, revoked)
+	assert.NotContains(t, store.revoked, "jti-1")
+}
+
+func TestLogoutHandler_RevokesBothTokens(t *testing.T) {
+	sm := newTestSecurityManager()
+	access, refresh, err := sm.GenerateTokenPair("u-1", "alice", nil, "10.0.0.1", "test")
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/logout", strings.NewReader(`{"refresh_token": "`+refresh+`"}`))
+	req.Header.Set("Authorization", "Bearer "+access)
+	rec := httptest.NewRecorder()
+	sm.GetLogoutHandler()(rec, req)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+
+	_, err = sm.ValidateToken(access, "10.0.0.1", "test")
+	assert.Error(t, err)
+	_, err = sm.ValidateToken(refresh, "10.0.0.1", "test")
+	assert.Error(t, err)
+
+	// Logging out again with the revoked token fails
+	req = httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+access)
+	rec = httptest.NewRecorder()
+	sm.GetLogoutHandler()(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestLogout_RejectsRefreshTokenFromAnotherSession(t *testing.T) {
+	sm := newTestSecurityManager()
+	access, _, err := sm.GenerateTokenPair("u-1", "alice", nil, "10.0.0.1", "test")
+	require.NoError(t, err)
+	_, otherRefresh, err := sm.GenerateTokenPair("u-2", "bob", nil, "10.0.0.2", "test")
+	require.NoError(t, err)
+
+	assert.Error(t, sm.Logout(access, otherRefresh, "10.0.0.1", "test"))
+	_, err = sm.ValidateToken(access, "10.0.0.1", "test")
+	assert.NoError(t, err, "nothing revoked")
+}
+
+func TestRefreshTokenPair_RotatesWithinSession(t *testing.T) {
+	sm := newTestSecurityManager()
+	access, refresh, err := sm.GenerateTokenPair("u-1", "alice", []string{"admin"}, "10.0.0.1", "test")
+	require.NoError(t, err)
+	original, err := sm.ValidateToken(access, "10.0.0.1", "test")
+	require.NoError(t, err)
+
+	newAccess, newRefresh, err := sm.RefreshTokenPair(refresh, "10.0.0.1", "test")
+	require.NoError(t, err)
+
+	claims, err := sm.ValidateToken(newAccess, "10.0.0.1", "test")
+	require.NoError(t, err)
+	assert.Equal(t, original.SessionID, claims.SessionID)
+	assert.Equal(t, []string{"admin"}, claims.Roles)
+	assert.Equal(t, original.LastLogin, claims.LastLogin)
+
+	old := &EnhancedClaims{}
+	_, _, err = jwt.NewParser().ParseUnverified(refresh, old)
+	require.NoError(t, err)
+	revoked, err := sm.revocations.IsRevoked(t.Context(), old.JTI)
+	require.NoError(t, err)
+	assert.True(t, revoked, "rotated out")
+	_, _, err = sm.RefreshTokenPair(newRefresh, "10.0.0.1", "test")
+	assert.NoError(t, err, "the new refresh token works once")
+}
+
+func TestRefreshTokenPair_RejectsAccessToken(t *testing.T) {
+	sm := newTestSecurityManager()
+	access, _, err := sm.GenerateTokenPair("u-1", "alice", nil, "10.0.0.1", "test")
+	require.NoError(t, err)
+
+	_, _, err = sm.RefreshTokenPair(access, "10.0.0.1", "test")
+	assert.ErrorContains(t, err, "not a refresh token")
+}
+
+func TestRefreshTokenPair_ReuseRevokesSession(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	sm := NewEnhancedSecurityManager("test-secret", 15*time.Minute, time.Hour, zap.New(core))
+	_, refresh, err := sm.GenerateTokenPair("u-1", "alice", nil, "10.0.0.1", "test")
+	require.NoError(t, err)
+
+	newAccess, newRefresh, err := sm.RefreshTokenPair(refresh, "10.0.0.1", "test")
+	require.NoError(t, err)
+
+	// Someone replays the rotated token
+	_, _, err = sm.RefreshTokenPair(refresh, "203.0.113.9", "curl")
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	reuse := logs.FilterField(zap.String("event_type", "refresh_token_reuse")).All()
+	require.Len(t, reuse, 1)
+	assert.Equal(t, zapcore.ErrorLevel, reuse[0].Level)
+	assert.Equal(t, "high_risk_security_event", reuse[0].Message)
+
+	// The legitimate holder's tokens are cut off too
+	_, err = sm.ValidateToken(newAccess, "10.0.0.1", "test")
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	_, _, err = sm.RefreshTokenPair(newRefresh, "10.0.0.1", "test")
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+}
+
+func TestRefreshTokenPair_ConcurrentRefreshesIssueOnePair(t *testing.T) {
+	sm := newTestSecurityManager()
+	_, refresh, err := sm.GenerateTokenPair("u-1", "alice", nil, "10.0.0.1", "test")
+	require.NoError(t, err)
+
+	const attempts = 20
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var issued []string
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			access, _, err := sm.RefreshTokenPair(refresh, "10.0.0.1", "test")
+			if err == nil {
+				mu.Lock()
+				issued = append(issued, access)
+				mu.Unlock()
+			} else {
+				assert.ErrorIs(t, err, ErrTokenRevoked)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The losers count as reuse, so the winner's session is revoked as well
+	require.Len(t, issued, 1)
+	_, err = sm.ValidateToken(issued[0], "10.0.0.1", "test")
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+}
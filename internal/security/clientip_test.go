@@ -0,0 +1,63 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTrustedProxies(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8", " 192.0.2.7 ", "2001:db8::1"})
+	require.NoError(t, err)
+
+	assert.True(t, proxies.Contains("10.20.30.40"))
+	assert.True(t, proxies.Contains("192.0.2.7"))
+	assert.False(t, proxies.Contains("192.0.2.8"), "a bare IP trusts only itself")
+	assert.True(t, proxies.Contains("2001:db8::1"))
+	assert.False(t, proxies.Contains("2001:db8::2"))
+	assert.False(t, proxies.Contains("not-an-ip"))
+
+	_, err = ParseTrustedProxies([]string{"lb.internal"})
+	assert.ErrorContains(t, err, `invalid trusted proxy "lb.internal"`)
+}
+
+func TestTrustedProxies_ClientIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		proxies    TrustedProxies
+		remoteAddr string
+		xff        string
+		realIP     string
+		want       string
+	}{
+		{"no proxies configured", nil, "203.0.113.1:40000", "198.51.100.9", "198.51.100.9", "203.0.113.1"},
+		{"untrusted peer spoofing headers", proxies, "203.0.113.1:40000", "198.51.100.9", "198.51.100.9", "203.0.113.1"},
+		{"trusted proxy", proxies, "10.0.0.5:443", "198.51.100.9", "", "198.51.100.9"},
+		{"client-written prefix is skipped", proxies, "10.0.0.5:443", "1.2.3.4, 198.51.100.9", "", "198.51.100.9"},
+		{"chain of trusted proxies", proxies, "10.0.0.5:443", "198.51.100.9, 10.1.1.1", "", "198.51.100.9"},
+		{"malformed hop stops the walk", proxies, "10.0.0.5:443", "198.51.100.9, garbage", "", "10.0.0.5"},
+		{"X-Real-IP from a trusted proxy", proxies, "10.0.0.5:443", "", "198.51.100.9", "198.51.100.9"},
+		{"no headers from a trusted proxy", proxies, "10.0.0.5:443", "", "", "10.0.0.5"},
+		{"IPv6 peer", nil, "[2001:db8::1]:40000", "", "", "2001:db8::1"},
+		{"RemoteAddr without a port", nil, "203.0.113.1", "", "", "203.0.113.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if tt.realIP != "" {
+				req.Header.Set("X-Real-IP", tt.realIP)
+			}
+			assert.Equal(t, tt.want, tt.proxies.ClientIP(req))
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package security
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter implements thread-safe rate limiting. Each identity (an IP
+// address or user ID) gets its own budget of limit requests in any sliding
+// window.
+type RateLimiter struct {
+	mu       sync.Mutex
+	requests map[string][]time.Time
+	limit    int
+	window   time.Duration
+	now      func() time.Time
+}
+
+// NewRateLimiter creates a new rate limiter
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		requests: make(map[string][]time.Time),
+		limit:    limit,
+		window:   window,
+		now:      time.Now,
+	}
+}
+
+// Allow records a request for key and reports whether it is within the
+// key's budget. Rejected requests don't count against it.
+func (rl *RateLimiter) Allow(key string) bool {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	requests := rl.prune(key, now)
+	if len(requests) >= rl.limit {
+		return false
+	}
+	rl.requests[key] = append(requests, now)
+	return true
+}
+
+// Remaining returns how many more requests key may make in the current window
+func (rl *RateLimiter) Remaining(key string) int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	return max(0, rl.limit-len(rl.prune(key, rl.now())))
+}
+
+// RetryAfter returns how long key has to wait before its next request is
+// allowed, or zero if it may make one now
+func (rl *RateLimiter) RetryAfter(key string) time.Duration {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := rl.now()
+	requests := rl.prune(key, now)
+	if len(requests) < rl.limit {
+		return 0
+	}
+	// A slot frees up when the oldest request that fills the budget leaves
+	// the window
+	return requests[len(requests)-rl.limit].Add(rl.window).Sub(now)
+}
+
+// prune drops key's requests that have left the window and returns the rest.
+// Keys without recent requests are forgotten. The caller must hold rl.mu.
+func (rl *RateLimiter) prune(key string, now time.Time) []time.Time {
+	requests := rl.requests[key]
+	i := 0
+	for i < len(requests) && now.Sub(requests[i]) >= rl.window {
+		i++
+	}
+	requests = requests[i:]
+	if len(requests) == 0 {
+		delete(rl.requests, key)
+		return nil
+	}
+	rl.requests[key] = requests
+	return requests
+}
@@ -0,0 +1,100 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRateLimiter(limit int) (*RateLimiter, *time.Time) {
+	rl := NewRateLimiter(limit, time.Hour)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	rl.now = func() time.Time { return now }
+	return rl, &now
+}
+
+func TestRateLimiter_IndependentKeys(t *testing.T) {
+	rl, _ := newTestRateLimiter(2)
+
+	assert.True(t, rl.Allow("10.0.0.1"))
+	assert.True(t, rl.Allow("10.0.0.1"))
+	assert.False(t, rl.Allow("10.0.0.1"))
+	assert.Zero(t, rl.Remaining("10.0.0.1"))
+
+	assert.Equal(t, 2, rl.Remaining("10.0.0.2"), "untouched by another key")
+	assert.True(t, rl.Allow("10.0.0.2"))
+	assert.Equal(t, 1, rl.Remaining("10.0.0.2"))
+}
+
+func TestRateLimiter_SlidingWindow(t *testing.T) {
+	rl, now := newTestRateLimiter(2)
+
+	rl.Allow("user-1")
+	*now = now.Add(30 * time.Minute)
+	rl.Allow("user-1")
+	assert.False(t, rl.Allow("user-1"))
+	assert.Equal(t, 30*time.Minute, rl.RetryAfter("user-1"))
+
+	// The first request leaves the window; the second still counts
+	*now = now.Add(30 * time.Minute)
+	assert.Equal(t, 1, rl.Remaining("user-1"))
+	assert.Zero(t, rl.RetryAfter("user-1"))
+	assert.True(t, rl.Allow("user-1"))
+	assert.False(t, rl.Allow("user-1"))
+
+	*now = now.Add(2 * time.Hour)
+	assert.Equal(t, 2, rl.Remaining("user-1"))
+	assert.Empty(t, rl.requests, "idle keys are forgotten")
+}
+
+func TestSecurityMiddleware_RateLimitsPerIP(t *testing.T) {
+	sm := newTestSecurityManager()
+	rl, _ := newTestRateLimiter(1)
+	sm.rateLimiter = rl
+	handler := sm.GetSecurityMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/resources", nil)
+		req.RemoteAddr = remoteAddr
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, request("203.0.113.1:40000").Code)
+	limited := request("203.0.113.1:40001")
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code, "a new port is the same client")
+	assert.Equal(t, "3600", limited.Header().Get("Retry-After"))
+
+	assert.Equal(t, http.StatusOK, request("[2001:db8::1]:40000").Code)
+}
+
+func TestSecurityMiddleware_ForwardedHeadersDontEvadeLimit(t *testing.T) {
+	sm := newTestSecurityManager()
+	sm.SetRateLimit(1, time.Minute)
+	handler := sm.GetSecurityMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := func(remoteAddr, forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/resources", nil)
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, request("203.0.113.1:40000", "198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, request("203.0.113.1:40000", "198.51.100.2"), "a direct client can't pick its own key")
+
+	// Behind a trusted load balancer each forwarded client has its own budget
+	proxies, err := ParseTrustedProxies([]string{"10.0.0.0/8"})
+	require.NoError(t, err)
+	sm.SetTrustedProxies(proxies)
+	assert.Equal(t, http.StatusOK, request("10.0.0.5:443", "198.51.100.1"))
+	assert.Equal(t, http.StatusOK, request("10.0.0.5:443", "198.51.100.2"))
+	assert.Equal(t, http.StatusTooManyRequests, request("10.0.0.5:443", "198.51.100.1"))
+}
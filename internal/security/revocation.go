@@ -0,0 +1,109 @@
+package security
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RevocationStore records revoked JWT IDs until the tokens would have
+// expired anyway
+type RevocationStore interface {
+	Revoke(ctx context.Context, jti string, until time.Time) error
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+	// Claim atomically revokes jti unless it already is, and reports whether
+	// this call revoked it. Of concurrent claims exactly one succeeds.
+	Claim(ctx context.Context, jti string, until time.Time) (bool, error)
+}
+
+// MemoryRevocationStore keeps revocations in process memory. Use it for
+// tests and single-instance deployments.
+type MemoryRevocationStore struct {
+	mu      sync.Mutex
+	revoked map[string]time.Time
+	now     func() time.Time
+}
+
+// NewMemoryRevocationStore creates an empty in-memory revocation store
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{revoked: make(map[string]time.Time), now: time.Now}
+}
+
+func (s *MemoryRevocationStore) Revoke(_ context.Context, jti string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune()
+	s.revoked[jti] = until
+	return nil
+}
+
+func (s *MemoryRevocationStore) Claim(_ context.Context, jti string, until time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.prune()
+	if _, revoked := s.revoked[jti]; revoked {
+		return false, nil
+	}
+	s.revoked[jti] = until
+	return true, nil
+}
+
+// prune drops revocations whose tokens have expired. s.mu must be held.
+func (s *MemoryRevocationStore) prune() {
+	now := s.now()
+	for id, expiry := range s.revoked {
+		if !expiry.After(now) {
+			delete(s.revoked, id)
+		}
+	}
+}
+
+func (s *MemoryRevocationStore) IsRevoked(_ context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until, ok := s.revoked[jti]
+	return ok && until.After(s.now()), nil
+}
+
+// RedisRevocationStore shares revocations between instances. Each entry
+// expires with the token it revokes.
+type RedisRevocationStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedisRevocationStore creates a revocation store backed by client
+func NewRedisRevocationStore(client *redis.Client) *RedisRevocationStore {
+	return &RedisRevocationStore{client: client, prefix: "talos:revoked:"}
+}
+
+func (s *RedisRevocationStore) Revoke(ctx context.Context, jti string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		// Already expired, nothing to revoke
+		return nil
+	}
+	return s.client.Set(ctx, s.prefix+jti, until.Unix(), ttl).Err()
+}
+
+func (s *RedisRevocationStore) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	n, err := s.client.Exists(ctx, s.prefix+jti).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+func (s *RedisRevocationStore) Claim(ctx context.Context, jti string, until time.Time) (bool, error) {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		// An expired token can't be used, so it can't be claimed either
+		return false, nil
+	}
+	return s.client.SetNX(ctx, s.prefix+jti, until.Unix(), ttl).Result()
+}
@@ -0,0 +1,350 @@
+// Package taskstate records the state of distributed tasks in Redis so the
+// manager API can list and look them up. The manager creates a task's state
+// when it enqueues the task and workers update it as they process it.
+//
+// Each task is a hash, tasks:state:<id>, that expires Retention after the
+// task was created. tasks:index orders every task by creation time and
+// tasks:index:<status> does the same per status, so listing pages through a
+// sorted set instead of scanning keys.
+package taskstate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Retention is how long a task's state is kept after it was created
+const Retention = 24 * time.Hour
+
+// MaxPageSize caps how many tasks List returns
+const MaxPageSize = 1000
+
+const (
+	statePrefix = "tasks:state:"
+	indexKey    = "tasks:index"
+)
+
+// Status is where a task is in its lifecycle
+type Status string
+
+const (
+	StatusQueued     Status = "queued" // waiting in a queue, including for a retry
+	StatusProcessing Status = "processing"
+	StatusCompleted  Status = "completed"
+	StatusDead       Status = "dead" // exhausted its attempts; see tasks:dead
+)
+
+// Statuses lists every status
+var Statuses = []Status{StatusQueued, StatusProcessing, StatusCompleted, StatusDead}
+
+// Valid reports whether s is a known status
+func (s Status) Valid() bool {
+	for _, status := range Statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrNotFound is returned for a task with no recorded state
+var ErrNotFound = errors.New("task not found")
+
+// State is the recorded state of a task
+type State struct {
+	ID          string                 `json:"id"`
+	Type        string                 `json:"type"`
+	Priority    int                    `json:"priority"`
+	Payload     map[string]interface{} `json:"payload,omitempty"`
+	Status      Status                 `json:"status"`
+	Attempts    int                    `json:"attempts"`
+	MaxAttempts int                    `json:"max_attempts"`
+	WorkerID    string                 `json:"worker_id,omitempty"`
+	Result      string                 `json:"result,omitempty"`
+	Error       string                 `json:"error,omitempty"` // the last failure
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
+
+// Update is a worker's change to a task's state. An empty Result or Error
+// leaves the recorded one in place.
+type Update struct {
+	Status   Status
+	Attempts int
+	WorkerID string
+	Result   string
+	Error    string
+}
+
+// ListOptions selects a page of tasks, newest first
+type ListOptions struct {
+	Status Status // all statuses when empty
+	Offset int64
+	Limit  int64
+}
+
+// Page is one page of tasks
+type Page struct {
+	Tasks  []State `json:"tasks"`
+	Total  int64   `json:"total"` // tasks matching the filter across all pages
+	Offset int64   `json:"offset"`
+	Limit  int64   `json:"limit"`
+}
+
+func stateKey(id string) string {
+	return statePrefix + id
+}
+
+func statusIndexKey(status Status) string {
+	return indexKey + ":" + string(status)
+}
+
+// Create records a newly enqueued task, replacing any earlier task with the
+// same ID, and drops index entries older than Retention
+func Create(ctx context.Context, rdb redis.Cmdable, state State) error {
+	if state.UpdatedAt.IsZero() {
+		state.UpdatedAt = state.CreatedAt
+	}
+	payload, err := json.Marshal(state.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	key := stateKey(state.ID)
+	member := &redis.Z{Score: float64(state.CreatedAt.UnixMilli()), Member: state.ID}
+	cutoff := fmt.Sprintf("(%d", state.CreatedAt.Add(-Retention).UnixMilli())
+
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.HSet(ctx, key,
+		"id", state.ID,
+		"type", state.Type,
+		"priority", state.Priority,
+		"payload", payload,
+		"status", string(state.Status),
+		"attempts", state.Attempts,
+		"max_attempts", state.MaxAttempts,
+		"worker_id", state.WorkerID,
+		"result", state.Result,
+		"error", state.Error,
+		"created_at", state.CreatedAt.Format(time.RFC3339Nano),
+		"updated_at", state.UpdatedAt.Format(time.RFC3339Nano),
+	)
+	pipe.Expire(ctx, key, Retention)
+	pipe.ZAdd(ctx, indexKey, member)
+	pipe.ZRemRangeByScore(ctx, indexKey, "-inf", cutoff)
+	for _, status := range Statuses {
+		pipe.ZRem(ctx, statusIndexKey(status), state.ID)
+		pipe.ZRemRangeByScore(ctx, statusIndexKey(status), "-inf", cutoff)
+	}
+	pipe.ZAdd(ctx, statusIndexKey(state.Status), member)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record task %s: %w", state.ID, err)
+	}
+	return nil
+}
+
+// Delete removes a task's state, for a task that never made it into a queue
+func Delete(ctx context.Context, rdb redis.Cmdable, id string) error {
+	pipe := rdb.TxPipeline()
+	pipe.Del(ctx, stateKey(id))
+	pipe.ZRem(ctx, indexKey, id)
+	for _, status := range Statuses {
+		pipe.ZRem(ctx, statusIndexKey(status), id)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete task %s: %w", id, err)
+	}
+	return nil
+}
+
+// updateScript applies an update to the hash KEYS[1] of task ARGV[1] and,
+// when the status changes, moves the task from one status index to the
+// other under the score it has in KEYS[2]. ARGV[2] is the status index
+// prefix, ARGV[3] the new status and the rest field/value pairs. A task with
+// no state is left alone, so a task enqueued before state was recorded, or
+// whose state expired, is not recreated half empty.
+var updateScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+local old = redis.call('HGET', KEYS[1], 'status')
+for i = 4, #ARGV, 2 do
+	redis.call('HSET', KEYS[1], ARGV[i], ARGV[i + 1])
+end
+redis.call('HSET', KEYS[1], 'status', ARGV[3])
+if old ~= ARGV[3] then
+	local score = redis.call('ZSCORE', KEYS[2], ARGV[1])
+	if old then
+		redis.call('ZREM', ARGV[2] .. old, ARGV[1])
+	end
+	if score then
+		redis.call('ZADD', ARGV[2] .. ARGV[3], score, ARGV[1])
+	end
+end
+return 1
+`)
+
+// Apply records a worker's update to a task. It is a no-op for a task with
+// no recorded state.
+func Apply(ctx context.Context, rdb redis.Scripter, id string, update Update) error {
+	args := []interface{}{
+		id, indexKey + ":", string(update.Status),
+		"attempts", update.Attempts,
+		"worker_id", update.WorkerID,
+		"updated_at", time.Now().Format(time.RFC3339Nano),
+	}
+	if update.Result != "" {
+		args = append(args, "result", update.Result)
+	}
+	if update.Error != "" {
+		args = append(args, "error", update.Error)
+	}
+
+	if err := updateScript.Run(ctx, rdb, []string{stateKey(id), indexKey}, args...).Err(); err != nil {
+		return fmt.Errorf("failed to update task %s: %w", id, err)
+	}
+	return nil
+}
+
+// Get returns the state of a task, or ErrNotFound
+func Get(ctx context.Context, rdb redis.Cmdable, id string) (State, error) {
+	fields, err := rdb.HGetAll(ctx, stateKey(id)).Result()
+	if err != nil {
+		return State{}, fmt.Errorf("failed to get task %s: %w", id, err)
+	}
+	if len(fields) == 0 {
+		return State{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return decode(fields)
+}
+
+// List returns a page of tasks, newest first. Index entries whose state has
+// expired are dropped as they are found, so a page can come back short.
+func List(ctx context.Context, rdb redis.Cmdable, opts ListOptions) (Page, error) {
+	if opts.Limit <= 0 || opts.Limit > MaxPageSize {
+		opts.Limit = MaxPageSize
+	}
+	if opts.Offset < 0 {
+		opts.Offset = 0
+	}
+	key := indexKey
+	if opts.Status != "" {
+		key = statusIndexKey(opts.Status)
+	}
+
+	page := Page{Tasks: []State{}, Offset: opts.Offset, Limit: opts.Limit}
+	total, err := rdb.ZCard(ctx, key).Result()
+	if err != nil {
+		return page, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	page.Total = total
+
+	ids, err := rdb.ZRevRange(ctx, key, opts.Offset, opts.Offset+opts.Limit-1).Result()
+	if err != nil {
+		return page, fmt.Errorf("failed to list tasks: %w", err)
+	}
+	if len(ids) == 0 {
+		return page, nil
+	}
+
+	pipe := rdb.Pipeline()
+	cmds := make([]*redis.StringStringMapCmd, len(ids))
+	for i, id := range ids {
+		cmds[i] = pipe.HGetAll(ctx, stateKey(id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return page, fmt.Errorf("failed to get tasks: %w", err)
+	}
+
+	var expired []interface{}
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if len(fields) == 0 {
+			expired = append(expired, ids[i])
+			continue
+		}
+		state, err := decode(fields)
+		if err != nil {
+			return page, err
+		}
+		page.Tasks = append(page.Tasks, state)
+	}
+
+	if len(expired) > 0 {
+		pipe := rdb.Pipeline()
+		pipe.ZRem(ctx, indexKey, expired...)
+		for _, status := range Statuses {
+			pipe.ZRem(ctx, statusIndexKey(status), expired...)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return page, fmt.Errorf("failed to drop expired tasks: %w", err)
+		}
+		page.Total -= int64(len(expired))
+	}
+	return page, nil
+}
+
+// decode parses a task's hash
+func decode(fields map[string]string) (State, error) {
+	state := State{
+		ID:       fields["id"],
+		Type:     fields["type"],
+		Status:   Status(fields["status"]),
+		WorkerID: fields["worker_id"],
+		Result:   fields["result"],
+		Error:    fields["error"],
+	}
+
+	var err error
+	if state.Priority, err = atoi(fields, "priority"); err != nil {
+		return state, err
+	}
+	if state.Attempts, err = atoi(fields, "attempts"); err != nil {
+		return state, err
+	}
+	if state.MaxAttempts, err = atoi(fields, "max_attempts"); err != nil {
+		return state, err
+	}
+	if state.CreatedAt, err = parseTime(fields, "created_at"); err != nil {
+		return state, err
+	}
+	if state.UpdatedAt, err = parseTime(fields, "updated_at"); err != nil {
+		return state, err
+	}
+	if payload := fields["payload"]; payload != "" {
+		if err := json.Unmarshal([]byte(payload), &state.Payload); err != nil {
+			return state, fmt.Errorf("task %s has a bad payload: %w", state.ID, err)
+		}
+	}
+	return state, nil
+}
+
+func atoi(fields map[string]string, name string) (int, error) {
+	if fields[name] == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(fields[name])
+	if err != nil {
+		return 0, fmt.Errorf("task %s has a bad %s: %w", fields["id"], name, err)
+	}
+	return n, nil
+}
+
+func parseTime(fields map[string]string, name string) (time.Time, error) {
+	if fields[name] == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, fields[name])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("task %s has a bad %s: %w", fields["id"], name, err)
+	}
+	return t, nil
+}
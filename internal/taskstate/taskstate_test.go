@@ -0,0 +1,180 @@
+package taskstate
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T) (*redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client, server
+}
+
+// createTasks records count queued tasks a second apart, task-0 oldest
+func createTasks(t *testing.T, client *redis.Client, count int) {
+	t.Helper()
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < count; i++ {
+		require.NoError(t, Create(context.Background(), client, State{
+			ID:          fmt.Sprintf("task-%d", i),
+			Type:        "scan",
+			Status:      StatusQueued,
+			MaxAttempts: 3,
+			CreatedAt:   start.Add(time.Duration(i) * time.Second),
+		}))
+	}
+}
+
+func ids(page Page) []string {
+	ids := make([]string, len(page.Tasks))
+	for i, task := range page.Tasks {
+		ids[i] = task.ID
+	}
+	return ids
+}
+
+func TestCreateAndGet(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestClient(t)
+	created := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, Create(ctx, client, State{
+		ID:          "task-1",
+		Type:        "scan",
+		Priority:    7,
+		Payload:     map[string]interface{}{"provider": "aws"},
+		Status:      StatusQueued,
+		MaxAttempts: 3,
+		CreatedAt:   created,
+	}))
+
+	state, err := Get(ctx, client, "task-1")
+	require.NoError(t, err)
+	assert.Equal(t, "scan", state.Type)
+	assert.Equal(t, 7, state.Priority)
+	assert.Equal(t, "aws", state.Payload["provider"])
+	assert.Equal(t, StatusQueued, state.Status)
+	assert.Equal(t, 3, state.MaxAttempts)
+	assert.True(t, created.Equal(state.CreatedAt))
+	assert.True(t, created.Equal(state.UpdatedAt))
+}
+
+func TestGet_NotFound(t *testing.T) {
+	client, _ := newTestClient(t)
+
+	_, err := Get(context.Background(), client, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestApply_MovesBetweenStatusIndexes(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestClient(t)
+	createTasks(t, client, 2)
+
+	require.NoError(t, Apply(ctx, client, "task-1", Update{Status: StatusProcessing, Attempts: 0, WorkerID: "worker-1"}))
+	require.NoError(t, Apply(ctx, client, "task-1", Update{Status: StatusQueued, Attempts: 1, WorkerID: "worker-1", Error: "boom"}))
+	require.NoError(t, Apply(ctx, client, "task-1", Update{Status: StatusProcessing, Attempts: 1, WorkerID: "worker-2"}))
+	require.NoError(t, Apply(ctx, client, "task-1", Update{Status: StatusCompleted, Attempts: 1, WorkerID: "worker-2", Result: "done"}))
+
+	state, err := Get(ctx, client, "task-1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusCompleted, state.Status)
+	assert.Equal(t, 1, state.Attempts)
+	assert.Equal(t, "worker-2", state.WorkerID)
+	assert.Equal(t, "done", state.Result)
+	assert.Equal(t, "boom", state.Error, "the last failure is kept")
+	assert.True(t, state.UpdatedAt.After(state.CreatedAt))
+
+	completed, err := List(ctx, client, ListOptions{Status: StatusCompleted})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task-1"}, ids(completed))
+	queued, err := List(ctx, client, ListOptions{Status: StatusQueued})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task-0"}, ids(queued))
+	processing, err := List(ctx, client, ListOptions{Status: StatusProcessing})
+	require.NoError(t, err)
+	assert.Empty(t, processing.Tasks)
+	assert.Zero(t, processing.Total)
+}
+
+func TestApply_IgnoresUnknownTask(t *testing.T) {
+	ctx := context.Background()
+	client, server := newTestClient(t)
+
+	require.NoError(t, Apply(ctx, client, "missing", Update{Status: StatusCompleted}))
+	assert.False(t, server.Exists(stateKey("missing")))
+	assert.False(t, server.Exists(statusIndexKey(StatusCompleted)))
+}
+
+func TestList_Pages(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestClient(t)
+	createTasks(t, client, 5)
+
+	var seen []string
+	for offset := int64(0); ; offset += 2 {
+		page, err := List(ctx, client, ListOptions{Offset: offset, Limit: 2})
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), page.Total)
+		if len(page.Tasks) == 0 {
+			break
+		}
+		assert.LessOrEqual(t, len(page.Tasks), 2)
+		seen = append(seen, ids(page)...)
+	}
+	assert.Equal(t, []string{"task-4", "task-3", "task-2", "task-1", "task-0"}, seen, "newest first")
+}
+
+func TestList_DropsExpiredTasks(t *testing.T) {
+	ctx := context.Background()
+	client, server := newTestClient(t)
+	createTasks(t, client, 2)
+	server.FastForward(Retention + time.Minute)
+
+	page, err := List(ctx, client, ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, page.Tasks)
+	assert.Zero(t, page.Total)
+	assert.False(t, server.Exists(indexKey))
+}
+
+func TestCreate_ReplacesEarlierTask(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestClient(t)
+	createTasks(t, client, 1)
+	require.NoError(t, Apply(ctx, client, "task-0", Update{Status: StatusDead, Attempts: 3, Error: "boom"}))
+
+	// Enqueued again once its dedup window passed
+	require.NoError(t, Create(ctx, client, State{ID: "task-0", Status: StatusQueued, CreatedAt: time.Now()}))
+
+	state, err := Get(ctx, client, "task-0")
+	require.NoError(t, err)
+	assert.Equal(t, StatusQueued, state.Status)
+	assert.Empty(t, state.Error)
+	dead, err := List(ctx, client, ListOptions{Status: StatusDead})
+	require.NoError(t, err)
+	assert.Zero(t, dead.Total)
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	client, _ := newTestClient(t)
+	createTasks(t, client, 2)
+
+	require.NoError(t, Delete(ctx, client, "task-1"))
+	_, err := Get(ctx, client, "task-1")
+	assert.ErrorIs(t, err, ErrNotFound)
+	page, err := List(ctx, client, ListOptions{Status: StatusQueued})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"task-0"}, ids(page))
+}
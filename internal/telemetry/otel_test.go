@@ -0,0 +1,170 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	collectortrace "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// traceIDAt returns a trace ID whose ratio-sampling position is fraction
+// of the way through the ID space
+func traceIDAt(fraction float64) trace.TraceID {
+	var id trace.TraceID
+	id[0] = 1 // a valid ID is never all zeros
+	binary.BigEndian.PutUint64(id[8:], uint64(fraction*(1<<63))<<1)
+	return id
+}
+
+func decide(sampler sdktrace.Sampler, parent context.Context, id trace.TraceID) sdktrace.SamplingDecision {
+	return sampler.ShouldSample(sdktrace.SamplingParameters{ParentContext: parent, TraceID: id, Name: "op"}).Decision
+}
+
+func TestSampler_KeepsTheConfiguredRatio(t *testing.T) {
+	sampler := Sampler(0.25)
+
+	assert.Equal(t, sdktrace.RecordAndSample, decide(sampler, context.Background(), traceIDAt(0.1)))
+	assert.Equal(t, sdktrace.Drop, decide(sampler, context.Background(), traceIDAt(0.9)))
+
+	sampled := 0
+	const traces = 1000
+	for i := 0; i < traces; i++ {
+		if decide(sampler, context.Background(), traceIDAt(float64(i)/traces)) == sdktrace.RecordAndSample {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 250, sampled, 2)
+}
+
+func TestSampler_FollowsTheParent(t *testing.T) {
+	parent := func(flags trace.TraceFlags) context.Context {
+		return trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceIDAt(0.9),
+			SpanID:     trace.SpanID{1},
+			TraceFlags: flags,
+			Remote:     true,
+		}))
+	}
+
+	// An upstream decision wins over the ratio either way
+	assert.Equal(t, sdktrace.RecordAndSample, decide(Sampler(0), parent(trace.FlagsSampled), traceIDAt(0.9)))
+	assert.Equal(t, sdktrace.Drop, decide(Sampler(1), parent(0), traceIDAt(0.1)))
+}
+
+// keepGlobalProvider restores the global tracer provider Init replaces
+func keepGlobalProvider(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+}
+
+func TestInit_DisabledIsNoop(t *testing.T) {
+	keepGlobalProvider(t)
+
+	shutdown, err := Init(TelemetryConfig{Enabled: false})
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "op")
+	assert.False(t, span.IsRecording())
+	span.End()
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestInit_RejectsInvalidConfig(t *testing.T) {
+	_, err := Init(TelemetryConfig{Enabled: true, SampleRate: 1.5})
+	assert.ErrorContains(t, err, "sample rate")
+	_, err = Init(TelemetryConfig{Enabled: true, SampleRate: 1, OTLPProtocol: "udp"})
+	assert.ErrorContains(t, err, "unknown OTLP protocol")
+}
+
+// fakeCollector accepts OTLP spans over gRPC and HTTP and counts them
+type fakeCollector struct {
+	collectortrace.UnimplementedTraceServiceServer
+	spans atomic.Int64
+}
+
+func (c *fakeCollector) Export(_ context.Context, req *collectortrace.ExportTraceServiceRequest) (*collectortrace.ExportTraceServiceResponse, error) {
+	for _, rs := range req.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			c.spans.Add(int64(len(ss.Spans)))
+		}
+	}
+	return &collectortrace.ExportTraceServiceResponse{}, nil
+}
+
+func (c *fakeCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	var req collectortrace.ExportTraceServiceRequest
+	if err := proto.Unmarshal(body, &req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.Export(r.Context(), &req)
+	w.Header().Set("Content-Type", "application/x-protobuf")
+}
+
+// startCollector serves collector on a local port for protocol and returns
+// the endpoint and a func stopping the server
+func startCollector(t *testing.T, protocol string, collector *fakeCollector) (string, func()) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	if protocol == OTLPProtocolHTTP {
+		server := &http.Server{Handler: collector}
+		go server.Serve(listener)
+		return listener.Addr().String(), func() { server.Close() }
+	}
+	server := grpc.NewServer()
+	collectortrace.RegisterTraceServiceServer(server, collector)
+	go server.Serve(listener)
+	return listener.Addr().String(), server.Stop
+}
+
+func TestInit_ExportsAndShutsDownCleanly(t *testing.T) {
+	keepGlobalProvider(t)
+
+	for _, protocol := range []string{OTLPProtocolGRPC, OTLPProtocolHTTP} {
+		t.Run(protocol, func(t *testing.T) {
+			before := runtime.NumGoroutine()
+			collector := &fakeCollector{}
+			endpoint, stop := startCollector(t, protocol, collector)
+
+			cfg := DefaultTelemetryConfig()
+			cfg.OTLPProtocol = protocol
+			cfg.OTLPEndpoint = endpoint
+			cfg.OTLPInsecure = true
+			shutdown, err := Init(cfg)
+			require.NoError(t, err)
+
+			_, span := otel.Tracer("test").Start(context.Background(), "op")
+			assert.True(t, span.IsRecording())
+			span.End()
+
+			// Shutdown flushes the batched span before stopping the exporter
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			require.NoError(t, shutdown(ctx))
+			assert.Equal(t, int64(1), collector.spans.Load())
+			stop()
+
+			deadline := time.Now().Add(2 * time.Second)
+			for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			assert.LessOrEqual(t, runtime.NumGoroutine(), before, "goroutines left running after shutdown")
+		})
+	}
+}
@@ -27,6 +27,10 @@ type TelemetryConfig struct {
 	JaegerEndpoint string  `yaml:"jaeger_endpoint"`
 	OTLPEndpoint   string  `yaml:"otlp_endpoint"`
 	SampleRate     float64 `yaml:"sample_rate"`
+	// OTLPProtocol is OTLPProtocolGRPC (the default) or OTLPProtocolHTTP
+	OTLPProtocol string `yaml:"otlp_protocol"`
+	// OTLPInsecure sends spans to the collector without TLS
+	OTLPInsecure bool `yaml:"otlp_insecure"`
 }
 
 // TelemetryManager manages distributed tracing
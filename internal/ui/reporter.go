@@ -22,6 +22,10 @@ func (r *Reporter) GenerateSavingsReport(resources []*cloud.ResourceV2) error {
 	content += "| :--- | :--- | :--- | :--- | :--- | :--- |\n"
 
 	totalSavings := 0.0
+	sizing := risk.DefaultSizingPolicy()
+	if r.Engine != nil {
+		sizing = r.Engine.Sizing
+	}
 
 	for _, res := range resources {
 		impact := res.CostPerMonth * 0.25
@@ -35,7 +39,7 @@ func (r *Reporter) GenerateSavingsReport(resources []*cloud.ResourceV2) error {
 		}
 
 		rec := "❌ Skip"
-		if res.CPUUsage < 40 && res.MemoryUsage < 50 && riskScore < 5.0 {
+		if sizing.Evaluate(res.CPUUsage, res.MemoryUsage).Safe && riskScore < 5.0 {
 			rec = "✅ Optimize"
 			totalSavings += impact
 		}
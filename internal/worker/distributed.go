@@ -2,6 +2,8 @@ package worker
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -16,6 +18,7 @@ import (
 	"github.com/Xover-Official/Xover/internal/analytics"
 	"github.com/Xover-Official/Xover/internal/config"
 	"github.com/Xover-Official/Xover/internal/persistence"
+	"github.com/Xover-Official/Xover/internal/taskstate"
 )
 
 // Task represents a distributed work item
@@ -27,6 +30,8 @@ type Task struct {
 	CreatedAt   time.Time              `json:"created_at"`
 	Attempts    int                    `json:"attempts"`
 	MaxAttempts int                    `json:"max_attempts"`
+
+	raw string // the queued JSON, which identifies the task in the processing list
 }
 
 // DistributedWorker represents a scalable worker node
@@ -51,6 +56,25 @@ type DistributedWorker struct {
 	lastHeartbeat  time.Time
 }
 
+// UniqueID makes a worker ID for this process from a configured prefix, the
+// hostname and a random suffix. Every process needs its own ID: replicas
+// sharing one would claim tasks into the same processing list, and a
+// restarting replica couldn't tell its orphans from its siblings' tasks.
+func UniqueID(prefix string) string {
+	if prefix == "" {
+		prefix = "worker"
+	}
+	id := prefix
+	if host, err := os.Hostname(); err == nil && host != "" {
+		id += "-" + host
+	}
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Sprintf("%s-%d", id, os.Getpid())
+	}
+	return id + "-" + hex.EncodeToString(suffix)
+}
+
 // NewDistributedWorker creates a new enterprise worker
 func NewDistributedWorker(workerID string, cfg *config.Config, db persistence.Ledger, orchestrator *ai.UnifiedOrchestrator, tracker *analytics.TokenTracker) (*DistributedWorker, error) {
 	// Connect to Redis
@@ -95,6 +119,15 @@ func (w *DistributedWorker) Start(ctx context.Context) error {
 	}
 	defer os.Remove("/tmp/healthy")
 
+	// Requeue tasks that crashed workers, including this one's previous run,
+	// left behind, then announce this worker so others leave its tasks alone
+	if recovered, err := w.recoverTasks(ctx); err != nil {
+		log.Printf("⚠️  Failed to recover in-flight tasks: %v", err)
+	} else if recovered > 0 {
+		log.Printf("♻️  Recovered %d in-flight tasks", recovered)
+	}
+	w.sendHeartbeat(ctx)
+
 	// Start heartbeat goroutine
 	w.wg.Add(1)
 	go w.heartbeatLoop(ctx)
@@ -130,7 +163,9 @@ func (w *DistributedWorker) Start(ctx context.Context) error {
 	return w.Shutdown()
 }
 
-// Shutdown gracefully stops the worker
+// Shutdown gracefully stops the worker. Running tasks get up to 30 seconds
+// to finish; fetched tasks that never started go back to their queues, and
+// anything still in flight is recovered when a worker next starts.
 func (w *DistributedWorker) Shutdown() error {
 	if !w.isRunning {
 		return nil
@@ -154,6 +189,12 @@ func (w *DistributedWorker) Shutdown() error {
 		log.Println("⚠️  Worker shutdown timeout")
 	}
 
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	if released := w.requeueUnstarted(ctx); released > 0 {
+		log.Printf("↩️  Requeued %d unstarted tasks", released)
+	}
+
 	// Close Redis connection
 	if err := w.redis.Close(); err != nil {
 		log.Printf("⚠️  Error closing Redis: %v", err)
@@ -221,8 +262,10 @@ func (w *DistributedWorker) taskFetcher(ctx context.Context) {
 				select {
 				case w.taskQueue <- *task:
 				case <-ctx.Done():
+					w.releaseOnShutdown(*task)
 					return
 				case <-w.shutdownChan:
+					w.releaseOnShutdown(*task)
 					return
 				}
 			} else {
@@ -233,13 +276,24 @@ func (w *DistributedWorker) taskFetcher(ctx context.Context) {
 	}
 }
 
-// fetchTask retrieves a task from Redis queue
+// releaseOnShutdown returns a task the fetcher could not hand off before
+// shutdown to its queue
+func (w *DistributedWorker) releaseOnShutdown(task Task) {
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	if err := w.release(ctx, task); err != nil {
+		log.Printf("⚠️  Failed to requeue task %s: %v", task.ID, err)
+	}
+}
+
+// fetchTask claims a task from Redis, moving it into this worker's
+// processing list so it survives a crash
 func (w *DistributedWorker) fetchTask(ctx context.Context) (*Task, error) {
 	// Try to get a task from high priority queue first
-	result, err := w.redis.BRPop(ctx, 1*time.Second, "tasks:high_priority").Result()
+	raw, err := w.redis.BRPopLPush(ctx, highPriorityQueue, w.processingQueue(), 1*time.Second).Result()
 	if err == redis.Nil {
 		// Try normal priority queue
-		result, err = w.redis.BRPop(ctx, 1*time.Second, "tasks:normal").Result()
+		raw, err = w.redis.BRPopLPush(ctx, normalQueue, w.processingQueue(), 1*time.Second).Result()
 	}
 
 	if err == redis.Nil {
@@ -249,14 +303,16 @@ func (w *DistributedWorker) fetchTask(ctx context.Context) (*Task, error) {
 		return nil, err
 	}
 
-	if len(result) < 2 {
-		return nil, fmt.Errorf("invalid task result")
-	}
-
 	var task Task
-	if err := json.Unmarshal([]byte(result[1]), &task); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal task: %w", err)
+	if err := json.Unmarshal([]byte(raw), &task); err != nil {
+		err = fmt.Errorf("failed to unmarshal task: %w", err)
+		// Retrying cannot fix it
+		if dlqErr := w.deadLetter(ctx, Task{raw: raw}, err); dlqErr != nil {
+			log.Printf("⚠️  %v", dlqErr)
+		}
+		return nil, err
 	}
+	task.raw = raw
 
 	return &task, nil
 }
@@ -286,16 +342,17 @@ func (w *DistributedWorker) processTask(ctx context.Context, task Task) {
 	start := time.Now()
 
 	// Update task status to processing
-	w.updateTaskStatus(ctx, task.ID, "processing")
+	w.updateTaskState(ctx, task, taskstate.StatusProcessing, "", nil)
 
+	var result string
 	var err error
 	switch task.Type {
 	case "scan":
-		err = w.handleScanTask(ctx, task)
+		result, err = w.handleScanTask(ctx, task)
 	case "analyze":
-		err = w.handleAnalyzeTask(ctx, task)
+		result, err = w.handleAnalyzeTask(ctx, task)
 	case "optimize":
-		err = w.handleOptimizeTask(ctx, task)
+		result, err = w.handleOptimizeTask(ctx, task)
 	default:
 		err = fmt.Errorf("unknown task type: %s", task.Type)
 	}
@@ -305,31 +362,38 @@ func (w *DistributedWorker) processTask(ctx context.Context, task Task) {
 	if err != nil {
 		w.tasksFailed++
 		w.errorQueue <- fmt.Errorf("task %s failed: %w", task.ID, err)
-		w.updateTaskStatus(ctx, task.ID, "failed")
 
 		// Retry logic
 		if task.Attempts < task.MaxAttempts {
 			task.Attempts++
 			w.retryTask(ctx, task)
+			w.updateTaskState(ctx, task, taskstate.StatusQueued, "", err)
+		} else if dlqErr := w.deadLetter(ctx, task, err); dlqErr != nil {
+			w.errorQueue <- dlqErr
+		} else {
+			w.updateTaskState(ctx, task, taskstate.StatusDead, "", err)
 		}
 	} else {
 		w.tasksProcessed++
-		w.updateTaskStatus(ctx, task.ID, "completed")
+		if err := w.ack(ctx, task); err != nil {
+			w.errorQueue <- fmt.Errorf("failed to ack task %s: %w", task.ID, err)
+		}
+		w.updateTaskState(ctx, task, taskstate.StatusCompleted, result, nil)
 		log.Printf("✅ Task %s completed in %v", task.ID, duration)
 	}
 }
 
 // handleScanTask processes cloud resource scanning
-func (w *DistributedWorker) handleScanTask(ctx context.Context, task Task) error {
+func (w *DistributedWorker) handleScanTask(ctx context.Context, task Task) (string, error) {
 	// Extract scan parameters
 	orgID, ok := task.Payload["org_id"].(string)
 	if !ok {
-		return fmt.Errorf("missing org_id in scan task")
+		return "", fmt.Errorf("missing org_id in scan task")
 	}
 
 	provider, ok := task.Payload["provider"].(string)
 	if !ok {
-		return fmt.Errorf("missing provider in scan task")
+		return "", fmt.Errorf("missing provider in scan task")
 	}
 
 	region, _ := task.Payload["region"].(string)
@@ -340,19 +404,19 @@ func (w *DistributedWorker) handleScanTask(ctx context.Context, task Task) error
 	// This would integrate with cloud provider APIs
 	time.Sleep(2 * time.Second) // Simulate work
 
-	return nil
+	return fmt.Sprintf("scanned %s %s for org %s", provider, region, orgID), nil
 }
 
 // handleAnalyzeTask processes AI analysis
-func (w *DistributedWorker) handleAnalyzeTask(ctx context.Context, task Task) error {
+func (w *DistributedWorker) handleAnalyzeTask(ctx context.Context, task Task) (string, error) {
 	resourceID, ok := task.Payload["resource_id"].(string)
 	if !ok {
-		return fmt.Errorf("missing resource_id in analyze task")
+		return "", fmt.Errorf("missing resource_id in analyze task")
 	}
 
 	prompt, ok := task.Payload["prompt"].(string)
 	if !ok {
-		return fmt.Errorf("missing prompt in analyze task")
+		return "", fmt.Errorf("missing prompt in analyze task")
 	}
 
 	riskScore, _ := task.Payload["risk_score"].(float64)
@@ -363,21 +427,21 @@ func (w *DistributedWorker) handleAnalyzeTask(ctx context.Context, task Task) er
 	// Call AI orchestrator
 	response, err := w.orchestrator.Analyze(ctx, prompt, riskScore, nil)
 	if err != nil {
-		return fmt.Errorf("AI analysis failed: %w", err)
+		return "", fmt.Errorf("AI analysis failed: %w", err)
 	}
 
 	// Store AI decision
 	// TODO: Store in database
 	log.Printf("🤖 AI analysis completed for %s: %s", resourceID, response.Content[:100])
 
-	return nil
+	return response.Content, nil
 }
 
 // handleOptimizeTask processes optimization actions
-func (w *DistributedWorker) handleOptimizeTask(ctx context.Context, task Task) error {
+func (w *DistributedWorker) handleOptimizeTask(ctx context.Context, task Task) (string, error) {
 	actionID, ok := task.Payload["action_id"].(string)
 	if !ok {
-		return fmt.Errorf("missing action_id in optimize task")
+		return "", fmt.Errorf("missing action_id in optimize task")
 	}
 
 	// Execute the optimization
@@ -385,25 +449,30 @@ func (w *DistributedWorker) handleOptimizeTask(ctx context.Context, task Task) e
 	time.Sleep(1 * time.Second) // Simulate work
 
 	log.Printf("⚡ Optimization completed for action %s", actionID)
-	return nil
+	return fmt.Sprintf("optimized action %s", actionID), nil
 }
 
-// updateTaskStatus updates task status in Redis
-func (w *DistributedWorker) updateTaskStatus(ctx context.Context, taskID, status string) {
-	key := fmt.Sprintf("tasks:%s:status", taskID)
-	w.redis.Set(ctx, key, status, 24*time.Hour)
+// updateTaskState records the task's progress for the manager API; cause is
+// the failure that sent it back to the queue or to tasks:dead
+func (w *DistributedWorker) updateTaskState(ctx context.Context, task Task, status taskstate.Status, result string, cause error) {
+	update := taskstate.Update{Status: status, Attempts: task.Attempts, WorkerID: w.id, Result: result}
+	if cause != nil {
+		update.Error = cause.Error()
+	}
+	if err := taskstate.Apply(ctx, w.redis, task.ID, update); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
 }
 
-// retryTask requeues a failed task for retry
+// retryTask moves a failed task from the processing list to the back of its
+// queue for retry
 func (w *DistributedWorker) retryTask(ctx context.Context, task Task) {
 	taskData, _ := json.Marshal(task)
 
-	queue := "tasks:normal"
-	if task.Priority > 5 {
-		queue = "tasks:high_priority"
+	if _, err := w.move(ctx, w.processingQueue(), task.raw, queueFor(task), taskData, false); err != nil {
+		w.errorQueue <- fmt.Errorf("failed to retry task %s: %w", task.ID, err)
+		return
 	}
-
-	w.redis.LPush(ctx, queue, taskData)
 	log.Printf("🔄 Retrying task %s (attempt %d/%d)", task.ID, task.Attempts, task.MaxAttempts)
 }
 
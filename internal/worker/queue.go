@@ -0,0 +1,217 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"github.com/Xover-Official/Xover/internal/taskstate"
+)
+
+// Redis keys for the task queues. Tasks are pushed on the left and popped on
+// the right, so the right end is the front of a queue.
+const (
+	highPriorityQueue = "tasks:high_priority"
+	normalQueue       = "tasks:normal"
+	deadLetterQueue   = "tasks:dead"
+
+	// processingPrefix is followed by a worker ID. Each worker claims tasks
+	// into its own list so a starting worker can tell its orphans apart
+	// from tasks that live workers are still processing.
+	processingPrefix = "tasks:processing:"
+)
+
+// drainTimeout bounds the Redis calls made while shutting down, after the
+// worker's context may already be cancelled
+const drainTimeout = 5 * time.Second
+
+// DeadTask is a task that exhausted its attempts, as recorded in tasks:dead
+type DeadTask struct {
+	Task
+	Error    string    `json:"error"`
+	WorkerID string    `json:"worker_id"`
+	FailedAt time.Time `json:"failed_at"`
+	Raw      string    `json:"raw,omitempty"` // set when the task could not be decoded
+}
+
+// moveScript removes ARGV[1] from KEYS[1] and, only if it was there, pushes
+// ARGV[2] onto KEYS[2]: at the front when ARGV[3] is "front", otherwise at
+// the back. Doing both in one script means a crash can neither drop the task
+// nor queue it twice, and two workers recovering the same list move each
+// task once.
+var moveScript = redis.NewScript(`
+if redis.call('LREM', KEYS[1], 1, ARGV[1]) == 0 then
+	return 0
+end
+if ARGV[3] == 'front' then
+	redis.call('RPUSH', KEYS[2], ARGV[2])
+else
+	redis.call('LPUSH', KEYS[2], ARGV[2])
+end
+return 1
+`)
+
+// queueFor returns the queue a task belongs in
+func queueFor(task Task) string {
+	if task.Priority > 5 {
+		return highPriorityQueue
+	}
+	return normalQueue
+}
+
+// processingQueue is the list this worker claims tasks into
+func (w *DistributedWorker) processingQueue() string {
+	return processingPrefix + w.id
+}
+
+// move atomically replaces raw in from with value in to, reporting whether
+// raw was still in from
+func (w *DistributedWorker) move(ctx context.Context, from, raw, to string, value []byte, front bool) (bool, error) {
+	end := "back"
+	if front {
+		end = "front"
+	}
+	moved, err := moveScript.Run(ctx, w.redis, []string{from, to}, raw, value, end).Int()
+	return moved == 1, err
+}
+
+// ack removes a finished task from the processing list
+func (w *DistributedWorker) ack(ctx context.Context, task Task) error {
+	return w.redis.LRem(ctx, w.processingQueue(), 1, task.raw).Err()
+}
+
+// deadLetter moves a task from the processing list to tasks:dead
+func (w *DistributedWorker) deadLetter(ctx context.Context, task Task, cause error) error {
+	dead := DeadTask{Task: task, Error: cause.Error(), WorkerID: w.id, FailedAt: time.Now()}
+	if task.ID == "" {
+		dead.Raw = task.raw
+	}
+	data, err := json.Marshal(dead)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead task: %w", err)
+	}
+
+	if _, err := w.move(ctx, w.processingQueue(), task.raw, deadLetterQueue, data, false); err != nil {
+		return fmt.Errorf("failed to dead-letter task %s: %w", task.ID, err)
+	}
+	log.Printf("☠️  Task %s moved to %s after %d attempts: %v", task.ID, deadLetterQueue, task.Attempts, cause)
+	return nil
+}
+
+// release returns a claimed task that was never started to the front of its
+// queue, unchanged
+func (w *DistributedWorker) release(ctx context.Context, task Task) error {
+	_, err := w.move(ctx, w.processingQueue(), task.raw, queueFor(task), []byte(task.raw), true)
+	return err
+}
+
+// requeueUnstarted releases the tasks fetched into taskQueue that no
+// processor picked up, keeping their order
+func (w *DistributedWorker) requeueUnstarted(ctx context.Context) int {
+	var unstarted []Task
+	for drained := false; !drained; {
+		select {
+		case task := <-w.taskQueue:
+			unstarted = append(unstarted, task)
+		default:
+			drained = true
+		}
+	}
+
+	// The first fetched goes back last, so it is at the front again
+	released := 0
+	for i := len(unstarted) - 1; i >= 0; i-- {
+		if err := w.release(ctx, unstarted[i]); err != nil {
+			// Still in the processing list; recovered on the next start
+			log.Printf("⚠️  Failed to requeue task %s: %v", unstarted[i].ID, err)
+			continue
+		}
+		released++
+	}
+	return released
+}
+
+// recoverTasks requeues the tasks left in processing lists by workers whose
+// heartbeat has expired. A list with a live heartbeat is never touched, even
+// one under this worker's ID, since another process may be using that ID. A
+// recovered task counts as an attempt, so one that keeps crashing workers ends
+// up in tasks:dead.
+func (w *DistributedWorker) recoverTasks(ctx context.Context) (int, error) {
+	var keys []string
+	iter := w.redis.Scan(ctx, 0, processingPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return 0, fmt.Errorf("failed to scan processing lists: %w", err)
+	}
+
+	recovered := 0
+	for _, key := range keys {
+		owner := strings.TrimPrefix(key, processingPrefix)
+		alive, err := w.redis.Exists(ctx, fmt.Sprintf("workers:%s", owner)).Result()
+		if err != nil {
+			return recovered, fmt.Errorf("failed to check worker %s: %w", owner, err)
+		}
+		if alive > 0 {
+			continue
+		}
+
+		raws, err := w.redis.LRange(ctx, key, 0, -1).Result()
+		if err != nil {
+			return recovered, fmt.Errorf("failed to read %s: %w", key, err)
+		}
+		for _, raw := range raws {
+			moved, err := w.recoverTask(ctx, key, owner, raw)
+			if err != nil {
+				return recovered, err
+			}
+			if moved {
+				recovered++
+			}
+		}
+	}
+	return recovered, nil
+}
+
+// recoverTask moves one orphaned task from a processing list back to its
+// queue, or to tasks:dead once it has no attempts left
+func (w *DistributedWorker) recoverTask(ctx context.Context, key, owner, raw string) (bool, error) {
+	cause := fmt.Errorf("worker %s stopped while processing the task", owner)
+
+	var task Task
+	if err := json.Unmarshal([]byte(raw), &task); err != nil {
+		dead := DeadTask{Error: fmt.Sprintf("failed to unmarshal task: %v", err), WorkerID: owner, FailedAt: time.Now(), Raw: raw}
+		data, _ := json.Marshal(dead)
+		return w.move(ctx, key, raw, deadLetterQueue, data, false)
+	}
+
+	if task.Attempts >= task.MaxAttempts {
+		data, err := json.Marshal(DeadTask{Task: task, Error: cause.Error(), WorkerID: owner, FailedAt: time.Now()})
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal dead task: %w", err)
+		}
+		moved, err := w.move(ctx, key, raw, deadLetterQueue, data, false)
+		if moved {
+			w.updateTaskState(ctx, task, taskstate.StatusDead, "", cause)
+		}
+		return moved, err
+	}
+
+	task.Attempts++
+	data, err := json.Marshal(task)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal task: %w", err)
+	}
+	moved, err := w.move(ctx, key, raw, queueFor(task), data, true)
+	if moved {
+		log.Printf("♻️  Requeued task %s from %s (attempt %d/%d)", task.ID, key, task.Attempts, task.MaxAttempts)
+		w.updateTaskState(ctx, task, taskstate.StatusQueued, "", cause)
+	}
+	return moved, err
+}
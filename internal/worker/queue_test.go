@@ -0,0 +1,302 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Xover-Official/Xover/internal/taskstate"
+)
+
+func newTestWorker(t *testing.T, server *miniredis.Miniredis, id string) *DistributedWorker {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return &DistributedWorker{
+		id:           id,
+		redis:        client,
+		taskQueue:    make(chan Task, 100),
+		errorQueue:   make(chan error, 10),
+		shutdownChan: make(chan struct{}),
+	}
+}
+
+// enqueue records and pushes tasks the way EnterpriseManager.enqueueTask does
+func enqueue(t *testing.T, server *miniredis.Miniredis, tasks ...Task) {
+	t.Helper()
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	for _, task := range tasks {
+		require.NoError(t, taskstate.Create(context.Background(), client, taskstate.State{
+			ID:          task.ID,
+			Type:        task.Type,
+			Status:      taskstate.StatusQueued,
+			Attempts:    task.Attempts,
+			MaxAttempts: task.MaxAttempts,
+			CreatedAt:   time.Now(),
+		}))
+		data, err := json.Marshal(task)
+		require.NoError(t, err)
+		_, err = server.Lpush(queueFor(task), string(data))
+		require.NoError(t, err)
+	}
+}
+
+func taskState(t *testing.T, w *DistributedWorker, id string) taskstate.State {
+	t.Helper()
+	state, err := taskstate.Get(context.Background(), w.redis, id)
+	require.NoError(t, err)
+	return state
+}
+
+// queued decodes a list, front first
+func queued(t *testing.T, server *miniredis.Miniredis, key string) []Task {
+	t.Helper()
+	if !server.Exists(key) {
+		return nil
+	}
+	values, err := server.List(key)
+	require.NoError(t, err)
+	tasks := make([]Task, 0, len(values))
+	for i := len(values) - 1; i >= 0; i-- {
+		var task Task
+		require.NoError(t, json.Unmarshal([]byte(values[i]), &task))
+		tasks = append(tasks, task)
+	}
+	return tasks
+}
+
+func deadTasks(t *testing.T, server *miniredis.Miniredis) []DeadTask {
+	t.Helper()
+	if !server.Exists(deadLetterQueue) {
+		return nil
+	}
+	values, err := server.List(deadLetterQueue)
+	require.NoError(t, err)
+	dead := make([]DeadTask, len(values))
+	for i, value := range values {
+		require.NoError(t, json.Unmarshal([]byte(value), &dead[i]))
+	}
+	return dead
+}
+
+func TestFetchTask_ClaimsIntoProcessingList(t *testing.T) {
+	server := miniredis.RunT(t)
+	w := newTestWorker(t, server, "worker-1")
+	enqueue(t, server, Task{ID: "normal", MaxAttempts: 3}, Task{ID: "urgent", Priority: 9, MaxAttempts: 3})
+
+	task, err := w.fetchTask(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, task)
+	assert.Equal(t, "urgent", task.ID, "high priority first")
+
+	assert.Empty(t, queued(t, server, highPriorityQueue))
+	processing := queued(t, server, processingPrefix+"worker-1")
+	require.Len(t, processing, 1)
+	assert.Equal(t, "urgent", processing[0].ID)
+}
+
+func TestWorkerCrash_TaskIsRequeued(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	crashed := newTestWorker(t, server, "worker-1")
+	enqueue(t, server, Task{ID: "task-1", Type: "scan", MaxAttempts: 3})
+
+	// The worker claims the task and dies before finishing it; its
+	// heartbeat expires with it
+	crashed.sendHeartbeat(ctx)
+	task, err := crashed.fetchTask(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, task)
+	assert.Empty(t, queued(t, server, normalQueue))
+	server.FastForward(time.Minute)
+
+	survivor := newTestWorker(t, server, "worker-2")
+	recovered, err := survivor.recoverTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, recovered)
+
+	assert.False(t, server.Exists(processingPrefix+"worker-1"))
+	requeued := queued(t, server, normalQueue)
+	require.Len(t, requeued, 1)
+	assert.Equal(t, "task-1", requeued[0].ID)
+	assert.Equal(t, 1, requeued[0].Attempts, "the crashed run counts as an attempt")
+
+	state := taskState(t, survivor, "task-1")
+	assert.Equal(t, taskstate.StatusQueued, state.Status)
+	assert.Equal(t, 1, state.Attempts)
+	assert.Contains(t, state.Error, "worker worker-1 stopped")
+
+	task, err = survivor.fetchTask(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, task)
+	assert.Equal(t, "task-1", task.ID)
+}
+
+func TestRecoverTasks_LeavesLiveWorkersAlone(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	busy := newTestWorker(t, server, "worker-1")
+	enqueue(t, server, Task{ID: "task-1", MaxAttempts: 3})
+
+	busy.sendHeartbeat(ctx)
+	_, err := busy.fetchTask(ctx)
+	require.NoError(t, err)
+
+	recovered, err := newTestWorker(t, server, "worker-2").recoverTasks(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, recovered)
+	assert.Len(t, queued(t, server, processingPrefix+"worker-1"), 1)
+	assert.Empty(t, queued(t, server, normalQueue))
+}
+
+func TestRecoverTasks_OwnListAfterRestart(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	before := newTestWorker(t, server, "worker-1")
+	enqueue(t, server, Task{ID: "task-1", MaxAttempts: 3})
+
+	before.sendHeartbeat(ctx)
+	_, err := before.fetchTask(ctx)
+	require.NoError(t, err)
+
+	// A live heartbeat under our own ID may be a sibling using the same ID,
+	// so its tasks are left alone
+	recovered, err := newTestWorker(t, server, "worker-1").recoverTasks(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, recovered)
+	assert.Empty(t, queued(t, server, normalQueue))
+
+	// Once the heartbeat has expired, whoever starts next recovers them
+	server.FastForward(time.Minute)
+	recovered, err = newTestWorker(t, server, "worker-1").recoverTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, recovered)
+	assert.Len(t, queued(t, server, normalQueue), 1)
+}
+
+func TestUniqueID(t *testing.T) {
+	first, second := UniqueID("worker"), UniqueID("worker")
+	assert.NotEqual(t, first, second, "replicas sharing WORKER_ID get their own processing lists")
+	assert.True(t, strings.HasPrefix(first, "worker-"))
+	assert.True(t, strings.HasPrefix(UniqueID(""), "worker-"))
+}
+
+func TestRecoverTasks_DeadLettersExhaustedTasks(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	enqueue(t, server, Task{ID: "poison", Attempts: 3, MaxAttempts: 3})
+	_, err := newTestWorker(t, server, "worker-1").fetchTask(ctx)
+	require.NoError(t, err)
+
+	recovered, err := newTestWorker(t, server, "worker-2").recoverTasks(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, recovered)
+
+	assert.Empty(t, queued(t, server, normalQueue))
+	dead := deadTasks(t, server)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "poison", dead[0].ID)
+	assert.Equal(t, "worker-1", dead[0].WorkerID)
+	assert.Contains(t, dead[0].Error, "worker worker-1 stopped")
+}
+
+func TestProcessTask_RetriesThenDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	w := newTestWorker(t, server, "worker-1")
+	enqueue(t, server, Task{ID: "task-1", Type: "bogus", MaxAttempts: 1})
+
+	// First failure goes back to the queue
+	task, err := w.fetchTask(ctx)
+	require.NoError(t, err)
+	w.processTask(ctx, *task)
+	retried := queued(t, server, normalQueue)
+	require.Len(t, retried, 1)
+	assert.Equal(t, 1, retried[0].Attempts)
+	assert.Empty(t, queued(t, server, processingPrefix+"worker-1"))
+	assert.Equal(t, taskstate.StatusQueued, taskState(t, w, "task-1").Status)
+
+	// Second failure has no attempts left
+	task, err = w.fetchTask(ctx)
+	require.NoError(t, err)
+	w.processTask(ctx, *task)
+	assert.Empty(t, queued(t, server, normalQueue))
+	assert.Empty(t, queued(t, server, processingPrefix+"worker-1"))
+
+	dead := deadTasks(t, server)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "task-1", dead[0].ID)
+	assert.Equal(t, "unknown task type: bogus", dead[0].Error)
+	assert.Equal(t, "worker-1", dead[0].WorkerID)
+
+	state := taskState(t, w, "task-1")
+	assert.Equal(t, taskstate.StatusDead, state.Status)
+	assert.Equal(t, 1, state.Attempts)
+	assert.Equal(t, "unknown task type: bogus", state.Error)
+}
+
+func TestProcessTask_AcksCompletedTask(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	w := newTestWorker(t, server, "worker-1")
+	enqueue(t, server, Task{ID: "task-1", Type: "optimize", Payload: map[string]interface{}{"action_id": "a-1"}, MaxAttempts: 3})
+
+	task, err := w.fetchTask(ctx)
+	require.NoError(t, err)
+	w.processTask(ctx, *task)
+
+	assert.False(t, server.Exists(processingPrefix+"worker-1"))
+	assert.Empty(t, queued(t, server, normalQueue))
+	assert.Empty(t, deadTasks(t, server))
+
+	state := taskState(t, w, "task-1")
+	assert.Equal(t, taskstate.StatusCompleted, state.Status)
+	assert.Equal(t, "optimized action a-1", state.Result)
+	assert.Equal(t, "worker-1", state.WorkerID)
+}
+
+func TestFetchTask_DeadLettersMalformedTask(t *testing.T) {
+	server := miniredis.RunT(t)
+	w := newTestWorker(t, server, "worker-1")
+	_, err := server.Lpush(normalQueue, "{not json")
+	require.NoError(t, err)
+
+	task, err := w.fetchTask(context.Background())
+	assert.Error(t, err)
+	assert.Nil(t, task)
+
+	assert.False(t, server.Exists(processingPrefix+"worker-1"))
+	dead := deadTasks(t, server)
+	require.Len(t, dead, 1)
+	assert.Equal(t, "{not json", dead[0].Raw)
+}
+
+func TestRequeueUnstarted_KeepsOrder(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	w := newTestWorker(t, server, "worker-1")
+	enqueue(t, server, Task{ID: "first"}, Task{ID: "second"}, Task{ID: "third"})
+
+	for i := 0; i < 2; i++ {
+		task, err := w.fetchTask(ctx)
+		require.NoError(t, err)
+		w.taskQueue <- *task
+	}
+
+	assert.Equal(t, 2, w.requeueUnstarted(ctx))
+	assert.False(t, server.Exists(processingPrefix+"worker-1"))
+
+	var ids []string
+	for _, task := range queued(t, server, normalQueue) {
+		ids = append(ids, task.ID)
+	}
+	assert.Equal(t, []string{"first", "second", "third"}, ids)
+}
@@ -0,0 +1,14 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 001_initial_schema
+-- Description: Drop the initial schema
+
+DROP TABLE IF EXISTS audit_log;
+DROP TABLE IF EXISTS resources;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS organizations;
+DROP TABLE IF EXISTS savings_events;
+DROP TABLE IF EXISTS token_usage;
+DROP TABLE IF EXISTS ai_decisions;
+DROP TABLE IF EXISTS actions;
+
+DROP EXTENSION IF EXISTS "uuid-ossp";
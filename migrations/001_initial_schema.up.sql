@@ -1,5 +1,5 @@
 -- Talos PostgreSQL Schema Migration
--- Version: 001_initial_schema.sql
+-- Version: 001_initial_schema
 -- Description: Initial database schema for production PostgreSQL deployment
 
 -- Enable UUID extension
@@ -0,0 +1,15 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 002_user_management
+-- Description: Drop credentials, invitations and user lifecycle status
+
+DROP INDEX IF EXISTS idx_users_invite_token;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_status_check;
+
+ALTER TABLE users DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE users DROP COLUMN IF EXISTS invite_expires_at;
+ALTER TABLE users DROP COLUMN IF EXISTS invite_token_hash;
+ALTER TABLE users DROP COLUMN IF EXISTS status;
+ALTER TABLE users DROP COLUMN IF EXISTS password_hash;
+
+ALTER TABLE organizations DROP COLUMN IF EXISTS updated_at;
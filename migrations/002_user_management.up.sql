@@ -0,0 +1,18 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 002_user_management
+-- Description: Credentials, invitations and lifecycle status for organization/user administration
+
+ALTER TABLE organizations ADD COLUMN updated_at TIMESTAMP;
+
+ALTER TABLE users ADD COLUMN password_hash TEXT;
+ALTER TABLE users ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active';
+ALTER TABLE users ADD COLUMN invite_token_hash VARCHAR(64);
+ALTER TABLE users ADD COLUMN invite_expires_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN updated_at TIMESTAMP;
+
+ALTER TABLE users ADD CONSTRAINT users_status_check CHECK (status IN ('invited', 'active', 'disabled'));
+
+CREATE UNIQUE INDEX idx_users_invite_token ON users(invite_token_hash) WHERE invite_token_hash IS NOT NULL;
+
+COMMENT ON COLUMN users.password_hash IS 'bcrypt hash produced by the security manager; NULL for SSO-only users';
+COMMENT ON COLUMN users.invite_token_hash IS 'SHA-256 of the one-time invitation token; cleared on acceptance';
@@ -0,0 +1,5 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 003_pending_actions_index
+-- Description: Drop the pending actions queue index
+
+DROP INDEX IF EXISTS idx_actions_pending_queue;
@@ -0,0 +1,7 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 003_pending_actions_index
+-- Description: Index for keyset pagination over pending actions, ordered by (created_at, id)
+
+CREATE INDEX IF NOT EXISTS idx_actions_pending_queue
+    ON actions (created_at, (id::text))
+    WHERE status = 'PENDING';
@@ -0,0 +1,13 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 004_action_approval
+-- Description: Drop the human approval states for actions
+
+DROP INDEX IF EXISTS idx_actions_awaiting_approval;
+
+-- Without approval states, unresolved and rejected actions must not run
+UPDATE actions SET status = 'FAILED', error_message = 'approval states removed by migration rollback'
+WHERE status IN ('AWAITING_APPROVAL', 'REJECTED');
+
+ALTER TABLE actions DROP CONSTRAINT IF EXISTS actions_status_check;
+ALTER TABLE actions ADD CONSTRAINT actions_status_check
+    CHECK (status IN ('PENDING', 'IN_PROGRESS', 'COMPLETED', 'FAILED', 'ROLLED_BACK'));
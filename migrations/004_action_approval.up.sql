@@ -0,0 +1,11 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 004_action_approval
+-- Description: Human approval states for actions; approved actions move to PENDING
+
+ALTER TABLE actions DROP CONSTRAINT IF EXISTS actions_status_check;
+ALTER TABLE actions ADD CONSTRAINT actions_status_check
+    CHECK (status IN ('AWAITING_APPROVAL', 'PENDING', 'IN_PROGRESS', 'COMPLETED', 'FAILED', 'ROLLED_BACK', 'REJECTED'));
+
+CREATE INDEX IF NOT EXISTS idx_actions_awaiting_approval
+    ON actions (action_type, risk_score, estimated_savings)
+    WHERE status = 'AWAITING_APPROVAL';
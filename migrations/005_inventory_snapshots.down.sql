@@ -0,0 +1,7 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 005_inventory_snapshots
+-- Description: Drop the inventory snapshots
+
+DROP INDEX IF EXISTS idx_ai_decisions_resource_created;
+
+DROP TABLE IF EXISTS inventory_snapshots;
@@ -0,0 +1,19 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 005_inventory_snapshots
+-- Description: Per-cycle resource inventory snapshots, replayed by backtests
+
+CREATE TABLE IF NOT EXISTS inventory_snapshots (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    cycle_id VARCHAR(255) NOT NULL,
+    resource_id VARCHAR(255) NOT NULL,
+    resource JSONB NOT NULL,
+    captured_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE(cycle_id, resource_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_inventory_snapshots_captured ON inventory_snapshots(captured_at);
+CREATE INDEX IF NOT EXISTS idx_inventory_snapshots_resource ON inventory_snapshots(resource_id, captured_at);
+
+-- Backtests look up the latest decision per resource as of a snapshot
+CREATE INDEX IF NOT EXISTS idx_ai_decisions_resource_created ON ai_decisions(resource_id, created_at DESC);
@@ -0,0 +1,5 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 006_event_outbox
+-- Description: Drop the event outbox
+
+DROP TABLE IF EXISTS event_outbox;
@@ -0,0 +1,20 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 006_event_outbox
+-- Description: Durable outbox for the outbound lifecycle event stream
+
+-- Events are written in the same statement as the change they describe and
+-- delivered in sequence order; delivered rows are purged by retention
+CREATE TABLE IF NOT EXISTS event_outbox (
+    sequence BIGSERIAL PRIMARY KEY,
+    id UUID NOT NULL UNIQUE DEFAULT uuid_generate_v4(),
+    event_type VARCHAR(64) NOT NULL,
+    subject_id VARCHAR(255) NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}'::jsonb,
+    occurred_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_event_outbox_undelivered ON event_outbox(sequence) WHERE delivered_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_event_outbox_delivered ON event_outbox(delivered_at) WHERE delivered_at IS NOT NULL;
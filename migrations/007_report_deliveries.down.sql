@@ -0,0 +1,5 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 007_report_deliveries
+-- Description: Drop the report delivery log
+
+DROP TABLE IF EXISTS report_deliveries;
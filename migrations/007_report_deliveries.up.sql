@@ -0,0 +1,19 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 007_report_deliveries
+-- Description: Delivery log for scheduled report emails
+
+-- One row per schedule and period; a scheduler must claim the row before
+-- sending, so a report is never mailed twice for the same period
+CREATE TABLE IF NOT EXISTS report_deliveries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    schedule VARCHAR(255) NOT NULL,
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    recipients TEXT[] NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'SENDING', -- SENDING, SENT, FAILED
+    attempts INTEGER NOT NULL DEFAULT 1,
+    error_message TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP,
+    UNIQUE (schedule, period_start, period_end)
+);
@@ -0,0 +1,9 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 008_action_organizations
+-- Description: Drop organization scoping of actions and savings events
+
+DROP INDEX IF EXISTS idx_savings_org_created;
+DROP INDEX IF EXISTS idx_actions_org_pending_queue;
+
+ALTER TABLE savings_events DROP COLUMN IF EXISTS org_id;
+ALTER TABLE actions DROP COLUMN IF EXISTS org_id;
@@ -0,0 +1,12 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 008_action_organizations
+-- Description: Scope actions and savings events to an organization
+
+-- NULL for single-tenant deployments and rows created before tenancy
+ALTER TABLE actions ADD COLUMN IF NOT EXISTS org_id UUID REFERENCES organizations(id);
+ALTER TABLE savings_events ADD COLUMN IF NOT EXISTS org_id UUID REFERENCES organizations(id);
+
+CREATE INDEX IF NOT EXISTS idx_actions_org_pending_queue
+    ON actions (org_id, created_at, (id::text))
+    WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_savings_org_created ON savings_events(org_id, created_at DESC);
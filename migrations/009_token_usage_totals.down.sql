@@ -0,0 +1,6 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 009_token_usage_totals
+-- Description: Drop the per-row request count and savings from token_usage
+
+ALTER TABLE token_usage DROP COLUMN IF EXISTS savings_usd;
+ALTER TABLE token_usage DROP COLUMN IF EXISTS requests;
@@ -0,0 +1,8 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 009_token_usage_totals
+-- Description: Let a token_usage row carry several requests and the savings they found
+
+-- A row was one request; the token tracker writes one row per model per
+-- flush, covering every request since its previous flush
+ALTER TABLE token_usage ADD COLUMN IF NOT EXISTS requests INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE token_usage ADD COLUMN IF NOT EXISTS savings_usd DECIMAL(12,4) NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 010_token_usage_tier
+-- Description: Drop the tier from token_usage
+
+ALTER TABLE token_usage DROP COLUMN IF EXISTS tier;
@@ -0,0 +1,6 @@
+-- Talos PostgreSQL Schema Migration
+-- Version: 010_token_usage_tier
+-- Description: Record which AI tier each token_usage row was spent on
+
+-- Rows written before this migration have no tier and read back as "unknown"
+ALTER TABLE token_usage ADD COLUMN IF NOT EXISTS tier VARCHAR(50) NOT NULL DEFAULT '';
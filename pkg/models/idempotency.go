@@ -17,4 +17,5 @@ type ActionRecord struct {
 	ResourceID string       `json:"resource_id,omitempty"`
 	CreatedAt  time.Time    `json:"created_at"`
 	UpdatedAt  time.Time    `json:"updated_at"`
+	ExpiresAt  time.Time    `json:"expires_at"`
 }